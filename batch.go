@@ -0,0 +1,204 @@
+package river
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+
+	"github.com/riverqueue/river/rivershared/util/randutil"
+	"github.com/riverqueue/river/rivertype"
+)
+
+// Metadata keys used to track batches. Batches are modeled entirely in job
+// metadata, so these keys are reserved for River's use.
+const (
+	metadataKeyBatchID       = "river:batch_id"
+	metadataKeyBatchOnFinish = "river:batch_on_finish"
+)
+
+// Batch is a group of jobs with an optional "on finish" job that's run once
+// every job in the batch has been finalized (completed, cancelled, or
+// discarded). It's useful for fanning out work and then acting on the results
+// all at once, like sending a report or merging output.
+//
+// A batch is built up with Add and OnFinish, then converted to insert
+// parameters with Prepare, which can be passed to InsertMany or InsertManyTx:
+//
+//	batch := river.NewBatch(nil)
+//	for _, account := range accounts {
+//		batch.Add(SyncAccountArgs{AccountID: account.ID}, nil)
+//	}
+//	batch.OnFinish(SyncReportArgs{}, nil)
+//
+//	params, err := batch.Prepare()
+//	if err != nil {
+//		// handle error
+//	}
+//
+//	if _, err := client.InsertMany(ctx, params); err != nil {
+//		// handle error
+//	}
+//
+// The on finish job is inserted in the pending state along with the rest of
+// the batch, and a maintenance service run by the elected leader makes it
+// available once no unfinalized jobs remain in the batch. Because all state is
+// kept in the database, this is robust to leadership changes. Jobs and the on
+// finish job should be inserted together, ideally in a single transaction, so
+// that the on finish job can't run before the whole batch has been inserted.
+//
+// Progress of a batch can be checked with Client.BatchGet.
+type Batch struct {
+	id       string
+	jobs     []InsertManyParams
+	onFinish *InsertManyParams
+}
+
+// BatchOpts are options for a new batch.
+type BatchOpts struct {
+	// ID is a unique identifier for the batch. It's stored in the metadata of
+	// each of the batch's jobs, so it should be unique across all batches that
+	// may be in the database at the same time.
+	//
+	// Defaults to a randomly generated ID.
+	ID string
+}
+
+// NewBatch initializes a new batch. Add jobs with Add and an optional on
+// finish job with OnFinish, then call Prepare to get insert parameters.
+func NewBatch(opts *BatchOpts) *Batch {
+	if opts == nil {
+		opts = &BatchOpts{}
+	}
+
+	id := opts.ID
+	if id == "" {
+		id = "batch_" + randutil.Hex(12)
+	}
+
+	return &Batch{id: id}
+}
+
+// ID returns the batch's unique identifier, which can be used to check its
+// progress with Client.BatchGet.
+func (b *Batch) ID() string { return b.id }
+
+// Add adds a job to the batch. Insert options are optional and may be nil.
+func (b *Batch) Add(args JobArgs, insertOpts *InsertOpts) *Batch {
+	b.jobs = append(b.jobs, InsertManyParams{Args: args, InsertOpts: insertOpts})
+	return b
+}
+
+// OnFinish sets a job to be run once every job in the batch has been
+// finalized. Its worker can use Client.BatchGet to find out how many of the
+// batch's jobs completed successfully. Insert options are optional and may be
+// nil. Calling OnFinish again replaces any previously set job.
+func (b *Batch) OnFinish(args JobArgs, insertOpts *InsertOpts) *Batch {
+	b.onFinish = &InsertManyParams{Args: args, InsertOpts: insertOpts}
+	return b
+}
+
+// Prepare returns insert parameters for each of the batch's jobs, in the order
+// they were added, followed by the on finish job if one was set. The result
+// should be passed to InsertMany or InsertManyTx.
+func (b *Batch) Prepare() ([]InsertManyParams, error) {
+	if len(b.jobs) < 1 {
+		return nil, errors.New("batch must have at least one job")
+	}
+
+	params := make([]InsertManyParams, 0, len(b.jobs)+1)
+	for i, job := range b.jobs {
+		if job.Args == nil {
+			return nil, fmt.Errorf("batch job at index %d must have args", i)
+		}
+
+		insertOpts, err := batchInsertOpts(job.InsertOpts, metadataKeyBatchID, b.id)
+		if err != nil {
+			return nil, err
+		}
+
+		params = append(params, InsertManyParams{Args: job.Args, InsertOpts: insertOpts})
+	}
+
+	if b.onFinish != nil {
+		if b.onFinish.Args == nil {
+			return nil, errors.New("batch on finish job must have args")
+		}
+
+		insertOpts, err := batchInsertOpts(b.onFinish.InsertOpts, metadataKeyBatchOnFinish, b.id)
+		if err != nil {
+			return nil, err
+		}
+		insertOpts.Pending = true
+
+		params = append(params, InsertManyParams{Args: b.onFinish.Args, InsertOpts: insertOpts})
+	}
+
+	return params, nil
+}
+
+// batchInsertOpts returns a copy of the given insert options (which may be
+// nil) with the batch ID merged into its metadata under the given key.
+func batchInsertOpts(insertOpts *InsertOpts, metadataKey, batchID string) (*InsertOpts, error) {
+	var insertOptsCopy InsertOpts
+	if insertOpts != nil {
+		insertOptsCopy = *insertOpts
+	}
+
+	metadataMap := make(map[string]any)
+	if len(insertOptsCopy.Metadata) > 0 {
+		if err := json.Unmarshal(insertOptsCopy.Metadata, &metadataMap); err != nil {
+			return nil, fmt.Errorf("error unmarshaling metadata for batch job: %w", err)
+		}
+	}
+	metadataMap[metadataKey] = batchID
+
+	var err error
+	if insertOptsCopy.Metadata, err = json.Marshal(metadataMap); err != nil {
+		return nil, err
+	}
+
+	return &insertOptsCopy, nil
+}
+
+// BatchStatus is the progress of a batch, returned by Client.BatchGet.
+type BatchStatus struct {
+	// CountsByState is the number of the batch's jobs in each state. States
+	// without any jobs are omitted. The on finish job isn't included.
+	CountsByState map[rivertype.JobState]int
+
+	// Finished is true if every job in the batch has been finalized (completed,
+	// cancelled, or discarded), at which point its on finish job becomes
+	// eligible to run.
+	Finished bool
+
+	// ID is the batch's unique identifier.
+	ID string
+
+	// NumFinalized is the number of the batch's jobs which have been finalized
+	// (completed, cancelled, or discarded).
+	NumFinalized int
+
+	// NumJobs is the total number of jobs in the batch. Finalized jobs removed
+	// by the job cleaner are no longer counted.
+	NumJobs int
+}
+
+func batchStatusFromCounts(batchID string, countsByState map[rivertype.JobState]int) *BatchStatus {
+	status := &BatchStatus{
+		CountsByState: countsByState,
+		ID:            batchID,
+	}
+
+	for state, count := range countsByState {
+		status.NumJobs += count
+
+		switch state { //nolint:exhaustive
+		case rivertype.JobStateCancelled, rivertype.JobStateCompleted, rivertype.JobStateDiscarded:
+			status.NumFinalized += count
+		}
+	}
+
+	status.Finished = status.NumFinalized == status.NumJobs
+
+	return status
+}
@@ -0,0 +1,99 @@
+package river
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/riverqueue/river/rivertype"
+)
+
+func TestBatch(t *testing.T) {
+	t.Parallel()
+
+	t.Run("GeneratesID", func(t *testing.T) {
+		t.Parallel()
+
+		batch1 := NewBatch(nil)
+		batch2 := NewBatch(&BatchOpts{})
+		require.NotEmpty(t, batch1.ID())
+		require.NotEmpty(t, batch2.ID())
+		require.NotEqual(t, batch1.ID(), batch2.ID())
+
+		require.Equal(t, "my_batch", NewBatch(&BatchOpts{ID: "my_batch"}).ID())
+	})
+
+	t.Run("PreparesInsertParams", func(t *testing.T) {
+		t.Parallel()
+
+		batch := NewBatch(&BatchOpts{ID: "batch_123"}).
+			Add(noOpArgs{Name: "job1"}, &InsertOpts{Metadata: []byte(`{"foo":"bar"}`), Queue: "other_queue"}).
+			Add(noOpArgs{Name: "job2"}, nil).
+			OnFinish(noOpArgs{Name: "on_finish"}, &InsertOpts{Priority: 2})
+
+		params, err := batch.Prepare()
+		require.NoError(t, err)
+		require.Len(t, params, 3)
+
+		require.Equal(t, noOpArgs{Name: "job1"}, params[0].Args)
+		require.False(t, params[0].InsertOpts.Pending)
+		require.Equal(t, "other_queue", params[0].InsertOpts.Queue)
+		require.JSONEq(t, `{"foo":"bar","river:batch_id":"batch_123"}`, string(params[0].InsertOpts.Metadata))
+
+		require.Equal(t, noOpArgs{Name: "job2"}, params[1].Args)
+		require.False(t, params[1].InsertOpts.Pending)
+		require.JSONEq(t, `{"river:batch_id":"batch_123"}`, string(params[1].InsertOpts.Metadata))
+
+		require.Equal(t, noOpArgs{Name: "on_finish"}, params[2].Args)
+		require.True(t, params[2].InsertOpts.Pending)
+		require.Equal(t, 2, params[2].InsertOpts.Priority)
+		require.JSONEq(t, `{"river:batch_on_finish":"batch_123"}`, string(params[2].InsertOpts.Metadata))
+	})
+
+	t.Run("WithoutOnFinish", func(t *testing.T) {
+		t.Parallel()
+
+		params, err := NewBatch(nil).Add(noOpArgs{}, nil).Prepare()
+		require.NoError(t, err)
+		require.Len(t, params, 1)
+	})
+
+	t.Run("ErrorOnNoJobs", func(t *testing.T) {
+		t.Parallel()
+
+		_, err := NewBatch(nil).OnFinish(noOpArgs{}, nil).Prepare()
+		require.EqualError(t, err, "batch must have at least one job")
+	})
+
+	t.Run("ErrorOnNilArgs", func(t *testing.T) {
+		t.Parallel()
+
+		_, err := NewBatch(nil).Add(noOpArgs{}, nil).Add(nil, nil).Prepare()
+		require.EqualError(t, err, "batch job at index 1 must have args")
+
+		_, err = NewBatch(nil).Add(noOpArgs{}, nil).OnFinish(nil, nil).Prepare()
+		require.EqualError(t, err, "batch on finish job must have args")
+	})
+}
+
+func TestBatchStatusFromCounts(t *testing.T) {
+	t.Parallel()
+
+	status := batchStatusFromCounts("batch_123", map[rivertype.JobState]int{
+		rivertype.JobStateAvailable: 1,
+		rivertype.JobStateCompleted: 3,
+		rivertype.JobStateDiscarded: 1,
+	})
+	require.Equal(t, "batch_123", status.ID)
+	require.False(t, status.Finished)
+	require.Equal(t, 4, status.NumFinalized)
+	require.Equal(t, 5, status.NumJobs)
+
+	status = batchStatusFromCounts("batch_123", map[rivertype.JobState]int{
+		rivertype.JobStateCancelled: 1,
+		rivertype.JobStateCompleted: 3,
+	})
+	require.True(t, status.Finished)
+	require.Equal(t, 4, status.NumFinalized)
+	require.Equal(t, 4, status.NumJobs)
+}
@@ -1,14 +1,17 @@
 package river
 
 import (
+	"bytes"
 	"cmp"
 	"context"
 	"encoding/json"
 	"errors"
 	"fmt"
+	"iter"
 	"log/slog"
 	"os"
 	"regexp"
+	"slices"
 	"strings"
 	"sync"
 	"time"
@@ -25,6 +28,7 @@ import (
 	"github.com/riverqueue/river/internal/rivercommon"
 	"github.com/riverqueue/river/internal/workunit"
 	"github.com/riverqueue/river/riverdriver"
+	"github.com/riverqueue/river/rivermetrics"
 	"github.com/riverqueue/river/rivershared/baseservice"
 	"github.com/riverqueue/river/rivershared/riverpilot"
 	"github.com/riverqueue/river/rivershared/startstop"
@@ -47,6 +51,8 @@ const (
 	PriorityDefault    = rivercommon.PriorityDefault
 	QueueDefault       = rivercommon.QueueDefault
 	QueueNumWorkersMax = 10_000
+
+	ProgressFlushIntervalDefault = 5 * time.Second
 )
 
 // TestConfig contains configuration specific to test environments.
@@ -96,12 +102,37 @@ type Config struct {
 	// Defaults to 24 hours.
 	CancelledJobRetentionPeriod time.Duration
 
+	// ClusterEvents enables cluster-wide job events. When enabled, clients
+	// working jobs publish a notification for each job they finish working,
+	// and any client, including one that only inserts jobs, can receive events
+	// for jobs finished anywhere in the cluster with SubscribeCluster.
+	//
+	// Notifications are published in batches using listen/notify. In poll-only
+	// mode (see PollOnly) or with a driver that doesn't support listen/notify,
+	// nothing is published, and SubscribeCluster instead polls for recently
+	// finalized jobs every FetchPollInterval.
+	//
+	// Defaults to false.
+	ClusterEvents bool
+
 	// CompletedJobRetentionPeriod is the amount of time to keep completed jobs
 	// around before they're removed permanently.
 	//
 	// Defaults to 24 hours.
 	CompletedJobRetentionPeriod time.Duration
 
+	// DeadLetterEnabled causes discarded jobs to be moved into the
+	// river_job_dead_letter table along with their full errors history once
+	// they're older than DiscardedJobRetentionPeriod, instead of being deleted
+	// permanently. Dead letter entries can be inspected, requeued, or purged
+	// with the client's DeadLetter* functions.
+	//
+	// Requires that the river_job_dead_letter table has been raised through
+	// migration version 009.
+	//
+	// Defaults to false.
+	DeadLetterEnabled bool
+
 	// DiscardedJobRetentionPeriod is the amount of time to keep discarded jobs
 	// around before they're removed permanently.
 	//
@@ -164,6 +195,23 @@ type Config struct {
 	// instances of rivertype.JobInsertMiddleware).
 	JobInsertMiddleware []rivertype.JobInsertMiddleware
 
+	// JobLeaseDuration enables leases on running jobs when set above zero. Each
+	// job worked by the client holds a lease of this duration, which its
+	// producer renews on a heartbeat every third of the duration for as long as
+	// the job is running. The job rescuer then considers a job stuck as soon as
+	// its lease expires rather than after RescueStuckJobsAfter, so jobs from a
+	// crashed client are recovered quickly while healthy jobs can run for
+	// arbitrarily long without being rescued. Jobs without a lease, like those
+	// worked by clients that don't have leases enabled, are still rescued after
+	// RescueStuckJobsAfter.
+	//
+	// Leases are stored in job metadata under the `river:lease` key. Keep in
+	// mind that each renewal is a write to every running job, so very short
+	// durations increase load on the database.
+	//
+	// Defaults to 0, which disables leases.
+	JobLeaseDuration time.Duration
+
 	// JobTimeout is the maximum amount of time a job is allowed to run before its
 	// context is cancelled. A timeout of zero means JobTimeoutDefault will be
 	// used, whereas a value of -1 means the job's context will not be cancelled
@@ -180,7 +228,10 @@ type Config struct {
 	// rivertype.HookInsertBegin will cause the hook to be invoked before a job
 	// is inserted, or implementing rivertype.HookWorkBegin will cause it to be
 	// invoked before a job is worked. Hook structs may implement multiple hook
-	// interfaces.
+	// interfaces. Other hooks are invoked after a job is inserted
+	// (rivertype.HookInsertEnd), after it's worked (rivertype.HookWorkEnd),
+	// once its new state has been persisted (rivertype.HookJobStateChange),
+	// or after it's been rescued (rivertype.HookJobRescued).
 	//
 	// Order in this list is significant. A hook that appears first will be
 	// entered before a hook that appears later. For any particular phase, order
@@ -206,6 +257,22 @@ type Config struct {
 	// If not specified, defaults to 25 (MaxAttemptsDefault).
 	MaxAttempts int
 
+	// Metrics receives metrics about inserted and worked jobs and the depth of
+	// queues, like counters of completed jobs and histograms of run durations
+	// labeled by kind and queue. See the rivermetrics package for the metrics
+	// recorded. rivermetrics.PrometheusRecorder is an implementation that
+	// serves metrics for Prometheus to scrape, and rivermetrics.Recorder may
+	// be implemented to send them elsewhere.
+	//
+	// Metrics for inserted and started jobs are recorded by middleware that's
+	// installed ahead of any in Middleware. Metrics for jobs that have been
+	// worked are only recorded by clients working jobs. Gauges of queue depth
+	// are cluster-wide, so they're only updated periodically by the elected
+	// leader, and reset to zero by a client that loses leadership.
+	//
+	// Defaults to nil, in which case no metrics are recorded.
+	Metrics rivermetrics.Recorder
+
 	// Middleware contains middleware that may activate at certain points during
 	// a job's lifecycle (see rivertype.Middleware), installed globally.
 	//
@@ -245,6 +312,15 @@ type Config struct {
 	// pooling mode.
 	PollOnly bool
 
+	// ProgressFlushInterval is the interval at which running jobs flush
+	// progress and checkpoints recorded with RecordProgress and Checkpoint to
+	// the database, making them visible to JobGet and emitting
+	// EventKindJobProgress events. Progress and checkpoints are also always
+	// stored when a job finishes executing.
+	//
+	// Defaults to 5 seconds.
+	ProgressFlushInterval time.Duration
+
 	// Queues is a list of queue names for this client to operate on along with
 	// configuration for the queue like the maximum number of workers to run for
 	// each queue.
@@ -253,6 +329,12 @@ type Config struct {
 	// than working them. If it's specified, then Workers must also be given.
 	Queues map[string]QueueConfig
 
+	// RateLimitsByKind configures rate limits on how many jobs of particular
+	// kinds may be started, keyed by job kind. A kind's limit applies across
+	// every queue that the client works. Limits are in addition to any rate
+	// limit configured for a queue with QueueConfig.RateLimit.
+	RateLimitsByKind map[string]*RateLimitConfig
+
 	// ReindexerSchedule is the schedule for running the reindexer. If nil, the
 	// reindexer will run at midnight UTC every day.
 	ReindexerSchedule PeriodicSchedule
@@ -263,6 +345,9 @@ type Config struct {
 	// will be discarded.  This prevents jobs from being stuck forever if a worker
 	// crashes or is killed.
 	//
+	// Jobs that hold a lease for their current attempt (see JobLeaseDuration)
+	// are instead considered stuck once their lease expires.
+	//
 	// Note that this can result in repeat or duplicate execution of a job that is
 	// not actually stuck but is still working. The value should be set higher
 	// than the maximum duration you expect your jobs to run. Setting a value too
@@ -365,7 +450,9 @@ func (c *Config) WithDefaults() *Config {
 	return &Config{
 		AdvisoryLockPrefix:          c.AdvisoryLockPrefix,
 		CancelledJobRetentionPeriod: valutil.ValOrDefault(c.CancelledJobRetentionPeriod, maintenance.CancelledJobRetentionPeriodDefault),
+		ClusterEvents:               c.ClusterEvents,
 		CompletedJobRetentionPeriod: valutil.ValOrDefault(c.CompletedJobRetentionPeriod, maintenance.CompletedJobRetentionPeriodDefault),
+		DeadLetterEnabled:           c.DeadLetterEnabled,
 		DiscardedJobRetentionPeriod: valutil.ValOrDefault(c.DiscardedJobRetentionPeriod, maintenance.DiscardedJobRetentionPeriodDefault),
 		ErrorHandler:                c.ErrorHandler,
 		FetchCooldown:               valutil.ValOrDefault(c.FetchCooldown, FetchCooldownDefault),
@@ -373,13 +460,17 @@ func (c *Config) WithDefaults() *Config {
 		ID:                          valutil.ValOrDefaultFunc(c.ID, func() string { return defaultClientID(time.Now().UTC()) }),
 		Hooks:                       c.Hooks,
 		JobInsertMiddleware:         c.JobInsertMiddleware,
+		JobLeaseDuration:            c.JobLeaseDuration,
 		JobTimeout:                  valutil.ValOrDefault(c.JobTimeout, JobTimeoutDefault),
 		Logger:                      logger,
 		MaxAttempts:                 valutil.ValOrDefault(c.MaxAttempts, MaxAttemptsDefault),
+		Metrics:                     c.Metrics,
 		Middleware:                  c.Middleware,
 		PeriodicJobs:                c.PeriodicJobs,
 		PollOnly:                    c.PollOnly,
+		ProgressFlushInterval:       valutil.ValOrDefault(c.ProgressFlushInterval, ProgressFlushIntervalDefault),
 		Queues:                      c.Queues,
+		RateLimitsByKind:            c.RateLimitsByKind,
 		ReindexerSchedule:           c.ReindexerSchedule,
 		RescueStuckJobsAfter:        valutil.ValOrDefault(c.RescueStuckJobsAfter, rescueAfter),
 		RetryPolicy:                 retryPolicy,
@@ -415,6 +506,9 @@ func (c *Config) validate() error {
 	if len(c.ID) > 100 {
 		return errors.New("ID cannot be longer than 100 characters")
 	}
+	if c.JobLeaseDuration < 0 {
+		return errors.New("JobLeaseDuration cannot be less than zero")
+	}
 	if c.JobTimeout < -1 {
 		return errors.New("JobTimeout cannot be negative, except for -1 (infinite)")
 	}
@@ -424,6 +518,9 @@ func (c *Config) validate() error {
 	if len(c.Middleware) > 0 && (len(c.JobInsertMiddleware) > 0 || len(c.WorkerMiddleware) > 0) {
 		return errors.New("only one of the pair JobInsertMiddleware/WorkerMiddleware or Middleware may be provided (Middleware is recommended, and may contain both job insert and worker middleware)")
 	}
+	if c.ProgressFlushInterval < 0 {
+		return errors.New("ProgressFlushInterval cannot be less than zero")
+	}
 	if c.RescueStuckJobsAfter < 0 {
 		return errors.New("RescueStuckJobsAfter cannot be less than zero")
 	}
@@ -437,6 +534,10 @@ func (c *Config) validate() error {
 		}
 	}
 
+	if err := validateRateLimitsByKind(c.RateLimitsByKind); err != nil {
+		return err
+	}
+
 	if c.Workers == nil && c.Queues != nil {
 		return errors.New("Workers must be set if Queues is set")
 	}
@@ -464,12 +565,55 @@ type QueueConfig struct {
 	//
 	// Requires a minimum of 1, and a maximum of 10,000.
 	MaxWorkers int
+
+	// Concurrency configures limits on how many of the queue's jobs may be
+	// worked at once across all clients sharing the same database. Every
+	// client working the queue should be configured with the same limits.
+	// Limits can be overridden at runtime for every client at once with
+	// Client.QueueUpdate.
+	//
+	// Defaults to no limits beyond MaxWorkers.
+	Concurrency *ConcurrencyConfig
+
+	// FairFetch enables fair fetching for the queue. Instead of fetching jobs
+	// strictly in order of priority and then scheduled time, fetches are
+	// shared between partition keys in round robin fashion so that a single
+	// key with a large backlog (e.g. a tenant that inserted a large number of
+	// jobs at once) can't starve out jobs of other keys. Jobs with a more
+	// urgent priority are still always worked first.
+	//
+	// Partition keys are extracted from job args fields tagged with
+	// `river:"partition"`, in the same way as for partitioned concurrency
+	// limits (see PartitionConfig). Only args fields are supported, so a
+	// tenant ID that should be used as a key must be included in job args
+	// rather than only in job metadata. Jobs without a partition key share a
+	// single empty key.
+	//
+	// Fair fetching depends on an index added in migration version 8.
+	FairFetch bool
+
+	// RateLimit configures a rate limit on how many of the queue's jobs may be
+	// started. Limits on particular job kinds can be configured with
+	// Config.RateLimitsByKind.
+	//
+	// Defaults to no rate limit.
+	RateLimit *RateLimitConfig
 }
 
 func (c QueueConfig) validate(queueName string) error {
 	if c.MaxWorkers < 1 || c.MaxWorkers > QueueNumWorkersMax {
 		return fmt.Errorf("invalid number of workers for queue %q: %d", queueName, c.MaxWorkers)
 	}
+	if c.Concurrency != nil {
+		if err := c.Concurrency.validate(queueName); err != nil {
+			return err
+		}
+	}
+	if c.RateLimit != nil {
+		if err := c.RateLimit.validate(); err != nil {
+			return fmt.Errorf("invalid rate limit for queue %q: %w", queueName, err)
+		}
+	}
 	if err := validateQueueName(queueName); err != nil {
 		return err
 	}
@@ -486,6 +630,7 @@ type Client[TTx any] struct {
 	baseService   baseservice.BaseService
 	baseStartStop startstop.BaseStartStop
 
+	clusterEventStream     *clusterEventStream // nil unless ClusterEvents is enabled
 	completer              jobcompleter.JobCompleter
 	config                 *Config
 	driver                 riverdriver.Driver[TTx]
@@ -493,13 +638,16 @@ type Client[TTx any] struct {
 	hookLookupByJob        *hooklookup.JobHookLookup
 	hookLookupGlobal       hooklookup.HookLookupInterface
 	insertNotifyLimiter    *notifylimiter.Limiter
+	jobManyBatchSize       int // batch size for bulk operations like JobCancelMany; overridable in tests
 	middlewareLookupGlobal middlewarelookup.MiddlewareLookupInterface
 	notifier               *notifier.Notifier // may be nil in poll-only mode
 	periodicJobs           *PeriodicJobBundle
 	pilot                  riverpilot.Pilot
 	producersByQueueName   map[string]*producer
 	queueMaintainer        *maintenance.QueueMaintainer
+	queueMetrics           startstop.Service // nil unless Metrics is configured
 	queues                 *QueueBundle
+	rateLimitsByKind       map[string]*rateLimit // shared by all producers so local limits apply across queues
 	services               []startstop.Service
 	stopped                <-chan struct{}
 	subscriptionManager    *subscriptionManager
@@ -514,17 +662,23 @@ type Client[TTx any] struct {
 type clientTestSignals struct {
 	electedLeader testsignal.TestSignal[struct{}] // notifies when elected leader
 
+	batchFinisher       *maintenance.BatchFinisherTestSignals
 	jobCleaner          *maintenance.JobCleanerTestSignals
 	jobRescuer          *maintenance.JobRescuerTestSignals
 	jobScheduler        *maintenance.JobSchedulerTestSignals
 	periodicJobEnqueuer *maintenance.PeriodicJobEnqueuerTestSignals
 	queueCleaner        *maintenance.QueueCleanerTestSignals
 	reindexer           *maintenance.ReindexerTestSignals
+	sequencePromoter    *maintenance.SequencePromoterTestSignals
+	workflowPromoter    *maintenance.WorkflowPromoterTestSignals
 }
 
 func (ts *clientTestSignals) Init() {
 	ts.electedLeader.Init()
 
+	if ts.batchFinisher != nil {
+		ts.batchFinisher.Init()
+	}
 	if ts.jobCleaner != nil {
 		ts.jobCleaner.Init()
 	}
@@ -543,6 +697,12 @@ func (ts *clientTestSignals) Init() {
 	if ts.reindexer != nil {
 		ts.reindexer.Init()
 	}
+	if ts.sequencePromoter != nil {
+		ts.sequencePromoter.Init()
+	}
+	if ts.workflowPromoter != nil {
+		ts.workflowPromoter.Init()
+	}
 }
 
 var (
@@ -617,22 +777,33 @@ func NewClient[TTx any](driver riverdriver.Driver[TTx], config *Config) (*Client
 		driver:               driver,
 		hookLookupByJob:      hooklookup.NewJobHookLookup(),
 		hookLookupGlobal:     hooklookup.NewHookLookup(config.Hooks),
+		jobManyBatchSize:     maintenance.BatchSizeDefault,
 		producersByQueueName: make(map[string]*producer),
 		testSignals:          clientTestSignals{},
 		workCancel:           func(cause error) {}, // replaced on start, but here in case StopAndCancel is called before start up
 	}
 
-	client.queues = &QueueBundle{addProducer: client.addProducer, clientWillExecuteJobs: config.willExecuteJobs(), removeProducer: client.removeProducer}
+	client.queues = &QueueBundle{addProducer: client.addProducer, clientWillExecuteJobs: config.willExecuteJobs(), distributeEvent: client.distributeEvent, removeProducer: client.removeProducer}
 
 	baseservice.Init(archetype, &client.baseService)
 	client.baseService.Name = "Client" // Have to correct the name because base service isn't embedded like it usually is
 	client.insertNotifyLimiter = notifylimiter.NewLimiter(archetype, config.FetchCooldown)
 
+	if len(config.RateLimitsByKind) > 0 {
+		client.rateLimitsByKind = make(map[string]*rateLimit, len(config.RateLimitsByKind))
+		for kind, rateLimitConfig := range config.RateLimitsByKind {
+			client.rateLimitsByKind[kind] = newRateLimit(archetype, rateLimitKeyKind(kind), rateLimitConfig)
+		}
+	}
+
 	// Validation ensures that config.JobInsertMiddleware/WorkerMiddleware or
 	// the more abstract config.Middleware for middleware are set, but not both,
 	// so in practice we never append all three of these to each other.
 	{
 		middleware := config.Middleware
+		if config.Metrics != nil {
+			middleware = append([]rivertype.Middleware{&metricsMiddleware{recorder: config.Metrics}}, middleware...)
+		}
 		for _, jobInsertMiddleware := range config.JobInsertMiddleware {
 			middleware = append(middleware, jobInsertMiddleware)
 		}
@@ -683,6 +854,8 @@ func NewClient[TTx any](driver riverdriver.Driver[TTx], config *Config) (*Client
 
 		client.completer = jobcompleter.NewBatchCompleter(archetype, driver.GetExecutor(), client.pilot, nil)
 		client.subscriptionManager = newSubscriptionManager(archetype, nil)
+		client.subscriptionManager.jobStateChangeFunc = client.invokeJobStateChangeHooks
+		client.subscriptionManager.metrics = config.Metrics
 		client.services = append(client.services, client.completer, client.subscriptionManager)
 
 		if driver.SupportsListener() {
@@ -709,6 +882,12 @@ func NewClient[TTx any](driver riverdriver.Driver[TTx], config *Config) (*Client
 		client.services = append(client.services,
 			startstop.StartStopFunc(client.logStatsLoop))
 
+		// Not added to the main services list because it's only started while
+		// the client is leader, like the queue maintainer.
+		if config.Metrics != nil {
+			client.queueMetrics = startstop.StartStopFunc(client.queueMetricsLoop)
+		}
+
 		client.services = append(client.services,
 			startstop.StartStopFunc(client.handleLeadershipChangeLoop))
 
@@ -726,6 +905,7 @@ func NewClient[TTx any](driver riverdriver.Driver[TTx], config *Config) (*Client
 			jobCleaner := maintenance.NewJobCleaner(archetype, &maintenance.JobCleanerConfig{
 				CancelledJobRetentionPeriod: config.CancelledJobRetentionPeriod,
 				CompletedJobRetentionPeriod: config.CompletedJobRetentionPeriod,
+				DeadLetterEnabled:           config.DeadLetterEnabled,
 				DiscardedJobRetentionPeriod: config.DiscardedJobRetentionPeriod,
 				Schema:                      config.schema,
 				Timeout:                     config.JobCleanerTimeout,
@@ -735,10 +915,23 @@ func NewClient[TTx any](driver riverdriver.Driver[TTx], config *Config) (*Client
 		}
 
 		{
+			// With leases enabled, run the rescuer at least as often as leases
+			// expire so that jobs from a crashed client are recovered promptly.
+			var rescuerInterval time.Duration
+			if config.JobLeaseDuration > 0 {
+				rescuerInterval = min(maintenance.JobRescuerIntervalDefault, config.JobLeaseDuration)
+			}
+
 			jobRescuer := maintenance.NewRescuer(archetype, &maintenance.JobRescuerConfig{
 				ClientRetryPolicy: config.RetryPolicy,
+				HookLookupByJob:   client.hookLookupByJob,
+				HookLookupGlobal:  client.hookLookupGlobal,
+				Interval:          rescuerInterval,
 				RescueAfter:       config.RescueStuckJobsAfter,
-				Schema:            config.schema,
+				RescuedJobFunc: func(job *rivertype.JobRow) {
+					client.distributeEvent(&Event{Kind: EventKindJobRescued, Job: job})
+				},
+				Schema: config.schema,
 				WorkUnitFactoryFunc: func(kind string) workunit.WorkUnitFactory {
 					if workerInfo, ok := config.Workers.workersMap[kind]; ok {
 						return workerInfo.workUnitFactory
@@ -760,10 +953,40 @@ func NewClient[TTx any](driver riverdriver.Driver[TTx], config *Config) (*Client
 			client.testSignals.jobScheduler = &jobScheduler.TestSignals
 		}
 
+		{
+			batchFinisher := maintenance.NewBatchFinisher(archetype, &maintenance.BatchFinisherConfig{
+				NotifyInsert: client.maybeNotifyInsertForQueues,
+				Schema:       config.schema,
+			}, driver.GetExecutor())
+			maintenanceServices = append(maintenanceServices, batchFinisher)
+			client.testSignals.batchFinisher = &batchFinisher.TestSignals
+		}
+
+		{
+			sequencePromoter := maintenance.NewSequencePromoter(archetype, &maintenance.SequencePromoterConfig{
+				NotifyInsert: client.maybeNotifyInsertForQueues,
+				Schema:       config.schema,
+			}, driver.GetExecutor())
+			maintenanceServices = append(maintenanceServices, sequencePromoter)
+			client.testSignals.sequencePromoter = &sequencePromoter.TestSignals
+		}
+
+		{
+			workflowPromoter := maintenance.NewWorkflowPromoter(archetype, &maintenance.WorkflowPromoterConfig{
+				NotifyInsert: client.maybeNotifyInsertForQueues,
+				Schema:       config.schema,
+			}, driver.GetExecutor())
+			maintenanceServices = append(maintenanceServices, workflowPromoter)
+			client.testSignals.workflowPromoter = &workflowPromoter.TestSignals
+		}
+
 		{
 			periodicJobEnqueuer := maintenance.NewPeriodicJobEnqueuer(archetype, &maintenance.PeriodicJobEnqueuerConfig{
 				AdvisoryLockPrefix: config.AdvisoryLockPrefix,
 				Insert:             client.insertMany,
+				InsertedJobFunc: func(job *rivertype.JobRow) {
+					client.distributeEvent(&Event{Kind: EventKindPeriodicJobEnqueued, Job: job})
+				},
 			}, driver.GetExecutor())
 			maintenanceServices = append(maintenanceServices, periodicJobEnqueuer)
 			client.testSignals.periodicJobEnqueuer = &periodicJobEnqueuer.TestSignals
@@ -808,6 +1031,23 @@ func NewClient[TTx any](driver riverdriver.Driver[TTx], config *Config) (*Client
 		}
 	}
 
+	// Cluster events may be received by any client with a database pool,
+	// including ones that only insert jobs.
+	if config.ClusterEvents && driver.HasPool() {
+		var listener riverdriver.Listener
+		if driver.SupportsListener() && !config.PollOnly {
+			listener = driver.GetListener(config.schema)
+		}
+
+		client.clusterEventStream = newClusterEventStream(archetype, driver.GetExecutor(), listener, config.schema, config.FetchPollInterval)
+
+		// Only clients using listen/notify publish events. Clients in poll only
+		// mode rely on receivers polling for finalized jobs instead.
+		if client.notifier != nil {
+			client.subscriptionManager.clusterPublishFunc = client.publishClusterJobEvents
+		}
+	}
+
 	return client, nil
 }
 
@@ -960,6 +1200,13 @@ func (c *Client[TTx]) Start(ctx context.Context) error {
 
 		c.workCancel(rivercommon.ErrStop)
 
+		// Will only be started if this client was leader, but can tolerate a
+		// stop without having been started.
+		leaderServices := []startstop.Service{c.queueMaintainer}
+		if c.queueMetrics != nil {
+			leaderServices = append(leaderServices, c.queueMetrics)
+		}
+
 		// Stop all mainline services where stop order isn't important.
 		startstop.StopAllParallel(append(
 			// This list of services contains the completer, which should always
@@ -971,9 +1218,7 @@ func (c *Client[TTx]) Start(ctx context.Context) error {
 			// cases.
 			c.services,
 
-			// Will only be started if this client was leader, but can tolerate a
-			// stop without having been started.
-			c.queueMaintainer,
+			leaderServices...,
 		)...)
 	}()
 
@@ -1092,6 +1337,81 @@ func (c *Client[TTx]) SubscribeConfig(config *SubscribeConfig) (<-chan *Event, f
 	return c.subscriptionManager.SubscribeConfig(config)
 }
 
+// SubscribeCluster subscribes to job events for jobs worked anywhere in the
+// cluster, as opposed to Subscribe, which only receives events for jobs worked
+// by this client. It requires that ClusterEvents is enabled, and unlike
+// Subscribe, may be used by clients that only insert jobs. A common use is
+// waiting on the result of a job from a process that doesn't work jobs.
+//
+// Only job events for jobs that the completer set to a new state are
+// supported: EventKindJobCancelled, EventKindJobCompleted,
+// EventKindJobDiscarded, EventKindJobFailed, and EventKindJobSnoozed.
+// Subscribing to any other kind panics.
+//
+// Events are received via listen/notify from clients working jobs that also
+// have ClusterEvents enabled. In poll only mode or with a driver that doesn't
+// support listen/notify, the client instead polls for jobs finalized since the
+// subscription was created every FetchPollInterval. Polling only observes
+// finalized jobs, so EventKindJobSnoozed and failures of jobs that will be
+// retried aren't received. Polling relies on jobs' finalization times, so
+// events for jobs finalized by clients with clocks skewed behind this one's
+// may be missed.
+//
+// Events carry the job's row as it was fetched after the event was received,
+// and have no JobStats.
+//
+// Returned are a channel over which events are sent and a function to cancel
+// the subscription, which should be called when it's no longer needed. The
+// first subscription starts a background listener or poller that stops after
+// the last subscription is cancelled, and the provided context is only used
+// for that start. As with Subscribe, sends on the channel are non-blocking, so
+// events may be dropped if it's not read from in a timely manner.
+func (c *Client[TTx]) SubscribeCluster(ctx context.Context, kinds ...EventKind) (<-chan *Event, func(), error) {
+	if !c.config.ClusterEvents {
+		return nil, nil, errors.New("cluster subscriptions require Config.ClusterEvents to be enabled")
+	}
+	if c.clusterEventStream == nil {
+		return nil, nil, errors.New("cluster subscriptions require a driver with a non-nil database pool")
+	}
+
+	return c.clusterEventStream.Subscribe(ctx, &SubscribeConfig{Kinds: kinds})
+}
+
+// Distributes an event to subscriptions. A no-op for clients that don't work
+// jobs, which don't have a subscription manager.
+func (c *Client[TTx]) distributeEvent(event *Event) {
+	if c.subscriptionManager == nil {
+		return
+	}
+
+	c.subscriptionManager.distributeEvent(event)
+}
+
+// Distributes job inserted events for committed inserts, skipping any that
+// were skipped as duplicates.
+func (c *Client[TTx]) distributeInsertedEvents(results []*rivertype.JobInsertResult) {
+	for _, result := range results {
+		if result.UniqueSkippedAsDuplicate {
+			continue
+		}
+
+		c.distributeEvent(&Event{Kind: EventKindJobInserted, Job: result.Job})
+	}
+}
+
+// Invokes job state change hooks, both global and those for the job's kind,
+// for a job whose new state was persisted by the completer.
+func (c *Client[TTx]) invokeJobStateChangeHooks(ctx context.Context, job *rivertype.JobRow) {
+	hooks := c.hookLookupGlobal.ByHookKind(hooklookup.HookKindJobStateChange)
+	if workerInfo, ok := c.config.Workers.workersMap[job.Kind]; ok {
+		hooks = append(hooks, workerInfo.workUnitFactory.MakeUnit(job).HookLookup(c.hookLookupByJob).ByHookKind(hooklookup.HookKindJobStateChange)...)
+	}
+
+	for _, hook := range hooks {
+		hook.(rivertype.HookJobStateChange).JobStateChange(ctx, job) //nolint:forcetypeassert
+	}
+}
+
 // Dump aggregate stats from job completions to logs periodically.  These
 // numbers don't mean much in themselves, but can give a rough idea of the
 // proportions of each compared to each other, and may help flag outlying values
@@ -1123,6 +1443,11 @@ func (c *Client[TTx]) logStatsLoop(ctx context.Context, shouldStart bool, starte
 }
 
 func (c *Client[TTx]) handleLeadershipChangeLoop(ctx context.Context, shouldStart bool, started, stopped func()) error {
+	// Tracks whether the client was leader as of the last notification so that
+	// a lost leadership event isn't emitted for the initial notification of a
+	// client that was never leader.
+	var wasLeader bool
+
 	handleLeadershipChange := func(ctx context.Context, notification *leadership.Notification) {
 		c.baseService.Logger.DebugContext(ctx, c.baseService.Name+": Election change received",
 			slog.String("client_id", c.config.ID), slog.Bool("is_leader", notification.IsLeader))
@@ -1134,13 +1459,33 @@ func (c *Client[TTx]) handleLeadershipChangeLoop(ctx context.Context, shouldStar
 			// cancel the queue maintainer start, and overall run much faster.
 			c.testSignals.electedLeader.Signal(struct{}{})
 
+			if !wasLeader {
+				c.distributeEvent(&Event{Kind: EventKindLeadershipGained})
+			}
+
 			if err := c.queueMaintainer.Start(ctx); err != nil {
 				c.baseService.Logger.ErrorContext(ctx, "Error starting queue maintainer", slog.String("err", err.Error()))
 			}
 
+			if c.queueMetrics != nil {
+				if err := c.queueMetrics.Start(ctx); err != nil {
+					c.baseService.Logger.ErrorContext(ctx, "Error starting queue metrics", slog.String("err", err.Error()))
+				}
+			}
+
 		default:
 			c.queueMaintainer.Stop()
+
+			if c.queueMetrics != nil {
+				c.queueMetrics.Stop()
+			}
+
+			if wasLeader {
+				c.distributeEvent(&Event{Kind: EventKindLeadershipLost})
+			}
 		}
+
+		wasLeader = notification.IsLeader
 	}
 
 	if !shouldStart {
@@ -1346,155 +1691,608 @@ func (c *Client[TTx]) JobRetryTx(ctx context.Context, tx TTx, id int64) (*rivert
 	})
 }
 
-// ID returns the unique ID of this client as set in its config or
-// auto-generated if not specified.
-func (c *Client[TTx]) ID() string {
-	return c.config.ID
+// JobManyOpts are options for JobCancelMany, JobDeleteMany, and JobRetryMany,
+// along with their Tx variants.
+type JobManyOpts struct {
+	// CountOnly skips collecting the jobs acted on so that only counts are
+	// returned in the result. Without it every affected job row is held in
+	// memory until the operation finishes, which may be a lot of memory for
+	// operations matching many jobs.
+	CountOnly bool
 }
 
-func insertParamsFromConfigArgsAndOptions(archetype *baseservice.Archetype, config *Config, args JobArgs, insertOpts *InsertOpts) (*rivertype.JobInsertParams, error) {
-	encodedArgs, err := json.Marshal(args)
-	if err != nil {
-		return nil, fmt.Errorf("error marshaling args to JSON: %w", err)
-	}
-
-	if insertOpts == nil {
-		insertOpts = &InsertOpts{}
-	}
-
-	var jobInsertOpts InsertOpts
-	if argsWithOpts, ok := args.(JobArgsWithInsertOpts); ok {
-		jobInsertOpts = argsWithOpts.InsertOpts()
-	}
-
-	// If the time is stubbed (in a test), use that for `created_at`. Otherwise,
-	// leave an empty value which will either use the database's `now()` or be defaulted
-	// by drivers as necessary.
-	createdAt := archetype.Time.NowUTCOrNil()
+// JobCancelManyResult is the result of a JobCancelMany or JobCancelManyTx
+// operation.
+type JobCancelManyResult struct {
+	// Jobs are the jobs that were cancelled, ordered by ID. Jobs that were
+	// running at the time of the operation are included, but are only marked
+	// for cancellation and will still be in the running state. Empty if
+	// JobManyOpts.CountOnly was set.
+	Jobs []*rivertype.JobRow
 
-	maxAttempts := cmp.Or(insertOpts.MaxAttempts, jobInsertOpts.MaxAttempts, config.MaxAttempts)
-	priority := cmp.Or(insertOpts.Priority, jobInsertOpts.Priority, rivercommon.PriorityDefault)
-	queue := cmp.Or(insertOpts.Queue, jobInsertOpts.Queue, rivercommon.QueueDefault)
+	// NumCancelled is the number of jobs that were cancelled or marked for
+	// cancellation.
+	NumCancelled int
 
-	if err := validateQueueName(queue); err != nil {
-		return nil, err
-	}
+	// NumSkipped is the number of jobs that matched params, but which were left
+	// untouched because they were already finalized.
+	NumSkipped int
+}
 
-	tags := insertOpts.Tags
-	if insertOpts.Tags == nil {
-		tags = jobInsertOpts.Tags
-	}
-	if tags == nil {
-		tags = []string{}
-	} else {
-		for _, tag := range tags {
-			if len(tag) > 255 {
-				return nil, errors.New("tags should be a maximum of 255 characters long")
-			}
-			if !tagRE.MatchString(tag) {
-				return nil, errors.New("tags should match regex " + tagRE.String())
-			}
-		}
+// JobCancelMany cancels all jobs matching the given params. Jobs are selected
+// using the same filters as JobList (including an explicit list of IDs with
+// JobListParams.IDs), but pagination and ordering options like First, After,
+// and OrderBy are ignored so that every matching job is cancelled.
+//
+// Jobs are cancelled in batches, with each batch committed independently. If
+// an error is returned, batches prior to the one that failed will have already
+// been cancelled.
+//
+// Cancellation for each job follows the same rules as JobCancel. Jobs still in
+// the queue are cancelled immediately, finalized jobs are left unchanged, and
+// running jobs are marked for cancellation with their clients notified via
+// LISTEN/NOTIFY so that they can cancel the jobs' contexts.
+//
+//	res, err := client.JobCancelMany(ctx, river.NewJobListParams().
+//		Kinds("email_send").
+//		States(rivertype.JobStateAvailable, rivertype.JobStateScheduled), nil)
+//	if err != nil {
+//		// handle error
+//	}
+func (c *Client[TTx]) JobCancelMany(ctx context.Context, params *JobListParams, opts *JobManyOpts) (*JobCancelManyResult, error) {
+	if !c.driver.HasPool() {
+		return nil, errNoDriverDBPool
 	}
 
-	if priority > 4 {
-		return nil, errors.New("priority must be between 1 and 4")
-	}
+	return c.jobCancelMany(ctx, c.driver.GetExecutor(), params, opts)
+}
 
-	var uniqueOpts UniqueOpts
-	if !config.Test.DisableUniqueEnforcement {
-		uniqueOpts = insertOpts.UniqueOpts
-		if uniqueOpts.isEmpty() {
-			uniqueOpts = jobInsertOpts.UniqueOpts
-		}
-	}
-	if err := uniqueOpts.validate(); err != nil {
+// JobCancelManyTx cancels all jobs matching the given params within the
+// specified transaction. This variant lets a caller cancel jobs atomically
+// alongside other database changes. Cancelled jobs don't take effect until the
+// transaction commits, and if the transaction rolls back, so too are the
+// cancelled jobs.
+//
+// See JobCancelMany for details on how jobs are selected and cancelled.
+func (c *Client[TTx]) JobCancelManyTx(ctx context.Context, tx TTx, params *JobListParams, opts *JobManyOpts) (*JobCancelManyResult, error) {
+	return c.jobCancelMany(ctx, c.driver.UnwrapExecutor(tx), params, opts)
+}
+
+func (c *Client[TTx]) jobCancelMany(ctx context.Context, exec riverdriver.Executor, params *JobListParams, opts *JobManyOpts) (*JobCancelManyResult, error) {
+	res, err := c.jobManyInBatches(ctx, exec, params, opts, func(ids []int64) ([]*rivertype.JobRow, error) {
+		return exec.JobCancelMany(ctx, &riverdriver.JobCancelManyParams{
+			ID:                ids,
+			CancelAttemptedAt: c.baseService.Time.NowUTC(),
+			ControlTopic:      string(notifier.NotificationTopicControl),
+			Schema:            c.config.schema,
+		})
+	})
+	if err != nil {
 		return nil, err
 	}
 
-	metadata := insertOpts.Metadata
-	if len(metadata) == 0 {
-		metadata = []byte("{}")
-	}
-
-	insertParams := &rivertype.JobInsertParams{
-		Args:        args,
-		CreatedAt:   createdAt,
-		EncodedArgs: encodedArgs,
-		Kind:        args.Kind(),
-		MaxAttempts: maxAttempts,
-		Metadata:    metadata,
-		Priority:    priority,
-		Queue:       queue,
-		State:       rivertype.JobStateAvailable,
-		Tags:        tags,
-	}
-	if !uniqueOpts.isEmpty() {
-		internalUniqueOpts := (*dbunique.UniqueOpts)(&uniqueOpts)
-		insertParams.UniqueKey, err = dbunique.UniqueKey(archetype.Time, internalUniqueOpts, insertParams)
-		if err != nil {
-			return nil, err
-		}
-		insertParams.UniqueStates = internalUniqueOpts.StateBitmask()
-	}
+	return &JobCancelManyResult{
+		Jobs:         res.jobs,
+		NumCancelled: res.numActed,
+		NumSkipped:   res.numMatched - res.numActed,
+	}, nil
+}
 
-	switch {
-	case !insertOpts.ScheduledAt.IsZero():
-		insertParams.ScheduledAt = &insertOpts.ScheduledAt
-		insertParams.State = rivertype.JobStateScheduled
-	case !jobInsertOpts.ScheduledAt.IsZero():
-		insertParams.ScheduledAt = &jobInsertOpts.ScheduledAt
-		insertParams.State = rivertype.JobStateScheduled
-	default:
-		// Use a stubbed time if there was one, but otherwise prefer the value
-		// generated by the database. createdAt is nil unless time is stubbed.
-		insertParams.ScheduledAt = createdAt
-	}
+// JobDeleteManyResult is the result of a JobDeleteMany or JobDeleteManyTx
+// operation.
+type JobDeleteManyResult struct {
+	// Jobs are the jobs that were deleted, ordered by ID. Empty if
+	// JobManyOpts.CountOnly was set.
+	Jobs []*rivertype.JobRow
 
-	if insertOpts.Pending {
-		insertParams.State = rivertype.JobStatePending
-	}
+	// NumDeleted is the number of jobs that were deleted.
+	NumDeleted int
 
-	return insertParams, nil
+	// NumSkipped is the number of jobs that matched params, but which weren't
+	// deleted because they were running. These are the same jobs for which
+	// JobDelete would return rivertype.ErrJobRunning.
+	NumSkipped int
 }
 
-var errNoDriverDBPool = errors.New("driver must have non-nil database pool to use non-transactional methods like Insert and InsertMany (try InsertTx or InsertManyTx instead")
-
-// Insert inserts a new job with the provided args. Job opts can be used to
-// override any defaults that may have been provided by an implementation of
-// JobArgsWithInsertOpts.InsertOpts, as well as any global defaults. The
-// provided context is used for the underlying Postgres insert and can be used
-// to cancel the operation or apply a timeout.
+// JobDeleteMany deletes all jobs matching the given params. Jobs are selected
+// using the same filters as JobList (including an explicit list of IDs with
+// JobListParams.IDs), but pagination and ordering options like First, After,
+// and OrderBy are ignored so that every matching job is deleted.
 //
-//	jobRow, err := client.Insert(insertCtx, MyArgs{}, nil)
-//	if err != nil {
-//		// handle error
-//	}
-func (c *Client[TTx]) Insert(ctx context.Context, args JobArgs, opts *InsertOpts) (*rivertype.JobInsertResult, error) {
+// Jobs are deleted in batches, with each batch committed independently. If an
+// error is returned, batches prior to the one that failed will have already
+// been deleted.
+//
+// Jobs in the running state are never deleted. Where JobDelete would return
+// rivertype.ErrJobRunning, JobDeleteMany instead skips the job and counts it in
+// the result's NumSkipped.
+func (c *Client[TTx]) JobDeleteMany(ctx context.Context, params *JobListParams, opts *JobManyOpts) (*JobDeleteManyResult, error) {
 	if !c.driver.HasPool() {
 		return nil, errNoDriverDBPool
 	}
 
-	tx, err := c.driver.GetExecutor().Begin(ctx)
-	if err != nil {
-		return nil, err
-	}
-	defer tx.Rollback(ctx)
+	return c.jobDeleteMany(ctx, c.driver.GetExecutor(), params, opts)
+}
 
-	inserted, err := c.insert(ctx, tx, args, opts)
+// JobDeleteManyTx deletes all jobs matching the given params within the
+// specified transaction. This variant lets a caller delete jobs atomically
+// alongside other database changes. Deleted jobs aren't deleted until the
+// transaction commits, and if the transaction rolls back, so too are the
+// deleted jobs.
+//
+// See JobDeleteMany for details on how jobs are selected and deleted.
+func (c *Client[TTx]) JobDeleteManyTx(ctx context.Context, tx TTx, params *JobListParams, opts *JobManyOpts) (*JobDeleteManyResult, error) {
+	return c.jobDeleteMany(ctx, c.driver.UnwrapExecutor(tx), params, opts)
+}
+
+func (c *Client[TTx]) jobDeleteMany(ctx context.Context, exec riverdriver.Executor, params *JobListParams, opts *JobManyOpts) (*JobDeleteManyResult, error) {
+	res, err := c.jobManyInBatches(ctx, exec, params, opts, func(ids []int64) ([]*rivertype.JobRow, error) {
+		return exec.JobDeleteMany(ctx, &riverdriver.JobDeleteManyParams{
+			ID:     ids,
+			Schema: c.config.schema,
+		})
+	})
 	if err != nil {
 		return nil, err
 	}
 
-	if err := tx.Commit(ctx); err != nil {
-		return nil, err
-	}
-	return inserted, nil
+	return &JobDeleteManyResult{
+		Jobs:       res.jobs,
+		NumDeleted: res.numActed,
+		NumSkipped: res.numMatched - res.numActed,
+	}, nil
 }
 
-// InsertTx inserts a new job with the provided args on the given transaction.
-// Job opts can be used to override any defaults that may have been provided by
-// an implementation of JobArgsWithInsertOpts.InsertOpts, as well as any global
+// JobRetryManyResult is the result of a JobRetryMany or JobRetryManyTx
+// operation.
+type JobRetryManyResult struct {
+	// Jobs are the jobs that were made available to be retried, ordered by ID.
+	// Empty if JobManyOpts.CountOnly was set.
+	Jobs []*rivertype.JobRow
+
+	// NumRetried is the number of jobs that were made available to be retried.
+	NumRetried int
+
+	// NumSkipped is the number of jobs that matched params, but which were left
+	// untouched because they were running or already available.
+	NumSkipped int
+}
+
+// JobRetryMany updates all jobs matching the given params to make them
+// immediately available to be retried. Jobs are selected using the same filters
+// as JobList (including an explicit list of IDs with JobListParams.IDs), but
+// pagination and ordering options like First, After, and OrderBy are ignored so
+// that every matching job is retried.
+//
+// Jobs are retried in batches, with each batch committed independently. If an
+// error is returned, batches prior to the one that failed will have already
+// been retried.
+//
+// Each job is retried following the same rules as JobRetry. Jobs in the
+// running state are not touched, and jobs already available with a
+// scheduled_at in the past are left alone so they're not set back in line.
+func (c *Client[TTx]) JobRetryMany(ctx context.Context, params *JobListParams, opts *JobManyOpts) (*JobRetryManyResult, error) {
+	if !c.driver.HasPool() {
+		return nil, errNoDriverDBPool
+	}
+
+	return c.jobRetryMany(ctx, c.driver.GetExecutor(), params, opts)
+}
+
+// JobRetryManyTx updates all jobs matching the given params to make them
+// immediately available to be retried, within the specified transaction. This
+// variant lets a caller retry jobs atomically alongside other database
+// changes. Retried jobs aren't visible to be worked until the transaction
+// commits, and if the transaction rolls back, so too are the retried jobs.
+//
+// See JobRetryMany for details on how jobs are selected and retried.
+func (c *Client[TTx]) JobRetryManyTx(ctx context.Context, tx TTx, params *JobListParams, opts *JobManyOpts) (*JobRetryManyResult, error) {
+	return c.jobRetryMany(ctx, c.driver.UnwrapExecutor(tx), params, opts)
+}
+
+func (c *Client[TTx]) jobRetryMany(ctx context.Context, exec riverdriver.Executor, params *JobListParams, opts *JobManyOpts) (*JobRetryManyResult, error) {
+	res, err := c.jobManyInBatches(ctx, exec, params, opts, func(ids []int64) ([]*rivertype.JobRow, error) {
+		return exec.JobRetryMany(ctx, &riverdriver.JobRetryManyParams{
+			ID:     ids,
+			Schema: c.config.schema,
+		})
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return &JobRetryManyResult{
+		Jobs:       res.jobs,
+		NumRetried: res.numActed,
+		NumSkipped: res.numMatched - res.numActed,
+	}, nil
+}
+
+// jobManyInBatchesResult is the result of jobManyInBatches.
+type jobManyInBatchesResult struct {
+	jobs       []*rivertype.JobRow // nil if JobManyOpts.CountOnly was set
+	numActed   int
+	numMatched int
+}
+
+// jobManyInBatches pages through all jobs matching params in batches ordered by
+// ID, invoking batchFunc with the IDs of each batch. It returns all the jobs
+// returned by batchFunc ordered by ID (unless opts.CountOnly is set), along
+// with the number of jobs returned by batchFunc and the total number of jobs
+// that matched params.
+func (c *Client[TTx]) jobManyInBatches(ctx context.Context, exec riverdriver.Executor, params *JobListParams, opts *JobManyOpts, batchFunc func(ids []int64) ([]*rivertype.JobRow, error)) (*jobManyInBatchesResult, error) {
+	if opts == nil {
+		opts = &JobManyOpts{}
+	}
+	if params == nil {
+		params = NewJobListParams()
+	}
+
+	// Pagination and ordering is controlled here so that every matching job is
+	// visited exactly once regardless of how the params were configured.
+	params = params.copy()
+	params.after = nil
+	params.before = nil
+	params.paginationCount = int32(c.jobManyBatchSize) //nolint:gosec
+	params.sortField = JobListOrderByID
+	params.sortOrder = SortOrderAsc
+
+	res := &jobManyInBatchesResult{}
+
+	for {
+		dbParams, err := params.toDBParams()
+		if err != nil {
+			return nil, err
+		}
+
+		batchJobs, err := dblist.JobList(ctx, exec, dbParams)
+		if err != nil {
+			return nil, err
+		}
+		if len(batchJobs) < 1 {
+			break
+		}
+
+		res.numMatched += len(batchJobs)
+
+		jobs, err := batchFunc(sliceutil.Map(batchJobs, func(job *rivertype.JobRow) int64 { return job.ID }))
+		if err != nil {
+			return nil, err
+		}
+		res.numActed += len(jobs)
+		if !opts.CountOnly {
+			res.jobs = append(res.jobs, jobs...)
+		}
+
+		if len(batchJobs) < c.jobManyBatchSize {
+			break
+		}
+
+		params.after = &JobListCursor{id: batchJobs[len(batchJobs)-1].ID, sortField: JobListOrderByID}
+	}
+
+	slices.SortFunc(res.jobs, func(a, b *rivertype.JobRow) int { return cmp.Compare(a.ID, b.ID) })
+
+	return res, nil
+}
+
+// JobUpdateParams are the parameters for a JobUpdate operation. Fields left as
+// their zero value are not changed.
+type JobUpdateParams struct {
+	// MaxAttempts is the new maximum number of total attempts (including both
+	// the original run and all retries) before the job is discarded.
+	MaxAttempts int
+
+	// Metadata is a JSON object blob of metadata. By default it's merged into
+	// the job's existing metadata, with its keys taking precedence over any
+	// existing ones. Set MetadataReplace to replace existing metadata entirely
+	// instead. If nil, metadata is not changed. An error is returned if it's
+	// anything other than a JSON object.
+	Metadata []byte
+
+	// MetadataReplace indicates that Metadata should replace the job's
+	// existing metadata rather than being merged into it.
+	MetadataReplace bool
+
+	// Priority is the new priority of the job, between 1 and 4.
+	Priority int
+
+	// Queue is the new name of the job queue in which to work the job.
+	Queue string
+
+	// ScheduledAt is the new time at which the job should be worked. An
+	// available job moved into the future becomes scheduled, and a scheduled
+	// job moved into the past becomes available.
+	ScheduledAt time.Time
+
+	// Tags are new tags for the job, replacing any existing ones. If nil, tags
+	// are not changed. A non-nil empty slice removes all tags.
+	Tags []string
+}
+
+// JobUpdate updates the job with the given ID with the changes specified in
+// params, returning the updated job.
+//
+// Only jobs still waiting in the queue (available, pending, retryable, or
+// scheduled) can be updated. If the job is running or has already been
+// finalized, no changes are made and a *JobNotUpdatableError is returned.
+// Returns ErrNotFound if the job doesn't exist.
+//
+// A job's unique key is not recomputed, so changing properties like queue on a
+// unique job won't change the set of jobs it's considered a duplicate of.
+//
+//	job, err := client.JobUpdate(ctx, jobID, &river.JobUpdateParams{
+//		Priority:    1,
+//		ScheduledAt: time.Now().Add(time.Hour),
+//	})
+//	if err != nil {
+//		// handle error
+//	}
+func (c *Client[TTx]) JobUpdate(ctx context.Context, id int64, params *JobUpdateParams) (*rivertype.JobRow, error) {
+	return c.jobUpdate(ctx, c.driver.GetExecutor(), id, params)
+}
+
+// JobUpdateTx updates the job with the given ID with the changes specified in
+// params, within the specified transaction. This variant lets a caller update a
+// job atomically alongside other database changes. An updated job isn't
+// visible until the transaction commits, and if the transaction rolls back, so
+// too is the update.
+//
+// Only jobs still waiting in the queue (available, pending, retryable, or
+// scheduled) can be updated. If the job is running or has already been
+// finalized, no changes are made and a *JobNotUpdatableError is returned.
+// Returns ErrNotFound if the job doesn't exist.
+func (c *Client[TTx]) JobUpdateTx(ctx context.Context, tx TTx, id int64, params *JobUpdateParams) (*rivertype.JobRow, error) {
+	return c.jobUpdate(ctx, c.driver.UnwrapExecutor(tx), id, params)
+}
+
+func (c *Client[TTx]) jobUpdate(ctx context.Context, exec riverdriver.Executor, id int64, params *JobUpdateParams) (*rivertype.JobRow, error) {
+	if params == nil {
+		params = &JobUpdateParams{}
+	}
+
+	if params.MaxAttempts < 0 {
+		return nil, errors.New("max attempts must be greater than zero")
+	}
+
+	if params.Metadata != nil {
+		if !json.Valid(params.Metadata) {
+			return nil, errors.New("metadata must be valid JSON")
+		}
+
+		// Checked here because Postgres would happily merge a non-object into
+		// existing metadata, turning it into an array.
+		if trimmed := bytes.TrimSpace(params.Metadata); len(trimmed) < 1 || trimmed[0] != '{' {
+			return nil, errors.New("metadata must be a JSON object")
+		}
+	}
+
+	if params.Priority < 0 || params.Priority > 4 {
+		return nil, errors.New("priority must be between 1 and 4")
+	}
+
+	if params.Queue != "" {
+		if err := validateQueueName(params.Queue); err != nil {
+			return nil, err
+		}
+	}
+
+	for _, tag := range params.Tags {
+		if len(tag) > 255 {
+			return nil, errors.New("tags should be a maximum of 255 characters long")
+		}
+		if !tagRE.MatchString(tag) {
+			return nil, errors.New("tags should match regex " + tagRE.String())
+		}
+	}
+
+	job, err := exec.JobUpdateIfQueued(ctx, &riverdriver.JobUpdateIfQueuedParams{
+		ID:                  id,
+		MaxAttemptsDoUpdate: params.MaxAttempts != 0,
+		MaxAttempts:         params.MaxAttempts,
+		MetadataDoMerge:     params.Metadata != nil && !params.MetadataReplace,
+		MetadataDoUpdate:    params.Metadata != nil && params.MetadataReplace,
+		Metadata:            params.Metadata,
+		Now:                 c.baseService.Time.NowUTCOrNil(),
+		PriorityDoUpdate:    params.Priority != 0,
+		Priority:            params.Priority,
+		QueueDoUpdate:       params.Queue != "",
+		Queue:               params.Queue,
+		ScheduledAtDoUpdate: !params.ScheduledAt.IsZero(),
+		ScheduledAt:         params.ScheduledAt.UTC(),
+		Schema:              c.config.schema,
+		TagsDoUpdate:        params.Tags != nil,
+		Tags:                params.Tags,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	// Don't include a `default` so `exhaustive` lint can detect omissions.
+	switch job.State {
+	case rivertype.JobStateAvailable, rivertype.JobStatePending, rivertype.JobStateRetryable, rivertype.JobStateScheduled:
+	case rivertype.JobStateCancelled, rivertype.JobStateCompleted, rivertype.JobStateDiscarded, rivertype.JobStateRunning:
+		return nil, &JobNotUpdatableError{ID: job.ID, State: job.State}
+	}
+
+	return job, nil
+}
+
+// ID returns the unique ID of this client as set in its config or
+// auto-generated if not specified.
+func (c *Client[TTx]) ID() string {
+	return c.config.ID
+}
+
+func insertParamsFromConfigArgsAndOptions(archetype *baseservice.Archetype, config *Config, args JobArgs, insertOpts *InsertOpts) (*rivertype.JobInsertParams, error) {
+	encodedArgs, err := json.Marshal(args)
+	if err != nil {
+		return nil, fmt.Errorf("error marshaling args to JSON: %w", err)
+	}
+
+	if insertOpts == nil {
+		insertOpts = &InsertOpts{}
+	}
+
+	var jobInsertOpts InsertOpts
+	if argsWithOpts, ok := args.(JobArgsWithInsertOpts); ok {
+		jobInsertOpts = argsWithOpts.InsertOpts()
+	}
+
+	// If the time is stubbed (in a test), use that for `created_at`. Otherwise,
+	// leave an empty value which will either use the database's `now()` or be defaulted
+	// by drivers as necessary.
+	createdAt := archetype.Time.NowUTCOrNil()
+
+	maxAttempts := cmp.Or(insertOpts.MaxAttempts, jobInsertOpts.MaxAttempts, config.MaxAttempts)
+	priority := cmp.Or(insertOpts.Priority, jobInsertOpts.Priority, rivercommon.PriorityDefault)
+	queue := cmp.Or(insertOpts.Queue, jobInsertOpts.Queue, rivercommon.QueueDefault)
+
+	if err := validateQueueName(queue); err != nil {
+		return nil, err
+	}
+
+	tags := insertOpts.Tags
+	if insertOpts.Tags == nil {
+		tags = jobInsertOpts.Tags
+	}
+	if tags == nil {
+		tags = []string{}
+	} else {
+		for _, tag := range tags {
+			if len(tag) > 255 {
+				return nil, errors.New("tags should be a maximum of 255 characters long")
+			}
+			if !tagRE.MatchString(tag) {
+				return nil, errors.New("tags should match regex " + tagRE.String())
+			}
+		}
+	}
+
+	if priority > 4 {
+		return nil, errors.New("priority must be between 1 and 4")
+	}
+
+	var uniqueOpts UniqueOpts
+	if !config.Test.DisableUniqueEnforcement {
+		uniqueOpts = insertOpts.UniqueOpts
+		if uniqueOpts.isEmpty() {
+			uniqueOpts = jobInsertOpts.UniqueOpts
+		}
+	}
+	if err := uniqueOpts.validate(); err != nil {
+		return nil, err
+	}
+
+	sequenceOpts := insertOpts.SequenceOpts
+	if sequenceOpts.isEmpty() {
+		sequenceOpts = jobInsertOpts.SequenceOpts
+	}
+	if err := sequenceOpts.validate(); err != nil {
+		return nil, err
+	}
+	if !sequenceOpts.isEmpty() && insertOpts.Pending {
+		return nil, errors.New("SequenceOpts can't be combined with Pending")
+	}
+
+	metadata := insertOpts.Metadata
+	if len(metadata) == 0 {
+		metadata = []byte("{}")
+	}
+
+	insertParams := &rivertype.JobInsertParams{
+		Args:        args,
+		CreatedAt:   createdAt,
+		EncodedArgs: encodedArgs,
+		Kind:        args.Kind(),
+		MaxAttempts: maxAttempts,
+		Metadata:    metadata,
+		Priority:    priority,
+		Queue:       queue,
+		State:       rivertype.JobStateAvailable,
+		Tags:        tags,
+	}
+	if !uniqueOpts.isEmpty() {
+		internalUniqueOpts := (*dbunique.UniqueOpts)(&uniqueOpts)
+		insertParams.UniqueKey, err = dbunique.UniqueKey(archetype.Time, internalUniqueOpts, insertParams)
+		if err != nil {
+			return nil, err
+		}
+		insertParams.UniqueStates = internalUniqueOpts.StateBitmask()
+	}
+
+	if insertParams.Metadata, err = metadataWithPartitionKey(insertParams); err != nil {
+		return nil, err
+	}
+
+	if !sequenceOpts.isEmpty() {
+		if insertParams.Metadata, err = metadataWithSequence(&sequenceOpts, insertParams); err != nil {
+			return nil, err
+		}
+	}
+
+	switch {
+	case !insertOpts.ScheduledAt.IsZero():
+		insertParams.ScheduledAt = &insertOpts.ScheduledAt
+		insertParams.State = rivertype.JobStateScheduled
+	case !jobInsertOpts.ScheduledAt.IsZero():
+		insertParams.ScheduledAt = &jobInsertOpts.ScheduledAt
+		insertParams.State = rivertype.JobStateScheduled
+	default:
+		// Use a stubbed time if there was one, but otherwise prefer the value
+		// generated by the database. createdAt is nil unless time is stubbed.
+		insertParams.ScheduledAt = createdAt
+	}
+
+	// Sequenced jobs are inserted as pending and made available by the
+	// sequence promoter once they reach the head of their sequence.
+	if insertOpts.Pending || !sequenceOpts.isEmpty() {
+		insertParams.State = rivertype.JobStatePending
+	}
+
+	return insertParams, nil
+}
+
+var errNoDriverDBPool = errors.New("driver must have non-nil database pool to use non-transactional methods like Insert and InsertMany (try InsertTx or InsertManyTx instead")
+
+// Insert inserts a new job with the provided args. Job opts can be used to
+// override any defaults that may have been provided by an implementation of
+// JobArgsWithInsertOpts.InsertOpts, as well as any global defaults. The
+// provided context is used for the underlying Postgres insert and can be used
+// to cancel the operation or apply a timeout.
+//
+//	jobRow, err := client.Insert(insertCtx, MyArgs{}, nil)
+//	if err != nil {
+//		// handle error
+//	}
+func (c *Client[TTx]) Insert(ctx context.Context, args JobArgs, opts *InsertOpts) (*rivertype.JobInsertResult, error) {
+	if !c.driver.HasPool() {
+		return nil, errNoDriverDBPool
+	}
+
+	tx, err := c.driver.GetExecutor().Begin(ctx)
+	if err != nil {
+		return nil, err
+	}
+	defer tx.Rollback(ctx)
+
+	inserted, err := c.insert(ctx, tx, args, opts)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		return nil, err
+	}
+
+	c.distributeInsertedEvents([]*rivertype.JobInsertResult{inserted})
+
+	return inserted, nil
+}
+
+// InsertTx inserts a new job with the provided args on the given transaction.
+// Job opts can be used to override any defaults that may have been provided by
+// an implementation of JobArgsWithInsertOpts.InsertOpts, as well as any global
 // defaults. The provided context is used for the underlying Postgres insert and
 // can be used to cancel the operation or apply a timeout.
 //
@@ -1558,19 +2356,221 @@ func (c *Client[TTx]) InsertMany(ctx context.Context, params []InsertManyParams)
 	}
 	defer tx.Rollback(ctx)
 
-	inserted, err := c.validateParamsAndInsertMany(ctx, tx, params)
+	inserted, err := c.validateParamsAndInsertMany(ctx, tx, params)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		return nil, err
+	}
+
+	c.distributeInsertedEvents(inserted)
+
+	return inserted, nil
+}
+
+// InsertManyTx inserts many jobs at once. Each job is inserted as an
+// InsertManyParams tuple, which takes job args along with an optional set of
+// insert options, which override insert options provided by an
+// JobArgsWithInsertOpts.InsertOpts implementation or any client-level defaults.
+// The provided context is used for the underlying Postgres inserts and can be
+// used to cancel the operation or apply a timeout.
+//
+//	count, err := client.InsertManyTx(ctx, tx, []river.InsertManyParams{
+//		{Args: BatchInsertArgs{}},
+//		{Args: BatchInsertArgs{}, InsertOpts: &river.InsertOpts{Priority: 3}},
+//	})
+//	if err != nil {
+//		// handle error
+//	}
+//
+// This variant lets a caller insert jobs atomically alongside other database
+// changes. An inserted job isn't visible to be worked until the transaction
+// commits, and if the transaction rolls back, so too is the inserted job.
+func (c *Client[TTx]) InsertManyTx(ctx context.Context, tx TTx, params []InsertManyParams) ([]*rivertype.JobInsertResult, error) {
+	exec := c.driver.UnwrapExecutor(tx)
+	return c.validateParamsAndInsertMany(ctx, exec, params)
+}
+
+// validateParamsAndInsertMany is a helper method that wraps the insertMany
+// method to provide param validation and conversion prior to calling the actual
+// insertMany method. This allows insertMany to be reused by the
+// PeriodicJobEnqueuer which cannot reference top-level river package types.
+func (c *Client[TTx]) validateParamsAndInsertMany(ctx context.Context, tx riverdriver.ExecutorTx, params []InsertManyParams) ([]*rivertype.JobInsertResult, error) {
+	insertParams, err := c.insertManyParams(params)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := workflowValidateDepJobIDs(ctx, tx, c.config.schema, insertParams); err != nil {
+		return nil, err
+	}
+
+	return c.insertMany(ctx, tx, insertParams)
+}
+
+// insertMany is a shared code path for InsertMany and InsertManyTx, also used
+// by the PeriodicJobEnqueuer.
+func (c *Client[TTx]) insertMany(ctx context.Context, tx riverdriver.ExecutorTx, insertParams []*rivertype.JobInsertParams) ([]*rivertype.JobInsertResult, error) {
+	return c.insertManyShared(ctx, tx, insertParams, func(ctx context.Context, insertParams []*riverdriver.JobInsertFastParams) ([]*rivertype.JobInsertResult, error) {
+		results, err := c.pilot.JobInsertMany(ctx, tx, &riverdriver.JobInsertFastManyParams{
+			Jobs:   insertParams,
+			Schema: c.config.schema,
+		})
+		if err != nil {
+			return nil, err
+		}
+
+		return sliceutil.Map(results,
+			func(result *riverdriver.JobInsertFastResult) *rivertype.JobInsertResult {
+				return (*rivertype.JobInsertResult)(result)
+			},
+		), nil
+	})
+}
+
+// The shared code path for all Insert and InsertMany methods. It takes a
+// function that executes the actual insert operation and allows for different
+// implementations of the insert query to be passed in, each mapping their
+// results back to a common result type.
+func (c *Client[TTx]) insertManyShared(
+	ctx context.Context,
+	tx riverdriver.ExecutorTx,
+	insertParams []*rivertype.JobInsertParams,
+	execute func(context.Context, []*riverdriver.JobInsertFastParams) ([]*rivertype.JobInsertResult, error),
+) ([]*rivertype.JobInsertResult, error) {
+	doInner := func(ctx context.Context) ([]*rivertype.JobInsertResult, error) {
+		for _, params := range insertParams {
+			for _, hook := range append(
+				c.hookLookupGlobal.ByHookKind(hooklookup.HookKindInsertBegin),
+				c.hookLookupByJob.ByJobArgs(params.Args).ByHookKind(hooklookup.HookKindInsertBegin)...,
+			) {
+				if err := hook.(rivertype.HookInsertBegin).InsertBegin(ctx, params); err != nil { //nolint:forcetypeassert
+					return nil, err
+				}
+			}
+		}
+
+		finalInsertParams := sliceutil.Map(insertParams, func(params *rivertype.JobInsertParams) *riverdriver.JobInsertFastParams {
+			return (*riverdriver.JobInsertFastParams)(params)
+		})
+		results, err := execute(ctx, finalInsertParams)
+		if err != nil {
+			return results, err
+		}
+
+		for i, result := range results {
+			// Results are nil for fast inserts, which don't return rows.
+			if result == nil {
+				continue
+			}
+
+			for _, hook := range append(
+				c.hookLookupGlobal.ByHookKind(hooklookup.HookKindInsertEnd),
+				c.hookLookupByJob.ByJobArgs(insertParams[i].Args).ByHookKind(hooklookup.HookKindInsertEnd)...,
+			) {
+				if err := hook.(rivertype.HookInsertEnd).InsertEnd(ctx, insertParams[i], result); err != nil { //nolint:forcetypeassert
+					return nil, err
+				}
+			}
+		}
+
+		queues := make([]string, 0, 10)
+		for _, params := range insertParams {
+			if params.State == rivertype.JobStateAvailable {
+				queues = append(queues, params.Queue)
+			}
+		}
+		if err := c.maybeNotifyInsertForQueues(ctx, tx, queues); err != nil {
+			return nil, err
+		}
+		return results, nil
+	}
+
+	jobInsertMiddleware := c.middlewareLookupGlobal.ByMiddlewareKind(middlewarelookup.MiddlewareKindJobInsert)
+	if len(jobInsertMiddleware) > 0 {
+		// Wrap middlewares in reverse order so the one defined first is wrapped
+		// as the outermost function and is first to receive the operation.
+		for i := len(jobInsertMiddleware) - 1; i >= 0; i-- {
+			middlewareItem := jobInsertMiddleware[i].(rivertype.JobInsertMiddleware) //nolint:forcetypeassert // capture the current middleware item
+			previousDoInner := doInner                                               // Capture the current doInner function
+			doInner = func(ctx context.Context) ([]*rivertype.JobInsertResult, error) {
+				return middlewareItem.InsertMany(ctx, insertParams, previousDoInner)
+			}
+		}
+	}
+
+	return doInner(ctx)
+}
+
+// Validates input parameters for a batch insert operation and generates a set
+// of batch insert parameters.
+func (c *Client[TTx]) insertManyParams(params []InsertManyParams) ([]*rivertype.JobInsertParams, error) {
+	if len(params) < 1 {
+		return nil, errors.New("no jobs to insert")
+	}
+
+	insertParams := make([]*rivertype.JobInsertParams, len(params))
+	for i, param := range params {
+		if err := c.validateJobArgs(param.Args); err != nil {
+			return nil, err
+		}
+
+		insertParamsItem, err := insertParamsFromConfigArgsAndOptions(&c.baseService.Archetype, c.config, param.Args, param.InsertOpts)
+		if err != nil {
+			return nil, err
+		}
+
+		insertParams[i] = insertParamsItem
+	}
+
+	return insertParams, nil
+}
+
+// InsertManyFast inserts many jobs at once using Postgres' `COPY FROM` mechanism,
+// making the operation quite fast and memory efficient. Each job is inserted as
+// an InsertManyParams tuple, which takes job args along with an optional set of
+// insert options, which override insert options provided by an
+// JobArgsWithInsertOpts.InsertOpts implementation or any client-level defaults.
+// The provided context is used for the underlying Postgres inserts and can be
+// used to cancel the operation or apply a timeout.
+//
+//	count, err := client.InsertMany(ctx, []river.InsertManyParams{
+//		{Args: BatchInsertArgs{}},
+//		{Args: BatchInsertArgs{}, InsertOpts: &river.InsertOpts{Priority: 3}},
+//	})
+//	if err != nil {
+//		// handle error
+//	}
+//
+// Unlike with `InsertMany`, unique conflicts cannot be handled gracefully. If a
+// unique constraint is violated, the operation will fail and no jobs will be inserted.
+func (c *Client[TTx]) InsertManyFast(ctx context.Context, params []InsertManyParams) (int, error) {
+	if !c.driver.HasPool() {
+		return 0, errNoDriverDBPool
+	}
+
+	// Wrap in a transaction in case we need to notify about inserts.
+	tx, err := c.driver.GetExecutor().Begin(ctx)
+	if err != nil {
+		return 0, err
+	}
+	defer tx.Rollback(ctx)
+
+	inserted, err := c.insertManyFast(ctx, tx, params)
 	if err != nil {
-		return nil, err
+		return 0, err
 	}
-
 	if err := tx.Commit(ctx); err != nil {
-		return nil, err
+		return 0, err
 	}
 	return inserted, nil
 }
 
-// InsertManyTx inserts many jobs at once. Each job is inserted as an
-// InsertManyParams tuple, which takes job args along with an optional set of
+// InsertManyTx inserts many jobs at once using Postgres' `COPY FROM` mechanism,
+// making the operation quite fast and memory efficient. Each job is inserted as
+// an InsertManyParams tuple, which takes job args along with an optional set of
 // insert options, which override insert options provided by an
 // JobArgsWithInsertOpts.InsertOpts implementation or any client-level defaults.
 // The provided context is used for the underlying Postgres inserts and can be
@@ -1587,430 +2587,868 @@ func (c *Client[TTx]) InsertMany(ctx context.Context, params []InsertManyParams)
 // This variant lets a caller insert jobs atomically alongside other database
 // changes. An inserted job isn't visible to be worked until the transaction
 // commits, and if the transaction rolls back, so too is the inserted job.
-func (c *Client[TTx]) InsertManyTx(ctx context.Context, tx TTx, params []InsertManyParams) ([]*rivertype.JobInsertResult, error) {
+//
+// Unlike with `InsertManyTx`, unique conflicts cannot be handled gracefully. If
+// a unique constraint is violated, the operation will fail and no jobs will be
+// inserted.
+func (c *Client[TTx]) InsertManyFastTx(ctx context.Context, tx TTx, params []InsertManyParams) (int, error) {
 	exec := c.driver.UnwrapExecutor(tx)
-	return c.validateParamsAndInsertMany(ctx, exec, params)
+	return c.insertManyFast(ctx, exec, params)
 }
 
-// validateParamsAndInsertMany is a helper method that wraps the insertMany
-// method to provide param validation and conversion prior to calling the actual
-// insertMany method. This allows insertMany to be reused by the
-// PeriodicJobEnqueuer which cannot reference top-level river package types.
-func (c *Client[TTx]) validateParamsAndInsertMany(ctx context.Context, tx riverdriver.ExecutorTx, params []InsertManyParams) ([]*rivertype.JobInsertResult, error) {
+func (c *Client[TTx]) insertManyFast(ctx context.Context, tx riverdriver.ExecutorTx, params []InsertManyParams) (int, error) {
 	insertParams, err := c.insertManyParams(params)
 	if err != nil {
-		return nil, err
+		return 0, err
 	}
 
-	return c.insertMany(ctx, tx, insertParams)
-}
+	if err := workflowValidateDepJobIDs(ctx, tx, c.config.schema, insertParams); err != nil {
+		return 0, err
+	}
 
-// insertMany is a shared code path for InsertMany and InsertManyTx, also used
-// by the PeriodicJobEnqueuer.
-func (c *Client[TTx]) insertMany(ctx context.Context, tx riverdriver.ExecutorTx, insertParams []*rivertype.JobInsertParams) ([]*rivertype.JobInsertResult, error) {
-	return c.insertManyShared(ctx, tx, insertParams, func(ctx context.Context, insertParams []*riverdriver.JobInsertFastParams) ([]*rivertype.JobInsertResult, error) {
-		results, err := c.pilot.JobInsertMany(ctx, tx, &riverdriver.JobInsertFastManyParams{
+	results, err := c.insertManyShared(ctx, tx, insertParams, func(ctx context.Context, insertParams []*riverdriver.JobInsertFastParams) ([]*rivertype.JobInsertResult, error) {
+		count, err := tx.JobInsertFastManyNoReturning(ctx, &riverdriver.JobInsertFastManyParams{
 			Jobs:   insertParams,
 			Schema: c.config.schema,
 		})
 		if err != nil {
 			return nil, err
 		}
+		return make([]*rivertype.JobInsertResult, count), nil
+	})
+	if err != nil {
+		return 0, err
+	}
+
+	return len(results), nil
+}
+
+// Notify the given queues that new jobs are available. The queues list will be
+// deduplicated and each will be checked to see if it is due for an insert
+// notification from this client.
+func (c *Client[TTx]) maybeNotifyInsertForQueues(ctx context.Context, tx riverdriver.ExecutorTx, queues []string) error {
+	if len(queues) < 1 {
+		return nil
+	}
+
+	queueMap := make(map[string]struct{})
+	queuesDeduped := make([]string, 0, len(queues))
+	payloads := make([]string, 0, len(queues))
+
+	for _, queue := range queues {
+		if _, ok := queueMap[queue]; ok {
+			continue
+		}
+
+		queueMap[queue] = struct{}{}
+		if c.insertNotifyLimiter.ShouldTrigger(queue) {
+			payloads = append(payloads, fmt.Sprintf("{\"queue\": %q}", queue))
+			queuesDeduped = append(queuesDeduped, queue)
+		}
+	}
+
+	if len(payloads) < 1 {
+		return nil
+	}
+
+	err := tx.NotifyMany(ctx, &riverdriver.NotifyManyParams{
+		Payload: payloads,
+		Schema:  c.config.schema,
+		Topic:   string(notifier.NotificationTopicInsert),
+	})
+	if err != nil {
+		c.baseService.Logger.ErrorContext(
+			ctx,
+			c.baseService.Name+": Failed to send job insert notification",
+			slog.String("queues", strings.Join(queuesDeduped, ",")),
+			slog.String("err", err.Error()),
+		)
+		return err
+	}
+	return nil
+}
+
+// emit a notification about a queue being paused or resumed.
+func (c *Client[TTx]) notifyQueuePauseOrResume(ctx context.Context, tx riverdriver.ExecutorTx, action controlAction, queue string, opts *QueuePauseOpts) error {
+	c.baseService.Logger.DebugContext(ctx,
+		c.baseService.Name+": Notifying about queue state change",
+		slog.String("action", string(action)),
+		slog.String("queue", queue),
+		slog.String("opts", fmt.Sprintf("%+v", opts)),
+	)
+
+	payload, err := json.Marshal(controlEventPayload{Action: action, Queue: queue})
+	if err != nil {
+		return err
+	}
+
+	err = tx.NotifyMany(ctx, &riverdriver.NotifyManyParams{
+		Payload: []string{string(payload)},
+		Schema:  c.config.schema,
+		Topic:   string(notifier.NotificationTopicControl),
+	})
+	if err != nil {
+		c.baseService.Logger.ErrorContext(
+			ctx,
+			c.baseService.Name+": Failed to send queue state change notification",
+			slog.String("err", err.Error()),
+		)
+		return err
+	}
+	return nil
+}
+
+// Validates job args prior to insertion. Currently, verifies that a worker to
+// handle the kind is registered in the configured workers bundle.
+// This validation is skipped if the client is configured as an insert-only (with no workers)
+// or if the client is configured to skip unknown job kinds.
+func (c *Client[TTx]) validateJobArgs(args JobArgs) error {
+	if c.config.Workers == nil || c.config.SkipUnknownJobCheck {
+		return nil
+	}
+
+	if _, ok := c.config.Workers.workersMap[args.Kind()]; !ok {
+		return &UnknownJobKindError{Kind: args.Kind()}
+	}
+
+	return nil
+}
+
+func (c *Client[TTx]) addProducer(queueName string, queueConfig QueueConfig) *producer {
+	var queueRateLimit *rateLimit
+	if queueConfig.RateLimit != nil {
+		queueRateLimit = newRateLimit(&c.baseService.Archetype, rateLimitKeyQueue(queueName), queueConfig.RateLimit)
+	}
+
+	producer := newProducer(&c.baseService.Archetype, c.driver.GetExecutor(), c.pilot, &producerConfig{
+		AdvisoryLockPrefix:           c.config.AdvisoryLockPrefix,
+		ClientID:                     c.config.ID,
+		Completer:                    c.completer,
+		Concurrency:                  queueConfig.Concurrency,
+		ErrorHandler:                 c.config.ErrorHandler,
+		FairFetch:                    queueConfig.FairFetch,
+		FetchCooldown:                c.config.FetchCooldown,
+		FetchPollInterval:            c.config.FetchPollInterval,
+		HookLookupByJob:              c.hookLookupByJob,
+		HookLookupGlobal:             c.hookLookupGlobal,
+		JobEventCallback:             c.subscriptionManager.distributeEvent,
+		JobLeaseDuration:             c.config.JobLeaseDuration,
+		JobTimeout:                   c.config.JobTimeout,
+		MaxWorkers:                   queueConfig.MaxWorkers,
+		MiddlewareLookupGlobal:       c.middlewareLookupGlobal,
+		Notifier:                     c.notifier,
+		Queue:                        queueName,
+		ProgressFlushInterval:        c.config.ProgressFlushInterval,
+		QueueEventCallback:           c.subscriptionManager.distributeEvent,
+		RateLimit:                    queueRateLimit,
+		RateLimitsByKind:             c.rateLimitsByKind,
+		RetryPolicy:                  c.config.RetryPolicy,
+		SchedulerInterval:            c.config.schedulerInterval,
+		Schema:                       c.config.schema,
+		StaleProducerRetentionPeriod: 5 * time.Minute,
+		Workers:                      c.config.Workers,
+	})
+	c.producersByQueueName[queueName] = producer
+	return producer
+}
+
+func (c *Client[TTx]) removeProducer(queueName string) bool {
+	producer, ok := c.producersByQueueName[queueName]
+	if !ok {
+		return false
+	}
+
+	producer.Stop()
+
+	delete(c.producersByQueueName, queueName)
+	return true
+}
+
+var nameRegex = regexp.MustCompile(`^(?:[a-z0-9])+(?:[_|\-]?[a-z0-9]+)*$`)
+
+func validateQueueName(queueName string) error {
+	if queueName == "" {
+		return errors.New("queue name cannot be empty")
+	}
+	if len(queueName) > 64 {
+		return errors.New("queue name cannot be longer than 64 characters")
+	}
+	if !nameRegex.MatchString(queueName) {
+		return fmt.Errorf("queue name is invalid, expected letters and numbers separated by underscores or hyphens: %q", queueName)
+	}
+	return nil
+}
+
+// JobListResult is the result of a job list operation. It contains a list of
+// jobs and cursors for fetching the next or previous page of results.
+type JobListResult struct {
+	// Jobs is a slice of job returned as part of the list operation.
+	Jobs []*rivertype.JobRow
+
+	// FirstCursor is a cursor that can be used with JobListParams.Before to
+	// list the previous page of jobs.
+	FirstCursor *JobListCursor
+
+	// LastCursor is a cursor that can be used with JobListParams.After to list
+	// the next page of jobs.
+	LastCursor *JobListCursor
+}
+
+// JobList returns a paginated list of jobs matching the provided filters. The
+// provided context is used for the underlying Postgres query and can be used to
+// cancel the operation or apply a timeout.
+//
+//	params := river.NewJobListParams().First(10).State(rivertype.JobStateCompleted)
+//	jobRows, err := client.JobList(ctx, params)
+//	if err != nil {
+//		// handle error
+//	}
+func (c *Client[TTx]) JobList(ctx context.Context, params *JobListParams) (*JobListResult, error) {
+	if !c.driver.HasPool() {
+		return nil, errNoDriverDBPool
+	}
+
+	return c.jobList(ctx, c.driver.GetExecutor(), params)
+}
+
+// JobListTx returns a paginated list of jobs matching the provided filters. The
+// provided context is used for the underlying Postgres query and can be used to
+// cancel the operation or apply a timeout.
+//
+//	params := river.NewJobListParams().First(10).States(river.JobStateCompleted)
+//	jobRows, err := client.JobListTx(ctx, tx, params)
+//	if err != nil {
+//		// handle error
+//	}
+func (c *Client[TTx]) JobListTx(ctx context.Context, tx TTx, params *JobListParams) (*JobListResult, error) {
+	return c.jobList(ctx, c.driver.UnwrapExecutor(tx), params)
+}
+
+func (c *Client[TTx]) jobList(ctx context.Context, exec riverdriver.Executor, params *JobListParams) (*JobListResult, error) {
+	if params == nil {
+		params = NewJobListParams()
+	}
+
+	dbParams, err := params.toDBParams()
+	if err != nil {
+		return nil, err
+	}
+
+	jobs, err := dblist.JobList(ctx, exec, dbParams)
+	if err != nil {
+		return nil, err
+	}
+
+	// Jobs before a cursor are fetched in reverse order, so put them back in
+	// the order that was requested.
+	if params.before != nil {
+		slices.Reverse(jobs)
+	}
+
+	res := &JobListResult{Jobs: jobs}
+	if len(jobs) > 0 {
+		res.FirstCursor = jobListCursorFromJobAndParams(jobs[0], params)
+		res.LastCursor = jobListCursorFromJobAndParams(jobs[len(jobs)-1], params)
+	}
+	return res, nil
+}
+
+// JobListAll returns an iterator over every job matching the provided filters,
+// fetching them page by page so that memory use stays bounded no matter how
+// many jobs match. The page size is set with JobListParams.First, and
+// iteration starts after the cursor given to JobListParams.After, if any. A
+// cursor given to JobListParams.Before is ignored.
+//
+// Each page is fetched with a separate query, so jobs inserted or changed
+// during iteration may or may not be seen. Use JobListAllTx in a transaction
+// with an isolation level of repeatable read or higher for a consistent
+// snapshot.
+//
+// If a page fails to be fetched, the error is yielded along with a nil job and
+// iteration stops.
+//
+//	for job, err := range client.JobListAll(ctx, river.NewJobListParams().First(1000)) {
+//		if err != nil {
+//			// handle error
+//		}
+//		...
+//	}
+func (c *Client[TTx]) JobListAll(ctx context.Context, params *JobListParams) iter.Seq2[*rivertype.JobRow, error] {
+	if !c.driver.HasPool() {
+		return func(yield func(*rivertype.JobRow, error) bool) {
+			yield(nil, errNoDriverDBPool)
+		}
+	}
 
-		return sliceutil.Map(results,
-			func(result *riverdriver.JobInsertFastResult) *rivertype.JobInsertResult {
-				return (*rivertype.JobInsertResult)(result)
-			},
-		), nil
-	})
+	return c.jobListAll(ctx, c.driver.GetExecutor(), params)
 }
 
-// The shared code path for all Insert and InsertMany methods. It takes a
-// function that executes the actual insert operation and allows for different
-// implementations of the insert query to be passed in, each mapping their
-// results back to a common result type.
-func (c *Client[TTx]) insertManyShared(
-	ctx context.Context,
-	tx riverdriver.ExecutorTx,
-	insertParams []*rivertype.JobInsertParams,
-	execute func(context.Context, []*riverdriver.JobInsertFastParams) ([]*rivertype.JobInsertResult, error),
-) ([]*rivertype.JobInsertResult, error) {
-	doInner := func(ctx context.Context) ([]*rivertype.JobInsertResult, error) {
-		for _, params := range insertParams {
-			for _, hook := range append(
-				c.hookLookupGlobal.ByHookKind(hooklookup.HookKindInsertBegin),
-				c.hookLookupByJob.ByJobArgs(params.Args).ByHookKind(hooklookup.HookKindInsertBegin)...,
-			) {
-				if err := hook.(rivertype.HookInsertBegin).InsertBegin(ctx, params); err != nil { //nolint:forcetypeassert
-					return nil, err
-				}
+// JobListAllTx returns an iterator over every job matching the provided
+// filters within the specified transaction, fetching them page by page so that
+// memory use stays bounded no matter how many jobs match. The page size is set
+// with JobListParams.First, and iteration starts after the cursor given to
+// JobListParams.After, if any. A cursor given to JobListParams.Before is
+// ignored.
+//
+// If a page fails to be fetched, the error is yielded along with a nil job and
+// iteration stops.
+//
+//	for job, err := range client.JobListAllTx(ctx, tx, river.NewJobListParams().First(1000)) {
+//		if err != nil {
+//			// handle error
+//		}
+//		...
+//	}
+func (c *Client[TTx]) JobListAllTx(ctx context.Context, tx TTx, params *JobListParams) iter.Seq2[*rivertype.JobRow, error] {
+	return c.jobListAll(ctx, c.driver.UnwrapExecutor(tx), params)
+}
+
+func (c *Client[TTx]) jobListAll(ctx context.Context, exec riverdriver.Executor, params *JobListParams) iter.Seq2[*rivertype.JobRow, error] {
+	if params == nil {
+		params = NewJobListParams()
+	}
+
+	params = params.copy()
+	params.before = nil
+
+	return func(yield func(*rivertype.JobRow, error) bool) {
+		for {
+			res, err := c.jobList(ctx, exec, params)
+			if err != nil {
+				yield(nil, err)
+				return
 			}
-		}
 
-		finalInsertParams := sliceutil.Map(insertParams, func(params *rivertype.JobInsertParams) *riverdriver.JobInsertFastParams {
-			return (*riverdriver.JobInsertFastParams)(params)
-		})
-		results, err := execute(ctx, finalInsertParams)
-		if err != nil {
-			return results, err
-		}
+			for _, job := range res.Jobs {
+				if !yield(job, nil) {
+					return
+				}
+			}
 
-		queues := make([]string, 0, 10)
-		for _, params := range insertParams {
-			if params.State == rivertype.JobStateAvailable {
-				queues = append(queues, params.Queue)
+			if len(res.Jobs) < int(params.paginationCount) {
+				return
 			}
+
+			params = params.After(res.LastCursor)
 		}
-		if err := c.maybeNotifyInsertForQueues(ctx, tx, queues); err != nil {
-			return nil, err
-		}
-		return results, nil
 	}
+}
 
-	jobInsertMiddleware := c.middlewareLookupGlobal.ByMiddlewareKind(middlewarelookup.MiddlewareKindJobInsert)
-	if len(jobInsertMiddleware) > 0 {
-		// Wrap middlewares in reverse order so the one defined first is wrapped
-		// as the outermost function and is first to receive the operation.
-		for i := len(jobInsertMiddleware) - 1; i >= 0; i-- {
-			middlewareItem := jobInsertMiddleware[i].(rivertype.JobInsertMiddleware) //nolint:forcetypeassert // capture the current middleware item
-			previousDoInner := doInner                                               // Capture the current doInner function
-			doInner = func(ctx context.Context) ([]*rivertype.JobInsertResult, error) {
-				return middlewareItem.InsertMany(ctx, insertParams, previousDoInner)
-			}
-		}
+// JobCount returns the number of jobs matching the provided filters. Jobs are
+// selected using the same filters as JobList, but pagination and ordering
+// options like First, After, and OrderBy are ignored so that every matching job
+// is counted.
+//
+//	numDiscarded, err := client.JobCount(ctx, river.NewJobListParams().
+//		Kinds("email_send").
+//		States(rivertype.JobStateDiscarded))
+//	if err != nil {
+//		// handle error
+//	}
+func (c *Client[TTx]) JobCount(ctx context.Context, params *JobListParams) (int, error) {
+	if !c.driver.HasPool() {
+		return 0, errNoDriverDBPool
 	}
 
-	return doInner(ctx)
+	return c.jobCount(ctx, c.driver.GetExecutor(), params)
 }
 
-// Validates input parameters for a batch insert operation and generates a set
-// of batch insert parameters.
-func (c *Client[TTx]) insertManyParams(params []InsertManyParams) ([]*rivertype.JobInsertParams, error) {
-	if len(params) < 1 {
-		return nil, errors.New("no jobs to insert")
+// JobCountTx returns the number of jobs matching the provided filters within
+// the specified transaction. Jobs are selected using the same filters as
+// JobList, but pagination and ordering options like First, After, and OrderBy
+// are ignored so that every matching job is counted.
+//
+//	numDiscarded, err := client.JobCountTx(ctx, tx, river.NewJobListParams().
+//		Kinds("email_send").
+//		States(rivertype.JobStateDiscarded))
+//	if err != nil {
+//		// handle error
+//	}
+func (c *Client[TTx]) JobCountTx(ctx context.Context, tx TTx, params *JobListParams) (int, error) {
+	return c.jobCount(ctx, c.driver.UnwrapExecutor(tx), params)
+}
+
+func (c *Client[TTx]) jobCount(ctx context.Context, exec riverdriver.Executor, params *JobListParams) (int, error) {
+	dbParams, err := params.toCountDBParams()
+	if err != nil {
+		return 0, err
 	}
 
-	insertParams := make([]*rivertype.JobInsertParams, len(params))
-	for i, param := range params {
-		if err := c.validateJobArgs(param.Args); err != nil {
-			return nil, err
-		}
+	return dblist.JobCount(ctx, exec, dbParams)
+}
 
-		insertParamsItem, err := insertParamsFromConfigArgsAndOptions(&c.baseService.Archetype, c.config, param.Args, param.InsertOpts)
-		if err != nil {
-			return nil, err
-		}
+// JobCountGroupBy is a job property that counts can be grouped by with
+// JobCountBy.
+type JobCountGroupBy string
 
-		insertParams[i] = insertParamsItem
+const (
+	// JobCountGroupByKind groups job counts by kind.
+	JobCountGroupByKind JobCountGroupBy = "kind"
+
+	// JobCountGroupByQueue groups job counts by queue.
+	JobCountGroupByQueue JobCountGroupBy = "queue"
+
+	// JobCountGroupByState groups job counts by state.
+	JobCountGroupByState JobCountGroupBy = "state"
+
+	// JobCountGroupByTag groups job counts by tag. A job with multiple tags is
+	// counted once for each of them, and jobs without tags aren't counted.
+	JobCountGroupByTag JobCountGroupBy = "tag"
+)
+
+// Returns the SQL expression producing the values jobs are grouped by.
+func (g JobCountGroupBy) expr() (string, error) {
+	switch g {
+	case JobCountGroupByKind:
+		return "kind", nil
+	case JobCountGroupByQueue:
+		return "queue", nil
+	case JobCountGroupByState:
+		return "state", nil
+	case JobCountGroupByTag:
+		return "unnest(tags)", nil
 	}
 
-	return insertParams, nil
+	return "", fmt.Errorf("invalid job count group by: %q", g)
 }
 
-// InsertManyFast inserts many jobs at once using Postgres' `COPY FROM` mechanism,
-// making the operation quite fast and memory efficient. Each job is inserted as
-// an InsertManyParams tuple, which takes job args along with an optional set of
-// insert options, which override insert options provided by an
-// JobArgsWithInsertOpts.InsertOpts implementation or any client-level defaults.
-// The provided context is used for the underlying Postgres inserts and can be
-// used to cancel the operation or apply a timeout.
+// JobCountBy returns the number of jobs matching the provided filters grouped
+// by the given property, keyed by the property's value. Values without any
+// matching jobs are omitted. This makes it possible to show facet counts like
+// the number of jobs of each kind without fetching every job.
 //
-//	count, err := client.InsertMany(ctx, []river.InsertManyParams{
-//		{Args: BatchInsertArgs{}},
-//		{Args: BatchInsertArgs{}, InsertOpts: &river.InsertOpts{Priority: 3}},
-//	})
+// Jobs are selected using the same filters as JobList, but pagination and
+// ordering options like First, After, and OrderBy are ignored so that every
+// matching job is counted.
+//
+//	countsByKind, err := client.JobCountBy(ctx, river.NewJobListParams().
+//		States(rivertype.JobStateDiscarded), river.JobCountGroupByKind)
 //	if err != nil {
 //		// handle error
 //	}
-//
-// Unlike with `InsertMany`, unique conflicts cannot be handled gracefully. If a
-// unique constraint is violated, the operation will fail and no jobs will be inserted.
-func (c *Client[TTx]) InsertManyFast(ctx context.Context, params []InsertManyParams) (int, error) {
+func (c *Client[TTx]) JobCountBy(ctx context.Context, params *JobListParams, groupBy JobCountGroupBy) (map[string]int, error) {
 	if !c.driver.HasPool() {
-		return 0, errNoDriverDBPool
+		return nil, errNoDriverDBPool
 	}
 
-	// Wrap in a transaction in case we need to notify about inserts.
-	tx, err := c.driver.GetExecutor().Begin(ctx)
+	return c.jobCountBy(ctx, c.driver.GetExecutor(), params, groupBy)
+}
+
+// JobCountByTx returns the number of jobs matching the provided filters
+// grouped by the given property within the specified transaction, keyed by the
+// property's value. Values without any matching jobs are omitted.
+//
+// Jobs are selected using the same filters as JobList, but pagination and
+// ordering options like First, After, and OrderBy are ignored so that every
+// matching job is counted.
+//
+//	countsByKind, err := client.JobCountByTx(ctx, tx, river.NewJobListParams().
+//		States(rivertype.JobStateDiscarded), river.JobCountGroupByKind)
+//	if err != nil {
+//		// handle error
+//	}
+func (c *Client[TTx]) JobCountByTx(ctx context.Context, tx TTx, params *JobListParams, groupBy JobCountGroupBy) (map[string]int, error) {
+	return c.jobCountBy(ctx, c.driver.UnwrapExecutor(tx), params, groupBy)
+}
+
+func (c *Client[TTx]) jobCountBy(ctx context.Context, exec riverdriver.Executor, params *JobListParams, groupBy JobCountGroupBy) (map[string]int, error) {
+	groupByExpr, err := groupBy.expr()
 	if err != nil {
-		return 0, err
+		return nil, err
 	}
-	defer tx.Rollback(ctx)
 
-	inserted, err := c.insertManyFast(ctx, tx, params)
+	dbParams, err := params.toCountDBParams()
 	if err != nil {
-		return 0, err
+		return nil, err
 	}
-	if err := tx.Commit(ctx); err != nil {
-		return 0, err
+
+	results, err := dblist.JobCountGrouped(ctx, exec, dbParams, groupByExpr)
+	if err != nil {
+		return nil, err
 	}
-	return inserted, nil
+
+	counts := make(map[string]int, len(results))
+	for _, result := range results {
+		counts[result.Value] = result.Count
+	}
+	return counts, nil
 }
 
-// InsertManyTx inserts many jobs at once using Postgres' `COPY FROM` mechanism,
-// making the operation quite fast and memory efficient. Each job is inserted as
-// an InsertManyParams tuple, which takes job args along with an optional set of
-// insert options, which override insert options provided by an
-// JobArgsWithInsertOpts.InsertOpts implementation or any client-level defaults.
-// The provided context is used for the underlying Postgres inserts and can be
-// used to cancel the operation or apply a timeout.
+// DeadLetterListResult is the result of a dead letter list operation.
+type DeadLetterListResult struct {
+	// DeadLetters is a slice of dead letters returned as part of the list
+	// operation.
+	DeadLetters []*rivertype.DeadLetter
+}
+
+// DeadLetterGet fetches a single dead letter by the ID of the job it was moved
+// from. Returns ErrNotFound if the dead letter doesn't exist.
 //
-//	count, err := client.InsertManyTx(ctx, tx, []river.InsertManyParams{
-//		{Args: BatchInsertArgs{}},
-//		{Args: BatchInsertArgs{}, InsertOpts: &river.InsertOpts{Priority: 3}},
-//	})
+// Dead letters are only produced when Config.DeadLetterEnabled is set.
+func (c *Client[TTx]) DeadLetterGet(ctx context.Context, id int64) (*rivertype.DeadLetter, error) {
+	return c.driver.GetExecutor().JobDeadLetterGetByID(ctx, &riverdriver.JobDeadLetterGetByIDParams{
+		ID:     id,
+		Schema: c.config.schema,
+	})
+}
+
+// DeadLetterGetTx fetches a single dead letter by the ID of the job it was
+// moved from, within a transaction. Returns ErrNotFound if the dead letter
+// doesn't exist.
+func (c *Client[TTx]) DeadLetterGetTx(ctx context.Context, tx TTx, id int64) (*rivertype.DeadLetter, error) {
+	return c.driver.UnwrapExecutor(tx).JobDeadLetterGetByID(ctx, &riverdriver.JobDeadLetterGetByIDParams{
+		ID:     id,
+		Schema: c.config.schema,
+	})
+}
+
+// DeadLetterList returns a paginated list of dead letters matching the
+// provided filters, ordered by ID. The provided context is used for the
+// underlying Postgres query and can be used to cancel the operation or apply a
+// timeout.
+//
+//	params := river.NewDeadLetterListParams().First(10).Kinds("email_send")
+//	res, err := client.DeadLetterList(ctx, params)
 //	if err != nil {
 //		// handle error
 //	}
+func (c *Client[TTx]) DeadLetterList(ctx context.Context, params *DeadLetterListParams) (*DeadLetterListResult, error) {
+	if !c.driver.HasPool() {
+		return nil, errNoDriverDBPool
+	}
+
+	return c.deadLetterList(ctx, c.driver.GetExecutor(), params)
+}
+
+// DeadLetterListTx returns a paginated list of dead letters matching the
+// provided filters, ordered by ID. The provided context is used for the
+// underlying Postgres query and can be used to cancel the operation or apply a
+// timeout.
 //
-// This variant lets a caller insert jobs atomically alongside other database
-// changes. An inserted job isn't visible to be worked until the transaction
-// commits, and if the transaction rolls back, so too is the inserted job.
-//
-// Unlike with `InsertManyTx`, unique conflicts cannot be handled gracefully. If
-// a unique constraint is violated, the operation will fail and no jobs will be
-// inserted.
-func (c *Client[TTx]) InsertManyFastTx(ctx context.Context, tx TTx, params []InsertManyParams) (int, error) {
-	exec := c.driver.UnwrapExecutor(tx)
-	return c.insertManyFast(ctx, exec, params)
+//	params := river.NewDeadLetterListParams().First(10).Kinds("email_send")
+//	res, err := client.DeadLetterListTx(ctx, tx, params)
+//	if err != nil {
+//		// handle error
+//	}
+func (c *Client[TTx]) DeadLetterListTx(ctx context.Context, tx TTx, params *DeadLetterListParams) (*DeadLetterListResult, error) {
+	return c.deadLetterList(ctx, c.driver.UnwrapExecutor(tx), params)
 }
 
-func (c *Client[TTx]) insertManyFast(ctx context.Context, tx riverdriver.ExecutorTx, params []InsertManyParams) (int, error) {
-	insertParams, err := c.insertManyParams(params)
-	if err != nil {
-		return 0, err
+func (c *Client[TTx]) deadLetterList(ctx context.Context, exec riverdriver.Executor, params *DeadLetterListParams) (*DeadLetterListResult, error) {
+	if params == nil {
+		params = NewDeadLetterListParams()
 	}
 
-	results, err := c.insertManyShared(ctx, tx, insertParams, func(ctx context.Context, insertParams []*riverdriver.JobInsertFastParams) ([]*rivertype.JobInsertResult, error) {
-		count, err := tx.JobInsertFastManyNoReturning(ctx, &riverdriver.JobInsertFastManyParams{
-			Jobs:   insertParams,
-			Schema: c.config.schema,
-		})
-		if err != nil {
-			return nil, err
-		}
-		return make([]*rivertype.JobInsertResult, count), nil
+	deadLetters, err := exec.JobDeadLetterList(ctx, &riverdriver.JobDeadLetterListParams{
+		AfterID: params.afterID,
+		Kind:    params.kinds,
+		Max:     int(params.paginationCount),
+		Queue:   params.queues,
+		Schema:  c.config.schema,
 	})
 	if err != nil {
-		return 0, err
+		return nil, err
 	}
 
-	return len(results), nil
+	return &DeadLetterListResult{DeadLetters: deadLetters}, nil
 }
 
-// Notify the given queues that new jobs are available. The queues list will be
-// deduplicated and each will be checked to see if it is due for an insert
-// notification from this client.
-func (c *Client[TTx]) maybeNotifyInsertForQueues(ctx context.Context, tx riverdriver.ExecutorTx, queues []string) error {
-	if len(queues) < 1 {
-		return nil
+// DeadLetterPurge permanently deletes dead letters matching the provided
+// filters, returning the number deleted. Params without any filters purge all
+// dead letters.
+//
+//	params := river.NewDeadLetterPurgeParams().DeadLetteredBefore(time.Now().Add(-30 * 24 * time.Hour))
+//	numPurged, err := client.DeadLetterPurge(ctx, params)
+//	if err != nil {
+//		// handle error
+//	}
+func (c *Client[TTx]) DeadLetterPurge(ctx context.Context, params *DeadLetterPurgeParams) (int, error) {
+	if !c.driver.HasPool() {
+		return 0, errNoDriverDBPool
 	}
 
-	queueMap := make(map[string]struct{})
-	queuesDeduped := make([]string, 0, len(queues))
-	payloads := make([]string, 0, len(queues))
+	return c.deadLetterPurge(ctx, c.driver.GetExecutor(), params)
+}
 
-	for _, queue := range queues {
-		if _, ok := queueMap[queue]; ok {
-			continue
-		}
+// DeadLetterPurgeTx permanently deletes dead letters matching the provided
+// filters, returning the number deleted. Params without any filters purge all
+// dead letters. This variant purges within a transaction so that dead letters
+// aren't deleted until the transaction commits.
+func (c *Client[TTx]) DeadLetterPurgeTx(ctx context.Context, tx TTx, params *DeadLetterPurgeParams) (int, error) {
+	return c.deadLetterPurge(ctx, c.driver.UnwrapExecutor(tx), params)
+}
 
-		queueMap[queue] = struct{}{}
-		if c.insertNotifyLimiter.ShouldTrigger(queue) {
-			payloads = append(payloads, fmt.Sprintf("{\"queue\": %q}", queue))
-			queuesDeduped = append(queuesDeduped, queue)
-		}
+func (c *Client[TTx]) deadLetterPurge(ctx context.Context, exec riverdriver.Executor, params *DeadLetterPurgeParams) (int, error) {
+	if params == nil {
+		params = NewDeadLetterPurgeParams()
 	}
 
-	if len(payloads) < 1 {
-		return nil
+	return exec.JobDeadLetterDeleteMany(ctx, &riverdriver.JobDeadLetterDeleteManyParams{
+		DeadLetteredBefore: params.deadLetteredBefore,
+		ID:                 params.ids,
+		Kind:               params.kinds,
+		Queue:              params.queues,
+		Schema:             c.config.schema,
+	})
+}
+
+// DeadLetterRequeue removes the dead letter with the given ID and inserts a
+// fresh job in its place, carrying over its kind, args, queue, priority, max
+// attempts, tags, and metadata. The new job gets a new ID, starts from zero
+// attempts, and is immediately available to be worked. Returns ErrNotFound if
+// the dead letter doesn't exist.
+//
+// Only user metadata is carried over. Keys that River sets as a job is worked,
+// like recorded output, progress, and checkpoints, leases, and sequence,
+// workflow, and batch membership, are removed so that the new job doesn't
+// inherit state from its previous run.
+//
+//	insertRes, err := client.DeadLetterRequeue(ctx, deadLetter.ID)
+//	if err != nil {
+//		// handle error
+//	}
+func (c *Client[TTx]) DeadLetterRequeue(ctx context.Context, id int64) (*rivertype.JobInsertResult, error) {
+	if !c.driver.HasPool() {
+		return nil, errNoDriverDBPool
+	}
+
+	tx, err := c.driver.GetExecutor().Begin(ctx)
+	if err != nil {
+		return nil, err
 	}
+	defer tx.Rollback(ctx)
 
-	err := tx.NotifyMany(ctx, &riverdriver.NotifyManyParams{
-		Payload: payloads,
-		Schema:  c.config.schema,
-		Topic:   string(notifier.NotificationTopicInsert),
-	})
+	insertRes, err := c.deadLetterRequeue(ctx, tx, id)
 	if err != nil {
-		c.baseService.Logger.ErrorContext(
-			ctx,
-			c.baseService.Name+": Failed to send job insert notification",
-			slog.String("queues", strings.Join(queuesDeduped, ",")),
-			slog.String("err", err.Error()),
-		)
-		return err
+		return nil, err
 	}
-	return nil
+
+	if err := tx.Commit(ctx); err != nil {
+		return nil, err
+	}
+	return insertRes, nil
 }
 
-// emit a notification about a queue being paused or resumed.
-func (c *Client[TTx]) notifyQueuePauseOrResume(ctx context.Context, tx riverdriver.ExecutorTx, action controlAction, queue string, opts *QueuePauseOpts) error {
-	c.baseService.Logger.DebugContext(ctx,
-		c.baseService.Name+": Notifying about queue state change",
-		slog.String("action", string(action)),
-		slog.String("queue", queue),
-		slog.String("opts", fmt.Sprintf("%+v", opts)),
-	)
+// DeadLetterRequeueTx removes the dead letter with the given ID and inserts a
+// fresh job in its place, carrying over its kind, args, queue, priority, max
+// attempts, tags, and user metadata. Returns ErrNotFound if the dead letter
+// doesn't exist.
+//
+// This variant requeues within a transaction. The dead letter isn't removed
+// and the new job isn't visible until the transaction commits, and if the
+// transaction rolls back, the dead letter is left in place.
+func (c *Client[TTx]) DeadLetterRequeueTx(ctx context.Context, tx TTx, id int64) (*rivertype.JobInsertResult, error) {
+	return c.deadLetterRequeue(ctx, c.driver.UnwrapExecutor(tx), id)
+}
 
-	payload, err := json.Marshal(controlEventPayload{Action: action, Queue: queue})
+func (c *Client[TTx]) deadLetterRequeue(ctx context.Context, tx riverdriver.ExecutorTx, id int64) (*rivertype.JobInsertResult, error) {
+	deadLetter, err := tx.JobDeadLetterDeleteByID(ctx, &riverdriver.JobDeadLetterDeleteByIDParams{
+		ID:     id,
+		Schema: c.config.schema,
+	})
 	if err != nil {
-		return err
+		return nil, err
 	}
 
-	err = tx.NotifyMany(ctx, &riverdriver.NotifyManyParams{
-		Payload: []string{string(payload)},
-		Schema:  c.config.schema,
-		Topic:   string(notifier.NotificationTopicControl),
-	})
+	metadata, err := deadLetterRequeueMetadata(deadLetter.Metadata)
 	if err != nil {
-		c.baseService.Logger.ErrorContext(
-			ctx,
-			c.baseService.Name+": Failed to send queue state change notification",
-			slog.String("err", err.Error()),
-		)
-		return err
+		return nil, err
 	}
-	return nil
-}
 
-// Validates job args prior to insertion. Currently, verifies that a worker to
-// handle the kind is registered in the configured workers bundle.
-// This validation is skipped if the client is configured as an insert-only (with no workers)
-// or if the client is configured to skip unknown job kinds.
-func (c *Client[TTx]) validateJobArgs(args JobArgs) error {
-	if c.config.Workers == nil || c.config.SkipUnknownJobCheck {
-		return nil
+	tags := deadLetter.Tags
+	if tags == nil {
+		tags = []string{}
 	}
 
-	if _, ok := c.config.Workers.workersMap[args.Kind()]; !ok {
-		return &UnknownJobKindError{Kind: args.Kind()}
+	results, err := c.insertMany(ctx, tx, []*rivertype.JobInsertParams{
+		{
+			Args:        &deadLetterArgs{encodedArgs: deadLetter.EncodedArgs, kind: deadLetter.Kind},
+			CreatedAt:   c.baseService.Time.NowUTCOrNil(),
+			EncodedArgs: deadLetter.EncodedArgs,
+			Kind:        deadLetter.Kind,
+			MaxAttempts: deadLetter.MaxAttempts,
+			Metadata:    metadata,
+			Priority:    deadLetter.Priority,
+			Queue:       deadLetter.Queue,
+			ScheduledAt: c.baseService.Time.NowUTCOrNil(),
+			State:       rivertype.JobStateAvailable,
+			Tags:        tags,
+		},
+	})
+	if err != nil {
+		return nil, err
 	}
 
-	return nil
+	return results[0], nil
 }
 
-func (c *Client[TTx]) addProducer(queueName string, queueConfig QueueConfig) *producer {
-	producer := newProducer(&c.baseService.Archetype, c.driver.GetExecutor(), c.pilot, &producerConfig{
-		ClientID:                     c.config.ID,
-		Completer:                    c.completer,
-		ErrorHandler:                 c.config.ErrorHandler,
-		FetchCooldown:                c.config.FetchCooldown,
-		FetchPollInterval:            c.config.FetchPollInterval,
-		HookLookupByJob:              c.hookLookupByJob,
-		HookLookupGlobal:             c.hookLookupGlobal,
-		JobTimeout:                   c.config.JobTimeout,
-		MaxWorkers:                   queueConfig.MaxWorkers,
-		MiddlewareLookupGlobal:       c.middlewareLookupGlobal,
-		Notifier:                     c.notifier,
-		Queue:                        queueName,
-		QueueEventCallback:           c.subscriptionManager.distributeQueueEvent,
-		RetryPolicy:                  c.config.RetryPolicy,
-		SchedulerInterval:            c.config.schedulerInterval,
-		Schema:                       c.config.schema,
-		StaleProducerRetentionPeriod: 5 * time.Minute,
-		Workers:                      c.config.Workers,
-	})
-	c.producersByQueueName[queueName] = producer
-	return producer
+// Metadata keys that River sets on a job as it's worked, but which don't start
+// with the "river:" prefix reserved for its other keys.
+var deadLetterRequeueUnprefixedRiverKeys = []string{ //nolint:gochecknoglobals
+	"cancel_attempted_at",
+	"snoozes",
+	rivertype.MetadataKeyCheckpoint,
+	rivertype.MetadataKeyOutput,
+	rivertype.MetadataKeyProgress,
 }
 
-func (c *Client[TTx]) removeProducer(queueName string) {
-	producer, ok := c.producersByQueueName[queueName]
-	if !ok {
-		return
+// Returns the metadata of a dead letter with keys owned by River removed,
+// leaving only user metadata for the job inserted when it's requeued. Keys like
+// output, leases, and cancellation, sequence, workflow, and batch state belong
+// to the previous run and would be stale on a new job. The partition key is
+// kept because it's derived from args, which are carried over unchanged.
+func deadLetterRequeueMetadata(metadata []byte) ([]byte, error) {
+	if len(metadata) < 1 {
+		return metadata, nil
 	}
 
-	producer.Stop()
-
-	delete(c.producersByQueueName, queueName)
-}
-
-var nameRegex = regexp.MustCompile(`^(?:[a-z0-9])+(?:[_|\-]?[a-z0-9]+)*$`)
-
-func validateQueueName(queueName string) error {
-	if queueName == "" {
-		return errors.New("queue name cannot be empty")
-	}
-	if len(queueName) > 64 {
-		return errors.New("queue name cannot be longer than 64 characters")
+	var metadataMap map[string]json.RawMessage
+	if err := json.Unmarshal(metadata, &metadataMap); err != nil {
+		return nil, fmt.Errorf("error unmarshaling dead letter metadata: %w", err)
 	}
-	if !nameRegex.MatchString(queueName) {
-		return fmt.Errorf("queue name is invalid, expected letters and numbers separated by underscores or hyphens: %q", queueName)
+
+	for key := range metadataMap {
+		if (strings.HasPrefix(key, "river:") && key != metadataKeyPartition) ||
+			slices.Contains(deadLetterRequeueUnprefixedRiverKeys, key) {
+			delete(metadataMap, key)
+		}
 	}
-	return nil
-}
 
-// JobListResult is the result of a job list operation. It contains a list of
-// jobs and a cursor for fetching the next page of results.
-type JobListResult struct {
-	// Jobs is a slice of job returned as part of the list operation.
-	Jobs []*rivertype.JobRow
+	return json.Marshal(metadataMap)
+}
 
-	// LastCursor is a cursor that can be used to list the next page of jobs.
-	LastCursor *JobListCursor
+// deadLetterArgs stands in for the args of a requeued dead letter, whose
+// concrete JobArgs type isn't known to the client.
+type deadLetterArgs struct {
+	encodedArgs []byte
+	kind        string
 }
 
-// JobList returns a paginated list of jobs matching the provided filters. The
-// provided context is used for the underlying Postgres query and can be used to
-// cancel the operation or apply a timeout.
+func (a *deadLetterArgs) Kind() string                 { return a.kind }
+func (a *deadLetterArgs) MarshalJSON() ([]byte, error) { return a.encodedArgs, nil }
+
+// BatchGet returns the progress of the batch with the given ID, including the
+// number of its jobs in each state. Returns ErrNotFound if no jobs exist for
+// the batch. The provided context is used for the underlying Postgres query and
+// can be used to cancel the operation or apply a timeout.
 //
-//	params := river.NewJobListParams().First(10).State(rivertype.JobStateCompleted)
-//	jobRows, err := client.JobList(ctx, params)
+//	status, err := client.BatchGet(ctx, batch.ID())
 //	if err != nil {
 //		// handle error
 //	}
-func (c *Client[TTx]) JobList(ctx context.Context, params *JobListParams) (*JobListResult, error) {
+func (c *Client[TTx]) BatchGet(ctx context.Context, batchID string) (*BatchStatus, error) {
 	if !c.driver.HasPool() {
 		return nil, errNoDriverDBPool
 	}
 
-	if params == nil {
-		params = NewJobListParams()
-	}
-	dbParams, err := params.toDBParams()
-	if err != nil {
-		return nil, err
+	return c.batchGet(ctx, c.driver.GetExecutor(), batchID)
+}
+
+// BatchGetTx returns the progress of the batch with the given ID, including
+// the number of its jobs in each state. Returns ErrNotFound if no jobs exist
+// for the batch. The provided context is used for the underlying Postgres query
+// and can be used to cancel the operation or apply a timeout.
+//
+//	status, err := client.BatchGetTx(ctx, tx, batch.ID())
+//	if err != nil {
+//		// handle error
+//	}
+func (c *Client[TTx]) BatchGetTx(ctx context.Context, tx TTx, batchID string) (*BatchStatus, error) {
+	return c.batchGet(ctx, c.driver.UnwrapExecutor(tx), batchID)
+}
+
+func (c *Client[TTx]) batchGet(ctx context.Context, exec riverdriver.Executor, batchID string) (*BatchStatus, error) {
+	if batchID == "" {
+		return nil, errors.New("batch ID must not be empty")
 	}
 
-	jobs, err := dblist.JobList(ctx, c.driver.GetExecutor(), dbParams)
+	countsByState, err := exec.JobBatchCountByState(ctx, &riverdriver.JobBatchCountByStateParams{
+		BatchID: batchID,
+		Schema:  c.config.schema,
+	})
 	if err != nil {
 		return nil, err
 	}
-	res := &JobListResult{Jobs: jobs}
-	if len(jobs) > 0 {
-		res.LastCursor = jobListCursorFromJobAndParams(jobs[len(jobs)-1], params)
+	if len(countsByState) < 1 {
+		return nil, ErrNotFound
 	}
-	return res, nil
+
+	return batchStatusFromCounts(batchID, countsByState), nil
 }
 
-// JobListTx returns a paginated list of jobs matching the provided filters. The
-// provided context is used for the underlying Postgres query and can be used to
-// cancel the operation or apply a timeout.
+// WorkflowTaskList returns all tasks of the workflow with the given ID, along
+// with their current job rows, ordered by job ID. The provided context is used
+// for the underlying Postgres queries and can be used to cancel the operation
+// or apply a timeout.
 //
-//	params := river.NewJobListParams().First(10).States(river.JobStateCompleted)
-//	jobRows, err := client.JobListTx(ctx, tx, params)
+//	tasks, err := client.WorkflowTaskList(ctx, workflow.ID())
 //	if err != nil {
 //		// handle error
 //	}
-func (c *Client[TTx]) JobListTx(ctx context.Context, tx TTx, params *JobListParams) (*JobListResult, error) {
-	if params == nil {
-		params = NewJobListParams()
+func (c *Client[TTx]) WorkflowTaskList(ctx context.Context, workflowID string) ([]*WorkflowTask, error) {
+	if !c.driver.HasPool() {
+		return nil, errNoDriverDBPool
 	}
 
-	dbParams, err := params.toDBParams()
-	if err != nil {
-		return nil, err
+	return c.workflowTaskList(ctx, c.driver.GetExecutor(), workflowID)
+}
+
+// WorkflowTaskListTx returns all tasks of the workflow with the given ID, along
+// with their current job rows, ordered by job ID. The provided context is used
+// for the underlying Postgres queries and can be used to cancel the operation
+// or apply a timeout.
+//
+//	tasks, err := client.WorkflowTaskListTx(ctx, tx, workflow.ID())
+//	if err != nil {
+//		// handle error
+//	}
+func (c *Client[TTx]) WorkflowTaskListTx(ctx context.Context, tx TTx, workflowID string) ([]*WorkflowTask, error) {
+	return c.workflowTaskList(ctx, c.driver.UnwrapExecutor(tx), workflowID)
+}
+
+func (c *Client[TTx]) workflowTaskList(ctx context.Context, exec riverdriver.Executor, workflowID string) ([]*WorkflowTask, error) {
+	if workflowID == "" {
+		return nil, errors.New("workflow ID must not be empty")
 	}
 
-	jobs, err := dblist.JobList(ctx, c.driver.UnwrapExecutor(tx), dbParams)
+	metadataFragment, err := json.Marshal(map[string]string{metadataKeyWorkflowID: workflowID})
 	if err != nil {
 		return nil, err
 	}
-	res := &JobListResult{Jobs: jobs}
-	if len(jobs) > 0 {
-		res.LastCursor = jobListCursorFromJobAndParams(jobs[len(jobs)-1], params)
+
+	params := NewJobListParams().
+		First(c.jobManyBatchSize).
+		Metadata(string(metadataFragment)).
+		OrderBy(JobListOrderByID, SortOrderAsc)
+
+	var tasks []*WorkflowTask
+	for {
+		dbParams, err := params.toDBParams()
+		if err != nil {
+			return nil, err
+		}
+
+		jobs, err := dblist.JobList(ctx, exec, dbParams)
+		if err != nil {
+			return nil, err
+		}
+
+		for _, job := range jobs {
+			task, err := workflowTaskFromJobRow(job)
+			if err != nil {
+				return nil, err
+			}
+			tasks = append(tasks, task)
+		}
+
+		if len(jobs) < c.jobManyBatchSize {
+			break
+		}
+
+		params = params.After(jobListCursorFromJobAndParams(jobs[len(jobs)-1], params))
 	}
-	return res, nil
+
+	return tasks, nil
 }
 
 // PeriodicJobs returns the currently configured set of periodic jobs for the
@@ -2113,6 +3551,168 @@ func (c *Client[TTx]) QueueListTx(ctx context.Context, tx TTx, params *QueueList
 	return &QueueListResult{Queues: queues}, nil
 }
 
+// The window over which QueueStats counts finalized jobs and measures
+// completion throughput.
+const queueStatsThroughputWindow = 5 * time.Minute
+
+// Job states counted in QueueStats.CountsByState.
+var queueStatsUnfinalizedStates = []rivertype.JobState{ //nolint:gochecknoglobals
+	rivertype.JobStateAvailable,
+	rivertype.JobStatePending,
+	rivertype.JobStateRetryable,
+	rivertype.JobStateRunning,
+	rivertype.JobStateScheduled,
+}
+
+// QueueStats contains statistics about the jobs in a queue, as returned by
+// Client.QueueStats and Client.QueueStatsByKind.
+type QueueStats struct {
+	// CancelledCount is the number of jobs cancelled within ThroughputWindow.
+	CancelledCount int
+
+	// CompletedCount is the number of jobs completed within ThroughputWindow.
+	CompletedCount int
+
+	// CompletedPerSecond is the average rate at which jobs were completed
+	// over ThroughputWindow.
+	CompletedPerSecond float64
+
+	// CountsByState contains the number of jobs in each unfinalized state
+	// (available, pending, retryable, running, and scheduled). Every
+	// unfinalized state is present, including those without any jobs.
+	// Finalized jobs are instead counted over ThroughputWindow in
+	// CancelledCount, CompletedCount, and DiscardedCount.
+	CountsByState map[rivertype.JobState]int
+
+	// DiscardedCount is the number of jobs discarded within ThroughputWindow.
+	DiscardedCount int
+
+	// Kind is the job kind that statistics were limited to, or empty if they
+	// include all kinds.
+	Kind string
+
+	// OldestAvailableAge is the amount of time that the oldest available job
+	// has been waiting to be worked, measured from when it was scheduled. Zero
+	// if there are no available jobs.
+	OldestAvailableAge time.Duration
+
+	// Queue is the name of the queue.
+	Queue string
+
+	// RunningByClient contains the number of jobs being worked by the ID of
+	// each client working them. Clients not working any jobs in the queue are
+	// omitted.
+	RunningByClient map[string]int
+
+	// ThroughputWindow is the window of time over which CancelledCount,
+	// CompletedCount, CompletedPerSecond, and DiscardedCount are measured,
+	// which is currently five minutes.
+	ThroughputWindow time.Duration
+}
+
+// QueueStats returns statistics about the jobs in the queue with the given
+// name, including counts of unfinalized jobs by state, the age of the oldest
+// available job, the number of running jobs by client, and counts of jobs
+// recently finalized. Statistics are computed from the jobs table rather than
+// the queues table, so they're available for any queue that has jobs,
+// including ones that aren't being worked, and are zero for a queue without
+// any jobs.
+//
+// Statistics are computed in a single statement so they're consistent with
+// each other. Only unfinalized jobs and jobs finalized within
+// ThroughputWindow are counted, so the cost of computing them doesn't grow
+// with the number of finalized jobs retained.
+//
+// The provided context is used for the underlying Postgres queries and can be
+// used to cancel the operation or apply a timeout.
+func (c *Client[TTx]) QueueStats(ctx context.Context, name string) (*QueueStats, error) {
+	stats, err := c.queueStats(ctx, []string{name}, "")
+	if err != nil {
+		return nil, err
+	}
+
+	return stats[0], nil
+}
+
+// QueueStatsByKind returns statistics like QueueStats, but limited to jobs of
+// the given kind in the queue with the given name.
+//
+// The provided context is used for the underlying Postgres queries and can be
+// used to cancel the operation or apply a timeout.
+func (c *Client[TTx]) QueueStatsByKind(ctx context.Context, name, kind string) (*QueueStats, error) {
+	if kind == "" {
+		return nil, errors.New("kind must not be empty")
+	}
+
+	stats, err := c.queueStats(ctx, []string{name}, kind)
+	if err != nil {
+		return nil, err
+	}
+
+	return stats[0], nil
+}
+
+// QueueStatsMany returns statistics like QueueStats for each of the queues with
+// the given names, ordered by name. Statistics for all queues are computed in
+// a single statement, so this is much cheaper than calling QueueStats for each
+// queue when statistics are needed for many of them.
+//
+// The provided context is used for the underlying Postgres queries and can be
+// used to cancel the operation or apply a timeout.
+func (c *Client[TTx]) QueueStatsMany(ctx context.Context, names []string) ([]*QueueStats, error) {
+	if len(names) < 1 {
+		return []*QueueStats{}, nil
+	}
+
+	return c.queueStats(ctx, names, "")
+}
+
+func (c *Client[TTx]) queueStats(ctx context.Context, names []string, kind string) ([]*QueueStats, error) {
+	if !c.driver.HasPool() {
+		return nil, errNoDriverDBPool
+	}
+
+	now := c.baseService.Time.NowUTC()
+
+	results, err := c.driver.GetExecutor().JobStats(ctx, &riverdriver.JobStatsParams{
+		FinalizedSince: now.Add(-queueStatsThroughputWindow),
+		Kind:           kind,
+		Queues:         names,
+		Schema:         c.config.schema,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return sliceutil.Map(results, func(result *riverdriver.JobStatsResult) *QueueStats {
+		completedCount := result.FinalizedCountsByState[rivertype.JobStateCompleted]
+
+		stats := &QueueStats{
+			CancelledCount:     result.FinalizedCountsByState[rivertype.JobStateCancelled],
+			CompletedCount:     completedCount,
+			CompletedPerSecond: float64(completedCount) / queueStatsThroughputWindow.Seconds(),
+			CountsByState:      make(map[rivertype.JobState]int, len(queueStatsUnfinalizedStates)),
+			DiscardedCount:     result.FinalizedCountsByState[rivertype.JobStateDiscarded],
+			Kind:               kind,
+			Queue:              result.Queue,
+			RunningByClient:    result.RunningByClient,
+			ThroughputWindow:   queueStatsThroughputWindow,
+		}
+
+		for _, state := range queueStatsUnfinalizedStates {
+			stats.CountsByState[state] = result.CountsByState[state]
+		}
+
+		// Jobs may be scheduled slightly in the future relative to this
+		// client's clock, so don't allow a negative age.
+		if result.OldestAvailableAt != nil {
+			stats.OldestAvailableAge = max(now.Sub(*result.OldestAvailableAt), 0)
+		}
+
+		return stats
+	}), nil
+}
+
 // QueuePause pauses the queue with the given name. When a queue is paused,
 // clients will not fetch any more jobs for that particular queue. To pause all
 // queues at once, use the special queue name "*".
@@ -2237,8 +3837,18 @@ func (c *Client[TTx]) QueueResumeTx(ctx context.Context, tx TTx, name string, op
 
 // QueueUpdateParams are the parameters for a QueueUpdate operation.
 type QueueUpdateParams struct {
+	// Concurrency overrides the concurrency limits configured for the queue
+	// through QueueConfig.Concurrency in every client working it, taking effect
+	// as soon as each client is notified of the change. If nil, any existing
+	// override is left unchanged. An empty ConcurrencyConfig removes an
+	// existing override so that clients go back to their own configuration.
+	//
+	// The override is stored in the queue's metadata.
+	Concurrency *ConcurrencyConfig
+
 	// Metadata is the new metadata for the queue. If nil or empty, the metadata
-	// will not be changed.
+	// will not be changed. A concurrency override previously set through
+	// Concurrency is preserved.
 	Metadata []byte
 }
 
@@ -2272,12 +3882,44 @@ func (c *Client[TTx]) QueueUpdateTx(ctx context.Context, tx TTx, name string, pa
 }
 
 func (c *Client[TTx]) queueUpdate(ctx context.Context, executorTx riverdriver.ExecutorTx, name string, params *QueueUpdateParams) (*rivertype.Queue, error) {
-	updateMetadata := len(params.Metadata) > 0
+	if params.Concurrency != nil {
+		if err := params.Concurrency.validate(name); err != nil {
+			return nil, err
+		}
+	}
+
+	var (
+		metadata       = params.Metadata
+		updateMetadata = len(params.Metadata) > 0 || params.Concurrency != nil
+	)
+	if updateMetadata {
+		existingQueue, err := executorTx.QueueGet(ctx, &riverdriver.QueueGetParams{
+			Name:   name,
+			Schema: c.config.schema,
+		})
+		if err != nil {
+			return nil, err
+		}
+
+		if len(metadata) < 1 {
+			metadata = existingQueue.Metadata
+		}
+
+		if params.Concurrency != nil {
+			metadata, err = queueMetadataWithConcurrency(metadata, params.Concurrency)
+		} else {
+			metadata, err = queueMetadataCarryConcurrency(existingQueue.Metadata, metadata)
+		}
+		if err != nil {
+			return nil, err
+		}
+	}
 
 	queue, err := executorTx.QueueUpdate(ctx, &riverdriver.QueueUpdateParams{
-		Metadata:         params.Metadata,
+		Metadata:         metadata,
 		MetadataDoUpdate: updateMetadata,
 		Name:             name,
+		Schema:           c.config.schema,
 	})
 	if err != nil {
 		return nil, err
@@ -2286,7 +3928,7 @@ func (c *Client[TTx]) queueUpdate(ctx context.Context, executorTx riverdriver.Ex
 	if updateMetadata {
 		payload, err := json.Marshal(controlEventPayload{
 			Action:   controlActionMetadataChanged,
-			Metadata: params.Metadata,
+			Metadata: metadata,
 			Queue:    queue.Name,
 		})
 		if err != nil {
@@ -2311,9 +3953,13 @@ type QueueBundle struct {
 	// Function that adds a producer to the associated client.
 	addProducer func(queueName string, queueConfig QueueConfig) *producer
 
-	removeProducer        func(queueName string)
+	removeProducer        func(queueName string) bool
 	clientWillExecuteJobs bool
 
+	// Function that distributes an event to the associated client's
+	// subscriptions.
+	distributeEvent func(event *Event)
+
 	fetchCtx context.Context //nolint:containedctx
 
 	// Mutex that's acquired when client is starting and stopping and when a
@@ -2351,14 +3997,20 @@ func (b *QueueBundle) Add(queueName string, queueConfig QueueConfig) error {
 		}
 	}
 
+	b.distributeEvent(&Event{Kind: EventKindQueueAdded, Queue: &rivertype.Queue{Name: queueName}})
+
 	return nil
 }
 
+// Remove removes a queue from the client, stopping its producer if the client
+// is started. Removing a queue that the client doesn't have is a no-op.
 func (b *QueueBundle) Remove(queueName string) error {
 	b.startStopMu.Lock()
 	defer b.startStopMu.Unlock()
 
-	b.removeProducer(queueName)
+	if b.removeProducer(queueName) {
+		b.distributeEvent(&Event{Kind: EventKindQueueRemoved, Queue: &rivertype.Queue{Name: queueName}})
+	}
 
 	return nil
 }
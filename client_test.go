@@ -21,6 +21,7 @@ import (
 	"github.com/jackc/pgx/v5/stdlib"
 	"github.com/robfig/cron/v3"
 	"github.com/stretchr/testify/require"
+	"github.com/tidwall/gjson"
 	"github.com/tidwall/sjson"
 
 	"github.com/riverqueue/river/internal/dbunique"
@@ -766,6 +767,112 @@ func Test_Client(t *testing.T) {
 		require.Equal(t, "called", metadataMap["work_begin_hook"])
 	})
 
+	t.Run("WithGlobalInsertEndHook", func(t *testing.T) {
+		t.Parallel()
+
+		_, bundle := setup(t)
+
+		var insertEndResults []*rivertype.JobInsertResult
+
+		bundle.config.Hooks = []rivertype.Hook{
+			HookInsertEndFunc(func(ctx context.Context, params *rivertype.JobInsertParams, result *rivertype.JobInsertResult) error {
+				insertEndResults = append(insertEndResults, result)
+				return nil
+			}),
+		}
+
+		AddWorker(bundle.config.Workers, WorkFunc(func(ctx context.Context, job *Job[callbackArgs]) error {
+			return nil
+		}))
+
+		client, err := NewClient(riverpgxv5.New(bundle.dbPool), bundle.config)
+		require.NoError(t, err)
+
+		insertOpts := &InsertOpts{UniqueOpts: UniqueOpts{ByArgs: true}}
+
+		insertRes1, err := client.Insert(ctx, callbackArgs{}, insertOpts)
+		require.NoError(t, err)
+		insertRes2, err := client.Insert(ctx, callbackArgs{}, insertOpts)
+		require.NoError(t, err)
+
+		require.Len(t, insertEndResults, 2)
+		require.Equal(t, insertRes1.Job.ID, insertEndResults[0].Job.ID)
+		require.False(t, insertEndResults[0].UniqueSkippedAsDuplicate)
+		require.Equal(t, insertRes2.Job.ID, insertEndResults[1].Job.ID)
+		require.True(t, insertEndResults[1].UniqueSkippedAsDuplicate)
+	})
+
+	t.Run("InsertEndHookErrorAbortsInsert", func(t *testing.T) {
+		t.Parallel()
+
+		_, bundle := setup(t)
+
+		bundle.config.Hooks = []rivertype.Hook{
+			HookInsertEndFunc(func(ctx context.Context, params *rivertype.JobInsertParams, result *rivertype.JobInsertResult) error {
+				return errors.New("insert end hook error")
+			}),
+		}
+
+		AddWorker(bundle.config.Workers, WorkFunc(func(ctx context.Context, job *Job[callbackArgs]) error {
+			return nil
+		}))
+
+		client, err := NewClient(riverpgxv5.New(bundle.dbPool), bundle.config)
+		require.NoError(t, err)
+
+		_, err = client.Insert(ctx, callbackArgs{}, nil)
+		require.EqualError(t, err, "insert end hook error")
+
+		jobs, err := client.JobList(ctx, NewJobListParams())
+		require.NoError(t, err)
+		require.Empty(t, jobs.Jobs)
+	})
+
+	t.Run("WithGlobalWorkEndAndJobStateChangeHooks", func(t *testing.T) {
+		t.Parallel()
+
+		_, bundle := setup(t)
+
+		var (
+			jobStateChangeChan = make(chan *rivertype.JobRow, 10)
+			workEndChan        = make(chan *rivertype.JobWorkResult, 10)
+		)
+
+		bundle.config.Hooks = []rivertype.Hook{
+			HookJobStateChangeFunc(func(ctx context.Context, job *rivertype.JobRow) {
+				jobStateChangeChan <- job
+			}),
+			HookWorkEndFunc(func(ctx context.Context, job *rivertype.JobRow, result *rivertype.JobWorkResult) {
+				workEndChan <- result
+			}),
+		}
+
+		AddWorker(bundle.config.Workers, WorkFunc(func(ctx context.Context, job *Job[callbackArgs]) error {
+			return errors.New("job error")
+		}))
+
+		client, err := NewClient(riverpgxv5.New(bundle.dbPool), bundle.config)
+		require.NoError(t, err)
+
+		subscribeChan := subscribe(t, client)
+		startClient(ctx, t, client)
+
+		insertRes, err := client.Insert(ctx, callbackArgs{}, &InsertOpts{MaxAttempts: 1})
+		require.NoError(t, err)
+
+		event := riversharedtest.WaitOrTimeout(t, subscribeChan)
+		require.Equal(t, EventKindJobFailed, event.Kind)
+
+		workEndRes := riversharedtest.WaitOrTimeout(t, workEndChan)
+		require.EqualError(t, workEndRes.Err, "job error")
+		require.Nil(t, workEndRes.PanicVal)
+		require.Equal(t, rivertype.JobStateDiscarded, workEndRes.State)
+
+		stateChangeJob := riversharedtest.WaitOrTimeout(t, jobStateChangeChan)
+		require.Equal(t, insertRes.Job.ID, stateChangeJob.ID)
+		require.Equal(t, rivertype.JobStateDiscarded, stateChangeJob.State)
+	})
+
 	t.Run("WithGlobalWorkerMiddleware", func(t *testing.T) {
 		t.Parallel()
 
@@ -1708,6 +1815,251 @@ func Test_Client_JobDelete(t *testing.T) {
 	})
 }
 
+func Test_Client_JobCancelMany(t *testing.T) {
+	t.Parallel()
+
+	ctx := context.Background()
+
+	type testBundle struct {
+		dbPool *pgxpool.Pool
+		exec   riverdriver.Executor
+	}
+
+	setup := func(t *testing.T) (*Client[pgx.Tx], *testBundle) {
+		t.Helper()
+
+		dbPool := riverinternaltest.TestDB(ctx, t)
+		config := newTestConfig(t, nil)
+		client := newTestClient(t, dbPool, config)
+
+		return client, &testBundle{
+			dbPool: dbPool,
+			exec:   client.driver.GetExecutor(),
+		}
+	}
+
+	t.Run("CancelsJobsMatchingFilters", func(t *testing.T) {
+		t.Parallel()
+
+		client, bundle := setup(t)
+
+		job1 := testfactory.Job(ctx, t, bundle.exec, &testfactory.JobOpts{Kind: ptrutil.Ptr("kind_1")})
+		job2 := testfactory.Job(ctx, t, bundle.exec, &testfactory.JobOpts{Kind: ptrutil.Ptr("kind_1"), State: ptrutil.Ptr(rivertype.JobStateRunning)})
+		job3 := testfactory.Job(ctx, t, bundle.exec, &testfactory.JobOpts{Kind: ptrutil.Ptr("kind_1"), FinalizedAt: ptrutil.Ptr(time.Now()), State: ptrutil.Ptr(rivertype.JobStateCompleted)})
+		job4 := testfactory.Job(ctx, t, bundle.exec, &testfactory.JobOpts{Kind: ptrutil.Ptr("kind_2")})
+
+		res, err := client.JobCancelMany(ctx, NewJobListParams().Kinds("kind_1"), nil)
+		require.NoError(t, err)
+		require.Equal(t, 2, res.NumCancelled)
+		require.Equal(t, 1, res.NumSkipped)
+		require.Equal(t, []int64{job1.ID, job2.ID}, sliceutil.Map(res.Jobs, func(j *rivertype.JobRow) int64 { return j.ID }))
+		require.Equal(t, rivertype.JobStateCancelled, res.Jobs[0].State)
+		require.Equal(t, rivertype.JobStateRunning, res.Jobs[1].State) // only marked for cancellation
+
+		job3Updated, err := client.JobGet(ctx, job3.ID)
+		require.NoError(t, err)
+		require.Equal(t, rivertype.JobStateCompleted, job3Updated.State)
+
+		job4Updated, err := client.JobGet(ctx, job4.ID)
+		require.NoError(t, err)
+		require.Equal(t, rivertype.JobStateAvailable, job4Updated.State)
+	})
+
+	t.Run("CancelsJobsByID", func(t *testing.T) {
+		t.Parallel()
+
+		client, bundle := setup(t)
+
+		job1 := testfactory.Job(ctx, t, bundle.exec, &testfactory.JobOpts{})
+		job2 := testfactory.Job(ctx, t, bundle.exec, &testfactory.JobOpts{})
+		job3 := testfactory.Job(ctx, t, bundle.exec, &testfactory.JobOpts{})
+
+		res, err := client.JobCancelMany(ctx, NewJobListParams().IDs(job1.ID, job3.ID), nil)
+		require.NoError(t, err)
+		require.Equal(t, []int64{job1.ID, job3.ID}, sliceutil.Map(res.Jobs, func(j *rivertype.JobRow) int64 { return j.ID }))
+
+		job2Updated, err := client.JobGet(ctx, job2.ID)
+		require.NoError(t, err)
+		require.Equal(t, rivertype.JobStateAvailable, job2Updated.State)
+	})
+
+	t.Run("OperatesInBatches", func(t *testing.T) {
+		t.Parallel()
+
+		client, bundle := setup(t)
+		client.jobManyBatchSize = 2
+
+		jobs := make([]*rivertype.JobRow, 5)
+		for i := range jobs {
+			jobs[i] = testfactory.Job(ctx, t, bundle.exec, &testfactory.JobOpts{})
+		}
+
+		// Pagination count is ignored in favor of visiting all matching jobs.
+		res, err := client.JobCancelMany(ctx, NewJobListParams().First(1), nil)
+		require.NoError(t, err)
+		require.Equal(t, 5, res.NumCancelled)
+		require.Equal(t,
+			sliceutil.Map(jobs, func(j *rivertype.JobRow) int64 { return j.ID }),
+			sliceutil.Map(res.Jobs, func(j *rivertype.JobRow) int64 { return j.ID }),
+		)
+	})
+
+	t.Run("TxVariant", func(t *testing.T) {
+		t.Parallel()
+
+		client, bundle := setup(t)
+
+		job := testfactory.Job(ctx, t, bundle.exec, &testfactory.JobOpts{})
+
+		tx, err := bundle.dbPool.Begin(ctx)
+		require.NoError(t, err)
+		t.Cleanup(func() { tx.Rollback(ctx) })
+
+		res, err := client.JobCancelManyTx(ctx, tx, NewJobListParams().IDs(job.ID), nil)
+		require.NoError(t, err)
+		require.Equal(t, 1, res.NumCancelled)
+
+		// Not visible outside the transaction.
+		jobUpdated, err := client.JobGet(ctx, job.ID)
+		require.NoError(t, err)
+		require.Equal(t, rivertype.JobStateAvailable, jobUpdated.State)
+
+		jobUpdated, err = client.JobGetTx(ctx, tx, job.ID)
+		require.NoError(t, err)
+		require.Equal(t, rivertype.JobStateCancelled, jobUpdated.State)
+	})
+}
+
+func Test_Client_JobDeleteMany(t *testing.T) {
+	t.Parallel()
+
+	ctx := context.Background()
+
+	type testBundle struct {
+		dbPool *pgxpool.Pool
+		exec   riverdriver.Executor
+	}
+
+	setup := func(t *testing.T) (*Client[pgx.Tx], *testBundle) {
+		t.Helper()
+
+		dbPool := riverinternaltest.TestDB(ctx, t)
+		config := newTestConfig(t, nil)
+		client := newTestClient(t, dbPool, config)
+
+		return client, &testBundle{
+			dbPool: dbPool,
+			exec:   client.driver.GetExecutor(),
+		}
+	}
+
+	t.Run("DeletesJobsMatchingFiltersExceptRunning", func(t *testing.T) {
+		t.Parallel()
+
+		client, bundle := setup(t)
+
+		job1 := testfactory.Job(ctx, t, bundle.exec, &testfactory.JobOpts{Queue: ptrutil.Ptr("queue_1")})
+		job2 := testfactory.Job(ctx, t, bundle.exec, &testfactory.JobOpts{Queue: ptrutil.Ptr("queue_1"), FinalizedAt: ptrutil.Ptr(time.Now()), State: ptrutil.Ptr(rivertype.JobStateDiscarded)})
+		job3 := testfactory.Job(ctx, t, bundle.exec, &testfactory.JobOpts{Queue: ptrutil.Ptr("queue_1"), State: ptrutil.Ptr(rivertype.JobStateRunning)})
+		job4 := testfactory.Job(ctx, t, bundle.exec, &testfactory.JobOpts{Queue: ptrutil.Ptr("queue_2")})
+
+		res, err := client.JobDeleteMany(ctx, NewJobListParams().Queues("queue_1"), nil)
+		require.NoError(t, err)
+		require.Equal(t, 2, res.NumDeleted)
+		require.Equal(t, 1, res.NumSkipped)
+		require.Equal(t, []int64{job1.ID, job2.ID}, sliceutil.Map(res.Jobs, func(j *rivertype.JobRow) int64 { return j.ID }))
+
+		_, err = client.JobGet(ctx, job1.ID)
+		require.ErrorIs(t, err, ErrNotFound)
+		_, err = client.JobGet(ctx, job2.ID)
+		require.ErrorIs(t, err, ErrNotFound)
+
+		_, err = client.JobGet(ctx, job3.ID)
+		require.NoError(t, err)
+		_, err = client.JobGet(ctx, job4.ID)
+		require.NoError(t, err)
+	})
+
+	t.Run("DeletesJobsByMetadataAndState", func(t *testing.T) {
+		t.Parallel()
+
+		client, bundle := setup(t)
+
+		job1 := testfactory.Job(ctx, t, bundle.exec, &testfactory.JobOpts{Metadata: []byte(`{"incident": 123}`), FinalizedAt: ptrutil.Ptr(time.Now()), State: ptrutil.Ptr(rivertype.JobStateDiscarded)})
+		job2 := testfactory.Job(ctx, t, bundle.exec, &testfactory.JobOpts{Metadata: []byte(`{"incident": 123}`)})
+		job3 := testfactory.Job(ctx, t, bundle.exec, &testfactory.JobOpts{FinalizedAt: ptrutil.Ptr(time.Now()), State: ptrutil.Ptr(rivertype.JobStateDiscarded)})
+
+		res, err := client.JobDeleteMany(ctx, NewJobListParams().Metadata(`{"incident": 123}`).States(rivertype.JobStateDiscarded), nil)
+		require.NoError(t, err)
+		require.Equal(t, []int64{job1.ID}, sliceutil.Map(res.Jobs, func(j *rivertype.JobRow) int64 { return j.ID }))
+
+		_, err = client.JobGet(ctx, job2.ID)
+		require.NoError(t, err)
+		_, err = client.JobGet(ctx, job3.ID)
+		require.NoError(t, err)
+	})
+
+	t.Run("OperatesInBatches", func(t *testing.T) {
+		t.Parallel()
+
+		client, bundle := setup(t)
+		client.jobManyBatchSize = 2
+
+		for range 5 {
+			_ = testfactory.Job(ctx, t, bundle.exec, &testfactory.JobOpts{})
+		}
+
+		res, err := client.JobDeleteMany(ctx, nil, nil)
+		require.NoError(t, err)
+		require.Equal(t, 5, res.NumDeleted)
+
+		listRes, err := client.JobList(ctx, nil)
+		require.NoError(t, err)
+		require.Empty(t, listRes.Jobs)
+	})
+
+	t.Run("CountOnly", func(t *testing.T) {
+		t.Parallel()
+
+		client, bundle := setup(t)
+		client.jobManyBatchSize = 2
+
+		for range 5 {
+			_ = testfactory.Job(ctx, t, bundle.exec, &testfactory.JobOpts{})
+		}
+		_ = testfactory.Job(ctx, t, bundle.exec, &testfactory.JobOpts{State: ptrutil.Ptr(rivertype.JobStateRunning)})
+
+		res, err := client.JobDeleteMany(ctx, nil, &JobManyOpts{CountOnly: true})
+		require.NoError(t, err)
+		require.Empty(t, res.Jobs)
+		require.Equal(t, 5, res.NumDeleted)
+		require.Equal(t, 1, res.NumSkipped)
+	})
+
+	t.Run("TxVariant", func(t *testing.T) {
+		t.Parallel()
+
+		client, bundle := setup(t)
+
+		job := testfactory.Job(ctx, t, bundle.exec, &testfactory.JobOpts{})
+
+		tx, err := bundle.dbPool.Begin(ctx)
+		require.NoError(t, err)
+		t.Cleanup(func() { tx.Rollback(ctx) })
+
+		res, err := client.JobDeleteManyTx(ctx, tx, NewJobListParams().IDs(job.ID), nil)
+		require.NoError(t, err)
+		require.Equal(t, 1, res.NumDeleted)
+
+		// Still visible outside the transaction.
+		_, err = client.JobGet(ctx, job.ID)
+		require.NoError(t, err)
+
+		_, err = client.JobGetTx(ctx, tx, job.ID)
+		require.ErrorIs(t, err, ErrNotFound)
+	})
+}
+
 func Test_Client_Insert(t *testing.T) {
 	t.Parallel()
 
@@ -2987,6 +3339,24 @@ func Test_Client_InsertManyTx(t *testing.T) {
 		require.Nil(t, results)
 	})
 
+	t.Run("ErrorsOnMissingWorkflowDepJobID", func(t *testing.T) {
+		t.Parallel()
+
+		client, bundle := setup(t)
+
+		depResult, err := client.InsertTx(ctx, bundle.tx, noOpArgs{}, nil)
+		require.NoError(t, err)
+
+		params, err := NewWorkflow(nil).
+			Add("a", noOpArgs{}, nil, &WorkflowTaskOpts{DepJobIDs: []int64{depResult.Job.ID, depResult.Job.ID + 1_000_000}}).
+			Prepare()
+		require.NoError(t, err)
+
+		results, err := client.InsertManyTx(ctx, bundle.tx, params)
+		require.EqualError(t, err, fmt.Sprintf("workflow task depends on job %d, which doesn't exist", depResult.Job.ID+1_000_000))
+		require.Nil(t, results)
+	})
+
 	t.Run("ErrorsOnUnknownJobKindWithWorkers", func(t *testing.T) {
 		t.Parallel()
 
@@ -3046,12 +3416,14 @@ func Test_Client_InsertManyTx(t *testing.T) {
 	})
 }
 
-func Test_Client_JobGet(t *testing.T) {
+func Test_Client_DeadLetter(t *testing.T) {
 	t.Parallel()
 
 	ctx := context.Background()
 
-	type testBundle struct{}
+	type testBundle struct {
+		exec riverdriver.Executor
+	}
 
 	setup := func(t *testing.T) (*Client[pgx.Tx], *testBundle) {
 		t.Helper()
@@ -3060,21 +3432,367 @@ func Test_Client_JobGet(t *testing.T) {
 		config := newTestConfig(t, nil)
 		client := newTestClient(t, dbPool, config)
 
-		return client, &testBundle{}
+		return client, &testBundle{exec: client.driver.GetExecutor()}
 	}
 
-	t.Run("FetchesAnExistingJob", func(t *testing.T) {
-		t.Parallel()
+	// Inserts a discarded job and moves it to the dead letter table.
+	deadLetterJob := func(t *testing.T, bundle *testBundle, opts *testfactory.JobOpts) *rivertype.JobRow {
+		t.Helper()
 
-		client, _ := setup(t)
+		opts.FinalizedAt = ptrutil.Ptr(time.Now().Add(-1 * time.Hour))
+		opts.State = ptrutil.Ptr(rivertype.JobStateDiscarded)
+		job := testfactory.Job(ctx, t, bundle.exec, opts)
 
-		insertRes, err := client.Insert(ctx, noOpArgs{}, nil)
+		numMoved, err := bundle.exec.JobDeadLetterMoveBefore(ctx, &riverdriver.JobDeadLetterMoveBeforeParams{
+			DiscardedFinalizedAtHorizon: time.Now(),
+			Max:                         100,
+		})
 		require.NoError(t, err)
+		require.Equal(t, 1, numMoved)
 
-		job, err := client.JobGet(ctx, insertRes.Job.ID)
-		require.NoError(t, err)
+		return job
+	}
 
-		require.Equal(t, insertRes.Job.ID, job.ID)
+	t.Run("Get", func(t *testing.T) {
+		t.Parallel()
+
+		client, bundle := setup(t)
+
+		job := deadLetterJob(t, bundle, &testfactory.JobOpts{Kind: ptrutil.Ptr("kind1")})
+
+		deadLetter, err := client.DeadLetterGet(ctx, job.ID)
+		require.NoError(t, err)
+		require.Equal(t, job.ID, deadLetter.ID)
+		require.Equal(t, "kind1", deadLetter.Kind)
+
+		_, err = client.DeadLetterGet(ctx, 0)
+		require.ErrorIs(t, err, ErrNotFound)
+	})
+
+	t.Run("ListFiltersAndPaginates", func(t *testing.T) {
+		t.Parallel()
+
+		client, bundle := setup(t)
+
+		job1 := deadLetterJob(t, bundle, &testfactory.JobOpts{Kind: ptrutil.Ptr("kind1")})
+		job2 := deadLetterJob(t, bundle, &testfactory.JobOpts{Kind: ptrutil.Ptr("kind1")})
+		job3 := deadLetterJob(t, bundle, &testfactory.JobOpts{Kind: ptrutil.Ptr("kind2")})
+
+		listRes, err := client.DeadLetterList(ctx, nil)
+		require.NoError(t, err)
+		require.Equal(t, []int64{job1.ID, job2.ID, job3.ID}, sliceutil.Map(listRes.DeadLetters, func(d *rivertype.DeadLetter) int64 { return d.ID }))
+
+		listRes, err = client.DeadLetterList(ctx, NewDeadLetterListParams().First(1))
+		require.NoError(t, err)
+		require.Len(t, listRes.DeadLetters, 1)
+		require.Equal(t, job1.ID, listRes.DeadLetters[0].ID)
+
+		listRes, err = client.DeadLetterList(ctx, NewDeadLetterListParams().After(job1.ID).Kinds("kind1"))
+		require.NoError(t, err)
+		require.Len(t, listRes.DeadLetters, 1)
+		require.Equal(t, job2.ID, listRes.DeadLetters[0].ID)
+	})
+
+	t.Run("Purge", func(t *testing.T) {
+		t.Parallel()
+
+		client, bundle := setup(t)
+
+		job1 := deadLetterJob(t, bundle, &testfactory.JobOpts{Kind: ptrutil.Ptr("kind1")})
+		job2 := deadLetterJob(t, bundle, &testfactory.JobOpts{Kind: ptrutil.Ptr("kind2")})
+
+		numPurged, err := client.DeadLetterPurge(ctx, NewDeadLetterPurgeParams().Kinds("kind1"))
+		require.NoError(t, err)
+		require.Equal(t, 1, numPurged)
+
+		_, err = client.DeadLetterGet(ctx, job1.ID)
+		require.ErrorIs(t, err, ErrNotFound)
+		_, err = client.DeadLetterGet(ctx, job2.ID)
+		require.NoError(t, err)
+
+		numPurged, err = client.DeadLetterPurge(ctx, nil)
+		require.NoError(t, err)
+		require.Equal(t, 1, numPurged)
+	})
+
+	t.Run("Requeue", func(t *testing.T) {
+		t.Parallel()
+
+		client, bundle := setup(t)
+
+		job := deadLetterJob(t, bundle, &testfactory.JobOpts{
+			EncodedArgs: []byte(`{"foo":"bar"}`),
+			Kind:        ptrutil.Ptr("kind1"),
+			MaxAttempts: ptrutil.Ptr(7),
+			Metadata:    []byte(`{"meta":"data"}`),
+			Priority:    ptrutil.Ptr(2),
+			Queue:       ptrutil.Ptr("queue1"),
+			Tags:        []string{"tag1"},
+		})
+
+		insertRes, err := client.DeadLetterRequeue(ctx, job.ID)
+		require.NoError(t, err)
+		require.NotEqual(t, job.ID, insertRes.Job.ID)
+		require.JSONEq(t, `{"foo":"bar"}`, string(insertRes.Job.EncodedArgs))
+		require.Zero(t, insertRes.Job.Attempt)
+		require.Equal(t, "kind1", insertRes.Job.Kind)
+		require.Equal(t, 7, insertRes.Job.MaxAttempts)
+		require.JSONEq(t, `{"meta":"data"}`, string(insertRes.Job.Metadata))
+		require.Equal(t, 2, insertRes.Job.Priority)
+		require.Equal(t, "queue1", insertRes.Job.Queue)
+		require.Equal(t, rivertype.JobStateAvailable, insertRes.Job.State)
+		require.Equal(t, []string{"tag1"}, insertRes.Job.Tags)
+
+		_, err = client.DeadLetterGet(ctx, job.ID)
+		require.ErrorIs(t, err, ErrNotFound)
+
+		_, err = client.DeadLetterRequeue(ctx, job.ID)
+		require.ErrorIs(t, err, ErrNotFound)
+	})
+
+	t.Run("RequeueRemovesRiverMetadata", func(t *testing.T) {
+		t.Parallel()
+
+		client, bundle := setup(t)
+
+		// A job that recorded output and progress before being discarded, and
+		// which was part of a workflow and sequence.
+		job := deadLetterJob(t, bundle, &testfactory.JobOpts{
+			Metadata: []byte(`{
+				"meta": "data",
+				"output": {"result": 123},
+				"progress": {"percent": 50},
+				"cancel_attempted_at": "2025-01-01T00:00:00Z",
+				"river:lease": {"attempt": 1, "expires_at": "2025-01-01T00:00:00Z"},
+				"river:partition": {"tenant": 1},
+				"river:sequence_key": "key",
+				"river:workflow_id": "workflow_id"
+			}`),
+		})
+
+		deadLetter, err := client.DeadLetterGet(ctx, job.ID)
+		require.NoError(t, err)
+		require.Contains(t, string(deadLetter.Metadata), `"output"`)
+
+		insertRes, err := client.DeadLetterRequeue(ctx, job.ID)
+		require.NoError(t, err)
+		require.Nil(t, insertRes.Job.Output())
+		require.JSONEq(t, `{"meta":"data","river:partition":{"tenant":1}}`, string(insertRes.Job.Metadata))
+	})
+}
+
+func Test_deadLetterRequeueMetadata(t *testing.T) {
+	t.Parallel()
+
+	t.Run("RemovesRiverKeys", func(t *testing.T) {
+		t.Parallel()
+
+		metadata, err := deadLetterRequeueMetadata([]byte(`{
+			"batch": "user_key",
+			"cancel_attempted_at": "2025-01-01T00:00:00Z",
+			"checkpoint": {"cursor": 1},
+			"output": {"result": 123},
+			"progress": {"percent": 50},
+			"river:batch_id": "batch_id",
+			"river:lease": {"attempt": 1},
+			"river:log": [],
+			"river:partition": {"tenant": 1},
+			"river:sequence_key": "key",
+			"river:workflow_id": "workflow_id",
+			"snoozes": 2,
+			"user": "value"
+		}`))
+		require.NoError(t, err)
+		require.JSONEq(t, `{"batch":"user_key","river:partition":{"tenant":1},"user":"value"}`, string(metadata))
+	})
+
+	t.Run("EmptyMetadata", func(t *testing.T) {
+		t.Parallel()
+
+		metadata, err := deadLetterRequeueMetadata(nil)
+		require.NoError(t, err)
+		require.Nil(t, metadata)
+	})
+}
+
+func Test_Client_JobCount(t *testing.T) {
+	t.Parallel()
+
+	ctx := context.Background()
+
+	type testBundle struct {
+		dbPool *pgxpool.Pool
+		exec   riverdriver.Executor
+	}
+
+	setup := func(t *testing.T) (*Client[pgx.Tx], *testBundle) {
+		t.Helper()
+
+		dbPool := riverinternaltest.TestDB(ctx, t)
+		config := newTestConfig(t, nil)
+		client := newTestClient(t, dbPool, config)
+
+		return client, &testBundle{
+			dbPool: dbPool,
+			exec:   client.driver.GetExecutor(),
+		}
+	}
+
+	t.Run("CountsMatchingJobs", func(t *testing.T) {
+		t.Parallel()
+
+		client, bundle := setup(t)
+
+		_ = testfactory.Job(ctx, t, bundle.exec, &testfactory.JobOpts{Kind: ptrutil.Ptr("kind1")})
+		_ = testfactory.Job(ctx, t, bundle.exec, &testfactory.JobOpts{Kind: ptrutil.Ptr("kind1"), FinalizedAt: ptrutil.Ptr(time.Now()), State: ptrutil.Ptr(rivertype.JobStateDiscarded)})
+		_ = testfactory.Job(ctx, t, bundle.exec, &testfactory.JobOpts{Kind: ptrutil.Ptr("kind2"), FinalizedAt: ptrutil.Ptr(time.Now()), State: ptrutil.Ptr(rivertype.JobStateDiscarded)})
+
+		numJobs, err := client.JobCount(ctx, NewJobListParams())
+		require.NoError(t, err)
+		require.Equal(t, 3, numJobs)
+
+		numJobs, err = client.JobCount(ctx, NewJobListParams().Kinds("kind1").States(rivertype.JobStateDiscarded))
+		require.NoError(t, err)
+		require.Equal(t, 1, numJobs)
+	})
+
+	t.Run("NilParams", func(t *testing.T) {
+		t.Parallel()
+
+		client, bundle := setup(t)
+
+		_ = testfactory.Job(ctx, t, bundle.exec, &testfactory.JobOpts{})
+
+		numJobs, err := client.JobCount(ctx, nil)
+		require.NoError(t, err)
+		require.Equal(t, 1, numJobs)
+	})
+
+	t.Run("IgnoresPagination", func(t *testing.T) {
+		t.Parallel()
+
+		client, bundle := setup(t)
+
+		job1 := testfactory.Job(ctx, t, bundle.exec, &testfactory.JobOpts{})
+		_ = testfactory.Job(ctx, t, bundle.exec, &testfactory.JobOpts{})
+		_ = testfactory.Job(ctx, t, bundle.exec, &testfactory.JobOpts{})
+
+		numJobs, err := client.JobCount(ctx, NewJobListParams().After(JobListCursorFromJob(job1)).First(1))
+		require.NoError(t, err)
+		require.Equal(t, 3, numJobs)
+	})
+
+	t.Run("CountsByGroup", func(t *testing.T) {
+		t.Parallel()
+
+		client, bundle := setup(t)
+
+		_ = testfactory.Job(ctx, t, bundle.exec, &testfactory.JobOpts{Kind: ptrutil.Ptr("kind1"), Queue: ptrutil.Ptr("queue1"), Tags: []string{"tag1", "tag2"}})
+		_ = testfactory.Job(ctx, t, bundle.exec, &testfactory.JobOpts{Kind: ptrutil.Ptr("kind1"), Queue: ptrutil.Ptr("queue2"), Tags: []string{"tag1"}})
+		_ = testfactory.Job(ctx, t, bundle.exec, &testfactory.JobOpts{Kind: ptrutil.Ptr("kind2"), Queue: ptrutil.Ptr("queue2"), State: ptrutil.Ptr(rivertype.JobStateRunning)})
+
+		counts, err := client.JobCountBy(ctx, NewJobListParams(), JobCountGroupByKind)
+		require.NoError(t, err)
+		require.Equal(t, map[string]int{"kind1": 2, "kind2": 1}, counts)
+
+		counts, err = client.JobCountBy(ctx, NewJobListParams(), JobCountGroupByQueue)
+		require.NoError(t, err)
+		require.Equal(t, map[string]int{"queue1": 1, "queue2": 2}, counts)
+
+		counts, err = client.JobCountBy(ctx, NewJobListParams(), JobCountGroupByState)
+		require.NoError(t, err)
+		require.Equal(t, map[string]int{string(rivertype.JobStateAvailable): 2, string(rivertype.JobStateRunning): 1}, counts)
+
+		counts, err = client.JobCountBy(ctx, NewJobListParams(), JobCountGroupByTag)
+		require.NoError(t, err)
+		require.Equal(t, map[string]int{"tag1": 2, "tag2": 1}, counts)
+
+		counts, err = client.JobCountBy(ctx, NewJobListParams().Kinds("kind2"), JobCountGroupByQueue)
+		require.NoError(t, err)
+		require.Equal(t, map[string]int{"queue2": 1}, counts)
+	})
+
+	t.Run("CountsByGroupInvalidGroupBy", func(t *testing.T) {
+		t.Parallel()
+
+		client, _ := setup(t)
+
+		_, err := client.JobCountBy(ctx, NewJobListParams(), JobCountGroupBy("invalid"))
+		require.EqualError(t, err, `invalid job count group by: "invalid"`)
+	})
+
+	t.Run("TxVariant", func(t *testing.T) {
+		t.Parallel()
+
+		client, bundle := setup(t)
+
+		_ = testfactory.Job(ctx, t, bundle.exec, &testfactory.JobOpts{})
+
+		tx, err := bundle.dbPool.Begin(ctx)
+		require.NoError(t, err)
+		t.Cleanup(func() { tx.Rollback(ctx) })
+
+		_ = testfactory.Job(ctx, t, client.driver.UnwrapExecutor(tx), &testfactory.JobOpts{Kind: ptrutil.Ptr("kind1")})
+
+		numJobs, err := client.JobCountTx(ctx, tx, NewJobListParams())
+		require.NoError(t, err)
+		require.Equal(t, 2, numJobs)
+
+		counts, err := client.JobCountByTx(ctx, tx, NewJobListParams().Kinds("kind1"), JobCountGroupByKind)
+		require.NoError(t, err)
+		require.Equal(t, map[string]int{"kind1": 1}, counts)
+
+		// Not visible outside the transaction.
+		numJobs, err = client.JobCount(ctx, NewJobListParams())
+		require.NoError(t, err)
+		require.Equal(t, 1, numJobs)
+	})
+
+	t.Run("ErrorsOnDriverWithoutPool", func(t *testing.T) {
+		t.Parallel()
+
+		client, err := NewClient(riverpgxv5.New(nil), &Config{
+			Logger: riversharedtest.Logger(t),
+		})
+		require.NoError(t, err)
+
+		_, err = client.JobCount(ctx, NewJobListParams())
+		require.ErrorIs(t, err, errNoDriverDBPool)
+
+		_, err = client.JobCountBy(ctx, NewJobListParams(), JobCountGroupByKind)
+		require.ErrorIs(t, err, errNoDriverDBPool)
+	})
+}
+
+func Test_Client_JobGet(t *testing.T) {
+	t.Parallel()
+
+	ctx := context.Background()
+
+	type testBundle struct{}
+
+	setup := func(t *testing.T) (*Client[pgx.Tx], *testBundle) {
+		t.Helper()
+
+		dbPool := riverinternaltest.TestDB(ctx, t)
+		config := newTestConfig(t, nil)
+		client := newTestClient(t, dbPool, config)
+
+		return client, &testBundle{}
+	}
+
+	t.Run("FetchesAnExistingJob", func(t *testing.T) {
+		t.Parallel()
+
+		client, _ := setup(t)
+
+		insertRes, err := client.Insert(ctx, noOpArgs{}, nil)
+		require.NoError(t, err)
+
+		job, err := client.JobGet(ctx, insertRes.Job.ID)
+		require.NoError(t, err)
+
+		require.Equal(t, insertRes.Job.ID, job.ID)
 		require.Equal(t, insertRes.Job.State, job.State)
 	})
 
@@ -3111,6 +3829,20 @@ func Test_Client_JobList(t *testing.T) {
 		}
 	}
 
+	t.Run("FiltersByIDs", func(t *testing.T) {
+		t.Parallel()
+
+		client, bundle := setup(t)
+
+		job1 := testfactory.Job(ctx, t, bundle.exec, &testfactory.JobOpts{})
+		_ = testfactory.Job(ctx, t, bundle.exec, &testfactory.JobOpts{})
+		job3 := testfactory.Job(ctx, t, bundle.exec, &testfactory.JobOpts{})
+
+		listRes, err := client.JobList(ctx, NewJobListParams().IDs(job1.ID, job3.ID))
+		require.NoError(t, err)
+		require.Equal(t, []int64{job1.ID, job3.ID}, sliceutil.Map(listRes.Jobs, func(job *rivertype.JobRow) int64 { return job.ID }))
+	})
+
 	t.Run("FiltersByKind", func(t *testing.T) { //nolint:dupl
 		t.Parallel()
 
@@ -3174,60 +3906,228 @@ func Test_Client_JobList(t *testing.T) {
 		require.Equal(t, []int64{job1.ID, job2.ID, job3.ID, job4.ID}, sliceutil.Map(listRes.Jobs, func(job *rivertype.JobRow) int64 { return job.ID }))
 	})
 
-	t.Run("DefaultsToOrderingByID", func(t *testing.T) {
+	t.Run("FiltersByArgs", func(t *testing.T) {
 		t.Parallel()
 
 		client, bundle := setup(t)
 
-		job1 := testfactory.Job(ctx, t, bundle.exec, &testfactory.JobOpts{})
-		job2 := testfactory.Job(ctx, t, bundle.exec, &testfactory.JobOpts{})
+		job1 := testfactory.Job(ctx, t, bundle.exec, &testfactory.JobOpts{EncodedArgs: []byte(`{"customer": {"id": 1, "name": "a"}, "items": [{"sku": "sku1"}]}`)})
+		job2 := testfactory.Job(ctx, t, bundle.exec, &testfactory.JobOpts{EncodedArgs: []byte(`{"customer": {"id": 2, "name": "a"}, "items": [{"sku": "sku2"}]}`)})
+		_ = testfactory.Job(ctx, t, bundle.exec, &testfactory.JobOpts{EncodedArgs: []byte(`{}`)})
 
-		listRes, err := client.JobList(ctx, NewJobListParams().OrderBy(JobListOrderByTime, SortOrderAsc))
+		listRes, err := client.JobList(ctx, NewJobListParams().Args(`{"customer": {"id": 1}}`))
+		require.NoError(t, err)
+		require.Equal(t, []int64{job1.ID}, sliceutil.Map(listRes.Jobs, func(job *rivertype.JobRow) int64 { return job.ID }))
+
+		listRes, err = client.JobList(ctx, NewJobListParams().ArgsPath("customer.name", "a"))
 		require.NoError(t, err)
 		require.Equal(t, []int64{job1.ID, job2.ID}, sliceutil.Map(listRes.Jobs, func(job *rivertype.JobRow) int64 { return job.ID }))
 
-		listRes, err = client.JobList(ctx, NewJobListParams().OrderBy(JobListOrderByTime, SortOrderDesc))
+		listRes, err = client.JobList(ctx, NewJobListParams().ArgsPath("customer.name", "a").ArgsPath("items.0.sku", "sku2"))
 		require.NoError(t, err)
-		require.Equal(t, []int64{job2.ID, job1.ID}, sliceutil.Map(listRes.Jobs, func(job *rivertype.JobRow) int64 { return job.ID }))
+		require.Equal(t, []int64{job2.ID}, sliceutil.Map(listRes.Jobs, func(job *rivertype.JobRow) int64 { return job.ID }))
+
+		listRes, err = client.JobList(ctx, NewJobListParams().ArgsPath("customer.id", 2))
+		require.NoError(t, err)
+		require.Equal(t, []int64{job2.ID}, sliceutil.Map(listRes.Jobs, func(job *rivertype.JobRow) int64 { return job.ID }))
 	})
 
-	t.Run("OrderByTimeSortsAvailableRetryableAndScheduledJobsByScheduledAt", func(t *testing.T) {
+	t.Run("FiltersByAttempt", func(t *testing.T) {
 		t.Parallel()
 
 		client, bundle := setup(t)
 
-		now := time.Now().UTC()
+		job1 := testfactory.Job(ctx, t, bundle.exec, &testfactory.JobOpts{Attempt: ptrutil.Ptr(0)})
+		job2 := testfactory.Job(ctx, t, bundle.exec, &testfactory.JobOpts{Attempt: ptrutil.Ptr(2)})
+		job3 := testfactory.Job(ctx, t, bundle.exec, &testfactory.JobOpts{Attempt: ptrutil.Ptr(5)})
 
-		states := []rivertype.JobState{
-			rivertype.JobStateAvailable,
-			rivertype.JobStateRetryable,
-			rivertype.JobStateScheduled,
-		}
-		for _, state := range states {
-			job1 := testfactory.Job(ctx, t, bundle.exec, &testfactory.JobOpts{State: ptrutil.Ptr(state), ScheduledAt: &now})
-			job2 := testfactory.Job(ctx, t, bundle.exec, &testfactory.JobOpts{State: ptrutil.Ptr(state), ScheduledAt: ptrutil.Ptr(now.Add(-5 * time.Second))})
+		listRes, err := client.JobList(ctx, NewJobListParams().AttemptAtLeast(2))
+		require.NoError(t, err)
+		require.Equal(t, []int64{job2.ID, job3.ID}, sliceutil.Map(listRes.Jobs, func(job *rivertype.JobRow) int64 { return job.ID }))
 
-			listRes, err := client.JobList(ctx, NewJobListParams().OrderBy(JobListOrderByTime, SortOrderAsc).States(state))
-			require.NoError(t, err)
-			require.Equal(t, []int64{job2.ID, job1.ID}, sliceutil.Map(listRes.Jobs, func(job *rivertype.JobRow) int64 { return job.ID }))
+		listRes, err = client.JobList(ctx, NewJobListParams().AttemptAtMost(2))
+		require.NoError(t, err)
+		require.Equal(t, []int64{job1.ID, job2.ID}, sliceutil.Map(listRes.Jobs, func(job *rivertype.JobRow) int64 { return job.ID }))
 
-			listRes, err = client.JobList(ctx, NewJobListParams().States(state).OrderBy(JobListOrderByTime, SortOrderDesc))
-			require.NoError(t, err)
-			require.Equal(t, []int64{job1.ID, job2.ID}, sliceutil.Map(listRes.Jobs, func(job *rivertype.JobRow) int64 { return job.ID }))
-		}
+		listRes, err = client.JobList(ctx, NewJobListParams().AttemptAtLeast(1).AttemptAtMost(4))
+		require.NoError(t, err)
+		require.Equal(t, []int64{job2.ID}, sliceutil.Map(listRes.Jobs, func(job *rivertype.JobRow) int64 { return job.ID }))
 	})
 
-	t.Run("OrderByTimeSortsCancelledCompletedAndDiscardedJobsByFinalizedAt", func(t *testing.T) {
+	t.Run("FiltersByIDAtMost", func(t *testing.T) {
 		t.Parallel()
 
 		client, bundle := setup(t)
 
-		now := time.Now().UTC()
+		job1 := testfactory.Job(ctx, t, bundle.exec, &testfactory.JobOpts{})
+		job2 := testfactory.Job(ctx, t, bundle.exec, &testfactory.JobOpts{})
+		_ = testfactory.Job(ctx, t, bundle.exec, &testfactory.JobOpts{})
 
-		states := []rivertype.JobState{
-			rivertype.JobStateCancelled,
-			rivertype.JobStateCompleted,
-			rivertype.JobStateDiscarded,
+		listRes, err := client.JobList(ctx, NewJobListParams().IDAtMost(job2.ID))
+		require.NoError(t, err)
+		require.Equal(t, []int64{job1.ID, job2.ID}, sliceutil.Map(listRes.Jobs, func(job *rivertype.JobRow) int64 { return job.ID }))
+
+		count, err := client.JobCount(ctx, NewJobListParams().IDAtMost(job1.ID))
+		require.NoError(t, err)
+		require.Equal(t, 1, count)
+	})
+
+	t.Run("FiltersByPriority", func(t *testing.T) {
+		t.Parallel()
+
+		client, bundle := setup(t)
+
+		job1 := testfactory.Job(ctx, t, bundle.exec, &testfactory.JobOpts{Priority: ptrutil.Ptr(1)})
+		job2 := testfactory.Job(ctx, t, bundle.exec, &testfactory.JobOpts{Priority: ptrutil.Ptr(2)})
+		_ = testfactory.Job(ctx, t, bundle.exec, &testfactory.JobOpts{Priority: ptrutil.Ptr(3)})
+
+		listRes, err := client.JobList(ctx, NewJobListParams().Priorities(1, 2))
+		require.NoError(t, err)
+		require.Equal(t, []int64{job1.ID, job2.ID}, sliceutil.Map(listRes.Jobs, func(job *rivertype.JobRow) int64 { return job.ID }))
+	})
+
+	t.Run("FiltersByTags", func(t *testing.T) {
+		t.Parallel()
+
+		client, bundle := setup(t)
+
+		job1 := testfactory.Job(ctx, t, bundle.exec, &testfactory.JobOpts{Tags: []string{"tag1", "tag2"}})
+		job2 := testfactory.Job(ctx, t, bundle.exec, &testfactory.JobOpts{Tags: []string{"tag2"}})
+		_ = testfactory.Job(ctx, t, bundle.exec, &testfactory.JobOpts{Tags: []string{}})
+
+		listRes, err := client.JobList(ctx, NewJobListParams().TagsAny("tag1", "tag2"))
+		require.NoError(t, err)
+		require.Equal(t, []int64{job1.ID, job2.ID}, sliceutil.Map(listRes.Jobs, func(job *rivertype.JobRow) int64 { return job.ID }))
+
+		listRes, err = client.JobList(ctx, NewJobListParams().TagsAll("tag1", "tag2"))
+		require.NoError(t, err)
+		require.Equal(t, []int64{job1.ID}, sliceutil.Map(listRes.Jobs, func(job *rivertype.JobRow) int64 { return job.ID }))
+	})
+
+	t.Run("FiltersByTimeRanges", func(t *testing.T) {
+		t.Parallel()
+
+		client, bundle := setup(t)
+
+		now := time.Now().UTC()
+
+		job1 := testfactory.Job(ctx, t, bundle.exec, &testfactory.JobOpts{CreatedAt: ptrutil.Ptr(now.Add(-3 * time.Hour)), FinalizedAt: ptrutil.Ptr(now.Add(-3 * time.Hour)), ScheduledAt: ptrutil.Ptr(now.Add(-3 * time.Hour)), State: ptrutil.Ptr(rivertype.JobStateCompleted)})
+		job2 := testfactory.Job(ctx, t, bundle.exec, &testfactory.JobOpts{CreatedAt: ptrutil.Ptr(now.Add(-2 * time.Hour)), FinalizedAt: ptrutil.Ptr(now.Add(-2 * time.Hour)), ScheduledAt: ptrutil.Ptr(now.Add(-2 * time.Hour)), State: ptrutil.Ptr(rivertype.JobStateCompleted)})
+		job3 := testfactory.Job(ctx, t, bundle.exec, &testfactory.JobOpts{CreatedAt: ptrutil.Ptr(now.Add(-1 * time.Hour)), ScheduledAt: ptrutil.Ptr(now.Add(time.Hour))})
+
+		listRes, err := client.JobList(ctx, NewJobListParams().CreatedAtRange(now.Add(-2*time.Hour), time.Time{}))
+		require.NoError(t, err)
+		require.Equal(t, []int64{job2.ID, job3.ID}, sliceutil.Map(listRes.Jobs, func(job *rivertype.JobRow) int64 { return job.ID }))
+
+		// End of range is exclusive.
+		listRes, err = client.JobList(ctx, NewJobListParams().CreatedAtRange(time.Time{}, now.Add(-2*time.Hour)))
+		require.NoError(t, err)
+		require.Equal(t, []int64{job1.ID}, sliceutil.Map(listRes.Jobs, func(job *rivertype.JobRow) int64 { return job.ID }))
+
+		// Jobs that aren't finalized never match.
+		listRes, err = client.JobList(ctx, NewJobListParams().FinalizedAtRange(now.Add(-150*time.Minute), now))
+		require.NoError(t, err)
+		require.Equal(t, []int64{job2.ID}, sliceutil.Map(listRes.Jobs, func(job *rivertype.JobRow) int64 { return job.ID }))
+
+		listRes, err = client.JobList(ctx, NewJobListParams().ScheduledAtRange(now, now.Add(2*time.Hour)))
+		require.NoError(t, err)
+		require.Equal(t, []int64{job3.ID}, sliceutil.Map(listRes.Jobs, func(job *rivertype.JobRow) int64 { return job.ID }))
+
+		require.PanicsWithValue(t, "end must not be before start", func() {
+			NewJobListParams().CreatedAtRange(now, now.Add(-time.Hour))
+		})
+	})
+
+	t.Run("FiltersCombined", func(t *testing.T) {
+		t.Parallel()
+
+		client, bundle := setup(t)
+
+		var (
+			now          = time.Now().UTC()
+			lastTuesday  = now.Add(-6 * 24 * time.Hour)
+			discarded    = ptrutil.Ptr(rivertype.JobStateDiscarded)
+			sendEmail    = ptrutil.Ptr("send_email")
+			campaignTags = []string{"campaign-42"}
+		)
+
+		job1 := testfactory.Job(ctx, t, bundle.exec, &testfactory.JobOpts{FinalizedAt: ptrutil.Ptr(lastTuesday.Add(time.Hour)), Kind: sendEmail, State: discarded, Tags: campaignTags})
+
+		// Each is excluded by one filter.
+		_ = testfactory.Job(ctx, t, bundle.exec, &testfactory.JobOpts{FinalizedAt: ptrutil.Ptr(lastTuesday.Add(time.Hour)), Kind: ptrutil.Ptr("other_kind"), State: discarded, Tags: campaignTags})
+		_ = testfactory.Job(ctx, t, bundle.exec, &testfactory.JobOpts{FinalizedAt: ptrutil.Ptr(lastTuesday.Add(time.Hour)), Kind: sendEmail, State: ptrutil.Ptr(rivertype.JobStateCompleted), Tags: campaignTags})
+		_ = testfactory.Job(ctx, t, bundle.exec, &testfactory.JobOpts{FinalizedAt: ptrutil.Ptr(lastTuesday.Add(time.Hour)), Kind: sendEmail, State: discarded, Tags: []string{"campaign-43"}})
+		_ = testfactory.Job(ctx, t, bundle.exec, &testfactory.JobOpts{FinalizedAt: ptrutil.Ptr(now), Kind: sendEmail, State: discarded, Tags: campaignTags})
+
+		params := NewJobListParams().
+			FinalizedAtRange(lastTuesday, lastTuesday.Add(24*time.Hour)).
+			Kinds("send_email").
+			States(rivertype.JobStateDiscarded).
+			TagsAny("campaign-42")
+
+		listRes, err := client.JobList(ctx, params)
+		require.NoError(t, err)
+		require.Equal(t, []int64{job1.ID}, sliceutil.Map(listRes.Jobs, func(job *rivertype.JobRow) int64 { return job.ID }))
+
+		numJobs, err := client.JobCount(ctx, params)
+		require.NoError(t, err)
+		require.Equal(t, 1, numJobs)
+	})
+
+	t.Run("DefaultsToOrderingByID", func(t *testing.T) {
+		t.Parallel()
+
+		client, bundle := setup(t)
+
+		job1 := testfactory.Job(ctx, t, bundle.exec, &testfactory.JobOpts{})
+		job2 := testfactory.Job(ctx, t, bundle.exec, &testfactory.JobOpts{})
+
+		listRes, err := client.JobList(ctx, NewJobListParams().OrderBy(JobListOrderByTime, SortOrderAsc))
+		require.NoError(t, err)
+		require.Equal(t, []int64{job1.ID, job2.ID}, sliceutil.Map(listRes.Jobs, func(job *rivertype.JobRow) int64 { return job.ID }))
+
+		listRes, err = client.JobList(ctx, NewJobListParams().OrderBy(JobListOrderByTime, SortOrderDesc))
+		require.NoError(t, err)
+		require.Equal(t, []int64{job2.ID, job1.ID}, sliceutil.Map(listRes.Jobs, func(job *rivertype.JobRow) int64 { return job.ID }))
+	})
+
+	t.Run("OrderByTimeSortsAvailableRetryableAndScheduledJobsByScheduledAt", func(t *testing.T) {
+		t.Parallel()
+
+		client, bundle := setup(t)
+
+		now := time.Now().UTC()
+
+		states := []rivertype.JobState{
+			rivertype.JobStateAvailable,
+			rivertype.JobStateRetryable,
+			rivertype.JobStateScheduled,
+		}
+		for _, state := range states {
+			job1 := testfactory.Job(ctx, t, bundle.exec, &testfactory.JobOpts{State: ptrutil.Ptr(state), ScheduledAt: &now})
+			job2 := testfactory.Job(ctx, t, bundle.exec, &testfactory.JobOpts{State: ptrutil.Ptr(state), ScheduledAt: ptrutil.Ptr(now.Add(-5 * time.Second))})
+
+			listRes, err := client.JobList(ctx, NewJobListParams().OrderBy(JobListOrderByTime, SortOrderAsc).States(state))
+			require.NoError(t, err)
+			require.Equal(t, []int64{job2.ID, job1.ID}, sliceutil.Map(listRes.Jobs, func(job *rivertype.JobRow) int64 { return job.ID }))
+
+			listRes, err = client.JobList(ctx, NewJobListParams().States(state).OrderBy(JobListOrderByTime, SortOrderDesc))
+			require.NoError(t, err)
+			require.Equal(t, []int64{job1.ID, job2.ID}, sliceutil.Map(listRes.Jobs, func(job *rivertype.JobRow) int64 { return job.ID }))
+		}
+	})
+
+	t.Run("OrderByTimeSortsCancelledCompletedAndDiscardedJobsByFinalizedAt", func(t *testing.T) {
+		t.Parallel()
+
+		client, bundle := setup(t)
+
+		now := time.Now().UTC()
+
+		states := []rivertype.JobState{
+			rivertype.JobStateCancelled,
+			rivertype.JobStateCompleted,
+			rivertype.JobStateDiscarded,
 		}
 		for _, state := range states {
 			job1 := testfactory.Job(ctx, t, bundle.exec, &testfactory.JobOpts{State: ptrutil.Ptr(state), FinalizedAt: ptrutil.Ptr(now.Add(-10 * time.Second))})
@@ -3369,6 +4269,80 @@ func Test_Client_JobList(t *testing.T) {
 		require.Equal(t, job6.ID, listRes.LastCursor.id)
 	})
 
+	t.Run("PaginatesWithBefore_JobListOrderByID", func(t *testing.T) {
+		t.Parallel()
+
+		client, bundle := setup(t)
+
+		job1 := testfactory.Job(ctx, t, bundle.exec, &testfactory.JobOpts{})
+		job2 := testfactory.Job(ctx, t, bundle.exec, &testfactory.JobOpts{})
+		job3 := testfactory.Job(ctx, t, bundle.exec, &testfactory.JobOpts{})
+		job4 := testfactory.Job(ctx, t, bundle.exec, &testfactory.JobOpts{})
+
+		// Returns the jobs immediately before the cursor, still in ascending order.
+		listRes, err := client.JobList(ctx, NewJobListParams().First(2).Before(JobListCursorFromJob(job4)))
+		require.NoError(t, err)
+		require.Equal(t, []int64{job2.ID, job3.ID}, sliceutil.Map(listRes.Jobs, func(job *rivertype.JobRow) int64 { return job.ID }))
+		require.Equal(t, job2.ID, listRes.FirstCursor.id)
+		require.Equal(t, job3.ID, listRes.LastCursor.id)
+
+		listRes, err = client.JobList(ctx, NewJobListParams().First(2).Before(listRes.FirstCursor))
+		require.NoError(t, err)
+		require.Equal(t, []int64{job1.ID}, sliceutil.Map(listRes.Jobs, func(job *rivertype.JobRow) int64 { return job.ID }))
+		require.Equal(t, job1.ID, listRes.FirstCursor.id)
+
+		// No more results
+		listRes, err = client.JobList(ctx, NewJobListParams().Before(listRes.FirstCursor))
+		require.NoError(t, err)
+		require.Equal(t, []int64{}, sliceutil.Map(listRes.Jobs, func(job *rivertype.JobRow) int64 { return job.ID }))
+		require.Nil(t, listRes.FirstCursor)
+		require.Nil(t, listRes.LastCursor)
+
+		// Descending
+		listRes, err = client.JobList(ctx, NewJobListParams().OrderBy(JobListOrderByID, SortOrderDesc).First(2).Before(JobListCursorFromJob(job1)))
+		require.NoError(t, err)
+		require.Equal(t, []int64{job3.ID, job2.ID}, sliceutil.Map(listRes.Jobs, func(job *rivertype.JobRow) int64 { return job.ID }))
+		require.Equal(t, job3.ID, listRes.FirstCursor.id)
+		require.Equal(t, job2.ID, listRes.LastCursor.id)
+	})
+
+	t.Run("PaginatesWithBefore_JobListOrderByScheduledAt", func(t *testing.T) {
+		t.Parallel()
+
+		client, bundle := setup(t)
+
+		now := time.Now().UTC()
+		job1 := testfactory.Job(ctx, t, bundle.exec, &testfactory.JobOpts{ScheduledAt: ptrutil.Ptr(now.Add(2 * time.Second))})
+		job2 := testfactory.Job(ctx, t, bundle.exec, &testfactory.JobOpts{ScheduledAt: ptrutil.Ptr(now.Add(1 * time.Second))})
+		job3 := testfactory.Job(ctx, t, bundle.exec, &testfactory.JobOpts{ScheduledAt: &now})
+
+		listRes, err := client.JobList(ctx, NewJobListParams().OrderBy(JobListOrderByScheduledAt, SortOrderAsc).Before(JobListCursorFromJob(job1)))
+		require.NoError(t, err)
+		require.Equal(t, []int64{job3.ID, job2.ID}, sliceutil.Map(listRes.Jobs, func(job *rivertype.JobRow) int64 { return job.ID }))
+		require.Equal(t, JobListOrderByScheduledAt, listRes.FirstCursor.sortField)
+		require.Equal(t, job3.ID, listRes.FirstCursor.id)
+
+		// Descending
+		listRes, err = client.JobList(ctx, NewJobListParams().OrderBy(JobListOrderByScheduledAt, SortOrderDesc).Before(JobListCursorFromJob(job3)))
+		require.NoError(t, err)
+		require.Equal(t, []int64{job1.ID, job2.ID}, sliceutil.Map(listRes.Jobs, func(job *rivertype.JobRow) int64 { return job.ID }))
+	})
+
+	t.Run("PaginatesBetweenAfterAndBefore", func(t *testing.T) {
+		t.Parallel()
+
+		client, bundle := setup(t)
+
+		job1 := testfactory.Job(ctx, t, bundle.exec, &testfactory.JobOpts{})
+		job2 := testfactory.Job(ctx, t, bundle.exec, &testfactory.JobOpts{})
+		job3 := testfactory.Job(ctx, t, bundle.exec, &testfactory.JobOpts{})
+		job4 := testfactory.Job(ctx, t, bundle.exec, &testfactory.JobOpts{})
+
+		listRes, err := client.JobList(ctx, NewJobListParams().After(JobListCursorFromJob(job1)).Before(JobListCursorFromJob(job4)))
+		require.NoError(t, err)
+		require.Equal(t, []int64{job2.ID, job3.ID}, sliceutil.Map(listRes.Jobs, func(job *rivertype.JobRow) int64 { return job.ID }))
+	})
+
 	t.Run("MetadataOnly", func(t *testing.T) {
 		t.Parallel()
 
@@ -3402,6 +4376,133 @@ func Test_Client_JobList(t *testing.T) {
 	})
 }
 
+func Test_Client_JobListAll(t *testing.T) {
+	t.Parallel()
+
+	ctx := context.Background()
+
+	type testBundle struct {
+		dbPool *pgxpool.Pool
+		exec   riverdriver.Executor
+	}
+
+	setup := func(t *testing.T) (*Client[pgx.Tx], *testBundle) {
+		t.Helper()
+
+		dbPool := riverinternaltest.TestDB(ctx, t)
+		config := newTestConfig(t, nil)
+		client := newTestClient(t, dbPool, config)
+
+		return client, &testBundle{
+			dbPool: dbPool,
+			exec:   client.driver.GetExecutor(),
+		}
+	}
+
+	t.Run("IteratesAllPages", func(t *testing.T) {
+		t.Parallel()
+
+		client, bundle := setup(t)
+
+		var expectedIDs []int64
+		for range 5 {
+			expectedIDs = append(expectedIDs, testfactory.Job(ctx, t, bundle.exec, &testfactory.JobOpts{Kind: ptrutil.Ptr("kind1")}).ID)
+		}
+
+		// Excluded by filter.
+		_ = testfactory.Job(ctx, t, bundle.exec, &testfactory.JobOpts{Kind: ptrutil.Ptr("other_kind")})
+
+		var ids []int64
+		for job, err := range client.JobListAll(ctx, NewJobListParams().Kinds("kind1").First(2)) {
+			require.NoError(t, err)
+			ids = append(ids, job.ID)
+		}
+		require.Equal(t, expectedIDs, ids)
+	})
+
+	t.Run("StartsAfterCursorAndIgnoresBefore", func(t *testing.T) {
+		t.Parallel()
+
+		client, bundle := setup(t)
+
+		job1 := testfactory.Job(ctx, t, bundle.exec, &testfactory.JobOpts{})
+		job2 := testfactory.Job(ctx, t, bundle.exec, &testfactory.JobOpts{})
+		job3 := testfactory.Job(ctx, t, bundle.exec, &testfactory.JobOpts{})
+
+		var ids []int64
+		for job, err := range client.JobListAll(ctx, NewJobListParams().After(JobListCursorFromJob(job1)).Before(JobListCursorFromJob(job3)).First(1)) {
+			require.NoError(t, err)
+			ids = append(ids, job.ID)
+		}
+		require.Equal(t, []int64{job2.ID, job3.ID}, ids)
+	})
+
+	t.Run("StopsWhenBrokenOutOf", func(t *testing.T) {
+		t.Parallel()
+
+		client, bundle := setup(t)
+
+		job1 := testfactory.Job(ctx, t, bundle.exec, &testfactory.JobOpts{})
+		_ = testfactory.Job(ctx, t, bundle.exec, &testfactory.JobOpts{})
+
+		var ids []int64
+		for job, err := range client.JobListAll(ctx, NewJobListParams().First(1)) {
+			require.NoError(t, err)
+			ids = append(ids, job.ID)
+			break
+		}
+		require.Equal(t, []int64{job1.ID}, ids)
+	})
+
+	t.Run("YieldsErrors", func(t *testing.T) {
+		t.Parallel()
+
+		client, _ := setup(t)
+
+		var errs []error
+		for job, err := range client.JobListAll(ctx, NewJobListParams().OrderBy(JobListOrderByID, SortOrder(99))) {
+			require.Nil(t, job)
+			errs = append(errs, err)
+		}
+		require.Len(t, errs, 1)
+		require.EqualError(t, errs[0], "invalid sort order")
+	})
+
+	t.Run("TxVariant", func(t *testing.T) {
+		t.Parallel()
+
+		client, bundle := setup(t)
+
+		job1 := testfactory.Job(ctx, t, bundle.exec, &testfactory.JobOpts{})
+
+		tx, err := bundle.dbPool.Begin(ctx)
+		require.NoError(t, err)
+		t.Cleanup(func() { tx.Rollback(ctx) })
+
+		job2 := testfactory.Job(ctx, t, client.driver.UnwrapExecutor(tx), &testfactory.JobOpts{})
+
+		var ids []int64
+		for job, err := range client.JobListAllTx(ctx, tx, NewJobListParams().First(1)) {
+			require.NoError(t, err)
+			ids = append(ids, job.ID)
+		}
+		require.Equal(t, []int64{job1.ID, job2.ID}, ids)
+	})
+
+	t.Run("ErrorsOnDriverWithoutPool", func(t *testing.T) {
+		t.Parallel()
+
+		client, err := NewClient(riverpgxv5.New(nil), &Config{
+			Logger: riversharedtest.Logger(t),
+		})
+		require.NoError(t, err)
+
+		for _, err := range client.JobListAll(ctx, NewJobListParams()) {
+			require.ErrorIs(t, err, errNoDriverDBPool)
+		}
+	})
+}
+
 func Test_Client_JobRetry(t *testing.T) {
 	t.Parallel()
 
@@ -3438,38 +4539,253 @@ func Test_Client_JobRetry(t *testing.T) {
 		require.WithinDuration(t, time.Now().UTC(), job.ScheduledAt, 5*time.Second)
 	})
 
-	t.Run("TxVariantAlsoUpdatesJobToAvailable", func(t *testing.T) {
+	t.Run("TxVariantAlsoUpdatesJobToAvailable", func(t *testing.T) {
+		t.Parallel()
+
+		client, bundle := setup(t)
+
+		insertRes, err := client.Insert(ctx, noOpArgs{}, &InsertOpts{ScheduledAt: time.Now().Add(time.Hour)})
+		require.NoError(t, err)
+		require.Equal(t, rivertype.JobStateScheduled, insertRes.Job.State)
+
+		var jobAfter *rivertype.JobRow
+
+		err = pgx.BeginFunc(ctx, bundle.dbPool, func(tx pgx.Tx) error {
+			var err error
+			jobAfter, err = client.JobRetryTx(ctx, tx, insertRes.Job.ID)
+			return err
+		})
+		require.NoError(t, err)
+		require.NotNil(t, jobAfter)
+
+		require.Equal(t, rivertype.JobStateAvailable, jobAfter.State)
+		require.WithinDuration(t, time.Now().UTC(), jobAfter.ScheduledAt, 5*time.Second)
+	})
+
+	t.Run("ReturnsErrNotFoundIfJobDoesNotExist", func(t *testing.T) {
+		t.Parallel()
+
+		client, _ := setup(t)
+
+		job, err := client.JobRetry(ctx, 0)
+		require.Error(t, err)
+		require.ErrorIs(t, err, ErrNotFound)
+		require.Nil(t, job)
+	})
+}
+
+func Test_Client_JobRetryMany(t *testing.T) {
+	t.Parallel()
+
+	ctx := context.Background()
+
+	type testBundle struct {
+		exec riverdriver.Executor
+	}
+
+	setup := func(t *testing.T) (*Client[pgx.Tx], *testBundle) {
+		t.Helper()
+
+		dbPool := riverinternaltest.TestDB(ctx, t)
+		config := newTestConfig(t, nil)
+		client := newTestClient(t, dbPool, config)
+
+		return client, &testBundle{
+			exec: client.driver.GetExecutor(),
+		}
+	}
+
+	t.Run("RetriesJobsMatchingFilters", func(t *testing.T) {
+		t.Parallel()
+
+		client, bundle := setup(t)
+
+		job1 := testfactory.Job(ctx, t, bundle.exec, &testfactory.JobOpts{FinalizedAt: ptrutil.Ptr(time.Now()), State: ptrutil.Ptr(rivertype.JobStateDiscarded)})
+		job2 := testfactory.Job(ctx, t, bundle.exec, &testfactory.JobOpts{FinalizedAt: ptrutil.Ptr(time.Now()), State: ptrutil.Ptr(rivertype.JobStateCancelled)})
+		job3 := testfactory.Job(ctx, t, bundle.exec, &testfactory.JobOpts{State: ptrutil.Ptr(rivertype.JobStateRunning)})
+		job4 := testfactory.Job(ctx, t, bundle.exec, &testfactory.JobOpts{FinalizedAt: ptrutil.Ptr(time.Now()), State: ptrutil.Ptr(rivertype.JobStateCompleted)})
+
+		res, err := client.JobRetryMany(ctx, NewJobListParams().States(rivertype.JobStateCancelled, rivertype.JobStateDiscarded, rivertype.JobStateRunning), nil)
+		require.NoError(t, err)
+		require.Equal(t, 2, res.NumRetried)
+		require.Equal(t, 1, res.NumSkipped)
+		require.Equal(t, []int64{job1.ID, job2.ID}, sliceutil.Map(res.Jobs, func(j *rivertype.JobRow) int64 { return j.ID }))
+		for _, job := range res.Jobs {
+			require.Equal(t, rivertype.JobStateAvailable, job.State)
+			require.Nil(t, job.FinalizedAt)
+		}
+
+		job3Updated, err := client.JobGet(ctx, job3.ID)
+		require.NoError(t, err)
+		require.Equal(t, rivertype.JobStateRunning, job3Updated.State)
+
+		job4Updated, err := client.JobGet(ctx, job4.ID)
+		require.NoError(t, err)
+		require.Equal(t, rivertype.JobStateCompleted, job4Updated.State)
+	})
+
+	t.Run("OperatesInBatches", func(t *testing.T) {
+		t.Parallel()
+
+		client, bundle := setup(t)
+		client.jobManyBatchSize = 2
+
+		for range 5 {
+			_ = testfactory.Job(ctx, t, bundle.exec, &testfactory.JobOpts{FinalizedAt: ptrutil.Ptr(time.Now()), State: ptrutil.Ptr(rivertype.JobStateDiscarded)})
+		}
+
+		res, err := client.JobRetryMany(ctx, NewJobListParams().States(rivertype.JobStateDiscarded), nil)
+		require.NoError(t, err)
+		require.Equal(t, 5, res.NumRetried)
+	})
+}
+
+func Test_Client_JobUpdate(t *testing.T) {
+	t.Parallel()
+
+	ctx := context.Background()
+
+	type testBundle struct {
+		dbPool *pgxpool.Pool
+		exec   riverdriver.Executor
+	}
+
+	setup := func(t *testing.T) (*Client[pgx.Tx], *testBundle) {
+		t.Helper()
+
+		dbPool := riverinternaltest.TestDB(ctx, t)
+		config := newTestConfig(t, nil)
+		client := newTestClient(t, dbPool, config)
+
+		return client, &testBundle{
+			dbPool: dbPool,
+			exec:   client.driver.GetExecutor(),
+		}
+	}
+
+	t.Run("UpdatesQueuedJob", func(t *testing.T) {
+		t.Parallel()
+
+		client, _ := setup(t)
+
+		insertRes, err := client.Insert(ctx, noOpArgs{}, &InsertOpts{Metadata: []byte(`{"foo":"bar"}`)})
+		require.NoError(t, err)
+
+		scheduledAt := time.Now().Add(time.Hour)
+
+		job, err := client.JobUpdate(ctx, insertRes.Job.ID, &JobUpdateParams{
+			MaxAttempts: 7,
+			Metadata:    []byte(`{"baz":"qux"}`),
+			Priority:    2,
+			Queue:       "other_queue",
+			ScheduledAt: scheduledAt,
+			Tags:        []string{"updated"},
+		})
+		require.NoError(t, err)
+		require.Equal(t, 7, job.MaxAttempts)
+		require.JSONEq(t, `{"foo":"bar","baz":"qux"}`, string(job.Metadata))
+		require.Equal(t, 2, job.Priority)
+		require.Equal(t, "other_queue", job.Queue)
+		require.WithinDuration(t, scheduledAt, job.ScheduledAt, time.Millisecond)
+		require.Equal(t, rivertype.JobStateScheduled, job.State)
+		require.Equal(t, []string{"updated"}, job.Tags)
+	})
+
+	t.Run("ReplacesMetadata", func(t *testing.T) {
+		t.Parallel()
+
+		client, _ := setup(t)
+
+		insertRes, err := client.Insert(ctx, noOpArgs{}, &InsertOpts{Metadata: []byte(`{"foo":"bar"}`)})
+		require.NoError(t, err)
+
+		job, err := client.JobUpdate(ctx, insertRes.Job.ID, &JobUpdateParams{
+			Metadata:        []byte(`{"baz":"qux"}`),
+			MetadataReplace: true,
+		})
+		require.NoError(t, err)
+		require.JSONEq(t, `{"baz":"qux"}`, string(job.Metadata))
+	})
+
+	t.Run("RunningJobNotUpdatable", func(t *testing.T) {
+		t.Parallel()
+
+		client, bundle := setup(t)
+
+		job := testfactory.Job(ctx, t, bundle.exec, &testfactory.JobOpts{State: ptrutil.Ptr(rivertype.JobStateRunning)})
+
+		_, err := client.JobUpdate(ctx, job.ID, &JobUpdateParams{Priority: 2})
+		var notUpdatableErr *JobNotUpdatableError
+		require.ErrorAs(t, err, &notUpdatableErr)
+		require.Equal(t, job.ID, notUpdatableErr.ID)
+		require.Equal(t, rivertype.JobStateRunning, notUpdatableErr.State)
+
+		jobAfter, err := client.JobGet(ctx, job.ID)
+		require.NoError(t, err)
+		require.Equal(t, job.Priority, jobAfter.Priority)
+	})
+
+	t.Run("FinalizedJobNotUpdatable", func(t *testing.T) {
+		t.Parallel()
+
+		client, bundle := setup(t)
+
+		job := testfactory.Job(ctx, t, bundle.exec, &testfactory.JobOpts{FinalizedAt: ptrutil.Ptr(time.Now()), State: ptrutil.Ptr(rivertype.JobStateCompleted)})
+
+		_, err := client.JobUpdate(ctx, job.ID, &JobUpdateParams{Priority: 2})
+		require.ErrorIs(t, err, &JobNotUpdatableError{})
+	})
+
+	t.Run("NotFound", func(t *testing.T) {
+		t.Parallel()
+
+		client, _ := setup(t)
+
+		_, err := client.JobUpdate(ctx, 0, &JobUpdateParams{Priority: 2})
+		require.ErrorIs(t, err, ErrNotFound)
+	})
+
+	t.Run("ValidatesParams", func(t *testing.T) {
 		t.Parallel()
 
-		client, bundle := setup(t)
+		client, _ := setup(t)
 
-		insertRes, err := client.Insert(ctx, noOpArgs{}, &InsertOpts{ScheduledAt: time.Now().Add(time.Hour)})
-		require.NoError(t, err)
-		require.Equal(t, rivertype.JobStateScheduled, insertRes.Job.State)
+		_, err := client.JobUpdate(ctx, 0, &JobUpdateParams{Priority: 5})
+		require.EqualError(t, err, "priority must be between 1 and 4")
 
-		var jobAfter *rivertype.JobRow
+		_, err = client.JobUpdate(ctx, 0, &JobUpdateParams{Metadata: []byte("not json")})
+		require.EqualError(t, err, "metadata must be valid JSON")
 
-		err = pgx.BeginFunc(ctx, bundle.dbPool, func(tx pgx.Tx) error {
-			var err error
-			jobAfter, err = client.JobRetryTx(ctx, tx, insertRes.Job.ID)
-			return err
-		})
-		require.NoError(t, err)
-		require.NotNil(t, jobAfter)
+		_, err = client.JobUpdate(ctx, 0, &JobUpdateParams{Metadata: []byte(`["not", "an", "object"]`)})
+		require.EqualError(t, err, "metadata must be a JSON object")
 
-		require.Equal(t, rivertype.JobStateAvailable, jobAfter.State)
-		require.WithinDuration(t, time.Now().UTC(), jobAfter.ScheduledAt, 5*time.Second)
+		_, err = client.JobUpdate(ctx, 0, &JobUpdateParams{Metadata: []byte(`"string"`), MetadataReplace: true})
+		require.EqualError(t, err, "metadata must be a JSON object")
+
+		_, err = client.JobUpdate(ctx, 0, &JobUpdateParams{Tags: []string{"commas,bad"}})
+		require.EqualError(t, err, "tags should match regex "+tagRE.String())
 	})
 
-	t.Run("ReturnsErrNotFoundIfJobDoesNotExist", func(t *testing.T) {
+	t.Run("TxVariant", func(t *testing.T) {
 		t.Parallel()
 
-		client, _ := setup(t)
+		client, bundle := setup(t)
 
-		job, err := client.JobRetry(ctx, 0)
-		require.Error(t, err)
-		require.ErrorIs(t, err, ErrNotFound)
-		require.Nil(t, job)
+		insertRes, err := client.Insert(ctx, noOpArgs{}, nil)
+		require.NoError(t, err)
+
+		tx, err := bundle.dbPool.Begin(ctx)
+		require.NoError(t, err)
+		t.Cleanup(func() { tx.Rollback(ctx) })
+
+		job, err := client.JobUpdateTx(ctx, tx, insertRes.Job.ID, &JobUpdateParams{Priority: 3})
+		require.NoError(t, err)
+		require.Equal(t, 3, job.Priority)
+
+		// Not visible outside the transaction.
+		jobAfter, err := client.JobGet(ctx, insertRes.Job.ID)
+		require.NoError(t, err)
+		require.Equal(t, 1, jobAfter.Priority)
 	})
 }
 
@@ -3614,6 +4930,37 @@ func Test_Client_Maintenance(t *testing.T) {
 		riversharedtest.WaitOrTimeout(t, client.queueMaintainer.Started())
 	}
 
+	t.Run("BatchFinisher", func(t *testing.T) {
+		t.Parallel()
+
+		config := newTestConfig(t, nil)
+		config.Queues = map[string]QueueConfig{"another_queue": {MaxWorkers: 1}} // don't work jobs on the default queue we're using in this test
+
+		client, bundle := setup(t, config)
+
+		// Take care to insert jobs before starting the client because otherwise
+		// there's a race condition where the finisher could run its initial
+		// pass before our insertion is complete.
+		testfactory.Job(ctx, t, bundle.exec, &testfactory.JobOpts{Metadata: []byte(`{"river:batch_id": "batch1"}`), State: ptrutil.Ptr(rivertype.JobStateCompleted)})
+		testfactory.Job(ctx, t, bundle.exec, &testfactory.JobOpts{Metadata: []byte(`{"river:batch_id": "batch2"}`), State: ptrutil.Ptr(rivertype.JobStateAvailable)})
+
+		finishedOnFinish := testfactory.Job(ctx, t, bundle.exec, &testfactory.JobOpts{Metadata: []byte(`{"river:batch_on_finish": "batch1"}`), State: ptrutil.Ptr(rivertype.JobStatePending)})
+		unfinishedOnFinish := testfactory.Job(ctx, t, bundle.exec, &testfactory.JobOpts{Metadata: []byte(`{"river:batch_on_finish": "batch2"}`), State: ptrutil.Ptr(rivertype.JobStatePending)})
+
+		startAndWaitForQueueMaintainer(ctx, t, client)
+
+		svc := maintenance.GetService[*maintenance.BatchFinisher](client.queueMaintainer)
+		svc.TestSignals.FinishedBatch.WaitOrTimeout()
+
+		job, err := client.JobGet(ctx, finishedOnFinish.ID)
+		require.NoError(t, err)
+		require.Equal(t, rivertype.JobStateAvailable, job.State)
+
+		job, err = client.JobGet(ctx, unfinishedOnFinish.ID)
+		require.NoError(t, err)
+		require.Equal(t, rivertype.JobStatePending, job.State)
+	})
+
 	t.Run("JobCleaner", func(t *testing.T) {
 		t.Parallel()
 
@@ -4058,17 +5405,339 @@ func Test_Client_Maintenance(t *testing.T) {
 	t.Run("Reindexer", func(t *testing.T) {
 		t.Parallel()
 
-		config := newTestConfig(t, nil)
-		config.ReindexerSchedule = &runOnceSchedule{}
+		config := newTestConfig(t, nil)
+		config.ReindexerSchedule = &runOnceSchedule{}
+
+		client, _ := setup(t, config)
+
+		startAndWaitForQueueMaintainer(ctx, t, client)
+
+		svc := maintenance.GetService[*maintenance.Reindexer](client.queueMaintainer)
+		// There are two indexes to reindex by default:
+		svc.TestSignals.Reindexed.WaitOrTimeout()
+		svc.TestSignals.Reindexed.WaitOrTimeout()
+	})
+
+	t.Run("SequencePromoter", func(t *testing.T) {
+		t.Parallel()
+
+		config := newTestConfig(t, nil)
+		config.Queues = map[string]QueueConfig{"another_queue": {MaxWorkers: 1}} // don't work jobs on the default queue we're using in this test
+
+		client, _ := setup(t, config)
+
+		type AccountEventArgs struct {
+			JobArgsReflectKind[AccountEventArgs]
+			AccountID int    `json:"account_id" river:"sequence"`
+			Event     string `json:"event"`
+		}
+
+		insertOpts := &InsertOpts{SequenceOpts: SequenceOpts{ByArgs: true}}
+
+		// Take care to insert jobs before starting the client because otherwise
+		// there's a race condition where the promoter could run its initial
+		// pass before our insertion is complete.
+		insertRes1, err := client.Insert(ctx, AccountEventArgs{AccountID: 1, Event: "created"}, insertOpts)
+		require.NoError(t, err)
+		require.Equal(t, rivertype.JobStatePending, insertRes1.Job.State)
+
+		insertRes2, err := client.Insert(ctx, AccountEventArgs{AccountID: 1, Event: "updated"}, insertOpts)
+		require.NoError(t, err)
+		require.Equal(t, rivertype.JobStatePending, insertRes2.Job.State)
+
+		insertRes3, err := client.Insert(ctx, AccountEventArgs{AccountID: 2, Event: "created"}, insertOpts)
+		require.NoError(t, err)
+		require.Equal(t, rivertype.JobStatePending, insertRes3.Job.State)
+
+		startAndWaitForQueueMaintainer(ctx, t, client)
+
+		svc := maintenance.GetService[*maintenance.SequencePromoter](client.queueMaintainer)
+		svc.TestSignals.PromotedSequence.WaitOrTimeout()
+
+		// The head of each account's sequence is available, but the second
+		// event for account 1 waits until the first is completed.
+		job, err := client.JobGet(ctx, insertRes1.Job.ID)
+		require.NoError(t, err)
+		require.Equal(t, rivertype.JobStateAvailable, job.State)
+
+		job, err = client.JobGet(ctx, insertRes2.Job.ID)
+		require.NoError(t, err)
+		require.Equal(t, rivertype.JobStatePending, job.State)
+
+		job, err = client.JobGet(ctx, insertRes3.Job.ID)
+		require.NoError(t, err)
+		require.Equal(t, rivertype.JobStateAvailable, job.State)
+	})
+
+	t.Run("WorkflowPromoter", func(t *testing.T) {
+		t.Parallel()
+
+		config := newTestConfig(t, nil)
+		config.Queues = map[string]QueueConfig{"another_queue": {MaxWorkers: 1}} // don't work jobs on the default queue we're using in this test
+
+		client, bundle := setup(t, config)
+
+		// Take care to insert jobs before starting the client because otherwise
+		// there's a race condition where the promoter could run its initial
+		// pass before our insertion is complete.
+		testfactory.Job(ctx, t, bundle.exec, &testfactory.JobOpts{Metadata: []byte(`{"river:workflow_id": "wf1", "river:workflow_task": "a"}`), State: ptrutil.Ptr(rivertype.JobStateCompleted)})
+		testfactory.Job(ctx, t, bundle.exec, &testfactory.JobOpts{Metadata: []byte(`{"river:workflow_id": "wf1", "river:workflow_task": "b"}`), State: ptrutil.Ptr(rivertype.JobStateCancelled)})
+
+		promotedTask := testfactory.Job(ctx, t, bundle.exec, &testfactory.JobOpts{Metadata: []byte(`{"river:workflow_id": "wf1", "river:workflow_task": "c", "river:workflow_deps": ["a"]}`), State: ptrutil.Ptr(rivertype.JobStatePending)})
+		cancelledTask := testfactory.Job(ctx, t, bundle.exec, &testfactory.JobOpts{Metadata: []byte(`{"river:workflow_id": "wf1", "river:workflow_task": "d", "river:workflow_deps": ["a", "b"]}`), State: ptrutil.Ptr(rivertype.JobStatePending)})
+
+		startAndWaitForQueueMaintainer(ctx, t, client)
+
+		svc := maintenance.GetService[*maintenance.WorkflowPromoter](client.queueMaintainer)
+		svc.TestSignals.PromotedBatch.WaitOrTimeout()
+
+		job, err := client.JobGet(ctx, promotedTask.ID)
+		require.NoError(t, err)
+		require.Equal(t, rivertype.JobStateAvailable, job.State)
+
+		job, err = client.JobGet(ctx, cancelledTask.ID)
+		require.NoError(t, err)
+		require.Equal(t, rivertype.JobStateCancelled, job.State)
+	})
+}
+
+func Test_Client_BatchGet(t *testing.T) {
+	t.Parallel()
+
+	ctx := context.Background()
+
+	type testBundle struct {
+		dbPool *pgxpool.Pool
+		exec   riverdriver.Executor
+	}
+
+	setup := func(t *testing.T) (*Client[pgx.Tx], *testBundle) {
+		t.Helper()
+
+		dbPool := riverinternaltest.TestDB(ctx, t)
+		config := newTestConfig(t, nil)
+		client := newTestClient(t, dbPool, config)
+
+		return client, &testBundle{
+			dbPool: dbPool,
+			exec:   client.driver.GetExecutor(),
+		}
+	}
+
+	t.Run("ReturnsProgress", func(t *testing.T) {
+		t.Parallel()
+
+		client, bundle := setup(t)
+
+		batch := NewBatch(nil).
+			Add(noOpArgs{Name: "job1"}, nil).
+			Add(noOpArgs{Name: "job2"}, nil).
+			Add(noOpArgs{Name: "job3"}, nil).
+			OnFinish(noOpArgs{Name: "on_finish"}, nil)
+
+		params, err := batch.Prepare()
+		require.NoError(t, err)
+
+		results, err := client.InsertMany(ctx, params)
+		require.NoError(t, err)
+		require.Len(t, results, 4)
+		require.Equal(t, rivertype.JobStatePending, results[3].Job.State)
+
+		status, err := client.BatchGet(ctx, batch.ID())
+		require.NoError(t, err)
+		require.Equal(t, &BatchStatus{
+			CountsByState: map[rivertype.JobState]int{rivertype.JobStateAvailable: 3},
+			Finished:      false,
+			ID:            batch.ID(),
+			NumFinalized:  0,
+			NumJobs:       3,
+		}, status)
+
+		for _, result := range results[0:3] {
+			_, err := bundle.exec.JobUpdate(ctx, &riverdriver.JobUpdateParams{
+				ID:                  result.Job.ID,
+				FinalizedAtDoUpdate: true,
+				FinalizedAt:         ptrutil.Ptr(time.Now()),
+				StateDoUpdate:       true,
+				State:               rivertype.JobStateCompleted,
+			})
+			require.NoError(t, err)
+		}
+
+		status, err = client.BatchGet(ctx, batch.ID())
+		require.NoError(t, err)
+		require.Equal(t, &BatchStatus{
+			CountsByState: map[rivertype.JobState]int{rivertype.JobStateCompleted: 3},
+			Finished:      true,
+			ID:            batch.ID(),
+			NumFinalized:  3,
+			NumJobs:       3,
+		}, status)
+	})
+
+	t.Run("ReturnsErrNotFoundForUnknownBatch", func(t *testing.T) {
+		t.Parallel()
+
+		client, _ := setup(t)
+
+		_, err := client.BatchGet(ctx, "does_not_exist")
+		require.ErrorIs(t, err, ErrNotFound)
+	})
+
+	t.Run("ErrorsOnEmptyBatchID", func(t *testing.T) {
+		t.Parallel()
+
+		client, _ := setup(t)
+
+		_, err := client.BatchGet(ctx, "")
+		require.EqualError(t, err, "batch ID must not be empty")
+	})
+
+	t.Run("WithinTransaction", func(t *testing.T) {
+		t.Parallel()
+
+		client, bundle := setup(t)
+
+		tx, err := bundle.dbPool.Begin(ctx)
+		require.NoError(t, err)
+		t.Cleanup(func() { tx.Rollback(ctx) })
+
+		params, err := NewBatch(&BatchOpts{ID: "tx_batch"}).Add(noOpArgs{}, nil).Prepare()
+		require.NoError(t, err)
+
+		_, err = client.InsertManyTx(ctx, tx, params)
+		require.NoError(t, err)
+
+		status, err := client.BatchGetTx(ctx, tx, "tx_batch")
+		require.NoError(t, err)
+		require.Equal(t, 1, status.NumJobs)
+
+		// Not visible outside the transaction.
+		_, err = client.BatchGet(ctx, "tx_batch")
+		require.ErrorIs(t, err, ErrNotFound)
+	})
+}
+
+func Test_Client_WorkflowTaskList(t *testing.T) {
+	t.Parallel()
+
+	ctx := context.Background()
+
+	type testBundle struct {
+		dbPool *pgxpool.Pool
+	}
+
+	setup := func(t *testing.T) (*Client[pgx.Tx], *testBundle) {
+		t.Helper()
+
+		dbPool := riverinternaltest.TestDB(ctx, t)
+		config := newTestConfig(t, nil)
+		client := newTestClient(t, dbPool, config)
+
+		return client, &testBundle{dbPool: dbPool}
+	}
+
+	insertWorkflow := func(t *testing.T, client *Client[pgx.Tx]) *Workflow {
+		t.Helper()
+
+		depJob, err := client.Insert(ctx, noOpArgs{}, nil)
+		require.NoError(t, err)
+
+		workflow := NewWorkflow(nil).
+			Add("a", noOpArgs{}, nil, nil).
+			Add("b", noOpArgs{}, nil, &WorkflowTaskOpts{Deps: []string{"a"}}).
+			Add("c", noOpArgs{}, nil, &WorkflowTaskOpts{DepJobIDs: []int64{depJob.Job.ID}, Deps: []string{"a", "b"}})
+
+		params, err := workflow.Prepare()
+		require.NoError(t, err)
+
+		_, err = client.InsertMany(ctx, params)
+		require.NoError(t, err)
+
+		return workflow
+	}
+
+	t.Run("ListsTasks", func(t *testing.T) {
+		t.Parallel()
+
+		client, _ := setup(t)
+
+		workflow := insertWorkflow(t, client)
+		_ = insertWorkflow(t, client) // other workflow that shouldn't be included
+
+		tasks, err := client.WorkflowTaskList(ctx, workflow.ID())
+		require.NoError(t, err)
+		require.Len(t, tasks, 3)
+
+		require.Equal(t, "a", tasks[0].Name)
+		require.Empty(t, tasks[0].Deps)
+		require.Equal(t, rivertype.JobStateAvailable, tasks[0].Job.State)
+
+		require.Equal(t, "b", tasks[1].Name)
+		require.Equal(t, []string{"a"}, tasks[1].Deps)
+		require.Equal(t, rivertype.JobStatePending, tasks[1].Job.State)
+
+		require.Equal(t, "c", tasks[2].Name)
+		require.Equal(t, []string{"a", "b"}, tasks[2].Deps)
+		require.Len(t, tasks[2].DepJobIDs, 1)
+		require.Equal(t, rivertype.JobStatePending, tasks[2].Job.State)
+	})
+
+	t.Run("Paginates", func(t *testing.T) {
+		t.Parallel()
+
+		client, _ := setup(t)
+		client.jobManyBatchSize = 2
+
+		workflow := insertWorkflow(t, client)
+
+		tasks, err := client.WorkflowTaskList(ctx, workflow.ID())
+		require.NoError(t, err)
+		require.Equal(t, []string{"a", "b", "c"}, sliceutil.Map(tasks, func(task *WorkflowTask) string { return task.Name }))
+	})
+
+	t.Run("ReturnsEmptyForUnknownWorkflow", func(t *testing.T) {
+		t.Parallel()
+
+		client, _ := setup(t)
+
+		tasks, err := client.WorkflowTaskList(ctx, "does_not_exist")
+		require.NoError(t, err)
+		require.Empty(t, tasks)
+	})
+
+	t.Run("ErrorsOnEmptyWorkflowID", func(t *testing.T) {
+		t.Parallel()
+
+		client, _ := setup(t)
+
+		_, err := client.WorkflowTaskList(ctx, "")
+		require.EqualError(t, err, "workflow ID must not be empty")
+	})
+
+	t.Run("WithinTransaction", func(t *testing.T) {
+		t.Parallel()
+
+		client, bundle := setup(t)
+
+		tx, err := bundle.dbPool.Begin(ctx)
+		require.NoError(t, err)
+		t.Cleanup(func() { tx.Rollback(ctx) })
+
+		params, err := NewWorkflow(&WorkflowOpts{ID: "tx_workflow"}).Add("a", noOpArgs{}, nil, nil).Prepare()
+		require.NoError(t, err)
 
-		client, _ := setup(t, config)
+		_, err = client.InsertManyTx(ctx, tx, params)
+		require.NoError(t, err)
 
-		startAndWaitForQueueMaintainer(ctx, t, client)
+		tasks, err := client.WorkflowTaskListTx(ctx, tx, "tx_workflow")
+		require.NoError(t, err)
+		require.Len(t, tasks, 1)
 
-		svc := maintenance.GetService[*maintenance.Reindexer](client.queueMaintainer)
-		// There are two indexes to reindex by default:
-		svc.TestSignals.Reindexed.WaitOrTimeout()
-		svc.TestSignals.Reindexed.WaitOrTimeout()
+		// Not visible outside the transaction.
+		tasks, err = client.WorkflowTaskList(ctx, "tx_workflow")
+		require.NoError(t, err)
+		require.Empty(t, tasks)
 	})
 }
 
@@ -4182,6 +5851,119 @@ func Test_Client_QueueGetTx(t *testing.T) {
 	})
 }
 
+func Test_Client_QueueStats(t *testing.T) {
+	t.Parallel()
+
+	ctx := context.Background()
+
+	type testBundle struct {
+		exec riverdriver.Executor
+	}
+
+	setup := func(t *testing.T) (*Client[pgx.Tx], *testBundle) {
+		t.Helper()
+
+		dbPool := riverinternaltest.TestDB(ctx, t)
+		config := newTestConfig(t, nil)
+		client := newTestClient(t, dbPool, config)
+
+		return client, &testBundle{exec: client.driver.GetExecutor()}
+	}
+
+	t.Run("ReturnsStats", func(t *testing.T) {
+		t.Parallel()
+
+		client, bundle := setup(t)
+
+		now := time.Now().UTC()
+
+		_ = testfactory.Job(ctx, t, bundle.exec, &testfactory.JobOpts{Kind: ptrutil.Ptr("kind1"), ScheduledAt: ptrutil.Ptr(now.Add(-time.Minute)), State: ptrutil.Ptr(rivertype.JobStateAvailable)})
+		_ = testfactory.Job(ctx, t, bundle.exec, &testfactory.JobOpts{AttemptedBy: []string{"client1"}, Kind: ptrutil.Ptr("kind1"), State: ptrutil.Ptr(rivertype.JobStateRunning)})
+		for range 3 {
+			_ = testfactory.Job(ctx, t, bundle.exec, &testfactory.JobOpts{FinalizedAt: ptrutil.Ptr(now.Add(-time.Minute)), Kind: ptrutil.Ptr("kind2"), State: ptrutil.Ptr(rivertype.JobStateCompleted)})
+		}
+		_ = testfactory.Job(ctx, t, bundle.exec, &testfactory.JobOpts{FinalizedAt: ptrutil.Ptr(now.Add(-time.Minute)), Kind: ptrutil.Ptr("kind2"), State: ptrutil.Ptr(rivertype.JobStateCancelled)})
+		_ = testfactory.Job(ctx, t, bundle.exec, &testfactory.JobOpts{FinalizedAt: ptrutil.Ptr(now.Add(-time.Minute)), Kind: ptrutil.Ptr("kind2"), State: ptrutil.Ptr(rivertype.JobStateDiscarded)})
+
+		// Finalized before the throughput window, so not counted.
+		_ = testfactory.Job(ctx, t, bundle.exec, &testfactory.JobOpts{FinalizedAt: ptrutil.Ptr(now.Add(-time.Hour)), Kind: ptrutil.Ptr("kind2"), State: ptrutil.Ptr(rivertype.JobStateCompleted)})
+
+		stats, err := client.QueueStats(ctx, QueueDefault)
+		require.NoError(t, err)
+		require.Equal(t, 1, stats.CancelledCount)
+		require.Equal(t, 3, stats.CompletedCount)
+		require.InDelta(t, 3.0/300, stats.CompletedPerSecond, 0.0001)
+		require.Equal(t, map[rivertype.JobState]int{
+			rivertype.JobStateAvailable: 1,
+			rivertype.JobStatePending:   0,
+			rivertype.JobStateRetryable: 0,
+			rivertype.JobStateRunning:   1,
+			rivertype.JobStateScheduled: 0,
+		}, stats.CountsByState)
+		require.Equal(t, 1, stats.DiscardedCount)
+		require.Empty(t, stats.Kind)
+		require.InDelta(t, time.Minute.Seconds(), stats.OldestAvailableAge.Seconds(), 5)
+		require.Equal(t, QueueDefault, stats.Queue)
+		require.Equal(t, map[string]int{"client1": 1}, stats.RunningByClient)
+		require.Equal(t, 5*time.Minute, stats.ThroughputWindow)
+
+		stats, err = client.QueueStatsByKind(ctx, QueueDefault, "kind1")
+		require.NoError(t, err)
+		require.Zero(t, stats.CompletedCount)
+		require.Equal(t, 1, stats.CountsByState[rivertype.JobStateAvailable])
+		require.Zero(t, stats.DiscardedCount)
+		require.Equal(t, "kind1", stats.Kind)
+		require.Equal(t, map[string]int{"client1": 1}, stats.RunningByClient)
+	})
+
+	t.Run("QueueWithoutJobs", func(t *testing.T) {
+		t.Parallel()
+
+		client, _ := setup(t)
+
+		stats, err := client.QueueStats(ctx, "queue_without_jobs")
+		require.NoError(t, err)
+		require.Zero(t, stats.CompletedCount)
+		require.Zero(t, stats.CountsByState[rivertype.JobStateAvailable])
+		require.Zero(t, stats.OldestAvailableAge)
+		require.Empty(t, stats.RunningByClient)
+	})
+
+	t.Run("QueueStatsByKindEmptyKind", func(t *testing.T) {
+		t.Parallel()
+
+		client, _ := setup(t)
+
+		_, err := client.QueueStatsByKind(ctx, QueueDefault, "")
+		require.EqualError(t, err, "kind must not be empty")
+	})
+
+	t.Run("QueueStatsMany", func(t *testing.T) {
+		t.Parallel()
+
+		client, bundle := setup(t)
+
+		_ = testfactory.Job(ctx, t, bundle.exec, &testfactory.JobOpts{Queue: ptrutil.Ptr("queue1"), State: ptrutil.Ptr(rivertype.JobStateAvailable)})
+		_ = testfactory.Job(ctx, t, bundle.exec, &testfactory.JobOpts{Queue: ptrutil.Ptr("queue2"), State: ptrutil.Ptr(rivertype.JobStateScheduled)})
+
+		stats, err := client.QueueStatsMany(ctx, []string{"queue2", "queue1", "queue_without_jobs"})
+		require.NoError(t, err)
+		require.Len(t, stats, 3)
+
+		require.Equal(t, "queue1", stats[0].Queue)
+		require.Equal(t, 1, stats[0].CountsByState[rivertype.JobStateAvailable])
+		require.Equal(t, "queue2", stats[1].Queue)
+		require.Equal(t, 1, stats[1].CountsByState[rivertype.JobStateScheduled])
+		require.Equal(t, "queue_without_jobs", stats[2].Queue)
+		require.Zero(t, stats[2].CountsByState[rivertype.JobStateAvailable])
+		require.Len(t, stats[2].CountsByState, 5)
+
+		stats, err = client.QueueStatsMany(ctx, nil)
+		require.NoError(t, err)
+		require.Empty(t, stats)
+	})
+}
+
 func Test_Client_QueueList(t *testing.T) {
 	t.Parallel()
 
@@ -4434,6 +6216,47 @@ func Test_Client_QueueUpdateTx(t *testing.T) {
 		require.NoError(t, err)
 		require.JSONEq(t, `{}`, string(queue.Metadata))
 	})
+
+	t.Run("UpdatesConcurrency", func(t *testing.T) {
+		t.Parallel()
+
+		client, bundle := setup(t)
+
+		queue := testfactory.Queue(ctx, t, bundle.executorTx, &testfactory.QueueOpts{Metadata: []byte(`{"foo":"bar"}`)})
+
+		queue, err := client.QueueUpdateTx(ctx, bundle.tx, queue.Name, &QueueUpdateParams{
+			Concurrency: &ConcurrencyConfig{GlobalLimit: 5},
+		})
+		require.NoError(t, err)
+		require.JSONEq(t, `{"foo":"bar","river:concurrency":{"global_limit":5}}`, string(queue.Metadata))
+
+		// Replacing metadata preserves the concurrency override.
+		queue, err = client.QueueUpdateTx(ctx, bundle.tx, queue.Name, &QueueUpdateParams{
+			Metadata: []byte(`{"foo":"baz"}`),
+		})
+		require.NoError(t, err)
+		require.JSONEq(t, `{"foo":"baz","river:concurrency":{"global_limit":5}}`, string(queue.Metadata))
+
+		// An empty config removes the override.
+		queue, err = client.QueueUpdateTx(ctx, bundle.tx, queue.Name, &QueueUpdateParams{
+			Concurrency: &ConcurrencyConfig{},
+		})
+		require.NoError(t, err)
+		require.JSONEq(t, `{"foo":"baz"}`, string(queue.Metadata))
+	})
+
+	t.Run("ConcurrencyValidation", func(t *testing.T) {
+		t.Parallel()
+
+		client, bundle := setup(t)
+
+		queue := testfactory.Queue(ctx, t, bundle.executorTx, nil)
+
+		_, err := client.QueueUpdateTx(ctx, bundle.tx, queue.Name, &QueueUpdateParams{
+			Concurrency: &ConcurrencyConfig{GlobalLimit: -1},
+		})
+		require.EqualError(t, err, fmt.Sprintf("invalid global concurrency limit for queue %q: -1", queue.Name))
+	})
 }
 
 func Test_Client_RetryPolicy(t *testing.T) {
@@ -4728,6 +6551,127 @@ func Test_Client_Subscribe(t *testing.T) {
 		require.Equal(t, rivertype.JobStateRetryable, eventFailed.Job.State)
 	})
 
+	t.Run("InsertedAndStarted", func(t *testing.T) {
+		t.Parallel()
+
+		dbPool := riverinternaltest.TestDB(ctx, t)
+
+		config := newTestConfig(t, func(ctx context.Context, job *Job[callbackArgs]) error {
+			return nil
+		})
+
+		client := newTestClient(t, dbPool, config)
+
+		subscribeChan, cancel := client.Subscribe(EventKindJobCompleted, EventKindJobInserted, EventKindJobStarted)
+		t.Cleanup(cancel)
+
+		job := requireInsert(ctx, client, "completed1")
+
+		event := riversharedtest.WaitOrTimeout(t, subscribeChan)
+		require.Equal(t, EventKindJobInserted, event.Kind)
+		require.Equal(t, job.ID, event.Job.ID)
+		require.Equal(t, rivertype.JobStateAvailable, event.Job.State)
+
+		startClient(ctx, t, client)
+
+		event = riversharedtest.WaitOrTimeout(t, subscribeChan)
+		require.Equal(t, EventKindJobStarted, event.Kind)
+		require.Equal(t, job.ID, event.Job.ID)
+		require.Equal(t, rivertype.JobStateRunning, event.Job.State)
+
+		event = riversharedtest.WaitOrTimeout(t, subscribeChan)
+		require.Equal(t, EventKindJobCompleted, event.Kind)
+		require.Equal(t, job.ID, event.Job.ID)
+	})
+
+	t.Run("Discarded", func(t *testing.T) {
+		t.Parallel()
+
+		dbPool := riverinternaltest.TestDB(ctx, t)
+
+		config := newTestConfig(t, func(ctx context.Context, job *Job[callbackArgs]) error {
+			return errors.New("job error")
+		})
+
+		client := newTestClient(t, dbPool, config)
+
+		subscribeChan, cancel := client.Subscribe(EventKindJobDiscarded, EventKindJobFailed)
+		t.Cleanup(cancel)
+
+		insertRes, err := client.Insert(ctx, callbackArgs{Name: "discarded1"}, &InsertOpts{MaxAttempts: 1})
+		require.NoError(t, err)
+
+		startClient(ctx, t, client)
+
+		// Discarded jobs produce both a failed and a discarded event.
+		event := riversharedtest.WaitOrTimeout(t, subscribeChan)
+		require.Equal(t, EventKindJobFailed, event.Kind)
+		require.Equal(t, insertRes.Job.ID, event.Job.ID)
+		require.Equal(t, rivertype.JobStateDiscarded, event.Job.State)
+
+		event = riversharedtest.WaitOrTimeout(t, subscribeChan)
+		require.Equal(t, EventKindJobDiscarded, event.Kind)
+		require.Equal(t, insertRes.Job.ID, event.Job.ID)
+		require.Equal(t, rivertype.JobStateDiscarded, event.Job.State)
+	})
+
+	t.Run("LeadershipAndPeriodicJobEnqueued", func(t *testing.T) {
+		t.Parallel()
+
+		dbPool := riverinternaltest.TestDB(ctx, t)
+
+		config := newTestConfig(t, nil)
+		AddWorker(config.Workers, &periodicJobWorker{})
+		config.PeriodicJobs = []*PeriodicJob{
+			NewPeriodicJob(cron.Every(15*time.Minute), func() (JobArgs, *InsertOpts) {
+				return periodicJobArgs{}, nil
+			}, &PeriodicJobOpts{RunOnStart: true}),
+		}
+
+		client := newTestClient(t, dbPool, config)
+
+		subscribeChan, cancel := client.Subscribe(EventKindLeadershipGained, EventKindPeriodicJobEnqueued)
+		t.Cleanup(cancel)
+
+		startClient(ctx, t, client)
+
+		event := riversharedtest.WaitOrTimeout(t, subscribeChan)
+		require.Equal(t, EventKindLeadershipGained, event.Kind)
+
+		event = riversharedtest.WaitOrTimeout(t, subscribeChan)
+		require.Equal(t, EventKindPeriodicJobEnqueued, event.Kind)
+		require.Equal(t, (periodicJobArgs{}).Kind(), event.Job.Kind)
+	})
+
+	t.Run("QueueAddedAndRemoved", func(t *testing.T) {
+		t.Parallel()
+
+		dbPool := riverinternaltest.TestDB(ctx, t)
+
+		config := newTestConfig(t, nil)
+
+		client := newTestClient(t, dbPool, config)
+
+		subscribeChan, cancel := client.Subscribe(EventKindQueueAdded, EventKindQueueRemoved)
+		t.Cleanup(cancel)
+
+		startClient(ctx, t, client)
+
+		require.NoError(t, client.Queues().Add("new_queue", QueueConfig{MaxWorkers: 1}))
+
+		event := riversharedtest.WaitOrTimeout(t, subscribeChan)
+		require.Equal(t, EventKindQueueAdded, event.Kind)
+		require.Equal(t, "new_queue", event.Queue.Name)
+
+		// Removing a queue that doesn't exist doesn't produce an event.
+		require.NoError(t, client.Queues().Remove("does_not_exist"))
+		require.NoError(t, client.Queues().Remove("new_queue"))
+
+		event = riversharedtest.WaitOrTimeout(t, subscribeChan)
+		require.Equal(t, EventKindQueueRemoved, event.Kind)
+		require.Equal(t, "new_queue", event.Queue.Name)
+	})
+
 	t.Run("PanicOnUnknownKind", func(t *testing.T) {
 		t.Parallel()
 
@@ -5522,10 +7466,12 @@ func Test_NewClient_Defaults(t *testing.T) {
 	require.Nil(t, client.config.ErrorHandler)
 	require.Equal(t, FetchCooldownDefault, client.config.FetchCooldown)
 	require.Equal(t, FetchPollIntervalDefault, client.config.FetchPollInterval)
+	require.Zero(t, client.config.JobLeaseDuration)
 	require.Equal(t, JobTimeoutDefault, client.config.JobTimeout)
 	require.Nil(t, client.config.Hooks)
 	require.NotZero(t, client.baseService.Logger)
 	require.Equal(t, MaxAttemptsDefault, client.config.MaxAttempts)
+	require.Equal(t, ProgressFlushIntervalDefault, client.config.ProgressFlushInterval)
 	require.IsType(t, &DefaultClientRetryPolicy{}, client.config.RetryPolicy)
 	require.False(t, client.config.SkipUnknownJobCheck)
 	require.IsType(t, nil, client.config.Test.Time)
@@ -5568,9 +7514,11 @@ func Test_NewClient_Overrides(t *testing.T) {
 		FetchPollInterval:           124 * time.Millisecond,
 		Hooks:                       []rivertype.Hook{&noOpHook{}},
 		JobInsertMiddleware:         []rivertype.JobInsertMiddleware{&noOpInsertMiddleware{}},
+		JobLeaseDuration:            10 * time.Second,
 		JobTimeout:                  125 * time.Millisecond,
 		Logger:                      logger,
 		MaxAttempts:                 5,
+		ProgressFlushInterval:       126 * time.Millisecond,
 		Queues:                      map[string]QueueConfig{QueueDefault: {MaxWorkers: 1}},
 		ReindexerSchedule:           &periodicIntervalSchedule{interval: time.Hour},
 		RetryPolicy:                 retryPolicy,
@@ -5589,6 +7537,9 @@ func Test_NewClient_Overrides(t *testing.T) {
 	require.Equal(t, 3*time.Hour, jobCleaner.Config.DiscardedJobRetentionPeriod)
 	require.True(t, jobCleaner.StaggerStartupIsDisabled())
 
+	jobRescuer := maintenance.GetService[*maintenance.JobRescuer](client.queueMaintainer)
+	require.Equal(t, 10*time.Second, jobRescuer.Config.Interval)
+
 	enqueuer := maintenance.GetService[*maintenance.PeriodicJobEnqueuer](client.queueMaintainer)
 	require.Equal(t, int32(123_456), enqueuer.Config.AdvisoryLockPrefix)
 	require.True(t, enqueuer.StaggerStartupIsDisabled())
@@ -5601,10 +7552,12 @@ func Test_NewClient_Overrides(t *testing.T) {
 	require.Equal(t, 123*time.Millisecond, client.config.FetchCooldown)
 	require.Equal(t, 124*time.Millisecond, client.config.FetchPollInterval)
 	require.Len(t, client.config.JobInsertMiddleware, 1)
+	require.Equal(t, 10*time.Second, client.config.JobLeaseDuration)
 	require.Equal(t, 125*time.Millisecond, client.config.JobTimeout)
 	require.Equal(t, []rivertype.Hook{&noOpHook{}}, client.config.Hooks)
 	require.Equal(t, logger, client.baseService.Logger)
 	require.Equal(t, 5, client.config.MaxAttempts)
+	require.Equal(t, 126*time.Millisecond, client.config.ProgressFlushInterval)
 	require.Equal(t, retryPolicy, client.config.RetryPolicy)
 	require.True(t, client.config.SkipUnknownJobCheck)
 	require.Len(t, client.config.WorkerMiddleware, 1)
@@ -5701,6 +7654,13 @@ func Test_NewClient_Validations(t *testing.T) {
 			},
 			wantErr: errors.New("ID cannot be longer than 100 characters"),
 		},
+		{
+			name: "JobLeaseDuration cannot be less than zero",
+			configFunc: func(config *Config) {
+				config.JobLeaseDuration = -1
+			},
+			wantErr: errors.New("JobLeaseDuration cannot be less than zero"),
+		},
 		{
 			name: "JobTimeout can be -1 (infinite)",
 			configFunc: func(config *Config) {
@@ -5799,6 +7759,13 @@ func Test_NewClient_Validations(t *testing.T) {
 			},
 			wantErr: errors.New("only one of the pair JobInsertMiddleware/WorkerMiddleware or Middleware may be provided (Middleware is recommended, and may contain both job insert and worker middleware)"),
 		},
+		{
+			name: "ProgressFlushInterval cannot be less than zero",
+			configFunc: func(config *Config) {
+				config.ProgressFlushInterval = -1
+			},
+			wantErr: errors.New("ProgressFlushInterval cannot be less than zero"),
+		},
 		{
 			name: "RescueStuckJobsAfter may be overridden",
 			configFunc: func(config *Config) {
@@ -5856,6 +7823,54 @@ func Test_NewClient_Validations(t *testing.T) {
 			},
 			wantErr: fmt.Errorf("invalid number of workers for queue \"default\": %d", QueueNumWorkersMax+1),
 		},
+		{
+			name: "Queues Concurrency GlobalLimit can't be negative",
+			configFunc: func(config *Config) {
+				config.Queues = map[string]QueueConfig{QueueDefault: {Concurrency: &ConcurrencyConfig{GlobalLimit: -1}, MaxWorkers: 1}}
+			},
+			wantErr: errors.New("invalid global concurrency limit for queue \"default\": -1"),
+		},
+		{
+			name: "Queues Concurrency Partition must be valid",
+			configFunc: func(config *Config) {
+				config.Queues = map[string]QueueConfig{QueueDefault: {Concurrency: &ConcurrencyConfig{Partition: PartitionConfig{ByArgs: true}}, MaxWorkers: 1}}
+			},
+			wantErr: errors.New("invalid concurrency partition for queue \"default\": partition Limit must be set along with ByArgs or ByKind"),
+		},
+		{
+			name: "Queues RateLimit Limit must be greater than zero",
+			configFunc: func(config *Config) {
+				config.Queues = map[string]QueueConfig{QueueDefault: {MaxWorkers: 1, RateLimit: &RateLimitConfig{Period: time.Second}}}
+			},
+			wantErr: errors.New("invalid rate limit for queue \"default\": rate limit Limit must be greater than zero"),
+		},
+		{
+			name: "Queues RateLimit Period must be greater than zero",
+			configFunc: func(config *Config) {
+				config.Queues = map[string]QueueConfig{QueueDefault: {MaxWorkers: 1, RateLimit: &RateLimitConfig{Limit: 1}}}
+			},
+			wantErr: errors.New("invalid rate limit for queue \"default\": rate limit Period must be greater than zero"),
+		},
+		{
+			name: "RateLimitsByKind can be configured",
+			configFunc: func(config *Config) {
+				config.RateLimitsByKind = map[string]*RateLimitConfig{"noOp": {Limit: 10, Period: time.Minute}}
+			},
+		},
+		{
+			name: "RateLimitsByKind entries can't be nil",
+			configFunc: func(config *Config) {
+				config.RateLimitsByKind = map[string]*RateLimitConfig{"noOp": nil}
+			},
+			wantErr: errors.New("rate limit for kind \"noOp\" must not be nil"),
+		},
+		{
+			name: "RateLimitsByKind entries must be valid",
+			configFunc: func(config *Config) {
+				config.RateLimitsByKind = map[string]*RateLimitConfig{"noOp": {Limit: 10}}
+			},
+			wantErr: errors.New("invalid rate limit for kind \"noOp\": rate limit Period must be greater than zero"),
+		},
 		{
 			name: "Queues queue names can't be empty",
 			configFunc: func(config *Config) {
@@ -6256,6 +8271,75 @@ func TestInsertParamsFromJobArgsAndOptions(t *testing.T) {
 		require.Equal(t, params.UniqueKey, params2.UniqueKey, "unique keys should be identical because included args are the same, even though others differ")
 	})
 
+	t.Run("PartitionKeyStoredInMetadata", func(t *testing.T) {
+		t.Parallel()
+
+		type PartitionedArgs struct {
+			JobArgsStaticKind
+			CustomerID int    `json:"customer_id" river:"partition"`
+			Message    string `json:"message"`
+		}
+
+		args := PartitionedArgs{
+			JobArgsStaticKind: JobArgsStaticKind{kind: "partitionedArgs"},
+			CustomerID:        123,
+			Message:           "hello",
+		}
+
+		insertParams, err := insertParamsFromConfigArgsAndOptions(archetype, config, args, &InsertOpts{Metadata: []byte(`{"foo":"bar"}`)})
+		require.NoError(t, err)
+		require.JSONEq(t, `{"foo":"bar","river:partition":{"customer_id":123}}`, string(insertParams.Metadata))
+	})
+
+	t.Run("SequenceOpts", func(t *testing.T) {
+		t.Parallel()
+
+		type AccountEventArgs struct {
+			JobArgsStaticKind
+			AccountID int    `json:"account_id" river:"sequence"`
+			Event     string `json:"event"`
+		}
+
+		args := AccountEventArgs{
+			JobArgsStaticKind: JobArgsStaticKind{kind: "accountEvent"},
+			AccountID:         123,
+			Event:             "created",
+		}
+
+		insertParams, err := insertParamsFromConfigArgsAndOptions(archetype, config, args, &InsertOpts{
+			Metadata:     []byte(`{"foo":"bar"}`),
+			SequenceOpts: SequenceOpts{ByArgs: true, ContinueOnDiscarded: true},
+		})
+		require.NoError(t, err)
+		require.Equal(t, rivertype.JobStatePending, insertParams.State)
+		require.Equal(t, "bar", gjson.GetBytes(insertParams.Metadata, "foo").String())
+		require.Len(t, gjson.GetBytes(insertParams.Metadata, metadataKeySequenceKey).String(), 64)
+		require.True(t, gjson.GetBytes(insertParams.Metadata, metadataKeySequenceContinueOnDiscarded).Bool())
+
+		// Another event for the same account is in the same sequence.
+		args.Event = "updated"
+		insertParams2, err := insertParamsFromConfigArgsAndOptions(archetype, config, args, &InsertOpts{
+			SequenceOpts: SequenceOpts{ByArgs: true},
+		})
+		require.NoError(t, err)
+		require.Equal(t,
+			gjson.GetBytes(insertParams.Metadata, metadataKeySequenceKey).String(),
+			gjson.GetBytes(insertParams2.Metadata, metadataKeySequenceKey).String())
+		require.False(t, gjson.GetBytes(insertParams2.Metadata, metadataKeySequenceContinueOnDiscarded).Exists())
+	})
+
+	t.Run("SequenceOptsAreValidated", func(t *testing.T) {
+		t.Parallel()
+
+		insertParams, err := insertParamsFromConfigArgsAndOptions(archetype, config, noOpArgs{}, &InsertOpts{SequenceOpts: SequenceOpts{ExcludeKind: true}})
+		require.EqualError(t, err, "SequenceOpts requires at least one of ByArgs or ByQueue")
+		require.Nil(t, insertParams)
+
+		insertParams, err = insertParamsFromConfigArgsAndOptions(archetype, config, noOpArgs{}, &InsertOpts{Pending: true, SequenceOpts: SequenceOpts{ByArgs: true}})
+		require.EqualError(t, err, "SequenceOpts can't be combined with Pending")
+		require.Nil(t, insertParams)
+	})
+
 	t.Run("PriorityIsLimitedTo4", func(t *testing.T) {
 		t.Parallel()
 
@@ -0,0 +1,461 @@
+package river
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"log/slog"
+	"sync"
+	"time"
+
+	"github.com/riverqueue/river/internal/notifier"
+	"github.com/riverqueue/river/riverdriver"
+	"github.com/riverqueue/river/rivershared/baseservice"
+	"github.com/riverqueue/river/rivershared/startstop"
+	"github.com/riverqueue/river/rivershared/util/maputil"
+	"github.com/riverqueue/river/rivertype"
+)
+
+const (
+	// Maximum size of a single cluster job event payload. Postgres limits
+	// notification payloads to 8000 bytes, so leave some headroom.
+	clusterEventPayloadMaxBytes = 7_500
+
+	// Maximum number of finalized jobs fetched per query when polling for
+	// cluster job events.
+	clusterEventPollLimit = 1_000
+)
+
+// The kinds of events that can be received with Client.SubscribeCluster.
+var clusterEventKinds = map[EventKind]struct{}{ //nolint:gochecknoglobals
+	EventKindJobCancelled: {},
+	EventKindJobCompleted: {},
+	EventKindJobDiscarded: {},
+	EventKindJobFailed:    {},
+	EventKindJobSnoozed:   {},
+}
+
+// clusterJobEventPayload is the payload of a notification published on the
+// job event topic. Only IDs and states are sent so that payloads stay small,
+// and receivers fetch full job rows themselves.
+type clusterJobEventPayload struct {
+	Jobs []clusterJobEventPayloadJob `json:"jobs"`
+}
+
+type clusterJobEventPayloadJob struct {
+	ID    int64              `json:"id"`
+	State rivertype.JobState `json:"state"`
+}
+
+// Builds notification payloads for the given jobs, splitting them across as
+// many payloads as necessary to keep each one under the maximum payload size.
+func clusterJobEventPayloads(jobs []*rivertype.JobRow) ([]string, error) {
+	var (
+		payloads []string
+		chunk    []clusterJobEventPayloadJob
+		// Size of the empty envelope `{"jobs":[]}`.
+		emptySize = len(`{"jobs":[]}`)
+		chunkSize = emptySize
+	)
+
+	flush := func() error {
+		if len(chunk) < 1 {
+			return nil
+		}
+
+		payload, err := json.Marshal(clusterJobEventPayload{Jobs: chunk})
+		if err != nil {
+			return err
+		}
+
+		payloads = append(payloads, string(payload))
+		chunk = nil
+		chunkSize = emptySize
+		return nil
+	}
+
+	for _, job := range jobs {
+		entry := clusterJobEventPayloadJob{ID: job.ID, State: job.State}
+
+		entryBytes, err := json.Marshal(entry)
+		if err != nil {
+			return nil, err
+		}
+
+		// Plus one for a separating comma.
+		if chunkSize+len(entryBytes)+1 > clusterEventPayloadMaxBytes {
+			if err := flush(); err != nil {
+				return nil, err
+			}
+		}
+
+		chunk = append(chunk, entry)
+		chunkSize += len(entryBytes) + 1
+	}
+
+	if err := flush(); err != nil {
+		return nil, err
+	}
+
+	return payloads, nil
+}
+
+// Publishes cluster job events for jobs that were just set to a new state by
+// the completer. Invoked by the subscription manager for clients configured
+// with ClusterEvents that can use listen/notify.
+func (c *Client[TTx]) publishClusterJobEvents(ctx context.Context, jobs []*rivertype.JobRow) {
+	payloads, err := clusterJobEventPayloads(jobs)
+	if err != nil {
+		c.baseService.Logger.ErrorContext(ctx, c.baseService.Name+": Error building cluster job event payloads", slog.String("err", err.Error()))
+		return
+	}
+
+	if len(payloads) < 1 {
+		return
+	}
+
+	if err := c.driver.GetExecutor().NotifyMany(ctx, &riverdriver.NotifyManyParams{
+		Payload: payloads,
+		Schema:  c.config.schema,
+		Topic:   string(notifier.NotificationTopicJobEvent),
+	}); err != nil {
+		c.baseService.Logger.ErrorContext(ctx, c.baseService.Name+": Failed to send cluster job event notification", slog.String("err", err.Error()))
+	}
+}
+
+// clusterEventStream receives job events for jobs finalized anywhere in the
+// cluster and distributes them to subscriptions created with
+// Client.SubscribeCluster. It's started when the first subscription is created
+// and stopped after the last one is cancelled.
+//
+// When a listener is available, it listens for notifications published by
+// clients working jobs. Otherwise, it polls for jobs finalized since it
+// started.
+//
+// Besides subscriptions, it also dispatches finalized jobs to waiters
+// registered with WaitJob by job ID, so that each of many concurrent calls to
+// Client.JobWait only receives the job it's waiting on.
+type clusterEventStream struct {
+	baseservice.BaseService
+	startstop.BaseStartStop
+
+	archetype         *baseservice.Archetype
+	exec              riverdriver.Executor
+	fetchPollInterval time.Duration
+	listener          riverdriver.Listener // nil in poll mode
+	schema            string
+
+	// subscriptions is used only as a subscription registry and is never
+	// started itself.
+	subscriptions *subscriptionManager
+
+	mu       sync.Mutex // protects numUsers and start/stop
+	numUsers int        // subscriptions plus waiters
+
+	dispatchMu       sync.RWMutex // protects numSubscriptions and waiters
+	numSubscriptions int
+	waiters          map[int64]map[chan *rivertype.JobRow]struct{}
+}
+
+func newClusterEventStream(archetype *baseservice.Archetype, exec riverdriver.Executor, listener riverdriver.Listener, schema string, fetchPollInterval time.Duration) *clusterEventStream {
+	return baseservice.Init(archetype, &clusterEventStream{
+		archetype:         archetype,
+		exec:              exec,
+		fetchPollInterval: fetchPollInterval,
+		listener:          listener,
+		schema:            schema,
+		subscriptions:     newSubscriptionManager(archetype, nil),
+		waiters:           make(map[int64]map[chan *rivertype.JobRow]struct{}),
+	})
+}
+
+// Subscribe creates a new subscription, starting the stream if it's not
+// running already. The stream is stopped when the last subscription or waiter
+// is cancelled.
+func (s *clusterEventStream) Subscribe(ctx context.Context, config *SubscribeConfig) (<-chan *Event, func(), error) {
+	for _, kind := range config.Kinds {
+		if _, ok := clusterEventKinds[kind]; !ok {
+			panic("event kind not supported by cluster subscriptions: " + string(kind))
+		}
+	}
+
+	subChan, subCancel := s.subscriptions.SubscribeConfig(config)
+
+	s.dispatchMu.Lock()
+	s.numSubscriptions++
+	s.dispatchMu.Unlock()
+
+	removeSubscription := func() {
+		subCancel()
+
+		s.dispatchMu.Lock()
+		s.numSubscriptions--
+		s.dispatchMu.Unlock()
+	}
+
+	release, err := s.acquire(ctx)
+	if err != nil {
+		removeSubscription()
+		return nil, nil, err
+	}
+
+	var cancelOnce sync.Once
+	cancel := func() {
+		cancelOnce.Do(func() {
+			removeSubscription()
+			release()
+		})
+	}
+
+	return subChan, cancel, nil
+}
+
+// WaitJob registers a waiter for the job with the given ID, starting the
+// stream if it's not running already. The returned channel receives the job
+// when an event is received for it being finalized. Unlike subscriptions,
+// waiters only receive events for their own job, so the work done per event
+// doesn't grow with the number of waiters. The returned function must be
+// invoked to unregister the waiter.
+func (s *clusterEventStream) WaitJob(ctx context.Context, id int64) (<-chan *rivertype.JobRow, func(), error) {
+	// Buffered so that dispatch never blocks on a waiter. Only the first
+	// finalized job is of interest, so others may be dropped.
+	waitChan := make(chan *rivertype.JobRow, 1)
+
+	s.dispatchMu.Lock()
+	if s.waiters[id] == nil {
+		s.waiters[id] = make(map[chan *rivertype.JobRow]struct{})
+	}
+	s.waiters[id][waitChan] = struct{}{}
+	s.dispatchMu.Unlock()
+
+	removeWaiter := func() {
+		s.dispatchMu.Lock()
+		defer s.dispatchMu.Unlock()
+
+		delete(s.waiters[id], waitChan)
+		if len(s.waiters[id]) < 1 {
+			delete(s.waiters, id)
+		}
+	}
+
+	release, err := s.acquire(ctx)
+	if err != nil {
+		removeWaiter()
+		return nil, nil, err
+	}
+
+	var cancelOnce sync.Once
+	cancel := func() {
+		cancelOnce.Do(func() {
+			removeWaiter()
+			release()
+		})
+	}
+
+	return waitChan, cancel, nil
+}
+
+// Registers a user of the stream, starting it if this is the first one. The
+// returned function unregisters the user, stopping the stream after the last
+// one.
+func (s *clusterEventStream) acquire(ctx context.Context) (func(), error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.numUsers == 0 {
+		// Run independently of the context of the first user. The stream is
+		// stopped when the last user is released instead.
+		if err := s.Start(context.WithoutCancel(ctx)); err != nil {
+			return nil, err
+		}
+	}
+	s.numUsers++
+
+	return func() {
+		s.mu.Lock()
+		defer s.mu.Unlock()
+
+		s.numUsers--
+		if s.numUsers == 0 {
+			s.Stop()
+		}
+	}, nil
+}
+
+func (s *clusterEventStream) Start(ctx context.Context) error {
+	ctx, shouldStart, started, stopped := s.StartInit(ctx)
+	if !shouldStart {
+		return nil
+	}
+
+	if s.listener == nil {
+		go func() {
+			started()
+			defer stopped()
+
+			s.pollLoop(ctx)
+		}()
+
+		return nil
+	}
+
+	notif := notifier.New(s.archetype, s.listener)
+	if err := notif.Start(ctx); err != nil {
+		stopped()
+		return err
+	}
+
+	sub, err := notif.Listen(ctx, notifier.NotificationTopicJobEvent, func(_ notifier.NotificationTopic, payload string) {
+		s.handleNotification(ctx, payload)
+	})
+	if err != nil {
+		notif.Stop()
+		stopped()
+		return err
+	}
+
+	go func() {
+		started()
+		defer stopped()
+
+		<-ctx.Done()
+
+		sub.Unlisten(context.WithoutCancel(ctx))
+		notif.Stop()
+	}()
+
+	return nil
+}
+
+func (s *clusterEventStream) handleNotification(ctx context.Context, payload string) {
+	var decoded clusterJobEventPayload
+	if err := json.Unmarshal([]byte(payload), &decoded); err != nil {
+		s.Logger.ErrorContext(ctx, s.Name+": Error unmarshaling cluster job event payload", slog.String("err", err.Error()))
+		return
+	}
+
+	publishedStates := make(map[int64]rivertype.JobState, len(decoded.Jobs))
+	for _, job := range decoded.Jobs {
+		switch job.State {
+		case rivertype.JobStateAvailable,
+			rivertype.JobStateCancelled,
+			rivertype.JobStateCompleted,
+			rivertype.JobStateDiscarded,
+			rivertype.JobStateRetryable,
+			rivertype.JobStateScheduled:
+			publishedStates[job.ID] = job.State
+		case rivertype.JobStatePending, rivertype.JobStateRunning:
+			// Never published; ignore in case of a malformed payload.
+		}
+	}
+
+	// Without any subscriptions, only jobs being waited on need to be fetched,
+	// which is usually none of them.
+	s.dispatchMu.RLock()
+	if s.numSubscriptions < 1 {
+		for id := range publishedStates {
+			if _, ok := s.waiters[id]; !ok {
+				delete(publishedStates, id)
+			}
+		}
+	}
+	s.dispatchMu.RUnlock()
+
+	if len(publishedStates) < 1 {
+		return
+	}
+
+	jobs, err := s.exec.JobGetByIDMany(ctx, &riverdriver.JobGetByIDManyParams{
+		ID:     maputil.Keys(publishedStates),
+		Schema: s.schema,
+	})
+	if err != nil {
+		if !errors.Is(err, context.Canceled) {
+			s.Logger.ErrorContext(ctx, s.Name+": Error fetching jobs for cluster job events", slog.String("err", err.Error()))
+		}
+		return
+	}
+
+	for _, job := range jobs {
+		state, ok := publishedStates[job.ID]
+		if !ok {
+			continue
+		}
+
+		// The job may have moved on to another state since the notification
+		// was published (e.g. a retryable job was worked again), so emit
+		// events based on the state that was published rather than the
+		// job's current state.
+		s.distributeJobEvents(job, state)
+	}
+}
+
+func (s *clusterEventStream) distributeJobEvents(job *rivertype.JobRow, state rivertype.JobState) {
+	for _, kind := range jobEventKindsForState(state) {
+		s.subscriptions.distributeEvent(&Event{Kind: kind, Job: job})
+	}
+
+	if !jobStateIsFinalized(state) {
+		return
+	}
+
+	s.dispatchMu.RLock()
+	defer s.dispatchMu.RUnlock()
+
+	for waitChan := range s.waiters[job.ID] {
+		select {
+		case waitChan <- job:
+		default:
+		}
+	}
+}
+
+// Polls for jobs finalized since the stream started. Only finalized jobs are
+// visible to polling, so subscriptions receive cancelled, completed, and
+// discarded (and failed) events, but not events for retryable or snoozed jobs.
+//
+// Polling uses job finalization times as a cursor, so events may be missed for
+// jobs finalized by clients whose clocks are skewed behind this one's.
+func (s *clusterEventStream) pollLoop(ctx context.Context) {
+	var (
+		cursorFinalizedAt = s.Time.NowUTC()
+		cursorID          int64
+	)
+
+	ticker := time.NewTicker(s.fetchPollInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+
+		for {
+			jobs, err := s.exec.JobGetFinalizedAfter(ctx, &riverdriver.JobGetFinalizedAfterParams{
+				FinalizedAt: cursorFinalizedAt,
+				ID:          cursorID,
+				Max:         clusterEventPollLimit,
+				Schema:      s.schema,
+			})
+			if err != nil {
+				if !errors.Is(err, context.Canceled) {
+					s.Logger.ErrorContext(ctx, s.Name+": Error polling for finalized jobs", slog.String("err", err.Error()))
+				}
+				break
+			}
+
+			for _, job := range jobs {
+				s.distributeJobEvents(job, job.State)
+
+				cursorFinalizedAt = *job.FinalizedAt
+				cursorID = job.ID
+			}
+
+			if len(jobs) < clusterEventPollLimit {
+				break
+			}
+		}
+	}
+}
@@ -0,0 +1,271 @@
+package river
+
+import (
+	"context"
+	"encoding/json"
+	"testing"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/stretchr/testify/require"
+
+	"github.com/riverqueue/river/internal/riverinternaltest"
+	"github.com/riverqueue/river/riverdriver/riverpgxv5"
+	"github.com/riverqueue/river/rivershared/riversharedtest"
+	"github.com/riverqueue/river/rivertype"
+)
+
+func Test_clusterJobEventPayloads(t *testing.T) {
+	t.Parallel()
+
+	t.Run("SinglePayload", func(t *testing.T) {
+		t.Parallel()
+
+		payloads, err := clusterJobEventPayloads([]*rivertype.JobRow{
+			{ID: 1, State: rivertype.JobStateCompleted},
+			{ID: 2, State: rivertype.JobStateDiscarded},
+		})
+		require.NoError(t, err)
+		require.Equal(t, []string{`{"jobs":[{"id":1,"state":"completed"},{"id":2,"state":"discarded"}]}`}, payloads)
+	})
+
+	t.Run("NoJobs", func(t *testing.T) {
+		t.Parallel()
+
+		payloads, err := clusterJobEventPayloads(nil)
+		require.NoError(t, err)
+		require.Empty(t, payloads)
+	})
+
+	t.Run("SplitsAtMaxBytes", func(t *testing.T) {
+		t.Parallel()
+
+		jobs := make([]*rivertype.JobRow, 1_000)
+		for i := range jobs {
+			jobs[i] = &rivertype.JobRow{ID: int64(1_000_000_000 + i), State: rivertype.JobStateCompleted}
+		}
+
+		payloads, err := clusterJobEventPayloads(jobs)
+		require.NoError(t, err)
+		require.Greater(t, len(payloads), 1)
+
+		var ids []int64
+		for _, payload := range payloads {
+			require.LessOrEqual(t, len(payload), clusterEventPayloadMaxBytes)
+
+			var decoded clusterJobEventPayload
+			require.NoError(t, json.Unmarshal([]byte(payload), &decoded))
+			for _, job := range decoded.Jobs {
+				ids = append(ids, job.ID)
+			}
+		}
+
+		require.Len(t, ids, len(jobs))
+		for i, id := range ids {
+			require.Equal(t, jobs[i].ID, id)
+		}
+	})
+}
+
+func Test_Client_SubscribeCluster(t *testing.T) {
+	t.Parallel()
+
+	ctx := context.Background()
+
+	// Returns a client working jobs and an insert-only client, both with
+	// cluster events enabled.
+	setup := func(t *testing.T, pollOnly bool) (*Client[pgx.Tx], *Client[pgx.Tx]) {
+		t.Helper()
+
+		dbPool := riverinternaltest.TestDB(ctx, t)
+
+		workConfig := newTestConfig(t, nil)
+		workConfig.ClusterEvents = true
+		workConfig.PollOnly = pollOnly
+		workClient := newTestClient(t, dbPool, workConfig)
+
+		insertClient := newTestClient(t, dbPool, &Config{
+			ClusterEvents:     true,
+			FetchPollInterval: 50 * time.Millisecond,
+			Logger:            riversharedtest.Logger(t),
+			PollOnly:          pollOnly,
+			TestOnly:          true,
+		})
+
+		return workClient, insertClient
+	}
+
+	t.Run("ReceivesEventsFromOtherClient", func(t *testing.T) {
+		t.Parallel()
+
+		workClient, insertClient := setup(t, false)
+
+		subChan, cancel, err := insertClient.SubscribeCluster(ctx, EventKindJobCompleted)
+		require.NoError(t, err)
+		t.Cleanup(cancel)
+
+		startClient(ctx, t, workClient)
+
+		insertRes, err := insertClient.Insert(ctx, noOpArgs{}, nil)
+		require.NoError(t, err)
+
+		event := riversharedtest.WaitOrTimeout(t, subChan)
+		require.Equal(t, EventKindJobCompleted, event.Kind)
+		require.Equal(t, insertRes.Job.ID, event.Job.ID)
+		require.Equal(t, rivertype.JobStateCompleted, event.Job.State)
+		require.Nil(t, event.JobStats)
+	})
+
+	t.Run("PollOnly", func(t *testing.T) {
+		t.Parallel()
+
+		workClient, insertClient := setup(t, true)
+
+		subChan, cancel, err := insertClient.SubscribeCluster(ctx, EventKindJobCompleted)
+		require.NoError(t, err)
+		t.Cleanup(cancel)
+
+		startClient(ctx, t, workClient)
+
+		insertRes, err := insertClient.Insert(ctx, noOpArgs{}, nil)
+		require.NoError(t, err)
+
+		event := riversharedtest.WaitOrTimeout(t, subChan)
+		require.Equal(t, EventKindJobCompleted, event.Kind)
+		require.Equal(t, insertRes.Job.ID, event.Job.ID)
+	})
+
+	t.Run("CancelAndResubscribe", func(t *testing.T) {
+		t.Parallel()
+
+		workClient, insertClient := setup(t, false)
+
+		_, cancel, err := insertClient.SubscribeCluster(ctx, EventKindJobCompleted)
+		require.NoError(t, err)
+		cancel()
+		cancel() // safe to call twice
+
+		subChan, cancel, err := insertClient.SubscribeCluster(ctx, EventKindJobCompleted)
+		require.NoError(t, err)
+		t.Cleanup(cancel)
+
+		startClient(ctx, t, workClient)
+
+		insertRes, err := insertClient.Insert(ctx, noOpArgs{}, nil)
+		require.NoError(t, err)
+
+		event := riversharedtest.WaitOrTimeout(t, subChan)
+		require.Equal(t, insertRes.Job.ID, event.Job.ID)
+	})
+
+	t.Run("ClusterEventsNotEnabled", func(t *testing.T) {
+		t.Parallel()
+
+		client, err := NewClient(riverpgxv5.New(nil), &Config{
+			Logger: riversharedtest.Logger(t),
+		})
+		require.NoError(t, err)
+
+		_, _, err = client.SubscribeCluster(ctx, EventKindJobCompleted)
+		require.EqualError(t, err, "cluster subscriptions require Config.ClusterEvents to be enabled")
+	})
+
+	t.Run("NoDatabasePool", func(t *testing.T) {
+		t.Parallel()
+
+		client, err := NewClient(riverpgxv5.New(nil), &Config{
+			ClusterEvents: true,
+			Logger:        riversharedtest.Logger(t),
+		})
+		require.NoError(t, err)
+
+		_, _, err = client.SubscribeCluster(ctx, EventKindJobCompleted)
+		require.EqualError(t, err, "cluster subscriptions require a driver with a non-nil database pool")
+	})
+
+	t.Run("UnsupportedKindPanics", func(t *testing.T) {
+		t.Parallel()
+
+		client, err := NewClient(riverpgxv5.New(nil), &Config{
+			Logger: riversharedtest.Logger(t),
+		})
+		require.NoError(t, err)
+		client.config.ClusterEvents = true
+		client.clusterEventStream = newClusterEventStream(riversharedtest.BaseServiceArchetype(t), nil, nil, "", time.Second)
+
+		require.PanicsWithValue(t, "event kind not supported by cluster subscriptions: job_started", func() {
+			_, _, _ = client.SubscribeCluster(ctx, EventKindJobStarted)
+		})
+	})
+}
+
+func Test_clusterEventStream_WaitJob(t *testing.T) {
+	t.Parallel()
+
+	ctx := context.Background()
+
+	setup := func(t *testing.T) *clusterEventStream {
+		t.Helper()
+
+		// Poll interval is long enough that the stream never polls, which
+		// would require a database.
+		return newClusterEventStream(riversharedtest.BaseServiceArchetype(t), nil, nil, "", time.Hour)
+	}
+
+	t.Run("DispatchesByJobID", func(t *testing.T) {
+		t.Parallel()
+
+		stream := setup(t)
+
+		waitChan1a, cancel1a, err := stream.WaitJob(ctx, 1)
+		require.NoError(t, err)
+		t.Cleanup(cancel1a)
+
+		waitChan1b, cancel1b, err := stream.WaitJob(ctx, 1)
+		require.NoError(t, err)
+		t.Cleanup(cancel1b)
+
+		waitChan2, cancel2, err := stream.WaitJob(ctx, 2)
+		require.NoError(t, err)
+		t.Cleanup(cancel2)
+
+		job1 := &rivertype.JobRow{ID: 1, State: rivertype.JobStateCompleted}
+		stream.distributeJobEvents(job1, job1.State)
+
+		require.Equal(t, job1, riversharedtest.WaitOrTimeout(t, waitChan1a))
+		require.Equal(t, job1, riversharedtest.WaitOrTimeout(t, waitChan1b))
+		require.Empty(t, waitChan2)
+
+		// Waiters only receive jobs that were finalized.
+		stream.distributeJobEvents(&rivertype.JobRow{ID: 2, State: rivertype.JobStateRetryable}, rivertype.JobStateRetryable)
+		require.Empty(t, waitChan2)
+
+		job2 := &rivertype.JobRow{ID: 2, State: rivertype.JobStateDiscarded}
+		stream.distributeJobEvents(job2, job2.State)
+		require.Equal(t, job2, riversharedtest.WaitOrTimeout(t, waitChan2))
+	})
+
+	t.Run("CancelRemovesWaiterAndStopsStream", func(t *testing.T) {
+		t.Parallel()
+
+		stream := setup(t)
+
+		_, cancel1, err := stream.WaitJob(ctx, 1)
+		require.NoError(t, err)
+
+		_, cancel2, err := stream.WaitJob(ctx, 1)
+		require.NoError(t, err)
+
+		cancel1()
+		cancel1() // idempotent
+		require.Len(t, stream.waiters[1], 1)
+		require.Equal(t, 1, stream.numUsers)
+
+		stopped := stream.Stopped()
+
+		cancel2()
+		require.Empty(t, stream.waiters)
+		require.Zero(t, stream.numUsers)
+		riversharedtest.WaitOrTimeout(t, stopped)
+	})
+}
@@ -32,9 +32,33 @@ func NewBenchmarker[TTx any](driver riverdriver.Driver[TTx], logger *slog.Logger
 	}
 }
 
+// RunOpts are options for a benchmark run.
+type RunOpts struct {
+	// Duration is a maximum run duration after which to stop. Zero runs until
+	// interrupted, or until NumTotalJobs are worked if it's set.
+	Duration time.Duration
+
+	// FairFetch enables fair fetching for the benchmark's queue. It's most
+	// interesting in combination with NumTenants.
+	FairFetch bool
+
+	// NumTenants distributes inserted jobs between this many partition keys
+	// in round robin order. Zero inserts jobs without a partition key.
+	NumTenants int
+
+	// NumTotalJobs is a number of jobs to insert before starting the client,
+	// which are then worked until finished. Zero inserts jobs continuously.
+	NumTotalJobs int
+}
+
 // Run starts the benchmarking loop. Stops upon receiving SIGINT/SIGTERM, or
 // when reaching maximum configured run duration.
-func (b *Benchmarker[TTx]) Run(ctx context.Context, duration time.Duration, numTotalJobs int) error {
+func (b *Benchmarker[TTx]) Run(ctx context.Context, opts *RunOpts) error {
+	var (
+		duration     = opts.Duration
+		numTotalJobs = opts.NumTotalJobs
+	)
+
 	var (
 		lastJobWorkedAt time.Time
 		numJobsInserted atomic.Int64
@@ -104,7 +128,7 @@ func (b *Benchmarker[TTx]) Run(ctx context.Context, duration time.Duration, numT
 			// maximum of 10k performed quite badly (scheduler contention?).
 			// There may be a more optimal number than 1,000, but it seems close
 			// enough to target for now.
-			river.QueueDefault: {MaxWorkers: 2_000},
+			river.QueueDefault: {FairFetch: opts.FairFetch, MaxWorkers: 2_000},
 		},
 		Workers: workers,
 	})
@@ -205,17 +229,24 @@ func (b *Benchmarker[TTx]) Run(ctx context.Context, duration time.Duration, numT
 		}
 	}()
 
+	// Partition key metadata for each tenant, built once up front so that it
+	// doesn't need to be allocated for every inserted job.
+	tenantMetadata := make([][]byte, opts.NumTenants)
+	for i := range tenantMetadata {
+		tenantMetadata[i] = []byte(fmt.Sprintf(`{"river:partition":{"tenant":%d}}`, i))
+	}
+
 	minJobsReady := make(chan struct{})
 
 	if numTotalJobs != 0 {
-		b.insertJobs(ctx, client, minJobsReady, &numJobsInserted, &numJobsLeft, numTotalJobs, shutdown)
+		b.insertJobs(ctx, client, minJobsReady, &numJobsInserted, &numJobsLeft, numTotalJobs, tenantMetadata, shutdown)
 	} else {
 		insertJobsFinished := make(chan struct{})
 		defer func() { <-insertJobsFinished }()
 
 		go func() {
 			defer close(insertJobsFinished)
-			b.insertJobsContinuously(ctx, client, minJobsReady, &numJobsInserted, &numJobsLeft, shutdown)
+			b.insertJobsContinuously(ctx, client, minJobsReady, &numJobsInserted, &numJobsLeft, tenantMetadata, shutdown)
 		}()
 	}
 
@@ -330,6 +361,7 @@ func (b *Benchmarker[TTx]) insertJobs(
 	numJobsInserted *atomic.Int64,
 	numJobsLeft *atomic.Int64,
 	numTotalJobs int,
+	tenantMetadata [][]byte,
 	shutdown chan struct{},
 ) {
 	defer close(minJobsReady)
@@ -353,6 +385,7 @@ func (b *Benchmarker[TTx]) insertJobs(
 
 		for i := range insertParamsBatch {
 			insertParamsBatch[i].Args = jobArgsBatch[i]
+			insertParamsBatch[i].InsertOpts = tenantInsertOpts(tenantMetadata, int(numJobsInserted.Load())+i)
 		}
 
 		numLeft := numTotalJobs - numInsertedThisRound
@@ -396,6 +429,7 @@ func (b *Benchmarker[TTx]) insertJobsContinuously(
 	minJobsReady chan struct{},
 	numJobsInserted *atomic.Int64,
 	numJobsLeft *atomic.Int64,
+	tenantMetadata [][]byte,
 	shutdown chan struct{},
 ) {
 	var (
@@ -428,6 +462,7 @@ func (b *Benchmarker[TTx]) insertJobsContinuously(
 
 			for i := range insertParamsBatch {
 				insertParamsBatch[i].Args = jobArgsBatch[i]
+				insertParamsBatch[i].InsertOpts = tenantInsertOpts(tenantMetadata, int(numJobsInserted.Load())+i)
 			}
 
 			if _, err := client.InsertMany(ctx, insertParamsBatch); err != nil {
@@ -480,6 +515,15 @@ func (b *Benchmarker[TTx]) resetJobsTable(ctx context.Context) error {
 	return nil
 }
 
+// Returns insert options putting the given job in one of the given tenants'
+// partitions in round robin order, or nil if there are no tenants.
+func tenantInsertOpts(tenantMetadata [][]byte, jobIndex int) *river.InsertOpts {
+	if len(tenantMetadata) < 1 {
+		return nil
+	}
+	return &river.InsertOpts{Metadata: tenantMetadata[jobIndex%len(tenantMetadata)]}
+}
+
 type BenchmarkArgs struct {
 	Num int `json:"num"`
 }
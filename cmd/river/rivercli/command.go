@@ -10,11 +10,15 @@ import (
 	"strings"
 	"time"
 
+	"github.com/jackc/pgx/v5"
 	"github.com/jackc/pgx/v5/pgxpool"
 
+	"github.com/riverqueue/river"
 	"github.com/riverqueue/river/cmd/river/riverbench"
+	"github.com/riverqueue/river/riverdriver"
 	"github.com/riverqueue/river/rivermigrate"
 	"github.com/riverqueue/river/rivershared/util/ptrutil"
+	"github.com/riverqueue/river/rivertype"
 )
 
 const (
@@ -26,7 +30,40 @@ const (
 // existence is to wrap a benchmarker to strip it of its generic parameter,
 // letting us pass it around without having to know the transaction type.
 type BenchmarkerInterface interface {
-	Run(ctx context.Context, duration time.Duration, numTotalJobs int) error
+	Run(ctx context.Context, opts *riverbench.RunOpts) error
+}
+
+// ClientInterface is an interface to the subset of a Client's functions used
+// by CLI commands. Its reason for existence is to wrap a client to strip it of
+// its generic parameter, letting us pass it around without having to know the
+// transaction type.
+type ClientInterface interface {
+	DeadLetterGet(ctx context.Context, id int64) (*rivertype.DeadLetter, error)
+	DeadLetterList(ctx context.Context, params *river.DeadLetterListParams) (*river.DeadLetterListResult, error)
+	DeadLetterPurge(ctx context.Context, params *river.DeadLetterPurgeParams) (int, error)
+	DeadLetterRequeue(ctx context.Context, id int64) (*rivertype.JobInsertResult, error)
+	JobCancel(ctx context.Context, jobID int64) (*rivertype.JobRow, error)
+	JobCancelMany(ctx context.Context, params *river.JobListParams, opts *river.JobManyOpts) (*river.JobCancelManyResult, error)
+	JobCount(ctx context.Context, params *river.JobListParams) (int, error)
+	JobDelete(ctx context.Context, id int64) (*rivertype.JobRow, error)
+	JobDeleteMany(ctx context.Context, params *river.JobListParams, opts *river.JobManyOpts) (*river.JobDeleteManyResult, error)
+	JobGet(ctx context.Context, id int64) (*rivertype.JobRow, error)
+	JobList(ctx context.Context, params *river.JobListParams) (*river.JobListResult, error)
+	JobRetry(ctx context.Context, id int64) (*rivertype.JobRow, error)
+	JobRetryMany(ctx context.Context, params *river.JobListParams, opts *river.JobManyOpts) (*river.JobRetryManyResult, error)
+	QueueGet(ctx context.Context, name string) (*rivertype.Queue, error)
+	QueueList(ctx context.Context, params *river.QueueListParams) (*river.QueueListResult, error)
+	QueuePause(ctx context.Context, name string, opts *river.QueuePauseOpts) error
+	QueueResume(ctx context.Context, name string, opts *river.QueuePauseOpts) error
+	QueueStatsMany(ctx context.Context, names []string) ([]*river.QueueStats, error)
+	QueueUpdate(ctx context.Context, name string, params *river.QueueUpdateParams) (*rivertype.Queue, error)
+}
+
+// ExecutorInterface is an interface to the subset of a driver executor's
+// functions used by CLI commands, for information that isn't available through
+// a client like the currently elected leader.
+type ExecutorInterface interface {
+	LeaderGetElectedLeader(ctx context.Context, params *riverdriver.LeaderGetElectedLeaderParams) (*riverdriver.Leader, error)
 }
 
 // MigratorInterface is an interface to a Migrator. Its reason for existence is
@@ -53,11 +90,14 @@ type Command[TOpts CommandOpts] interface {
 // generally embedded on the struct of a command.
 type CommandBase struct {
 	DriverProcurer DriverProcurer
+	In             io.Reader
 	Logger         *slog.Logger
 	Out            io.Writer
 	Schema         string
 
 	GetBenchmarker func() BenchmarkerInterface
+	GetClient      func() (ClientInterface, error)
+	GetExecutor    func() (ExecutorInterface, error)
 	GetMigrator    func(config *rivermigrate.Config) (MigratorInterface, error)
 }
 
@@ -74,6 +114,7 @@ type CommandOpts interface {
 type RunCommandBundle struct {
 	DatabaseURL    *string
 	DriverProcurer DriverProcurer
+	InStd          io.Reader
 	Logger         *slog.Logger
 	OutStd         io.Writer
 	Schema         string
@@ -88,6 +129,7 @@ func RunCommand[TOpts CommandOpts](ctx context.Context, bundle *RunCommandBundle
 
 		commandBase := &CommandBase{
 			DriverProcurer: bundle.DriverProcurer,
+			In:             bundle.InStd,
 			Logger:         bundle.Logger,
 			Out:            bundle.OutStd,
 			Schema:         bundle.Schema,
@@ -115,11 +157,13 @@ func RunCommand[TOpts CommandOpts](ctx context.Context, bundle *RunCommandBundle
 
 		if databaseURL == nil {
 			commandBase.GetBenchmarker = func() BenchmarkerInterface { panic("neither PG* env nor databaseURL was not set") }
+			commandBase.GetClient = func() (ClientInterface, error) { panic("neither PG* env nor databaseURL was not set") }
+			commandBase.GetExecutor = func() (ExecutorInterface, error) { panic("neither PG* env nor databaseURL was not set") }
 			commandBase.GetMigrator = func(config *rivermigrate.Config) (MigratorInterface, error) {
 				panic("neither PG* env nor databaseURL was not set")
 			}
 		} else {
-			dbPool, err := openPgxV5DBPool(ctx, *databaseURL)
+			dbPool, err := openPgxV5DBPool(ctx, *databaseURL, bundle.Schema)
 			if err != nil {
 				return false, err
 			}
@@ -130,6 +174,11 @@ func RunCommand[TOpts CommandOpts](ctx context.Context, bundle *RunCommandBundle
 			commandBase.GetBenchmarker = func() BenchmarkerInterface {
 				return riverbench.NewBenchmarker(driver, commandBase.Logger, commandBase.Schema)
 			}
+			commandBase.GetClient = func() (ClientInterface, error) {
+				// Insert-only client, so no queues or workers are configured.
+				return river.NewClient(driver, &river.Config{Logger: commandBase.Logger})
+			}
+			commandBase.GetExecutor = func() (ExecutorInterface, error) { return driver.GetExecutor(), nil }
 			commandBase.GetMigrator = func(config *rivermigrate.Config) (MigratorInterface, error) { return rivermigrate.New(driver, config) }
 		}
 
@@ -148,7 +197,7 @@ func RunCommand[TOpts CommandOpts](ctx context.Context, bundle *RunCommandBundle
 	return nil
 }
 
-func openPgxV5DBPool(ctx context.Context, databaseURL string) (*pgxpool.Pool, error) {
+func openPgxV5DBPool(ctx context.Context, databaseURL, schema string) (*pgxpool.Pool, error) {
 	const (
 		defaultIdleInTransactionSessionTimeout = 11 * time.Second // should be greater than statement timeout because statements count towards idle-in-transaction
 		defaultStatementTimeout                = 10 * time.Second
@@ -173,6 +222,13 @@ func openPgxV5DBPool(ctx context.Context, databaseURL string) (*pgxpool.Pool, er
 	setParamIfUnset(pgxConfig.ConnConfig.RuntimeParams, "idle_in_transaction_session_timeout", strconv.Itoa(int(defaultIdleInTransactionSessionTimeout.Milliseconds())))
 	setParamIfUnset(pgxConfig.ConnConfig.RuntimeParams, "statement_timeout", strconv.Itoa(int(defaultStatementTimeout.Milliseconds())))
 
+	// Clients don't take a schema, so point the search path at the schema
+	// instead so that client operations like those of job commands find River
+	// tables in it. Migrators and benchmarkers use an explicit schema anyway.
+	if schema != "" {
+		pgxConfig.ConnConfig.RuntimeParams["search_path"] = pgx.Identifier{schema}.Sanitize()
+	}
+
 	dbPool, err := pgxpool.NewWithConfig(ctx, pgxConfig)
 	if err != nil {
 		return nil, fmt.Errorf("error connecting to database: %w", err)
@@ -6,7 +6,11 @@
 package rivercli
 
 import (
+	"bufio"
+	"bytes"
+	"cmp"
 	"context"
+	"encoding/json"
 	"errors"
 	"fmt"
 	"io"
@@ -15,16 +19,22 @@ import (
 	"runtime/debug"
 	"slices"
 	"strings"
+	"text/tabwriter"
 	"time"
 
 	"github.com/jackc/pgx/v5"
 	"github.com/jackc/pgx/v5/pgxpool"
 	"github.com/lmittmann/tint"
 	"github.com/spf13/cobra"
+	"golang.org/x/term"
 
+	"github.com/riverqueue/river"
+	"github.com/riverqueue/river/cmd/river/riverbench"
 	"github.com/riverqueue/river/riverdriver"
 	"github.com/riverqueue/river/rivermigrate"
+	"github.com/riverqueue/river/rivershared/util/sliceutil"
 	"github.com/riverqueue/river/rivershared/util/valutil"
+	"github.com/riverqueue/river/rivertype"
 )
 
 type Config struct {
@@ -47,6 +57,7 @@ type DriverProcurer interface {
 // CLI provides a common base of commands for the River CLI.
 type CLI struct {
 	driverProcurer DriverProcurer
+	in             io.Reader
 	name           string
 	out            io.Writer
 }
@@ -54,6 +65,7 @@ type CLI struct {
 func NewCLI(config *Config) *CLI {
 	return &CLI{
 		driverProcurer: config.DriverProcurer,
+		in:             os.Stdin,
 		name:           config.Name,
 		out:            os.Stdout,
 	}
@@ -85,6 +97,7 @@ func (c *CLI) BaseCommandSet() *cobra.Command {
 		return &RunCommandBundle{
 			DatabaseURL:    databaseURL,
 			DriverProcurer: c.driverProcurer,
+			InStd:          c.in,
 			Logger:         makeLogger(),
 			OutStd:         c.out,
 			Schema:         schema,
@@ -133,6 +146,9 @@ PG* vars if it's been specified.
 	addLineFlag := func(cmd *cobra.Command, line *string) {
 		cmd.Flags().StringVar(line, "line", "", "migration line to operate on (default: main)")
 	}
+	addOutputFlag := func(cmd *cobra.Command, output *string) {
+		cmd.Flags().StringVarP(output, "output", "o", outputTable, "output format, either table or json")
+	}
 	addSchemaFlag := func(cmd *cobra.Command, schema *string) {
 		cmd.Flags().StringVar(schema, "schema", "", "name of non-default database schema where River tables are located")
 	}
@@ -154,6 +170,12 @@ duration with --duration, which takes a Go-style duration string like 1m.
 Lastly, it can take --num-total-jobs, which inserts the given number of jobs
 before starting the client, and works until all jobs are finished.
 
+Fair fetching can be benchmarked with --fair-fetch, usually in combination with
+--num-tenants, which spreads inserted jobs between the given number of
+partition keys. Inserting a large backlog with --num-total-jobs (e.g. a few
+million jobs) and comparing throughput with and without --fair-fetch gives an
+idea of the overhead of fair fetching with a large jobs table.
+
 The database in --database-url will have its jobs table truncated, so make sure
 to use a development database only.
 	`),
@@ -164,10 +186,303 @@ to use a development database only.
 		addDatabaseURLFlag(cmd, &opts.DatabaseURL)
 		addSchemaFlag(cmd, &opts.Schema)
 		cmd.Flags().DurationVar(&opts.Duration, "duration", 0, "duration after which to stop benchmark, accepting Go-style durations like 1m, 5m30s")
+		cmd.Flags().BoolVar(&opts.FairFetch, "fair-fetch", false, "enable fair fetching between partition keys for the benchmark queue")
+		cmd.Flags().IntVar(&opts.NumTenants, "num-tenants", 0, "number of partition keys to distribute inserted jobs between")
 		cmd.Flags().IntVarP(&opts.NumTotalJobs, "num-total-jobs", "n", 0, "number of jobs to insert before starting and which are worked down until finish")
 		rootCmd.AddCommand(cmd)
 	}
 
+	// dead-letter-get
+	{
+		var opts deadLetterGetOpts
+
+		cmd := &cobra.Command{
+			Use:   "dead-letter-get",
+			Short: "Show a dead letter",
+			Long: strings.TrimSpace(`
+Show a single dead letter, including its args, metadata, and the full history
+of errors that occurred while it was worked. Dead letters are identified by the
+ID of the job they were moved from:
+
+    river dead-letter-get --id 123
+
+Dead letters are only produced by clients with DeadLetterEnabled set, and
+require migration version 009.
+	`),
+			RunE: func(cmd *cobra.Command, args []string) error {
+				return RunCommand(ctx, makeCommandBundle(&opts.DatabaseURL, ""), &deadLetterGet{}, &opts)
+			},
+		}
+		addDatabaseURLFlag(cmd, &opts.DatabaseURL)
+		cmd.Flags().Int64Var(&opts.ID, "id", 0, "ID of the dead letter to show")
+		_ = cmd.MarkFlagRequired("id")
+		rootCmd.AddCommand(cmd)
+	}
+
+	// dead-letter-list
+	{
+		var opts deadLetterListOpts
+
+		cmd := &cobra.Command{
+			Use:   "dead-letter-list",
+			Short: "List dead letters",
+			Long: strings.TrimSpace(`
+List dead letters ordered by ID, optionally filtered by kind or queue:
+
+    river dead-letter-list --kind email_send --queue default
+
+Results are paginated. Pass the ID of the last dead letter of a page to --after
+to list the next page.
+	`),
+			RunE: func(cmd *cobra.Command, args []string) error {
+				return RunCommand(ctx, makeCommandBundle(&opts.DatabaseURL, ""), &deadLetterList{}, &opts)
+			},
+		}
+		addDatabaseURLFlag(cmd, &opts.DatabaseURL)
+		cmd.Flags().Int64Var(&opts.After, "after", 0, "list only dead letters with an ID greater than this one")
+		cmd.Flags().StringSliceVar(&opts.Kind, "kind", nil, "list only dead letters of the given kind(s)")
+		cmd.Flags().IntVar(&opts.Limit, "limit", 100, "maximum number of dead letters to list")
+		cmd.Flags().StringSliceVar(&opts.Queue, "queue", nil, "list only dead letters from the given queue(s)")
+		rootCmd.AddCommand(cmd)
+	}
+
+	// dead-letter-purge
+	{
+		var opts deadLetterPurgeOpts
+
+		cmd := &cobra.Command{
+			Use:   "dead-letter-purge",
+			Short: "Permanently delete dead letters",
+			Long: strings.TrimSpace(`
+Permanently delete dead letters matching the given filters. Filters are
+combined so that only dead letters matching all of them are deleted:
+
+    river dead-letter-purge --kind email_send --older-than 720h
+
+At least one filter is required. Use --all to delete every dead letter.
+	`),
+			RunE: func(cmd *cobra.Command, args []string) error {
+				return RunCommand(ctx, makeCommandBundle(&opts.DatabaseURL, ""), &deadLetterPurge{}, &opts)
+			},
+		}
+		addDatabaseURLFlag(cmd, &opts.DatabaseURL)
+		cmd.Flags().BoolVar(&opts.All, "all", false, "delete all dead letters")
+		cmd.Flags().Int64SliceVar(&opts.ID, "id", nil, "delete only dead letters with the given ID(s)")
+		cmd.Flags().StringSliceVar(&opts.Kind, "kind", nil, "delete only dead letters of the given kind(s)")
+		cmd.Flags().DurationVar(&opts.OlderThan, "older-than", 0, "delete only dead letters moved longer ago than this, accepting Go-style durations like 720h")
+		cmd.Flags().StringSliceVar(&opts.Queue, "queue", nil, "delete only dead letters from the given queue(s)")
+		cmd.MarkFlagsMutuallyExclusive("all", "id")
+		cmd.MarkFlagsMutuallyExclusive("all", "kind")
+		cmd.MarkFlagsMutuallyExclusive("all", "older-than")
+		cmd.MarkFlagsMutuallyExclusive("all", "queue")
+		rootCmd.AddCommand(cmd)
+	}
+
+	// dead-letter-requeue
+	{
+		var opts deadLetterRequeueOpts
+
+		cmd := &cobra.Command{
+			Use:   "dead-letter-requeue",
+			Short: "Requeue dead letters as fresh jobs",
+			Long: strings.TrimSpace(`
+Remove dead letters and insert fresh jobs in their place with the same kind,
+args, queue, priority, max attempts, tags, and metadata. New jobs get new IDs
+and are immediately available to be worked:
+
+    river dead-letter-requeue --id 123,124
+	`),
+			RunE: func(cmd *cobra.Command, args []string) error {
+				return RunCommand(ctx, makeCommandBundle(&opts.DatabaseURL, ""), &deadLetterRequeue{}, &opts)
+			},
+		}
+		addDatabaseURLFlag(cmd, &opts.DatabaseURL)
+		cmd.Flags().Int64SliceVar(&opts.ID, "id", nil, "ID(s) of the dead letters to requeue")
+		_ = cmd.MarkFlagRequired("id")
+		rootCmd.AddCommand(cmd)
+	}
+
+	// job and its subcommands
+	{
+		jobCmd := &cobra.Command{
+			Use:   "job",
+			Short: "Inspect and manage jobs",
+			Long: strings.TrimSpace(`
+Inspect and manage jobs. Subcommands that select jobs take a common set of
+filters like --id, --kind, --queue, and --state, which are combined so that
+only jobs matching all of them are selected:
+
+    river job list --kind email_send --state retryable,discarded
+    river job retry --kind email_send --state discarded --created-after 24h
+
+Time filters like --created-after accept either an RFC3339 timestamp or a
+Go-style duration like 24h, which is interpreted as that long ago.
+	`),
+		}
+		rootCmd.AddCommand(jobCmd)
+
+		// Adds flags for filters shared between job subcommands, with verb used
+		// to describe the subcommand's action in their usage.
+		addJobFilterFlags := func(cmd *cobra.Command, opts *jobFilterOpts, verb string) {
+			cmd.Flags().StringVar(&opts.Args, "args", "", verb+" only jobs whose args contain the given JSON fragment")
+			cmd.Flags().IntVar(&opts.AttemptMax, "attempt-max", 0, verb+" only jobs attempted at most this many times")
+			cmd.Flags().IntVar(&opts.AttemptMin, "attempt-min", 0, verb+" only jobs attempted at least this many times")
+			cmd.Flags().StringVar(&opts.CreatedAfter, "created-after", "", verb+" only jobs created at or after this time")
+			cmd.Flags().StringVar(&opts.CreatedBefore, "created-before", "", verb+" only jobs created before this time")
+			cmd.Flags().StringVar(&opts.FinalizedAfter, "finalized-after", "", verb+" only jobs finalized at or after this time")
+			cmd.Flags().StringVar(&opts.FinalizedBefore, "finalized-before", "", verb+" only jobs finalized before this time")
+			cmd.Flags().Int64SliceVar(&opts.ID, "id", nil, verb+" only jobs with the given ID(s)")
+			cmd.Flags().StringSliceVar(&opts.Kind, "kind", nil, verb+" only jobs of the given kind(s)")
+			cmd.Flags().StringVar(&opts.Metadata, "metadata", "", verb+" only jobs whose metadata contains the given JSON fragment")
+			cmd.Flags().IntSliceVar(&opts.Priority, "priority", nil, verb+" only jobs with the given priority(s)")
+			cmd.Flags().StringSliceVar(&opts.Queue, "queue", nil, verb+" only jobs in the given queue(s)")
+			cmd.Flags().StringVar(&opts.ScheduledAfter, "scheduled-after", "", verb+" only jobs scheduled at or after this time")
+			cmd.Flags().StringVar(&opts.ScheduledBefore, "scheduled-before", "", verb+" only jobs scheduled before this time")
+			cmd.Flags().StringSliceVar(&opts.State, "state", nil, verb+" only jobs in the given state(s)")
+			cmd.Flags().StringSliceVar(&opts.Tag, "tag", nil, verb+" only jobs with any of the given tag(s)")
+			cmd.Flags().StringSliceVar(&opts.TagAll, "tag-all", nil, verb+" only jobs with all of the given tag(s)")
+		}
+
+		// job cancel, delete, and retry share a set of options, so this is a
+		// way of plugging in all the right flags to each.
+		addJobActionFlags := func(cmd *cobra.Command, opts *jobActionOpts, verb string) {
+			addDatabaseURLFlag(cmd, &opts.DatabaseURL)
+			addSchemaFlag(cmd, &opts.Schema)
+			addJobFilterFlags(cmd, &opts.jobFilterOpts, verb)
+			cmd.Flags().BoolVarP(&opts.Yes, "yes", "y", false, "skip confirmation when selecting jobs by filters other than --id")
+		}
+
+		// job cancel
+		{
+			var opts jobActionOpts
+
+			cmd := &cobra.Command{
+				Use:   "cancel",
+				Short: "Cancel jobs",
+				Long: strings.TrimSpace(`
+Cancel jobs by ID, or all jobs matching the given filters. Jobs still in the
+queue are cancelled immediately, finalized jobs are left unchanged, and running
+jobs are marked for cancellation so that their clients cancel them:
+
+    river job cancel --id 123,124
+    river job cancel --kind email_send --state available,scheduled
+
+Cancelling jobs by filters other than --id prompts for confirmation, which can
+be skipped with --yes.
+	`),
+				RunE: func(cmd *cobra.Command, args []string) error {
+					return RunCommand(ctx, makeCommandBundle(&opts.DatabaseURL, opts.Schema), &jobCancel{}, &opts)
+				},
+			}
+			addJobActionFlags(cmd, &opts, "cancel")
+			jobCmd.AddCommand(cmd)
+		}
+
+		// job delete
+		{
+			var opts jobActionOpts
+
+			cmd := &cobra.Command{
+				Use:   "delete",
+				Short: "Delete jobs",
+				Long: strings.TrimSpace(`
+Permanently delete jobs by ID, or all jobs matching the given filters. Running
+jobs can't be deleted and are skipped:
+
+    river job delete --id 123,124
+    river job delete --state completed --finalized-before 720h
+
+Deleting jobs by filters other than --id prompts for confirmation, which can be
+skipped with --yes.
+	`),
+				RunE: func(cmd *cobra.Command, args []string) error {
+					return RunCommand(ctx, makeCommandBundle(&opts.DatabaseURL, opts.Schema), &jobDelete{}, &opts)
+				},
+			}
+			addJobActionFlags(cmd, &opts, "delete")
+			jobCmd.AddCommand(cmd)
+		}
+
+		// job get
+		{
+			var opts jobGetOpts
+
+			cmd := &cobra.Command{
+				Use:   "get",
+				Short: "Show a job",
+				Long: strings.TrimSpace(`
+Show a single job, including its args, metadata, and the full history of
+errors that occurred while it was worked:
+
+    river job get --id 123
+	`),
+				RunE: func(cmd *cobra.Command, args []string) error {
+					return RunCommand(ctx, makeCommandBundle(&opts.DatabaseURL, opts.Schema), &jobGet{}, &opts)
+				},
+			}
+			addDatabaseURLFlag(cmd, &opts.DatabaseURL)
+			addOutputFlag(cmd, &opts.Output)
+			addSchemaFlag(cmd, &opts.Schema)
+			cmd.Flags().Int64Var(&opts.ID, "id", 0, "ID of the job to show")
+			_ = cmd.MarkFlagRequired("id")
+			jobCmd.AddCommand(cmd)
+		}
+
+		// job list
+		{
+			var opts jobListOpts
+
+			cmd := &cobra.Command{
+				Use:   "list",
+				Short: "List jobs",
+				Long: strings.TrimSpace(`
+List jobs ordered by ID, optionally filtered:
+
+    river job list --queue default --state running
+
+Results are paginated. When a page is full, a cursor is printed that can be
+passed to --after to list the next page.
+	`),
+				RunE: func(cmd *cobra.Command, args []string) error {
+					return RunCommand(ctx, makeCommandBundle(&opts.DatabaseURL, opts.Schema), &jobList{}, &opts)
+				},
+			}
+			addDatabaseURLFlag(cmd, &opts.DatabaseURL)
+			addJobFilterFlags(cmd, &opts.jobFilterOpts, "list")
+			addOutputFlag(cmd, &opts.Output)
+			addSchemaFlag(cmd, &opts.Schema)
+			cmd.Flags().StringVar(&opts.After, "after", "", "list only jobs after the given cursor from a previous page")
+			cmd.Flags().IntVar(&opts.Limit, "limit", 100, "maximum number of jobs to list")
+			jobCmd.AddCommand(cmd)
+		}
+
+		// job retry
+		{
+			var opts jobActionOpts
+
+			cmd := &cobra.Command{
+				Use:   "retry",
+				Short: "Retry jobs",
+				Long: strings.TrimSpace(`
+Make jobs immediately available to be retried by ID, or all jobs matching the
+given filters. Running jobs and jobs that are already available are left
+unchanged:
+
+    river job retry --id 123,124
+    river job retry --kind email_send --state discarded
+
+Retrying jobs by filters other than --id prompts for confirmation, which can be
+skipped with --yes.
+	`),
+				RunE: func(cmd *cobra.Command, args []string) error {
+					return RunCommand(ctx, makeCommandBundle(&opts.DatabaseURL, opts.Schema), &jobRetry{}, &opts)
+				},
+			}
+			addJobActionFlags(cmd, &opts, "retry")
+			jobCmd.AddCommand(cmd)
+		}
+	}
+
 	// migrate-down and migrate-up share a set of options, so this is a way of
 	// plugging in all the right flags to both so options and docstrings stay
 	// consistent.
@@ -298,6 +613,211 @@ dump prospective migrations that would be applied to stdout.
 		rootCmd.AddCommand(cmd)
 	}
 
+	// queue and its subcommands
+	{
+		queueCmd := &cobra.Command{
+			Use:   "queue",
+			Short: "Inspect and manage queues",
+			Long: strings.TrimSpace(`
+Inspect and manage queues. Pausing, resuming, or updating a queue notifies
+running clients so that they react immediately, the same as when done through
+a River client:
+
+    river queue pause --name default
+    river queue update --name default --concurrency-global-limit 10
+
+Only queues that are being worked or were worked recently are known.
+	`),
+		}
+		rootCmd.AddCommand(queueCmd)
+
+		// queue get
+		{
+			var opts queueGetOpts
+
+			cmd := &cobra.Command{
+				Use:   "get",
+				Short: "Show a queue",
+				Long: strings.TrimSpace(`
+Show a single queue, including its metadata:
+
+    river queue get --name default
+	`),
+				RunE: func(cmd *cobra.Command, args []string) error {
+					return RunCommand(ctx, makeCommandBundle(&opts.DatabaseURL, opts.Schema), &queueGet{}, &opts)
+				},
+			}
+			addDatabaseURLFlag(cmd, &opts.DatabaseURL)
+			addOutputFlag(cmd, &opts.Output)
+			addSchemaFlag(cmd, &opts.Schema)
+			cmd.Flags().StringVar(&opts.Name, "name", "", "name of the queue to show")
+			_ = cmd.MarkFlagRequired("name")
+			queueCmd.AddCommand(cmd)
+		}
+
+		// queue list
+		{
+			var opts queueListOpts
+
+			cmd := &cobra.Command{
+				Use:   "list",
+				Short: "List queues",
+				Long: strings.TrimSpace(`
+List queues ordered by name:
+
+    river queue list
+	`),
+				RunE: func(cmd *cobra.Command, args []string) error {
+					return RunCommand(ctx, makeCommandBundle(&opts.DatabaseURL, opts.Schema), &queueList{}, &opts)
+				},
+			}
+			addDatabaseURLFlag(cmd, &opts.DatabaseURL)
+			addOutputFlag(cmd, &opts.Output)
+			addSchemaFlag(cmd, &opts.Schema)
+			cmd.Flags().IntVar(&opts.Limit, "limit", 100, "maximum number of queues to list")
+			queueCmd.AddCommand(cmd)
+		}
+
+		// queue pause and resume share a set of options, so this is a way of
+		// plugging in all the right flags to both.
+		addQueuePauseFlags := func(cmd *cobra.Command, opts *queuePauseOpts, verb string) {
+			addDatabaseURLFlag(cmd, &opts.DatabaseURL)
+			addSchemaFlag(cmd, &opts.Schema)
+			cmd.Flags().BoolVar(&opts.All, "all", false, verb+" all queues")
+			cmd.Flags().StringSliceVar(&opts.Name, "name", nil, "name(s) of the queues to "+verb)
+			cmd.MarkFlagsMutuallyExclusive("all", "name")
+			cmd.MarkFlagsOneRequired("all", "name")
+		}
+
+		// queue pause
+		{
+			var opts queuePauseOpts
+
+			cmd := &cobra.Command{
+				Use:   "pause",
+				Short: "Pause queues",
+				Long: strings.TrimSpace(`
+Pause queues so that clients stop fetching new jobs from them. Jobs that are
+already running are left to finish:
+
+    river queue pause --name default,email
+    river queue pause --all
+
+Clients listening for notifications pause within moments. Clients in poll-only
+mode pause after their next poll for queue configuration.
+	`),
+				RunE: func(cmd *cobra.Command, args []string) error {
+					return RunCommand(ctx, makeCommandBundle(&opts.DatabaseURL, opts.Schema), &queuePause{}, &opts)
+				},
+			}
+			addQueuePauseFlags(cmd, &opts, "pause")
+			queueCmd.AddCommand(cmd)
+		}
+
+		// queue resume
+		{
+			var opts queuePauseOpts
+
+			cmd := &cobra.Command{
+				Use:   "resume",
+				Short: "Resume paused queues",
+				Long: strings.TrimSpace(`
+Resume paused queues so that clients start fetching jobs from them again:
+
+    river queue resume --name default,email
+    river queue resume --all
+
+Clients listening for notifications resume within moments. Clients in
+poll-only mode resume after their next poll for queue configuration.
+	`),
+				RunE: func(cmd *cobra.Command, args []string) error {
+					return RunCommand(ctx, makeCommandBundle(&opts.DatabaseURL, opts.Schema), &queueResume{}, &opts)
+				},
+			}
+			addQueuePauseFlags(cmd, &opts, "resume")
+			queueCmd.AddCommand(cmd)
+		}
+
+		// queue update
+		{
+			var opts queueUpdateOpts
+
+			cmd := &cobra.Command{
+				Use:   "update",
+				Short: "Update a queue's metadata or concurrency limits",
+				Long: strings.TrimSpace(`
+Update a queue's metadata, or override the concurrency limits configured for it
+in every client working it:
+
+    river queue update --name default --metadata '{"owner":"billing"}'
+    river queue update --name default --concurrency-global-limit 10
+    river queue update --name default --concurrency-partition-by-kind --concurrency-partition-limit 2
+
+Concurrency flags replace any existing override as a whole. Remove an override
+with --concurrency-reset so that clients go back to their own configuration.
+A concurrency override is preserved when only metadata is updated.
+	`),
+				RunE: func(cmd *cobra.Command, args []string) error {
+					return RunCommand(ctx, makeCommandBundle(&opts.DatabaseURL, opts.Schema), &queueUpdate{}, &opts)
+				},
+			}
+			addDatabaseURLFlag(cmd, &opts.DatabaseURL)
+			addOutputFlag(cmd, &opts.Output)
+			addSchemaFlag(cmd, &opts.Schema)
+			cmd.Flags().IntVar(&opts.ConcurrencyGlobalLimit, "concurrency-global-limit", 0, "maximum number of the queue's jobs that may be running at once across all clients")
+			cmd.Flags().BoolVar(&opts.ConcurrencyPartitionByArgs, "concurrency-partition-by-args", false, "partition the queue's jobs by the values of their args' partition fields")
+			cmd.Flags().BoolVar(&opts.ConcurrencyPartitionByKind, "concurrency-partition-by-kind", false, "partition the queue's jobs by kind")
+			cmd.Flags().IntVar(&opts.ConcurrencyPartitionLimit, "concurrency-partition-limit", 0, "maximum number of jobs from any single partition that may be running at once across all clients")
+			cmd.Flags().BoolVar(&opts.ConcurrencyReset, "concurrency-reset", false, "remove any concurrency override")
+			cmd.Flags().StringVar(&opts.Metadata, "metadata", "", "new metadata for the queue as a JSON object")
+			cmd.Flags().StringVar(&opts.Name, "name", "", "name of the queue to update")
+			_ = cmd.MarkFlagRequired("name")
+			cmd.MarkFlagsMutuallyExclusive("concurrency-reset", "concurrency-global-limit")
+			cmd.MarkFlagsMutuallyExclusive("concurrency-reset", "concurrency-partition-by-args")
+			cmd.MarkFlagsMutuallyExclusive("concurrency-reset", "concurrency-partition-by-kind")
+			cmd.MarkFlagsMutuallyExclusive("concurrency-reset", "concurrency-partition-limit")
+			queueCmd.AddCommand(cmd)
+		}
+	}
+
+	// top
+	{
+		var opts topOpts
+
+		cmd := &cobra.Command{
+			Use:   "top",
+			Short: "Show a live view of queues and clients",
+			Long: strings.TrimSpace(`
+Show a continuously refreshing view of queues, including their number of jobs
+in each state, the jobs running in each client, throughput, and error rate,
+along with the currently elected leader:
+
+    river top
+    river top --interval 5s
+
+Throughput and error rate are measured over the last five minutes. Error rate
+is the proportion of jobs finalized in that time that were discarded rather
+than completed.
+
+When run in a terminal, queues can be selected with the up and down arrow keys
+(or j and k) and paused or resumed with p and r. Press q to quit.
+
+When output isn't a terminal, each refresh is printed in turn instead. Use
+--iterations to stop after a number of refreshes:
+
+    river top --iterations 1
+	`),
+			RunE: func(cmd *cobra.Command, args []string) error {
+				return RunCommand(ctx, makeCommandBundle(&opts.DatabaseURL, opts.Schema), &top{}, &opts)
+			},
+		}
+		addDatabaseURLFlag(cmd, &opts.DatabaseURL)
+		addSchemaFlag(cmd, &opts.Schema)
+		cmd.Flags().DurationVar(&opts.Interval, "interval", 2*time.Second, "interval between refreshes, accepting Go-style durations like 2s, 1m")
+		cmd.Flags().IntVarP(&opts.Iterations, "iterations", "n", 0, "number of refreshes after which to exit (default: refresh until quit)")
+		rootCmd.AddCommand(cmd)
+	}
+
 	// validate
 	{
 		var opts validateOpts
@@ -340,6 +860,10 @@ Print River and Go version information.
 	return rootCmd
 }
 
+// SetIn sets standard input, which is read to confirm destructive operations.
+// Should be called before BaseCommandSet.
+func (c *CLI) SetIn(in io.Reader) { c.in = in }
+
 // SetOut sets standard output. Should be called before BaseCommandSet.
 func (c *CLI) SetOut(out io.Writer) { c.out = out }
 
@@ -347,6 +871,8 @@ type benchOpts struct {
 	DatabaseURL  string
 	Debug        bool
 	Duration     time.Duration
+	FairFetch    bool
+	NumTenants   int
 	NumTotalJobs int
 	Schema       string
 	Verbose      bool
@@ -357,6 +883,10 @@ func (o *benchOpts) Validate() error {
 		return errors.New("either PG* env vars or --database-url must be set")
 	}
 
+	if o.NumTenants < 0 {
+		return errors.New("--num-tenants must be greater than or equal to zero")
+	}
+
 	return nil
 }
 
@@ -365,23 +895,23 @@ type bench struct {
 }
 
 func (c *bench) Run(ctx context.Context, opts *benchOpts) (bool, error) {
-	if err := c.GetBenchmarker().Run(ctx, opts.Duration, opts.NumTotalJobs); err != nil {
+	if err := c.GetBenchmarker().Run(ctx, &riverbench.RunOpts{
+		Duration:     opts.Duration,
+		FairFetch:    opts.FairFetch,
+		NumTenants:   opts.NumTenants,
+		NumTotalJobs: opts.NumTotalJobs,
+	}); err != nil {
 		return false, err
 	}
 	return true, nil
 }
 
-type migrateOpts struct {
-	DatabaseURL   string
-	DryRun        bool
-	Line          string
-	ShowSQL       bool
-	MaxSteps      int
-	Schema        string
-	TargetVersion int
+type deadLetterGetOpts struct {
+	DatabaseURL string
+	ID          int64
 }
 
-func (o *migrateOpts) Validate() error {
+func (o *deadLetterGetOpts) Validate() error {
 	if o.DatabaseURL == "" && !pgEnvConfigured() {
 		return errors.New("either PG* env vars or --database-url must be set")
 	}
@@ -389,221 +919,1844 @@ func (o *migrateOpts) Validate() error {
 	return nil
 }
 
-type migrateDown struct {
+type deadLetterGet struct {
 	CommandBase
 }
 
-func (c *migrateDown) Run(ctx context.Context, opts *migrateOpts) (bool, error) {
-	migrator, err := c.GetMigrator(&rivermigrate.Config{Line: opts.Line, Logger: c.Logger})
+func (c *deadLetterGet) Run(ctx context.Context, opts *deadLetterGetOpts) (bool, error) {
+	client, err := c.GetClient()
 	if err != nil {
 		return false, err
 	}
 
-	res, err := migrator.Migrate(ctx, rivermigrate.DirectionDown, &rivermigrate.MigrateOpts{
-		DryRun:        opts.DryRun,
-		MaxSteps:      opts.MaxSteps,
-		TargetVersion: opts.TargetVersion,
-	})
+	deadLetter, err := client.DeadLetterGet(ctx, opts.ID)
 	if err != nil {
+		if errors.Is(err, rivertype.ErrNotFound) {
+			fmt.Fprintf(c.Out, "no dead letter with ID %d\n", opts.ID)
+			return false, nil
+		}
 		return false, err
 	}
 
-	migratePrintResult(c.Out, opts, res, rivermigrate.DirectionDown)
-
-	return true, nil
-}
-
-// Rounds a duration so that it doesn't show so much cluttered and not useful
-// precision in printf output.
-func roundDuration(duration time.Duration) time.Duration {
-	switch {
-	case duration > 1*time.Second:
-		return duration.Truncate(10 * time.Millisecond)
-	case duration < 1*time.Millisecond:
-		return duration.Truncate(10 * time.Nanosecond)
-	default:
-		return duration.Truncate(10 * time.Microsecond)
+	tabWriter := tabwriter.NewWriter(c.Out, 0, 0, 2, ' ', 0)
+	fmt.Fprintf(tabWriter, "id:\t%d\n", deadLetter.ID)
+	fmt.Fprintf(tabWriter, "kind:\t%s\n", deadLetter.Kind)
+	fmt.Fprintf(tabWriter, "queue:\t%s\n", deadLetter.Queue)
+	fmt.Fprintf(tabWriter, "priority:\t%d\n", deadLetter.Priority)
+	fmt.Fprintf(tabWriter, "attempt:\t%d/%d\n", deadLetter.Attempt, deadLetter.MaxAttempts)
+	fmt.Fprintf(tabWriter, "tags:\t%s\n", strings.Join(deadLetter.Tags, ","))
+	fmt.Fprintf(tabWriter, "created at:\t%s\n", formatOptionalTime(&deadLetter.CreatedAt))
+	fmt.Fprintf(tabWriter, "finalized at:\t%s\n", formatOptionalTime(deadLetter.FinalizedAt))
+	fmt.Fprintf(tabWriter, "dead lettered at:\t%s\n", formatOptionalTime(&deadLetter.DeadLetteredAt))
+	fmt.Fprintf(tabWriter, "args:\t%s\n", deadLetter.EncodedArgs)
+	fmt.Fprintf(tabWriter, "metadata:\t%s\n", deadLetter.Metadata)
+	if err := tabWriter.Flush(); err != nil {
+		return false, err
 	}
-}
 
-func migratePrintResult(out io.Writer, opts *migrateOpts, res *rivermigrate.MigrateResult, direction rivermigrate.Direction) {
-	if len(res.Versions) < 1 {
-		fmt.Fprintf(out, "no migrations to apply\n")
-		return
+	fmt.Fprintf(c.Out, "errors:\n")
+	for _, attemptErr := range deadLetter.Errors {
+		fmt.Fprintf(c.Out, "  attempt %d at %s: %s\n", attemptErr.Attempt, formatOptionalTime(&attemptErr.At), attemptErr.Error)
 	}
 
-	versionWithLongestName := slices.MaxFunc(res.Versions,
-		func(v1, v2 rivermigrate.MigrateVersion) int { return len(v1.Name) - len(v2.Name) })
+	return true, nil
+}
 
-	for _, migrateVersion := range res.Versions {
-		if opts.DryRun {
-			fmt.Fprintf(out, "migration %03d [%s] [DRY RUN]\n", migrateVersion.Version, direction)
-		} else {
-			fmt.Fprintf(out, "applied migration %03d [%s] %-*s [%s]\n", migrateVersion.Version, direction, len(versionWithLongestName.Name), migrateVersion.Name, roundDuration(migrateVersion.Duration))
-		}
+type deadLetterListOpts struct {
+	After       int64
+	DatabaseURL string
+	Kind        []string
+	Limit       int
+	Queue       []string
+}
 
-		if opts.ShowSQL {
-			fmt.Fprintf(out, "%s\n", strings.Repeat("-", 80))
-			fmt.Fprintf(out, "%s\n", migrationComment(opts.Line, migrateVersion.Version, direction))
-			fmt.Fprintf(out, "%s\n\n", strings.TrimSpace(migrateVersion.SQL))
-		}
+func (o *deadLetterListOpts) Validate() error {
+	if o.DatabaseURL == "" && !pgEnvConfigured() {
+		return errors.New("either PG* env vars or --database-url must be set")
 	}
 
-	// Only prints if more steps than available were requested.
-	if opts.MaxSteps > 0 && len(res.Versions) < opts.MaxSteps {
-		fmt.Fprintf(out, "no more migrations to apply\n")
+	if o.Limit < 1 || o.Limit > 10_000 {
+		return errors.New("--limit must be between 1 and 10000")
 	}
-}
-
-// An informational comment that's tagged on top of any migration's SQL to help
-// attribute what it is for when it's copied elsewhere like other migration
-// frameworks.
-func migrationComment(line string, version int, direction rivermigrate.Direction) string {
-	return fmt.Sprintf("-- River %s migration %03d [%s]", line, version, direction)
-}
 
-type migrateGetOpts struct {
-	All            bool
-	Down           bool
-	ExcludeVersion []int
-	Line           string
-	Up             bool
-	Version        []int
+	return nil
 }
 
-func (o *migrateGetOpts) Validate() error { return nil }
-
-type migrateGet struct {
+type deadLetterList struct {
 	CommandBase
 }
 
-func (c *migrateGet) Run(_ context.Context, opts *migrateGetOpts) (bool, error) {
-	// We'll need to have a way of using an alternate driver if support for
-	// other databases is added in the future. Unlike other migrate commands,
-	// this one doesn't take a `--database-url`, so we'd need a way of
-	// detecting the database type.
-	migrator, err := rivermigrate.New(c.DriverProcurer.ProcurePgxV5(nil), &rivermigrate.Config{Line: opts.Line, Logger: c.Logger})
+func (c *deadLetterList) Run(ctx context.Context, opts *deadLetterListOpts) (bool, error) {
+	client, err := c.GetClient()
 	if err != nil {
 		return false, err
 	}
 
-	var migrations []rivermigrate.Migration
-	if opts.All {
-		migrations = migrator.AllVersions()
-		if opts.Down {
-			slices.Reverse(migrations)
+	params := river.NewDeadLetterListParams().After(opts.After).First(opts.Limit)
+	if len(opts.Kind) > 0 {
+		params = params.Kinds(opts.Kind...)
+	}
+	if len(opts.Queue) > 0 {
+		params = params.Queues(opts.Queue...)
+	}
+
+	res, err := client.DeadLetterList(ctx, params)
+	if err != nil {
+		return false, err
+	}
+
+	if len(res.DeadLetters) < 1 {
+		fmt.Fprintf(c.Out, "no dead letters found\n")
+		return true, nil
+	}
+
+	tabWriter := tabwriter.NewWriter(c.Out, 0, 0, 2, ' ', 0)
+	fmt.Fprintf(tabWriter, "ID\tKIND\tQUEUE\tATTEMPT\tDEAD LETTERED AT\tLAST ERROR\n")
+	for _, deadLetter := range res.DeadLetters {
+		var lastError string
+		if len(deadLetter.Errors) > 0 {
+			lastError = deadLetter.Errors[len(deadLetter.Errors)-1].Error
 		}
-	} else {
-		for _, version := range opts.Version {
-			migration, err := migrator.GetVersion(version)
-			if err != nil {
-				return false, err
+
+		fmt.Fprintf(tabWriter, "%d\t%s\t%s\t%d/%d\t%s\t%s\n",
+			deadLetter.ID,
+			deadLetter.Kind,
+			deadLetter.Queue,
+			deadLetter.Attempt,
+			deadLetter.MaxAttempts,
+			deadLetter.DeadLetteredAt.UTC().Format(time.RFC3339),
+			truncateString(firstLine(lastError), 80),
+		)
+	}
+	if err := tabWriter.Flush(); err != nil {
+		return false, err
+	}
+
+	return true, nil
+}
+
+// Returns the first line of a possibly multi-line string.
+func firstLine(s string) string {
+	if i := strings.IndexByte(s, '\n'); i >= 0 {
+		return s[:i]
+	}
+	return s
+}
+
+// Truncates a string to the given maximum number of runes, adding an ellipsis
+// if it was truncated.
+func truncateString(s string, maxLen int) string {
+	runes := []rune(s)
+	if len(runes) <= maxLen {
+		return s
+	}
+	return string(runes[:maxLen-1]) + "…"
+}
+
+// Formats a time for output, or a placeholder if it's not set.
+func formatOptionalTime(t *time.Time) string {
+	if t == nil {
+		return "-"
+	}
+	return t.UTC().Format(time.RFC3339)
+}
+
+type deadLetterPurgeOpts struct {
+	All         bool
+	DatabaseURL string
+	ID          []int64
+	Kind        []string
+	OlderThan   time.Duration
+	Queue       []string
+}
+
+func (o *deadLetterPurgeOpts) Validate() error {
+	if o.DatabaseURL == "" && !pgEnvConfigured() {
+		return errors.New("either PG* env vars or --database-url must be set")
+	}
+
+	if o.OlderThan < 0 {
+		return errors.New("--older-than must be greater than or equal to zero")
+	}
+
+	if !o.All && len(o.ID) < 1 && len(o.Kind) < 1 && o.OlderThan == 0 && len(o.Queue) < 1 {
+		return errors.New("at least one of --id, --kind, --older-than, or --queue must be set; use --all to purge all dead letters")
+	}
+
+	return nil
+}
+
+type deadLetterPurge struct {
+	CommandBase
+}
+
+func (c *deadLetterPurge) Run(ctx context.Context, opts *deadLetterPurgeOpts) (bool, error) {
+	client, err := c.GetClient()
+	if err != nil {
+		return false, err
+	}
+
+	params := river.NewDeadLetterPurgeParams()
+	if len(opts.ID) > 0 {
+		params = params.IDs(opts.ID...)
+	}
+	if len(opts.Kind) > 0 {
+		params = params.Kinds(opts.Kind...)
+	}
+	if opts.OlderThan > 0 {
+		params = params.DeadLetteredBefore(time.Now().Add(-opts.OlderThan))
+	}
+	if len(opts.Queue) > 0 {
+		params = params.Queues(opts.Queue...)
+	}
+
+	numPurged, err := client.DeadLetterPurge(ctx, params)
+	if err != nil {
+		return false, err
+	}
+
+	fmt.Fprintf(c.Out, "purged %d dead letter(s)\n", numPurged)
+
+	return true, nil
+}
+
+type deadLetterRequeueOpts struct {
+	DatabaseURL string
+	ID          []int64
+}
+
+func (o *deadLetterRequeueOpts) Validate() error {
+	if o.DatabaseURL == "" && !pgEnvConfigured() {
+		return errors.New("either PG* env vars or --database-url must be set")
+	}
+
+	if len(o.ID) < 1 {
+		return errors.New("at least one --id must be set")
+	}
+
+	return nil
+}
+
+type deadLetterRequeue struct {
+	CommandBase
+}
+
+func (c *deadLetterRequeue) Run(ctx context.Context, opts *deadLetterRequeueOpts) (bool, error) {
+	client, err := c.GetClient()
+	if err != nil {
+		return false, err
+	}
+
+	ok := true
+	for _, id := range opts.ID {
+		insertRes, err := client.DeadLetterRequeue(ctx, id)
+		if err != nil {
+			if errors.Is(err, rivertype.ErrNotFound) {
+				fmt.Fprintf(c.Out, "no dead letter with ID %d\n", id)
+				ok = false
+				continue
 			}
+			return false, err
+		}
 
-			migrations = append(migrations, migration)
+		fmt.Fprintf(c.Out, "requeued dead letter %d as job %d\n", id, insertRes.Job.ID)
+	}
+
+	return ok, nil
+}
+
+// Formats accepted by --output.
+const (
+	outputJSON  = "json"
+	outputTable = "table"
+)
+
+func validateOutput(output string) error {
+	if output != outputJSON && output != outputTable {
+		return fmt.Errorf("--output must be one of `%s` or `%s`", outputTable, outputJSON)
+	}
+
+	return nil
+}
+
+// jobJSON is the representation of a job in JSON output. JobRow carries no
+// JSON tags, and its args and metadata are raw bytes which would otherwise be
+// output as base64.
+type jobJSON struct {
+	ID          int64                    `json:"id"`
+	Args        json.RawMessage          `json:"args"`
+	Attempt     int                      `json:"attempt"`
+	AttemptedAt *time.Time               `json:"attempted_at"`
+	AttemptedBy []string                 `json:"attempted_by"`
+	CreatedAt   time.Time                `json:"created_at"`
+	Errors      []rivertype.AttemptError `json:"errors"`
+	FinalizedAt *time.Time               `json:"finalized_at"`
+	Kind        string                   `json:"kind"`
+	MaxAttempts int                      `json:"max_attempts"`
+	Metadata    json.RawMessage          `json:"metadata"`
+	Priority    int                      `json:"priority"`
+	Queue       string                   `json:"queue"`
+	ScheduledAt time.Time                `json:"scheduled_at"`
+	State       rivertype.JobState       `json:"state"`
+	Tags        []string                 `json:"tags"`
+}
+
+func jobJSONFromRow(job *rivertype.JobRow) *jobJSON {
+	return &jobJSON{
+		ID:          job.ID,
+		Args:        rawJSONOrNull(job.EncodedArgs),
+		Attempt:     job.Attempt,
+		AttemptedAt: job.AttemptedAt,
+		AttemptedBy: job.AttemptedBy,
+		CreatedAt:   job.CreatedAt,
+		Errors:      job.Errors,
+		FinalizedAt: job.FinalizedAt,
+		Kind:        job.Kind,
+		MaxAttempts: job.MaxAttempts,
+		Metadata:    rawJSONOrNull(job.Metadata),
+		Priority:    job.Priority,
+		Queue:       job.Queue,
+		ScheduledAt: job.ScheduledAt,
+		State:       job.State,
+		Tags:        job.Tags,
+	}
+}
+
+// Returns raw bytes as JSON for output, substituting null for empty bytes
+// which would otherwise be invalid JSON and fail to marshal.
+func rawJSONOrNull(data []byte) json.RawMessage {
+	if len(data) < 1 {
+		return json.RawMessage("null")
+	}
+	return data
+}
+
+func writeJSON(out io.Writer, value any) error {
+	encoder := json.NewEncoder(out)
+	encoder.SetIndent("", "  ")
+	return encoder.Encode(value)
+}
+
+// jobFilterOpts are filters shared between job commands that select jobs,
+// mapping onto the filters of river.JobListParams.
+type jobFilterOpts struct {
+	Args            string
+	AttemptMax      int
+	AttemptMin      int
+	CreatedAfter    string
+	CreatedBefore   string
+	FinalizedAfter  string
+	FinalizedBefore string
+	ID              []int64
+	Kind            []string
+	Metadata        string
+	Priority        []int
+	Queue           []string
+	ScheduledAfter  string
+	ScheduledBefore string
+	State           []string
+	Tag             []string
+	TagAll          []string
+}
+
+// Returns true if any filter other than --id is set.
+func (o *jobFilterOpts) hasNonIDFilters() bool {
+	return o.Args != "" ||
+		o.AttemptMax > 0 ||
+		o.AttemptMin > 0 ||
+		o.CreatedAfter != "" ||
+		o.CreatedBefore != "" ||
+		o.FinalizedAfter != "" ||
+		o.FinalizedBefore != "" ||
+		len(o.Kind) > 0 ||
+		o.Metadata != "" ||
+		len(o.Priority) > 0 ||
+		len(o.Queue) > 0 ||
+		o.ScheduledAfter != "" ||
+		o.ScheduledBefore != "" ||
+		len(o.State) > 0 ||
+		len(o.Tag) > 0 ||
+		len(o.TagAll) > 0
+}
+
+// Builds list params from filters, with relative times like 24h interpreted as
+// that long before now. Also used for validation, so any invalid filter is
+// returned as an error.
+func (o *jobFilterOpts) jobListParams(now time.Time) (*river.JobListParams, error) {
+	if o.AttemptMax < 0 || o.AttemptMin < 0 {
+		return nil, errors.New("--attempt-max and --attempt-min must be greater than or equal to zero")
+	}
+
+	params := river.NewJobListParams()
+
+	if o.Args != "" {
+		if !json.Valid([]byte(o.Args)) {
+			return nil, errors.New("--args must be valid JSON")
 		}
+		params = params.Args(o.Args)
+	}
+	if o.AttemptMax > 0 {
+		params = params.AttemptAtMost(o.AttemptMax)
+	}
+	if o.AttemptMin > 0 {
+		params = params.AttemptAtLeast(o.AttemptMin)
 	}
 
-	var printedOne bool
+	for _, timeRange := range []struct {
+		name          string
+		after, before string
+		apply         func(params *river.JobListParams, start, end time.Time) *river.JobListParams
+	}{
+		{"created", o.CreatedAfter, o.CreatedBefore, (*river.JobListParams).CreatedAtRange},
+		{"finalized", o.FinalizedAfter, o.FinalizedBefore, (*river.JobListParams).FinalizedAtRange},
+		{"scheduled", o.ScheduledAfter, o.ScheduledBefore, (*river.JobListParams).ScheduledAtRange},
+	} {
+		start, err := parseTimeFlag(timeRange.name+"-after", timeRange.after, now)
+		if err != nil {
+			return nil, err
+		}
+		end, err := parseTimeFlag(timeRange.name+"-before", timeRange.before, now)
+		if err != nil {
+			return nil, err
+		}
 
-	for _, migration := range migrations {
-		if slices.Contains(opts.ExcludeVersion, migration.Version) {
+		if start.IsZero() && end.IsZero() {
 			continue
 		}
-
-		// print newlines between multiple versions
-		if printedOne {
-			fmt.Fprintf(c.Out, "\n")
+		if !start.IsZero() && !end.IsZero() && end.Before(start) {
+			return nil, fmt.Errorf("--%s-before must not be before --%s-after", timeRange.name, timeRange.name)
 		}
 
-		var (
-			direction rivermigrate.Direction
-			sql       string
-		)
-		switch {
-		case opts.Down:
-			direction = rivermigrate.DirectionDown
-			sql = migration.SQLDown
-		case opts.Up:
-			direction = rivermigrate.DirectionUp
-			sql = migration.SQLUp
+		params = timeRange.apply(params, start, end)
+	}
+
+	if len(o.ID) > 0 {
+		params = params.IDs(o.ID...)
+	}
+	if len(o.Kind) > 0 {
+		params = params.Kinds(o.Kind...)
+	}
+	if o.Metadata != "" {
+		if !json.Valid([]byte(o.Metadata)) {
+			return nil, errors.New("--metadata must be valid JSON")
+		}
+		params = params.Metadata(o.Metadata)
+	}
+	if len(o.Priority) > 0 {
+		params = params.Priorities(o.Priority...)
+	}
+	if len(o.Queue) > 0 {
+		params = params.Queues(o.Queue...)
+	}
+	if len(o.State) > 0 {
+		states := make([]rivertype.JobState, len(o.State))
+		for i, state := range o.State {
+			states[i] = rivertype.JobState(state)
+			if !slices.Contains(rivertype.JobStates(), states[i]) {
+				return nil, fmt.Errorf("invalid --state `%s`; must be one of: %s", state, strings.Join(sliceutil.Map(rivertype.JobStates(), func(s rivertype.JobState) string { return string(s) }), ", "))
+			}
 		}
+		params = params.States(states...)
+	}
+	if len(o.Tag) > 0 {
+		params = params.TagsAny(o.Tag...)
+	}
+	if len(o.TagAll) > 0 {
+		params = params.TagsAll(o.TagAll...)
+	}
 
-		printedOne = true
-		fmt.Fprintf(c.Out, "%s\n", migrationComment(opts.Line, migration.Version, direction))
-		fmt.Fprintf(c.Out, "%s\n", strings.TrimSpace(sql))
+	return params, nil
+}
+
+// Parses the value of a time flag, which may be either an RFC3339 timestamp or
+// a Go-style duration like 24h interpreted as that long before now. Returns a
+// zero time if the value is empty.
+func parseTimeFlag(name, value string, now time.Time) (time.Time, error) {
+	if value == "" {
+		return time.Time{}, nil
 	}
 
-	return true, nil
+	if t, err := time.Parse(time.RFC3339, value); err == nil {
+		return t, nil
+	}
+
+	if duration, err := time.ParseDuration(value); err == nil {
+		return now.Add(-duration), nil
+	}
+
+	return time.Time{}, fmt.Errorf("--%s must be an RFC3339 timestamp like 2025-01-01T00:00:00Z or a duration like 24h", name)
 }
 
-type migrateListOpts struct {
+// jobActionOpts are options shared by job commands that act on jobs selected
+// by ID or by filters.
+type jobActionOpts struct {
+	jobFilterOpts
+
 	DatabaseURL string
-	Line        string
 	Schema      string
+	Yes         bool
 }
 
-func (o *migrateListOpts) Validate() error { return nil }
+func (o *jobActionOpts) Validate() error {
+	if o.DatabaseURL == "" && !pgEnvConfigured() {
+		return errors.New("either PG* env vars or --database-url must be set")
+	}
 
-type migrateList struct {
-	CommandBase
+	if len(o.ID) < 1 && !o.hasNonIDFilters() {
+		return errors.New("at least one of --id or another filter like --kind, --queue, or --state must be set")
+	}
+
+	if _, err := o.jobListParams(time.Now()); err != nil {
+		return err
+	}
+
+	return nil
 }
 
-func (c *migrateList) Run(ctx context.Context, opts *migrateListOpts) (bool, error) {
-	migrator, err := c.GetMigrator(&rivermigrate.Config{Line: opts.Line, Logger: c.Logger})
+// jobAction describes an action taken by one of the job commands that act on
+// jobs selected by ID or by filters.
+type jobAction struct {
+	pastTense     string // like "cancelled"
+	skippedReason string // like "were already finalized"
+	verb          string // like "cancel"
+
+	actOne  func(ctx context.Context, id int64) (*rivertype.JobRow, error)
+	actMany func(ctx context.Context, params *river.JobListParams) (numActed, numSkipped int, err error)
+}
+
+// Runs a job action. Jobs selected by --id alone are acted on one by one, with
+// a line of output for each. Jobs selected by other filters are acted on in
+// bulk after the number of matching jobs is confirmed, unless --yes was given.
+// Confirmed jobs are pinned to those with an ID no higher than the highest one
+// matching when the prompt was shown so that jobs inserted while waiting for
+// confirmation aren't acted on.
+func (c *CommandBase) runJobAction(ctx context.Context, client ClientInterface, opts *jobActionOpts, action *jobAction) (bool, error) {
+	if !opts.hasNonIDFilters() {
+		ok := true
+		for _, id := range opts.ID {
+			job, err := action.actOne(ctx, id)
+			if err != nil {
+				switch {
+				case errors.Is(err, rivertype.ErrNotFound):
+					fmt.Fprintf(c.Out, "no job with ID %d\n", id)
+				case errors.Is(err, rivertype.ErrJobRunning):
+					fmt.Fprintf(c.Out, "job %d not %s: %s\n", id, action.pastTense, err)
+				default:
+					return false, err
+				}
+				ok = false
+				continue
+			}
+
+			fmt.Fprintf(c.Out, "%s job %d (state: %s)\n", action.pastTense, id, job.State)
+		}
+
+		return ok, nil
+	}
+
+	params, err := opts.jobListParams(time.Now())
 	if err != nil {
 		return false, err
 	}
 
-	allMigrations := migrator.AllVersions()
+	if !opts.Yes {
+		listRes, err := client.JobList(ctx, params.OrderBy(river.JobListOrderByID, river.SortOrderDesc).First(1))
+		if err != nil {
+			return false, err
+		}
 
-	existingMigrations, err := migrator.ExistingVersions(ctx)
+		if len(listRes.Jobs) < 1 {
+			fmt.Fprintf(c.Out, "no jobs match the given filters\n")
+			return true, nil
+		}
+
+		params = params.IDAtMost(listRes.Jobs[0].ID)
+
+		numJobs, err := client.JobCount(ctx, params)
+		if err != nil {
+			return false, err
+		}
+
+		if numJobs < 1 {
+			fmt.Fprintf(c.Out, "no jobs match the given filters\n")
+			return true, nil
+		}
+
+		fmt.Fprintf(c.Out, "%s %d job(s) matching the given filters? [y/N] ", action.verb, numJobs)
+
+		answer, err := bufio.NewReader(c.In).ReadString('\n')
+		if err != nil && !errors.Is(err, io.EOF) {
+			return false, fmt.Errorf("error reading confirmation: %w", err)
+		}
+
+		if answer = strings.ToLower(strings.TrimSpace(answer)); answer != "y" && answer != "yes" {
+			fmt.Fprintf(c.Out, "aborted\n")
+			return false, nil
+		}
+	}
+
+	numActed, numSkipped, err := action.actMany(ctx, params)
 	if err != nil {
 		return false, err
 	}
 
-	var maxExistingVersion int
-	if len(existingMigrations) > 0 {
-		maxExistingVersion = existingMigrations[len(existingMigrations)-1].Version
+	fmt.Fprintf(c.Out, "%s %d job(s)", action.pastTense, numActed)
+	if numSkipped > 0 {
+		fmt.Fprintf(c.Out, "; skipped %d job(s) that %s", numSkipped, action.skippedReason)
 	}
+	fmt.Fprintf(c.Out, "\n")
 
-	for _, migration := range allMigrations {
-		var currentVersionPrefix string
-		switch {
-		case migration.Version == maxExistingVersion:
-			currentVersionPrefix = "* "
-		case maxExistingVersion > 0:
-			currentVersionPrefix = "  "
-		}
+	return true, nil
+}
 
-		fmt.Fprintf(c.Out, "%s%03d %s\n", currentVersionPrefix, migration.Version, migration.Name)
+type jobCancel struct {
+	CommandBase
+}
+
+func (c *jobCancel) Run(ctx context.Context, opts *jobActionOpts) (bool, error) {
+	client, err := c.GetClient()
+	if err != nil {
+		return false, err
 	}
 
-	return true, nil
+	return c.runJobAction(ctx, client, opts, &jobAction{
+		pastTense:     "cancelled",
+		skippedReason: "were already finalized",
+		verb:          "cancel",
+
+		actOne: client.JobCancel,
+		actMany: func(ctx context.Context, params *river.JobListParams) (int, int, error) {
+			res, err := client.JobCancelMany(ctx, params, &river.JobManyOpts{CountOnly: true})
+			if err != nil {
+				return 0, 0, err
+			}
+			return res.NumCancelled, res.NumSkipped, nil
+		},
+	})
 }
 
-type migrateUp struct {
+type jobDelete struct {
 	CommandBase
 }
 
-func (c *migrateUp) Run(ctx context.Context, opts *migrateOpts) (bool, error) {
-	migrator, err := c.GetMigrator(&rivermigrate.Config{Line: opts.Line, Logger: c.Logger})
+func (c *jobDelete) Run(ctx context.Context, opts *jobActionOpts) (bool, error) {
+	client, err := c.GetClient()
 	if err != nil {
 		return false, err
 	}
 
-	res, err := migrator.Migrate(ctx, rivermigrate.DirectionUp, &rivermigrate.MigrateOpts{
-		DryRun:        opts.DryRun,
-		MaxSteps:      opts.MaxSteps,
-		TargetVersion: opts.TargetVersion,
+	return c.runJobAction(ctx, client, opts, &jobAction{
+		pastTense:     "deleted",
+		skippedReason: "were running",
+		verb:          "delete",
+
+		actOne: client.JobDelete,
+		actMany: func(ctx context.Context, params *river.JobListParams) (int, int, error) {
+			res, err := client.JobDeleteMany(ctx, params, &river.JobManyOpts{CountOnly: true})
+			if err != nil {
+				return 0, 0, err
+			}
+			return res.NumDeleted, res.NumSkipped, nil
+		},
 	})
+}
+
+type jobGetOpts struct {
+	DatabaseURL string
+	ID          int64
+	Output      string
+	Schema      string
+}
+
+func (o *jobGetOpts) Validate() error {
+	if o.DatabaseURL == "" && !pgEnvConfigured() {
+		return errors.New("either PG* env vars or --database-url must be set")
+	}
+
+	return validateOutput(o.Output)
+}
+
+type jobGet struct {
+	CommandBase
+}
+
+func (c *jobGet) Run(ctx context.Context, opts *jobGetOpts) (bool, error) {
+	client, err := c.GetClient()
 	if err != nil {
 		return false, err
 	}
 
-	migratePrintResult(c.Out, opts, res, rivermigrate.DirectionUp)
-
-	return true, nil
+	job, err := client.JobGet(ctx, opts.ID)
+	if err != nil {
+		if errors.Is(err, rivertype.ErrNotFound) {
+			fmt.Fprintf(c.Out, "no job with ID %d\n", opts.ID)
+			return false, nil
+		}
+		return false, err
+	}
+
+	if opts.Output == outputJSON {
+		if err := writeJSON(c.Out, jobJSONFromRow(job)); err != nil {
+			return false, err
+		}
+		return true, nil
+	}
+
+	tabWriter := tabwriter.NewWriter(c.Out, 0, 0, 2, ' ', 0)
+	fmt.Fprintf(tabWriter, "id:\t%d\n", job.ID)
+	fmt.Fprintf(tabWriter, "kind:\t%s\n", job.Kind)
+	fmt.Fprintf(tabWriter, "queue:\t%s\n", job.Queue)
+	fmt.Fprintf(tabWriter, "state:\t%s\n", job.State)
+	fmt.Fprintf(tabWriter, "priority:\t%d\n", job.Priority)
+	fmt.Fprintf(tabWriter, "attempt:\t%d/%d\n", job.Attempt, job.MaxAttempts)
+	fmt.Fprintf(tabWriter, "attempted by:\t%s\n", strings.Join(job.AttemptedBy, ","))
+	fmt.Fprintf(tabWriter, "tags:\t%s\n", strings.Join(job.Tags, ","))
+	fmt.Fprintf(tabWriter, "created at:\t%s\n", formatOptionalTime(&job.CreatedAt))
+	fmt.Fprintf(tabWriter, "scheduled at:\t%s\n", formatOptionalTime(&job.ScheduledAt))
+	fmt.Fprintf(tabWriter, "attempted at:\t%s\n", formatOptionalTime(job.AttemptedAt))
+	fmt.Fprintf(tabWriter, "finalized at:\t%s\n", formatOptionalTime(job.FinalizedAt))
+	fmt.Fprintf(tabWriter, "args:\t%s\n", job.EncodedArgs)
+	fmt.Fprintf(tabWriter, "metadata:\t%s\n", job.Metadata)
+	if err := tabWriter.Flush(); err != nil {
+		return false, err
+	}
+
+	fmt.Fprintf(c.Out, "errors:\n")
+	for _, attemptErr := range job.Errors {
+		fmt.Fprintf(c.Out, "  attempt %d at %s: %s\n", attemptErr.Attempt, formatOptionalTime(&attemptErr.At), attemptErr.Error)
+	}
+
+	return true, nil
+}
+
+type jobListOpts struct {
+	jobFilterOpts
+
+	After       string
+	DatabaseURL string
+	Limit       int
+	Output      string
+	Schema      string
+}
+
+func (o *jobListOpts) Validate() error {
+	if o.DatabaseURL == "" && !pgEnvConfigured() {
+		return errors.New("either PG* env vars or --database-url must be set")
+	}
+
+	if o.Limit < 1 || o.Limit > 10_000 {
+		return errors.New("--limit must be between 1 and 10000")
+	}
+
+	if err := validateOutput(o.Output); err != nil {
+		return err
+	}
+
+	if _, err := o.jobListParams(time.Now()); err != nil {
+		return err
+	}
+
+	return nil
+}
+
+// jobListJSON is the representation of a page of jobs in JSON output.
+type jobListJSON struct {
+	Jobs []*jobJSON `json:"jobs"`
+
+	// NextCursor is a cursor that can be passed to --after to list the next
+	// page. Only set if the page was full.
+	NextCursor string `json:"next_cursor,omitempty"`
+}
+
+type jobList struct {
+	CommandBase
+}
+
+func (c *jobList) Run(ctx context.Context, opts *jobListOpts) (bool, error) {
+	client, err := c.GetClient()
+	if err != nil {
+		return false, err
+	}
+
+	params, err := opts.jobListParams(time.Now())
+	if err != nil {
+		return false, err
+	}
+	params = params.First(opts.Limit)
+
+	if opts.After != "" {
+		var cursor river.JobListCursor
+		if err := cursor.UnmarshalText([]byte(opts.After)); err != nil {
+			return false, fmt.Errorf("error decoding --after cursor: %w", err)
+		}
+		params = params.After(&cursor)
+	}
+
+	res, err := client.JobList(ctx, params)
+	if err != nil {
+		return false, err
+	}
+
+	// Only offer a cursor for the next page if this one was full. A page with
+	// fewer jobs than the limit is known to be the last one.
+	var nextCursor string
+	if len(res.Jobs) >= opts.Limit && res.LastCursor != nil {
+		nextCursorBytes, err := res.LastCursor.MarshalText()
+		if err != nil {
+			return false, err
+		}
+		nextCursor = string(nextCursorBytes)
+	}
+
+	if opts.Output == outputJSON {
+		if err := writeJSON(c.Out, &jobListJSON{
+			Jobs:       sliceutil.Map(res.Jobs, jobJSONFromRow),
+			NextCursor: nextCursor,
+		}); err != nil {
+			return false, err
+		}
+		return true, nil
+	}
+
+	if len(res.Jobs) < 1 {
+		fmt.Fprintf(c.Out, "no jobs found\n")
+		return true, nil
+	}
+
+	tabWriter := tabwriter.NewWriter(c.Out, 0, 0, 2, ' ', 0)
+	fmt.Fprintf(tabWriter, "ID\tKIND\tQUEUE\tSTATE\tATTEMPT\tSCHEDULED AT\tLAST ERROR\n")
+	for _, job := range res.Jobs {
+		var lastError string
+		if len(job.Errors) > 0 {
+			lastError = job.Errors[len(job.Errors)-1].Error
+		}
+
+		fmt.Fprintf(tabWriter, "%d\t%s\t%s\t%s\t%d/%d\t%s\t%s\n",
+			job.ID,
+			job.Kind,
+			job.Queue,
+			job.State,
+			job.Attempt,
+			job.MaxAttempts,
+			job.ScheduledAt.UTC().Format(time.RFC3339),
+			truncateString(firstLine(lastError), 80),
+		)
+	}
+	if err := tabWriter.Flush(); err != nil {
+		return false, err
+	}
+
+	if nextCursor != "" {
+		fmt.Fprintf(c.Out, "\nmore jobs may be available; list the next page with --after %s\n", nextCursor)
+	}
+
+	return true, nil
+}
+
+type jobRetry struct {
+	CommandBase
+}
+
+func (c *jobRetry) Run(ctx context.Context, opts *jobActionOpts) (bool, error) {
+	client, err := c.GetClient()
+	if err != nil {
+		return false, err
+	}
+
+	return c.runJobAction(ctx, client, opts, &jobAction{
+		pastTense:     "retried",
+		skippedReason: "were running or already available",
+		verb:          "retry",
+
+		actOne: client.JobRetry,
+		actMany: func(ctx context.Context, params *river.JobListParams) (int, int, error) {
+			res, err := client.JobRetryMany(ctx, params, &river.JobManyOpts{CountOnly: true})
+			if err != nil {
+				return 0, 0, err
+			}
+			return res.NumRetried, res.NumSkipped, nil
+		},
+	})
+}
+
+type migrateOpts struct {
+	DatabaseURL   string
+	DryRun        bool
+	Line          string
+	ShowSQL       bool
+	MaxSteps      int
+	Schema        string
+	TargetVersion int
+}
+
+func (o *migrateOpts) Validate() error {
+	if o.DatabaseURL == "" && !pgEnvConfigured() {
+		return errors.New("either PG* env vars or --database-url must be set")
+	}
+
+	return nil
+}
+
+type migrateDown struct {
+	CommandBase
+}
+
+func (c *migrateDown) Run(ctx context.Context, opts *migrateOpts) (bool, error) {
+	migrator, err := c.GetMigrator(&rivermigrate.Config{Line: opts.Line, Logger: c.Logger})
+	if err != nil {
+		return false, err
+	}
+
+	res, err := migrator.Migrate(ctx, rivermigrate.DirectionDown, &rivermigrate.MigrateOpts{
+		DryRun:        opts.DryRun,
+		MaxSteps:      opts.MaxSteps,
+		TargetVersion: opts.TargetVersion,
+	})
+	if err != nil {
+		return false, err
+	}
+
+	migratePrintResult(c.Out, opts, res, rivermigrate.DirectionDown)
+
+	return true, nil
+}
+
+// Rounds a duration so that it doesn't show so much cluttered and not useful
+// precision in printf output.
+func roundDuration(duration time.Duration) time.Duration {
+	switch {
+	case duration > 1*time.Second:
+		return duration.Truncate(10 * time.Millisecond)
+	case duration < 1*time.Millisecond:
+		return duration.Truncate(10 * time.Nanosecond)
+	default:
+		return duration.Truncate(10 * time.Microsecond)
+	}
+}
+
+func migratePrintResult(out io.Writer, opts *migrateOpts, res *rivermigrate.MigrateResult, direction rivermigrate.Direction) {
+	if len(res.Versions) < 1 {
+		fmt.Fprintf(out, "no migrations to apply\n")
+		return
+	}
+
+	versionWithLongestName := slices.MaxFunc(res.Versions,
+		func(v1, v2 rivermigrate.MigrateVersion) int { return len(v1.Name) - len(v2.Name) })
+
+	for _, migrateVersion := range res.Versions {
+		if opts.DryRun {
+			fmt.Fprintf(out, "migration %03d [%s] [DRY RUN]\n", migrateVersion.Version, direction)
+		} else {
+			fmt.Fprintf(out, "applied migration %03d [%s] %-*s [%s]\n", migrateVersion.Version, direction, len(versionWithLongestName.Name), migrateVersion.Name, roundDuration(migrateVersion.Duration))
+		}
+
+		if opts.ShowSQL {
+			fmt.Fprintf(out, "%s\n", strings.Repeat("-", 80))
+			fmt.Fprintf(out, "%s\n", migrationComment(opts.Line, migrateVersion.Version, direction))
+			fmt.Fprintf(out, "%s\n\n", strings.TrimSpace(migrateVersion.SQL))
+		}
+	}
+
+	// Only prints if more steps than available were requested.
+	if opts.MaxSteps > 0 && len(res.Versions) < opts.MaxSteps {
+		fmt.Fprintf(out, "no more migrations to apply\n")
+	}
+}
+
+// An informational comment that's tagged on top of any migration's SQL to help
+// attribute what it is for when it's copied elsewhere like other migration
+// frameworks.
+func migrationComment(line string, version int, direction rivermigrate.Direction) string {
+	return fmt.Sprintf("-- River %s migration %03d [%s]", line, version, direction)
+}
+
+type migrateGetOpts struct {
+	All            bool
+	Down           bool
+	ExcludeVersion []int
+	Line           string
+	Up             bool
+	Version        []int
+}
+
+func (o *migrateGetOpts) Validate() error { return nil }
+
+type migrateGet struct {
+	CommandBase
+}
+
+func (c *migrateGet) Run(_ context.Context, opts *migrateGetOpts) (bool, error) {
+	// We'll need to have a way of using an alternate driver if support for
+	// other databases is added in the future. Unlike other migrate commands,
+	// this one doesn't take a `--database-url`, so we'd need a way of
+	// detecting the database type.
+	migrator, err := rivermigrate.New(c.DriverProcurer.ProcurePgxV5(nil), &rivermigrate.Config{Line: opts.Line, Logger: c.Logger})
+	if err != nil {
+		return false, err
+	}
+
+	var migrations []rivermigrate.Migration
+	if opts.All {
+		migrations = migrator.AllVersions()
+		if opts.Down {
+			slices.Reverse(migrations)
+		}
+	} else {
+		for _, version := range opts.Version {
+			migration, err := migrator.GetVersion(version)
+			if err != nil {
+				return false, err
+			}
+
+			migrations = append(migrations, migration)
+		}
+	}
+
+	var printedOne bool
+
+	for _, migration := range migrations {
+		if slices.Contains(opts.ExcludeVersion, migration.Version) {
+			continue
+		}
+
+		// print newlines between multiple versions
+		if printedOne {
+			fmt.Fprintf(c.Out, "\n")
+		}
+
+		var (
+			direction rivermigrate.Direction
+			sql       string
+		)
+		switch {
+		case opts.Down:
+			direction = rivermigrate.DirectionDown
+			sql = migration.SQLDown
+		case opts.Up:
+			direction = rivermigrate.DirectionUp
+			sql = migration.SQLUp
+		}
+
+		printedOne = true
+		fmt.Fprintf(c.Out, "%s\n", migrationComment(opts.Line, migration.Version, direction))
+		fmt.Fprintf(c.Out, "%s\n", strings.TrimSpace(sql))
+	}
+
+	return true, nil
+}
+
+type migrateListOpts struct {
+	DatabaseURL string
+	Line        string
+	Schema      string
+}
+
+func (o *migrateListOpts) Validate() error { return nil }
+
+type migrateList struct {
+	CommandBase
+}
+
+func (c *migrateList) Run(ctx context.Context, opts *migrateListOpts) (bool, error) {
+	migrator, err := c.GetMigrator(&rivermigrate.Config{Line: opts.Line, Logger: c.Logger})
+	if err != nil {
+		return false, err
+	}
+
+	allMigrations := migrator.AllVersions()
+
+	existingMigrations, err := migrator.ExistingVersions(ctx)
+	if err != nil {
+		return false, err
+	}
+
+	var maxExistingVersion int
+	if len(existingMigrations) > 0 {
+		maxExistingVersion = existingMigrations[len(existingMigrations)-1].Version
+	}
+
+	for _, migration := range allMigrations {
+		var currentVersionPrefix string
+		switch {
+		case migration.Version == maxExistingVersion:
+			currentVersionPrefix = "* "
+		case maxExistingVersion > 0:
+			currentVersionPrefix = "  "
+		}
+
+		fmt.Fprintf(c.Out, "%s%03d %s\n", currentVersionPrefix, migration.Version, migration.Name)
+	}
+
+	return true, nil
+}
+
+type migrateUp struct {
+	CommandBase
+}
+
+func (c *migrateUp) Run(ctx context.Context, opts *migrateOpts) (bool, error) {
+	migrator, err := c.GetMigrator(&rivermigrate.Config{Line: opts.Line, Logger: c.Logger})
+	if err != nil {
+		return false, err
+	}
+
+	res, err := migrator.Migrate(ctx, rivermigrate.DirectionUp, &rivermigrate.MigrateOpts{
+		DryRun:        opts.DryRun,
+		MaxSteps:      opts.MaxSteps,
+		TargetVersion: opts.TargetVersion,
+	})
+	if err != nil {
+		return false, err
+	}
+
+	migratePrintResult(c.Out, opts, res, rivermigrate.DirectionUp)
+
+	return true, nil
+}
+
+// queueJSON is the representation of a queue in JSON output.
+type queueJSON struct {
+	Name      string          `json:"name"`
+	CreatedAt time.Time       `json:"created_at"`
+	Metadata  json.RawMessage `json:"metadata"`
+	PausedAt  *time.Time      `json:"paused_at"`
+	UpdatedAt time.Time       `json:"updated_at"`
+}
+
+func queueJSONFromQueue(queue *rivertype.Queue) *queueJSON {
+	return &queueJSON{
+		Name:      queue.Name,
+		CreatedAt: queue.CreatedAt,
+		Metadata:  rawJSONOrNull(queue.Metadata),
+		PausedAt:  queue.PausedAt,
+		UpdatedAt: queue.UpdatedAt,
+	}
+}
+
+// Writes a single queue in the given output format.
+func writeQueue(out io.Writer, output string, queue *rivertype.Queue) error {
+	if output == outputJSON {
+		return writeJSON(out, queueJSONFromQueue(queue))
+	}
+
+	tabWriter := tabwriter.NewWriter(out, 0, 0, 2, ' ', 0)
+	fmt.Fprintf(tabWriter, "name:\t%s\n", queue.Name)
+	fmt.Fprintf(tabWriter, "paused at:\t%s\n", formatOptionalTime(queue.PausedAt))
+	fmt.Fprintf(tabWriter, "created at:\t%s\n", formatOptionalTime(&queue.CreatedAt))
+	fmt.Fprintf(tabWriter, "updated at:\t%s\n", formatOptionalTime(&queue.UpdatedAt))
+	fmt.Fprintf(tabWriter, "metadata:\t%s\n", queue.Metadata)
+	return tabWriter.Flush()
+}
+
+type queueGetOpts struct {
+	DatabaseURL string
+	Name        string
+	Output      string
+	Schema      string
+}
+
+func (o *queueGetOpts) Validate() error {
+	if o.DatabaseURL == "" && !pgEnvConfigured() {
+		return errors.New("either PG* env vars or --database-url must be set")
+	}
+
+	return validateOutput(o.Output)
+}
+
+type queueGet struct {
+	CommandBase
+}
+
+func (c *queueGet) Run(ctx context.Context, opts *queueGetOpts) (bool, error) {
+	client, err := c.GetClient()
+	if err != nil {
+		return false, err
+	}
+
+	queue, err := client.QueueGet(ctx, opts.Name)
+	if err != nil {
+		if errors.Is(err, rivertype.ErrNotFound) {
+			fmt.Fprintf(c.Out, "no queue with name %s\n", opts.Name)
+			return false, nil
+		}
+		return false, err
+	}
+
+	if err := writeQueue(c.Out, opts.Output, queue); err != nil {
+		return false, err
+	}
+
+	return true, nil
+}
+
+type queueListOpts struct {
+	DatabaseURL string
+	Limit       int
+	Output      string
+	Schema      string
+}
+
+func (o *queueListOpts) Validate() error {
+	if o.DatabaseURL == "" && !pgEnvConfigured() {
+		return errors.New("either PG* env vars or --database-url must be set")
+	}
+
+	if o.Limit < 1 || o.Limit > 10_000 {
+		return errors.New("--limit must be between 1 and 10000")
+	}
+
+	return validateOutput(o.Output)
+}
+
+// queueListJSON is the representation of a list of queues in JSON output.
+type queueListJSON struct {
+	Queues []*queueJSON `json:"queues"`
+}
+
+type queueList struct {
+	CommandBase
+}
+
+func (c *queueList) Run(ctx context.Context, opts *queueListOpts) (bool, error) {
+	client, err := c.GetClient()
+	if err != nil {
+		return false, err
+	}
+
+	res, err := client.QueueList(ctx, river.NewQueueListParams().First(opts.Limit))
+	if err != nil {
+		return false, err
+	}
+
+	if opts.Output == outputJSON {
+		if err := writeJSON(c.Out, &queueListJSON{Queues: sliceutil.Map(res.Queues, queueJSONFromQueue)}); err != nil {
+			return false, err
+		}
+		return true, nil
+	}
+
+	if len(res.Queues) < 1 {
+		fmt.Fprintf(c.Out, "no queues found\n")
+		return true, nil
+	}
+
+	tabWriter := tabwriter.NewWriter(c.Out, 0, 0, 2, ' ', 0)
+	fmt.Fprintf(tabWriter, "NAME\tPAUSED AT\tCREATED AT\tUPDATED AT\n")
+	for _, queue := range res.Queues {
+		fmt.Fprintf(tabWriter, "%s\t%s\t%s\t%s\n",
+			queue.Name,
+			formatOptionalTime(queue.PausedAt),
+			formatOptionalTime(&queue.CreatedAt),
+			formatOptionalTime(&queue.UpdatedAt),
+		)
+	}
+	if err := tabWriter.Flush(); err != nil {
+		return false, err
+	}
+
+	return true, nil
+}
+
+// queuePauseOpts are options shared by the queue pause and resume commands.
+type queuePauseOpts struct {
+	All         bool
+	DatabaseURL string
+	Name        []string
+	Schema      string
+}
+
+func (o *queuePauseOpts) Validate() error {
+	if o.DatabaseURL == "" && !pgEnvConfigured() {
+		return errors.New("either PG* env vars or --database-url must be set")
+	}
+
+	if !o.All && len(o.Name) < 1 {
+		return errors.New("either --all or at least one --name must be set")
+	}
+
+	return nil
+}
+
+// Pauses or resumes the queues selected by opts with the given client
+// function, printing a line of output for each.
+func (c *CommandBase) runQueuePauseOrResume(ctx context.Context, opts *queuePauseOpts, pastTense string, pauseOrResume func(ctx context.Context, name string, opts *river.QueuePauseOpts) error) (bool, error) {
+	names := opts.Name
+	if opts.All {
+		names = []string{riverdriver.AllQueuesString}
+	}
+
+	ok := true
+	for _, name := range names {
+		if err := pauseOrResume(ctx, name, &river.QueuePauseOpts{}); err != nil {
+			if errors.Is(err, rivertype.ErrNotFound) {
+				fmt.Fprintf(c.Out, "no queue with name %s\n", name)
+				ok = false
+				continue
+			}
+			return false, err
+		}
+
+		if name == riverdriver.AllQueuesString {
+			fmt.Fprintf(c.Out, "%s all queues\n", pastTense)
+		} else {
+			fmt.Fprintf(c.Out, "%s queue %s\n", pastTense, name)
+		}
+	}
+
+	return ok, nil
+}
+
+type queuePause struct {
+	CommandBase
+}
+
+func (c *queuePause) Run(ctx context.Context, opts *queuePauseOpts) (bool, error) {
+	client, err := c.GetClient()
+	if err != nil {
+		return false, err
+	}
+
+	return c.runQueuePauseOrResume(ctx, opts, "paused", client.QueuePause)
+}
+
+type queueResume struct {
+	CommandBase
+}
+
+func (c *queueResume) Run(ctx context.Context, opts *queuePauseOpts) (bool, error) {
+	client, err := c.GetClient()
+	if err != nil {
+		return false, err
+	}
+
+	return c.runQueuePauseOrResume(ctx, opts, "resumed", client.QueueResume)
+}
+
+type queueUpdateOpts struct {
+	ConcurrencyGlobalLimit     int
+	ConcurrencyPartitionByArgs bool
+	ConcurrencyPartitionByKind bool
+	ConcurrencyPartitionLimit  int
+	ConcurrencyReset           bool
+	DatabaseURL                string
+	Metadata                   string
+	Name                       string
+	Output                     string
+	Schema                     string
+}
+
+// Returns true if any flag setting a concurrency override is set.
+func (o *queueUpdateOpts) hasConcurrency() bool {
+	return o.ConcurrencyGlobalLimit != 0 ||
+		o.ConcurrencyPartitionByArgs ||
+		o.ConcurrencyPartitionByKind ||
+		o.ConcurrencyPartitionLimit != 0
+}
+
+func (o *queueUpdateOpts) Validate() error {
+	if o.DatabaseURL == "" && !pgEnvConfigured() {
+		return errors.New("either PG* env vars or --database-url must be set")
+	}
+
+	if err := validateOutput(o.Output); err != nil {
+		return err
+	}
+
+	if o.Metadata != "" {
+		var metadataMap map[string]json.RawMessage
+		if err := json.Unmarshal([]byte(o.Metadata), &metadataMap); err != nil {
+			return errors.New("--metadata must be a JSON object")
+		}
+	}
+
+	if o.ConcurrencyReset && o.hasConcurrency() {
+		return errors.New("--concurrency-reset can't be combined with other concurrency flags")
+	}
+
+	if o.Metadata == "" && !o.ConcurrencyReset && !o.hasConcurrency() {
+		return errors.New("at least one of --metadata, --concurrency-reset, or a concurrency flag like --concurrency-global-limit must be set")
+	}
+
+	return nil
+}
+
+type queueUpdate struct {
+	CommandBase
+}
+
+func (c *queueUpdate) Run(ctx context.Context, opts *queueUpdateOpts) (bool, error) {
+	client, err := c.GetClient()
+	if err != nil {
+		return false, err
+	}
+
+	params := &river.QueueUpdateParams{}
+	if opts.Metadata != "" {
+		params.Metadata = []byte(opts.Metadata)
+	}
+
+	switch {
+	case opts.ConcurrencyReset:
+		params.Concurrency = &river.ConcurrencyConfig{}
+	case opts.hasConcurrency():
+		params.Concurrency = &river.ConcurrencyConfig{
+			GlobalLimit: opts.ConcurrencyGlobalLimit,
+			Partition: river.PartitionConfig{
+				ByArgs: opts.ConcurrencyPartitionByArgs,
+				ByKind: opts.ConcurrencyPartitionByKind,
+				Limit:  opts.ConcurrencyPartitionLimit,
+			},
+		}
+	}
+
+	queue, err := client.QueueUpdate(ctx, opts.Name, params)
+	if err != nil {
+		if errors.Is(err, rivertype.ErrNotFound) {
+			fmt.Fprintf(c.Out, "no queue with name %s\n", opts.Name)
+			return false, nil
+		}
+		return false, err
+	}
+
+	if err := writeQueue(c.Out, opts.Output, queue); err != nil {
+		return false, err
+	}
+
+	return true, nil
+}
+
+type topOpts struct {
+	DatabaseURL string
+	Interval    time.Duration
+	Iterations  int
+	Schema      string
+}
+
+func (o *topOpts) Validate() error {
+	if o.DatabaseURL == "" && !pgEnvConfigured() {
+		return errors.New("either PG* env vars or --database-url must be set")
+	}
+
+	if o.Interval < time.Second {
+		return errors.New("--interval must be at least 1s")
+	}
+
+	if o.Iterations < 0 {
+		return errors.New("--iterations must be greater than or equal to zero")
+	}
+
+	return nil
+}
+
+// The maximum number of queues shown by top. Statistics for every queue shown
+// are fetched on every refresh, so this also bounds the work done per refresh.
+const topMaxQueues = 100
+
+// topQueue is a queue along with its statistics as shown by top.
+type topQueue struct {
+	Queue *rivertype.Queue
+	Stats *river.QueueStats
+}
+
+// topSnapshot is the information shown by a single refresh of top.
+type topSnapshot struct {
+	Leader  *riverdriver.Leader // nil if no leader is elected
+	Queues  []*topQueue
+	TakenAt time.Time
+}
+
+// Fetches a snapshot of the elected leader, and queues along with their
+// statistics.
+func fetchTopSnapshot(ctx context.Context, client ClientInterface, executor ExecutorInterface, schema string, now time.Time) (*topSnapshot, error) {
+	leader, err := executor.LeaderGetElectedLeader(ctx, &riverdriver.LeaderGetElectedLeaderParams{Schema: schema})
+	if err != nil {
+		if !errors.Is(err, rivertype.ErrNotFound) {
+			return nil, fmt.Errorf("error getting elected leader: %w", err)
+		}
+		leader = nil
+	}
+
+	res, err := client.QueueList(ctx, river.NewQueueListParams().First(topMaxQueues))
+	if err != nil {
+		return nil, fmt.Errorf("error listing queues: %w", err)
+	}
+
+	snapshot := &topSnapshot{
+		Leader:  leader,
+		Queues:  make([]*topQueue, 0, len(res.Queues)),
+		TakenAt: now,
+	}
+
+	// Statistics for all queues are fetched with a single query rather than
+	// one per queue.
+	stats, err := client.QueueStatsMany(ctx, sliceutil.Map(res.Queues, func(queue *rivertype.Queue) string { return queue.Name }))
+	if err != nil {
+		return nil, fmt.Errorf("error getting queue stats: %w", err)
+	}
+
+	statsByQueue := make(map[string]*river.QueueStats, len(stats))
+	for _, queueStats := range stats {
+		statsByQueue[queueStats.Queue] = queueStats
+	}
+
+	for _, queue := range res.Queues {
+		snapshot.Queues = append(snapshot.Queues, &topQueue{
+			Queue: queue,
+			Stats: statsByQueue[queue.Name],
+		})
+	}
+
+	return snapshot, nil
+}
+
+// topKey is a keyboard shortcut recognized by top.
+type topKey int
+
+const (
+	topKeyDown topKey = iota
+	topKeyPause
+	topKeyQuit
+	topKeyResume
+	topKeyUp
+)
+
+// Parses keyboard shortcuts from input read from a terminal in raw mode.
+// Unrecognized input is ignored.
+func parseTopKeys(data []byte) []topKey {
+	var keys []topKey
+	for i := 0; i < len(data); i++ {
+		switch data[i] {
+		case 'j':
+			keys = append(keys, topKeyDown)
+		case 'k':
+			keys = append(keys, topKeyUp)
+		case 'p':
+			keys = append(keys, topKeyPause)
+		case 'q', 0x03: // 0x03 is Ctrl+C, which isn't a signal in raw mode
+			keys = append(keys, topKeyQuit)
+		case 'r':
+			keys = append(keys, topKeyResume)
+		case 0x1b: // arrow keys are sent as escape sequences like ESC [ A
+			if i+2 < len(data) && data[i+1] == '[' {
+				switch data[i+2] {
+				case 'A':
+					keys = append(keys, topKeyUp)
+				case 'B':
+					keys = append(keys, topKeyDown)
+				}
+				i += 2
+			}
+		}
+	}
+	return keys
+}
+
+// Reads keyboard shortcuts from the given reader and sends them on keyChan
+// until the reader returns an error or the context is cancelled.
+func readTopKeys(ctx context.Context, in io.Reader, keyChan chan<- topKey) {
+	buf := make([]byte, 64)
+	for {
+		n, err := in.Read(buf)
+		for _, key := range parseTopKeys(buf[:n]) {
+			select {
+			case <-ctx.Done():
+				return
+			case keyChan <- key:
+			}
+		}
+		if err != nil {
+			return
+		}
+	}
+}
+
+// topState is the state of top carried between refreshes.
+type topState struct {
+	interactive bool
+	message     string // outcome of the last keyboard shortcut
+	refreshErr  error  // error from the last refresh, if it failed
+	selected    string // name of the selected queue in interactive mode
+	snapshot    *topSnapshot
+}
+
+// Sets a new snapshot, keeping the selected queue if it's still present, and
+// selecting the first queue otherwise.
+func (s *topState) setSnapshot(snapshot *topSnapshot) {
+	s.snapshot = snapshot
+
+	if slices.ContainsFunc(snapshot.Queues, func(q *topQueue) bool { return q.Queue.Name == s.selected }) {
+		return
+	}
+
+	s.selected = ""
+	if len(snapshot.Queues) > 0 {
+		s.selected = snapshot.Queues[0].Queue.Name
+	}
+}
+
+// Moves the selection up or down by the given number of queues, stopping at
+// the first and last queue.
+func (s *topState) moveSelection(delta int) {
+	index := slices.IndexFunc(s.snapshot.Queues, func(q *topQueue) bool { return q.Queue.Name == s.selected })
+	if index == -1 {
+		return
+	}
+
+	index = max(0, min(len(s.snapshot.Queues)-1, index+delta))
+	s.selected = s.snapshot.Queues[index].Queue.Name
+}
+
+// Renders the current snapshot of top.
+func renderTop(out io.Writer, state *topState, interval time.Duration) error {
+	snapshot := state.snapshot
+
+	fmt.Fprintf(out, "river top - %s - refreshing every %s\n", snapshot.TakenAt.UTC().Format(time.RFC3339), interval)
+	if snapshot.Leader == nil {
+		fmt.Fprintf(out, "leader: none elected\n")
+	} else {
+		fmt.Fprintf(out, "leader: %s (elected at %s, expires at %s)\n",
+			snapshot.Leader.LeaderID,
+			snapshot.Leader.ElectedAt.UTC().Format(time.RFC3339),
+			snapshot.Leader.ExpiresAt.UTC().Format(time.RFC3339),
+		)
+	}
+	fmt.Fprintf(out, "\n")
+
+	if len(snapshot.Queues) < 1 {
+		fmt.Fprintf(out, "no queues found\n")
+	} else {
+		// Prefixes a queue name with a marker if it's selected. Only done in
+		// interactive mode, where there's a selection at all.
+		selectionPrefix := func(name string) string {
+			switch {
+			case !state.interactive:
+				return ""
+			case name == state.selected:
+				return "> "
+			default:
+				return "  "
+			}
+		}
+
+		tabWriter := tabwriter.NewWriter(out, 0, 0, 2, ' ', 0)
+		fmt.Fprintf(tabWriter, "%sQUEUE\tSTATUS\tAVAILABLE\tRUNNING\tSCHEDULED\tRETRYABLE\tOLDEST AVAILABLE\tCOMPLETED/S\tDISCARDED\tERROR RATE\n", selectionPrefix(""))
+		for _, queue := range snapshot.Queues {
+			status := "active"
+			if queue.Queue.PausedAt != nil {
+				status = "paused"
+			}
+
+			oldestAvailable := "-"
+			if queue.Stats.OldestAvailableAge > 0 {
+				oldestAvailable = queue.Stats.OldestAvailableAge.Round(time.Second).String()
+			}
+
+			errorRate := "-"
+			if numFinalized := queue.Stats.CompletedCount + queue.Stats.DiscardedCount; numFinalized > 0 {
+				errorRate = fmt.Sprintf("%.1f%%", float64(queue.Stats.DiscardedCount)/float64(numFinalized)*100)
+			}
+
+			fmt.Fprintf(tabWriter, "%s%s\t%s\t%d\t%d\t%d\t%d\t%s\t%.2f\t%d\t%s\n",
+				selectionPrefix(queue.Queue.Name),
+				queue.Queue.Name,
+				status,
+				queue.Stats.CountsByState[rivertype.JobStateAvailable],
+				queue.Stats.CountsByState[rivertype.JobStateRunning],
+				queue.Stats.CountsByState[rivertype.JobStateScheduled],
+				queue.Stats.CountsByState[rivertype.JobStateRetryable],
+				oldestAvailable,
+				queue.Stats.CompletedPerSecond,
+				queue.Stats.DiscardedCount,
+				errorRate,
+			)
+		}
+		if err := tabWriter.Flush(); err != nil {
+			return err
+		}
+		fmt.Fprintf(out, "\ncompleted/s, discarded, and error rate are measured over the last %s\n", snapshot.Queues[0].Stats.ThroughputWindow)
+
+		type clientQueueRunning struct {
+			clientID   string
+			numRunning int
+			queue      string
+		}
+
+		var running []clientQueueRunning
+		for _, queue := range snapshot.Queues {
+			for clientID, numRunning := range queue.Stats.RunningByClient {
+				running = append(running, clientQueueRunning{clientID: clientID, numRunning: numRunning, queue: queue.Queue.Name})
+			}
+		}
+		slices.SortFunc(running, func(a, b clientQueueRunning) int {
+			return cmp.Or(cmp.Compare(a.clientID, b.clientID), cmp.Compare(a.queue, b.queue))
+		})
+
+		fmt.Fprintf(out, "\n")
+		if len(running) < 1 {
+			fmt.Fprintf(out, "no jobs running\n")
+		} else {
+			tabWriter := tabwriter.NewWriter(out, 0, 0, 2, ' ', 0)
+			fmt.Fprintf(tabWriter, "CLIENT\tQUEUE\tRUNNING\n")
+			for _, r := range running {
+				fmt.Fprintf(tabWriter, "%s\t%s\t%d\n", r.clientID, r.queue, r.numRunning)
+			}
+			if err := tabWriter.Flush(); err != nil {
+				return err
+			}
+		}
+	}
+
+	if state.refreshErr != nil {
+		fmt.Fprintf(out, "\nerror refreshing, showing previous results: %s\n", state.refreshErr)
+	}
+
+	if state.interactive {
+		fmt.Fprintf(out, "\n")
+		if state.message != "" {
+			fmt.Fprintf(out, "%s\n", state.message)
+		}
+		fmt.Fprintf(out, "up/down or j/k: select queue  p: pause  r: resume  q: quit\n")
+	}
+
+	return nil
+}
+
+type top struct {
+	CommandBase
+}
+
+func (c *top) Run(ctx context.Context, opts *topOpts) (bool, error) {
+	client, err := c.GetClient()
+	if err != nil {
+		return false, err
+	}
+
+	executor, err := c.GetExecutor()
+	if err != nil {
+		return false, err
+	}
+
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	state := &topState{}
+
+	// Keyboard shortcuts are only available when input is a terminal. It's put
+	// in raw mode so that keys are read as they're pressed instead of a line
+	// at a time, and without being echoed.
+	keyChan := make(chan topKey)
+	if inFile, ok := c.In.(*os.File); ok && term.IsTerminal(int(inFile.Fd())) {
+		oldState, err := term.MakeRaw(int(inFile.Fd()))
+		if err != nil {
+			return false, fmt.Errorf("error putting terminal in raw mode: %w", err)
+		}
+		defer func() { _ = term.Restore(int(inFile.Fd()), oldState) }()
+
+		go readTopKeys(ctx, inFile, keyChan)
+		state.interactive = true
+	}
+
+	// When output is a terminal, each refresh replaces the last one in place.
+	// Otherwise, refreshes are printed one after another.
+	outFile, ok := c.Out.(*os.File)
+	outIsTerminal := ok && term.IsTerminal(int(outFile.Fd()))
+
+	ticker := time.NewTicker(opts.Interval)
+	defer ticker.Stop()
+
+	var (
+		numRefreshes int
+		refresh      = true
+	)
+	for {
+		if refresh {
+			snapshot, err := fetchTopSnapshot(ctx, client, executor, c.Schema, time.Now())
+			switch {
+			case err != nil && state.snapshot == nil:
+				return false, err
+			case err != nil:
+				state.refreshErr = err
+			default:
+				state.refreshErr = nil
+				state.setSnapshot(snapshot)
+			}
+			numRefreshes++
+		}
+
+		var buf bytes.Buffer
+		switch {
+		case outIsTerminal:
+			buf.WriteString("\x1b[H\x1b[2J") // move cursor home and clear screen
+		case numRefreshes > 1:
+			buf.WriteString("\n")
+		}
+		if err := renderTop(&buf, state, opts.Interval); err != nil {
+			return false, err
+		}
+
+		frame := buf.String()
+		if state.interactive {
+			// Raw mode disables translation of newlines to carriage return
+			// and newline, so do it here instead.
+			frame = strings.ReplaceAll(frame, "\n", "\r\n")
+		}
+		if _, err := io.WriteString(c.Out, frame); err != nil {
+			return false, err
+		}
+
+		if opts.Iterations > 0 && numRefreshes >= opts.Iterations {
+			return true, nil
+		}
+
+		select {
+		case <-ctx.Done():
+			return true, nil
+
+		case key := <-keyChan:
+			if key == topKeyQuit {
+				return true, nil
+			}
+			refresh = c.handleTopKey(ctx, client, state, key)
+
+		case <-ticker.C:
+			refresh = true
+		}
+	}
+}
+
+// Handles a keyboard shortcut other than quit, returning true if top should
+// refresh immediately to show its effect.
+func (c *top) handleTopKey(ctx context.Context, client ClientInterface, state *topState, key topKey) bool {
+	switch key {
+	case topKeyDown:
+		state.moveSelection(1)
+
+	case topKeyUp:
+		state.moveSelection(-1)
+
+	case topKeyPause, topKeyResume:
+		if state.selected == "" {
+			return false
+		}
+
+		pauseOrResume, pastTense, verb := client.QueuePause, "paused", "pausing"
+		if key == topKeyResume {
+			pauseOrResume, pastTense, verb = client.QueueResume, "resumed", "resuming"
+		}
+
+		if err := pauseOrResume(ctx, state.selected, &river.QueuePauseOpts{}); err != nil {
+			state.message = fmt.Sprintf("error %s queue %s: %s", verb, state.selected, err)
+			return false
+		}
+
+		state.message = fmt.Sprintf("%s queue %s", pastTense, state.selected)
+		return true
+
+	case topKeyQuit:
+		// handled by the caller
+	}
+
+	return false
 }
 
 type validateOpts struct {
@@ -4,6 +4,8 @@ import (
 	"bytes"
 	"cmp"
 	"context"
+	"encoding/json"
+	"errors"
 	"fmt"
 	"net/url"
 	"os"
@@ -17,12 +19,202 @@ import (
 	"github.com/spf13/cobra"
 	"github.com/stretchr/testify/require"
 
+	"github.com/riverqueue/river"
 	"github.com/riverqueue/river/riverdriver"
 	"github.com/riverqueue/river/riverdriver/riverpgxv5"
 	"github.com/riverqueue/river/rivermigrate"
 	"github.com/riverqueue/river/rivershared/riversharedtest"
+	"github.com/riverqueue/river/rivershared/util/ptrutil"
+	"github.com/riverqueue/river/rivershared/util/sliceutil"
+	"github.com/riverqueue/river/rivertype"
 )
 
+type ClientStub struct {
+	deadLetterGetStub     func(ctx context.Context, id int64) (*rivertype.DeadLetter, error)
+	deadLetterListStub    func(ctx context.Context, params *river.DeadLetterListParams) (*river.DeadLetterListResult, error)
+	deadLetterPurgeStub   func(ctx context.Context, params *river.DeadLetterPurgeParams) (int, error)
+	deadLetterRequeueStub func(ctx context.Context, id int64) (*rivertype.JobInsertResult, error)
+	jobCancelStub         func(ctx context.Context, jobID int64) (*rivertype.JobRow, error)
+	jobCancelManyStub     func(ctx context.Context, params *river.JobListParams, opts *river.JobManyOpts) (*river.JobCancelManyResult, error)
+	jobCountStub          func(ctx context.Context, params *river.JobListParams) (int, error)
+	jobDeleteStub         func(ctx context.Context, id int64) (*rivertype.JobRow, error)
+	jobDeleteManyStub     func(ctx context.Context, params *river.JobListParams, opts *river.JobManyOpts) (*river.JobDeleteManyResult, error)
+	jobGetStub            func(ctx context.Context, id int64) (*rivertype.JobRow, error)
+	jobListStub           func(ctx context.Context, params *river.JobListParams) (*river.JobListResult, error)
+	jobRetryStub          func(ctx context.Context, id int64) (*rivertype.JobRow, error)
+	jobRetryManyStub      func(ctx context.Context, params *river.JobListParams, opts *river.JobManyOpts) (*river.JobRetryManyResult, error)
+	queueGetStub          func(ctx context.Context, name string) (*rivertype.Queue, error)
+	queueListStub         func(ctx context.Context, params *river.QueueListParams) (*river.QueueListResult, error)
+	queuePauseStub        func(ctx context.Context, name string, opts *river.QueuePauseOpts) error
+	queueResumeStub       func(ctx context.Context, name string, opts *river.QueuePauseOpts) error
+	queueStatsManyStub    func(ctx context.Context, names []string) ([]*river.QueueStats, error)
+	queueUpdateStub       func(ctx context.Context, name string, params *river.QueueUpdateParams) (*rivertype.Queue, error)
+}
+
+func (c *ClientStub) DeadLetterGet(ctx context.Context, id int64) (*rivertype.DeadLetter, error) {
+	if c.deadLetterGetStub == nil {
+		panic("DeadLetterGet is not stubbed")
+	}
+
+	return c.deadLetterGetStub(ctx, id)
+}
+
+func (c *ClientStub) DeadLetterList(ctx context.Context, params *river.DeadLetterListParams) (*river.DeadLetterListResult, error) {
+	if c.deadLetterListStub == nil {
+		panic("DeadLetterList is not stubbed")
+	}
+
+	return c.deadLetterListStub(ctx, params)
+}
+
+func (c *ClientStub) DeadLetterPurge(ctx context.Context, params *river.DeadLetterPurgeParams) (int, error) {
+	if c.deadLetterPurgeStub == nil {
+		panic("DeadLetterPurge is not stubbed")
+	}
+
+	return c.deadLetterPurgeStub(ctx, params)
+}
+
+func (c *ClientStub) DeadLetterRequeue(ctx context.Context, id int64) (*rivertype.JobInsertResult, error) {
+	if c.deadLetterRequeueStub == nil {
+		panic("DeadLetterRequeue is not stubbed")
+	}
+
+	return c.deadLetterRequeueStub(ctx, id)
+}
+
+func (c *ClientStub) JobCancel(ctx context.Context, jobID int64) (*rivertype.JobRow, error) {
+	if c.jobCancelStub == nil {
+		panic("JobCancel is not stubbed")
+	}
+
+	return c.jobCancelStub(ctx, jobID)
+}
+
+func (c *ClientStub) JobCancelMany(ctx context.Context, params *river.JobListParams, opts *river.JobManyOpts) (*river.JobCancelManyResult, error) {
+	if c.jobCancelManyStub == nil {
+		panic("JobCancelMany is not stubbed")
+	}
+
+	return c.jobCancelManyStub(ctx, params, opts)
+}
+
+func (c *ClientStub) JobCount(ctx context.Context, params *river.JobListParams) (int, error) {
+	if c.jobCountStub == nil {
+		panic("JobCount is not stubbed")
+	}
+
+	return c.jobCountStub(ctx, params)
+}
+
+func (c *ClientStub) JobDelete(ctx context.Context, id int64) (*rivertype.JobRow, error) {
+	if c.jobDeleteStub == nil {
+		panic("JobDelete is not stubbed")
+	}
+
+	return c.jobDeleteStub(ctx, id)
+}
+
+func (c *ClientStub) JobDeleteMany(ctx context.Context, params *river.JobListParams, opts *river.JobManyOpts) (*river.JobDeleteManyResult, error) {
+	if c.jobDeleteManyStub == nil {
+		panic("JobDeleteMany is not stubbed")
+	}
+
+	return c.jobDeleteManyStub(ctx, params, opts)
+}
+
+func (c *ClientStub) JobGet(ctx context.Context, id int64) (*rivertype.JobRow, error) {
+	if c.jobGetStub == nil {
+		panic("JobGet is not stubbed")
+	}
+
+	return c.jobGetStub(ctx, id)
+}
+
+func (c *ClientStub) JobList(ctx context.Context, params *river.JobListParams) (*river.JobListResult, error) {
+	if c.jobListStub == nil {
+		panic("JobList is not stubbed")
+	}
+
+	return c.jobListStub(ctx, params)
+}
+
+func (c *ClientStub) JobRetry(ctx context.Context, id int64) (*rivertype.JobRow, error) {
+	if c.jobRetryStub == nil {
+		panic("JobRetry is not stubbed")
+	}
+
+	return c.jobRetryStub(ctx, id)
+}
+
+func (c *ClientStub) JobRetryMany(ctx context.Context, params *river.JobListParams, opts *river.JobManyOpts) (*river.JobRetryManyResult, error) {
+	if c.jobRetryManyStub == nil {
+		panic("JobRetryMany is not stubbed")
+	}
+
+	return c.jobRetryManyStub(ctx, params, opts)
+}
+
+func (c *ClientStub) QueueGet(ctx context.Context, name string) (*rivertype.Queue, error) {
+	if c.queueGetStub == nil {
+		panic("QueueGet is not stubbed")
+	}
+
+	return c.queueGetStub(ctx, name)
+}
+
+func (c *ClientStub) QueueList(ctx context.Context, params *river.QueueListParams) (*river.QueueListResult, error) {
+	if c.queueListStub == nil {
+		panic("QueueList is not stubbed")
+	}
+
+	return c.queueListStub(ctx, params)
+}
+
+func (c *ClientStub) QueuePause(ctx context.Context, name string, opts *river.QueuePauseOpts) error {
+	if c.queuePauseStub == nil {
+		panic("QueuePause is not stubbed")
+	}
+
+	return c.queuePauseStub(ctx, name, opts)
+}
+
+func (c *ClientStub) QueueResume(ctx context.Context, name string, opts *river.QueuePauseOpts) error {
+	if c.queueResumeStub == nil {
+		panic("QueueResume is not stubbed")
+	}
+
+	return c.queueResumeStub(ctx, name, opts)
+}
+
+func (c *ClientStub) QueueStatsMany(ctx context.Context, names []string) ([]*river.QueueStats, error) {
+	if c.queueStatsManyStub == nil {
+		panic("QueueStatsMany is not stubbed")
+	}
+
+	return c.queueStatsManyStub(ctx, names)
+}
+
+func (c *ClientStub) QueueUpdate(ctx context.Context, name string, params *river.QueueUpdateParams) (*rivertype.Queue, error) {
+	if c.queueUpdateStub == nil {
+		panic("QueueUpdate is not stubbed")
+	}
+
+	return c.queueUpdateStub(ctx, name, params)
+}
+
+type ExecutorStub struct {
+	leaderGetElectedLeaderStub func(ctx context.Context, params *riverdriver.LeaderGetElectedLeaderParams) (*riverdriver.Leader, error)
+}
+
+func (e *ExecutorStub) LeaderGetElectedLeader(ctx context.Context, params *riverdriver.LeaderGetElectedLeaderParams) (*riverdriver.Leader, error) {
+	if e.leaderGetElectedLeaderStub == nil {
+		panic("LeaderGetElectedLeader is not stubbed")
+	}
+
+	return e.leaderGetElectedLeaderStub(ctx, params)
+}
+
 type MigratorStub struct {
 	allVersionsStub      func() []rivermigrate.Migration
 	existingVersionsStub func(ctx context.Context) ([]rivermigrate.Migration, error)
@@ -71,6 +263,54 @@ func (m *MigratorStub) Validate(ctx context.Context) (*rivermigrate.ValidateResu
 	return m.validateStub(ctx)
 }
 
+var testDeadLetter = &rivertype.DeadLetter{ //nolint:gochecknoglobals
+	ID:             123,
+	Attempt:        2,
+	CreatedAt:      time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC),
+	DeadLetteredAt: time.Date(2025, 1, 8, 0, 2, 0, 0, time.UTC),
+	EncodedArgs:    []byte(`{"to":"user@example.com"}`),
+	Errors: []rivertype.AttemptError{
+		{At: time.Date(2025, 1, 1, 0, 1, 0, 0, time.UTC), Attempt: 1, Error: "error 1"},
+		{At: time.Date(2025, 1, 1, 0, 2, 0, 0, time.UTC), Attempt: 2, Error: "error 2"},
+	},
+	FinalizedAt: ptrutil.Ptr(time.Date(2025, 1, 1, 0, 2, 0, 0, time.UTC)),
+	Kind:        "email_send",
+	MaxAttempts: 2,
+	Metadata:    []byte(`{}`),
+	Priority:    1,
+	Queue:       "default",
+	Tags:        []string{"tag1", "tag2"},
+}
+
+var testJob = &rivertype.JobRow{ //nolint:gochecknoglobals
+	ID:          123,
+	Attempt:     2,
+	AttemptedAt: ptrutil.Ptr(time.Date(2025, 1, 1, 0, 2, 0, 0, time.UTC)),
+	AttemptedBy: []string{"worker1"},
+	CreatedAt:   time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC),
+	EncodedArgs: []byte(`{"to":"user@example.com"}`),
+	Errors: []rivertype.AttemptError{
+		{At: time.Date(2025, 1, 1, 0, 1, 0, 0, time.UTC), Attempt: 1, Error: "error 1"},
+		{At: time.Date(2025, 1, 1, 0, 2, 0, 0, time.UTC), Attempt: 2, Error: "error 2"},
+	},
+	Kind:        "email_send",
+	MaxAttempts: 25,
+	Metadata:    []byte(`{}`),
+	Priority:    1,
+	Queue:       "default",
+	ScheduledAt: time.Date(2025, 1, 1, 0, 5, 0, 0, time.UTC),
+	State:       rivertype.JobStateRetryable,
+	Tags:        []string{"tag1", "tag2"},
+}
+
+var testQueue = &rivertype.Queue{ //nolint:gochecknoglobals
+	CreatedAt: time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC),
+	Metadata:  []byte(`{"owner":"billing"}`),
+	Name:      "default",
+	PausedAt:  ptrutil.Ptr(time.Date(2025, 1, 1, 0, 1, 0, 0, time.UTC)),
+	UpdatedAt: time.Date(2025, 1, 1, 0, 2, 0, 0, time.UTC),
+}
+
 var (
 	testMigration01 = rivermigrate.Migration{Name: "1st migration", SQLDown: "SELECT 1", SQLUp: "SELECT 1", Version: 1} //nolint:gochecknoglobals
 	testMigration02 = rivermigrate.Migration{Name: "2nd migration", SQLDown: "SELECT 1", SQLUp: "SELECT 1", Version: 2} //nolint:gochecknoglobals
@@ -223,66 +463,1462 @@ func TestBaseCommandSetNonParallel(t *testing.T) {
 	})
 }
 
-func TestMigrateList(t *testing.T) {
+func TestDeadLetterGet(t *testing.T) {
 	t.Parallel()
 
 	ctx := context.Background()
 
 	type testBundle struct {
-		migratorStub *MigratorStub
-		out          *bytes.Buffer
+		clientStub *ClientStub
+		out        *bytes.Buffer
 	}
 
-	setup := func(t *testing.T) (*migrateList, *testBundle) {
+	setup := func(t *testing.T) (*deadLetterGet, *testBundle) {
 		t.Helper()
 
-		cmd, out := withCommandBase(t, &migrateList{})
+		cmd, out := withCommandBase(t, &deadLetterGet{})
 
-		migratorStub := &MigratorStub{}
-		migratorStub.allVersionsStub = func() []rivermigrate.Migration { return testMigrationAll }
-		migratorStub.existingVersionsStub = func(ctx context.Context) ([]rivermigrate.Migration, error) { return nil, nil }
+		clientStub := &ClientStub{}
+		cmd.GetCommandBase().GetClient = func() (ClientInterface, error) { return clientStub, nil }
 
-		cmd.GetCommandBase().GetMigrator = func(config *rivermigrate.Config) (MigratorInterface, error) { return migratorStub, nil }
+		return cmd, &testBundle{
+			clientStub: clientStub,
+			out:        out,
+		}
+	}
+
+	t.Run("PrintsDeadLetter", func(t *testing.T) {
+		t.Parallel()
+
+		cmd, bundle := setup(t)
+
+		bundle.clientStub.deadLetterGetStub = func(ctx context.Context, id int64) (*rivertype.DeadLetter, error) {
+			require.Equal(t, int64(123), id)
+			return testDeadLetter, nil
+		}
+
+		ok, err := runCommand(ctx, t, cmd, &deadLetterGetOpts{DatabaseURL: "postgres://", ID: 123})
+		require.NoError(t, err)
+		require.True(t, ok)
+
+		require.Equal(t, strings.TrimSpace(`
+id:                123
+kind:              email_send
+queue:             default
+priority:          1
+attempt:           2/2
+tags:              tag1,tag2
+created at:        2025-01-01T00:00:00Z
+finalized at:      2025-01-01T00:02:00Z
+dead lettered at:  2025-01-08T00:02:00Z
+args:              {"to":"user@example.com"}
+metadata:          {}
+errors:
+  attempt 1 at 2025-01-01T00:01:00Z: error 1
+  attempt 2 at 2025-01-01T00:02:00Z: error 2
+		`), strings.TrimSpace(bundle.out.String()))
+	})
+
+	t.Run("NotFound", func(t *testing.T) {
+		t.Parallel()
+
+		cmd, bundle := setup(t)
+
+		bundle.clientStub.deadLetterGetStub = func(ctx context.Context, id int64) (*rivertype.DeadLetter, error) {
+			return nil, rivertype.ErrNotFound
+		}
+
+		ok, err := runCommand(ctx, t, cmd, &deadLetterGetOpts{DatabaseURL: "postgres://", ID: 123})
+		require.NoError(t, err)
+		require.False(t, ok)
+
+		require.Equal(t, "no dead letter with ID 123", strings.TrimSpace(bundle.out.String()))
+	})
+}
+
+func TestDeadLetterList(t *testing.T) {
+	t.Parallel()
+
+	ctx := context.Background()
+
+	type testBundle struct {
+		clientStub *ClientStub
+		out        *bytes.Buffer
+	}
+
+	setup := func(t *testing.T) (*deadLetterList, *testBundle) {
+		t.Helper()
+
+		cmd, out := withCommandBase(t, &deadLetterList{})
+
+		clientStub := &ClientStub{}
+		cmd.GetCommandBase().GetClient = func() (ClientInterface, error) { return clientStub, nil }
 
 		return cmd, &testBundle{
-			out:          out,
-			migratorStub: migratorStub,
+			clientStub: clientStub,
+			out:        out,
 		}
 	}
 
-	t.Run("NoExistingMigrations", func(t *testing.T) {
+	t.Run("PrintsDeadLetters", func(t *testing.T) {
 		t.Parallel()
 
 		cmd, bundle := setup(t)
 
-		_, err := runCommand(ctx, t, cmd, &migrateListOpts{})
+		bundle.clientStub.deadLetterListStub = func(ctx context.Context, params *river.DeadLetterListParams) (*river.DeadLetterListResult, error) {
+			return &river.DeadLetterListResult{DeadLetters: []*rivertype.DeadLetter{testDeadLetter}}, nil
+		}
+
+		ok, err := runCommand(ctx, t, cmd, &deadLetterListOpts{DatabaseURL: "postgres://", Kind: []string{"email_send"}, Limit: 10})
 		require.NoError(t, err)
+		require.True(t, ok)
 
 		require.Equal(t, strings.TrimSpace(`
-001 1st migration
-002 2nd migration
-003 3rd migration
+ID   KIND        QUEUE    ATTEMPT  DEAD LETTERED AT      LAST ERROR
+123  email_send  default  2/2      2025-01-08T00:02:00Z  error 2
 		`), strings.TrimSpace(bundle.out.String()))
 	})
 
-	t.Run("WithExistingMigrations", func(t *testing.T) {
+	t.Run("NoDeadLetters", func(t *testing.T) {
 		t.Parallel()
 
 		cmd, bundle := setup(t)
 
-		bundle.migratorStub.existingVersionsStub = func(ctx context.Context) ([]rivermigrate.Migration, error) {
-			return []rivermigrate.Migration{testMigration01, testMigration02}, nil
+		bundle.clientStub.deadLetterListStub = func(ctx context.Context, params *river.DeadLetterListParams) (*river.DeadLetterListResult, error) {
+			return &river.DeadLetterListResult{}, nil
 		}
 
-		_, err := runCommand(ctx, t, cmd, &migrateListOpts{})
+		ok, err := runCommand(ctx, t, cmd, &deadLetterListOpts{DatabaseURL: "postgres://", Limit: 10})
+		require.NoError(t, err)
+		require.True(t, ok)
+
+		require.Equal(t, "no dead letters found", strings.TrimSpace(bundle.out.String()))
+	})
+}
+
+func TestDeadLetterPurge(t *testing.T) {
+	t.Parallel()
+
+	ctx := context.Background()
+
+	t.Run("PurgesDeadLetters", func(t *testing.T) {
+		t.Parallel()
+
+		cmd, out := withCommandBase(t, &deadLetterPurge{})
+
+		clientStub := &ClientStub{}
+		clientStub.deadLetterPurgeStub = func(ctx context.Context, params *river.DeadLetterPurgeParams) (int, error) { return 2, nil }
+		cmd.GetCommandBase().GetClient = func() (ClientInterface, error) { return clientStub, nil }
+
+		ok, err := runCommand(ctx, t, cmd, &deadLetterPurgeOpts{DatabaseURL: "postgres://", Kind: []string{"email_send"}})
+		require.NoError(t, err)
+		require.True(t, ok)
+
+		require.Equal(t, "purged 2 dead letter(s)", strings.TrimSpace(out.String()))
+	})
+
+	t.Run("RequiresFilterOrAll", func(t *testing.T) {
+		t.Parallel()
+
+		require.EqualError(t, (&deadLetterPurgeOpts{DatabaseURL: "postgres://"}).Validate(),
+			"at least one of --id, --kind, --older-than, or --queue must be set; use --all to purge all dead letters")
+		require.NoError(t, (&deadLetterPurgeOpts{All: true, DatabaseURL: "postgres://"}).Validate())
+	})
+}
+
+func TestDeadLetterRequeue(t *testing.T) {
+	t.Parallel()
+
+	ctx := context.Background()
+
+	cmd, out := withCommandBase(t, &deadLetterRequeue{})
+
+	clientStub := &ClientStub{}
+	clientStub.deadLetterRequeueStub = func(ctx context.Context, id int64) (*rivertype.JobInsertResult, error) {
+		if id == 124 {
+			return nil, rivertype.ErrNotFound
+		}
+		return &rivertype.JobInsertResult{Job: &rivertype.JobRow{ID: 456}}, nil
+	}
+	cmd.GetCommandBase().GetClient = func() (ClientInterface, error) { return clientStub, nil }
+
+	ok, err := runCommand(ctx, t, cmd, &deadLetterRequeueOpts{DatabaseURL: "postgres://", ID: []int64{123, 124}})
+	require.NoError(t, err)
+	require.False(t, ok)
+
+	require.Equal(t, strings.TrimSpace(`
+requeued dead letter 123 as job 456
+no dead letter with ID 124
+	`), strings.TrimSpace(out.String()))
+}
+
+func TestJobCancel(t *testing.T) {
+	t.Parallel()
+
+	ctx := context.Background()
+
+	type testBundle struct {
+		clientStub *ClientStub
+		in         *bytes.Buffer
+		out        *bytes.Buffer
+	}
+
+	setup := func(t *testing.T) (*jobCancel, *testBundle) {
+		t.Helper()
+
+		cmd, out := withCommandBase(t, &jobCancel{})
+
+		clientStub := &ClientStub{}
+		cmd.GetCommandBase().GetClient = func() (ClientInterface, error) { return clientStub, nil }
+
+		var in bytes.Buffer
+		cmd.GetCommandBase().In = &in
+
+		return cmd, &testBundle{
+			clientStub: clientStub,
+			in:         &in,
+			out:        out,
+		}
+	}
+
+	t.Run("ByID", func(t *testing.T) {
+		t.Parallel()
+
+		cmd, bundle := setup(t)
+
+		bundle.clientStub.jobCancelStub = func(ctx context.Context, jobID int64) (*rivertype.JobRow, error) {
+			if jobID == 124 {
+				return nil, rivertype.ErrNotFound
+			}
+			return &rivertype.JobRow{ID: jobID, State: rivertype.JobStateCancelled}, nil
+		}
+
+		ok, err := runCommand(ctx, t, cmd, &jobActionOpts{DatabaseURL: "postgres://", jobFilterOpts: jobFilterOpts{ID: []int64{123, 124}}})
 		require.NoError(t, err)
+		require.False(t, ok)
 
 		require.Equal(t, strings.TrimSpace(`
-  001 1st migration
-* 002 2nd migration
-  003 3rd migration
+cancelled job 123 (state: cancelled)
+no job with ID 124
 		`), strings.TrimSpace(bundle.out.String()))
 	})
+
+	t.Run("ByFiltersConfirmed", func(t *testing.T) {
+		t.Parallel()
+
+		cmd, bundle := setup(t)
+
+		bundle.clientStub.jobListStub = func(ctx context.Context, params *river.JobListParams) (*river.JobListResult, error) {
+			require.Equal(t, river.NewJobListParams().Kinds("email_send").States(rivertype.JobStateAvailable).OrderBy(river.JobListOrderByID, river.SortOrderDesc).First(1), params)
+			return &river.JobListResult{Jobs: []*rivertype.JobRow{{ID: 456}}}, nil
+		}
+		bundle.clientStub.jobCountStub = func(ctx context.Context, params *river.JobListParams) (int, error) {
+			require.Equal(t, river.NewJobListParams().Kinds("email_send").States(rivertype.JobStateAvailable).IDAtMost(456), params)
+			return 3, nil
+		}
+		bundle.clientStub.jobCancelManyStub = func(ctx context.Context, params *river.JobListParams, opts *river.JobManyOpts) (*river.JobCancelManyResult, error) {
+			require.Equal(t, &river.JobManyOpts{CountOnly: true}, opts)
+
+			// Pinned to the jobs matching when the prompt was shown.
+			require.Equal(t, river.NewJobListParams().Kinds("email_send").States(rivertype.JobStateAvailable).IDAtMost(456), params)
+			return &river.JobCancelManyResult{NumCancelled: 2, NumSkipped: 1}, nil
+		}
+		bundle.in.WriteString("y\n")
+
+		ok, err := runCommand(ctx, t, cmd, &jobActionOpts{DatabaseURL: "postgres://", jobFilterOpts: jobFilterOpts{Kind: []string{"email_send"}, State: []string{"available"}}})
+		require.NoError(t, err)
+		require.True(t, ok)
+
+		require.Equal(t, "cancel 3 job(s) matching the given filters? [y/N] cancelled 2 job(s); skipped 1 job(s) that were already finalized", strings.TrimSpace(bundle.out.String()))
+	})
+
+	t.Run("ByFiltersDeclined", func(t *testing.T) {
+		t.Parallel()
+
+		cmd, bundle := setup(t)
+
+		bundle.clientStub.jobListStub = func(ctx context.Context, params *river.JobListParams) (*river.JobListResult, error) {
+			return &river.JobListResult{Jobs: []*rivertype.JobRow{{ID: 456}}}, nil
+		}
+		bundle.clientStub.jobCountStub = func(ctx context.Context, params *river.JobListParams) (int, error) { return 3, nil }
+		bundle.in.WriteString("n\n")
+
+		ok, err := runCommand(ctx, t, cmd, &jobActionOpts{DatabaseURL: "postgres://", jobFilterOpts: jobFilterOpts{Kind: []string{"email_send"}}})
+		require.NoError(t, err)
+		require.False(t, ok)
+
+		require.Equal(t, "cancel 3 job(s) matching the given filters? [y/N] aborted", strings.TrimSpace(bundle.out.String()))
+	})
+
+	t.Run("ByFiltersNoInput", func(t *testing.T) {
+		t.Parallel()
+
+		cmd, bundle := setup(t)
+
+		bundle.clientStub.jobListStub = func(ctx context.Context, params *river.JobListParams) (*river.JobListResult, error) {
+			return &river.JobListResult{Jobs: []*rivertype.JobRow{{ID: 456}}}, nil
+		}
+		bundle.clientStub.jobCountStub = func(ctx context.Context, params *river.JobListParams) (int, error) { return 3, nil }
+
+		ok, err := runCommand(ctx, t, cmd, &jobActionOpts{DatabaseURL: "postgres://", jobFilterOpts: jobFilterOpts{Kind: []string{"email_send"}}})
+		require.NoError(t, err)
+		require.False(t, ok)
+
+		require.Equal(t, "cancel 3 job(s) matching the given filters? [y/N] aborted", strings.TrimSpace(bundle.out.String()))
+	})
+
+	t.Run("ByFiltersNoMatches", func(t *testing.T) {
+		t.Parallel()
+
+		cmd, bundle := setup(t)
+
+		bundle.clientStub.jobListStub = func(ctx context.Context, params *river.JobListParams) (*river.JobListResult, error) {
+			return &river.JobListResult{}, nil
+		}
+
+		ok, err := runCommand(ctx, t, cmd, &jobActionOpts{DatabaseURL: "postgres://", jobFilterOpts: jobFilterOpts{Kind: []string{"email_send"}}})
+		require.NoError(t, err)
+		require.True(t, ok)
+
+		require.Equal(t, "no jobs match the given filters", strings.TrimSpace(bundle.out.String()))
+	})
+
+	t.Run("ByFiltersWithYes", func(t *testing.T) {
+		t.Parallel()
+
+		cmd, bundle := setup(t)
+
+		bundle.clientStub.jobCancelManyStub = func(ctx context.Context, params *river.JobListParams, opts *river.JobManyOpts) (*river.JobCancelManyResult, error) {
+			require.Equal(t, &river.JobManyOpts{CountOnly: true}, opts)
+			return &river.JobCancelManyResult{NumCancelled: 2}, nil
+		}
+
+		ok, err := runCommand(ctx, t, cmd, &jobActionOpts{DatabaseURL: "postgres://", Yes: true, jobFilterOpts: jobFilterOpts{Kind: []string{"email_send"}}})
+		require.NoError(t, err)
+		require.True(t, ok)
+
+		require.Equal(t, "cancelled 2 job(s)", strings.TrimSpace(bundle.out.String()))
+	})
+
+	t.Run("RequiresIDOrFilter", func(t *testing.T) {
+		t.Parallel()
+
+		require.EqualError(t, (&jobActionOpts{DatabaseURL: "postgres://"}).Validate(),
+			"at least one of --id or another filter like --kind, --queue, or --state must be set")
+		require.NoError(t, (&jobActionOpts{DatabaseURL: "postgres://", jobFilterOpts: jobFilterOpts{ID: []int64{123}}}).Validate())
+		require.NoError(t, (&jobActionOpts{DatabaseURL: "postgres://", jobFilterOpts: jobFilterOpts{Queue: []string{"default"}}}).Validate())
+	})
+}
+
+func TestJobDelete(t *testing.T) {
+	t.Parallel()
+
+	ctx := context.Background()
+
+	t.Run("ByID", func(t *testing.T) {
+		t.Parallel()
+
+		cmd, out := withCommandBase(t, &jobDelete{})
+
+		clientStub := &ClientStub{}
+		clientStub.jobDeleteStub = func(ctx context.Context, id int64) (*rivertype.JobRow, error) {
+			if id == 124 {
+				return nil, rivertype.ErrJobRunning
+			}
+			return &rivertype.JobRow{ID: id, State: rivertype.JobStateCompleted}, nil
+		}
+		cmd.GetCommandBase().GetClient = func() (ClientInterface, error) { return clientStub, nil }
+
+		ok, err := runCommand(ctx, t, cmd, &jobActionOpts{DatabaseURL: "postgres://", jobFilterOpts: jobFilterOpts{ID: []int64{123, 124}}})
+		require.NoError(t, err)
+		require.False(t, ok)
+
+		require.Equal(t, strings.TrimSpace(`
+deleted job 123 (state: completed)
+job 124 not deleted: running jobs cannot be deleted
+		`), strings.TrimSpace(out.String()))
+	})
+
+	t.Run("ByFiltersWithYes", func(t *testing.T) {
+		t.Parallel()
+
+		cmd, out := withCommandBase(t, &jobDelete{})
+
+		clientStub := &ClientStub{}
+		clientStub.jobDeleteManyStub = func(ctx context.Context, params *river.JobListParams, opts *river.JobManyOpts) (*river.JobDeleteManyResult, error) {
+			require.Equal(t, &river.JobManyOpts{CountOnly: true}, opts)
+			return &river.JobDeleteManyResult{NumDeleted: 5, NumSkipped: 1}, nil
+		}
+		cmd.GetCommandBase().GetClient = func() (ClientInterface, error) { return clientStub, nil }
+
+		ok, err := runCommand(ctx, t, cmd, &jobActionOpts{DatabaseURL: "postgres://", Yes: true, jobFilterOpts: jobFilterOpts{State: []string{"completed"}}})
+		require.NoError(t, err)
+		require.True(t, ok)
+
+		require.Equal(t, "deleted 5 job(s); skipped 1 job(s) that were running", strings.TrimSpace(out.String()))
+	})
+}
+
+func TestJobFilterOpts(t *testing.T) {
+	t.Parallel()
+
+	now := time.Date(2025, 1, 2, 0, 0, 0, 0, time.UTC)
+
+	t.Run("AllFilters", func(t *testing.T) {
+		t.Parallel()
+
+		opts := &jobFilterOpts{
+			Args:            `{"to":"user@example.com"}`,
+			AttemptMax:      3,
+			AttemptMin:      1,
+			CreatedAfter:    "24h",
+			CreatedBefore:   "2025-01-01T12:00:00Z",
+			FinalizedAfter:  "2025-01-01T00:00:00Z",
+			FinalizedBefore: "1h",
+			ID:              []int64{123},
+			Kind:            []string{"email_send"},
+			Metadata:        `{"key":"value"}`,
+			Priority:        []int{1, 2},
+			Queue:           []string{"default"},
+			ScheduledBefore: "2025-01-03T00:00:00Z",
+			State:           []string{"retryable", "discarded"},
+			Tag:             []string{"tag1"},
+			TagAll:          []string{"tag2", "tag3"},
+		}
+		require.True(t, opts.hasNonIDFilters())
+
+		params, err := opts.jobListParams(now)
+		require.NoError(t, err)
+		require.Equal(t, river.NewJobListParams().
+			Args(`{"to":"user@example.com"}`).
+			AttemptAtMost(3).
+			AttemptAtLeast(1).
+			CreatedAtRange(time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC), time.Date(2025, 1, 1, 12, 0, 0, 0, time.UTC)).
+			FinalizedAtRange(time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC), time.Date(2025, 1, 1, 23, 0, 0, 0, time.UTC)).
+			ScheduledAtRange(time.Time{}, time.Date(2025, 1, 3, 0, 0, 0, 0, time.UTC)).
+			IDs(123).
+			Kinds("email_send").
+			Metadata(`{"key":"value"}`).
+			Priorities(1, 2).
+			Queues("default").
+			States(rivertype.JobStateRetryable, rivertype.JobStateDiscarded).
+			TagsAny("tag1").
+			TagsAll("tag2", "tag3"), params)
+	})
+
+	t.Run("IDOnly", func(t *testing.T) {
+		t.Parallel()
+
+		opts := &jobFilterOpts{ID: []int64{123}}
+		require.False(t, opts.hasNonIDFilters())
+
+		params, err := opts.jobListParams(now)
+		require.NoError(t, err)
+		require.Equal(t, river.NewJobListParams().IDs(123), params)
+	})
+
+	t.Run("InvalidFilters", func(t *testing.T) {
+		t.Parallel()
+
+		for _, tt := range []struct {
+			opts        *jobFilterOpts
+			expectedErr string
+		}{
+			{&jobFilterOpts{Args: "{"}, "--args must be valid JSON"},
+			{&jobFilterOpts{AttemptMin: -1}, "--attempt-max and --attempt-min must be greater than or equal to zero"},
+			{&jobFilterOpts{CreatedAfter: "yesterday"}, "--created-after must be an RFC3339 timestamp like 2025-01-01T00:00:00Z or a duration like 24h"},
+			{&jobFilterOpts{CreatedAfter: "1h", CreatedBefore: "2h"}, "--created-before must not be before --created-after"},
+			{&jobFilterOpts{Metadata: "{"}, "--metadata must be valid JSON"},
+			{&jobFilterOpts{State: []string{"failed"}}, "invalid --state `failed`; must be one of: available, cancelled, completed, discarded, pending, retryable, running, scheduled"},
+		} {
+			_, err := tt.opts.jobListParams(now)
+			require.EqualError(t, err, tt.expectedErr)
+		}
+	})
+}
+
+func TestJobGet(t *testing.T) {
+	t.Parallel()
+
+	ctx := context.Background()
+
+	type testBundle struct {
+		clientStub *ClientStub
+		out        *bytes.Buffer
+	}
+
+	setup := func(t *testing.T) (*jobGet, *testBundle) {
+		t.Helper()
+
+		cmd, out := withCommandBase(t, &jobGet{})
+
+		clientStub := &ClientStub{}
+		cmd.GetCommandBase().GetClient = func() (ClientInterface, error) { return clientStub, nil }
+
+		return cmd, &testBundle{
+			clientStub: clientStub,
+			out:        out,
+		}
+	}
+
+	t.Run("PrintsJob", func(t *testing.T) {
+		t.Parallel()
+
+		cmd, bundle := setup(t)
+
+		bundle.clientStub.jobGetStub = func(ctx context.Context, id int64) (*rivertype.JobRow, error) {
+			require.Equal(t, int64(123), id)
+			return testJob, nil
+		}
+
+		ok, err := runCommand(ctx, t, cmd, &jobGetOpts{DatabaseURL: "postgres://", ID: 123, Output: outputTable})
+		require.NoError(t, err)
+		require.True(t, ok)
+
+		require.Equal(t, strings.TrimSpace(`
+id:            123
+kind:          email_send
+queue:         default
+state:         retryable
+priority:      1
+attempt:       2/25
+attempted by:  worker1
+tags:          tag1,tag2
+created at:    2025-01-01T00:00:00Z
+scheduled at:  2025-01-01T00:05:00Z
+attempted at:  2025-01-01T00:02:00Z
+finalized at:  -
+args:          {"to":"user@example.com"}
+metadata:      {}
+errors:
+  attempt 1 at 2025-01-01T00:01:00Z: error 1
+  attempt 2 at 2025-01-01T00:02:00Z: error 2
+		`), strings.TrimSpace(bundle.out.String()))
+	})
+
+	t.Run("PrintsJSON", func(t *testing.T) {
+		t.Parallel()
+
+		cmd, bundle := setup(t)
+
+		bundle.clientStub.jobGetStub = func(ctx context.Context, id int64) (*rivertype.JobRow, error) { return testJob, nil }
+
+		ok, err := runCommand(ctx, t, cmd, &jobGetOpts{DatabaseURL: "postgres://", ID: 123, Output: outputJSON})
+		require.NoError(t, err)
+		require.True(t, ok)
+
+		var job map[string]any
+		require.NoError(t, json.Unmarshal(bundle.out.Bytes(), &job))
+		require.Equal(t, map[string]any{"to": "user@example.com"}, job["args"])
+		require.Equal(t, "2025-01-01T00:00:00Z", job["created_at"])
+		require.InDelta(t, 123, job["id"], 0)
+		require.Equal(t, "email_send", job["kind"])
+		require.Equal(t, "retryable", job["state"])
+	})
+
+	t.Run("NotFound", func(t *testing.T) {
+		t.Parallel()
+
+		cmd, bundle := setup(t)
+
+		bundle.clientStub.jobGetStub = func(ctx context.Context, id int64) (*rivertype.JobRow, error) {
+			return nil, rivertype.ErrNotFound
+		}
+
+		ok, err := runCommand(ctx, t, cmd, &jobGetOpts{DatabaseURL: "postgres://", ID: 123, Output: outputTable})
+		require.NoError(t, err)
+		require.False(t, ok)
+
+		require.Equal(t, "no job with ID 123", strings.TrimSpace(bundle.out.String()))
+	})
+
+	t.Run("InvalidOutput", func(t *testing.T) {
+		t.Parallel()
+
+		require.EqualError(t, (&jobGetOpts{DatabaseURL: "postgres://", ID: 123, Output: "yaml"}).Validate(),
+			"--output must be one of `table` or `json`")
+	})
+}
+
+func TestJobList(t *testing.T) {
+	t.Parallel()
+
+	ctx := context.Background()
+
+	type testBundle struct {
+		clientStub *ClientStub
+		out        *bytes.Buffer
+	}
+
+	setup := func(t *testing.T) (*jobList, *testBundle) {
+		t.Helper()
+
+		cmd, out := withCommandBase(t, &jobList{})
+
+		clientStub := &ClientStub{}
+		cmd.GetCommandBase().GetClient = func() (ClientInterface, error) { return clientStub, nil }
+
+		return cmd, &testBundle{
+			clientStub: clientStub,
+			out:        out,
+		}
+	}
+
+	// A cursor as it'd be returned by a previous page, which can only be
+	// produced by unmarshaling because its fields are unexported.
+	const cursorText = "eyJpZCI6MTIzLCJraW5kIjoiZW1haWxfc2VuZCIsInF1ZXVlIjoiZGVmYXVsdCIsInNvcnRfZmllbGQiOiJpZCIsInRpbWUiOiIwMDAxLTAxLTAxVDAwOjAwOjAwWiJ9"
+	var cursor river.JobListCursor
+	require.NoError(t, cursor.UnmarshalText([]byte(cursorText)))
+
+	t.Run("PrintsJobs", func(t *testing.T) {
+		t.Parallel()
+
+		cmd, bundle := setup(t)
+
+		bundle.clientStub.jobListStub = func(ctx context.Context, params *river.JobListParams) (*river.JobListResult, error) {
+			require.Equal(t, river.NewJobListParams().Kinds("email_send").First(10), params)
+			return &river.JobListResult{Jobs: []*rivertype.JobRow{testJob}, LastCursor: &cursor}, nil
+		}
+
+		ok, err := runCommand(ctx, t, cmd, &jobListOpts{DatabaseURL: "postgres://", Limit: 10, Output: outputTable, jobFilterOpts: jobFilterOpts{Kind: []string{"email_send"}}})
+		require.NoError(t, err)
+		require.True(t, ok)
+
+		require.Equal(t, strings.TrimSpace(`
+ID   KIND        QUEUE    STATE      ATTEMPT  SCHEDULED AT          LAST ERROR
+123  email_send  default  retryable  2/25     2025-01-01T00:05:00Z  error 2
+		`), strings.TrimSpace(bundle.out.String()))
+	})
+
+	t.Run("PrintsNextCursorForFullPage", func(t *testing.T) {
+		t.Parallel()
+
+		cmd, bundle := setup(t)
+
+		bundle.clientStub.jobListStub = func(ctx context.Context, params *river.JobListParams) (*river.JobListResult, error) {
+			require.Equal(t, river.NewJobListParams().First(1).After(&cursor), params)
+			return &river.JobListResult{Jobs: []*rivertype.JobRow{testJob}, LastCursor: &cursor}, nil
+		}
+
+		ok, err := runCommand(ctx, t, cmd, &jobListOpts{After: cursorText, DatabaseURL: "postgres://", Limit: 1, Output: outputTable})
+		require.NoError(t, err)
+		require.True(t, ok)
+
+		require.Equal(t, strings.TrimSpace(`
+ID   KIND        QUEUE    STATE      ATTEMPT  SCHEDULED AT          LAST ERROR
+123  email_send  default  retryable  2/25     2025-01-01T00:05:00Z  error 2
+
+more jobs may be available; list the next page with --after `+cursorText+`
+		`), strings.TrimSpace(bundle.out.String()))
+	})
+
+	t.Run("PrintsJSON", func(t *testing.T) {
+		t.Parallel()
+
+		cmd, bundle := setup(t)
+
+		bundle.clientStub.jobListStub = func(ctx context.Context, params *river.JobListParams) (*river.JobListResult, error) {
+			return &river.JobListResult{Jobs: []*rivertype.JobRow{testJob}, LastCursor: &cursor}, nil
+		}
+
+		ok, err := runCommand(ctx, t, cmd, &jobListOpts{DatabaseURL: "postgres://", Limit: 1, Output: outputJSON})
+		require.NoError(t, err)
+		require.True(t, ok)
+
+		var res struct {
+			Jobs       []map[string]any `json:"jobs"`
+			NextCursor string           `json:"next_cursor"`
+		}
+		require.NoError(t, json.Unmarshal(bundle.out.Bytes(), &res))
+		require.Len(t, res.Jobs, 1)
+		require.InDelta(t, 123, res.Jobs[0]["id"], 0)
+		require.Equal(t, cursorText, res.NextCursor)
+	})
+
+	t.Run("NoJobs", func(t *testing.T) {
+		t.Parallel()
+
+		cmd, bundle := setup(t)
+
+		bundle.clientStub.jobListStub = func(ctx context.Context, params *river.JobListParams) (*river.JobListResult, error) {
+			return &river.JobListResult{}, nil
+		}
+
+		ok, err := runCommand(ctx, t, cmd, &jobListOpts{DatabaseURL: "postgres://", Limit: 10, Output: outputTable})
+		require.NoError(t, err)
+		require.True(t, ok)
+
+		require.Equal(t, "no jobs found", strings.TrimSpace(bundle.out.String()))
+	})
+
+	t.Run("InvalidCursor", func(t *testing.T) {
+		t.Parallel()
+
+		cmd, _ := setup(t)
+
+		_, err := runCommand(ctx, t, cmd, &jobListOpts{After: "not a cursor", DatabaseURL: "postgres://", Limit: 10, Output: outputTable})
+		require.ErrorContains(t, err, "error decoding --after cursor")
+	})
+}
+
+func TestJobRetry(t *testing.T) {
+	t.Parallel()
+
+	ctx := context.Background()
+
+	t.Run("ByID", func(t *testing.T) {
+		t.Parallel()
+
+		cmd, out := withCommandBase(t, &jobRetry{})
+
+		clientStub := &ClientStub{}
+		clientStub.jobRetryStub = func(ctx context.Context, id int64) (*rivertype.JobRow, error) {
+			return &rivertype.JobRow{ID: id, State: rivertype.JobStateAvailable}, nil
+		}
+		cmd.GetCommandBase().GetClient = func() (ClientInterface, error) { return clientStub, nil }
+
+		ok, err := runCommand(ctx, t, cmd, &jobActionOpts{DatabaseURL: "postgres://", jobFilterOpts: jobFilterOpts{ID: []int64{123}}})
+		require.NoError(t, err)
+		require.True(t, ok)
+
+		require.Equal(t, "retried job 123 (state: available)", strings.TrimSpace(out.String()))
+	})
+
+	t.Run("ByFiltersConfirmed", func(t *testing.T) {
+		t.Parallel()
+
+		cmd, out := withCommandBase(t, &jobRetry{})
+
+		clientStub := &ClientStub{}
+		clientStub.jobListStub = func(ctx context.Context, params *river.JobListParams) (*river.JobListResult, error) {
+			return &river.JobListResult{Jobs: []*rivertype.JobRow{{ID: 456}}}, nil
+		}
+		clientStub.jobCountStub = func(ctx context.Context, params *river.JobListParams) (int, error) { return 2, nil }
+		clientStub.jobRetryManyStub = func(ctx context.Context, params *river.JobListParams, opts *river.JobManyOpts) (*river.JobRetryManyResult, error) {
+			require.Equal(t, &river.JobManyOpts{CountOnly: true}, opts)
+			require.Equal(t, river.NewJobListParams().States(rivertype.JobStateDiscarded).IDAtMost(456), params)
+			return &river.JobRetryManyResult{NumRetried: 2}, nil
+		}
+		cmd.GetCommandBase().GetClient = func() (ClientInterface, error) { return clientStub, nil }
+		cmd.GetCommandBase().In = strings.NewReader("yes\n")
+
+		ok, err := runCommand(ctx, t, cmd, &jobActionOpts{DatabaseURL: "postgres://", jobFilterOpts: jobFilterOpts{State: []string{"discarded"}}})
+		require.NoError(t, err)
+		require.True(t, ok)
+
+		require.Equal(t, "retry 2 job(s) matching the given filters? [y/N] retried 2 job(s)", strings.TrimSpace(out.String()))
+	})
+}
+
+func TestMigrateList(t *testing.T) {
+	t.Parallel()
+
+	ctx := context.Background()
+
+	type testBundle struct {
+		migratorStub *MigratorStub
+		out          *bytes.Buffer
+	}
+
+	setup := func(t *testing.T) (*migrateList, *testBundle) {
+		t.Helper()
+
+		cmd, out := withCommandBase(t, &migrateList{})
+
+		migratorStub := &MigratorStub{}
+		migratorStub.allVersionsStub = func() []rivermigrate.Migration { return testMigrationAll }
+		migratorStub.existingVersionsStub = func(ctx context.Context) ([]rivermigrate.Migration, error) { return nil, nil }
+
+		cmd.GetCommandBase().GetMigrator = func(config *rivermigrate.Config) (MigratorInterface, error) { return migratorStub, nil }
+
+		return cmd, &testBundle{
+			out:          out,
+			migratorStub: migratorStub,
+		}
+	}
+
+	t.Run("NoExistingMigrations", func(t *testing.T) {
+		t.Parallel()
+
+		cmd, bundle := setup(t)
+
+		_, err := runCommand(ctx, t, cmd, &migrateListOpts{})
+		require.NoError(t, err)
+
+		require.Equal(t, strings.TrimSpace(`
+001 1st migration
+002 2nd migration
+003 3rd migration
+		`), strings.TrimSpace(bundle.out.String()))
+	})
+
+	t.Run("WithExistingMigrations", func(t *testing.T) {
+		t.Parallel()
+
+		cmd, bundle := setup(t)
+
+		bundle.migratorStub.existingVersionsStub = func(ctx context.Context) ([]rivermigrate.Migration, error) {
+			return []rivermigrate.Migration{testMigration01, testMigration02}, nil
+		}
+
+		_, err := runCommand(ctx, t, cmd, &migrateListOpts{})
+		require.NoError(t, err)
+
+		require.Equal(t, strings.TrimSpace(`
+  001 1st migration
+* 002 2nd migration
+  003 3rd migration
+		`), strings.TrimSpace(bundle.out.String()))
+	})
+}
+
+func TestQueueGet(t *testing.T) {
+	t.Parallel()
+
+	ctx := context.Background()
+
+	type testBundle struct {
+		clientStub *ClientStub
+		out        *bytes.Buffer
+	}
+
+	setup := func(t *testing.T) (*queueGet, *testBundle) {
+		t.Helper()
+
+		cmd, out := withCommandBase(t, &queueGet{})
+
+		clientStub := &ClientStub{}
+		cmd.GetCommandBase().GetClient = func() (ClientInterface, error) { return clientStub, nil }
+
+		return cmd, &testBundle{
+			clientStub: clientStub,
+			out:        out,
+		}
+	}
+
+	t.Run("PrintsQueue", func(t *testing.T) {
+		t.Parallel()
+
+		cmd, bundle := setup(t)
+
+		bundle.clientStub.queueGetStub = func(ctx context.Context, name string) (*rivertype.Queue, error) {
+			require.Equal(t, "default", name)
+			return testQueue, nil
+		}
+
+		ok, err := runCommand(ctx, t, cmd, &queueGetOpts{DatabaseURL: "postgres://", Name: "default", Output: outputTable})
+		require.NoError(t, err)
+		require.True(t, ok)
+
+		require.Equal(t, strings.TrimSpace(`
+name:        default
+paused at:   2025-01-01T00:01:00Z
+created at:  2025-01-01T00:00:00Z
+updated at:  2025-01-01T00:02:00Z
+metadata:    {"owner":"billing"}
+		`), strings.TrimSpace(bundle.out.String()))
+	})
+
+	t.Run("PrintsJSON", func(t *testing.T) {
+		t.Parallel()
+
+		cmd, bundle := setup(t)
+
+		bundle.clientStub.queueGetStub = func(ctx context.Context, name string) (*rivertype.Queue, error) { return testQueue, nil }
+
+		ok, err := runCommand(ctx, t, cmd, &queueGetOpts{DatabaseURL: "postgres://", Name: "default", Output: outputJSON})
+		require.NoError(t, err)
+		require.True(t, ok)
+
+		var queue map[string]any
+		require.NoError(t, json.Unmarshal(bundle.out.Bytes(), &queue))
+		require.Equal(t, map[string]any{"owner": "billing"}, queue["metadata"])
+		require.Equal(t, "default", queue["name"])
+		require.Equal(t, "2025-01-01T00:01:00Z", queue["paused_at"])
+	})
+
+	t.Run("NotFound", func(t *testing.T) {
+		t.Parallel()
+
+		cmd, bundle := setup(t)
+
+		bundle.clientStub.queueGetStub = func(ctx context.Context, name string) (*rivertype.Queue, error) {
+			return nil, rivertype.ErrNotFound
+		}
+
+		ok, err := runCommand(ctx, t, cmd, &queueGetOpts{DatabaseURL: "postgres://", Name: "default", Output: outputTable})
+		require.NoError(t, err)
+		require.False(t, ok)
+
+		require.Equal(t, "no queue with name default", strings.TrimSpace(bundle.out.String()))
+	})
+}
+
+func TestQueueList(t *testing.T) {
+	t.Parallel()
+
+	ctx := context.Background()
+
+	type testBundle struct {
+		clientStub *ClientStub
+		out        *bytes.Buffer
+	}
+
+	setup := func(t *testing.T) (*queueList, *testBundle) {
+		t.Helper()
+
+		cmd, out := withCommandBase(t, &queueList{})
+
+		clientStub := &ClientStub{}
+		cmd.GetCommandBase().GetClient = func() (ClientInterface, error) { return clientStub, nil }
+
+		return cmd, &testBundle{
+			clientStub: clientStub,
+			out:        out,
+		}
+	}
+
+	t.Run("PrintsQueues", func(t *testing.T) {
+		t.Parallel()
+
+		cmd, bundle := setup(t)
+
+		bundle.clientStub.queueListStub = func(ctx context.Context, params *river.QueueListParams) (*river.QueueListResult, error) {
+			require.Equal(t, river.NewQueueListParams().First(10), params)
+			return &river.QueueListResult{Queues: []*rivertype.Queue{
+				testQueue,
+				{Name: "email", CreatedAt: time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC), UpdatedAt: time.Date(2025, 1, 1, 0, 3, 0, 0, time.UTC)},
+			}}, nil
+		}
+
+		ok, err := runCommand(ctx, t, cmd, &queueListOpts{DatabaseURL: "postgres://", Limit: 10, Output: outputTable})
+		require.NoError(t, err)
+		require.True(t, ok)
+
+		require.Equal(t, strings.TrimSpace(`
+NAME     PAUSED AT             CREATED AT            UPDATED AT
+default  2025-01-01T00:01:00Z  2025-01-01T00:00:00Z  2025-01-01T00:02:00Z
+email    -                     2025-01-01T00:00:00Z  2025-01-01T00:03:00Z
+		`), strings.TrimSpace(bundle.out.String()))
+	})
+
+	t.Run("PrintsJSON", func(t *testing.T) {
+		t.Parallel()
+
+		cmd, bundle := setup(t)
+
+		bundle.clientStub.queueListStub = func(ctx context.Context, params *river.QueueListParams) (*river.QueueListResult, error) {
+			return &river.QueueListResult{Queues: []*rivertype.Queue{testQueue}}, nil
+		}
+
+		ok, err := runCommand(ctx, t, cmd, &queueListOpts{DatabaseURL: "postgres://", Limit: 10, Output: outputJSON})
+		require.NoError(t, err)
+		require.True(t, ok)
+
+		var res struct {
+			Queues []map[string]any `json:"queues"`
+		}
+		require.NoError(t, json.Unmarshal(bundle.out.Bytes(), &res))
+		require.Len(t, res.Queues, 1)
+		require.Equal(t, "default", res.Queues[0]["name"])
+	})
+
+	t.Run("NoQueues", func(t *testing.T) {
+		t.Parallel()
+
+		cmd, bundle := setup(t)
+
+		bundle.clientStub.queueListStub = func(ctx context.Context, params *river.QueueListParams) (*river.QueueListResult, error) {
+			return &river.QueueListResult{}, nil
+		}
+
+		ok, err := runCommand(ctx, t, cmd, &queueListOpts{DatabaseURL: "postgres://", Limit: 10, Output: outputTable})
+		require.NoError(t, err)
+		require.True(t, ok)
+
+		require.Equal(t, "no queues found", strings.TrimSpace(bundle.out.String()))
+	})
+}
+
+func TestQueuePause(t *testing.T) {
+	t.Parallel()
+
+	ctx := context.Background()
+
+	t.Run("ByName", func(t *testing.T) {
+		t.Parallel()
+
+		cmd, out := withCommandBase(t, &queuePause{})
+
+		clientStub := &ClientStub{}
+		clientStub.queuePauseStub = func(ctx context.Context, name string, opts *river.QueuePauseOpts) error {
+			if name == "missing" {
+				return rivertype.ErrNotFound
+			}
+			return nil
+		}
+		cmd.GetCommandBase().GetClient = func() (ClientInterface, error) { return clientStub, nil }
+
+		ok, err := runCommand(ctx, t, cmd, &queuePauseOpts{DatabaseURL: "postgres://", Name: []string{"default", "missing"}})
+		require.NoError(t, err)
+		require.False(t, ok)
+
+		require.Equal(t, strings.TrimSpace(`
+paused queue default
+no queue with name missing
+		`), strings.TrimSpace(out.String()))
+	})
+
+	t.Run("All", func(t *testing.T) {
+		t.Parallel()
+
+		cmd, out := withCommandBase(t, &queuePause{})
+
+		clientStub := &ClientStub{}
+		clientStub.queuePauseStub = func(ctx context.Context, name string, opts *river.QueuePauseOpts) error {
+			require.Equal(t, "*", name)
+			return nil
+		}
+		cmd.GetCommandBase().GetClient = func() (ClientInterface, error) { return clientStub, nil }
+
+		ok, err := runCommand(ctx, t, cmd, &queuePauseOpts{All: true, DatabaseURL: "postgres://"})
+		require.NoError(t, err)
+		require.True(t, ok)
+
+		require.Equal(t, "paused all queues", strings.TrimSpace(out.String()))
+	})
+
+	t.Run("RequiresNameOrAll", func(t *testing.T) {
+		t.Parallel()
+
+		require.EqualError(t, (&queuePauseOpts{DatabaseURL: "postgres://"}).Validate(),
+			"either --all or at least one --name must be set")
+	})
+}
+
+func TestQueueResume(t *testing.T) {
+	t.Parallel()
+
+	ctx := context.Background()
+
+	cmd, out := withCommandBase(t, &queueResume{})
+
+	clientStub := &ClientStub{}
+	clientStub.queueResumeStub = func(ctx context.Context, name string, opts *river.QueuePauseOpts) error { return nil }
+	cmd.GetCommandBase().GetClient = func() (ClientInterface, error) { return clientStub, nil }
+
+	ok, err := runCommand(ctx, t, cmd, &queuePauseOpts{DatabaseURL: "postgres://", Name: []string{"default", "email"}})
+	require.NoError(t, err)
+	require.True(t, ok)
+
+	require.Equal(t, strings.TrimSpace(`
+resumed queue default
+resumed queue email
+	`), strings.TrimSpace(out.String()))
+}
+
+func TestQueueUpdate(t *testing.T) {
+	t.Parallel()
+
+	ctx := context.Background()
+
+	type testBundle struct {
+		clientStub *ClientStub
+		out        *bytes.Buffer
+	}
+
+	setup := func(t *testing.T) (*queueUpdate, *testBundle) {
+		t.Helper()
+
+		cmd, out := withCommandBase(t, &queueUpdate{})
+
+		clientStub := &ClientStub{}
+		cmd.GetCommandBase().GetClient = func() (ClientInterface, error) { return clientStub, nil }
+
+		return cmd, &testBundle{
+			clientStub: clientStub,
+			out:        out,
+		}
+	}
+
+	t.Run("Metadata", func(t *testing.T) {
+		t.Parallel()
+
+		cmd, bundle := setup(t)
+
+		bundle.clientStub.queueUpdateStub = func(ctx context.Context, name string, params *river.QueueUpdateParams) (*rivertype.Queue, error) {
+			require.Equal(t, "default", name)
+			require.Equal(t, &river.QueueUpdateParams{Metadata: []byte(`{"owner":"billing"}`)}, params)
+			return testQueue, nil
+		}
+
+		ok, err := runCommand(ctx, t, cmd, &queueUpdateOpts{DatabaseURL: "postgres://", Metadata: `{"owner":"billing"}`, Name: "default", Output: outputTable})
+		require.NoError(t, err)
+		require.True(t, ok)
+
+		require.Contains(t, bundle.out.String(), `metadata:    {"owner":"billing"}`)
+	})
+
+	t.Run("Concurrency", func(t *testing.T) {
+		t.Parallel()
+
+		cmd, bundle := setup(t)
+
+		bundle.clientStub.queueUpdateStub = func(ctx context.Context, name string, params *river.QueueUpdateParams) (*rivertype.Queue, error) {
+			require.Equal(t, &river.QueueUpdateParams{Concurrency: &river.ConcurrencyConfig{
+				GlobalLimit: 10,
+				Partition:   river.PartitionConfig{ByKind: true, Limit: 2},
+			}}, params)
+			return testQueue, nil
+		}
+
+		ok, err := runCommand(ctx, t, cmd, &queueUpdateOpts{
+			ConcurrencyGlobalLimit:     10,
+			ConcurrencyPartitionByKind: true,
+			ConcurrencyPartitionLimit:  2,
+			DatabaseURL:                "postgres://",
+			Name:                       "default",
+			Output:                     outputTable,
+		})
+		require.NoError(t, err)
+		require.True(t, ok)
+	})
+
+	t.Run("ConcurrencyReset", func(t *testing.T) {
+		t.Parallel()
+
+		cmd, bundle := setup(t)
+
+		bundle.clientStub.queueUpdateStub = func(ctx context.Context, name string, params *river.QueueUpdateParams) (*rivertype.Queue, error) {
+			require.Equal(t, &river.QueueUpdateParams{Concurrency: &river.ConcurrencyConfig{}}, params)
+			return testQueue, nil
+		}
+
+		ok, err := runCommand(ctx, t, cmd, &queueUpdateOpts{ConcurrencyReset: true, DatabaseURL: "postgres://", Name: "default", Output: outputTable})
+		require.NoError(t, err)
+		require.True(t, ok)
+	})
+
+	t.Run("NotFound", func(t *testing.T) {
+		t.Parallel()
+
+		cmd, bundle := setup(t)
+
+		bundle.clientStub.queueUpdateStub = func(ctx context.Context, name string, params *river.QueueUpdateParams) (*rivertype.Queue, error) {
+			return nil, rivertype.ErrNotFound
+		}
+
+		ok, err := runCommand(ctx, t, cmd, &queueUpdateOpts{DatabaseURL: "postgres://", Metadata: `{}`, Name: "default", Output: outputTable})
+		require.NoError(t, err)
+		require.False(t, ok)
+
+		require.Equal(t, "no queue with name default", strings.TrimSpace(bundle.out.String()))
+	})
+
+	t.Run("InvalidOpts", func(t *testing.T) {
+		t.Parallel()
+
+		require.EqualError(t, (&queueUpdateOpts{DatabaseURL: "postgres://", Name: "default", Output: outputTable}).Validate(),
+			"at least one of --metadata, --concurrency-reset, or a concurrency flag like --concurrency-global-limit must be set")
+		require.EqualError(t, (&queueUpdateOpts{DatabaseURL: "postgres://", Metadata: "[]", Name: "default", Output: outputTable}).Validate(),
+			"--metadata must be a JSON object")
+		require.EqualError(t, (&queueUpdateOpts{ConcurrencyGlobalLimit: 10, ConcurrencyReset: true, DatabaseURL: "postgres://", Name: "default", Output: outputTable}).Validate(),
+			"--concurrency-reset can't be combined with other concurrency flags")
+	})
+}
+
+func TestTop(t *testing.T) {
+	t.Parallel()
+
+	ctx := context.Background()
+
+	var (
+		electedAt = time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC)
+		takenAt   = time.Date(2025, 1, 1, 0, 5, 0, 0, time.UTC)
+	)
+
+	emailQueue := &rivertype.Queue{CreatedAt: electedAt, Name: "email", UpdatedAt: electedAt}
+
+	makeStats := func(name string, completedCount, discardedCount int, runningByClient map[string]int) *river.QueueStats {
+		return &river.QueueStats{
+			CompletedCount:     completedCount,
+			CompletedPerSecond: float64(completedCount) / 300,
+			CountsByState: map[rivertype.JobState]int{
+				rivertype.JobStateAvailable: 12,
+				rivertype.JobStateRetryable: 2,
+				rivertype.JobStateRunning:   3,
+				rivertype.JobStateScheduled: 4,
+			},
+			DiscardedCount:     discardedCount,
+			OldestAvailableAge: 90*time.Second + 300*time.Millisecond,
+			Queue:              name,
+			RunningByClient:    runningByClient,
+			ThroughputWindow:   5 * time.Minute,
+		}
+	}
+
+	testSnapshot := func() *topSnapshot {
+		return &topSnapshot{
+			Leader: &riverdriver.Leader{ElectedAt: electedAt, ExpiresAt: electedAt.Add(10 * time.Second), LeaderID: "client_1"},
+			Queues: []*topQueue{
+				{Queue: testQueue, Stats: makeStats("default", 3, 1, map[string]int{"client_2": 1, "client_1": 2})},
+				{Queue: emailQueue, Stats: makeStats("email", 0, 0, map[string]int{"client_1": 1})},
+			},
+			TakenAt: takenAt,
+		}
+	}
+
+	setupClient := func() (*ClientStub, *ExecutorStub) {
+		clientStub := &ClientStub{}
+		clientStub.queueListStub = func(ctx context.Context, params *river.QueueListParams) (*river.QueueListResult, error) {
+			return &river.QueueListResult{Queues: []*rivertype.Queue{testQueue, emailQueue}}, nil
+		}
+		clientStub.queueStatsManyStub = func(ctx context.Context, names []string) ([]*river.QueueStats, error) {
+			return sliceutil.Map(names, func(name string) *river.QueueStats { return makeStats(name, 3, 1, nil) }), nil
+		}
+
+		executorStub := &ExecutorStub{}
+		executorStub.leaderGetElectedLeaderStub = func(ctx context.Context, params *riverdriver.LeaderGetElectedLeaderParams) (*riverdriver.Leader, error) {
+			return &riverdriver.Leader{ElectedAt: electedAt, ExpiresAt: electedAt.Add(10 * time.Second), LeaderID: "client_1"}, nil
+		}
+
+		return clientStub, executorStub
+	}
+
+	t.Run("Render", func(t *testing.T) {
+		t.Parallel()
+
+		state := &topState{}
+		state.setSnapshot(testSnapshot())
+
+		var out bytes.Buffer
+		require.NoError(t, renderTop(&out, state, 2*time.Second))
+
+		require.Equal(t, strings.TrimSpace(`
+river top - 2025-01-01T00:05:00Z - refreshing every 2s
+leader: client_1 (elected at 2025-01-01T00:00:00Z, expires at 2025-01-01T00:00:10Z)
+
+QUEUE    STATUS  AVAILABLE  RUNNING  SCHEDULED  RETRYABLE  OLDEST AVAILABLE  COMPLETED/S  DISCARDED  ERROR RATE
+default  paused  12         3        4          2          1m30s             0.01         1          25.0%
+email    active  12         3        4          2          1m30s             0.00         0          -
+
+completed/s, discarded, and error rate are measured over the last 5m0s
+
+CLIENT    QUEUE    RUNNING
+client_1  default  2
+client_1  email    1
+client_2  default  1
+		`), strings.TrimSpace(out.String()))
+	})
+
+	t.Run("RenderInteractive", func(t *testing.T) {
+		t.Parallel()
+
+		state := &topState{interactive: true, message: "paused queue email"}
+		state.setSnapshot(testSnapshot())
+		state.moveSelection(1)
+
+		var out bytes.Buffer
+		require.NoError(t, renderTop(&out, state, 2*time.Second))
+
+		require.Contains(t, out.String(), "\n  default  paused")
+		require.Contains(t, out.String(), "\n> email    active")
+		require.True(t, strings.HasSuffix(out.String(), "\npaused queue email\nup/down or j/k: select queue  p: pause  r: resume  q: quit\n"))
+	})
+
+	t.Run("RenderNoLeaderOrQueues", func(t *testing.T) {
+		t.Parallel()
+
+		state := &topState{refreshErr: errors.New("connection refused")}
+		state.setSnapshot(&topSnapshot{TakenAt: takenAt})
+
+		var out bytes.Buffer
+		require.NoError(t, renderTop(&out, state, 2*time.Second))
+
+		require.Equal(t, strings.TrimSpace(`
+river top - 2025-01-01T00:05:00Z - refreshing every 2s
+leader: none elected
+
+no queues found
+
+error refreshing, showing previous results: connection refused
+		`), strings.TrimSpace(out.String()))
+	})
+
+	t.Run("RunIterations", func(t *testing.T) {
+		t.Parallel()
+
+		cmd, out := withCommandBase(t, &top{})
+
+		clientStub, executorStub := setupClient()
+		cmd.GetCommandBase().GetClient = func() (ClientInterface, error) { return clientStub, nil }
+		cmd.GetCommandBase().GetExecutor = func() (ExecutorInterface, error) { return executorStub, nil }
+
+		ok, err := runCommand(ctx, t, cmd, &topOpts{DatabaseURL: "postgres://", Interval: time.Second, Iterations: 2})
+		require.NoError(t, err)
+		require.True(t, ok)
+
+		// Output isn't a terminal, so refreshes are printed one after another.
+		require.Equal(t, 2, strings.Count(out.String(), "river top - "))
+		require.Contains(t, out.String(), "leader: client_1 (elected at 2025-01-01T00:00:00Z, expires at 2025-01-01T00:00:10Z)")
+		require.Contains(t, out.String(), "default  paused  12         3        4          2          1m30s             0.01         1          25.0%")
+		require.NotContains(t, out.String(), "\x1b[")
+	})
+
+	t.Run("RunNoLeader", func(t *testing.T) {
+		t.Parallel()
+
+		cmd, out := withCommandBase(t, &top{})
+
+		clientStub, executorStub := setupClient()
+		executorStub.leaderGetElectedLeaderStub = func(ctx context.Context, params *riverdriver.LeaderGetElectedLeaderParams) (*riverdriver.Leader, error) {
+			return nil, rivertype.ErrNotFound
+		}
+		cmd.GetCommandBase().GetClient = func() (ClientInterface, error) { return clientStub, nil }
+		cmd.GetCommandBase().GetExecutor = func() (ExecutorInterface, error) { return executorStub, nil }
+
+		ok, err := runCommand(ctx, t, cmd, &topOpts{DatabaseURL: "postgres://", Interval: time.Second, Iterations: 1})
+		require.NoError(t, err)
+		require.True(t, ok)
+
+		require.Contains(t, out.String(), "leader: none elected")
+	})
+
+	t.Run("FetchSnapshotFetchesStatsOnce", func(t *testing.T) {
+		t.Parallel()
+
+		clientStub, executorStub := setupClient()
+
+		var statsCalls [][]string
+		clientStub.queueStatsManyStub = func(ctx context.Context, names []string) ([]*river.QueueStats, error) {
+			statsCalls = append(statsCalls, names)
+
+			// Returned ordered by name, unlike the queue list.
+			return []*river.QueueStats{makeStats("default", 3, 1, nil), makeStats("email", 0, 0, nil)}, nil
+		}
+		clientStub.queueListStub = func(ctx context.Context, params *river.QueueListParams) (*river.QueueListResult, error) {
+			return &river.QueueListResult{Queues: []*rivertype.Queue{emailQueue, testQueue}}, nil
+		}
+
+		snapshot, err := fetchTopSnapshot(ctx, clientStub, executorStub, "", takenAt)
+		require.NoError(t, err)
+		require.Equal(t, [][]string{{"email", "default"}}, statsCalls)
+		require.Len(t, snapshot.Queues, 2)
+		require.Equal(t, "email", snapshot.Queues[0].Queue.Name)
+		require.Equal(t, "email", snapshot.Queues[0].Stats.Queue)
+		require.Equal(t, "default", snapshot.Queues[1].Queue.Name)
+		require.Equal(t, "default", snapshot.Queues[1].Stats.Queue)
+		require.Equal(t, 1, snapshot.Queues[1].Stats.DiscardedCount)
+	})
+
+	t.Run("RunErrorOnFirstRefresh", func(t *testing.T) {
+		t.Parallel()
+
+		cmd, _ := withCommandBase(t, &top{})
+
+		clientStub, executorStub := setupClient()
+		clientStub.queueListStub = func(ctx context.Context, params *river.QueueListParams) (*river.QueueListResult, error) {
+			return nil, errors.New("connection refused")
+		}
+		cmd.GetCommandBase().GetClient = func() (ClientInterface, error) { return clientStub, nil }
+		cmd.GetCommandBase().GetExecutor = func() (ExecutorInterface, error) { return executorStub, nil }
+
+		_, err := runCommand(ctx, t, cmd, &topOpts{DatabaseURL: "postgres://", Interval: time.Second, Iterations: 1})
+		require.EqualError(t, err, "error listing queues: connection refused")
+	})
+
+	t.Run("HandleKeySelection", func(t *testing.T) {
+		t.Parallel()
+
+		state := &topState{interactive: true}
+		state.setSnapshot(testSnapshot())
+		require.Equal(t, "default", state.selected)
+
+		cmd := &top{}
+		for _, tt := range []struct {
+			key      topKey
+			selected string
+		}{
+			{topKeyUp, "default"}, // stops at the first queue
+			{topKeyDown, "email"},
+			{topKeyDown, "email"}, // stops at the last queue
+			{topKeyUp, "default"},
+		} {
+			require.False(t, cmd.handleTopKey(ctx, &ClientStub{}, state, tt.key))
+			require.Equal(t, tt.selected, state.selected)
+		}
+
+		// Selection is kept across snapshots as long as the queue is still
+		// present, and otherwise reset to the first queue.
+		state.moveSelection(1)
+		state.setSnapshot(testSnapshot())
+		require.Equal(t, "email", state.selected)
+
+		snapshot := testSnapshot()
+		snapshot.Queues = snapshot.Queues[:1]
+		state.setSnapshot(snapshot)
+		require.Equal(t, "default", state.selected)
+	})
+
+	t.Run("HandleKeyPauseAndResume", func(t *testing.T) {
+		t.Parallel()
+
+		var paused, resumed []string
+
+		clientStub := &ClientStub{}
+		clientStub.queuePauseStub = func(ctx context.Context, name string, opts *river.QueuePauseOpts) error {
+			paused = append(paused, name)
+			return nil
+		}
+		clientStub.queueResumeStub = func(ctx context.Context, name string, opts *river.QueuePauseOpts) error {
+			resumed = append(resumed, name)
+			return errors.New("connection refused")
+		}
+
+		state := &topState{interactive: true}
+		state.setSnapshot(testSnapshot())
+		state.moveSelection(1)
+
+		cmd := &top{}
+
+		require.True(t, cmd.handleTopKey(ctx, clientStub, state, topKeyPause))
+		require.Equal(t, []string{"email"}, paused)
+		require.Equal(t, "paused queue email", state.message)
+
+		require.False(t, cmd.handleTopKey(ctx, clientStub, state, topKeyResume))
+		require.Equal(t, []string{"email"}, resumed)
+		require.Equal(t, "error resuming queue email: connection refused", state.message)
+	})
+
+	t.Run("HandleKeyPauseWithoutQueues", func(t *testing.T) {
+		t.Parallel()
+
+		state := &topState{interactive: true}
+		state.setSnapshot(&topSnapshot{TakenAt: takenAt})
+
+		require.False(t, (&top{}).handleTopKey(ctx, &ClientStub{}, state, topKeyPause))
+		require.Empty(t, state.message)
+	})
+
+	t.Run("ParseKeys", func(t *testing.T) {
+		t.Parallel()
+
+		require.Equal(t,
+			[]topKey{topKeyDown, topKeyUp, topKeyUp, topKeyDown, topKeyPause, topKeyResume, topKeyQuit, topKeyQuit},
+			parseTopKeys([]byte("jk\x1b[A\x1b[Bprxq\x03")),
+		)
+		require.Empty(t, parseTopKeys([]byte("\x1b[C\x1b")))
+	})
+
+	t.Run("Validate", func(t *testing.T) {
+		t.Parallel()
+
+		require.NoError(t, (&topOpts{DatabaseURL: "postgres://", Interval: time.Second}).Validate())
+		require.EqualError(t, (&topOpts{DatabaseURL: "postgres://", Interval: 500 * time.Millisecond}).Validate(),
+			"--interval must be at least 1s")
+		require.EqualError(t, (&topOpts{DatabaseURL: "postgres://", Interval: time.Second, Iterations: -1}).Validate(),
+			"--iterations must be greater than or equal to zero")
+	})
 }
 
 func TestVersion(t *testing.T) {
@@ -336,9 +1972,12 @@ func withCommandBase[TCommand Command[TOpts], TOpts CommandOpts](t *testing.T, c
 
 	var out bytes.Buffer
 	cmd.SetCommandBase(&CommandBase{
+		In:     strings.NewReader(""),
 		Logger: riversharedtest.Logger(t),
 		Out:    &out,
 
+		GetClient:   func() (ClientInterface, error) { return &ClientStub{}, nil },
+		GetExecutor: func() (ExecutorInterface, error) { return &ExecutorStub{}, nil },
 		GetMigrator: func(config *rivermigrate.Config) (MigratorInterface, error) { return &MigratorStub{}, nil },
 	})
 	return cmd, &out
@@ -0,0 +1,230 @@
+package river
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+
+	"github.com/riverqueue/river/internal/dbunique"
+	"github.com/riverqueue/river/rivertype"
+)
+
+const (
+	// metadataKeyPartition is the job metadata key under which the values of a
+	// job's partition fields (those tagged with `river:"partition"`) are stored
+	// on insert.
+	metadataKeyPartition = "river:partition"
+
+	// metadataKeyQueueConcurrency is the queue metadata key under which a
+	// concurrency override set with Client.QueueUpdate is stored. It's reserved
+	// for River's use.
+	metadataKeyQueueConcurrency = "river:concurrency"
+)
+
+// ConcurrencyConfig configures limits on how many of a queue's jobs may be
+// worked at once across every client sharing the same database, as opposed to
+// QueueConfig.MaxWorkers, which only limits jobs worked by a single client.
+//
+// Limits are enforced when jobs are fetched. Fetches for a queue with a global
+// limit are serialized with an advisory lock so that clients can't overshoot
+// the limit by fetching at the same time, which adds a small amount of
+// overhead and contention compared to a queue without one. Jobs held back by a
+// limit are picked up on a subsequent fetch once running jobs finish, which may
+// take up to Config.FetchPollInterval.
+type ConcurrencyConfig struct {
+	// GlobalLimit is the maximum number of the queue's jobs that may be running
+	// at once across all clients. Jobs left running by a client that crashed
+	// count against the limit until they're rescued.
+	//
+	// Zero means no limit.
+	GlobalLimit int
+
+	// Partition divides the queue's jobs into partitions (e.g. one per
+	// customer), each of which may have at most Partition.Limit jobs running at
+	// once across all clients. This prevents a single partition from occupying
+	// every worker in the queue. Applies in addition to GlobalLimit.
+	Partition PartitionConfig
+}
+
+func (c *ConcurrencyConfig) validate(queueName string) error {
+	if c.GlobalLimit < 0 {
+		return fmt.Errorf("invalid global concurrency limit for queue %q: %d", queueName, c.GlobalLimit)
+	}
+
+	if err := c.Partition.validate(); err != nil {
+		return fmt.Errorf("invalid concurrency partition for queue %q: %w", queueName, err)
+	}
+
+	return nil
+}
+
+// PartitionConfig configures how a queue's jobs are partitioned for the
+// purposes of concurrency limits. Jobs are in the same partition if they match
+// on every enabled dimension.
+//
+// Partition limits are enforced when jobs are fetched by counting running jobs
+// in each partition, which requires scanning the queue's available and running
+// jobs. Expect fetches to be slower for queues with very large numbers of
+// available jobs.
+type PartitionConfig struct {
+	// ByArgs partitions jobs by the values of their args fields tagged with
+	// `river:"partition"`, similarly to how `river:"unique"` selects fields for
+	// UniqueOpts.ByArgs:
+	//
+	// 	type TenantJobArgs struct {
+	// 		CustomerID int `json:"customer_id" river:"partition"`
+	// 	}
+	//
+	// Partition field values are extracted when a job is inserted and stored
+	// in its metadata. Jobs whose args have no partition fields (including jobs
+	// inserted by versions of River without partition support) share a single
+	// partition.
+	ByArgs bool
+
+	// ByKind partitions jobs by kind. Combined with ByArgs, jobs are in the
+	// same partition only if both their kind and partition fields match.
+	ByKind bool
+
+	// Limit is the maximum number of jobs from any single partition that may be
+	// running at once across all clients.
+	//
+	// Zero means no limit, but must be set if ByArgs or ByKind is.
+	Limit int
+}
+
+func (c *PartitionConfig) validate() error {
+	if c.Limit < 0 {
+		return fmt.Errorf("invalid partition limit: %d", c.Limit)
+	}
+
+	if c.ByArgs || c.ByKind {
+		if c.Limit == 0 {
+			return errors.New("partition Limit must be set along with ByArgs or ByKind")
+		}
+	} else if c.Limit > 0 {
+		return errors.New("partition Limit requires ByArgs or ByKind")
+	}
+
+	return nil
+}
+
+// concurrencyMetadata is the serialized form of ConcurrencyConfig as stored in
+// queue metadata.
+type concurrencyMetadata struct {
+	GlobalLimit int                `json:"global_limit,omitempty"`
+	Partition   *partitionMetadata `json:"partition,omitempty"`
+}
+
+// partitionMetadata is the serialized form of PartitionConfig as stored in
+// queue metadata.
+type partitionMetadata struct {
+	ByArgs bool `json:"by_args,omitempty"`
+	ByKind bool `json:"by_kind,omitempty"`
+	Limit  int  `json:"limit"`
+}
+
+// concurrencyFromQueueMetadata returns the concurrency configuration stored in
+// the given queue metadata, or defaultConfig (which may be nil) if the
+// metadata doesn't contain an override.
+func concurrencyFromQueueMetadata(metadata []byte, defaultConfig *ConcurrencyConfig) (*ConcurrencyConfig, error) {
+	if len(metadata) < 1 {
+		return defaultConfig, nil
+	}
+
+	var metadataMap map[string]json.RawMessage
+	if err := json.Unmarshal(metadata, &metadataMap); err != nil {
+		return nil, fmt.Errorf("error unmarshaling queue metadata: %w", err)
+	}
+
+	rawConcurrency, ok := metadataMap[metadataKeyQueueConcurrency]
+	if !ok {
+		return defaultConfig, nil
+	}
+
+	var concurrency concurrencyMetadata
+	if err := json.Unmarshal(rawConcurrency, &concurrency); err != nil {
+		return nil, fmt.Errorf("error unmarshaling queue concurrency metadata: %w", err)
+	}
+
+	config := &ConcurrencyConfig{GlobalLimit: concurrency.GlobalLimit}
+	if concurrency.Partition != nil {
+		config.Partition = PartitionConfig{
+			ByArgs: concurrency.Partition.ByArgs,
+			ByKind: concurrency.Partition.ByKind,
+			Limit:  concurrency.Partition.Limit,
+		}
+	}
+
+	return config, nil
+}
+
+// queueMetadataWithConcurrency returns a copy of the given queue metadata with
+// its concurrency override replaced by the given configuration, or removed if
+// the configuration is empty.
+func queueMetadataWithConcurrency(metadata []byte, concurrency *ConcurrencyConfig) ([]byte, error) {
+	metadataMap := make(map[string]json.RawMessage)
+	if len(metadata) > 0 {
+		if err := json.Unmarshal(metadata, &metadataMap); err != nil {
+			return nil, fmt.Errorf("error unmarshaling queue metadata: %w", err)
+		}
+	}
+
+	if concurrency == nil || *concurrency == (ConcurrencyConfig{}) {
+		delete(metadataMap, metadataKeyQueueConcurrency)
+	} else {
+		concurrencyMeta := concurrencyMetadata{GlobalLimit: concurrency.GlobalLimit}
+		if concurrency.Partition != (PartitionConfig{}) {
+			concurrencyMeta.Partition = &partitionMetadata{
+				ByArgs: concurrency.Partition.ByArgs,
+				ByKind: concurrency.Partition.ByKind,
+				Limit:  concurrency.Partition.Limit,
+			}
+		}
+
+		rawConcurrency, err := json.Marshal(concurrencyMeta)
+		if err != nil {
+			return nil, err
+		}
+		metadataMap[metadataKeyQueueConcurrency] = rawConcurrency
+	}
+
+	return json.Marshal(metadataMap)
+}
+
+// queueMetadataCarryConcurrency returns newMetadata with the concurrency
+// override from oldMetadata (if any) carried over, so that replacing a queue's
+// metadata doesn't inadvertently remove an override.
+func queueMetadataCarryConcurrency(oldMetadata, newMetadata []byte) ([]byte, error) {
+	concurrency, err := concurrencyFromQueueMetadata(oldMetadata, nil)
+	if err != nil {
+		return nil, err
+	}
+	if concurrency == nil {
+		return newMetadata, nil
+	}
+
+	return queueMetadataWithConcurrency(newMetadata, concurrency)
+}
+
+// metadataWithPartitionKey returns the metadata of the given insert params with
+// the values of the args' partition fields stored under metadataKeyPartition.
+// Metadata is returned unchanged if the args have no partition fields.
+func metadataWithPartitionKey(params *rivertype.JobInsertParams) ([]byte, error) {
+	partitionKey, err := dbunique.PartitionKey(params)
+	if err != nil {
+		return nil, err
+	}
+	if partitionKey == nil {
+		return params.Metadata, nil
+	}
+
+	metadataMap := make(map[string]json.RawMessage)
+	if len(params.Metadata) > 0 {
+		if err := json.Unmarshal(params.Metadata, &metadataMap); err != nil {
+			return nil, fmt.Errorf("error unmarshaling metadata: %w", err)
+		}
+	}
+	metadataMap[metadataKeyPartition] = partitionKey
+
+	return json.Marshal(metadataMap)
+}
@@ -0,0 +1,141 @@
+package river
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/riverqueue/river/rivertype"
+)
+
+func TestConcurrencyFromQueueMetadata(t *testing.T) {
+	t.Parallel()
+
+	defaultConfig := &ConcurrencyConfig{GlobalLimit: 5}
+
+	t.Run("EmptyMetadata", func(t *testing.T) {
+		t.Parallel()
+
+		concurrency, err := concurrencyFromQueueMetadata(nil, defaultConfig)
+		require.NoError(t, err)
+		require.Equal(t, defaultConfig, concurrency)
+	})
+
+	t.Run("NoOverride", func(t *testing.T) {
+		t.Parallel()
+
+		concurrency, err := concurrencyFromQueueMetadata([]byte(`{"foo":"bar"}`), defaultConfig)
+		require.NoError(t, err)
+		require.Equal(t, defaultConfig, concurrency)
+
+		concurrency, err = concurrencyFromQueueMetadata([]byte(`{"foo":"bar"}`), nil)
+		require.NoError(t, err)
+		require.Nil(t, concurrency)
+	})
+
+	t.Run("Override", func(t *testing.T) {
+		t.Parallel()
+
+		concurrency, err := concurrencyFromQueueMetadata([]byte(`{"river:concurrency":{"global_limit":2}}`), defaultConfig)
+		require.NoError(t, err)
+		require.Equal(t, &ConcurrencyConfig{GlobalLimit: 2}, concurrency)
+	})
+
+	t.Run("OverrideWithPartition", func(t *testing.T) {
+		t.Parallel()
+
+		concurrency, err := concurrencyFromQueueMetadata([]byte(`{"river:concurrency":{"partition":{"by_args":true,"limit":1}}}`), defaultConfig)
+		require.NoError(t, err)
+		require.Equal(t, &ConcurrencyConfig{Partition: PartitionConfig{ByArgs: true, Limit: 1}}, concurrency)
+	})
+
+	t.Run("InvalidMetadata", func(t *testing.T) {
+		t.Parallel()
+
+		_, err := concurrencyFromQueueMetadata([]byte(`{"river:concurrency":"bad"}`), defaultConfig)
+		require.ErrorContains(t, err, "error unmarshaling queue concurrency metadata")
+	})
+}
+
+func TestQueueMetadataWithConcurrency(t *testing.T) {
+	t.Parallel()
+
+	metadata, err := queueMetadataWithConcurrency([]byte(`{"foo":"bar"}`), &ConcurrencyConfig{GlobalLimit: 3})
+	require.NoError(t, err)
+	require.JSONEq(t, `{"foo":"bar","river:concurrency":{"global_limit":3}}`, string(metadata))
+
+	metadata, err = queueMetadataWithConcurrency(metadata, &ConcurrencyConfig{})
+	require.NoError(t, err)
+	require.JSONEq(t, `{"foo":"bar"}`, string(metadata))
+
+	metadata, err = queueMetadataWithConcurrency(nil, &ConcurrencyConfig{GlobalLimit: 3})
+	require.NoError(t, err)
+	require.JSONEq(t, `{"river:concurrency":{"global_limit":3}}`, string(metadata))
+
+	metadata, err = queueMetadataWithConcurrency(nil, &ConcurrencyConfig{Partition: PartitionConfig{ByKind: true, Limit: 2}})
+	require.NoError(t, err)
+	require.JSONEq(t, `{"river:concurrency":{"partition":{"by_kind":true,"limit":2}}}`, string(metadata))
+}
+
+func TestQueueMetadataCarryConcurrency(t *testing.T) {
+	t.Parallel()
+
+	metadata, err := queueMetadataCarryConcurrency([]byte(`{"river:concurrency":{"global_limit":3}}`), []byte(`{"foo":"bar"}`))
+	require.NoError(t, err)
+	require.JSONEq(t, `{"foo":"bar","river:concurrency":{"global_limit":3}}`, string(metadata))
+
+	metadata, err = queueMetadataCarryConcurrency([]byte(`{}`), []byte(`{"foo":"bar"}`))
+	require.NoError(t, err)
+	require.JSONEq(t, `{"foo":"bar"}`, string(metadata))
+}
+
+func TestPartitionConfigValidate(t *testing.T) {
+	t.Parallel()
+
+	require.NoError(t, (&PartitionConfig{}).validate())
+	require.NoError(t, (&PartitionConfig{ByArgs: true, Limit: 1}).validate())
+	require.NoError(t, (&PartitionConfig{ByArgs: true, ByKind: true, Limit: 1}).validate())
+
+	require.EqualError(t, (&PartitionConfig{ByKind: true, Limit: -1}).validate(), "invalid partition limit: -1")
+	require.EqualError(t, (&PartitionConfig{ByKind: true}).validate(), "partition Limit must be set along with ByArgs or ByKind")
+	require.EqualError(t, (&PartitionConfig{Limit: 1}).validate(), "partition Limit requires ByArgs or ByKind")
+}
+
+func TestMetadataWithPartitionKey(t *testing.T) {
+	t.Parallel()
+
+	type PartitionedArgs struct {
+		JobArgsReflectKind[PartitionedArgs]
+		CustomerID int    `json:"customer_id" river:"partition"`
+		Message    string `json:"message"`
+	}
+
+	type UnpartitionedArgs struct {
+		JobArgsReflectKind[UnpartitionedArgs]
+		CustomerID int `json:"customer_id"`
+	}
+
+	t.Run("PartitionFields", func(t *testing.T) {
+		t.Parallel()
+
+		metadata, err := metadataWithPartitionKey(&rivertype.JobInsertParams{
+			Args:        PartitionedArgs{CustomerID: 123, Message: "hello"},
+			EncodedArgs: []byte(`{"customer_id":123,"message":"hello"}`),
+			Metadata:    []byte(`{"foo":"bar"}`),
+		})
+		require.NoError(t, err)
+		require.JSONEq(t, `{"foo":"bar","river:partition":{"customer_id":123}}`, string(metadata))
+	})
+
+	t.Run("NoPartitionFields", func(t *testing.T) {
+		t.Parallel()
+
+		metadata, err := metadataWithPartitionKey(&rivertype.JobInsertParams{
+			Args:        UnpartitionedArgs{CustomerID: 123},
+			EncodedArgs: []byte(`{"customer_id":123}`),
+			Metadata:    []byte(`{"foo":"bar"}`),
+		})
+		require.NoError(t, err)
+		require.Equal(t, `{"foo":"bar"}`, string(metadata))
+	})
+}
@@ -0,0 +1,142 @@
+package river
+
+import (
+	"time"
+)
+
+// DeadLetterListParams specifies the parameters for a DeadLetterList query. It
+// must be initialized with NewDeadLetterListParams. Params can be built by
+// chaining methods on the DeadLetterListParams object:
+//
+//	params := NewDeadLetterListParams().First(100).Kinds("email_send")
+type DeadLetterListParams struct {
+	afterID         int64
+	kinds           []string
+	paginationCount int32
+	queues          []string
+}
+
+// NewDeadLetterListParams creates a new DeadLetterListParams to return dead
+// letters sorted by ID in ascending order, returning 100 dead letters at most.
+func NewDeadLetterListParams() *DeadLetterListParams {
+	return &DeadLetterListParams{
+		paginationCount: 100,
+	}
+}
+
+func (p *DeadLetterListParams) copy() *DeadLetterListParams {
+	return &DeadLetterListParams{
+		afterID:         p.afterID,
+		kinds:           append([]string(nil), p.kinds...),
+		paginationCount: p.paginationCount,
+		queues:          append([]string(nil), p.queues...),
+	}
+}
+
+// After returns an updated filter set that will only return dead letters with
+// an ID greater than the given one. Use the ID of the last dead letter of a
+// previous page to fetch the next one.
+func (p *DeadLetterListParams) After(id int64) *DeadLetterListParams {
+	result := p.copy()
+	result.afterID = id
+	return result
+}
+
+// First returns an updated filter set that will only return the first count
+// dead letters.
+//
+// Count must be between 1 and 10000, inclusive, or this will panic.
+func (p *DeadLetterListParams) First(count int) *DeadLetterListParams {
+	if count <= 0 {
+		panic("count must be > 0")
+	}
+	if count > 10000 {
+		panic("count must be <= 10000")
+	}
+	result := p.copy()
+	result.paginationCount = int32(count)
+	return result
+}
+
+// Kinds returns an updated filter set that will only return dead letters of
+// the given kinds.
+func (p *DeadLetterListParams) Kinds(kinds ...string) *DeadLetterListParams {
+	result := p.copy()
+	result.kinds = make([]string, len(kinds))
+	copy(result.kinds, kinds)
+	return result
+}
+
+// Queues returns an updated filter set that will only return dead letters
+// from the given queues.
+func (p *DeadLetterListParams) Queues(queues ...string) *DeadLetterListParams {
+	result := p.copy()
+	result.queues = make([]string, len(queues))
+	copy(result.queues, queues)
+	return result
+}
+
+// DeadLetterPurgeParams specifies the parameters for a DeadLetterPurge
+// operation. It must be initialized with NewDeadLetterPurgeParams. Params can be
+// built by chaining methods on the DeadLetterPurgeParams object:
+//
+//	params := NewDeadLetterPurgeParams().DeadLetteredBefore(time.Now().Add(-30 * 24 * time.Hour))
+//
+// Filters are combined so that only dead letters matching all of them are
+// purged. Params without any filters purge all dead letters.
+type DeadLetterPurgeParams struct {
+	deadLetteredBefore *time.Time
+	ids                []int64
+	kinds              []string
+	queues             []string
+}
+
+// NewDeadLetterPurgeParams creates a new DeadLetterPurgeParams which purges all
+// dead letters until filters are added.
+func NewDeadLetterPurgeParams() *DeadLetterPurgeParams {
+	return &DeadLetterPurgeParams{}
+}
+
+func (p *DeadLetterPurgeParams) copy() *DeadLetterPurgeParams {
+	return &DeadLetterPurgeParams{
+		deadLetteredBefore: p.deadLetteredBefore,
+		ids:                append([]int64(nil), p.ids...),
+		kinds:              append([]string(nil), p.kinds...),
+		queues:             append([]string(nil), p.queues...),
+	}
+}
+
+// DeadLetteredBefore returns an updated filter set that will only purge dead
+// letters that were moved to the dead letter table before the given time.
+func (p *DeadLetterPurgeParams) DeadLetteredBefore(before time.Time) *DeadLetterPurgeParams {
+	result := p.copy()
+	result.deadLetteredBefore = &before
+	return result
+}
+
+// IDs returns an updated filter set that will only purge dead letters with
+// the given IDs.
+func (p *DeadLetterPurgeParams) IDs(ids ...int64) *DeadLetterPurgeParams {
+	result := p.copy()
+	result.ids = make([]int64, len(ids))
+	copy(result.ids, ids)
+	return result
+}
+
+// Kinds returns an updated filter set that will only purge dead letters of the
+// given kinds.
+func (p *DeadLetterPurgeParams) Kinds(kinds ...string) *DeadLetterPurgeParams {
+	result := p.copy()
+	result.kinds = make([]string, len(kinds))
+	copy(result.kinds, kinds)
+	return result
+}
+
+// Queues returns an updated filter set that will only purge dead letters from
+// the given queues.
+func (p *DeadLetterPurgeParams) Queues(queues ...string) *DeadLetterPurgeParams {
+	result := p.copy()
+	result.queues = make([]string, len(queues))
+	copy(result.queues, queues)
+	return result
+}
@@ -0,0 +1,59 @@
+package river
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestDeadLetterListParams(t *testing.T) {
+	t.Parallel()
+
+	t.Run("Defaults", func(t *testing.T) {
+		t.Parallel()
+
+		params := NewDeadLetterListParams()
+		require.Zero(t, params.afterID)
+		require.Empty(t, params.kinds)
+		require.Equal(t, int32(100), params.paginationCount)
+		require.Empty(t, params.queues)
+	})
+
+	t.Run("ChainedWithoutMutatingOriginal", func(t *testing.T) {
+		t.Parallel()
+
+		params := NewDeadLetterListParams()
+		chained := params.After(123).First(10).Kinds("kind1", "kind2").Queues("queue1")
+		require.Equal(t, int64(123), chained.afterID)
+		require.Equal(t, []string{"kind1", "kind2"}, chained.kinds)
+		require.Equal(t, int32(10), chained.paginationCount)
+		require.Equal(t, []string{"queue1"}, chained.queues)
+
+		require.Zero(t, params.afterID)
+		require.Empty(t, params.kinds)
+	})
+
+	t.Run("FirstOutOfRange", func(t *testing.T) {
+		t.Parallel()
+
+		require.PanicsWithValue(t, "count must be > 0", func() { NewDeadLetterListParams().First(0) })
+		require.PanicsWithValue(t, "count must be <= 10000", func() { NewDeadLetterListParams().First(10001) })
+	})
+}
+
+func TestDeadLetterPurgeParams(t *testing.T) {
+	t.Parallel()
+
+	before := time.Now()
+
+	params := NewDeadLetterPurgeParams()
+	chained := params.DeadLetteredBefore(before).IDs(1, 2).Kinds("kind1").Queues("queue1")
+	require.Equal(t, &before, chained.deadLetteredBefore)
+	require.Equal(t, []int64{1, 2}, chained.ids)
+	require.Equal(t, []string{"kind1"}, chained.kinds)
+	require.Equal(t, []string{"queue1"}, chained.queues)
+
+	require.Nil(t, params.deadLetteredBefore)
+	require.Empty(t, params.ids)
+}
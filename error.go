@@ -21,6 +21,10 @@ func JobCancel(err error) error {
 	return rivertype.JobCancel(err)
 }
 
+// JobNotUpdatableError is returned by JobUpdate when a job is running or has
+// already been finalized, and therefore can't be updated.
+type JobNotUpdatableError = rivertype.JobNotUpdatableError
+
 // JobSnoozeError is the error type returned by JobSnooze. It should not be
 // initialized directly, but is returned from the [JobSnooze] function and can
 // be used for test assertions.
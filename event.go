@@ -17,18 +17,67 @@ const (
 	// EventKindJobCompleted occurs when a job is completed.
 	EventKindJobCompleted EventKind = "job_completed"
 
+	// EventKindJobDiscarded occurs when a job fails for the last time and is
+	// discarded. Discarded jobs also produce an EventKindJobFailed event, so
+	// subscriptions listening for both will receive two events for the job.
+	EventKindJobDiscarded EventKind = "job_discarded"
+
 	// EventKindJobFailed occurs when a job fails. Occurs both when a job fails
 	// and will be retried and when a job fails for the last time and will be
 	// discarded. Callers can use job fields like `Attempt` and `State` to
-	// differentiate each type of occurrence.
+	// differentiate each type of occurrence, or subscribe to
+	// EventKindJobDiscarded to receive only the latter.
 	EventKindJobFailed EventKind = "job_failed"
 
+	// EventKindJobInserted occurs when a job is inserted by the client with
+	// Insert or InsertMany, once the insert has been committed. Jobs inserted
+	// in a caller's transaction with InsertTx or InsertManyTx don't produce
+	// events because there's no way to know whether the transaction commits.
+	// Neither do jobs inserted with InsertManyFast, or jobs skipped as
+	// duplicates of an existing unique job.
+	EventKindJobInserted EventKind = "job_inserted"
+
+	// EventKindJobProgress occurs when progress or a checkpoint recorded by a
+	// running job with RecordProgress or Checkpoint is flushed to the
+	// database. The event's job reflects the job's updated metadata.
+	EventKindJobProgress EventKind = "job_progress"
+
+	// EventKindJobRescued occurs when a job that was stuck running is rescued
+	// by the rescuer. The rescuer only runs on the elected leader, so these
+	// events are only emitted by the client that's currently leader. The
+	// event's job reflects its state after being rescued.
+	EventKindJobRescued EventKind = "job_rescued"
+
 	// EventKindJobSnoozed occurs when a job is snoozed.
 	EventKindJobSnoozed EventKind = "job_snoozed"
 
+	// EventKindJobStarted occurs when a job is fetched by the client and
+	// starts being worked.
+	EventKindJobStarted EventKind = "job_started"
+
+	// EventKindLeadershipGained occurs when the client is elected leader.
+	EventKindLeadershipGained EventKind = "leadership_gained"
+
+	// EventKindLeadershipLost occurs when the client stops being leader, like
+	// when it resigns on shutdown or fails to reelect itself.
+	EventKindLeadershipLost EventKind = "leadership_lost"
+
+	// EventKindPeriodicJobEnqueued occurs when a periodic job is inserted by
+	// the periodic job enqueuer. The enqueuer only runs on the elected leader,
+	// so these events are only emitted by the client that's currently leader.
+	EventKindPeriodicJobEnqueued EventKind = "periodic_job_enqueued"
+
+	// EventKindQueueAdded occurs when a queue is added to the client with
+	// QueueBundle.Add.
+	EventKindQueueAdded EventKind = "queue_added"
+
 	// EventKindQueuePaused occurs when a queue is paused.
 	EventKindQueuePaused EventKind = "queue_paused"
 
+	// EventKindQueueRemoved occurs when a queue is removed from the client with
+	// QueueBundle.Remove.
+	EventKindQueueRemoved EventKind = "queue_removed"
+
 	// EventKindQueueResumed occurs when a queue is resumed.
 	EventKindQueueResumed EventKind = "queue_resumed"
 )
@@ -37,12 +86,22 @@ const (
 // exported because end users should have no way of subscribing to all known
 // kinds for forward compatibility reasons.
 var allKinds = map[EventKind]struct{}{ //nolint:gochecknoglobals
-	EventKindJobCancelled: {},
-	EventKindJobCompleted: {},
-	EventKindJobFailed:    {},
-	EventKindJobSnoozed:   {},
-	EventKindQueuePaused:  {},
-	EventKindQueueResumed: {},
+	EventKindJobCancelled:        {},
+	EventKindJobCompleted:        {},
+	EventKindJobDiscarded:        {},
+	EventKindJobFailed:           {},
+	EventKindJobInserted:         {},
+	EventKindJobProgress:         {},
+	EventKindJobRescued:          {},
+	EventKindJobSnoozed:          {},
+	EventKindJobStarted:          {},
+	EventKindLeadershipGained:    {},
+	EventKindLeadershipLost:      {},
+	EventKindPeriodicJobEnqueued: {},
+	EventKindQueueAdded:          {},
+	EventKindQueuePaused:         {},
+	EventKindQueueRemoved:        {},
+	EventKindQueueResumed:        {},
 }
 
 // Event wraps an event that occurred within a River client, like a job being
@@ -53,13 +112,14 @@ type Event struct {
 	// requested when creating a subscription with Subscribe.
 	Kind EventKind
 
-	// Job contains job-related information.
+	// Job contains job-related information. Set for job events and for
+	// EventKindPeriodicJobEnqueued.
 	Job *rivertype.JobRow
 
 	// JobStats are statistics about the run of a job.
 	JobStats *JobStatistics
 
-	// Queue contains queue-related information.
+	// Queue contains queue-related information. Set for queue events.
 	Queue *rivertype.Queue
 }
 
@@ -23,6 +23,36 @@ func (f HookInsertBeginFunc) InsertBegin(ctx context.Context, params *rivertype.
 
 func (f HookInsertBeginFunc) IsHook() bool { return true }
 
+// HookInsertEndFunc is a convenience helper for implementing
+// rivertype.HookInsertEnd using a simple function instead of a struct.
+type HookInsertEndFunc func(ctx context.Context, params *rivertype.JobInsertParams, result *rivertype.JobInsertResult) error
+
+func (f HookInsertEndFunc) InsertEnd(ctx context.Context, params *rivertype.JobInsertParams, result *rivertype.JobInsertResult) error {
+	return f(ctx, params, result)
+}
+
+func (f HookInsertEndFunc) IsHook() bool { return true }
+
+// HookJobRescuedFunc is a convenience helper for implementing
+// rivertype.HookJobRescued using a simple function instead of a struct.
+type HookJobRescuedFunc func(ctx context.Context, job *rivertype.JobRow)
+
+func (f HookJobRescuedFunc) JobRescued(ctx context.Context, job *rivertype.JobRow) {
+	f(ctx, job)
+}
+
+func (f HookJobRescuedFunc) IsHook() bool { return true }
+
+// HookJobStateChangeFunc is a convenience helper for implementing
+// rivertype.HookJobStateChange using a simple function instead of a struct.
+type HookJobStateChangeFunc func(ctx context.Context, job *rivertype.JobRow)
+
+func (f HookJobStateChangeFunc) JobStateChange(ctx context.Context, job *rivertype.JobRow) {
+	f(ctx, job)
+}
+
+func (f HookJobStateChangeFunc) IsHook() bool { return true }
+
 // HookWorkBeginFunc is a convenience helper for implementing
 // rivertype.HookworkBegin using a simple function instead of a struct.
 type HookWorkBeginFunc func(ctx context.Context, job *rivertype.JobRow) error
@@ -32,3 +62,13 @@ func (f HookWorkBeginFunc) WorkBegin(ctx context.Context, job *rivertype.JobRow)
 }
 
 func (f HookWorkBeginFunc) IsHook() bool { return true }
+
+// HookWorkEndFunc is a convenience helper for implementing
+// rivertype.HookWorkEnd using a simple function instead of a struct.
+type HookWorkEndFunc func(ctx context.Context, job *rivertype.JobRow, result *rivertype.JobWorkResult)
+
+func (f HookWorkEndFunc) WorkEnd(ctx context.Context, job *rivertype.JobRow, result *rivertype.JobWorkResult) {
+	f(ctx, job, result)
+}
+
+func (f HookWorkEndFunc) IsHook() bool { return true }
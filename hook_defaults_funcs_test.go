@@ -11,6 +11,22 @@ var (
 	_ rivertype.Hook            = HookInsertBeginFunc(func(ctx context.Context, params *rivertype.JobInsertParams) error { return nil })
 	_ rivertype.HookInsertBegin = HookInsertBeginFunc(func(ctx context.Context, params *rivertype.JobInsertParams) error { return nil })
 
+	_ rivertype.Hook = HookInsertEndFunc(func(ctx context.Context, params *rivertype.JobInsertParams, result *rivertype.JobInsertResult) error {
+		return nil
+	})
+	_ rivertype.HookInsertEnd = HookInsertEndFunc(func(ctx context.Context, params *rivertype.JobInsertParams, result *rivertype.JobInsertResult) error {
+		return nil
+	})
+
+	_ rivertype.Hook           = HookJobRescuedFunc(func(ctx context.Context, job *rivertype.JobRow) {})
+	_ rivertype.HookJobRescued = HookJobRescuedFunc(func(ctx context.Context, job *rivertype.JobRow) {})
+
+	_ rivertype.Hook               = HookJobStateChangeFunc(func(ctx context.Context, job *rivertype.JobRow) {})
+	_ rivertype.HookJobStateChange = HookJobStateChangeFunc(func(ctx context.Context, job *rivertype.JobRow) {})
+
 	_ rivertype.Hook          = HookWorkBeginFunc(func(ctx context.Context, job *rivertype.JobRow) error { return nil })
 	_ rivertype.HookWorkBegin = HookWorkBeginFunc(func(ctx context.Context, job *rivertype.JobRow) error { return nil })
+
+	_ rivertype.Hook        = HookWorkEndFunc(func(ctx context.Context, job *rivertype.JobRow, result *rivertype.JobWorkResult) {})
+	_ rivertype.HookWorkEnd = HookWorkEndFunc(func(ctx context.Context, job *rivertype.JobRow, result *rivertype.JobWorkResult) {})
 )
@@ -38,6 +38,9 @@ type InsertOpts struct {
 	// deleted, but they can be used to indicate work which should be performed in
 	// the future once they are made available (or scheduled) by some external
 	// update.
+	//
+	// Can't be combined with SequenceOpts, which manages the pending state of
+	// sequenced jobs itself.
 	Pending bool
 
 	// Priority is the priority of the job, with 1 being the highest priority and
@@ -54,6 +57,11 @@ type InsertOpts struct {
 	// Defaults to QueueDefault.
 	Queue string
 
+	// SequenceOpts places the job in a strictly ordered sequence with other jobs
+	// sharing the same sequence key. An empty struct avoids setting any
+	// worker-level sequence options.
+	SequenceOpts SequenceOpts
+
 	// ScheduledAt is a time in future at which to schedule the job (i.e. in
 	// cases where it shouldn't be run immediately). The job is guaranteed not
 	// to run before this time, but may run slightly after depending on the
@@ -233,3 +241,64 @@ func (o *UniqueOpts) validate() error {
 
 	return nil
 }
+
+// SequenceOpts contains parameters for placing a job in a sequence. Jobs in the
+// same sequence are worked strictly one at a time, in the order they were
+// inserted. A sequenced job is inserted as `pending` and only made available
+// once the job before it in its sequence has completed, so only the head of
+// each sequence is ever eligible to be worked.
+//
+// When the options struct is uninitialized (its zero value) the job isn't
+// sequenced. Similarly to UniqueOpts, a job's sequence key is made up of its
+// kind (unless ExcludeKind is set) and each enabled property. At least one of
+// ByArgs or ByQueue must be set.
+//
+// Sequenced jobs are promoted by a maintenance service run by the elected
+// leader, so there may be a short delay (about a second) between a job
+// completing and the next job in its sequence becoming available. Retrying or
+// deleting the job at the head of a blocked sequence unblocks it.
+type SequenceOpts struct {
+	// ByArgs indicates that jobs are sequenced by their encoded args. Like
+	// UniqueOpts.ByArgs, a subset of args can be selected by tagging fields on
+	// the `JobArgs` struct, in this case with `river:"sequence"`:
+	//
+	// 	type AccountEventArgs struct {
+	// 		AccountID string `json:"account_id" river:"sequence"`
+	// 		Event     string `json:"event"`
+	// 	}
+	//
+	// In this example, all events for the same account are in one sequence.
+	// Without any tagged fields, the entire encoded args are used.
+	ByArgs bool
+
+	// ByQueue indicates that jobs are sequenced separately in each queue.
+	ByQueue bool
+
+	// ContinueOnDiscarded indicates that the sequence should continue to its
+	// next job when a job in it is discarded or cancelled. By default, the
+	// sequence is blocked until the discarded job is retried and completes, or
+	// is deleted (including by the job cleaner once its retention period
+	// elapses).
+	ContinueOnDiscarded bool
+
+	// ExcludeKind indicates that the job kind should not be included in the
+	// sequence key, so that jobs of different kinds can share a sequence.
+	ExcludeKind bool
+}
+
+// isEmpty returns true for an empty, uninitialized options struct.
+func (o *SequenceOpts) isEmpty() bool {
+	return *o == SequenceOpts{}
+}
+
+func (o *SequenceOpts) validate() error {
+	if o.isEmpty() {
+		return nil
+	}
+
+	if !o.ByArgs && !o.ByQueue {
+		return errors.New("SequenceOpts requires at least one of ByArgs or ByQueue")
+	}
+
+	return nil
+}
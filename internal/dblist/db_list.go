@@ -3,7 +3,9 @@ package dblist
 import (
 	"context"
 	"errors"
+	"fmt"
 	"strings"
+	"time"
 
 	"github.com/riverqueue/river/riverdriver"
 	"github.com/riverqueue/river/rivershared/util/sliceutil"
@@ -23,28 +25,110 @@ type JobListOrderBy struct {
 	Order SortOrder
 }
 
+// JobListArgsPath is a predicate matching jobs whose args have the given JSON
+// value at a path of object keys or array indexes.
+type JobListArgsPath struct {
+	Path  []string
+	Value string // encoded JSON
+}
+
 type JobListParams struct {
-	Conditions string
-	Kinds      []string
-	LimitCount int32
-	NamedArgs  map[string]any
-	OrderBy    []JobListOrderBy
-	Priorities []int16
-	Queues     []string
-	States     []rivertype.JobState
+	ArgsFragment string // encoded JSON that args must contain
+	ArgsPaths    []JobListArgsPath
+	AttemptMax   *int
+	AttemptMin   *int
+	Conditions   string
+	CreatedAt    TimeRange
+	FinalizedAt  TimeRange
+	IDMax        *int64
+	IDs          []int64
+	Kinds        []string
+	LimitCount   int32
+	NamedArgs    map[string]any
+	OrderBy      []JobListOrderBy
+	Priorities   []int16
+	Queues       []string
+	ScheduledAt  TimeRange
+	States       []rivertype.JobState
+	TagsAll      []string
+	TagsAny      []string
+}
+
+// TimeRange is a range of time with an inclusive start and exclusive end.
+// Either bound may be zero to leave that side of the range open.
+type TimeRange struct {
+	End   time.Time
+	Start time.Time
+}
+
+// JobCount counts the jobs matching params. Options that only apply to
+// listing like LimitCount and OrderBy are ignored.
+func JobCount(ctx context.Context, exec riverdriver.Executor, params *JobListParams) (int, error) {
+	whereClause, namedArgs := jobListWhereClause(params)
+
+	return exec.JobCount(ctx, &riverdriver.JobCountParams{
+		NamedArgs:   namedArgs,
+		WhereClause: whereClause,
+	})
+}
+
+// JobCountGrouped counts the jobs matching params, grouped by the values of
+// groupByExpr. Options that only apply to listing like LimitCount and OrderBy
+// are ignored.
+func JobCountGrouped(ctx context.Context, exec riverdriver.Executor, params *JobListParams, groupByExpr string) ([]*riverdriver.JobCountGroupedResult, error) {
+	if groupByExpr == "" {
+		return nil, errors.New("group by expression is required")
+	}
+
+	whereClause, namedArgs := jobListWhereClause(params)
+
+	return exec.JobCountGrouped(ctx, &riverdriver.JobCountGroupedParams{
+		GroupByExpr: groupByExpr,
+		NamedArgs:   namedArgs,
+		WhereClause: whereClause,
+	})
 }
 
 func JobList(ctx context.Context, exec riverdriver.Executor, params *JobListParams) ([]*rivertype.JobRow, error) {
-	var whereBuilder strings.Builder
+	whereClause, namedArgs := jobListWhereClause(params)
 
-	orderBy := make([]JobListOrderBy, len(params.OrderBy))
-	for i, o := range params.OrderBy {
-		orderBy[i] = JobListOrderBy{
-			Expr:  o.Expr,
-			Order: o.Order,
+	if params.LimitCount < 1 {
+		return nil, errors.New("required parameter 'Count' in JobList must be greater than zero")
+	}
+
+	if len(params.OrderBy) == 0 {
+		return nil, errors.New("sort order is required")
+	}
+
+	var orderByBuilder strings.Builder
+
+	for i, orderBy := range params.OrderBy {
+		orderByBuilder.WriteString(orderBy.Expr)
+		switch orderBy.Order {
+		case SortOrderAsc:
+			orderByBuilder.WriteString(" ASC")
+		case SortOrderDesc:
+			orderByBuilder.WriteString(" DESC")
+		case SortOrderUnspecified:
+			return nil, errors.New("should not have gotten SortOrderUnspecified by this point before executing list (bug?)")
+		}
+		if i < len(params.OrderBy)-1 {
+			orderByBuilder.WriteString(", ")
 		}
 	}
 
+	return exec.JobList(ctx, &riverdriver.JobListParams{
+		Max:           params.LimitCount,
+		NamedArgs:     namedArgs,
+		OrderByClause: orderByBuilder.String(),
+		WhereClause:   whereClause,
+	})
+}
+
+// Builds a where clause and its named arguments from the filters in params.
+func jobListWhereClause(params *JobListParams) (string, map[string]any) {
+	var whereBuilder strings.Builder
+
 	namedArgs := params.NamedArgs
 	if namedArgs == nil {
 		namedArgs = make(map[string]any)
@@ -58,6 +142,18 @@ func JobList(ctx context.Context, exec riverdriver.Executor, params *JobListPara
 		}
 	}
 
+	if len(params.IDs) > 0 {
+		writeAndAfterFirst()
+		whereBuilder.WriteString("id = any(@ids::bigint[])")
+		namedArgs["ids"] = params.IDs
+	}
+
+	if params.IDMax != nil {
+		writeAndAfterFirst()
+		whereBuilder.WriteString("id <= @id_max::bigint")
+		namedArgs["id_max"] = *params.IDMax
+	}
+
 	if len(params.Kinds) > 0 {
 		writeAndAfterFirst()
 		whereBuilder.WriteString("kind = any(@kinds::text[])")
@@ -76,46 +172,81 @@ func JobList(ctx context.Context, exec riverdriver.Executor, params *JobListPara
 		namedArgs["states"] = sliceutil.Map(params.States, func(s rivertype.JobState) string { return string(s) })
 	}
 
-	if params.Conditions != "" {
+	if len(params.Priorities) > 0 {
 		writeAndAfterFirst()
-		whereBuilder.WriteString(params.Conditions)
+		whereBuilder.WriteString("priority = any(@priorities::smallint[])")
+		namedArgs["priorities"] = params.Priorities
 	}
 
-	// A condition of some kind is needed, so given no others write one that'll
-	// always return true.
-	if whereBuilder.Len() < 1 {
-		whereBuilder.WriteString("1")
+	if len(params.TagsAll) > 0 {
+		writeAndAfterFirst()
+		whereBuilder.WriteString("tags @> @tags_all::varchar(255)[]")
+		namedArgs["tags_all"] = params.TagsAll
 	}
 
-	if params.LimitCount < 1 {
-		return nil, errors.New("required parameter 'Count' in JobList must be greater than zero")
+	if len(params.TagsAny) > 0 {
+		writeAndAfterFirst()
+		whereBuilder.WriteString("tags && @tags_any::varchar(255)[]")
+		namedArgs["tags_any"] = params.TagsAny
 	}
 
-	if len(params.OrderBy) == 0 {
-		return nil, errors.New("sort order is required")
+	if params.AttemptMin != nil {
+		writeAndAfterFirst()
+		whereBuilder.WriteString("attempt >= @attempt_min::smallint")
+		namedArgs["attempt_min"] = *params.AttemptMin
 	}
 
-	var orderByBuilder strings.Builder
+	if params.AttemptMax != nil {
+		writeAndAfterFirst()
+		whereBuilder.WriteString("attempt <= @attempt_max::smallint")
+		namedArgs["attempt_max"] = *params.AttemptMax
+	}
 
-	for i, orderBy := range params.OrderBy {
-		orderByBuilder.WriteString(orderBy.Expr)
-		switch orderBy.Order {
-		case SortOrderAsc:
-			orderByBuilder.WriteString(" ASC")
-		case SortOrderDesc:
-			orderByBuilder.WriteString(" DESC")
-		case SortOrderUnspecified:
-			return nil, errors.New("should not have gotten SortOrderUnspecified by this point before executing list (bug?)")
+	writeTimeRange := func(column string, timeRange TimeRange) {
+		if !timeRange.Start.IsZero() {
+			writeAndAfterFirst()
+			whereBuilder.WriteString(column + " >= @" + column + "_start::timestamptz")
+			namedArgs[column+"_start"] = timeRange.Start
 		}
-		if i < len(params.OrderBy)-1 {
-			orderByBuilder.WriteString(", ")
+
+		if !timeRange.End.IsZero() {
+			writeAndAfterFirst()
+			whereBuilder.WriteString(column + " < @" + column + "_end::timestamptz")
+			namedArgs[column+"_end"] = timeRange.End
 		}
 	}
 
-	return exec.JobList(ctx, &riverdriver.JobListParams{
-		Max:           params.LimitCount,
-		NamedArgs:     namedArgs,
-		OrderByClause: orderByBuilder.String(),
-		WhereClause:   whereBuilder.String(),
-	})
+	writeTimeRange("created_at", params.CreatedAt)
+	writeTimeRange("finalized_at", params.FinalizedAt)
+	writeTimeRange("scheduled_at", params.ScheduledAt)
+
+	if params.ArgsFragment != "" {
+		writeAndAfterFirst()
+		whereBuilder.WriteString("args @> @args_fragment::jsonb")
+		namedArgs["args_fragment"] = params.ArgsFragment
+	}
+
+	// Arg names are terminated with a suffix so that none is a prefix of
+	// another, like `args_path_1` would be of `args_path_10`.
+	for i, argsPath := range params.ArgsPaths {
+		pathArg, valueArg := fmt.Sprintf("args_path_%d_keys", i), fmt.Sprintf("args_path_%d_value", i)
+
+		writeAndAfterFirst()
+		whereBuilder.WriteString("args #> @" + pathArg + "::text[] = @" + valueArg + "::jsonb")
+		namedArgs[pathArg] = argsPath.Path
+		namedArgs[valueArg] = argsPath.Value
+	}
+
+	if params.Conditions != "" {
+		writeAndAfterFirst()
+		whereBuilder.WriteString(params.Conditions)
+	}
+
+	// A condition of some kind is needed, so given no others write one that'll
+	// always return true.
+	if whereBuilder.Len() < 1 {
+		whereBuilder.WriteString("true")
+	}
+
+	return whereBuilder.String(), namedArgs
 }
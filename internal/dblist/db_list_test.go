@@ -47,6 +47,35 @@ func TestJobListNoJobs(t *testing.T) {
 		require.NoError(t, err)
 	})
 
+	t.Run("WithAllFilters", func(t *testing.T) {
+		t.Parallel()
+
+		bundle := setup()
+
+		now := time.Now()
+
+		_, err := JobList(ctx, bundle.exec, &JobListParams{
+			ArgsFragment: `{"foo": "bar"}`,
+			ArgsPaths:    []JobListArgsPath{{Path: []string{"customer", "id"}, Value: "123"}, {Path: []string{"items", "0"}, Value: `"sku"`}},
+			AttemptMax:   ptrutil.Ptr(5),
+			AttemptMin:   ptrutil.Ptr(1),
+			CreatedAt:    TimeRange{End: now, Start: now.Add(-time.Hour)},
+			FinalizedAt:  TimeRange{Start: now.Add(-time.Hour)},
+			IDMax:        ptrutil.Ptr(int64(2)),
+			IDs:          []int64{1, 2},
+			Kinds:        []string{"kind"},
+			LimitCount:   1,
+			OrderBy:      []JobListOrderBy{{Expr: "id", Order: SortOrderAsc}},
+			Priorities:   []int16{1, 2},
+			Queues:       []string{"queue"},
+			ScheduledAt:  TimeRange{End: now},
+			States:       []rivertype.JobState{rivertype.JobStateCompleted},
+			TagsAll:      []string{"tag1", "tag2"},
+			TagsAny:      []string{"tag3"},
+		})
+		require.NoError(t, err)
+	})
+
 	t.Run("WithConditionsAndSortOrders", func(t *testing.T) {
 		t.Parallel()
 
@@ -162,6 +191,30 @@ func TestJobListWithJobs(t *testing.T) {
 		})
 	})
 
+	t.Run("ConditionsWithIDs", func(t *testing.T) {
+		t.Parallel()
+
+		bundle := setup(t)
+
+		job2 := bundle.jobs[1]
+		job3 := bundle.jobs[2]
+
+		params := &JobListParams{
+			Conditions: "finalized_at IS NULL",
+			IDs:        []int64{job2.ID, job3.ID},
+			LimitCount: 10,
+			OrderBy:    []JobListOrderBy{{Expr: "id", Order: SortOrderAsc}},
+			States:     []rivertype.JobState{rivertype.JobStateAvailable},
+		}
+
+		execTest(ctx, t, bundle, params, func(jobs []*rivertype.JobRow, err error) {
+			require.NoError(t, err)
+
+			returnedIDs := sliceutil.Map(jobs, func(j *rivertype.JobRow) int64 { return j.ID })
+			require.Equal(t, []int64{job2.ID, job3.ID}, returnedIDs)
+		})
+	})
+
 	t.Run("ConditionsWithKinds", func(t *testing.T) {
 		t.Parallel()
 
@@ -227,3 +280,98 @@ func TestJobListWithJobs(t *testing.T) {
 		})
 	})
 }
+
+func TestJobCount(t *testing.T) {
+	t.Parallel()
+
+	ctx := context.Background()
+
+	type testBundle struct {
+		exec riverdriver.Executor
+		jobs []*rivertype.JobRow
+	}
+
+	setup := func(t *testing.T) *testBundle {
+		t.Helper()
+
+		var (
+			driver = riverpgxv5.New(nil)
+			exec   = driver.UnwrapExecutor(riverinternaltest.TestTx(ctx, t))
+		)
+
+		job1 := testfactory.Job(ctx, t, exec, &testfactory.JobOpts{Kind: ptrutil.Ptr("kind1"), Tags: []string{"tag1", "tag2"}})
+		job2 := testfactory.Job(ctx, t, exec, &testfactory.JobOpts{Kind: ptrutil.Ptr("kind1"), Tags: []string{"tag1"}})
+		job3 := testfactory.Job(ctx, t, exec, &testfactory.JobOpts{Kind: ptrutil.Ptr("kind2"), State: ptrutil.Ptr(rivertype.JobStateRunning)})
+
+		return &testBundle{
+			exec: exec,
+			jobs: []*rivertype.JobRow{job1, job2, job3},
+		}
+	}
+
+	t.Run("Count", func(t *testing.T) {
+		t.Parallel()
+
+		bundle := setup(t)
+
+		numJobs, err := JobCount(ctx, bundle.exec, &JobListParams{
+			States: []rivertype.JobState{rivertype.JobStateAvailable},
+		})
+		require.NoError(t, err)
+		require.Equal(t, 2, numJobs)
+	})
+
+	t.Run("CountWithConditionsAndNoOtherFilters", func(t *testing.T) {
+		t.Parallel()
+
+		bundle := setup(t)
+
+		numJobs, err := JobCount(ctx, bundle.exec, &JobListParams{
+			Conditions: "kind = @kind",
+			NamedArgs:  map[string]any{"kind": "kind2"},
+		})
+		require.NoError(t, err)
+		require.Equal(t, 1, numJobs)
+
+		numJobs, err = JobCount(ctx, bundle.exec, &JobListParams{})
+		require.NoError(t, err)
+		require.Equal(t, 3, numJobs)
+	})
+
+	t.Run("CountGrouped", func(t *testing.T) {
+		t.Parallel()
+
+		bundle := setup(t)
+
+		results, err := JobCountGrouped(ctx, bundle.exec, &JobListParams{}, "kind")
+		require.NoError(t, err)
+		require.Equal(t, []*riverdriver.JobCountGroupedResult{
+			{Count: 2, Value: "kind1"},
+			{Count: 1, Value: "kind2"},
+		}, results)
+	})
+
+	t.Run("CountGroupedBySetReturningFunction", func(t *testing.T) {
+		t.Parallel()
+
+		bundle := setup(t)
+
+		results, err := JobCountGrouped(ctx, bundle.exec, &JobListParams{
+			IDs: []int64{bundle.jobs[0].ID, bundle.jobs[1].ID},
+		}, "unnest(tags)")
+		require.NoError(t, err)
+		require.Equal(t, []*riverdriver.JobCountGroupedResult{
+			{Count: 2, Value: "tag1"},
+			{Count: 1, Value: "tag2"},
+		}, results)
+	})
+
+	t.Run("CountGroupedRequiresExpr", func(t *testing.T) {
+		t.Parallel()
+
+		bundle := setup(t)
+
+		_, err := JobCountGrouped(ctx, bundle.exec, &JobListParams{}, "")
+		require.EqualError(t, err, "group by expression is required")
+	})
+}
@@ -54,7 +54,7 @@ func (o *UniqueOpts) StateBitmask() byte {
 }
 
 func UniqueKey(timeGen rivertype.TimeGenerator, uniqueOpts *UniqueOpts, params *rivertype.JobInsertParams) ([]byte, error) {
-	uniqueKeyString, err := buildUniqueKeyString(timeGen, uniqueOpts, params)
+	uniqueKeyString, err := buildUniqueKeyString(timeGen, uniqueOpts, params, riverTagUnique)
 	if err != nil {
 		return nil, err
 	}
@@ -63,8 +63,9 @@ func UniqueKey(timeGen rivertype.TimeGenerator, uniqueOpts *UniqueOpts, params *
 }
 
 // Builds a unique key made up of the unique options in place. The key is hashed
-// to become a value for `unique_key`.
-func buildUniqueKeyString(timeGen rivertype.TimeGenerator, uniqueOpts *UniqueOpts, params *rivertype.JobInsertParams) (string, error) {
+// to become a value for `unique_key`. riverTag selects the args fields included
+// when ByArgs is set (e.g. those tagged `river:"unique"`).
+func buildUniqueKeyString(timeGen rivertype.TimeGenerator, uniqueOpts *UniqueOpts, params *rivertype.JobInsertParams, riverTag string) (string, error) {
 	var sb strings.Builder
 
 	if !uniqueOpts.ExcludeKind {
@@ -74,32 +75,17 @@ func buildUniqueKeyString(timeGen rivertype.TimeGenerator, uniqueOpts *UniqueOpt
 	if uniqueOpts.ByArgs {
 		var encodedArgsForUnique []byte
 		// Get unique JSON keys from the JobArgs struct:
-		uniqueFields, err := getSortedUniqueFieldsCached(params.Args)
+		uniqueFields, err := getSortedTaggedFieldsCached(params.Args, riverTag)
 		if err != nil {
 			return "", err
 		}
 
 		if len(uniqueFields) > 0 {
 			// Extract unique values from the EncodedArgs JSON
-			uniqueValues := extractUniqueValues(params.EncodedArgs, uniqueFields)
-
-			// Assemble the JSON object using bytes.Buffer
-			// Better to overallocate a bit than to allocate multiple times, so just
-			// assume we'll cap out at the length of the full encoded args.
-			sortedJSONWithOnlyUniqueValues := make([]byte, 0, len(params.EncodedArgs))
-
-			sjsonOpts := &sjson.Options{ReplaceInPlace: true}
-			for i, key := range uniqueFields {
-				if uniqueValues[i] == "undefined" {
-					continue
-				}
-				sortedJSONWithOnlyUniqueValues, err = sjson.SetRawBytesOptions(sortedJSONWithOnlyUniqueValues, key, []byte(uniqueValues[i]), sjsonOpts)
-				if err != nil {
-					// Should not happen unless key was invalid
-					return "", err
-				}
+			encodedArgsForUnique, err = sortedJSONWithOnlyValues(params.EncodedArgs, uniqueFields)
+			if err != nil {
+				return "", err
 			}
-			encodedArgsForUnique = sortedJSONWithOnlyUniqueValues
 		} else {
 			// Use all keys from EncodedArgs sorted alphabetically
 			keys := sliceutil.Map(gjson.GetBytes(params.EncodedArgs, "@keys").Array(), func(v gjson.Result) string { return v.String() })
@@ -261,7 +261,7 @@ func TestUniqueKey(t *testing.T) {
 				tt.modifyInsertParamsFunc(insertParams)
 			}
 
-			uniqueKeyPreHash, err := buildUniqueKeyString(stubSvc, &tt.uniqueOpts, insertParams)
+			uniqueKeyPreHash, err := buildUniqueKeyString(stubSvc, &tt.uniqueOpts, insertParams, riverTagUnique)
 			require.NoError(t, err)
 			require.Equal(t, tt.expectedJSON, uniqueKeyPreHash)
 			expectedHash := sha256.Sum256([]byte(tt.expectedJSON))
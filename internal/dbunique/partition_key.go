@@ -0,0 +1,31 @@
+package dbunique
+
+import "github.com/riverqueue/river/rivertype"
+
+// PartitionKey returns a JSON object made up of the values of the job args
+// fields marked with `river:"partition"`, which identifies the args partition
+// that a job belongs to for partitioned concurrency limits. Returns nil if the
+// args have no partition fields.
+func PartitionKey(params *rivertype.JobInsertParams) ([]byte, error) {
+	partitionFields, err := getSortedTaggedFieldsCached(params.Args, riverTagPartition)
+	if err != nil {
+		return nil, err
+	}
+
+	if len(partitionFields) < 1 {
+		return nil, nil
+	}
+
+	partitionKey, err := sortedJSONWithOnlyValues(params.EncodedArgs, partitionFields)
+	if err != nil {
+		return nil, err
+	}
+
+	// All partition fields were missing from the args, in which case the
+	// assembled JSON is empty. Use an empty object so the key is still valid.
+	if len(partitionKey) < 1 {
+		return []byte("{}"), nil
+	}
+
+	return partitionKey, nil
+}
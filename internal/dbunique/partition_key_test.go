@@ -0,0 +1,74 @@
+package dbunique
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/riverqueue/river/rivertype"
+)
+
+func TestPartitionKey(t *testing.T) {
+	t.Parallel()
+
+	partitionKey := func(t *testing.T, args rivertype.JobArgs) []byte {
+		t.Helper()
+
+		encodedArgs, err := json.Marshal(args)
+		require.NoError(t, err)
+
+		key, err := PartitionKey(&rivertype.JobInsertParams{
+			Args:        args,
+			EncodedArgs: encodedArgs,
+			Kind:        args.Kind(),
+		})
+		require.NoError(t, err)
+		return key
+	}
+
+	t.Run("MultiplePartitionFields", func(t *testing.T) {
+		t.Parallel()
+
+		type TenantJobArgs struct {
+			JobArgsStaticKind
+			Region     string `json:"region"      river:"partition"`
+			CustomerID int    `json:"customer_id" river:"partition,unique"`
+			Message    string `json:"message"     river:"unique"`
+		}
+
+		require.JSONEq(t, `{"customer_id":123,"region":"us-east-1"}`, string(partitionKey(t, TenantJobArgs{
+			JobArgsStaticKind: JobArgsStaticKind{kind: "tenant_job"},
+			CustomerID:        123,
+			Message:           "hello",
+			Region:            "us-east-1",
+		})))
+	})
+
+	t.Run("MissingPartitionField", func(t *testing.T) {
+		t.Parallel()
+
+		type TenantJobArgs struct {
+			JobArgsStaticKind
+			CustomerID *int `json:"customer_id,omitempty" river:"partition"`
+		}
+
+		require.Equal(t, "{}", string(partitionKey(t, TenantJobArgs{
+			JobArgsStaticKind: JobArgsStaticKind{kind: "tenant_job"},
+		})))
+	})
+
+	t.Run("NoPartitionFields", func(t *testing.T) {
+		t.Parallel()
+
+		type PlainJobArgs struct {
+			JobArgsStaticKind
+			CustomerID int `json:"customer_id" river:"unique"`
+		}
+
+		require.Nil(t, partitionKey(t, PlainJobArgs{
+			JobArgsStaticKind: JobArgsStaticKind{kind: "plain_job"},
+			CustomerID:        123,
+		}))
+	})
+}
@@ -0,0 +1,32 @@
+package dbunique
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+
+	"github.com/riverqueue/river/rivertype"
+)
+
+type SequenceOpts struct {
+	ByArgs      bool
+	ByQueue     bool
+	ExcludeKind bool
+}
+
+// SequenceKey returns a key identifying the sequence that a job belongs to,
+// built the same way as a unique key except that ByArgs selects the args
+// fields marked with `river:"sequence"`. The key is a hex-encoded hash so that
+// it's of a predictable size for storage in job metadata.
+func SequenceKey(sequenceOpts *SequenceOpts, params *rivertype.JobInsertParams) (string, error) {
+	sequenceKeyString, err := buildUniqueKeyString(nil, &UniqueOpts{
+		ByArgs:      sequenceOpts.ByArgs,
+		ByQueue:     sequenceOpts.ByQueue,
+		ExcludeKind: sequenceOpts.ExcludeKind,
+	}, params, riverTagSequence)
+	if err != nil {
+		return "", err
+	}
+
+	sequenceKeyHash := sha256.Sum256([]byte(sequenceKeyString))
+	return hex.EncodeToString(sequenceKeyHash[:]), nil
+}
@@ -0,0 +1,71 @@
+package dbunique
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/riverqueue/river/rivertype"
+)
+
+func TestSequenceKey(t *testing.T) {
+	t.Parallel()
+
+	type AccountEventArgs struct {
+		JobArgsStaticKind
+		AccountID int    `json:"account_id" river:"sequence"`
+		Event     string `json:"event"`
+	}
+
+	sequenceKey := func(t *testing.T, sequenceOpts *SequenceOpts, args rivertype.JobArgs, queue string) string {
+		t.Helper()
+
+		encodedArgs, err := json.Marshal(args)
+		require.NoError(t, err)
+
+		key, err := SequenceKey(sequenceOpts, &rivertype.JobInsertParams{
+			Args:        args,
+			EncodedArgs: encodedArgs,
+			Kind:        args.Kind(),
+			Queue:       queue,
+		})
+		require.NoError(t, err)
+		require.Len(t, key, 64)
+		return key
+	}
+
+	account1Created := AccountEventArgs{JobArgsStaticKind: JobArgsStaticKind{kind: "account_event"}, AccountID: 1, Event: "created"}
+	account1Updated := AccountEventArgs{JobArgsStaticKind: JobArgsStaticKind{kind: "account_event"}, AccountID: 1, Event: "updated"}
+	account2Created := AccountEventArgs{JobArgsStaticKind: JobArgsStaticKind{kind: "account_event"}, AccountID: 2, Event: "created"}
+	otherKind := AccountEventArgs{JobArgsStaticKind: JobArgsStaticKind{kind: "other_kind"}, AccountID: 1, Event: "created"}
+
+	t.Run("ByArgsWithSequenceFields", func(t *testing.T) {
+		t.Parallel()
+
+		opts := &SequenceOpts{ByArgs: true}
+
+		require.Equal(t, sequenceKey(t, opts, account1Created, "default"), sequenceKey(t, opts, account1Updated, "default"))
+		require.NotEqual(t, sequenceKey(t, opts, account1Created, "default"), sequenceKey(t, opts, account2Created, "default"))
+		require.NotEqual(t, sequenceKey(t, opts, account1Created, "default"), sequenceKey(t, opts, otherKind, "default"))
+
+		// Queue isn't part of the key unless ByQueue is set.
+		require.Equal(t, sequenceKey(t, opts, account1Created, "default"), sequenceKey(t, opts, account1Created, "other"))
+	})
+
+	t.Run("ByQueue", func(t *testing.T) {
+		t.Parallel()
+
+		opts := &SequenceOpts{ByArgs: true, ByQueue: true}
+
+		require.NotEqual(t, sequenceKey(t, opts, account1Created, "default"), sequenceKey(t, opts, account1Created, "other"))
+	})
+
+	t.Run("ExcludeKind", func(t *testing.T) {
+		t.Parallel()
+
+		opts := &SequenceOpts{ByArgs: true, ExcludeKind: true}
+
+		require.Equal(t, sequenceKey(t, opts, account1Created, "default"), sequenceKey(t, opts, otherKind, "default"))
+	})
+}
@@ -8,41 +8,83 @@ import (
 	"sync"
 
 	"github.com/tidwall/gjson"
+	"github.com/tidwall/sjson"
 
 	"github.com/riverqueue/river/rivertype"
 )
 
+const (
+	riverTagPartition = "partition"
+	riverTagSequence  = "sequence"
+	riverTagUnique    = "unique"
+)
+
 var (
-	// uniqueFieldsCache caches the unique fields for each JobArgs type. These are
-	// global to ensure that each struct type's tags are only extracted once.
-	uniqueFieldsCache = make(map[reflect.Type][]string) //nolint:gochecknoglobals
-	cacheMutex        sync.RWMutex                      //nolint:gochecknoglobals
+	// taggedFieldsCache caches the tagged fields for each JobArgs type and tag.
+	// These are global to ensure that each struct type's tags are only extracted
+	// once.
+	taggedFieldsCache = make(map[taggedFieldsCacheKey][]string) //nolint:gochecknoglobals
+	cacheMutex        sync.RWMutex                              //nolint:gochecknoglobals
 )
 
-// extractUniqueValues extracts the raw JSON values of the specified keys from the JSON-encoded args.
-func extractUniqueValues(encodedArgs []byte, uniqueKeys []string) []string {
+type taggedFieldsCacheKey struct {
+	tag string
+	typ reflect.Type
+}
+
+// extractValues extracts the raw JSON values of the specified keys from the JSON-encoded args.
+func extractValues(encodedArgs []byte, keys []string) []string {
 	// Use GetManyBytes to retrieve multiple values at once
-	results := gjson.GetManyBytes(encodedArgs, uniqueKeys...)
+	results := gjson.GetManyBytes(encodedArgs, keys...)
 
-	uniqueValues := make([]string, len(results))
+	values := make([]string, len(results))
 	for i, res := range results {
 		if res.Exists() {
-			uniqueValues[i] = res.Raw // Use Raw to get the JSON-encoded value
+			values[i] = res.Raw // Use Raw to get the JSON-encoded value
 		} else {
 			// Handle missing keys as "undefined" (they'll be skipped when building
-			// the unique key). We don't want to use "null" here because the JSON may
+			// the key). We don't want to use "null" here because the JSON may
 			// actually contain "null" as a value.
-			uniqueValues[i] = "undefined"
+			values[i] = "undefined"
 		}
 	}
 
-	return uniqueValues
+	return values
 }
 
-// getSortedUniqueFields uses reflection to retrieve the JSON keys of fields
-// marked with `river:"unique"` among potentially other comma-separated values.
-// The return values are the JSON keys using the same logic as the `json` struct tag.
-func getSortedUniqueFields(args rivertype.JobArgs) ([]string, error) {
+// sortedJSONWithOnlyValues assembles a JSON object containing only the given
+// keys (which should be sorted) and their values from the JSON-encoded args.
+// Keys missing from the args are omitted.
+func sortedJSONWithOnlyValues(encodedArgs []byte, sortedKeys []string) ([]byte, error) {
+	values := extractValues(encodedArgs, sortedKeys)
+
+	// Better to overallocate a bit than to allocate multiple times, so just
+	// assume we'll cap out at the length of the full encoded args.
+	sortedJSON := make([]byte, 0, len(encodedArgs))
+
+	var (
+		err       error
+		sjsonOpts = &sjson.Options{ReplaceInPlace: true}
+	)
+	for i, key := range sortedKeys {
+		if values[i] == "undefined" {
+			continue
+		}
+		sortedJSON, err = sjson.SetRawBytesOptions(sortedJSON, key, []byte(values[i]), sjsonOpts)
+		if err != nil {
+			// Should not happen unless key was invalid
+			return nil, err
+		}
+	}
+
+	return sortedJSON, nil
+}
+
+// getSortedTaggedFields uses reflection to retrieve the JSON keys of fields
+// marked with the given `river` tag (e.g. `river:"unique"`) among potentially
+// other comma-separated values. The return values are the JSON keys using the
+// same logic as the `json` struct tag.
+func getSortedTaggedFields(args rivertype.JobArgs, riverTag string) ([]string, error) {
 	typ := reflect.TypeOf(args)
 
 	// Handle pointer to struct
@@ -55,62 +97,62 @@ func getSortedUniqueFields(args rivertype.JobArgs) ([]string, error) {
 		return nil, fmt.Errorf("expected struct, got %T", args)
 	}
 
-	var uniqueFields []string
+	var taggedFields []string
 
 	// Iterate over all fields
 	for i := range typ.NumField() {
 		field := typ.Field(i)
 
-		// Check for `river:"unique"` tag, possibly among other comma-separated values
-		if riverTag, ok := field.Tag.Lookup("river"); ok {
-			// Split riverTag by comma
-			tags := strings.Split(riverTag, ",")
+		// Check for the `river` tag, possibly among other comma-separated values
+		if fieldRiverTag, ok := field.Tag.Lookup("river"); ok {
+			// Split fieldRiverTag by comma
+			tags := strings.Split(fieldRiverTag, ",")
 			for _, tag := range tags {
-				if strings.TrimSpace(tag) == "unique" {
+				if strings.TrimSpace(tag) == riverTag {
 					// Get the corresponding JSON key
 					jsonTag := field.Tag.Get("json")
 					if jsonTag == "" {
 						// If no JSON tag, use the field name as-is
-						uniqueFields = append(uniqueFields, field.Name)
+						taggedFields = append(taggedFields, field.Name)
 					} else {
 						// Handle cases like `json:"recipient,omitempty"`
 						jsonKey := parseJSONTag(jsonTag)
-						uniqueFields = append(uniqueFields, jsonKey)
+						taggedFields = append(taggedFields, jsonKey)
 					}
-					break // No need to check other tags once "unique" is found
+					break // No need to check other tags once the tag is found
 				}
 			}
 		}
 	}
 
-	// Sort the uniqueFields alphabetically for consistent ordering
-	sort.Strings(uniqueFields)
+	// Sort the taggedFields alphabetically for consistent ordering
+	sort.Strings(taggedFields)
 
-	return uniqueFields, nil
+	return taggedFields, nil
 }
 
-// getSortedUniqueFieldsCached retrieves unique fields with caching to avoid
+// getSortedTaggedFieldsCached retrieves tagged fields with caching to avoid
 // extracting fields from the same struct type repeatedly.
-func getSortedUniqueFieldsCached(args rivertype.JobArgs) ([]string, error) {
-	typ := reflect.TypeOf(args)
+func getSortedTaggedFieldsCached(args rivertype.JobArgs, riverTag string) ([]string, error) {
+	cacheKey := taggedFieldsCacheKey{tag: riverTag, typ: reflect.TypeOf(args)}
 
 	// Check cache first
 	cacheMutex.RLock()
-	if fields, ok := uniqueFieldsCache[typ]; ok {
+	if fields, ok := taggedFieldsCache[cacheKey]; ok {
 		cacheMutex.RUnlock()
 		return fields, nil
 	}
 	cacheMutex.RUnlock()
 
 	// Not in cache; retrieve using reflection
-	fields, err := getSortedUniqueFields(args)
+	fields, err := getSortedTaggedFields(args, riverTag)
 	if err != nil {
 		return nil, err
 	}
 
 	// Store in cache
 	cacheMutex.Lock()
-	uniqueFieldsCache[typ] = fields
+	taggedFieldsCache[cacheKey] = fields
 	cacheMutex.Unlock()
 
 	return fields, nil
@@ -13,8 +13,12 @@ import (
 type HookKind string
 
 const (
-	HookKindInsertBegin HookKind = "insert_begin"
-	HookKindWorkBegin   HookKind = "work_begin"
+	HookKindInsertBegin    HookKind = "insert_begin"
+	HookKindInsertEnd      HookKind = "insert_end"
+	HookKindJobRescued     HookKind = "job_rescued"
+	HookKindJobStateChange HookKind = "job_state_change"
+	HookKindWorkBegin      HookKind = "work_begin"
+	HookKindWorkEnd        HookKind = "work_end"
 )
 
 //
@@ -82,12 +86,36 @@ func (c *hookLookup) ByHookKind(kind HookKind) []rivertype.Hook {
 				c.hooksByKind[kind] = append(c.hooksByKind[kind], typedHook)
 			}
 		}
+	case HookKindInsertEnd:
+		for _, hook := range c.hooks {
+			if typedHook, ok := hook.(rivertype.HookInsertEnd); ok {
+				c.hooksByKind[kind] = append(c.hooksByKind[kind], typedHook)
+			}
+		}
+	case HookKindJobRescued:
+		for _, hook := range c.hooks {
+			if typedHook, ok := hook.(rivertype.HookJobRescued); ok {
+				c.hooksByKind[kind] = append(c.hooksByKind[kind], typedHook)
+			}
+		}
+	case HookKindJobStateChange:
+		for _, hook := range c.hooks {
+			if typedHook, ok := hook.(rivertype.HookJobStateChange); ok {
+				c.hooksByKind[kind] = append(c.hooksByKind[kind], typedHook)
+			}
+		}
 	case HookKindWorkBegin:
 		for _, hook := range c.hooks {
 			if typedHook, ok := hook.(rivertype.HookWorkBegin); ok {
 				c.hooksByKind[kind] = append(c.hooksByKind[kind], typedHook)
 			}
 		}
+	case HookKindWorkEnd:
+		for _, hook := range c.hooks {
+			if typedHook, ok := hook.(rivertype.HookWorkEnd); ok {
+				c.hooksByKind[kind] = append(c.hooksByKind[kind], typedHook)
+			}
+		}
 	}
 
 	return c.hooksByKind[kind]
@@ -21,6 +21,7 @@ func TestHookLookup(t *testing.T) {
 		return NewHookLookup([]rivertype.Hook{ //nolint:forcetypeassert
 			&testHookInsertAndWorkBegin{},
 			&testHookInsertBegin{},
+			&testHookLifecycleEnd{},
 			&testHookWorkBegin{},
 		}).(*hookLookup), &testBundle{}
 	}
@@ -52,6 +53,23 @@ func TestHookLookup(t *testing.T) {
 		}, hookLookup.ByHookKind(HookKindWorkBegin))
 	})
 
+	t.Run("LooksUpLifecycleEndHooks", func(t *testing.T) {
+		t.Parallel()
+
+		hookLookup, _ := setup(t)
+
+		for _, kind := range []HookKind{
+			HookKindInsertEnd,
+			HookKindJobRescued,
+			HookKindJobStateChange,
+			HookKindWorkEnd,
+		} {
+			require.Equal(t, []rivertype.Hook{&testHookLifecycleEnd{}}, hookLookup.ByHookKind(kind))
+		}
+
+		require.Len(t, hookLookup.hooksByKind, 4)
+	})
+
 	t.Run("Stress", func(t *testing.T) {
 		t.Parallel()
 
@@ -96,7 +114,9 @@ func TestEmptyHookLookup(t *testing.T) {
 		hookLookup, _ := setup(t)
 
 		require.Nil(t, hookLookup.ByHookKind(HookKindInsertBegin))
+		require.Nil(t, hookLookup.ByHookKind(HookKindInsertEnd))
 		require.Nil(t, hookLookup.ByHookKind(HookKindWorkBegin))
+		require.Nil(t, hookLookup.ByHookKind(HookKindWorkEnd))
 	})
 }
 
@@ -231,6 +251,30 @@ func (t *testHookInsertBegin) InsertBegin(ctx context.Context, params *rivertype
 	return nil
 }
 
+//
+// testHookLifecycleEnd
+//
+
+var (
+	_ rivertype.HookInsertEnd      = &testHookLifecycleEnd{}
+	_ rivertype.HookJobRescued     = &testHookLifecycleEnd{}
+	_ rivertype.HookJobStateChange = &testHookLifecycleEnd{}
+	_ rivertype.HookWorkEnd        = &testHookLifecycleEnd{}
+)
+
+type testHookLifecycleEnd struct{ rivertype.Hook }
+
+func (t *testHookLifecycleEnd) InsertEnd(ctx context.Context, params *rivertype.JobInsertParams, result *rivertype.JobInsertResult) error {
+	return nil
+}
+
+func (t *testHookLifecycleEnd) JobRescued(ctx context.Context, job *rivertype.JobRow) {}
+
+func (t *testHookLifecycleEnd) JobStateChange(ctx context.Context, job *rivertype.JobRow) {}
+
+func (t *testHookLifecycleEnd) WorkEnd(ctx context.Context, job *rivertype.JobRow, result *rivertype.JobWorkResult) {
+}
+
 //
 // testHookWorkBegin
 //
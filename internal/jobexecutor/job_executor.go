@@ -7,7 +7,9 @@ import (
 	"errors"
 	"fmt"
 	"log/slog"
+	"maps"
 	"runtime"
+	"sync"
 	"time"
 
 	"github.com/tidwall/gjson"
@@ -79,6 +81,70 @@ func MetadataUpdatesFromWorkContext(ctx context.Context) (map[string]any, bool)
 	return typedMetadataUpdates, true
 }
 
+// ContextKeyProgressUpdates is the context key for the progress updates stored
+// in the context. It's exposed from this internal package solely so that it
+// can be used in tests.
+const ContextKeyProgressUpdates contextKey = "river_progress_updates"
+
+// ProgressUpdatesFromWorkContext returns the progress updates stored in the
+// work context, if any.
+//
+// When run on a non-work context, it returns nil, false.
+func ProgressUpdatesFromWorkContext(ctx context.Context) (*ProgressUpdates, bool) {
+	progressUpdates, ok := ctx.Value(ContextKeyProgressUpdates).(*ProgressUpdates)
+	if !ok || progressUpdates == nil {
+		return nil, false
+	}
+	return progressUpdates, true
+}
+
+// ProgressUpdates are metadata updates like progress and checkpoints which are
+// flushed to the database periodically while a job is still running, unlike
+// the updates from MetadataUpdatesFromWorkContext which are only stored once
+// the job finishes executing. It's safe for concurrent use because it's
+// written by the worker while it's read by the executor's flush loop.
+type ProgressUpdates struct {
+	mu      sync.Mutex
+	dirty   bool
+	updates map[string]json.RawMessage
+}
+
+func NewProgressUpdates() *ProgressUpdates {
+	return &ProgressUpdates{updates: make(map[string]json.RawMessage)}
+}
+
+// Set sets the given metadata key to a JSON value, marking the updates as
+// needing a flush.
+func (u *ProgressUpdates) Set(key string, value json.RawMessage) {
+	u.mu.Lock()
+	defer u.mu.Unlock()
+
+	u.dirty = true
+	u.updates[key] = value
+}
+
+// All returns a copy of all updates that have been set.
+func (u *ProgressUpdates) All() map[string]json.RawMessage {
+	u.mu.Lock()
+	defer u.mu.Unlock()
+
+	return maps.Clone(u.updates)
+}
+
+// takeDirty returns a copy of all updates if any have been set since the last
+// time it was called, and false otherwise.
+func (u *ProgressUpdates) takeDirty() (map[string]json.RawMessage, bool) {
+	u.mu.Lock()
+	defer u.mu.Unlock()
+
+	if !u.dirty {
+		return nil, false
+	}
+
+	u.dirty = false
+	return maps.Clone(u.updates), true
+}
+
 type jobExecutorResult struct {
 	Err             error
 	MetadataUpdates map[string]any
@@ -115,6 +181,9 @@ type JobExecutor struct {
 	InformProducerDoneFunc   func(jobRow *rivertype.JobRow)
 	JobRow                   *rivertype.JobRow
 	MiddlewareLookupGlobal   middlewarelookup.MiddlewareLookupInterface
+	ProgressExec             riverdriver.Executor // used to flush progress while running; if nil, progress is only stored on completion
+	ProgressFlushInterval    time.Duration
+	ProgressFlushedFunc      func(jobRow *rivertype.JobRow)
 	SchedulerInterval        time.Duration
 	Schema                   string
 	WorkerMiddleware         []rivertype.WorkerMiddleware
@@ -158,6 +227,20 @@ func (e *JobExecutor) execute(ctx context.Context) (res *jobExecutorResult) {
 	metadataUpdates := make(map[string]any)
 	ctx = context.WithValue(ctx, ContextKeyMetadataUpdates, metadataUpdates)
 
+	progressUpdates := NewProgressUpdates()
+	ctx = context.WithValue(ctx, ContextKeyProgressUpdates, progressUpdates)
+
+	// Runs last so that final progress updates are stored along with the
+	// job's result, even in the event of a panic.
+	stopProgressFlush := e.startProgressFlush(ctx, progressUpdates)
+	defer func() {
+		stopProgressFlush()
+
+		for key, value := range progressUpdates.All() {
+			res.MetadataUpdates[key] = value
+		}
+	}()
+
 	defer func() {
 		if recovery := recover(); recovery != nil {
 			e.Logger.ErrorContext(ctx, e.Name+": panic recovery; possible bug with Worker",
@@ -222,6 +305,78 @@ func (e *JobExecutor) execute(ctx context.Context) (res *jobExecutorResult) {
 	return &jobExecutorResult{Err: executeFunc(ctx), MetadataUpdates: metadataUpdates}
 }
 
+// Starts a goroutine that periodically flushes progress updates to the job's
+// row while it's running, returning a function that stops it and waits for it
+// to exit. Updates still pending when it's stopped are stored along with the
+// job's result instead.
+func (e *JobExecutor) startProgressFlush(ctx context.Context, progressUpdates *ProgressUpdates) func() {
+	if e.ProgressExec == nil || e.ProgressFlushInterval <= 0 {
+		return func() {}
+	}
+
+	var (
+		done    = make(chan struct{})
+		stopped = make(chan struct{})
+	)
+
+	go func() {
+		defer close(stopped)
+
+		ticker := time.NewTicker(e.ProgressFlushInterval)
+		defer ticker.Stop()
+
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case <-done:
+				return
+			case <-ticker.C:
+				e.flushProgress(ctx, progressUpdates)
+			}
+		}
+	}()
+
+	return func() {
+		close(done)
+		<-stopped
+	}
+}
+
+func (e *JobExecutor) flushProgress(ctx context.Context, progressUpdates *ProgressUpdates) {
+	updates, ok := progressUpdates.takeDirty()
+	if !ok {
+		return
+	}
+
+	updatesBytes, err := json.Marshal(updates)
+	if err != nil {
+		e.Logger.ErrorContext(ctx, e.Name+": Failed to marshal progress updates", slog.String("error", err.Error()))
+		return
+	}
+
+	jobRow, err := e.ProgressExec.JobMetadataMergeIfRunning(ctx, &riverdriver.JobMetadataMergeIfRunningParams{
+		ID:              e.JobRow.ID,
+		MetadataUpdates: updatesBytes,
+		Schema:          e.Schema,
+	})
+	if err != nil {
+		// Not found means the job is no longer running, likely because it
+		// was rescued or cancelled. Updates will be stored with the result.
+		if !errors.Is(err, rivertype.ErrNotFound) && !errors.Is(err, context.Canceled) {
+			e.Logger.ErrorContext(ctx, e.Name+": Failed to flush progress updates",
+				slog.String("error", err.Error()),
+				slog.Int64("job_id", e.JobRow.ID),
+			)
+		}
+		return
+	}
+
+	if e.ProgressFlushedFunc != nil {
+		e.ProgressFlushedFunc(jobRow)
+	}
+}
+
 func (e *JobExecutor) invokeErrorHandler(ctx context.Context, res *jobExecutorResult) bool {
 	invokeAndHandlePanic := func(funcName string, errorHandler func() *ErrorHandlerResult) *ErrorHandlerResult {
 		defer func() {
@@ -293,7 +448,7 @@ func (e *JobExecutor) reportResult(ctx context.Context, res *jobExecutorResult)
 		} else {
 			params = riverdriver.JobSetStateSnoozed(e.JobRow.ID, nextAttemptScheduledAt, e.JobRow.Attempt-1, metadataUpdatesBytes)
 		}
-		if err := e.Completer.JobSetStateIfRunning(ctx, e.stats, params); err != nil {
+		if err := e.setStateIfRunning(ctx, res, params); err != nil {
 			e.Logger.ErrorContext(ctx, e.Name+": Error snoozing job",
 				slog.Int64("job_id", e.JobRow.ID),
 			)
@@ -306,7 +461,7 @@ func (e *JobExecutor) reportResult(ctx context.Context, res *jobExecutorResult)
 		return
 	}
 
-	if err := e.Completer.JobSetStateIfRunning(ctx, e.stats, riverdriver.JobSetStateCompleted(e.JobRow.ID, e.Time.NowUTC(), metadataUpdatesBytes)); err != nil {
+	if err := e.setStateIfRunning(ctx, res, riverdriver.JobSetStateCompleted(e.JobRow.ID, e.Time.NowUTC(), metadataUpdatesBytes)); err != nil {
 		e.Logger.ErrorContext(ctx, e.Name+": Error completing job",
 			slog.String("err", err.Error()),
 			slog.Int64("job_id", e.JobRow.ID),
@@ -315,6 +470,29 @@ func (e *JobExecutor) reportResult(ctx context.Context, res *jobExecutorResult)
 	}
 }
 
+// setStateIfRunning invokes any work end hooks with the job's result and the
+// state it's about to be transitioned to, then sends the state change to the
+// completer.
+func (e *JobExecutor) setStateIfRunning(ctx context.Context, res *jobExecutorResult, params *riverdriver.JobSetStateIfRunningParams) error {
+	hooks := e.HookLookupGlobal.ByHookKind(hooklookup.HookKindWorkEnd)
+	if e.WorkUnit != nil {
+		hooks = append(hooks, e.WorkUnit.HookLookup(e.HookLookupByJob).ByHookKind(hooklookup.HookKindWorkEnd)...)
+	}
+
+	if len(hooks) > 0 {
+		workRes := &rivertype.JobWorkResult{
+			Err:      res.Err,
+			PanicVal: res.PanicVal,
+			State:    params.State,
+		}
+		for _, hook := range hooks {
+			hook.(rivertype.HookWorkEnd).WorkEnd(ctx, e.JobRow, workRes) //nolint:forcetypeassert
+		}
+	}
+
+	return e.Completer.JobSetStateIfRunning(ctx, e.stats, params)
+}
+
 func (e *JobExecutor) reportError(ctx context.Context, res *jobExecutorResult, metadataUpdates []byte) {
 	var (
 		cancelJob bool
@@ -362,14 +540,14 @@ func (e *JobExecutor) reportError(ctx context.Context, res *jobExecutorResult, m
 	now := time.Now()
 
 	if cancelJob {
-		if err := e.Completer.JobSetStateIfRunning(ctx, e.stats, riverdriver.JobSetStateCancelled(e.JobRow.ID, now, errData, metadataUpdates)); err != nil {
+		if err := e.setStateIfRunning(ctx, res, riverdriver.JobSetStateCancelled(e.JobRow.ID, now, errData, metadataUpdates)); err != nil {
 			e.Logger.ErrorContext(ctx, e.Name+": Failed to cancel job and report error", logAttrs...)
 		}
 		return
 	}
 
 	if e.JobRow.Attempt >= e.JobRow.MaxAttempts {
-		if err := e.Completer.JobSetStateIfRunning(ctx, e.stats, riverdriver.JobSetStateDiscarded(e.JobRow.ID, now, errData, metadataUpdates)); err != nil {
+		if err := e.setStateIfRunning(ctx, res, riverdriver.JobSetStateDiscarded(e.JobRow.ID, now, errData, metadataUpdates)); err != nil {
 			e.Logger.ErrorContext(ctx, e.Name+": Failed to discard job and report error", logAttrs...)
 		}
 		return
@@ -404,7 +582,7 @@ func (e *JobExecutor) reportError(ctx context.Context, res *jobExecutorResult, m
 	} else {
 		params = riverdriver.JobSetStateErrorRetryable(e.JobRow.ID, nextRetryScheduledAt, errData, metadataUpdates)
 	}
-	if err := e.Completer.JobSetStateIfRunning(ctx, e.stats, params); err != nil {
+	if err := e.setStateIfRunning(ctx, res, params); err != nil {
 		e.Logger.ErrorContext(ctx, e.Name+": Failed to report error for job", logAttrs...)
 	}
 }
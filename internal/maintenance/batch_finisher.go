@@ -0,0 +1,199 @@
+package maintenance
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"time"
+
+	"github.com/riverqueue/river/riverdriver"
+	"github.com/riverqueue/river/rivershared/baseservice"
+	"github.com/riverqueue/river/rivershared/startstop"
+	"github.com/riverqueue/river/rivershared/testsignal"
+	"github.com/riverqueue/river/rivershared/util/randutil"
+	"github.com/riverqueue/river/rivershared/util/serviceutil"
+	"github.com/riverqueue/river/rivershared/util/timeutil"
+	"github.com/riverqueue/river/rivershared/util/valutil"
+	"github.com/riverqueue/river/rivertype"
+)
+
+const (
+	BatchFinisherIntervalDefault = 1 * time.Second
+)
+
+// Test-only properties.
+type BatchFinisherTestSignals struct {
+	FinishedBatch testsignal.TestSignal[struct{}] // notifies when runOnce finishes a pass
+}
+
+func (ts *BatchFinisherTestSignals) Init() {
+	ts.FinishedBatch.Init()
+}
+
+type BatchFinisherConfig struct {
+	// Interval is the amount of time between periodic checks for batches whose
+	// jobs have all been finalized.
+	Interval time.Duration
+
+	// NotifyInsert is a function to call to emit notifications for queues
+	// where on finish jobs were made available.
+	NotifyInsert NotifyInsertFunc
+
+	// Schema where River tables are located. Empty string omits schema, causing
+	// Postgres to default to `search_path`.
+	Schema string
+}
+
+func (c *BatchFinisherConfig) mustValidate() *BatchFinisherConfig {
+	if c.Interval <= 0 {
+		panic("BatchFinisherConfig.Interval must be above zero")
+	}
+
+	return c
+}
+
+// BatchFinisher periodically checks the pending on finish jobs of batches
+// against the state of the jobs in their batch. Once every job in a batch has
+// been finalized (completed, cancelled, or discarded), its on finish job is
+// moved to `available` (or `scheduled` if its scheduled time is still in the
+// future) so that it's eligible to be worked. All state is kept in the
+// database, so a newly elected leader picks up where the last one left off.
+type BatchFinisher struct {
+	queueMaintainerServiceBase
+	startstop.BaseStartStop
+
+	// exported for test purposes
+	TestSignals BatchFinisherTestSignals
+
+	batchSize int // configurable for test purposes
+	config    *BatchFinisherConfig
+	exec      riverdriver.Executor
+}
+
+func NewBatchFinisher(archetype *baseservice.Archetype, config *BatchFinisherConfig, exec riverdriver.Executor) *BatchFinisher {
+	return baseservice.Init(archetype, &BatchFinisher{
+		batchSize: BatchSizeDefault,
+		config: (&BatchFinisherConfig{
+			Interval:     valutil.ValOrDefault(config.Interval, BatchFinisherIntervalDefault),
+			NotifyInsert: config.NotifyInsert,
+			Schema:       config.Schema,
+		}).mustValidate(),
+		exec: exec,
+	})
+}
+
+func (s *BatchFinisher) Start(ctx context.Context) error { //nolint:dupl
+	ctx, shouldStart, started, stopped := s.StartInit(ctx)
+	if !shouldStart {
+		return nil
+	}
+
+	s.StaggerStart(ctx)
+
+	go func() {
+		started()
+		defer stopped() // this defer should come first so it's last out
+
+		s.Logger.DebugContext(ctx, s.Name+logPrefixRunLoopStarted)
+		defer s.Logger.DebugContext(ctx, s.Name+logPrefixRunLoopStopped)
+
+		ticker := timeutil.NewTickerWithInitialTick(ctx, s.config.Interval)
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case <-ticker.C:
+			}
+
+			res, err := s.runOnce(ctx)
+			if err != nil {
+				if !errors.Is(err, context.Canceled) {
+					s.Logger.ErrorContext(ctx, s.Name+": Error finishing batches", slog.String("error", err.Error()))
+				}
+				continue
+			}
+
+			if res.NumBatchesFinished > 0 {
+				s.Logger.InfoContext(ctx, s.Name+logPrefixRanSuccessfully,
+					slog.Int("num_batches_finished", res.NumBatchesFinished),
+				)
+			}
+		}
+	}()
+
+	return nil
+}
+
+type batchFinisherRunOnceResult struct {
+	NumBatchesFinished int
+}
+
+func (s *BatchFinisher) runOnce(ctx context.Context) (*batchFinisherRunOnceResult, error) {
+	var (
+		afterID int64
+		res     = &batchFinisherRunOnceResult{}
+	)
+
+	for {
+		// Wrapped in a function so that defers run as expected.
+		numExamined, err := func() (int, error) {
+			ctx, cancelFunc := context.WithTimeout(ctx, 30*time.Second)
+			defer cancelFunc()
+
+			tx, err := s.exec.Begin(ctx)
+			if err != nil {
+				return 0, fmt.Errorf("error starting transaction: %w", err)
+			}
+			defer tx.Rollback(ctx)
+
+			jobs, err := tx.JobBatchOnFinishPromote(ctx, &riverdriver.JobBatchOnFinishPromoteParams{
+				AfterID: afterID,
+				Max:     s.batchSize,
+				Now:     s.Time.NowUTC(),
+				Schema:  s.config.Schema,
+			})
+			if err != nil {
+				return 0, fmt.Errorf("error promoting batch on finish jobs: %w", err)
+			}
+
+			queues := make([]string, 0, len(jobs))
+
+			for _, job := range jobs {
+				afterID = max(afterID, job.ID)
+
+				// Don't include a `default` so `exhaustive` lint can detect omissions.
+				switch job.State {
+				case rivertype.JobStateAvailable:
+					queues = append(queues, job.Queue)
+					res.NumBatchesFinished++
+				case rivertype.JobStateScheduled:
+					res.NumBatchesFinished++
+				case rivertype.JobStateCancelled, rivertype.JobStateCompleted, rivertype.JobStateDiscarded, rivertype.JobStatePending, rivertype.JobStateRetryable, rivertype.JobStateRunning:
+				}
+			}
+
+			if len(queues) > 0 && s.config.NotifyInsert != nil {
+				if err := s.config.NotifyInsert(ctx, tx, queues); err != nil {
+					return 0, fmt.Errorf("error notifying insert: %w", err)
+				}
+			}
+
+			return len(jobs), tx.Commit(ctx)
+		}()
+		if err != nil {
+			return nil, err
+		}
+
+		s.TestSignals.FinishedBatch.Signal(struct{}{})
+
+		// Examined was less than query `LIMIT` which means work is done.
+		if numExamined < s.batchSize {
+			break
+		}
+
+		serviceutil.CancellableSleep(ctx, randutil.DurationBetween(BatchBackoffMin, BatchBackoffMax))
+	}
+
+	return res, nil
+}
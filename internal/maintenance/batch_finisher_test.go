@@ -0,0 +1,166 @@
+package maintenance
+
+import (
+	"context"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/riverqueue/river/internal/rivercommon"
+	"github.com/riverqueue/river/internal/riverinternaltest"
+	"github.com/riverqueue/river/riverdriver"
+	"github.com/riverqueue/river/riverdriver/riverpgxv5"
+	"github.com/riverqueue/river/rivershared/riversharedtest"
+	"github.com/riverqueue/river/rivershared/startstoptest"
+	"github.com/riverqueue/river/rivershared/testfactory"
+	"github.com/riverqueue/river/rivershared/util/ptrutil"
+	"github.com/riverqueue/river/rivertype"
+)
+
+func TestBatchFinisher(t *testing.T) {
+	t.Parallel()
+
+	ctx := context.Background()
+
+	type testBundle struct {
+		exec                 riverdriver.Executor
+		notificationsByQueue map[string]int
+	}
+
+	setup := func(t *testing.T) (*BatchFinisher, *testBundle) {
+		t.Helper()
+
+		tx := riverinternaltest.TestTx(ctx, t)
+		bundle := &testBundle{
+			exec:                 riverpgxv5.New(nil).UnwrapExecutor(tx),
+			notificationsByQueue: make(map[string]int),
+		}
+
+		finisher := NewBatchFinisher(
+			riversharedtest.BaseServiceArchetype(t),
+			&BatchFinisherConfig{
+				NotifyInsert: func(ctx context.Context, tx riverdriver.ExecutorTx, queues []string) error {
+					for _, queue := range queues {
+						bundle.notificationsByQueue[queue]++
+					}
+					return nil
+				},
+			},
+			bundle.exec)
+		finisher.TestSignals.Init()
+		t.Cleanup(finisher.Stop)
+
+		return finisher, bundle
+	}
+
+	batchJobMetadata := func(batchID string) []byte {
+		return []byte(fmt.Sprintf(`{"river:batch_id": %q}`, batchID))
+	}
+
+	onFinishMetadata := func(batchID string) []byte {
+		return []byte(fmt.Sprintf(`{"river:batch_on_finish": %q}`, batchID))
+	}
+
+	requireJobState := func(t *testing.T, exec riverdriver.Executor, job *rivertype.JobRow, expectedState rivertype.JobState) {
+		t.Helper()
+		newJob, err := exec.JobGetByID(ctx, &riverdriver.JobGetByIDParams{ID: job.ID})
+		require.NoError(t, err)
+		require.Equal(t, expectedState, newJob.State)
+	}
+
+	t.Run("Defaults", func(t *testing.T) {
+		t.Parallel()
+
+		finisher := NewBatchFinisher(riversharedtest.BaseServiceArchetype(t), &BatchFinisherConfig{}, nil)
+
+		require.Equal(t, BatchFinisherIntervalDefault, finisher.config.Interval)
+		require.Equal(t, BatchSizeDefault, finisher.batchSize)
+	})
+
+	t.Run("StartStopStress", func(t *testing.T) {
+		t.Parallel()
+
+		finisher, _ := setup(t)
+		finisher.Logger = riversharedtest.LoggerWarn(t)   // loop started/stop log is very noisy; suppress
+		finisher.TestSignals = BatchFinisherTestSignals{} // deinit so channels don't fill
+
+		startstoptest.Stress(ctx, t, finisher)
+	})
+
+	t.Run("PromotesOnFinishJobsOfFinishedBatches", func(t *testing.T) {
+		t.Parallel()
+
+		finisher, bundle := setup(t)
+
+		testfactory.Job(ctx, t, bundle.exec, &testfactory.JobOpts{Metadata: batchJobMetadata("batch1"), State: ptrutil.Ptr(rivertype.JobStateCompleted)})
+		testfactory.Job(ctx, t, bundle.exec, &testfactory.JobOpts{Metadata: batchJobMetadata("batch1"), State: ptrutil.Ptr(rivertype.JobStateDiscarded)})
+		onFinish1 := testfactory.Job(ctx, t, bundle.exec, &testfactory.JobOpts{Metadata: onFinishMetadata("batch1"), State: ptrutil.Ptr(rivertype.JobStatePending)})
+
+		testfactory.Job(ctx, t, bundle.exec, &testfactory.JobOpts{Metadata: batchJobMetadata("batch2"), State: ptrutil.Ptr(rivertype.JobStateCompleted)})
+		testfactory.Job(ctx, t, bundle.exec, &testfactory.JobOpts{Metadata: batchJobMetadata("batch2"), State: ptrutil.Ptr(rivertype.JobStateRunning)})
+		onFinish2 := testfactory.Job(ctx, t, bundle.exec, &testfactory.JobOpts{Metadata: onFinishMetadata("batch2"), State: ptrutil.Ptr(rivertype.JobStatePending)})
+
+		require.NoError(t, finisher.Start(ctx))
+		finisher.TestSignals.FinishedBatch.WaitOrTimeout()
+
+		requireJobState(t, bundle.exec, onFinish1, rivertype.JobStateAvailable)
+		requireJobState(t, bundle.exec, onFinish2, rivertype.JobStatePending)
+
+		require.Equal(t, map[string]int{rivercommon.QueueDefault: 1}, bundle.notificationsByQueue)
+	})
+
+	t.Run("FinishesInBatches", func(t *testing.T) {
+		t.Parallel()
+
+		finisher, bundle := setup(t)
+		finisher.batchSize = 10 // reduced size for test speed
+
+		// Add one to our chosen batch size to get one extra job and therefore
+		// one extra batch, ensuring that we've tested working multiple.
+		numBatches := finisher.batchSize + 1
+
+		onFinishJobs := make([]*rivertype.JobRow, numBatches)
+		for i := range numBatches {
+			batchID := fmt.Sprintf("batch_%d", i)
+			testfactory.Job(ctx, t, bundle.exec, &testfactory.JobOpts{Metadata: batchJobMetadata(batchID), State: ptrutil.Ptr(rivertype.JobStateCompleted)})
+			onFinishJobs[i] = testfactory.Job(ctx, t, bundle.exec, &testfactory.JobOpts{Metadata: onFinishMetadata(batchID), State: ptrutil.Ptr(rivertype.JobStatePending)})
+		}
+
+		require.NoError(t, finisher.Start(ctx))
+
+		// See comment above. Exactly two batches are expected.
+		finisher.TestSignals.FinishedBatch.WaitOrTimeout()
+		finisher.TestSignals.FinishedBatch.WaitOrTimeout()
+
+		for _, job := range onFinishJobs {
+			requireJobState(t, bundle.exec, job, rivertype.JobStateAvailable)
+		}
+	})
+
+	t.Run("CustomizableInterval", func(t *testing.T) {
+		t.Parallel()
+
+		finisher, _ := setup(t)
+		finisher.config.Interval = 1 * time.Microsecond
+
+		require.NoError(t, finisher.Start(ctx))
+
+		// This should trigger ~immediately every time:
+		for i := range 5 {
+			t.Logf("Iteration %d", i)
+			finisher.TestSignals.FinishedBatch.WaitOrTimeout()
+		}
+	})
+
+	t.Run("StopsImmediately", func(t *testing.T) {
+		t.Parallel()
+
+		finisher, _ := setup(t)
+		finisher.config.Interval = time.Minute // should only trigger once for the initial run
+
+		require.NoError(t, finisher.Start(ctx))
+		finisher.Stop()
+	})
+}
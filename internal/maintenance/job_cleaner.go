@@ -27,10 +27,12 @@ const (
 
 // Test-only properties.
 type JobCleanerTestSignals struct {
-	DeletedBatch testsignal.TestSignal[struct{}] // notifies when runOnce finishes a pass
+	DeadLetteredBatch testsignal.TestSignal[struct{}] // notifies when runOnce finishes a pass moving discarded jobs to the dead letter table
+	DeletedBatch      testsignal.TestSignal[struct{}] // notifies when runOnce finishes a pass
 }
 
 func (ts *JobCleanerTestSignals) Init() {
+	ts.DeadLetteredBatch.Init()
 	ts.DeletedBatch.Init()
 }
 
@@ -43,6 +45,10 @@ type JobCleanerConfig struct {
 	// around before they're removed permanently.
 	CompletedJobRetentionPeriod time.Duration
 
+	// DeadLetterEnabled moves discarded jobs to the dead letter table once
+	// their retention period has elapsed instead of removing them.
+	DeadLetterEnabled bool
+
 	// DiscardedJobRetentionPeriod is the amount of time to keep cancelled jobs
 	// around before they're removed permanently.
 	DiscardedJobRetentionPeriod time.Duration
@@ -80,6 +86,8 @@ func (c *JobCleanerConfig) mustValidate() *JobCleanerConfig {
 
 // JobCleaner periodically removes finalized jobs that are cancelled, completed,
 // or discarded. Each state's retention time can be configured individually.
+// Discarded jobs may optionally be moved to the dead letter table instead of
+// being removed.
 type JobCleaner struct {
 	queueMaintainerServiceBase
 	startstop.BaseStartStop
@@ -97,6 +105,7 @@ func NewJobCleaner(archetype *baseservice.Archetype, config *JobCleanerConfig, e
 		Config: (&JobCleanerConfig{
 			CancelledJobRetentionPeriod: valutil.ValOrDefault(config.CancelledJobRetentionPeriod, CancelledJobRetentionPeriodDefault),
 			CompletedJobRetentionPeriod: valutil.ValOrDefault(config.CompletedJobRetentionPeriod, CompletedJobRetentionPeriodDefault),
+			DeadLetterEnabled:           config.DeadLetterEnabled,
 			DiscardedJobRetentionPeriod: valutil.ValOrDefault(config.DiscardedJobRetentionPeriod, DiscardedJobRetentionPeriodDefault),
 			Interval:                    valutil.ValOrDefault(config.Interval, JobCleanerIntervalDefault),
 			Timeout:                     valutil.ValOrDefault(config.Timeout, JobCleanerTimeoutDefault),
@@ -138,8 +147,9 @@ func (s *JobCleaner) Start(ctx context.Context) error { //nolint:dupl
 				continue
 			}
 
-			if res.NumJobsDeleted > 0 {
+			if res.NumJobsDeadLettered > 0 || res.NumJobsDeleted > 0 {
 				s.Logger.InfoContext(ctx, s.Name+logPrefixRanSuccessfully,
+					slog.Int("num_jobs_dead_lettered", res.NumJobsDeadLettered),
 					slog.Int("num_jobs_deleted", res.NumJobsDeleted),
 				)
 			}
@@ -150,12 +160,27 @@ func (s *JobCleaner) Start(ctx context.Context) error { //nolint:dupl
 }
 
 type jobCleanerRunOnceResult struct {
-	NumJobsDeleted int
+	NumJobsDeadLettered int
+	NumJobsDeleted      int
 }
 
 func (s *JobCleaner) runOnce(ctx context.Context) (*jobCleanerRunOnceResult, error) {
 	res := &jobCleanerRunOnceResult{}
 
+	discardedFinalizedAtHorizon := time.Now().Add(-s.Config.DiscardedJobRetentionPeriod)
+
+	if s.Config.DeadLetterEnabled {
+		// Discarded jobs are moved to the dead letter table below, so use a
+		// horizon that no job can be finalized before to leave them alone.
+		discardedFinalizedAtHorizon = time.Time{}
+
+		numDeadLettered, err := s.runOnceDeadLetter(ctx)
+		if err != nil {
+			return nil, err
+		}
+		res.NumJobsDeadLettered = numDeadLettered
+	}
+
 	for {
 		// Wrapped in a function so that defers run as expected.
 		numDeleted, err := func() (int, error) {
@@ -165,7 +190,7 @@ func (s *JobCleaner) runOnce(ctx context.Context) (*jobCleanerRunOnceResult, err
 			numDeleted, err := s.exec.JobDeleteBefore(ctx, &riverdriver.JobDeleteBeforeParams{
 				CancelledFinalizedAtHorizon: time.Now().Add(-s.Config.CancelledJobRetentionPeriod),
 				CompletedFinalizedAtHorizon: time.Now().Add(-s.Config.CompletedJobRetentionPeriod),
-				DiscardedFinalizedAtHorizon: time.Now().Add(-s.Config.DiscardedJobRetentionPeriod),
+				DiscardedFinalizedAtHorizon: discardedFinalizedAtHorizon,
 				Max:                         s.batchSize,
 				Schema:                      s.Config.Schema,
 			})
@@ -196,3 +221,47 @@ func (s *JobCleaner) runOnce(ctx context.Context) (*jobCleanerRunOnceResult, err
 
 	return res, nil
 }
+
+// runOnceDeadLetter moves discarded jobs whose retention period has elapsed to
+// the dead letter table in batches, returning the total number moved.
+func (s *JobCleaner) runOnceDeadLetter(ctx context.Context) (int, error) {
+	var numDeadLetteredTotal int
+
+	for {
+		// Wrapped in a function so that defers run as expected.
+		numDeadLettered, err := func() (int, error) {
+			ctx, cancelFunc := context.WithTimeout(ctx, s.Config.Timeout)
+			defer cancelFunc()
+
+			numDeadLettered, err := s.exec.JobDeadLetterMoveBefore(ctx, &riverdriver.JobDeadLetterMoveBeforeParams{
+				DiscardedFinalizedAtHorizon: time.Now().Add(-s.Config.DiscardedJobRetentionPeriod),
+				Max:                         s.batchSize,
+				Schema:                      s.Config.Schema,
+			})
+			if err != nil {
+				return 0, fmt.Errorf("error moving discarded jobs to dead letter table: %w", err)
+			}
+
+			return numDeadLettered, nil
+		}()
+		if err != nil {
+			return 0, err
+		}
+
+		s.TestSignals.DeadLetteredBatch.Signal(struct{}{})
+
+		numDeadLetteredTotal += numDeadLettered
+		// Moved was less than query `LIMIT` which means work is done.
+		if numDeadLettered < s.batchSize {
+			break
+		}
+
+		s.Logger.DebugContext(ctx, s.Name+": Moved batch of jobs to dead letter table",
+			slog.Int("num_jobs_dead_lettered", numDeadLettered),
+		)
+
+		serviceutil.CancellableSleep(ctx, randutil.DurationBetween(BatchBackoffMin, BatchBackoffMax))
+	}
+
+	return numDeadLetteredTotal, nil
+}
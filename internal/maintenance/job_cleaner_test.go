@@ -163,6 +163,38 @@ func TestJobCleaner(t *testing.T) {
 		}
 	})
 
+	t.Run("MovesDiscardedJobsToDeadLetter", func(t *testing.T) {
+		t.Parallel()
+
+		cleaner, bundle := setup(t)
+		cleaner.Config.DeadLetterEnabled = true
+
+		discardedJob1 := testfactory.Job(ctx, t, bundle.exec, &testfactory.JobOpts{State: ptrutil.Ptr(rivertype.JobStateDiscarded), FinalizedAt: ptrutil.Ptr(bundle.discardedDeleteHorizon.Add(-1 * time.Hour))})
+		discardedJob2 := testfactory.Job(ctx, t, bundle.exec, &testfactory.JobOpts{State: ptrutil.Ptr(rivertype.JobStateDiscarded), FinalizedAt: ptrutil.Ptr(bundle.discardedDeleteHorizon.Add(1 * time.Minute))}) // won't be moved
+
+		// Other finalized jobs are still deleted as usual.
+		completedJob := testfactory.Job(ctx, t, bundle.exec, &testfactory.JobOpts{State: ptrutil.Ptr(rivertype.JobStateCompleted), FinalizedAt: ptrutil.Ptr(bundle.completedDeleteHorizon.Add(-1 * time.Hour))})
+
+		require.NoError(t, cleaner.Start(ctx))
+
+		cleaner.TestSignals.DeadLetteredBatch.WaitOrTimeout()
+		cleaner.TestSignals.DeletedBatch.WaitOrTimeout()
+
+		_, err := bundle.exec.JobGetByID(ctx, &riverdriver.JobGetByIDParams{ID: discardedJob1.ID, Schema: cleaner.Config.Schema})
+		require.ErrorIs(t, err, rivertype.ErrNotFound)
+		deadLetter, err := bundle.exec.JobDeadLetterGetByID(ctx, &riverdriver.JobDeadLetterGetByIDParams{ID: discardedJob1.ID, Schema: cleaner.Config.Schema})
+		require.NoError(t, err)
+		require.Equal(t, discardedJob1.Kind, deadLetter.Kind)
+
+		_, err = bundle.exec.JobGetByID(ctx, &riverdriver.JobGetByIDParams{ID: discardedJob2.ID, Schema: cleaner.Config.Schema})
+		require.NotErrorIs(t, err, rivertype.ErrNotFound) // still there
+		_, err = bundle.exec.JobDeadLetterGetByID(ctx, &riverdriver.JobDeadLetterGetByIDParams{ID: discardedJob2.ID, Schema: cleaner.Config.Schema})
+		require.ErrorIs(t, err, rivertype.ErrNotFound)
+
+		_, err = bundle.exec.JobGetByID(ctx, &riverdriver.JobGetByIDParams{ID: completedJob.ID, Schema: cleaner.Config.Schema})
+		require.ErrorIs(t, err, rivertype.ErrNotFound)
+	})
+
 	t.Run("CustomizableInterval", func(t *testing.T) {
 		t.Parallel()
 
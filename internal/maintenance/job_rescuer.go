@@ -8,6 +8,7 @@ import (
 	"log/slog"
 	"time"
 
+	"github.com/riverqueue/river/internal/hooklookup"
 	"github.com/riverqueue/river/internal/jobexecutor"
 	"github.com/riverqueue/river/internal/workunit"
 	"github.com/riverqueue/river/riverdriver"
@@ -43,13 +44,27 @@ type JobRescuerConfig struct {
 	// override NextRetry.
 	ClientRetryPolicy jobexecutor.ClientRetryPolicy
 
+	// HookLookupByJob looks up job rescued hooks for specific job kinds. May be
+	// left nil, in which case only global hooks are invoked.
+	HookLookupByJob *hooklookup.JobHookLookup
+
+	// HookLookupGlobal looks up globally installed job rescued hooks. May be
+	// left nil, in which case no global hooks are invoked.
+	HookLookupGlobal hooklookup.HookLookupInterface
+
 	// Interval is the amount of time to wait between runs of the rescuer.
 	Interval time.Duration
 
 	// RescueAfter is the amount of time for a job to be active before it is
-	// considered stuck and should be rescued.
+	// considered stuck and should be rescued. Jobs holding a lease for their
+	// current attempt are instead considered stuck once their lease expires.
 	RescueAfter time.Duration
 
+	// RescuedJobFunc is invoked with each rescued job in its updated state
+	// after it's been rescued, so that an event can be emitted for it. May be
+	// nil.
+	RescuedJobFunc func(job *rivertype.JobRow)
+
 	// Schema where River tables are located. Empty string omits schema, causing
 	// Postgres to default to `search_path`.
 	Schema string
@@ -75,7 +90,9 @@ func (c *JobRescuerConfig) mustValidate() *JobRescuerConfig {
 }
 
 // JobRescuer periodically rescues jobs that have been executing for too long
-// and are considered to be "stuck".
+// and are considered to be "stuck". Jobs whose producer maintains a lease on
+// them are considered stuck as soon as their lease expires, regardless of how
+// long they've been running.
 type JobRescuer struct {
 	queueMaintainerServiceBase
 	startstop.BaseStartStop
@@ -92,8 +109,11 @@ func NewRescuer(archetype *baseservice.Archetype, config *JobRescuerConfig, exec
 	return baseservice.Init(archetype, &JobRescuer{
 		Config: (&JobRescuerConfig{
 			ClientRetryPolicy:   config.ClientRetryPolicy,
+			HookLookupByJob:     config.HookLookupByJob,
+			HookLookupGlobal:    config.HookLookupGlobal,
 			Interval:            valutil.ValOrDefault(config.Interval, JobRescuerIntervalDefault),
 			RescueAfter:         valutil.ValOrDefault(config.RescueAfter, JobRescuerRescueAfterDefault),
+			RescuedJobFunc:      config.RescuedJobFunc,
 			WorkUnitFactoryFunc: config.WorkUnitFactoryFunc,
 		}).mustValidate(),
 
@@ -151,8 +171,16 @@ type rescuerRunOnceResult struct {
 	NumJobsRetried   int64
 }
 
-type metadataWithCancelAttemptedAt struct {
+type metadataWithCancelAttemptedAtAndLease struct {
 	CancelAttemptedAt time.Time `json:"cancel_attempted_at"`
+	Lease             *jobLease `json:"river:lease"`
+}
+
+// jobLease is a lease on a running job, renewed periodically by the producer
+// working it. A lease only applies to the attempt that it was taken for.
+type jobLease struct {
+	Attempt   int       `json:"attempt"`
+	ExpiresAt time.Time `json:"expires_at"`
 }
 
 func (s *JobRescuer) runOnce(ctx context.Context) (*rescuerRunOnceResult, error) {
@@ -177,8 +205,11 @@ func (s *JobRescuer) runOnce(ctx context.Context) (*rescuerRunOnceResult, error)
 			State:       make([]string, 0, len(stuckJobs)),
 		}
 
+		// Hooks for rescued jobs that have any, keyed by job ID.
+		var rescuedJobHooks map[int64][]rivertype.Hook
+
 		for _, job := range stuckJobs {
-			var metadata metadataWithCancelAttemptedAt
+			var metadata metadataWithCancelAttemptedAtAndLease
 			if err := json.Unmarshal(job.Metadata, &metadata); err != nil {
 				return nil, fmt.Errorf("error unmarshaling job metadata: %w", err)
 			}
@@ -199,6 +230,13 @@ func (s *JobRescuer) runOnce(ctx context.Context) (*rescuerRunOnceResult, error)
 				rescueManyParams.FinalizedAt = append(rescueManyParams.FinalizedAt, ptrutil.ValOrDefault(finalizedAt, time.Time{}))
 				rescueManyParams.ScheduledAt = append(rescueManyParams.ScheduledAt, scheduledAt)
 				rescueManyParams.State = append(rescueManyParams.State, string(state))
+
+				if hooks := s.jobRescuedHooks(job); len(hooks) > 0 {
+					if rescuedJobHooks == nil {
+						rescuedJobHooks = make(map[int64][]rivertype.Hook)
+					}
+					rescuedJobHooks[job.ID] = hooks
+				}
 			}
 
 			if !metadata.CancelAttemptedAt.IsZero() {
@@ -207,7 +245,11 @@ func (s *JobRescuer) runOnce(ctx context.Context) (*rescuerRunOnceResult, error)
 				continue
 			}
 
-			retryDecision, retryAt := s.makeRetryDecision(ctx, job, now)
+			// A job whose lease has expired is no longer being worked, so it's
+			// rescued even if a kind-specific timeout hasn't elapsed yet.
+			leaseExpired := metadata.Lease != nil && metadata.Lease.Attempt == job.Attempt && metadata.Lease.ExpiresAt.Before(now)
+
+			retryDecision, retryAt := s.makeRetryDecision(ctx, job, now, leaseExpired)
 
 			switch retryDecision {
 			case jobRetryDecisionDiscard:
@@ -230,6 +272,12 @@ func (s *JobRescuer) runOnce(ctx context.Context) (*rescuerRunOnceResult, error)
 			}
 		}
 
+		if len(rescuedJobHooks) > 0 || (len(rescueManyParams.ID) > 0 && s.Config.RescuedJobFunc != nil) {
+			if err := s.notifyJobsRescued(ctx, rescueManyParams.ID, rescuedJobHooks); err != nil {
+				return nil, err
+			}
+		}
+
 		s.TestSignals.UpdatedBatch.Signal(struct{}{})
 
 		// Number of rows fetched was less than query `LIMIT` which means work is
@@ -244,14 +292,68 @@ func (s *JobRescuer) runOnce(ctx context.Context) (*rescuerRunOnceResult, error)
 	return res, nil
 }
 
+// Fetches rescued jobs in their updated state, then invokes their job rescued
+// hooks and RescuedJobFunc. Only jobs with hooks are fetched if there's no
+// RescuedJobFunc.
+func (s *JobRescuer) notifyJobsRescued(ctx context.Context, rescuedIDs []int64, rescuedJobHooks map[int64][]rivertype.Hook) error {
+	ids := rescuedIDs
+	if s.Config.RescuedJobFunc == nil {
+		ids = make([]int64, 0, len(rescuedJobHooks))
+		for id := range rescuedJobHooks {
+			ids = append(ids, id)
+		}
+	}
+
+	rescuedJobs, err := s.exec.JobGetByIDMany(ctx, &riverdriver.JobGetByIDManyParams{
+		ID:     ids,
+		Schema: s.Config.Schema,
+	})
+	if err != nil {
+		return fmt.Errorf("error fetching rescued jobs: %w", err)
+	}
+
+	for _, job := range rescuedJobs {
+		for _, hook := range rescuedJobHooks[job.ID] {
+			hook.(rivertype.HookJobRescued).JobRescued(ctx, job) //nolint:forcetypeassert
+		}
+
+		if s.Config.RescuedJobFunc != nil {
+			s.Config.RescuedJobFunc(job)
+		}
+	}
+
+	return nil
+}
+
+// Returns job rescued hooks for the given job, both global and those specific
+// to its kind.
+func (s *JobRescuer) jobRescuedHooks(job *rivertype.JobRow) []rivertype.Hook {
+	var hooks []rivertype.Hook
+	if s.Config.HookLookupGlobal != nil {
+		hooks = s.Config.HookLookupGlobal.ByHookKind(hooklookup.HookKindJobRescued)
+	}
+
+	if s.Config.HookLookupByJob != nil {
+		if workUnitFactory := s.Config.WorkUnitFactoryFunc(job.Kind); workUnitFactory != nil {
+			if hookLookup := workUnitFactory.MakeUnit(job).HookLookup(s.Config.HookLookupByJob); hookLookup != nil {
+				hooks = append(hooks, hookLookup.ByHookKind(hooklookup.HookKindJobRescued)...)
+			}
+		}
+	}
+
+	return hooks
+}
+
 func (s *JobRescuer) getStuckJobs(ctx context.Context) ([]*rivertype.JobRow, error) {
 	ctx, cancelFunc := context.WithTimeout(ctx, 30*time.Second)
 	defer cancelFunc()
 
-	stuckHorizon := time.Now().Add(-s.Config.RescueAfter)
+	now := time.Now()
+	stuckHorizon := now.Add(-s.Config.RescueAfter)
 
 	return s.exec.JobGetStuck(ctx, &riverdriver.JobGetStuckParams{
 		Max:          s.batchSize,
+		Now:          now,
 		Schema:       s.Config.Schema,
 		StuckHorizon: stuckHorizon,
 	})
@@ -268,8 +370,9 @@ const (
 )
 
 // makeRetryDecision decides whether or not a rescued job should be retried, and if so,
-// when.
-func (s *JobRescuer) makeRetryDecision(ctx context.Context, job *rivertype.JobRow, now time.Time) (jobRetryDecision, time.Time) {
+// when. Jobs with a kind-specific timeout that hasn't elapsed are ignored unless
+// their lease has expired.
+func (s *JobRescuer) makeRetryDecision(ctx context.Context, job *rivertype.JobRow, now time.Time, leaseExpired bool) (jobRetryDecision, time.Time) {
 	workUnitFactory := s.Config.WorkUnitFactoryFunc(job.Kind)
 	if workUnitFactory == nil {
 		s.Logger.ErrorContext(ctx, s.Name+": Attempted to rescue unhandled job kind, discarding",
@@ -283,7 +386,7 @@ func (s *JobRescuer) makeRetryDecision(ctx context.Context, job *rivertype.JobRo
 			slog.String("job_kind", job.Kind), slog.Int64("job_id", job.ID))
 	}
 
-	if workUnit.Timeout() != 0 && now.Sub(*job.AttemptedAt) < workUnit.Timeout() {
+	if !leaseExpired && workUnit.Timeout() != 0 && now.Sub(*job.AttemptedAt) < workUnit.Timeout() {
 		return jobRetryDecisionIgnore, time.Time{}
 	}
 
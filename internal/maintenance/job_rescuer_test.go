@@ -222,6 +222,105 @@ func TestJobRescuer(t *testing.T) {
 		require.Equal(rivertype.JobStateRetryable, notTimedOutJob2After.State)
 	})
 
+	t.Run("RescuesJobsWithExpiredLeases", func(t *testing.T) {
+		t.Parallel()
+
+		rescuer, bundle := setup(t)
+
+		leaseMetadata := func(attempt int, expiresAt time.Time) []byte {
+			return []byte(fmt.Sprintf(`{"river:lease": {"attempt": %d, "expires_at": %q}}`, attempt, expiresAt.UTC().Format(time.RFC3339Nano)))
+		}
+
+		var (
+			recentlyAttemptedAt = time.Now().Add(-1 * time.Minute)
+			leaseExpired        = time.Now().Add(-10 * time.Second)
+			leaseValid          = time.Now().Add(1 * time.Minute)
+		)
+
+		// Rescued because its lease has expired, even though it only started
+		// running recently.
+		expiredLeaseJob := testfactory.Job(ctx, t, bundle.exec, &testfactory.JobOpts{Attempt: ptrutil.Ptr(1), AttemptedAt: &recentlyAttemptedAt, Kind: ptrutil.Ptr(rescuerJobKind), MaxAttempts: ptrutil.Ptr(5), Metadata: leaseMetadata(1, leaseExpired), State: ptrutil.Ptr(rivertype.JobStateRunning)})
+
+		// Rescued despite a long kind-specific timeout because its lease has
+		// expired.
+		expiredLeaseLongTimeoutJob := testfactory.Job(ctx, t, bundle.exec, &testfactory.JobOpts{Attempt: ptrutil.Ptr(1), AttemptedAt: &recentlyAttemptedAt, Kind: ptrutil.Ptr(rescuerJobKindLongTimeout), MaxAttempts: ptrutil.Ptr(5), Metadata: leaseMetadata(1, leaseExpired), State: ptrutil.Ptr(rivertype.JobStateRunning)})
+
+		// Not rescued because its lease is still valid, even though it's been
+		// running for longer than the rescue horizon.
+		validLeaseJob := testfactory.Job(ctx, t, bundle.exec, &testfactory.JobOpts{Attempt: ptrutil.Ptr(1), AttemptedAt: ptrutil.Ptr(bundle.rescueHorizon.Add(-1 * time.Hour)), Kind: ptrutil.Ptr(rescuerJobKind), MaxAttempts: ptrutil.Ptr(5), Metadata: leaseMetadata(1, leaseValid), State: ptrutil.Ptr(rivertype.JobStateRunning)})
+
+		// Not rescued because its expired lease was taken for a previous
+		// attempt, so the rescue horizon applies instead.
+		previousAttemptLeaseJob := testfactory.Job(ctx, t, bundle.exec, &testfactory.JobOpts{Attempt: ptrutil.Ptr(2), AttemptedAt: &recentlyAttemptedAt, Kind: ptrutil.Ptr(rescuerJobKind), MaxAttempts: ptrutil.Ptr(5), Metadata: leaseMetadata(1, leaseExpired), State: ptrutil.Ptr(rivertype.JobStateRunning)})
+
+		require.NoError(t, rescuer.Start(ctx))
+
+		rescuer.TestSignals.FetchedBatch.WaitOrTimeout()
+		rescuer.TestSignals.UpdatedBatch.WaitOrTimeout()
+
+		requireState := func(job *rivertype.JobRow, expectedState rivertype.JobState) {
+			t.Helper()
+
+			jobAfter, err := bundle.exec.JobGetByID(ctx, &riverdriver.JobGetByIDParams{ID: job.ID, Schema: rescuer.Config.Schema})
+			require.NoError(t, err)
+			require.Equal(t, expectedState, jobAfter.State)
+		}
+
+		requireState(expiredLeaseJob, rivertype.JobStateRetryable)
+		requireState(expiredLeaseLongTimeoutJob, rivertype.JobStateRetryable)
+		requireState(validLeaseJob, rivertype.JobStateRunning)
+		requireState(previousAttemptLeaseJob, rivertype.JobStateRunning)
+	})
+
+	t.Run("InvokesJobRescuedHooks", func(t *testing.T) {
+		t.Parallel()
+
+		rescuer, bundle := setup(t)
+
+		rescuedJobsChan := make(chan *rivertype.JobRow, 10)
+		rescuer.Config.HookLookupGlobal = hooklookup.NewHookLookup([]rivertype.Hook{
+			&testHookJobRescued{rescuedJobsChan: rescuedJobsChan},
+		})
+
+		stuckJob := testfactory.Job(ctx, t, bundle.exec, &testfactory.JobOpts{Kind: ptrutil.Ptr(rescuerJobKind), State: ptrutil.Ptr(rivertype.JobStateRunning), AttemptedAt: ptrutil.Ptr(bundle.rescueHorizon.Add(-1 * time.Hour)), MaxAttempts: ptrutil.Ptr(5)})
+		notStuckJob := testfactory.Job(ctx, t, bundle.exec, &testfactory.JobOpts{Kind: ptrutil.Ptr(rescuerJobKind), State: ptrutil.Ptr(rivertype.JobStateRunning), AttemptedAt: ptrutil.Ptr(bundle.rescueHorizon.Add(1 * time.Minute)), MaxAttempts: ptrutil.Ptr(5)})
+
+		require.NoError(t, rescuer.Start(ctx))
+
+		rescuer.TestSignals.FetchedBatch.WaitOrTimeout()
+		rescuer.TestSignals.UpdatedBatch.WaitOrTimeout()
+
+		rescuedJob := riversharedtest.WaitOrTimeout(t, rescuedJobsChan)
+		require.Equal(t, stuckJob.ID, rescuedJob.ID)
+		require.Equal(t, rivertype.JobStateRetryable, rescuedJob.State)
+
+		select {
+		case job := <-rescuedJobsChan:
+			require.FailNow(t, "unexpected rescued job", "job ID %d (not stuck job ID %d)", job.ID, notStuckJob.ID)
+		default:
+		}
+	})
+
+	t.Run("InvokesRescuedJobFunc", func(t *testing.T) {
+		t.Parallel()
+
+		rescuer, bundle := setup(t)
+
+		rescuedJobsChan := make(chan *rivertype.JobRow, 10)
+		rescuer.Config.RescuedJobFunc = func(job *rivertype.JobRow) { rescuedJobsChan <- job }
+
+		stuckJob := testfactory.Job(ctx, t, bundle.exec, &testfactory.JobOpts{Kind: ptrutil.Ptr(rescuerJobKind), State: ptrutil.Ptr(rivertype.JobStateRunning), Attempt: ptrutil.Ptr(5), AttemptedAt: ptrutil.Ptr(bundle.rescueHorizon.Add(-1 * time.Hour)), MaxAttempts: ptrutil.Ptr(5)})
+
+		require.NoError(t, rescuer.Start(ctx))
+
+		rescuer.TestSignals.FetchedBatch.WaitOrTimeout()
+		rescuer.TestSignals.UpdatedBatch.WaitOrTimeout()
+
+		rescuedJob := riversharedtest.WaitOrTimeout(t, rescuedJobsChan)
+		require.Equal(t, stuckJob.ID, rescuedJob.ID)
+		require.Equal(t, rivertype.JobStateDiscarded, rescuedJob.State)
+	})
+
 	t.Run("RescuesInBatches", func(t *testing.T) {
 		t.Parallel()
 
@@ -328,3 +427,15 @@ func TestJobRescuer(t *testing.T) {
 		require.Equal(t, rivertype.JobStateDiscarded, job2After.State)
 	})
 }
+
+var _ rivertype.HookJobRescued = &testHookJobRescued{}
+
+type testHookJobRescued struct {
+	rivertype.Hook
+
+	rescuedJobsChan chan *rivertype.JobRow
+}
+
+func (h *testHookJobRescued) JobRescued(ctx context.Context, job *rivertype.JobRow) {
+	h.rescuedJobsChan <- job
+}
@@ -62,6 +62,11 @@ type PeriodicJobEnqueuerConfig struct {
 	// Insert is the function to call to insert jobs into the database.
 	Insert InsertFunc
 
+	// InsertedJobFunc is invoked with each inserted job once its insert has
+	// been committed, so that an event can be emitted for it. Jobs skipped as
+	// duplicates of an existing unique job are excluded. May be nil.
+	InsertedJobFunc func(job *rivertype.JobRow)
+
 	// PeriodicJobs are the periodic jobs with which to configure the enqueuer.
 	PeriodicJobs []*PeriodicJob
 }
@@ -104,6 +109,7 @@ func NewPeriodicJobEnqueuer(archetype *baseservice.Archetype, config *PeriodicJo
 		Config: (&PeriodicJobEnqueuerConfig{
 			AdvisoryLockPrefix: config.AdvisoryLockPrefix,
 			Insert:             config.Insert,
+			InsertedJobFunc:    config.InsertedJobFunc,
 			PeriodicJobs:       config.PeriodicJobs,
 		}).mustValidate(),
 
@@ -340,8 +346,9 @@ func (s *PeriodicJobEnqueuer) insertBatch(ctx context.Context, insertParamsMany
 	}
 	defer tx.Rollback(ctx)
 
+	var insertResults []*rivertype.JobInsertResult
 	if len(insertParamsMany) > 0 {
-		_, err := s.Config.Insert(ctx, tx, insertParamsMany)
+		insertResults, err = s.Config.Insert(ctx, tx, insertParamsMany)
 		if err != nil {
 			s.Logger.ErrorContext(ctx, s.Name+": Error inserting periodic jobs",
 				"error", err.Error(), "num_jobs", len(insertParamsMany))
@@ -354,6 +361,14 @@ func (s *PeriodicJobEnqueuer) insertBatch(ctx context.Context, insertParamsMany
 		return
 	}
 
+	if s.Config.InsertedJobFunc != nil {
+		for _, insertResult := range insertResults {
+			if !insertResult.UniqueSkippedAsDuplicate {
+				s.Config.InsertedJobFunc(insertResult.Job)
+			}
+		}
+	}
+
 	s.TestSignals.InsertedJobs.Signal(struct{}{})
 }
 
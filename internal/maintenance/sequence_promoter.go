@@ -0,0 +1,208 @@
+package maintenance
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"time"
+
+	"github.com/tidwall/gjson"
+
+	"github.com/riverqueue/river/riverdriver"
+	"github.com/riverqueue/river/rivershared/baseservice"
+	"github.com/riverqueue/river/rivershared/startstop"
+	"github.com/riverqueue/river/rivershared/testsignal"
+	"github.com/riverqueue/river/rivershared/util/randutil"
+	"github.com/riverqueue/river/rivershared/util/serviceutil"
+	"github.com/riverqueue/river/rivershared/util/timeutil"
+	"github.com/riverqueue/river/rivershared/util/valutil"
+	"github.com/riverqueue/river/rivertype"
+)
+
+const (
+	SequencePromoterIntervalDefault = 1 * time.Second
+)
+
+// Test-only properties.
+type SequencePromoterTestSignals struct {
+	PromotedSequence testsignal.TestSignal[struct{}] // notifies when runOnce finishes a pass
+}
+
+func (ts *SequencePromoterTestSignals) Init() {
+	ts.PromotedSequence.Init()
+}
+
+type SequencePromoterConfig struct {
+	// Interval is the amount of time between periodic checks for sequenced
+	// jobs that have reached the head of their sequence.
+	Interval time.Duration
+
+	// NotifyInsert is a function to call to emit notifications for queues
+	// where sequenced jobs were made available.
+	NotifyInsert NotifyInsertFunc
+
+	// Schema where River tables are located. Empty string omits schema, causing
+	// Postgres to default to `search_path`.
+	Schema string
+}
+
+func (c *SequencePromoterConfig) mustValidate() *SequencePromoterConfig {
+	if c.Interval <= 0 {
+		panic("SequencePromoterConfig.Interval must be above zero")
+	}
+
+	return c
+}
+
+// SequencePromoter periodically checks pending sequenced jobs against the
+// job before them in their sequence. Sequenced jobs are inserted as `pending`,
+// and once the job before one has completed (or been cancelled or discarded,
+// if the sequence is configured to continue past those), it's moved to
+// `available` (or `scheduled` if its scheduled time is still in the future) so
+// that it's eligible to be worked. This way only the head of each sequence can
+// be worked at any given time. All state is kept in the database, so a newly
+// elected leader picks up where the last one left off.
+type SequencePromoter struct {
+	queueMaintainerServiceBase
+	startstop.BaseStartStop
+
+	// exported for test purposes
+	TestSignals SequencePromoterTestSignals
+
+	batchSize int // configurable for test purposes
+	config    *SequencePromoterConfig
+	exec      riverdriver.Executor
+}
+
+func NewSequencePromoter(archetype *baseservice.Archetype, config *SequencePromoterConfig, exec riverdriver.Executor) *SequencePromoter {
+	return baseservice.Init(archetype, &SequencePromoter{
+		batchSize: BatchSizeDefault,
+		config: (&SequencePromoterConfig{
+			Interval:     valutil.ValOrDefault(config.Interval, SequencePromoterIntervalDefault),
+			NotifyInsert: config.NotifyInsert,
+			Schema:       config.Schema,
+		}).mustValidate(),
+		exec: exec,
+	})
+}
+
+func (s *SequencePromoter) Start(ctx context.Context) error { //nolint:dupl
+	ctx, shouldStart, started, stopped := s.StartInit(ctx)
+	if !shouldStart {
+		return nil
+	}
+
+	s.StaggerStart(ctx)
+
+	go func() {
+		started()
+		defer stopped() // this defer should come first so it's last out
+
+		s.Logger.DebugContext(ctx, s.Name+logPrefixRunLoopStarted)
+		defer s.Logger.DebugContext(ctx, s.Name+logPrefixRunLoopStopped)
+
+		ticker := timeutil.NewTickerWithInitialTick(ctx, s.config.Interval)
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case <-ticker.C:
+			}
+
+			res, err := s.runOnce(ctx)
+			if err != nil {
+				if !errors.Is(err, context.Canceled) {
+					s.Logger.ErrorContext(ctx, s.Name+": Error promoting sequence jobs", slog.String("error", err.Error()))
+				}
+				continue
+			}
+
+			if res.NumJobsPromoted > 0 {
+				s.Logger.InfoContext(ctx, s.Name+logPrefixRanSuccessfully,
+					slog.Int("num_jobs_promoted", res.NumJobsPromoted),
+				)
+			}
+		}
+	}()
+
+	return nil
+}
+
+type sequencePromoterRunOnceResult struct {
+	NumJobsPromoted int
+}
+
+func (s *SequencePromoter) runOnce(ctx context.Context) (*sequencePromoterRunOnceResult, error) {
+	var (
+		afterSequenceKey *string
+		res              = &sequencePromoterRunOnceResult{}
+	)
+
+	for {
+		// Wrapped in a function so that defers run as expected.
+		numExamined, err := func() (int, error) {
+			ctx, cancelFunc := context.WithTimeout(ctx, 30*time.Second)
+			defer cancelFunc()
+
+			tx, err := s.exec.Begin(ctx)
+			if err != nil {
+				return 0, fmt.Errorf("error starting transaction: %w", err)
+			}
+			defer tx.Rollback(ctx)
+
+			jobs, err := tx.JobSequencePromote(ctx, &riverdriver.JobSequencePromoteParams{
+				AfterSequenceKey: afterSequenceKey,
+				Max:              s.batchSize,
+				Now:              s.Time.NowUTC(),
+				Schema:           s.config.Schema,
+			})
+			if err != nil {
+				return 0, fmt.Errorf("error promoting sequence jobs: %w", err)
+			}
+
+			queues := make([]string, 0, len(jobs))
+
+			// Jobs come back ordered by sequence key, so the next batch picks up
+			// with the sequences after the last one examined.
+			if len(jobs) > 0 {
+				sequenceKey := gjson.GetBytes(jobs[len(jobs)-1].Metadata, "river:sequence_key").String()
+				afterSequenceKey = &sequenceKey
+			}
+
+			for _, job := range jobs {
+				// Don't include a `default` so `exhaustive` lint can detect omissions.
+				switch job.State {
+				case rivertype.JobStateAvailable:
+					queues = append(queues, job.Queue)
+					res.NumJobsPromoted++
+				case rivertype.JobStateScheduled:
+					res.NumJobsPromoted++
+				case rivertype.JobStateCancelled, rivertype.JobStateCompleted, rivertype.JobStateDiscarded, rivertype.JobStatePending, rivertype.JobStateRetryable, rivertype.JobStateRunning:
+				}
+			}
+
+			if len(queues) > 0 && s.config.NotifyInsert != nil {
+				if err := s.config.NotifyInsert(ctx, tx, queues); err != nil {
+					return 0, fmt.Errorf("error notifying insert: %w", err)
+				}
+			}
+
+			return len(jobs), tx.Commit(ctx)
+		}()
+		if err != nil {
+			return nil, err
+		}
+
+		s.TestSignals.PromotedSequence.Signal(struct{}{})
+
+		// Examined was less than query `LIMIT` which means work is done.
+		if numExamined < s.batchSize {
+			break
+		}
+
+		serviceutil.CancellableSleep(ctx, randutil.DurationBetween(BatchBackoffMin, BatchBackoffMax))
+	}
+
+	return res, nil
+}
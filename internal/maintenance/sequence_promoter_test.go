@@ -0,0 +1,206 @@
+package maintenance
+
+import (
+	"context"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/riverqueue/river/internal/rivercommon"
+	"github.com/riverqueue/river/internal/riverinternaltest"
+	"github.com/riverqueue/river/riverdriver"
+	"github.com/riverqueue/river/riverdriver/riverpgxv5"
+	"github.com/riverqueue/river/rivershared/riversharedtest"
+	"github.com/riverqueue/river/rivershared/startstoptest"
+	"github.com/riverqueue/river/rivershared/testfactory"
+	"github.com/riverqueue/river/rivershared/util/ptrutil"
+	"github.com/riverqueue/river/rivertype"
+)
+
+func TestSequencePromoter(t *testing.T) {
+	t.Parallel()
+
+	ctx := context.Background()
+
+	type testBundle struct {
+		exec                 riverdriver.Executor
+		notificationsByQueue map[string]int
+	}
+
+	setup := func(t *testing.T) (*SequencePromoter, *testBundle) {
+		t.Helper()
+
+		tx := riverinternaltest.TestTx(ctx, t)
+		bundle := &testBundle{
+			exec:                 riverpgxv5.New(nil).UnwrapExecutor(tx),
+			notificationsByQueue: make(map[string]int),
+		}
+
+		promoter := NewSequencePromoter(
+			riversharedtest.BaseServiceArchetype(t),
+			&SequencePromoterConfig{
+				NotifyInsert: func(ctx context.Context, tx riverdriver.ExecutorTx, queues []string) error {
+					for _, queue := range queues {
+						bundle.notificationsByQueue[queue]++
+					}
+					return nil
+				},
+			},
+			bundle.exec)
+		promoter.TestSignals.Init()
+		t.Cleanup(promoter.Stop)
+
+		return promoter, bundle
+	}
+
+	sequenceMetadata := func(sequenceKey string) []byte {
+		return []byte(fmt.Sprintf(`{"river:sequence_key": %q}`, sequenceKey))
+	}
+
+	sequenceMetadataContinueOnDiscarded := func(sequenceKey string) []byte {
+		return []byte(fmt.Sprintf(`{"river:sequence_key": %q, "river:sequence_continue_on_discarded": true}`, sequenceKey))
+	}
+
+	requireJobState := func(t *testing.T, exec riverdriver.Executor, job *rivertype.JobRow, expectedState rivertype.JobState) {
+		t.Helper()
+		newJob, err := exec.JobGetByID(ctx, &riverdriver.JobGetByIDParams{ID: job.ID})
+		require.NoError(t, err)
+		require.Equal(t, expectedState, newJob.State)
+	}
+
+	t.Run("Defaults", func(t *testing.T) {
+		t.Parallel()
+
+		promoter := NewSequencePromoter(riversharedtest.BaseServiceArchetype(t), &SequencePromoterConfig{}, nil)
+
+		require.Equal(t, SequencePromoterIntervalDefault, promoter.config.Interval)
+		require.Equal(t, BatchSizeDefault, promoter.batchSize)
+	})
+
+	t.Run("StartStopStress", func(t *testing.T) {
+		t.Parallel()
+
+		promoter, _ := setup(t)
+		promoter.Logger = riversharedtest.LoggerWarn(t)      // loop started/stop log is very noisy; suppress
+		promoter.TestSignals = SequencePromoterTestSignals{} // deinit so channels don't fill
+
+		startstoptest.Stress(ctx, t, promoter)
+	})
+
+	t.Run("PromotesSequenceHeads", func(t *testing.T) {
+		t.Parallel()
+
+		promoter, bundle := setup(t)
+
+		// Sequence with no previous jobs, so its first job is the head.
+		seq1Job1 := testfactory.Job(ctx, t, bundle.exec, &testfactory.JobOpts{Metadata: sequenceMetadata("seq1"), State: ptrutil.Ptr(rivertype.JobStatePending)})
+		seq1Job2 := testfactory.Job(ctx, t, bundle.exec, &testfactory.JobOpts{Metadata: sequenceMetadata("seq1"), State: ptrutil.Ptr(rivertype.JobStatePending)})
+
+		// Sequence whose previous job completed.
+		testfactory.Job(ctx, t, bundle.exec, &testfactory.JobOpts{Metadata: sequenceMetadata("seq2"), State: ptrutil.Ptr(rivertype.JobStateCompleted)})
+		seq2Job2 := testfactory.Job(ctx, t, bundle.exec, &testfactory.JobOpts{Metadata: sequenceMetadata("seq2"), State: ptrutil.Ptr(rivertype.JobStatePending)})
+
+		// Sequence whose previous job is still running.
+		testfactory.Job(ctx, t, bundle.exec, &testfactory.JobOpts{Metadata: sequenceMetadata("seq3"), State: ptrutil.Ptr(rivertype.JobStateRunning)})
+		seq3Job2 := testfactory.Job(ctx, t, bundle.exec, &testfactory.JobOpts{Metadata: sequenceMetadata("seq3"), State: ptrutil.Ptr(rivertype.JobStatePending)})
+
+		// Scheduled in the future, so promoted to scheduled instead of available.
+		seq4Job1 := testfactory.Job(ctx, t, bundle.exec, &testfactory.JobOpts{Metadata: sequenceMetadata("seq4"), ScheduledAt: ptrutil.Ptr(time.Now().Add(time.Hour)), State: ptrutil.Ptr(rivertype.JobStatePending)})
+
+		require.NoError(t, promoter.Start(ctx))
+		promoter.TestSignals.PromotedSequence.WaitOrTimeout()
+
+		requireJobState(t, bundle.exec, seq1Job1, rivertype.JobStateAvailable)
+		requireJobState(t, bundle.exec, seq1Job2, rivertype.JobStatePending)
+		requireJobState(t, bundle.exec, seq2Job2, rivertype.JobStateAvailable)
+		requireJobState(t, bundle.exec, seq3Job2, rivertype.JobStatePending)
+		requireJobState(t, bundle.exec, seq4Job1, rivertype.JobStateScheduled)
+
+		require.Equal(t, map[string]int{rivercommon.QueueDefault: 2}, bundle.notificationsByQueue)
+	})
+
+	t.Run("DiscardedHeadBlocksSequence", func(t *testing.T) {
+		t.Parallel()
+
+		promoter, bundle := setup(t)
+
+		testfactory.Job(ctx, t, bundle.exec, &testfactory.JobOpts{Metadata: sequenceMetadata("seq1"), State: ptrutil.Ptr(rivertype.JobStateDiscarded)})
+		blockedJob := testfactory.Job(ctx, t, bundle.exec, &testfactory.JobOpts{Metadata: sequenceMetadata("seq1"), State: ptrutil.Ptr(rivertype.JobStatePending)})
+
+		testfactory.Job(ctx, t, bundle.exec, &testfactory.JobOpts{Metadata: sequenceMetadata("seq2"), State: ptrutil.Ptr(rivertype.JobStateCancelled)})
+		blockedJob2 := testfactory.Job(ctx, t, bundle.exec, &testfactory.JobOpts{Metadata: sequenceMetadata("seq2"), State: ptrutil.Ptr(rivertype.JobStatePending)})
+
+		require.NoError(t, promoter.Start(ctx))
+		promoter.TestSignals.PromotedSequence.WaitOrTimeout()
+
+		requireJobState(t, bundle.exec, blockedJob, rivertype.JobStatePending)
+		requireJobState(t, bundle.exec, blockedJob2, rivertype.JobStatePending)
+	})
+
+	t.Run("DiscardedHeadContinuesSequence", func(t *testing.T) {
+		t.Parallel()
+
+		promoter, bundle := setup(t)
+
+		testfactory.Job(ctx, t, bundle.exec, &testfactory.JobOpts{Metadata: sequenceMetadataContinueOnDiscarded("seq1"), State: ptrutil.Ptr(rivertype.JobStateDiscarded)})
+		nextJob := testfactory.Job(ctx, t, bundle.exec, &testfactory.JobOpts{Metadata: sequenceMetadataContinueOnDiscarded("seq1"), State: ptrutil.Ptr(rivertype.JobStatePending)})
+
+		require.NoError(t, promoter.Start(ctx))
+		promoter.TestSignals.PromotedSequence.WaitOrTimeout()
+
+		requireJobState(t, bundle.exec, nextJob, rivertype.JobStateAvailable)
+	})
+
+	t.Run("PromotesInBatches", func(t *testing.T) {
+		t.Parallel()
+
+		promoter, bundle := setup(t)
+		promoter.batchSize = 10 // reduced size for test speed
+
+		// Add one to our chosen batch size to get one extra job and therefore
+		// one extra batch, ensuring that we've tested working multiple.
+		numSequences := promoter.batchSize + 1
+
+		sequenceJobs := make([]*rivertype.JobRow, numSequences)
+		for i := range numSequences {
+			sequenceJobs[i] = testfactory.Job(ctx, t, bundle.exec, &testfactory.JobOpts{Metadata: sequenceMetadata(fmt.Sprintf("seq_%d", i)), State: ptrutil.Ptr(rivertype.JobStatePending)})
+		}
+
+		require.NoError(t, promoter.Start(ctx))
+
+		// See comment above. Exactly two batches are expected.
+		promoter.TestSignals.PromotedSequence.WaitOrTimeout()
+		promoter.TestSignals.PromotedSequence.WaitOrTimeout()
+
+		for _, job := range sequenceJobs {
+			requireJobState(t, bundle.exec, job, rivertype.JobStateAvailable)
+		}
+	})
+
+	t.Run("CustomizableInterval", func(t *testing.T) {
+		t.Parallel()
+
+		promoter, _ := setup(t)
+		promoter.config.Interval = 1 * time.Microsecond
+
+		require.NoError(t, promoter.Start(ctx))
+
+		// This should trigger ~immediately every time:
+		for i := range 5 {
+			t.Logf("Iteration %d", i)
+			promoter.TestSignals.PromotedSequence.WaitOrTimeout()
+		}
+	})
+
+	t.Run("StopsImmediately", func(t *testing.T) {
+		t.Parallel()
+
+		promoter, _ := setup(t)
+		promoter.config.Interval = time.Minute // should only trigger once for the initial run
+
+		require.NoError(t, promoter.Start(ctx))
+		promoter.Stop()
+	})
+}
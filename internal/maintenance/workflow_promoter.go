@@ -0,0 +1,203 @@
+package maintenance
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"time"
+
+	"github.com/riverqueue/river/riverdriver"
+	"github.com/riverqueue/river/rivershared/baseservice"
+	"github.com/riverqueue/river/rivershared/startstop"
+	"github.com/riverqueue/river/rivershared/testsignal"
+	"github.com/riverqueue/river/rivershared/util/randutil"
+	"github.com/riverqueue/river/rivershared/util/serviceutil"
+	"github.com/riverqueue/river/rivershared/util/timeutil"
+	"github.com/riverqueue/river/rivershared/util/valutil"
+	"github.com/riverqueue/river/rivertype"
+)
+
+const (
+	WorkflowPromoterIntervalDefault = 1 * time.Second
+)
+
+// Test-only properties.
+type WorkflowPromoterTestSignals struct {
+	PromotedBatch testsignal.TestSignal[struct{}] // notifies when runOnce finishes a pass
+}
+
+func (ts *WorkflowPromoterTestSignals) Init() {
+	ts.PromotedBatch.Init()
+}
+
+type WorkflowPromoterConfig struct {
+	// Interval is the amount of time between periodic checks for pending
+	// workflow tasks whose dependencies have finished.
+	Interval time.Duration
+
+	// NotifyInsert is a function to call to emit notifications for queues
+	// where tasks were made available.
+	NotifyInsert NotifyInsertFunc
+
+	// Schema where River tables are located. Empty string omits schema, causing
+	// Postgres to default to `search_path`.
+	Schema string
+}
+
+func (c *WorkflowPromoterConfig) mustValidate() *WorkflowPromoterConfig {
+	if c.Interval <= 0 {
+		panic("WorkflowPromoterConfig.Interval must be above zero")
+	}
+
+	return c
+}
+
+// WorkflowPromoter periodically checks pending workflow tasks against the
+// state of their dependencies. Tasks whose dependencies have all completed are
+// moved to `available` (or `scheduled` if their scheduled time is still in the
+// future) so that they're eligible to be worked, while tasks with a cancelled
+// or discarded dependency are cancelled (or discarded) so that they never
+// run.
+type WorkflowPromoter struct {
+	queueMaintainerServiceBase
+	startstop.BaseStartStop
+
+	// exported for test purposes
+	TestSignals WorkflowPromoterTestSignals
+
+	batchSize int // configurable for test purposes
+	config    *WorkflowPromoterConfig
+	exec      riverdriver.Executor
+}
+
+func NewWorkflowPromoter(archetype *baseservice.Archetype, config *WorkflowPromoterConfig, exec riverdriver.Executor) *WorkflowPromoter {
+	return baseservice.Init(archetype, &WorkflowPromoter{
+		batchSize: BatchSizeDefault,
+		config: (&WorkflowPromoterConfig{
+			Interval:     valutil.ValOrDefault(config.Interval, WorkflowPromoterIntervalDefault),
+			NotifyInsert: config.NotifyInsert,
+			Schema:       config.Schema,
+		}).mustValidate(),
+		exec: exec,
+	})
+}
+
+func (s *WorkflowPromoter) Start(ctx context.Context) error { //nolint:dupl
+	ctx, shouldStart, started, stopped := s.StartInit(ctx)
+	if !shouldStart {
+		return nil
+	}
+
+	s.StaggerStart(ctx)
+
+	go func() {
+		started()
+		defer stopped() // this defer should come first so it's last out
+
+		s.Logger.DebugContext(ctx, s.Name+logPrefixRunLoopStarted)
+		defer s.Logger.DebugContext(ctx, s.Name+logPrefixRunLoopStopped)
+
+		ticker := timeutil.NewTickerWithInitialTick(ctx, s.config.Interval)
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case <-ticker.C:
+			}
+
+			res, err := s.runOnce(ctx)
+			if err != nil {
+				if !errors.Is(err, context.Canceled) {
+					s.Logger.ErrorContext(ctx, s.Name+": Error promoting workflow tasks", slog.String("error", err.Error()))
+				}
+				continue
+			}
+
+			if res.NumTasksPromoted > 0 || res.NumTasksFailed > 0 {
+				s.Logger.InfoContext(ctx, s.Name+logPrefixRanSuccessfully,
+					slog.Int("num_tasks_failed", res.NumTasksFailed),
+					slog.Int("num_tasks_promoted", res.NumTasksPromoted),
+				)
+			}
+		}
+	}()
+
+	return nil
+}
+
+type workflowPromoterRunOnceResult struct {
+	NumTasksFailed   int
+	NumTasksPromoted int
+}
+
+func (s *WorkflowPromoter) runOnce(ctx context.Context) (*workflowPromoterRunOnceResult, error) {
+	var (
+		afterID int64
+		res     = &workflowPromoterRunOnceResult{}
+	)
+
+	for {
+		// Wrapped in a function so that defers run as expected.
+		numExamined, err := func() (int, error) {
+			ctx, cancelFunc := context.WithTimeout(ctx, 30*time.Second)
+			defer cancelFunc()
+
+			tx, err := s.exec.Begin(ctx)
+			if err != nil {
+				return 0, fmt.Errorf("error starting transaction: %w", err)
+			}
+			defer tx.Rollback(ctx)
+
+			tasks, err := tx.JobWorkflowPromote(ctx, &riverdriver.JobWorkflowPromoteParams{
+				AfterID: afterID,
+				Max:     s.batchSize,
+				Now:     s.Time.NowUTC(),
+				Schema:  s.config.Schema,
+			})
+			if err != nil {
+				return 0, fmt.Errorf("error promoting workflow tasks: %w", err)
+			}
+
+			queues := make([]string, 0, len(tasks))
+
+			for _, task := range tasks {
+				afterID = max(afterID, task.ID)
+
+				// Don't include a `default` so `exhaustive` lint can detect omissions.
+				switch task.State {
+				case rivertype.JobStateAvailable:
+					queues = append(queues, task.Queue)
+					res.NumTasksPromoted++
+				case rivertype.JobStateScheduled:
+					res.NumTasksPromoted++
+				case rivertype.JobStateCancelled, rivertype.JobStateDiscarded:
+					res.NumTasksFailed++
+				case rivertype.JobStateCompleted, rivertype.JobStatePending, rivertype.JobStateRetryable, rivertype.JobStateRunning:
+				}
+			}
+
+			if len(queues) > 0 && s.config.NotifyInsert != nil {
+				if err := s.config.NotifyInsert(ctx, tx, queues); err != nil {
+					return 0, fmt.Errorf("error notifying insert: %w", err)
+				}
+			}
+
+			return len(tasks), tx.Commit(ctx)
+		}()
+		if err != nil {
+			return nil, err
+		}
+
+		s.TestSignals.PromotedBatch.Signal(struct{}{})
+
+		// Examined was less than query `LIMIT` which means work is done.
+		if numExamined < s.batchSize {
+			break
+		}
+
+		serviceutil.CancellableSleep(ctx, randutil.DurationBetween(BatchBackoffMin, BatchBackoffMax))
+	}
+
+	return res, nil
+}
@@ -0,0 +1,258 @@
+package maintenance
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"sort"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/riverqueue/river/internal/rivercommon"
+	"github.com/riverqueue/river/internal/riverinternaltest"
+	"github.com/riverqueue/river/riverdriver"
+	"github.com/riverqueue/river/riverdriver/riverpgxv5"
+	"github.com/riverqueue/river/rivershared/riversharedtest"
+	"github.com/riverqueue/river/rivershared/startstoptest"
+	"github.com/riverqueue/river/rivershared/testfactory"
+	"github.com/riverqueue/river/rivershared/util/ptrutil"
+	"github.com/riverqueue/river/rivertype"
+)
+
+func TestWorkflowPromoter(t *testing.T) {
+	t.Parallel()
+
+	ctx := context.Background()
+
+	type testBundle struct {
+		exec                 riverdriver.Executor
+		notificationsByQueue map[string]int
+	}
+
+	setup := func(t *testing.T, exec riverdriver.Executor) (*WorkflowPromoter, *testBundle) {
+		t.Helper()
+
+		bundle := &testBundle{
+			exec:                 exec,
+			notificationsByQueue: make(map[string]int),
+		}
+
+		promoter := NewWorkflowPromoter(
+			riversharedtest.BaseServiceArchetype(t),
+			&WorkflowPromoterConfig{
+				NotifyInsert: func(ctx context.Context, tx riverdriver.ExecutorTx, queues []string) error {
+					for _, queue := range queues {
+						bundle.notificationsByQueue[queue]++
+					}
+					return nil
+				},
+			},
+			bundle.exec)
+		promoter.TestSignals.Init()
+		t.Cleanup(promoter.Stop)
+
+		return promoter, bundle
+	}
+
+	setupTx := func(t *testing.T) (*WorkflowPromoter, *testBundle) {
+		t.Helper()
+		tx := riverinternaltest.TestTx(ctx, t)
+		return setup(t, riverpgxv5.New(nil).UnwrapExecutor(tx))
+	}
+
+	taskMetadata := func(workflowID, taskName string, deps ...string) []byte {
+		metadata, err := json.Marshal(map[string]any{
+			"river:workflow_id":   workflowID,
+			"river:workflow_task": taskName,
+			"river:workflow_deps": append([]string{}, deps...),
+		})
+		require.NoError(t, err)
+		return metadata
+	}
+
+	requireJobState := func(t *testing.T, exec riverdriver.Executor, job *rivertype.JobRow, expectedState rivertype.JobState) *rivertype.JobRow {
+		t.Helper()
+		newJob, err := exec.JobGetByID(ctx, &riverdriver.JobGetByIDParams{ID: job.ID})
+		require.NoError(t, err)
+		require.Equal(t, expectedState, newJob.State)
+		return newJob
+	}
+
+	t.Run("Defaults", func(t *testing.T) {
+		t.Parallel()
+
+		promoter := NewWorkflowPromoter(riversharedtest.BaseServiceArchetype(t), &WorkflowPromoterConfig{}, nil)
+
+		require.Equal(t, WorkflowPromoterIntervalDefault, promoter.config.Interval)
+		require.Equal(t, BatchSizeDefault, promoter.batchSize)
+	})
+
+	t.Run("StartStopStress", func(t *testing.T) {
+		t.Parallel()
+
+		promoter, _ := setupTx(t)
+		promoter.Logger = riversharedtest.LoggerWarn(t)      // loop started/stop log is very noisy; suppress
+		promoter.TestSignals = WorkflowPromoterTestSignals{} // deinit so channels don't fill
+
+		startstoptest.Stress(ctx, t, promoter)
+	})
+
+	t.Run("PromotesTasksWithCompletedDeps", func(t *testing.T) {
+		t.Parallel()
+
+		promoter, bundle := setupTx(t)
+		now := time.Now().UTC()
+
+		taskA := testfactory.Job(ctx, t, bundle.exec, &testfactory.JobOpts{Metadata: taskMetadata("wf1", "a"), State: ptrutil.Ptr(rivertype.JobStateCompleted)})
+		taskB := testfactory.Job(ctx, t, bundle.exec, &testfactory.JobOpts{Metadata: taskMetadata("wf1", "b"), State: ptrutil.Ptr(rivertype.JobStateRunning)})
+		taskC := testfactory.Job(ctx, t, bundle.exec, &testfactory.JobOpts{Metadata: taskMetadata("wf1", "c", "a"), State: ptrutil.Ptr(rivertype.JobStatePending)})
+		taskD := testfactory.Job(ctx, t, bundle.exec, &testfactory.JobOpts{Metadata: taskMetadata("wf1", "d", "a", "b"), State: ptrutil.Ptr(rivertype.JobStatePending)})
+		taskE := testfactory.Job(ctx, t, bundle.exec, &testfactory.JobOpts{Metadata: taskMetadata("wf1", "e", "a"), ScheduledAt: ptrutil.Ptr(now.Add(time.Hour)), State: ptrutil.Ptr(rivertype.JobStatePending)})
+
+		// Same task name, but in a different workflow, so not a dependency.
+		otherTaskB := testfactory.Job(ctx, t, bundle.exec, &testfactory.JobOpts{Metadata: taskMetadata("wf2", "b"), State: ptrutil.Ptr(rivertype.JobStateCompleted)})
+
+		// Pending job that's not part of a workflow shouldn't be touched.
+		nonWorkflowJob := testfactory.Job(ctx, t, bundle.exec, &testfactory.JobOpts{State: ptrutil.Ptr(rivertype.JobStatePending)})
+
+		require.NoError(t, promoter.Start(ctx))
+		promoter.TestSignals.PromotedBatch.WaitOrTimeout()
+
+		requireJobState(t, bundle.exec, taskA, rivertype.JobStateCompleted)
+		requireJobState(t, bundle.exec, taskB, rivertype.JobStateRunning)
+		requireJobState(t, bundle.exec, taskC, rivertype.JobStateAvailable)
+		requireJobState(t, bundle.exec, taskD, rivertype.JobStatePending)
+		requireJobState(t, bundle.exec, taskE, rivertype.JobStateScheduled)
+		requireJobState(t, bundle.exec, otherTaskB, rivertype.JobStateCompleted)
+		requireJobState(t, bundle.exec, nonWorkflowJob, rivertype.JobStatePending)
+
+		require.Equal(t, map[string]int{rivercommon.QueueDefault: 1}, bundle.notificationsByQueue)
+	})
+
+	t.Run("CancelsOrDiscardsTasksWithFailedDeps", func(t *testing.T) {
+		t.Parallel()
+
+		promoter, bundle := setupTx(t)
+
+		testfactory.Job(ctx, t, bundle.exec, &testfactory.JobOpts{Metadata: taskMetadata("wf1", "a"), State: ptrutil.Ptr(rivertype.JobStateDiscarded)})
+		taskB := testfactory.Job(ctx, t, bundle.exec, &testfactory.JobOpts{Metadata: taskMetadata("wf1", "b", "a"), State: ptrutil.Ptr(rivertype.JobStatePending)})
+		taskC := testfactory.Job(ctx, t, bundle.exec, &testfactory.JobOpts{
+			Metadata: []byte(`{"river:workflow_id": "wf1", "river:workflow_task": "c", "river:workflow_deps": ["a"], "river:workflow_dep_failed_action": "discard"}`),
+			State:    ptrutil.Ptr(rivertype.JobStatePending),
+		})
+
+		require.NoError(t, promoter.Start(ctx))
+		promoter.TestSignals.PromotedBatch.WaitOrTimeout()
+
+		updatedTaskB := requireJobState(t, bundle.exec, taskB, rivertype.JobStateCancelled)
+		require.NotNil(t, updatedTaskB.FinalizedAt)
+		updatedTaskC := requireJobState(t, bundle.exec, taskC, rivertype.JobStateDiscarded)
+		require.NotNil(t, updatedTaskC.FinalizedAt)
+
+		require.Empty(t, bundle.notificationsByQueue)
+	})
+
+	t.Run("PromotesTasksWithDepJobIDs", func(t *testing.T) {
+		t.Parallel()
+
+		promoter, bundle := setupTx(t)
+
+		depJob1 := testfactory.Job(ctx, t, bundle.exec, &testfactory.JobOpts{State: ptrutil.Ptr(rivertype.JobStateCompleted)})
+		depJob2 := testfactory.Job(ctx, t, bundle.exec, &testfactory.JobOpts{State: ptrutil.Ptr(rivertype.JobStateAvailable)})
+
+		task1 := testfactory.Job(ctx, t, bundle.exec, &testfactory.JobOpts{
+			Metadata: []byte(fmt.Sprintf(`{"river:workflow_id": "wf1", "river:workflow_task": "a", "river:workflow_dep_job_ids": [%d]}`, depJob1.ID)),
+			State:    ptrutil.Ptr(rivertype.JobStatePending),
+		})
+		task2 := testfactory.Job(ctx, t, bundle.exec, &testfactory.JobOpts{
+			Metadata: []byte(fmt.Sprintf(`{"river:workflow_id": "wf1", "river:workflow_task": "b", "river:workflow_dep_job_ids": [%d, %d]}`, depJob1.ID, depJob2.ID)),
+			State:    ptrutil.Ptr(rivertype.JobStatePending),
+		})
+
+		require.NoError(t, promoter.Start(ctx))
+		promoter.TestSignals.PromotedBatch.WaitOrTimeout()
+
+		requireJobState(t, bundle.exec, task1, rivertype.JobStateAvailable)
+		requireJobState(t, bundle.exec, task2, rivertype.JobStatePending)
+	})
+
+	t.Run("PromotesInBatches", func(t *testing.T) {
+		t.Parallel()
+
+		promoter, bundle := setupTx(t)
+		promoter.batchSize = 10 // reduced size for test speed
+
+		testfactory.Job(ctx, t, bundle.exec, &testfactory.JobOpts{Metadata: taskMetadata("wf1", "a"), State: ptrutil.Ptr(rivertype.JobStateCompleted)})
+
+		// Add one to our chosen batch size to get one extra job and therefore
+		// one extra batch, ensuring that we've tested working multiple.
+		numTasks := promoter.batchSize + 1
+
+		tasks := make([]*rivertype.JobRow, numTasks)
+		for i := range numTasks {
+			tasks[i] = testfactory.Job(ctx, t, bundle.exec, &testfactory.JobOpts{
+				Metadata: taskMetadata("wf1", fmt.Sprintf("task_%d", i), "a"),
+				State:    ptrutil.Ptr(rivertype.JobStatePending),
+			})
+		}
+
+		require.NoError(t, promoter.Start(ctx))
+
+		// See comment above. Exactly two batches are expected.
+		promoter.TestSignals.PromotedBatch.WaitOrTimeout()
+		promoter.TestSignals.PromotedBatch.WaitOrTimeout()
+
+		for _, task := range tasks {
+			requireJobState(t, bundle.exec, task, rivertype.JobStateAvailable)
+		}
+	})
+
+	t.Run("CustomizableInterval", func(t *testing.T) {
+		t.Parallel()
+
+		promoter, _ := setupTx(t)
+		promoter.config.Interval = 1 * time.Microsecond
+
+		require.NoError(t, promoter.Start(ctx))
+
+		// This should trigger ~immediately every time:
+		for i := range 5 {
+			t.Logf("Iteration %d", i)
+			promoter.TestSignals.PromotedBatch.WaitOrTimeout()
+		}
+	})
+
+	t.Run("StopsImmediately", func(t *testing.T) {
+		t.Parallel()
+
+		promoter, _ := setupTx(t)
+		promoter.config.Interval = time.Minute // should only trigger once for the initial run
+
+		require.NoError(t, promoter.Start(ctx))
+		promoter.Stop()
+	})
+
+	t.Run("TriggersNotificationsOnEachQueueWithNewlyAvailableTasks", func(t *testing.T) {
+		t.Parallel()
+
+		promoter, bundle := setupTx(t)
+
+		testfactory.Job(ctx, t, bundle.exec, &testfactory.JobOpts{Metadata: taskMetadata("wf1", "a"), State: ptrutil.Ptr(rivertype.JobStateCompleted)})
+		for _, queue := range []string{"queue1", "queue2", "queue2"} {
+			testfactory.Job(ctx, t, bundle.exec, &testfactory.JobOpts{Metadata: taskMetadata("wf1", "task_"+queue, "a"), Queue: ptrutil.Ptr(queue), State: ptrutil.Ptr(rivertype.JobStatePending)})
+		}
+
+		require.NoError(t, promoter.Start(ctx))
+		promoter.TestSignals.PromotedBatch.WaitOrTimeout()
+
+		queues := make([]string, 0, len(bundle.notificationsByQueue))
+		for queue := range bundle.notificationsByQueue {
+			queues = append(queues, queue)
+		}
+		sort.Strings(queues)
+		require.Equal(t, []string{"queue1", "queue2"}, queues)
+		require.Equal(t, 2, bundle.notificationsByQueue["queue2"])
+	})
+}
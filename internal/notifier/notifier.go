@@ -23,6 +23,7 @@ type NotificationTopic string
 const (
 	NotificationTopicControl    NotificationTopic = "river_control"
 	NotificationTopicInsert     NotificationTopic = "river_insert"
+	NotificationTopicJobEvent   NotificationTopic = "river_job_event"
 	NotificationTopicLeadership NotificationTopic = "river_leadership"
 )
 
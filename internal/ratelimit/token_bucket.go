@@ -0,0 +1,77 @@
+package ratelimit
+
+import (
+	"sync"
+	"time"
+
+	"github.com/riverqueue/river/rivershared/baseservice"
+)
+
+// TokenBucket is an in-memory token bucket holding up to limit tokens, which
+// refill continuously at a rate of limit per period. It starts full, so up to
+// limit tokens can be taken at once after a period of inactivity.
+//
+// It's safe for concurrent use.
+type TokenBucket struct {
+	baseservice.BaseService
+
+	burst         float64
+	ratePerSecond float64
+
+	mu        sync.Mutex // protects tokens and updatedAt
+	tokens    float64
+	updatedAt time.Time
+}
+
+// NewTokenBucket creates a new TokenBucket allowing limit tokens per period.
+func NewTokenBucket(archetype *baseservice.Archetype, limit int, period time.Duration) *TokenBucket {
+	return baseservice.Init(archetype, &TokenBucket{
+		burst:         float64(limit),
+		ratePerSecond: float64(limit) / period.Seconds(),
+		tokens:        float64(limit),
+	})
+}
+
+// Reserve takes up to n tokens from the bucket, returning the number taken,
+// which is less than n if not enough tokens are available. Tokens that end up
+// going unused should be given back with Return.
+func (b *TokenBucket) Reserve(n int) int {
+	now := b.Time.NowUTC()
+
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	b.refill(now)
+
+	reserved := min(n, int(b.tokens))
+	if reserved <= 0 {
+		return 0
+	}
+
+	b.tokens -= float64(reserved)
+	return reserved
+}
+
+// Return gives back tokens previously taken by Reserve that went unused.
+func (b *TokenBucket) Return(n int) {
+	if n <= 0 {
+		return
+	}
+
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	b.tokens = min(b.burst, b.tokens+float64(n))
+}
+
+// refill adds tokens accrued since the bucket was last refilled. Must be
+// called with mu held.
+func (b *TokenBucket) refill(now time.Time) {
+	if !b.updatedAt.IsZero() && now.After(b.updatedAt) {
+		b.tokens = min(b.burst, b.tokens+now.Sub(b.updatedAt).Seconds()*b.ratePerSecond)
+	}
+
+	if now.After(b.updatedAt) {
+		b.updatedAt = now
+	}
+}
@@ -0,0 +1,108 @@
+package ratelimit
+
+import (
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/riverqueue/river/rivershared/riversharedtest"
+)
+
+func TestTokenBucket(t *testing.T) {
+	t.Parallel()
+
+	setup := func(t *testing.T, limit int, period time.Duration) *TokenBucket {
+		t.Helper()
+
+		return NewTokenBucket(riversharedtest.BaseServiceArchetype(t), limit, period)
+	}
+
+	t.Run("StartsFull", func(t *testing.T) {
+		t.Parallel()
+
+		bucket := setup(t, 10, time.Minute)
+		bucket.Time.StubNowUTC(time.Now())
+
+		require.Equal(t, 10, bucket.Reserve(100))
+		require.Zero(t, bucket.Reserve(1))
+	})
+
+	t.Run("ReservesPartially", func(t *testing.T) {
+		t.Parallel()
+
+		bucket := setup(t, 10, time.Minute)
+		bucket.Time.StubNowUTC(time.Now())
+
+		require.Equal(t, 4, bucket.Reserve(4))
+		require.Equal(t, 6, bucket.Reserve(7))
+		require.Zero(t, bucket.Reserve(1))
+	})
+
+	t.Run("Refills", func(t *testing.T) {
+		t.Parallel()
+
+		bucket := setup(t, 10, time.Minute)
+		now := bucket.Time.StubNowUTC(time.Now())
+
+		require.Equal(t, 10, bucket.Reserve(10))
+
+		// Just short of the time needed to accrue one token.
+		bucket.Time.StubNowUTC(now.Add(5*time.Second + 900*time.Millisecond))
+		require.Zero(t, bucket.Reserve(1))
+
+		bucket.Time.StubNowUTC(now.Add(6 * time.Second))
+		require.Equal(t, 1, bucket.Reserve(10))
+
+		bucket.Time.StubNowUTC(now.Add(30 * time.Second))
+		require.Equal(t, 4, bucket.Reserve(10))
+
+		// Refills no further than the limit.
+		bucket.Time.StubNowUTC(now.Add(time.Hour))
+		require.Equal(t, 10, bucket.Reserve(100))
+	})
+
+	t.Run("Return", func(t *testing.T) {
+		t.Parallel()
+
+		bucket := setup(t, 10, time.Minute)
+		bucket.Time.StubNowUTC(time.Now())
+
+		require.Equal(t, 10, bucket.Reserve(10))
+		bucket.Return(3)
+		require.Equal(t, 3, bucket.Reserve(10))
+
+		// Returns no further than the limit.
+		bucket.Return(100)
+		require.Equal(t, 10, bucket.Reserve(100))
+	})
+
+	t.Run("ConcurrentAccessStressTest", func(t *testing.T) {
+		t.Parallel()
+
+		bucket := setup(t, 100, time.Hour)
+		bucket.Time.StubNowUTC(time.Now())
+
+		var (
+			mu       sync.Mutex
+			reserved int
+			wg       sync.WaitGroup
+		)
+		for range 10 {
+			wg.Add(1)
+			go func() {
+				defer wg.Done()
+				for range 20 {
+					n := bucket.Reserve(1)
+					mu.Lock()
+					reserved += n
+					mu.Unlock()
+				}
+			}()
+		}
+		wg.Wait()
+
+		require.Equal(t, 100, reserved)
+	})
+}
@@ -240,6 +240,141 @@ func Exercise[TTx any](ctx context.Context, t *testing.T,
 		require.NoError(t, err)
 	})
 
+	t.Run("JobBatchCountByState", func(t *testing.T) {
+		t.Parallel()
+
+		t.Run("CountsBatchJobsByState", func(t *testing.T) {
+			t.Parallel()
+
+			exec, _ := setup(ctx, t)
+
+			batchMetadata := []byte(`{"river:batch_id": "batch1"}`)
+
+			testfactory.Job(ctx, t, exec, &testfactory.JobOpts{Metadata: batchMetadata, State: ptrutil.Ptr(rivertype.JobStateAvailable)})
+			testfactory.Job(ctx, t, exec, &testfactory.JobOpts{Metadata: batchMetadata, State: ptrutil.Ptr(rivertype.JobStateCompleted)})
+			testfactory.Job(ctx, t, exec, &testfactory.JobOpts{Metadata: batchMetadata, State: ptrutil.Ptr(rivertype.JobStateCompleted)})
+			testfactory.Job(ctx, t, exec, &testfactory.JobOpts{Metadata: batchMetadata, State: ptrutil.Ptr(rivertype.JobStateDiscarded)})
+
+			// Not part of the batch.
+			testfactory.Job(ctx, t, exec, &testfactory.JobOpts{Metadata: []byte(`{"river:batch_id": "batch2"}`), State: ptrutil.Ptr(rivertype.JobStateAvailable)})
+			testfactory.Job(ctx, t, exec, &testfactory.JobOpts{Metadata: []byte(`{"river:batch_on_finish": "batch1"}`), State: ptrutil.Ptr(rivertype.JobStatePending)})
+			testfactory.Job(ctx, t, exec, &testfactory.JobOpts{State: ptrutil.Ptr(rivertype.JobStateAvailable)})
+
+			countsByState, err := exec.JobBatchCountByState(ctx, &riverdriver.JobBatchCountByStateParams{
+				BatchID: "batch1",
+			})
+			require.NoError(t, err)
+			require.Equal(t, map[rivertype.JobState]int{
+				rivertype.JobStateAvailable: 1,
+				rivertype.JobStateCompleted: 2,
+				rivertype.JobStateDiscarded: 1,
+			}, countsByState)
+		})
+
+		t.Run("EmptyForUnknownBatch", func(t *testing.T) {
+			t.Parallel()
+
+			exec, _ := setup(ctx, t)
+
+			countsByState, err := exec.JobBatchCountByState(ctx, &riverdriver.JobBatchCountByStateParams{
+				BatchID: "does_not_exist",
+			})
+			require.NoError(t, err)
+			require.Empty(t, countsByState)
+		})
+
+		t.Run("AlternateSchema", func(t *testing.T) {
+			t.Parallel()
+
+			exec, _ := setup(ctx, t)
+
+			_, err := exec.JobBatchCountByState(ctx, &riverdriver.JobBatchCountByStateParams{
+				BatchID: "batch1",
+				Schema:  "custom_schema",
+			})
+			requireMissingRelation(t, err, "custom_schema.river_job")
+		})
+	})
+
+	t.Run("JobBatchOnFinishPromote", func(t *testing.T) {
+		t.Parallel()
+
+		t.Run("PromotesOnFinishJobsOfFinishedBatches", func(t *testing.T) {
+			t.Parallel()
+
+			exec, _ := setup(ctx, t)
+
+			now := time.Now().UTC()
+
+			testfactory.Job(ctx, t, exec, &testfactory.JobOpts{Metadata: []byte(`{"river:batch_id": "finished"}`), State: ptrutil.Ptr(rivertype.JobStateCompleted)})
+			testfactory.Job(ctx, t, exec, &testfactory.JobOpts{Metadata: []byte(`{"river:batch_id": "finished"}`), State: ptrutil.Ptr(rivertype.JobStateCancelled)})
+			testfactory.Job(ctx, t, exec, &testfactory.JobOpts{Metadata: []byte(`{"river:batch_id": "finished"}`), State: ptrutil.Ptr(rivertype.JobStateDiscarded)})
+			testfactory.Job(ctx, t, exec, &testfactory.JobOpts{Metadata: []byte(`{"river:batch_id": "finished_scheduled"}`), State: ptrutil.Ptr(rivertype.JobStateCompleted)})
+			testfactory.Job(ctx, t, exec, &testfactory.JobOpts{Metadata: []byte(`{"river:batch_id": "unfinished"}`), State: ptrutil.Ptr(rivertype.JobStateCompleted)})
+			testfactory.Job(ctx, t, exec, &testfactory.JobOpts{Metadata: []byte(`{"river:batch_id": "unfinished"}`), State: ptrutil.Ptr(rivertype.JobStateRetryable)})
+
+			finishedOnFinish := testfactory.Job(ctx, t, exec, &testfactory.JobOpts{Metadata: []byte(`{"river:batch_on_finish": "finished"}`), State: ptrutil.Ptr(rivertype.JobStatePending)})
+			scheduledOnFinish := testfactory.Job(ctx, t, exec, &testfactory.JobOpts{Metadata: []byte(`{"river:batch_on_finish": "finished_scheduled"}`), ScheduledAt: ptrutil.Ptr(now.Add(time.Hour)), State: ptrutil.Ptr(rivertype.JobStatePending)})
+			unfinishedOnFinish := testfactory.Job(ctx, t, exec, &testfactory.JobOpts{Metadata: []byte(`{"river:batch_on_finish": "unfinished"}`), State: ptrutil.Ptr(rivertype.JobStatePending)})
+
+			// Pending job that's not an on finish job, so not examined at all.
+			testfactory.Job(ctx, t, exec, &testfactory.JobOpts{State: ptrutil.Ptr(rivertype.JobStatePending)})
+
+			jobs, err := exec.JobBatchOnFinishPromote(ctx, &riverdriver.JobBatchOnFinishPromoteParams{
+				Max: 100,
+				Now: now,
+			})
+			require.NoError(t, err)
+
+			jobStates := make(map[int64]rivertype.JobState, len(jobs))
+			for _, job := range jobs {
+				jobStates[job.ID] = job.State
+			}
+			require.Equal(t, map[int64]rivertype.JobState{
+				finishedOnFinish.ID:   rivertype.JobStateAvailable,
+				scheduledOnFinish.ID:  rivertype.JobStateScheduled,
+				unfinishedOnFinish.ID: rivertype.JobStatePending,
+			}, jobStates)
+		})
+
+		t.Run("RespectsAfterIDAndMax", func(t *testing.T) {
+			t.Parallel()
+
+			exec, _ := setup(ctx, t)
+
+			jobs := make([]*rivertype.JobRow, 3)
+			for i := range jobs {
+				jobs[i] = testfactory.Job(ctx, t, exec, &testfactory.JobOpts{
+					Metadata: []byte(fmt.Sprintf(`{"river:batch_on_finish": "batch_%d"}`, i)),
+					State:    ptrutil.Ptr(rivertype.JobStatePending),
+				})
+			}
+
+			promotedJobs, err := exec.JobBatchOnFinishPromote(ctx, &riverdriver.JobBatchOnFinishPromoteParams{
+				AfterID: jobs[0].ID,
+				Max:     1,
+				Now:     time.Now().UTC(),
+			})
+			require.NoError(t, err)
+			require.Len(t, promotedJobs, 1)
+			require.Equal(t, jobs[1].ID, promotedJobs[0].ID)
+			require.Equal(t, rivertype.JobStateAvailable, promotedJobs[0].State)
+		})
+
+		t.Run("AlternateSchema", func(t *testing.T) {
+			t.Parallel()
+
+			exec, _ := setup(ctx, t)
+
+			_, err := exec.JobBatchOnFinishPromote(ctx, &riverdriver.JobBatchOnFinishPromoteParams{
+				Max:    100,
+				Now:    time.Now().UTC(),
+				Schema: "custom_schema",
+			})
+			requireMissingRelation(t, err, "custom_schema.river_job")
+		})
+	})
+
 	t.Run("JobCancel", func(t *testing.T) {
 		t.Parallel()
 
@@ -345,29 +480,66 @@ func Exercise[TTx any](ctx context.Context, t *testing.T,
 		})
 	})
 
-	t.Run("JobCountByState", func(t *testing.T) {
+	t.Run("JobCancelMany", func(t *testing.T) {
 		t.Parallel()
 
-		t.Run("CountsJobsByState", func(t *testing.T) {
+		t.Run("CancelsJobsAndMarksRunningJobs", func(t *testing.T) {
 			t.Parallel()
 
 			exec, _ := setup(ctx, t)
 
-			// Included because they're the queried state.
-			_ = testfactory.Job(ctx, t, exec, &testfactory.JobOpts{State: ptrutil.Ptr(rivertype.JobStateAvailable)})
-			_ = testfactory.Job(ctx, t, exec, &testfactory.JobOpts{State: ptrutil.Ptr(rivertype.JobStateAvailable)})
+			now := time.Now().UTC()
+			nowStr := now.Format(time.RFC3339Nano)
 
-			// Excluded because they're not.
-			finalizedAt := ptrutil.Ptr(time.Now())
-			_ = testfactory.Job(ctx, t, exec, &testfactory.JobOpts{FinalizedAt: finalizedAt, State: ptrutil.Ptr(rivertype.JobStateCancelled)})
-			_ = testfactory.Job(ctx, t, exec, &testfactory.JobOpts{FinalizedAt: finalizedAt, State: ptrutil.Ptr(rivertype.JobStateCompleted)})
-			_ = testfactory.Job(ctx, t, exec, &testfactory.JobOpts{FinalizedAt: finalizedAt, State: ptrutil.Ptr(rivertype.JobStateDiscarded)})
+			job1 := testfactory.Job(ctx, t, exec, &testfactory.JobOpts{State: ptrutil.Ptr(rivertype.JobStateAvailable)})
+			job2 := testfactory.Job(ctx, t, exec, &testfactory.JobOpts{State: ptrutil.Ptr(rivertype.JobStateScheduled)})
+			job3 := testfactory.Job(ctx, t, exec, &testfactory.JobOpts{State: ptrutil.Ptr(rivertype.JobStateRunning)})
+			job4 := testfactory.Job(ctx, t, exec, &testfactory.JobOpts{FinalizedAt: ptrutil.Ptr(now), State: ptrutil.Ptr(rivertype.JobStateCompleted)})
+			job5 := testfactory.Job(ctx, t, exec, &testfactory.JobOpts{State: ptrutil.Ptr(rivertype.JobStateAvailable)}) // not included in IDs
 
-			numJobs, err := exec.JobCountByState(ctx, &riverdriver.JobCountByStateParams{
-				State: rivertype.JobStateAvailable,
+			jobs, err := exec.JobCancelMany(ctx, &riverdriver.JobCancelManyParams{
+				ID:                []int64{job1.ID, job2.ID, job3.ID, job4.ID},
+				CancelAttemptedAt: now,
+				ControlTopic:      string(notifier.NotificationTopicControl),
 			})
 			require.NoError(t, err)
-			require.Equal(t, 2, numJobs)
+			require.Len(t, jobs, 3)
+
+			jobsByID := make(map[int64]*rivertype.JobRow, len(jobs))
+			for _, job := range jobs {
+				jobsByID[job.ID] = job
+				require.JSONEq(t, fmt.Sprintf(`{"cancel_attempted_at":%q}`, nowStr), string(job.Metadata))
+			}
+
+			require.Equal(t, rivertype.JobStateCancelled, jobsByID[job1.ID].State)
+			require.NotNil(t, jobsByID[job1.ID].FinalizedAt)
+			require.Equal(t, rivertype.JobStateCancelled, jobsByID[job2.ID].State)
+			require.NotNil(t, jobsByID[job2.ID].FinalizedAt)
+			require.Equal(t, rivertype.JobStateRunning, jobsByID[job3.ID].State)
+			require.Nil(t, jobsByID[job3.ID].FinalizedAt)
+
+			job4Updated, err := exec.JobGetByID(ctx, &riverdriver.JobGetByIDParams{ID: job4.ID})
+			require.NoError(t, err)
+			require.Equal(t, rivertype.JobStateCompleted, job4Updated.State)
+			require.JSONEq(t, `{}`, string(job4Updated.Metadata))
+
+			job5Updated, err := exec.JobGetByID(ctx, &riverdriver.JobGetByIDParams{ID: job5.ID})
+			require.NoError(t, err)
+			require.Equal(t, rivertype.JobStateAvailable, job5Updated.State)
+		})
+
+		t.Run("NoJobsFound", func(t *testing.T) {
+			t.Parallel()
+
+			exec, _ := setup(ctx, t)
+
+			jobs, err := exec.JobCancelMany(ctx, &riverdriver.JobCancelManyParams{
+				ID:                []int64{1234567890},
+				CancelAttemptedAt: time.Now(),
+				ControlTopic:      string(notifier.NotificationTopicControl),
+			})
+			require.NoError(t, err)
+			require.Empty(t, jobs)
 		})
 
 		t.Run("AlternateSchema", func(t *testing.T) {
@@ -375,154 +547,560 @@ func Exercise[TTx any](ctx context.Context, t *testing.T,
 
 			exec, _ := setup(ctx, t)
 
-			_, err := exec.JobCountByState(ctx, &riverdriver.JobCountByStateParams{
-				Schema: "custom_schema",
-				State:  rivertype.JobStateAvailable,
+			_, err := exec.JobCancelMany(ctx, &riverdriver.JobCancelManyParams{
+				ID:                []int64{1234567890},
+				CancelAttemptedAt: time.Now(),
+				ControlTopic:      string(notifier.NotificationTopicControl),
+				Schema:            "custom_schema",
 			})
 			requireMissingRelation(t, err, "custom_schema.river_job")
 		})
 	})
 
-	t.Run("JobDelete", func(t *testing.T) {
+	t.Run("JobCount", func(t *testing.T) {
 		t.Parallel()
 
-		t.Run("DoesNotDeleteARunningJob", func(t *testing.T) {
+		t.Run("CountsJobsMatchingWhereClause", func(t *testing.T) {
 			t.Parallel()
 
 			exec, _ := setup(ctx, t)
 
-			job := testfactory.Job(ctx, t, exec, &testfactory.JobOpts{
-				State: ptrutil.Ptr(rivertype.JobStateRunning),
-			})
+			_ = testfactory.Job(ctx, t, exec, &testfactory.JobOpts{Kind: ptrutil.Ptr("kind1")})
+			_ = testfactory.Job(ctx, t, exec, &testfactory.JobOpts{Kind: ptrutil.Ptr("kind1")})
+			_ = testfactory.Job(ctx, t, exec, &testfactory.JobOpts{Kind: ptrutil.Ptr("kind2")})
 
-			jobAfter, err := exec.JobDelete(ctx, &riverdriver.JobDeleteParams{
-				ID: job.ID,
+			numJobs, err := exec.JobCount(ctx, &riverdriver.JobCountParams{
+				NamedArgs:   map[string]any{"kind": "kind1"},
+				WhereClause: "kind = @kind",
 			})
-			require.ErrorIs(t, err, rivertype.ErrJobRunning)
-			require.Nil(t, jobAfter)
-
-			jobUpdated, err := exec.JobGetByID(ctx, &riverdriver.JobGetByIDParams{ID: job.ID, Schema: ""})
 			require.NoError(t, err)
-			require.Equal(t, rivertype.JobStateRunning, jobUpdated.State)
+			require.Equal(t, 2, numJobs)
 		})
 
-		for _, state := range []rivertype.JobState{
-			rivertype.JobStateAvailable,
-			rivertype.JobStateCancelled,
-			rivertype.JobStateCompleted,
-			rivertype.JobStateDiscarded,
-			rivertype.JobStatePending,
-			rivertype.JobStateRetryable,
-			rivertype.JobStateScheduled,
-		} {
-			t.Run(fmt.Sprintf("DeletesA_%s_Job", state), func(t *testing.T) {
-				t.Parallel()
+		t.Run("AlternateSchema", func(t *testing.T) {
+			t.Parallel()
 
-				exec, _ := setup(ctx, t)
+			exec, _ := setup(ctx, t)
 
-				now := time.Now().UTC()
+			_, err := exec.JobCount(ctx, &riverdriver.JobCountParams{
+				Schema:      "custom_schema",
+				WhereClause: "true",
+			})
+			requireMissingRelation(t, err, "custom_schema.river_job")
+		})
+	})
 
-				setFinalized := slices.Contains([]rivertype.JobState{
-					rivertype.JobStateCancelled,
-					rivertype.JobStateCompleted,
-					rivertype.JobStateDiscarded,
-				}, state)
+	t.Run("JobCountByQueueAndState", func(t *testing.T) {
+		t.Parallel()
 
-				var finalizedAt *time.Time
-				if setFinalized {
-					finalizedAt = &now
-				}
+		t.Run("CountsJobsByQueueAndState", func(t *testing.T) {
+			t.Parallel()
 
-				job := testfactory.Job(ctx, t, exec, &testfactory.JobOpts{
-					FinalizedAt: finalizedAt,
-					ScheduledAt: ptrutil.Ptr(now.Add(1 * time.Hour)),
-					State:       &state,
-				})
+			exec, _ := setup(ctx, t)
 
-				jobAfter, err := exec.JobDelete(ctx, &riverdriver.JobDeleteParams{
-					ID:     job.ID,
-					Schema: "",
-				})
-				require.NoError(t, err)
-				require.NotNil(t, jobAfter)
-				require.Equal(t, job.ID, jobAfter.ID)
-				require.Equal(t, state, jobAfter.State)
+			_ = testfactory.Job(ctx, t, exec, &testfactory.JobOpts{Queue: ptrutil.Ptr("queue1"), State: ptrutil.Ptr(rivertype.JobStateAvailable)})
+			_ = testfactory.Job(ctx, t, exec, &testfactory.JobOpts{Queue: ptrutil.Ptr("queue1"), State: ptrutil.Ptr(rivertype.JobStateAvailable)})
+			_ = testfactory.Job(ctx, t, exec, &testfactory.JobOpts{Queue: ptrutil.Ptr("queue1"), State: ptrutil.Ptr(rivertype.JobStateRunning)})
+			_ = testfactory.Job(ctx, t, exec, &testfactory.JobOpts{Queue: ptrutil.Ptr("queue2"), State: ptrutil.Ptr(rivertype.JobStateAvailable)})
 
-				_, err = exec.JobGetByID(ctx, &riverdriver.JobGetByIDParams{ID: job.ID, Schema: ""})
-				require.ErrorIs(t, err, rivertype.ErrNotFound)
+			// Excluded because its state isn't queried.
+			_ = testfactory.Job(ctx, t, exec, &testfactory.JobOpts{FinalizedAt: ptrutil.Ptr(time.Now()), Queue: ptrutil.Ptr("queue1"), State: ptrutil.Ptr(rivertype.JobStateCompleted)})
+
+			results, err := exec.JobCountByQueueAndState(ctx, &riverdriver.JobCountByQueueAndStateParams{
+				State: []rivertype.JobState{rivertype.JobStateAvailable, rivertype.JobStateRunning},
 			})
-		}
+			require.NoError(t, err)
+			require.Equal(t, []*riverdriver.JobCountByQueueAndStateResult{
+				{Count: 2, Queue: "queue1", State: rivertype.JobStateAvailable},
+				{Count: 1, Queue: "queue1", State: rivertype.JobStateRunning},
+				{Count: 1, Queue: "queue2", State: rivertype.JobStateAvailable},
+			}, results)
+		})
 
-		t.Run("ReturnsErrNotFoundIfJobDoesNotExist", func(t *testing.T) {
+		t.Run("AlternateSchema", func(t *testing.T) {
 			t.Parallel()
 
 			exec, _ := setup(ctx, t)
 
-			jobAfter, err := exec.JobDelete(ctx, &riverdriver.JobDeleteParams{
-				ID: 1234567890,
+			_, err := exec.JobCountByQueueAndState(ctx, &riverdriver.JobCountByQueueAndStateParams{
+				Schema: "custom_schema",
+				State:  []rivertype.JobState{rivertype.JobStateAvailable},
 			})
-			require.ErrorIs(t, err, rivertype.ErrNotFound)
-			require.Nil(t, jobAfter)
+			requireMissingRelation(t, err, "custom_schema.river_job")
 		})
+	})
 
-		t.Run("AlternateSchema", func(t *testing.T) {
+	t.Run("JobCountByState", func(t *testing.T) {
+		t.Parallel()
+
+		t.Run("CountsJobsByState", func(t *testing.T) {
 			t.Parallel()
 
 			exec, _ := setup(ctx, t)
 
-			job := testfactory.Job(ctx, t, exec, &testfactory.JobOpts{})
+			// Included because they're the queried state.
+			_ = testfactory.Job(ctx, t, exec, &testfactory.JobOpts{State: ptrutil.Ptr(rivertype.JobStateAvailable)})
+			_ = testfactory.Job(ctx, t, exec, &testfactory.JobOpts{State: ptrutil.Ptr(rivertype.JobStateAvailable)})
 
-			_, err := exec.JobDelete(ctx, &riverdriver.JobDeleteParams{
-				ID:     job.ID,
+			// Excluded because they're not.
+			finalizedAt := ptrutil.Ptr(time.Now())
+			_ = testfactory.Job(ctx, t, exec, &testfactory.JobOpts{FinalizedAt: finalizedAt, State: ptrutil.Ptr(rivertype.JobStateCancelled)})
+			_ = testfactory.Job(ctx, t, exec, &testfactory.JobOpts{FinalizedAt: finalizedAt, State: ptrutil.Ptr(rivertype.JobStateCompleted)})
+			_ = testfactory.Job(ctx, t, exec, &testfactory.JobOpts{FinalizedAt: finalizedAt, State: ptrutil.Ptr(rivertype.JobStateDiscarded)})
+
+			numJobs, err := exec.JobCountByState(ctx, &riverdriver.JobCountByStateParams{
+				State: rivertype.JobStateAvailable,
+			})
+			require.NoError(t, err)
+			require.Equal(t, 2, numJobs)
+		})
+
+		t.Run("AlternateSchema", func(t *testing.T) {
+			t.Parallel()
+
+			exec, _ := setup(ctx, t)
+
+			_, err := exec.JobCountByState(ctx, &riverdriver.JobCountByStateParams{
 				Schema: "custom_schema",
+				State:  rivertype.JobStateAvailable,
 			})
 			requireMissingRelation(t, err, "custom_schema.river_job")
 		})
 	})
 
-	t.Run("JobDeleteBefore", func(t *testing.T) {
+	t.Run("JobDeadLetter", func(t *testing.T) {
 		t.Parallel()
 
-		exec, _ := setup(ctx, t)
+		// Inserts a discarded job and moves it to the dead letter table.
+		deadLetterJob := func(ctx context.Context, t *testing.T, exec riverdriver.Executor, opts *testfactory.JobOpts) *rivertype.JobRow {
+			t.Helper()
 
-		var (
-			horizon       = time.Now()
-			beforeHorizon = horizon.Add(-1 * time.Minute)
-			afterHorizon  = horizon.Add(1 * time.Minute)
-		)
+			opts.FinalizedAt = ptrutil.Ptr(time.Now().Add(-1 * time.Hour))
+			opts.State = ptrutil.Ptr(rivertype.JobStateDiscarded)
+			job := testfactory.Job(ctx, t, exec, opts)
 
-		deletedJob1 := testfactory.Job(ctx, t, exec, &testfactory.JobOpts{FinalizedAt: &beforeHorizon, State: ptrutil.Ptr(rivertype.JobStateCancelled)})
-		deletedJob2 := testfactory.Job(ctx, t, exec, &testfactory.JobOpts{FinalizedAt: &beforeHorizon, State: ptrutil.Ptr(rivertype.JobStateCompleted)})
-		deletedJob3 := testfactory.Job(ctx, t, exec, &testfactory.JobOpts{FinalizedAt: &beforeHorizon, State: ptrutil.Ptr(rivertype.JobStateDiscarded)})
+			numMoved, err := exec.JobDeadLetterMoveBefore(ctx, &riverdriver.JobDeadLetterMoveBeforeParams{
+				DiscardedFinalizedAtHorizon: time.Now(),
+				Max:                         100,
+			})
+			require.NoError(t, err)
+			require.Equal(t, 1, numMoved)
 
-		// Not deleted because not appropriate state.
-		notDeletedJob1 := testfactory.Job(ctx, t, exec, &testfactory.JobOpts{State: ptrutil.Ptr(rivertype.JobStateAvailable)})
-		notDeletedJob2 := testfactory.Job(ctx, t, exec, &testfactory.JobOpts{State: ptrutil.Ptr(rivertype.JobStateRunning)})
+			return job
+		}
 
-		// Not deleted because after the delete horizon.
-		notDeletedJob3 := testfactory.Job(ctx, t, exec, &testfactory.JobOpts{FinalizedAt: &afterHorizon, State: ptrutil.Ptr(rivertype.JobStateCancelled)})
+		t.Run("DeleteByID", func(t *testing.T) {
+			t.Parallel()
 
-		// Max two deleted on the first pass.
-		numDeleted, err := exec.JobDeleteBefore(ctx, &riverdriver.JobDeleteBeforeParams{
-			CancelledFinalizedAtHorizon: horizon,
-			CompletedFinalizedAtHorizon: horizon,
-			DiscardedFinalizedAtHorizon: horizon,
-			Max:                         2,
-		})
-		require.NoError(t, err)
-		require.Equal(t, 2, numDeleted)
+			exec, _ := setup(ctx, t)
 
-		// And one more pass gets the last one.
-		numDeleted, err = exec.JobDeleteBefore(ctx, &riverdriver.JobDeleteBeforeParams{
-			CancelledFinalizedAtHorizon: horizon,
-			CompletedFinalizedAtHorizon: horizon,
-			DiscardedFinalizedAtHorizon: horizon,
-			Max:                         2,
+			job := deadLetterJob(ctx, t, exec, &testfactory.JobOpts{})
+
+			deadLetter, err := exec.JobDeadLetterDeleteByID(ctx, &riverdriver.JobDeadLetterDeleteByIDParams{ID: job.ID})
+			require.NoError(t, err)
+			require.Equal(t, job.ID, deadLetter.ID)
+
+			_, err = exec.JobDeadLetterDeleteByID(ctx, &riverdriver.JobDeadLetterDeleteByIDParams{ID: job.ID})
+			require.ErrorIs(t, err, rivertype.ErrNotFound)
 		})
-		require.NoError(t, err)
-		require.Equal(t, 1, numDeleted)
 
-		// All deleted.
+		t.Run("DeleteMany", func(t *testing.T) {
+			t.Parallel()
+
+			exec, _ := setup(ctx, t)
+
+			job1 := deadLetterJob(ctx, t, exec, &testfactory.JobOpts{Kind: ptrutil.Ptr("kind1"), Queue: ptrutil.Ptr("queue1")})
+			job2 := deadLetterJob(ctx, t, exec, &testfactory.JobOpts{Kind: ptrutil.Ptr("kind1"), Queue: ptrutil.Ptr("queue2")})
+			job3 := deadLetterJob(ctx, t, exec, &testfactory.JobOpts{Kind: ptrutil.Ptr("kind2"), Queue: ptrutil.Ptr("queue1")})
+
+			// No dead letters were moved before this time.
+			numDeleted, err := exec.JobDeadLetterDeleteMany(ctx, &riverdriver.JobDeadLetterDeleteManyParams{DeadLetteredBefore: ptrutil.Ptr(time.Now().Add(-1 * time.Hour))})
+			require.NoError(t, err)
+			require.Zero(t, numDeleted)
+
+			numDeleted, err = exec.JobDeadLetterDeleteMany(ctx, &riverdriver.JobDeadLetterDeleteManyParams{Kind: []string{"kind1"}, Queue: []string{"queue1"}})
+			require.NoError(t, err)
+			require.Equal(t, 1, numDeleted)
+
+			_, err = exec.JobDeadLetterGetByID(ctx, &riverdriver.JobDeadLetterGetByIDParams{ID: job1.ID})
+			require.ErrorIs(t, err, rivertype.ErrNotFound)
+
+			numDeleted, err = exec.JobDeadLetterDeleteMany(ctx, &riverdriver.JobDeadLetterDeleteManyParams{ID: []int64{job2.ID}})
+			require.NoError(t, err)
+			require.Equal(t, 1, numDeleted)
+
+			// Empty filters delete everything left.
+			numDeleted, err = exec.JobDeadLetterDeleteMany(ctx, &riverdriver.JobDeadLetterDeleteManyParams{})
+			require.NoError(t, err)
+			require.Equal(t, 1, numDeleted)
+
+			_, err = exec.JobDeadLetterGetByID(ctx, &riverdriver.JobDeadLetterGetByIDParams{ID: job3.ID})
+			require.ErrorIs(t, err, rivertype.ErrNotFound)
+		})
+
+		t.Run("GetByID", func(t *testing.T) {
+			t.Parallel()
+
+			exec, _ := setup(ctx, t)
+
+			job := deadLetterJob(ctx, t, exec, &testfactory.JobOpts{
+				Attempt:     ptrutil.Ptr(3),
+				EncodedArgs: []byte(`{"foo":"bar"}`),
+				Errors:      [][]byte{[]byte(`{"at":"2025-01-01T00:00:00Z","attempt":1,"error":"error 1"}`), []byte(`{"at":"2025-01-01T00:00:01Z","attempt":2,"error":"error 2"}`)},
+				Kind:        ptrutil.Ptr("kind1"),
+				Tags:        []string{"tag1"},
+			})
+
+			deadLetter, err := exec.JobDeadLetterGetByID(ctx, &riverdriver.JobDeadLetterGetByIDParams{ID: job.ID})
+			require.NoError(t, err)
+			require.Equal(t, job.ID, deadLetter.ID)
+			require.Equal(t, 3, deadLetter.Attempt)
+			require.WithinDuration(t, time.Now(), deadLetter.DeadLetteredAt, 5*time.Second)
+			require.JSONEq(t, `{"foo":"bar"}`, string(deadLetter.EncodedArgs))
+			require.Len(t, deadLetter.Errors, 2)
+			require.Equal(t, "error 1", deadLetter.Errors[0].Error)
+			require.Equal(t, "error 2", deadLetter.Errors[1].Error)
+			require.Equal(t, "kind1", deadLetter.Kind)
+			require.Equal(t, []string{"tag1"}, deadLetter.Tags)
+
+			_, err = exec.JobGetByID(ctx, &riverdriver.JobGetByIDParams{ID: job.ID})
+			require.ErrorIs(t, err, rivertype.ErrNotFound)
+
+			_, err = exec.JobDeadLetterGetByID(ctx, &riverdriver.JobDeadLetterGetByIDParams{ID: 0})
+			require.ErrorIs(t, err, rivertype.ErrNotFound)
+		})
+
+		t.Run("List", func(t *testing.T) {
+			t.Parallel()
+
+			exec, _ := setup(ctx, t)
+
+			job1 := deadLetterJob(ctx, t, exec, &testfactory.JobOpts{Kind: ptrutil.Ptr("kind1"), Queue: ptrutil.Ptr("queue1")})
+			job2 := deadLetterJob(ctx, t, exec, &testfactory.JobOpts{Kind: ptrutil.Ptr("kind2"), Queue: ptrutil.Ptr("queue1")})
+			job3 := deadLetterJob(ctx, t, exec, &testfactory.JobOpts{Kind: ptrutil.Ptr("kind1"), Queue: ptrutil.Ptr("queue2")})
+
+			deadLetterIDs := func(deadLetters []*rivertype.DeadLetter) []int64 {
+				return sliceutil.Map(deadLetters, func(d *rivertype.DeadLetter) int64 { return d.ID })
+			}
+
+			deadLetters, err := exec.JobDeadLetterList(ctx, &riverdriver.JobDeadLetterListParams{Max: 100})
+			require.NoError(t, err)
+			require.Equal(t, []int64{job1.ID, job2.ID, job3.ID}, deadLetterIDs(deadLetters))
+
+			deadLetters, err = exec.JobDeadLetterList(ctx, &riverdriver.JobDeadLetterListParams{AfterID: job1.ID, Max: 1})
+			require.NoError(t, err)
+			require.Equal(t, []int64{job2.ID}, deadLetterIDs(deadLetters))
+
+			deadLetters, err = exec.JobDeadLetterList(ctx, &riverdriver.JobDeadLetterListParams{Kind: []string{"kind1"}, Max: 100})
+			require.NoError(t, err)
+			require.Equal(t, []int64{job1.ID, job3.ID}, deadLetterIDs(deadLetters))
+
+			deadLetters, err = exec.JobDeadLetterList(ctx, &riverdriver.JobDeadLetterListParams{Max: 100, Queue: []string{"queue1"}})
+			require.NoError(t, err)
+			require.Equal(t, []int64{job1.ID, job2.ID}, deadLetterIDs(deadLetters))
+		})
+
+		t.Run("MoveBefore", func(t *testing.T) {
+			t.Parallel()
+
+			exec, _ := setup(ctx, t)
+
+			var (
+				horizon       = time.Now()
+				beforeHorizon = horizon.Add(-1 * time.Minute)
+				afterHorizon  = horizon.Add(1 * time.Minute)
+				now           = time.Now().UTC().Truncate(time.Microsecond)
+			)
+
+			movedJob1 := testfactory.Job(ctx, t, exec, &testfactory.JobOpts{FinalizedAt: &beforeHorizon, State: ptrutil.Ptr(rivertype.JobStateDiscarded)})
+			movedJob2 := testfactory.Job(ctx, t, exec, &testfactory.JobOpts{FinalizedAt: &beforeHorizon, State: ptrutil.Ptr(rivertype.JobStateDiscarded)})
+
+			// Not moved because not discarded.
+			notMovedJob1 := testfactory.Job(ctx, t, exec, &testfactory.JobOpts{FinalizedAt: &beforeHorizon, State: ptrutil.Ptr(rivertype.JobStateCompleted)})
+
+			// Not moved because after the horizon.
+			notMovedJob2 := testfactory.Job(ctx, t, exec, &testfactory.JobOpts{FinalizedAt: &afterHorizon, State: ptrutil.Ptr(rivertype.JobStateDiscarded)})
+
+			// Max one moved on the first pass.
+			numMoved, err := exec.JobDeadLetterMoveBefore(ctx, &riverdriver.JobDeadLetterMoveBeforeParams{
+				DiscardedFinalizedAtHorizon: horizon,
+				Max:                         1,
+				Now:                         &now,
+			})
+			require.NoError(t, err)
+			require.Equal(t, 1, numMoved)
+
+			// And one more pass gets the last one.
+			numMoved, err = exec.JobDeadLetterMoveBefore(ctx, &riverdriver.JobDeadLetterMoveBeforeParams{
+				DiscardedFinalizedAtHorizon: horizon,
+				Max:                         100,
+				Now:                         &now,
+			})
+			require.NoError(t, err)
+			require.Equal(t, 1, numMoved)
+
+			for _, job := range []*rivertype.JobRow{movedJob1, movedJob2} {
+				_, err := exec.JobGetByID(ctx, &riverdriver.JobGetByIDParams{ID: job.ID})
+				require.ErrorIs(t, err, rivertype.ErrNotFound)
+
+				deadLetter, err := exec.JobDeadLetterGetByID(ctx, &riverdriver.JobDeadLetterGetByIDParams{ID: job.ID})
+				require.NoError(t, err)
+				require.Equal(t, now, deadLetter.DeadLetteredAt.UTC())
+			}
+
+			for _, job := range []*rivertype.JobRow{notMovedJob1, notMovedJob2} {
+				_, err := exec.JobGetByID(ctx, &riverdriver.JobGetByIDParams{ID: job.ID})
+				require.NoError(t, err)
+			}
+		})
+
+		t.Run("MoveBeforeLeavesJobsAlreadyDeadLettered", func(t *testing.T) {
+			t.Parallel()
+
+			exec, _ := setup(ctx, t)
+
+			beforeHorizon := time.Now().Add(-1 * time.Minute)
+
+			conflictingJob := testfactory.Job(ctx, t, exec, &testfactory.JobOpts{FinalizedAt: &beforeHorizon, State: ptrutil.Ptr(rivertype.JobStateDiscarded)})
+			movedJob := testfactory.Job(ctx, t, exec, &testfactory.JobOpts{FinalizedAt: &beforeHorizon, State: ptrutil.Ptr(rivertype.JobStateDiscarded)})
+
+			// A dead letter already exists with the same ID as the first job.
+			_, err := exec.Exec(ctx, fmt.Sprintf(`
+				INSERT INTO river_job_dead_letter (id, attempt, created_at, kind, max_attempts, priority, queue, scheduled_at)
+				VALUES (%d, 1, now(), 'preexisting_kind', 25, 1, 'default', now())
+			`, conflictingJob.ID))
+			require.NoError(t, err)
+
+			numMoved, err := exec.JobDeadLetterMoveBefore(ctx, &riverdriver.JobDeadLetterMoveBeforeParams{
+				DiscardedFinalizedAtHorizon: time.Now(),
+				Max:                         100,
+			})
+			require.NoError(t, err)
+			require.Equal(t, 1, numMoved)
+
+			// The conflicting job stays in place rather than being deleted
+			// without being archived, and the existing dead letter is
+			// untouched.
+			_, err = exec.JobGetByID(ctx, &riverdriver.JobGetByIDParams{ID: conflictingJob.ID})
+			require.NoError(t, err)
+
+			deadLetter, err := exec.JobDeadLetterGetByID(ctx, &riverdriver.JobDeadLetterGetByIDParams{ID: conflictingJob.ID})
+			require.NoError(t, err)
+			require.Equal(t, "preexisting_kind", deadLetter.Kind)
+
+			_, err = exec.JobGetByID(ctx, &riverdriver.JobGetByIDParams{ID: movedJob.ID})
+			require.ErrorIs(t, err, rivertype.ErrNotFound)
+
+			_, err = exec.JobDeadLetterGetByID(ctx, &riverdriver.JobDeadLetterGetByIDParams{ID: movedJob.ID})
+			require.NoError(t, err)
+
+			// Later passes don't get stuck on the conflicting job, even though
+			// it has the lowest ID.
+			laterJob := testfactory.Job(ctx, t, exec, &testfactory.JobOpts{FinalizedAt: &beforeHorizon, State: ptrutil.Ptr(rivertype.JobStateDiscarded)})
+
+			numMoved, err = exec.JobDeadLetterMoveBefore(ctx, &riverdriver.JobDeadLetterMoveBeforeParams{
+				DiscardedFinalizedAtHorizon: time.Now(),
+				Max:                         1,
+			})
+			require.NoError(t, err)
+			require.Equal(t, 1, numMoved)
+
+			_, err = exec.JobDeadLetterGetByID(ctx, &riverdriver.JobDeadLetterGetByIDParams{ID: laterJob.ID})
+			require.NoError(t, err)
+		})
+	})
+
+	t.Run("JobCountGrouped", func(t *testing.T) {
+		t.Parallel()
+
+		t.Run("CountsJobsGroupedByExpr", func(t *testing.T) {
+			t.Parallel()
+
+			exec, _ := setup(ctx, t)
+
+			_ = testfactory.Job(ctx, t, exec, &testfactory.JobOpts{Queue: ptrutil.Ptr("queue1"), Tags: []string{"tag1", "tag2"}})
+			_ = testfactory.Job(ctx, t, exec, &testfactory.JobOpts{Queue: ptrutil.Ptr("queue1"), Tags: []string{"tag1"}})
+			_ = testfactory.Job(ctx, t, exec, &testfactory.JobOpts{Queue: ptrutil.Ptr("queue2")})
+
+			// Excluded by the where clause.
+			_ = testfactory.Job(ctx, t, exec, &testfactory.JobOpts{Queue: ptrutil.Ptr("queue3"), State: ptrutil.Ptr(rivertype.JobStateRunning)})
+
+			results, err := exec.JobCountGrouped(ctx, &riverdriver.JobCountGroupedParams{
+				GroupByExpr: "queue",
+				NamedArgs:   map[string]any{"state": string(rivertype.JobStateAvailable)},
+				WhereClause: "state = @state::river_job_state",
+			})
+			require.NoError(t, err)
+			require.Equal(t, []*riverdriver.JobCountGroupedResult{
+				{Count: 2, Value: "queue1"},
+				{Count: 1, Value: "queue2"},
+			}, results)
+
+			results, err = exec.JobCountGrouped(ctx, &riverdriver.JobCountGroupedParams{
+				GroupByExpr: "unnest(tags)",
+				WhereClause: "true",
+			})
+			require.NoError(t, err)
+			require.Equal(t, []*riverdriver.JobCountGroupedResult{
+				{Count: 2, Value: "tag1"},
+				{Count: 1, Value: "tag2"},
+			}, results)
+		})
+
+		t.Run("AlternateSchema", func(t *testing.T) {
+			t.Parallel()
+
+			exec, _ := setup(ctx, t)
+
+			_, err := exec.JobCountGrouped(ctx, &riverdriver.JobCountGroupedParams{
+				GroupByExpr: "kind",
+				Schema:      "custom_schema",
+				WhereClause: "true",
+			})
+			requireMissingRelation(t, err, "custom_schema.river_job")
+		})
+	})
+
+	t.Run("JobDelete", func(t *testing.T) {
+		t.Parallel()
+
+		t.Run("DoesNotDeleteARunningJob", func(t *testing.T) {
+			t.Parallel()
+
+			exec, _ := setup(ctx, t)
+
+			job := testfactory.Job(ctx, t, exec, &testfactory.JobOpts{
+				State: ptrutil.Ptr(rivertype.JobStateRunning),
+			})
+
+			jobAfter, err := exec.JobDelete(ctx, &riverdriver.JobDeleteParams{
+				ID: job.ID,
+			})
+			require.ErrorIs(t, err, rivertype.ErrJobRunning)
+			require.Nil(t, jobAfter)
+
+			jobUpdated, err := exec.JobGetByID(ctx, &riverdriver.JobGetByIDParams{ID: job.ID, Schema: ""})
+			require.NoError(t, err)
+			require.Equal(t, rivertype.JobStateRunning, jobUpdated.State)
+		})
+
+		for _, state := range []rivertype.JobState{
+			rivertype.JobStateAvailable,
+			rivertype.JobStateCancelled,
+			rivertype.JobStateCompleted,
+			rivertype.JobStateDiscarded,
+			rivertype.JobStatePending,
+			rivertype.JobStateRetryable,
+			rivertype.JobStateScheduled,
+		} {
+			t.Run(fmt.Sprintf("DeletesA_%s_Job", state), func(t *testing.T) {
+				t.Parallel()
+
+				exec, _ := setup(ctx, t)
+
+				now := time.Now().UTC()
+
+				setFinalized := slices.Contains([]rivertype.JobState{
+					rivertype.JobStateCancelled,
+					rivertype.JobStateCompleted,
+					rivertype.JobStateDiscarded,
+				}, state)
+
+				var finalizedAt *time.Time
+				if setFinalized {
+					finalizedAt = &now
+				}
+
+				job := testfactory.Job(ctx, t, exec, &testfactory.JobOpts{
+					FinalizedAt: finalizedAt,
+					ScheduledAt: ptrutil.Ptr(now.Add(1 * time.Hour)),
+					State:       &state,
+				})
+
+				jobAfter, err := exec.JobDelete(ctx, &riverdriver.JobDeleteParams{
+					ID:     job.ID,
+					Schema: "",
+				})
+				require.NoError(t, err)
+				require.NotNil(t, jobAfter)
+				require.Equal(t, job.ID, jobAfter.ID)
+				require.Equal(t, state, jobAfter.State)
+
+				_, err = exec.JobGetByID(ctx, &riverdriver.JobGetByIDParams{ID: job.ID, Schema: ""})
+				require.ErrorIs(t, err, rivertype.ErrNotFound)
+			})
+		}
+
+		t.Run("ReturnsErrNotFoundIfJobDoesNotExist", func(t *testing.T) {
+			t.Parallel()
+
+			exec, _ := setup(ctx, t)
+
+			jobAfter, err := exec.JobDelete(ctx, &riverdriver.JobDeleteParams{
+				ID: 1234567890,
+			})
+			require.ErrorIs(t, err, rivertype.ErrNotFound)
+			require.Nil(t, jobAfter)
+		})
+
+		t.Run("AlternateSchema", func(t *testing.T) {
+			t.Parallel()
+
+			exec, _ := setup(ctx, t)
+
+			job := testfactory.Job(ctx, t, exec, &testfactory.JobOpts{})
+
+			_, err := exec.JobDelete(ctx, &riverdriver.JobDeleteParams{
+				ID:     job.ID,
+				Schema: "custom_schema",
+			})
+			requireMissingRelation(t, err, "custom_schema.river_job")
+		})
+	})
+
+	t.Run("JobDeleteBefore", func(t *testing.T) {
+		t.Parallel()
+
+		exec, _ := setup(ctx, t)
+
+		var (
+			horizon       = time.Now()
+			beforeHorizon = horizon.Add(-1 * time.Minute)
+			afterHorizon  = horizon.Add(1 * time.Minute)
+		)
+
+		deletedJob1 := testfactory.Job(ctx, t, exec, &testfactory.JobOpts{FinalizedAt: &beforeHorizon, State: ptrutil.Ptr(rivertype.JobStateCancelled)})
+		deletedJob2 := testfactory.Job(ctx, t, exec, &testfactory.JobOpts{FinalizedAt: &beforeHorizon, State: ptrutil.Ptr(rivertype.JobStateCompleted)})
+		deletedJob3 := testfactory.Job(ctx, t, exec, &testfactory.JobOpts{FinalizedAt: &beforeHorizon, State: ptrutil.Ptr(rivertype.JobStateDiscarded)})
+
+		// Not deleted because not appropriate state.
+		notDeletedJob1 := testfactory.Job(ctx, t, exec, &testfactory.JobOpts{State: ptrutil.Ptr(rivertype.JobStateAvailable)})
+		notDeletedJob2 := testfactory.Job(ctx, t, exec, &testfactory.JobOpts{State: ptrutil.Ptr(rivertype.JobStateRunning)})
+
+		// Not deleted because after the delete horizon.
+		notDeletedJob3 := testfactory.Job(ctx, t, exec, &testfactory.JobOpts{FinalizedAt: &afterHorizon, State: ptrutil.Ptr(rivertype.JobStateCancelled)})
+
+		// Max two deleted on the first pass.
+		numDeleted, err := exec.JobDeleteBefore(ctx, &riverdriver.JobDeleteBeforeParams{
+			CancelledFinalizedAtHorizon: horizon,
+			CompletedFinalizedAtHorizon: horizon,
+			DiscardedFinalizedAtHorizon: horizon,
+			Max:                         2,
+		})
+		require.NoError(t, err)
+		require.Equal(t, 2, numDeleted)
+
+		// And one more pass gets the last one.
+		numDeleted, err = exec.JobDeleteBefore(ctx, &riverdriver.JobDeleteBeforeParams{
+			CancelledFinalizedAtHorizon: horizon,
+			CompletedFinalizedAtHorizon: horizon,
+			DiscardedFinalizedAtHorizon: horizon,
+			Max:                         2,
+		})
+		require.NoError(t, err)
+		require.Equal(t, 1, numDeleted)
+
+		// All deleted.
 		_, err = exec.JobGetByID(ctx, &riverdriver.JobGetByIDParams{ID: deletedJob1.ID, Schema: ""})
 		require.ErrorIs(t, err, rivertype.ErrNotFound)
 		_, err = exec.JobGetByID(ctx, &riverdriver.JobGetByIDParams{ID: deletedJob2.ID, Schema: ""})
@@ -530,13 +1108,60 @@ func Exercise[TTx any](ctx context.Context, t *testing.T,
 		_, err = exec.JobGetByID(ctx, &riverdriver.JobGetByIDParams{ID: deletedJob3.ID, Schema: ""})
 		require.ErrorIs(t, err, rivertype.ErrNotFound)
 
-		// Not deleted
-		_, err = exec.JobGetByID(ctx, &riverdriver.JobGetByIDParams{ID: notDeletedJob1.ID, Schema: ""})
-		require.NoError(t, err)
-		_, err = exec.JobGetByID(ctx, &riverdriver.JobGetByIDParams{ID: notDeletedJob2.ID, Schema: ""})
-		require.NoError(t, err)
-		_, err = exec.JobGetByID(ctx, &riverdriver.JobGetByIDParams{ID: notDeletedJob3.ID, Schema: ""})
-		require.NoError(t, err)
+		// Not deleted
+		_, err = exec.JobGetByID(ctx, &riverdriver.JobGetByIDParams{ID: notDeletedJob1.ID, Schema: ""})
+		require.NoError(t, err)
+		_, err = exec.JobGetByID(ctx, &riverdriver.JobGetByIDParams{ID: notDeletedJob2.ID, Schema: ""})
+		require.NoError(t, err)
+		_, err = exec.JobGetByID(ctx, &riverdriver.JobGetByIDParams{ID: notDeletedJob3.ID, Schema: ""})
+		require.NoError(t, err)
+	})
+
+	t.Run("JobDeleteMany", func(t *testing.T) {
+		t.Parallel()
+
+		t.Run("DeletesJobsExceptRunning", func(t *testing.T) {
+			t.Parallel()
+
+			exec, _ := setup(ctx, t)
+
+			job1 := testfactory.Job(ctx, t, exec, &testfactory.JobOpts{State: ptrutil.Ptr(rivertype.JobStateAvailable)})
+			job2 := testfactory.Job(ctx, t, exec, &testfactory.JobOpts{FinalizedAt: ptrutil.Ptr(time.Now()), State: ptrutil.Ptr(rivertype.JobStateDiscarded)})
+			job3 := testfactory.Job(ctx, t, exec, &testfactory.JobOpts{State: ptrutil.Ptr(rivertype.JobStateRunning)})
+			job4 := testfactory.Job(ctx, t, exec, &testfactory.JobOpts{State: ptrutil.Ptr(rivertype.JobStateAvailable)}) // not included in IDs
+
+			jobs, err := exec.JobDeleteMany(ctx, &riverdriver.JobDeleteManyParams{
+				ID: []int64{job1.ID, job2.ID, job3.ID},
+			})
+			require.NoError(t, err)
+			require.ElementsMatch(t,
+				[]int64{job1.ID, job2.ID},
+				sliceutil.Map(jobs, func(j *rivertype.JobRow) int64 { return j.ID }),
+			)
+
+			_, err = exec.JobGetByID(ctx, &riverdriver.JobGetByIDParams{ID: job1.ID})
+			require.ErrorIs(t, err, rivertype.ErrNotFound)
+			_, err = exec.JobGetByID(ctx, &riverdriver.JobGetByIDParams{ID: job2.ID})
+			require.ErrorIs(t, err, rivertype.ErrNotFound)
+
+			// Running job and job not included in IDs are still present.
+			_, err = exec.JobGetByID(ctx, &riverdriver.JobGetByIDParams{ID: job3.ID})
+			require.NoError(t, err)
+			_, err = exec.JobGetByID(ctx, &riverdriver.JobGetByIDParams{ID: job4.ID})
+			require.NoError(t, err)
+		})
+
+		t.Run("AlternateSchema", func(t *testing.T) {
+			t.Parallel()
+
+			exec, _ := setup(ctx, t)
+
+			_, err := exec.JobDeleteMany(ctx, &riverdriver.JobDeleteManyParams{
+				ID:     []int64{1234567890},
+				Schema: "custom_schema",
+			})
+			requireMissingRelation(t, err, "custom_schema.river_job")
+		})
 	})
 
 	t.Run("JobGetAvailable", func(t *testing.T) {
@@ -559,6 +1184,50 @@ func Exercise[TTx any](ctx context.Context, t *testing.T,
 
 			jobRow := jobRows[0]
 			require.Equal(t, []string{clientID}, jobRow.AttemptedBy)
+			require.False(t, gjson.GetBytes(jobRow.Metadata, "river:lease").Exists())
+		})
+
+		t.Run("TakesLease", func(t *testing.T) {
+			t.Parallel()
+
+			exec, _ := setup(ctx, t)
+
+			leaseExpiresAt := time.Now().Add(time.Minute).UTC()
+
+			job := testfactory.Job(ctx, t, exec, &testfactory.JobOpts{Attempt: ptrutil.Ptr(2), Metadata: []byte(`{"foo":"bar"}`)})
+			fairJob := testfactory.Job(ctx, t, exec, &testfactory.JobOpts{Metadata: []byte(`{"river:partition":{"customer_id":1}}`), Queue: ptrutil.Ptr("fair_queue")})
+
+			jobRows, err := exec.JobGetAvailable(ctx, &riverdriver.JobGetAvailableParams{
+				ClientID:       clientID,
+				LeaseExpiresAt: &leaseExpiresAt,
+				Max:            100,
+				Queue:          rivercommon.QueueDefault,
+			})
+			require.NoError(t, err)
+			require.Len(t, jobRows, 1)
+			require.Equal(t, job.ID, jobRows[0].ID)
+
+			// The lease is for the attempt started by the fetch, and existing
+			// metadata is kept.
+			require.Equal(t, "bar", gjson.GetBytes(jobRows[0].Metadata, "foo").String())
+			require.Equal(t, int64(3), gjson.GetBytes(jobRows[0].Metadata, "river:lease.attempt").Int())
+			require.WithinDuration(t, leaseExpiresAt, gjson.GetBytes(jobRows[0].Metadata, "river:lease.expires_at").Time(), time.Millisecond)
+
+			// Fair fetches take leases too.
+			jobRows, err = exec.JobGetAvailable(ctx, &riverdriver.JobGetAvailableParams{
+				ClientID:       clientID,
+				FairKeys:       []string{`{"customer_id": 1}`},
+				FairMaxPerKey:  100,
+				FairPriority:   1,
+				LeaseExpiresAt: &leaseExpiresAt,
+				Max:            100,
+				Queue:          "fair_queue",
+			})
+			require.NoError(t, err)
+			require.Len(t, jobRows, 1)
+			require.Equal(t, fairJob.ID, jobRows[0].ID)
+			require.Equal(t, int64(1), gjson.GetBytes(jobRows[0].Metadata, "river:lease.attempt").Int())
+			require.WithinDuration(t, leaseExpiresAt, gjson.GetBytes(jobRows[0].Metadata, "river:lease.expires_at").Time(), time.Millisecond)
 		})
 
 		t.Run("ConstrainedToLimit", func(t *testing.T) {
@@ -579,6 +1248,234 @@ func Exercise[TTx any](ctx context.Context, t *testing.T,
 			require.Len(t, jobRows, 1)
 		})
 
+		t.Run("ConstrainedToGlobalLimit", func(t *testing.T) {
+			t.Parallel()
+
+			exec, _ := setup(ctx, t)
+
+			_ = testfactory.Job(ctx, t, exec, &testfactory.JobOpts{State: ptrutil.Ptr(rivertype.JobStateRunning)})
+			_ = testfactory.Job(ctx, t, exec, &testfactory.JobOpts{State: ptrutil.Ptr(rivertype.JobStateRunning)})
+
+			// Running jobs in other queues don't count against the limit.
+			_ = testfactory.Job(ctx, t, exec, &testfactory.JobOpts{Queue: ptrutil.Ptr("other-queue"), State: ptrutil.Ptr(rivertype.JobStateRunning)})
+
+			for range 3 {
+				_ = testfactory.Job(ctx, t, exec, &testfactory.JobOpts{})
+			}
+
+			// Two running jobs leave room for only one more under the limit.
+			jobRows, err := exec.JobGetAvailable(ctx, &riverdriver.JobGetAvailableParams{
+				ClientID:    clientID,
+				GlobalLimit: 3,
+				Max:         100,
+				Queue:       rivercommon.QueueDefault,
+			})
+			require.NoError(t, err)
+			require.Len(t, jobRows, 1)
+
+			// Limit fully consumed.
+			jobRows, err = exec.JobGetAvailable(ctx, &riverdriver.JobGetAvailableParams{
+				ClientID:    clientID,
+				GlobalLimit: 3,
+				Max:         100,
+				Queue:       rivercommon.QueueDefault,
+			})
+			require.NoError(t, err)
+			require.Empty(t, jobRows)
+
+			// Limit exceeded, which is possible if it was lowered while jobs
+			// were running.
+			jobRows, err = exec.JobGetAvailable(ctx, &riverdriver.JobGetAvailableParams{
+				ClientID:    clientID,
+				GlobalLimit: 1,
+				Max:         100,
+				Queue:       rivercommon.QueueDefault,
+			})
+			require.NoError(t, err)
+			require.Empty(t, jobRows)
+
+			// Max takes precedence when it's lower than the remaining capacity.
+			jobRows, err = exec.JobGetAvailable(ctx, &riverdriver.JobGetAvailableParams{
+				ClientID:    clientID,
+				GlobalLimit: 10,
+				Max:         1,
+				Queue:       rivercommon.QueueDefault,
+			})
+			require.NoError(t, err)
+			require.Len(t, jobRows, 1)
+		})
+
+		t.Run("ConstrainedToMaxByKind", func(t *testing.T) {
+			t.Parallel()
+
+			exec, _ := setup(ctx, t)
+
+			for range 3 {
+				_ = testfactory.Job(ctx, t, exec, &testfactory.JobOpts{Kind: ptrutil.Ptr("kind1")})
+				_ = testfactory.Job(ctx, t, exec, &testfactory.JobOpts{Kind: ptrutil.Ptr("kind2")})
+				_ = testfactory.Job(ctx, t, exec, &testfactory.JobOpts{Kind: ptrutil.Ptr("kind3")})
+			}
+
+			jobRows, err := exec.JobGetAvailable(ctx, &riverdriver.JobGetAvailableParams{
+				ClientID:  clientID,
+				Max:       100,
+				MaxByKind: map[string]int{"kind1": 1, "kind2": 0},
+				Queue:     rivercommon.QueueDefault,
+			})
+			require.NoError(t, err)
+
+			jobCountsByKind := make(map[string]int)
+			for _, jobRow := range jobRows {
+				jobCountsByKind[jobRow.Kind]++
+			}
+			require.Equal(t, map[string]int{"kind1": 1, "kind3": 3}, jobCountsByKind)
+		})
+
+		t.Run("ConstrainedToPartitionLimit", func(t *testing.T) {
+			t.Parallel()
+
+			exec, _ := setup(ctx, t)
+
+			var (
+				customer1Metadata = []byte(`{"river:partition":{"customer_id":1}}`)
+				customer2Metadata = []byte(`{"river:partition":{"customer_id":2}}`)
+			)
+
+			// Customer 1 already has a job running, so only one more of
+			// theirs may be started with a limit of two.
+			_ = testfactory.Job(ctx, t, exec, &testfactory.JobOpts{Metadata: customer1Metadata, State: ptrutil.Ptr(rivertype.JobStateRunning)})
+
+			for range 3 {
+				_ = testfactory.Job(ctx, t, exec, &testfactory.JobOpts{Metadata: customer1Metadata})
+				_ = testfactory.Job(ctx, t, exec, &testfactory.JobOpts{Metadata: customer2Metadata})
+				_ = testfactory.Job(ctx, t, exec, nil) // no partition key
+			}
+
+			jobRows, err := exec.JobGetAvailable(ctx, &riverdriver.JobGetAvailableParams{
+				ClientID:        clientID,
+				Max:             100,
+				PartitionByArgs: true,
+				PartitionLimit:  2,
+				Queue:           rivercommon.QueueDefault,
+			})
+			require.NoError(t, err)
+
+			jobCountsByPartition := make(map[string]int)
+			for _, jobRow := range jobRows {
+				jobCountsByPartition[gjson.GetBytes(jobRow.Metadata, "river:partition.customer_id").String()]++
+			}
+			require.Equal(t, map[string]int{"": 2, "1": 1, "2": 2}, jobCountsByPartition)
+		})
+
+		t.Run("ConstrainedToPartitionLimitByKind", func(t *testing.T) {
+			t.Parallel()
+
+			exec, _ := setup(ctx, t)
+
+			for range 3 {
+				_ = testfactory.Job(ctx, t, exec, &testfactory.JobOpts{Kind: ptrutil.Ptr("kind1")})
+				_ = testfactory.Job(ctx, t, exec, &testfactory.JobOpts{Kind: ptrutil.Ptr("kind2")})
+			}
+
+			jobRows, err := exec.JobGetAvailable(ctx, &riverdriver.JobGetAvailableParams{
+				ClientID:        clientID,
+				Max:             100,
+				PartitionByKind: true,
+				PartitionLimit:  1,
+				Queue:           rivercommon.QueueDefault,
+			})
+			require.NoError(t, err)
+
+			jobCountsByKind := make(map[string]int)
+			for _, jobRow := range jobRows {
+				jobCountsByKind[jobRow.Kind]++
+			}
+			require.Equal(t, map[string]int{"kind1": 1, "kind2": 1}, jobCountsByKind)
+		})
+
+		t.Run("ConstrainedToPartitionCandidateMax", func(t *testing.T) {
+			t.Parallel()
+
+			exec, _ := setup(ctx, t)
+
+			var (
+				customer1Metadata = []byte(`{"river:partition":{"customer_id":1}}`)
+				customer2Metadata = []byte(`{"river:partition":{"customer_id":2}}`)
+			)
+
+			// Customer 1 is already at its limit of two running jobs, and has
+			// a deep backlog at the head of the queue, followed by jobs for
+			// customer 2 that would fall outside a small candidate set if
+			// customer 1's jobs were counted as candidates.
+			for range 2 {
+				_ = testfactory.Job(ctx, t, exec, &testfactory.JobOpts{Metadata: customer1Metadata, State: ptrutil.Ptr(rivertype.JobStateRunning)})
+			}
+			for range 20 {
+				_ = testfactory.Job(ctx, t, exec, &testfactory.JobOpts{Metadata: customer1Metadata, Priority: ptrutil.Ptr(1)})
+			}
+			for range 3 {
+				_ = testfactory.Job(ctx, t, exec, &testfactory.JobOpts{Metadata: customer2Metadata, Priority: ptrutil.Ptr(2)})
+			}
+
+			fetch := func(partitionCandidateMax int) map[string]int {
+				t.Helper()
+
+				// Rolled back so that each fetch sees the same available jobs.
+				tx, err := exec.Begin(ctx)
+				require.NoError(t, err)
+				defer tx.Rollback(ctx)
+
+				jobRows, err := tx.JobGetAvailable(ctx, &riverdriver.JobGetAvailableParams{
+					ClientID:              clientID,
+					Max:                   100,
+					PartitionByArgs:       true,
+					PartitionCandidateMax: partitionCandidateMax,
+					PartitionLimit:        2,
+					Queue:                 rivercommon.QueueDefault,
+				})
+				require.NoError(t, err)
+
+				jobCountsByPartition := make(map[string]int)
+				for _, jobRow := range jobRows {
+					jobCountsByPartition[gjson.GetBytes(jobRow.Metadata, "river:partition.customer_id").String()]++
+				}
+				return jobCountsByPartition
+			}
+
+			require.Equal(t, map[string]int{"2": 2}, fetch(10))
+			require.Equal(t, map[string]int{"2": 2}, fetch(0))
+		})
+
+		t.Run("FairKeysConstrainedToPartitionLimit", func(t *testing.T) {
+			t.Parallel()
+
+			exec, _ := setup(ctx, t)
+
+			for range 3 {
+				_ = testfactory.Job(ctx, t, exec, &testfactory.JobOpts{Kind: ptrutil.Ptr("kind1"), Metadata: []byte(`{"river:partition":{"customer_id":1}}`)})
+				_ = testfactory.Job(ctx, t, exec, &testfactory.JobOpts{Kind: ptrutil.Ptr("kind1"), Metadata: []byte(`{"river:partition":{"customer_id":2}}`)})
+				_ = testfactory.Job(ctx, t, exec, &testfactory.JobOpts{Kind: ptrutil.Ptr("kind2"), Metadata: []byte(`{"river:partition":{"customer_id":2}}`)})
+			}
+
+			jobRows, err := exec.JobGetAvailable(ctx, &riverdriver.JobGetAvailableParams{
+				ClientID:        clientID,
+				FairKeys:        []string{`{"customer_id": 1}`, `{"customer_id": 2}`},
+				FairMaxPerKey:   100,
+				FairPriority:    1,
+				Max:             100,
+				PartitionByKind: true,
+				PartitionLimit:  2,
+				Queue:           rivercommon.QueueDefault,
+			})
+			require.NoError(t, err)
+
+			jobCountsByKind := make(map[string]int)
+			for _, jobRow := range jobRows {
+				jobCountsByKind[jobRow.Kind]++
+			}
+			require.Equal(t, map[string]int{"kind1": 2, "kind2": 2}, jobCountsByKind)
+		})
+
 		t.Run("ConstrainedToQueue", func(t *testing.T) {
 			t.Parallel()
 
@@ -639,51 +1536,254 @@ func Exercise[TTx any](ctx context.Context, t *testing.T,
 				Queue:    rivercommon.QueueDefault,
 			})
 			require.NoError(t, err)
-			require.Len(t, jobRows, 1)
-			require.Equal(t, job2.ID, jobRows[0].ID)
+			require.Len(t, jobRows, 1)
+			require.Equal(t, job2.ID, jobRows[0].ID)
+		})
+
+		t.Run("FairKeys", func(t *testing.T) {
+			t.Parallel()
+
+			exec, _ := setup(ctx, t)
+
+			var (
+				customer1Metadata = []byte(`{"river:partition":{"customer_id":1}}`)
+				customer2Metadata = []byte(`{"river:partition":{"customer_id":2}}`)
+				customer3Metadata = []byte(`{"river:partition":{"customer_id":3}}`)
+			)
+
+			// Customer 1 has a large backlog inserted ahead of everyone else.
+			for range 5 {
+				_ = testfactory.Job(ctx, t, exec, &testfactory.JobOpts{Metadata: customer1Metadata})
+			}
+			_ = testfactory.Job(ctx, t, exec, &testfactory.JobOpts{Metadata: customer2Metadata})
+			_ = testfactory.Job(ctx, t, exec, &testfactory.JobOpts{Metadata: customer2Metadata})
+			_ = testfactory.Job(ctx, t, exec, &testfactory.JobOpts{Metadata: customer3Metadata})
+
+			// Lower priority job that's not fetched.
+			_ = testfactory.Job(ctx, t, exec, &testfactory.JobOpts{Metadata: customer3Metadata, Priority: ptrutil.Ptr(2)})
+
+			jobRows, err := exec.JobGetAvailable(ctx, &riverdriver.JobGetAvailableParams{
+				ClientID: clientID,
+				FairKeys: []string{
+					`{"customer_id": 1}`,
+					`{"customer_id": 2}`,
+					`{"customer_id": 3}`,
+				},
+				FairMaxPerKey: 2,
+				FairPriority:  1,
+				Max:           4,
+				Queue:         rivercommon.QueueDefault,
+			})
+			require.NoError(t, err)
+
+			jobCountsByPartition := make(map[string]int)
+			for _, jobRow := range jobRows {
+				require.Equal(t, 1, jobRow.Priority)
+				jobCountsByPartition[gjson.GetBytes(jobRow.Metadata, "river:partition.customer_id").String()]++
+			}
+
+			// Every customer gets a job before any gets a second one.
+			require.Len(t, jobRows, 4)
+			require.Equal(t, 1, jobCountsByPartition["3"])
+			require.LessOrEqual(t, jobCountsByPartition["1"], 2)
+			require.LessOrEqual(t, jobCountsByPartition["2"], 2)
+		})
+
+		t.Run("FairKeysOnlyGivenKeys", func(t *testing.T) {
+			t.Parallel()
+
+			exec, _ := setup(ctx, t)
+
+			job1 := testfactory.Job(ctx, t, exec, &testfactory.JobOpts{Metadata: []byte(`{"river:partition":{"customer_id":1}}`)})
+			_ = testfactory.Job(ctx, t, exec, &testfactory.JobOpts{Metadata: []byte(`{"river:partition":{"customer_id":2}}`)})
+			job3 := testfactory.Job(ctx, t, exec, nil) // no partition key
+
+			jobRows, err := exec.JobGetAvailable(ctx, &riverdriver.JobGetAvailableParams{
+				ClientID:      clientID,
+				FairKeys:      []string{`{"customer_id": 1}`, `{}`},
+				FairMaxPerKey: 100,
+				FairPriority:  1,
+				Max:           100,
+				Queue:         rivercommon.QueueDefault,
+			})
+			require.NoError(t, err)
+			require.ElementsMatch(t, []int64{job1.ID, job3.ID}, sliceutil.Map(jobRows, func(j *rivertype.JobRow) int64 { return j.ID }))
+		})
+
+		t.Run("Prioritized", func(t *testing.T) {
+			t.Parallel()
+
+			exec, _ := setup(ctx, t)
+
+			// Insert jobs with decreasing priority numbers (3, 2, 1) which means increasing priority.
+			for i := 3; i > 0; i-- {
+				_ = testfactory.Job(ctx, t, exec, &testfactory.JobOpts{
+					Priority: &i,
+				})
+			}
+
+			jobRows, err := exec.JobGetAvailable(ctx, &riverdriver.JobGetAvailableParams{
+				ClientID: clientID,
+				Max:      2,
+				Queue:    rivercommon.QueueDefault,
+			})
+			require.NoError(t, err)
+			require.Len(t, jobRows, 2, "expected to fetch exactly 2 jobs")
+
+			// Because the jobs are ordered within the fetch query's CTE but *not* within
+			// the final query, the final result list may not actually be sorted. This is
+			// fine, because we've already ensured that we've fetched the jobs we wanted
+			// to fetch via that ORDER BY. For testing we'll need to sort the list after
+			// fetch to easily assert that the expected jobs are in it.
+			sort.Slice(jobRows, func(i, j int) bool { return jobRows[i].Priority < jobRows[j].Priority })
+
+			require.Equal(t, 1, jobRows[0].Priority, "expected first job to have priority 1")
+			require.Equal(t, 2, jobRows[1].Priority, "expected second job to have priority 2")
+
+			// Should fetch the one remaining job on the next attempt:
+			jobRows, err = exec.JobGetAvailable(ctx, &riverdriver.JobGetAvailableParams{
+				ClientID: clientID,
+				Max:      1,
+				Queue:    rivercommon.QueueDefault,
+			})
+			require.NoError(t, err)
+			require.NoError(t, err)
+			require.Len(t, jobRows, 1, "expected to fetch exactly 1 job")
+			require.Equal(t, 3, jobRows[0].Priority, "expected final job to have priority 3")
+		})
+	})
+
+	t.Run("JobGetAvailableFairKeys", func(t *testing.T) {
+		t.Parallel()
+
+		t.Run("DistinctKeysInOrder", func(t *testing.T) {
+			t.Parallel()
+
+			exec, _ := setup(ctx, t)
+
+			for _, customerID := range []int{3, 1, 2, 1, 3} {
+				_ = testfactory.Job(ctx, t, exec, &testfactory.JobOpts{Metadata: []byte(fmt.Sprintf(`{"river:partition":{"customer_id":%d}}`, customerID))})
+			}
+			_ = testfactory.Job(ctx, t, exec, nil) // no partition key
+
+			// Not available or in another queue, so not included.
+			_ = testfactory.Job(ctx, t, exec, &testfactory.JobOpts{Metadata: []byte(`{"river:partition":{"customer_id":4}}`), State: ptrutil.Ptr(rivertype.JobStateRunning)})
+			_ = testfactory.Job(ctx, t, exec, &testfactory.JobOpts{Metadata: []byte(`{"river:partition":{"customer_id":5}}`), Queue: ptrutil.Ptr("other-queue")})
+
+			result, err := exec.JobGetAvailableFairKeys(ctx, &riverdriver.JobGetAvailableFairKeysParams{
+				Max:   100,
+				Queue: rivercommon.QueueDefault,
+			})
+			require.NoError(t, err)
+			require.Equal(t, []string{`{}`, `{"customer_id": 1}`, `{"customer_id": 2}`, `{"customer_id": 3}`}, result.FairKeys)
+			require.Equal(t, 1, result.Priority)
+		})
+
+		t.Run("AfterAndMax", func(t *testing.T) {
+			t.Parallel()
+
+			exec, _ := setup(ctx, t)
+
+			for customerID := range 5 {
+				_ = testfactory.Job(ctx, t, exec, &testfactory.JobOpts{Metadata: []byte(fmt.Sprintf(`{"river:partition":{"customer_id":%d}}`, customerID))})
+			}
+
+			result, err := exec.JobGetAvailableFairKeys(ctx, &riverdriver.JobGetAvailableFairKeysParams{
+				After: ptrutil.Ptr(`{"customer_id": 1}`),
+				Max:   2,
+				Queue: rivercommon.QueueDefault,
+			})
+			require.NoError(t, err)
+			require.Equal(t, []string{`{"customer_id": 2}`, `{"customer_id": 3}`}, result.FairKeys)
+
+			result, err = exec.JobGetAvailableFairKeys(ctx, &riverdriver.JobGetAvailableFairKeysParams{
+				After: ptrutil.Ptr(`{"customer_id": 4}`),
+				Max:   2,
+				Queue: rivercommon.QueueDefault,
+			})
+			require.NoError(t, err)
+			require.Empty(t, result.FairKeys)
 		})
 
-		t.Run("Prioritized", func(t *testing.T) {
+		t.Run("MostUrgentPriorityOnly", func(t *testing.T) {
 			t.Parallel()
 
 			exec, _ := setup(ctx, t)
 
-			// Insert jobs with decreasing priority numbers (3, 2, 1) which means increasing priority.
-			for i := 3; i > 0; i-- {
-				_ = testfactory.Job(ctx, t, exec, &testfactory.JobOpts{
-					Priority: &i,
-				})
-			}
+			_ = testfactory.Job(ctx, t, exec, &testfactory.JobOpts{Metadata: []byte(`{"river:partition":{"customer_id":1}}`), Priority: ptrutil.Ptr(3)})
+			_ = testfactory.Job(ctx, t, exec, &testfactory.JobOpts{Metadata: []byte(`{"river:partition":{"customer_id":2}}`), Priority: ptrutil.Ptr(2)})
+			_ = testfactory.Job(ctx, t, exec, &testfactory.JobOpts{Metadata: []byte(`{"river:partition":{"customer_id":3}}`), Priority: ptrutil.Ptr(2)})
 
-			jobRows, err := exec.JobGetAvailable(ctx, &riverdriver.JobGetAvailableParams{
-				ClientID: clientID,
-				Max:      2,
-				Queue:    rivercommon.QueueDefault,
+			result, err := exec.JobGetAvailableFairKeys(ctx, &riverdriver.JobGetAvailableFairKeysParams{
+				Max:   100,
+				Queue: rivercommon.QueueDefault,
 			})
 			require.NoError(t, err)
-			require.Len(t, jobRows, 2, "expected to fetch exactly 2 jobs")
+			require.Equal(t, []string{`{"customer_id": 2}`, `{"customer_id": 3}`}, result.FairKeys)
+			require.Equal(t, 2, result.Priority)
+		})
 
-			// Because the jobs are ordered within the fetch query's CTE but *not* within
-			// the final query, the final result list may not actually be sorted. This is
-			// fine, because we've already ensured that we've fetched the jobs we wanted
-			// to fetch via that ORDER BY. For testing we'll need to sort the list after
-			// fetch to easily assert that the expected jobs are in it.
-			sort.Slice(jobRows, func(i, j int) bool { return jobRows[i].Priority < jobRows[j].Priority })
+		t.Run("ScheduledInFutureExcluded", func(t *testing.T) {
+			t.Parallel()
 
-			require.Equal(t, 1, jobRows[0].Priority, "expected first job to have priority 1")
-			require.Equal(t, 2, jobRows[1].Priority, "expected second job to have priority 2")
+			exec, _ := setup(ctx, t)
 
-			// Should fetch the one remaining job on the next attempt:
-			jobRows, err = exec.JobGetAvailable(ctx, &riverdriver.JobGetAvailableParams{
-				ClientID: clientID,
-				Max:      1,
-				Queue:    rivercommon.QueueDefault,
+			now := time.Now().UTC()
+
+			// The first customer's only job has the most urgent priority, but
+			// isn't due yet, so neither its key nor its priority are used.
+			_ = testfactory.Job(ctx, t, exec, &testfactory.JobOpts{Metadata: []byte(`{"river:partition":{"customer_id":1}}`), Priority: ptrutil.Ptr(1), ScheduledAt: ptrutil.Ptr(now.Add(time.Minute))})
+			_ = testfactory.Job(ctx, t, exec, &testfactory.JobOpts{Metadata: []byte(`{"river:partition":{"customer_id":1}}`), Priority: ptrutil.Ptr(2), ScheduledAt: ptrutil.Ptr(now.Add(time.Minute))})
+			_ = testfactory.Job(ctx, t, exec, &testfactory.JobOpts{Metadata: []byte(`{"river:partition":{"customer_id":2}}`), Priority: ptrutil.Ptr(2), ScheduledAt: ptrutil.Ptr(now.Add(-time.Minute))})
+
+			result, err := exec.JobGetAvailableFairKeys(ctx, &riverdriver.JobGetAvailableFairKeysParams{
+				Max:   100,
+				Now:   &now,
+				Queue: rivercommon.QueueDefault,
 			})
 			require.NoError(t, err)
+			require.Equal(t, []string{`{"customer_id": 2}`}, result.FairKeys)
+			require.Equal(t, 2, result.Priority)
+		})
+
+		t.Run("NoAvailableJobs", func(t *testing.T) {
+			t.Parallel()
+
+			exec, _ := setup(ctx, t)
+
+			result, err := exec.JobGetAvailableFairKeys(ctx, &riverdriver.JobGetAvailableFairKeysParams{
+				Max:   100,
+				Queue: rivercommon.QueueDefault,
+			})
 			require.NoError(t, err)
-			require.Len(t, jobRows, 1, "expected to fetch exactly 1 job")
-			require.Equal(t, 3, jobRows[0].Priority, "expected final job to have priority 3")
+			require.Empty(t, result.FairKeys)
+		})
+	})
+
+	t.Run("JobGetAvailableKinds", func(t *testing.T) {
+		t.Parallel()
+
+		exec, _ := setup(ctx, t)
+
+		now := time.Now().UTC()
+
+		_ = testfactory.Job(ctx, t, exec, &testfactory.JobOpts{Kind: ptrutil.Ptr("kind2")})
+		_ = testfactory.Job(ctx, t, exec, &testfactory.JobOpts{Kind: ptrutil.Ptr("kind1")})
+		_ = testfactory.Job(ctx, t, exec, &testfactory.JobOpts{Kind: ptrutil.Ptr("kind1")})
+		_ = testfactory.Job(ctx, t, exec, &testfactory.JobOpts{Kind: ptrutil.Ptr("not_requested")})
+
+		// Not available, not yet scheduled, or in another queue, so not included.
+		_ = testfactory.Job(ctx, t, exec, &testfactory.JobOpts{Kind: ptrutil.Ptr("running"), State: ptrutil.Ptr(rivertype.JobStateRunning)})
+		_ = testfactory.Job(ctx, t, exec, &testfactory.JobOpts{Kind: ptrutil.Ptr("future"), ScheduledAt: ptrutil.Ptr(now.Add(time.Hour))})
+		_ = testfactory.Job(ctx, t, exec, &testfactory.JobOpts{Kind: ptrutil.Ptr("other_queue"), Queue: ptrutil.Ptr("other-queue")})
+
+		kinds, err := exec.JobGetAvailableKinds(ctx, &riverdriver.JobGetAvailableKindsParams{
+			Kinds: []string{"future", "kind1", "kind2", "no_jobs", "other_queue", "running"},
+			Now:   &now,
+			Queue: rivercommon.QueueDefault,
 		})
+		require.NoError(t, err)
+		require.Equal(t, []string{"kind1", "kind2"}, kinds)
 	})
 
 	t.Run("JobGetByID", func(t *testing.T) {
@@ -759,6 +1859,56 @@ func Exercise[TTx any](ctx context.Context, t *testing.T,
 			sliceutil.Map(jobs, func(j *rivertype.JobRow) int64 { return j.ID }))
 	})
 
+	t.Run("JobGetFinalizedAfter", func(t *testing.T) {
+		t.Parallel()
+
+		exec, _ := setup(ctx, t)
+
+		var (
+			cursor     = time.Now().Add(-1 * time.Hour)
+			afterTime1 = cursor.Add(1 * time.Minute)
+			afterTime2 = cursor.Add(2 * time.Minute)
+		)
+
+		job1 := testfactory.Job(ctx, t, exec, &testfactory.JobOpts{FinalizedAt: &afterTime2, State: ptrutil.Ptr(rivertype.JobStateCompleted)})
+		job2 := testfactory.Job(ctx, t, exec, &testfactory.JobOpts{FinalizedAt: &afterTime1, State: ptrutil.Ptr(rivertype.JobStateDiscarded)})
+		job3 := testfactory.Job(ctx, t, exec, &testfactory.JobOpts{FinalizedAt: &afterTime2, State: ptrutil.Ptr(rivertype.JobStateCancelled)})
+
+		// Not returned because finalized before the cursor.
+		_ = testfactory.Job(ctx, t, exec, &testfactory.JobOpts{FinalizedAt: ptrutil.Ptr(cursor.Add(-1 * time.Minute)), State: ptrutil.Ptr(rivertype.JobStateCompleted)})
+
+		// Not returned because not finalized.
+		_ = testfactory.Job(ctx, t, exec, &testfactory.JobOpts{State: ptrutil.Ptr(rivertype.JobStateAvailable)})
+
+		// Ordered by finalized at, then ID.
+		jobs, err := exec.JobGetFinalizedAfter(ctx, &riverdriver.JobGetFinalizedAfterParams{
+			FinalizedAt: cursor,
+			Max:         10,
+		})
+		require.NoError(t, err)
+		require.Equal(t, []int64{job2.ID, job1.ID, job3.ID},
+			sliceutil.Map(jobs, func(j *rivertype.JobRow) int64 { return j.ID }))
+
+		// Paginates using both finalized at and ID.
+		jobs, err = exec.JobGetFinalizedAfter(ctx, &riverdriver.JobGetFinalizedAfterParams{
+			FinalizedAt: *jobs[1].FinalizedAt,
+			ID:          jobs[1].ID,
+			Max:         10,
+		})
+		require.NoError(t, err)
+		require.Equal(t, []int64{job3.ID},
+			sliceutil.Map(jobs, func(j *rivertype.JobRow) int64 { return j.ID }))
+
+		// Respects max.
+		jobs, err = exec.JobGetFinalizedAfter(ctx, &riverdriver.JobGetFinalizedAfterParams{
+			FinalizedAt: cursor,
+			Max:         1,
+		})
+		require.NoError(t, err)
+		require.Equal(t, []int64{job2.ID},
+			sliceutil.Map(jobs, func(j *rivertype.JobRow) int64 { return j.ID }))
+	})
+
 	t.Run("JobGetStuck", func(t *testing.T) {
 		t.Parallel()
 
@@ -784,14 +1934,56 @@ func Exercise[TTx any](ctx context.Context, t *testing.T,
 
 		// Max two stuck
 		stuckJobs, err := exec.JobGetStuck(ctx, &riverdriver.JobGetStuckParams{
-			StuckHorizon: horizon,
 			Max:          2,
+			Now:          horizon,
+			StuckHorizon: horizon,
 		})
 		require.NoError(t, err)
 		require.Equal(t, []int64{stuckJob1.ID, stuckJob2.ID},
 			sliceutil.Map(stuckJobs, func(j *rivertype.JobRow) int64 { return j.ID }))
 	})
 
+	t.Run("JobGetStuckWithLeases", func(t *testing.T) {
+		t.Parallel()
+
+		exec, _ := setup(ctx, t)
+
+		var (
+			now           = time.Now()
+			horizon       = now.Add(-1 * time.Hour)
+			beforeHorizon = horizon.Add(-1 * time.Minute)
+			afterHorizon  = horizon.Add(1 * time.Minute)
+		)
+
+		leaseMetadata := func(attempt int, expiresAt time.Time) []byte {
+			return []byte(fmt.Sprintf(`{"river:lease": {"attempt": %d, "expires_at": %q}}`, attempt, expiresAt.UTC().Format(time.RFC3339Nano)))
+		}
+
+		// Stuck because its lease expired, even though it's after the horizon.
+		expiredLeaseJob := testfactory.Job(ctx, t, exec, &testfactory.JobOpts{Attempt: ptrutil.Ptr(1), AttemptedAt: &afterHorizon, Metadata: leaseMetadata(1, now.Add(-1*time.Minute)), State: ptrutil.Ptr(rivertype.JobStateRunning)})
+
+		// Stuck because its lease is for a previous attempt and it's before
+		// the horizon.
+		previousAttemptLeaseJob := testfactory.Job(ctx, t, exec, &testfactory.JobOpts{Attempt: ptrutil.Ptr(2), AttemptedAt: &beforeHorizon, Metadata: leaseMetadata(1, now.Add(1*time.Minute)), State: ptrutil.Ptr(rivertype.JobStateRunning)})
+
+		// Not stuck because its lease is still valid, even though it's before
+		// the horizon.
+		_ = testfactory.Job(ctx, t, exec, &testfactory.JobOpts{Attempt: ptrutil.Ptr(1), AttemptedAt: &beforeHorizon, Metadata: leaseMetadata(1, now.Add(1*time.Minute)), State: ptrutil.Ptr(rivertype.JobStateRunning)})
+
+		// Not stuck because its lease is for a previous attempt and it's after
+		// the horizon.
+		_ = testfactory.Job(ctx, t, exec, &testfactory.JobOpts{Attempt: ptrutil.Ptr(2), AttemptedAt: &afterHorizon, Metadata: leaseMetadata(1, now.Add(-1*time.Minute)), State: ptrutil.Ptr(rivertype.JobStateRunning)})
+
+		stuckJobs, err := exec.JobGetStuck(ctx, &riverdriver.JobGetStuckParams{
+			Max:          100,
+			Now:          now,
+			StuckHorizon: horizon,
+		})
+		require.NoError(t, err)
+		require.Equal(t, []int64{expiredLeaseJob.ID, previousAttemptLeaseJob.ID},
+			sliceutil.Map(stuckJobs, func(j *rivertype.JobRow) int64 { return j.ID }))
+	})
+
 	t.Run("JobInsertFastMany", func(t *testing.T) {
 		t.Parallel()
 
@@ -1218,6 +2410,60 @@ func Exercise[TTx any](ctx context.Context, t *testing.T,
 		})
 	})
 
+	t.Run("JobLeaseRenewMany", func(t *testing.T) {
+		t.Parallel()
+
+		exec, _ := setup(ctx, t)
+
+		expiresAt := time.Now().Add(1 * time.Minute).UTC()
+
+		runningJob1 := testfactory.Job(ctx, t, exec, &testfactory.JobOpts{Attempt: ptrutil.Ptr(2), Metadata: []byte(`{"foo": "bar"}`), State: ptrutil.Ptr(rivertype.JobStateRunning)})
+		runningJob2 := testfactory.Job(ctx, t, exec, &testfactory.JobOpts{Attempt: ptrutil.Ptr(1), State: ptrutil.Ptr(rivertype.JobStateRunning)})
+		completedJob := testfactory.Job(ctx, t, exec, &testfactory.JobOpts{State: ptrutil.Ptr(rivertype.JobStateCompleted)})
+
+		// Not included in IDs.
+		otherRunningJob := testfactory.Job(ctx, t, exec, &testfactory.JobOpts{State: ptrutil.Ptr(rivertype.JobStateRunning)})
+
+		numRenewed, err := exec.JobLeaseRenewMany(ctx, &riverdriver.JobLeaseRenewManyParams{
+			ExpiresAt: expiresAt,
+			ID:        []int64{runningJob1.ID, runningJob2.ID, completedJob.ID},
+		})
+		require.NoError(t, err)
+		require.Equal(t, 2, numRenewed)
+
+		type leaseMetadata struct {
+			Foo   string `json:"foo"`
+			Lease *struct {
+				Attempt   int       `json:"attempt"`
+				ExpiresAt time.Time `json:"expires_at"`
+			} `json:"river:lease"`
+		}
+
+		fetchLeaseMetadata := func(job *rivertype.JobRow) *leaseMetadata {
+			t.Helper()
+
+			fetchedJob, err := exec.JobGetByID(ctx, &riverdriver.JobGetByIDParams{ID: job.ID, Schema: ""})
+			require.NoError(t, err)
+
+			var metadata leaseMetadata
+			require.NoError(t, json.Unmarshal(fetchedJob.Metadata, &metadata))
+			return &metadata
+		}
+
+		runningJob1Metadata := fetchLeaseMetadata(runningJob1)
+		require.Equal(t, "bar", runningJob1Metadata.Foo)
+		require.NotNil(t, runningJob1Metadata.Lease)
+		require.Equal(t, 2, runningJob1Metadata.Lease.Attempt)
+		requireEqualTime(t, expiresAt, runningJob1Metadata.Lease.ExpiresAt)
+
+		runningJob2Metadata := fetchLeaseMetadata(runningJob2)
+		require.NotNil(t, runningJob2Metadata.Lease)
+		require.Equal(t, 1, runningJob2Metadata.Lease.Attempt)
+
+		require.Nil(t, fetchLeaseMetadata(completedJob).Lease)
+		require.Nil(t, fetchLeaseMetadata(otherRunningJob).Lease)
+	})
+
 	t.Run("JobList", func(t *testing.T) {
 		t.Parallel()
 
@@ -1300,6 +2546,63 @@ func Exercise[TTx any](ctx context.Context, t *testing.T,
 				require.NoError(t, err)
 				require.Len(t, fetchedJobs, 2)
 			}
+
+			{
+				job3 := testfactory.Job(ctx, t, exec, &testfactory.JobOpts{CreatedAt: ptrutil.Ptr(time.Now().Add(time.Hour))})
+
+				fetchedJobs, err := exec.JobList(ctx, &riverdriver.JobListParams{
+					Max:           100,
+					NamedArgs:     map[string]any{"created_at": job3.CreatedAt},
+					OrderByClause: "id",
+					WhereClause:   "created_at >= @created_at::timestamptz",
+				})
+				require.NoError(t, err)
+				require.Len(t, fetchedJobs, 1)
+			}
+		})
+	})
+
+	t.Run("JobMetadataMergeIfRunning", func(t *testing.T) {
+		t.Parallel()
+
+		t.Run("MergesIntoRunningJob", func(t *testing.T) {
+			t.Parallel()
+
+			exec, _ := setup(ctx, t)
+
+			job := testfactory.Job(ctx, t, exec, &testfactory.JobOpts{
+				Metadata: []byte(`{"foo": "bar", "progress": {"percent": 10}}`),
+				State:    ptrutil.Ptr(rivertype.JobStateRunning),
+			})
+
+			updatedJob, err := exec.JobMetadataMergeIfRunning(ctx, &riverdriver.JobMetadataMergeIfRunningParams{
+				ID:              job.ID,
+				MetadataUpdates: []byte(`{"progress": {"percent": 50}}`),
+			})
+			require.NoError(t, err)
+			require.JSONEq(t, `{"foo": "bar", "progress": {"percent": 50}}`, string(updatedJob.Metadata))
+			require.Equal(t, rivertype.JobStateRunning, updatedJob.State)
+		})
+
+		t.Run("DoesNotUpdateNonRunningJob", func(t *testing.T) {
+			t.Parallel()
+
+			exec, _ := setup(ctx, t)
+
+			job := testfactory.Job(ctx, t, exec, &testfactory.JobOpts{
+				Metadata: []byte(`{"foo": "bar"}`),
+				State:    ptrutil.Ptr(rivertype.JobStateCompleted),
+			})
+
+			_, err := exec.JobMetadataMergeIfRunning(ctx, &riverdriver.JobMetadataMergeIfRunningParams{
+				ID:              job.ID,
+				MetadataUpdates: []byte(`{"progress": {"percent": 50}}`),
+			})
+			require.ErrorIs(t, err, rivertype.ErrNotFound)
+
+			fetchedJob, err := exec.JobGetByID(ctx, &riverdriver.JobGetByIDParams{ID: job.ID, Schema: ""})
+			require.NoError(t, err)
+			require.JSONEq(t, `{"foo": "bar"}`, string(fetchedJob.Metadata))
 		})
 	})
 
@@ -1492,6 +2795,72 @@ func Exercise[TTx any](ctx context.Context, t *testing.T,
 		})
 	})
 
+	t.Run("JobRetryMany", func(t *testing.T) {
+		t.Parallel()
+
+		t.Run("RetriesJobsExceptRunningAndAlreadyAvailable", func(t *testing.T) {
+			t.Parallel()
+
+			exec, _ := setup(ctx, t)
+
+			now := time.Now().UTC()
+
+			job1 := testfactory.Job(ctx, t, exec, &testfactory.JobOpts{
+				Attempt:     ptrutil.Ptr(25),
+				FinalizedAt: ptrutil.Ptr(now),
+				MaxAttempts: ptrutil.Ptr(25),
+				State:       ptrutil.Ptr(rivertype.JobStateDiscarded),
+			})
+			job2 := testfactory.Job(ctx, t, exec, &testfactory.JobOpts{
+				ScheduledAt: ptrutil.Ptr(now.Add(time.Hour)),
+				State:       ptrutil.Ptr(rivertype.JobStateScheduled),
+			})
+			job3 := testfactory.Job(ctx, t, exec, &testfactory.JobOpts{State: ptrutil.Ptr(rivertype.JobStateRunning)})
+			job4 := testfactory.Job(ctx, t, exec, &testfactory.JobOpts{
+				ScheduledAt: ptrutil.Ptr(now.Add(-time.Hour)),
+				State:       ptrutil.Ptr(rivertype.JobStateAvailable),
+			})
+
+			jobs, err := exec.JobRetryMany(ctx, &riverdriver.JobRetryManyParams{
+				ID: []int64{job1.ID, job2.ID, job3.ID, job4.ID},
+			})
+			require.NoError(t, err)
+			require.Len(t, jobs, 2)
+
+			jobsByID := make(map[int64]*rivertype.JobRow, len(jobs))
+			for _, job := range jobs {
+				jobsByID[job.ID] = job
+				require.Equal(t, rivertype.JobStateAvailable, job.State)
+				require.Nil(t, job.FinalizedAt)
+				require.WithinDuration(t, time.Now(), job.ScheduledAt, 5*time.Second)
+			}
+
+			// Max attempts incremented because the job had exhausted them.
+			require.Equal(t, 26, jobsByID[job1.ID].MaxAttempts)
+			require.Equal(t, job2.MaxAttempts, jobsByID[job2.ID].MaxAttempts)
+
+			job3Updated, err := exec.JobGetByID(ctx, &riverdriver.JobGetByIDParams{ID: job3.ID})
+			require.NoError(t, err)
+			require.Equal(t, rivertype.JobStateRunning, job3Updated.State)
+
+			job4Updated, err := exec.JobGetByID(ctx, &riverdriver.JobGetByIDParams{ID: job4.ID})
+			require.NoError(t, err)
+			require.WithinDuration(t, job4.ScheduledAt, job4Updated.ScheduledAt, time.Microsecond)
+		})
+
+		t.Run("AlternateSchema", func(t *testing.T) {
+			t.Parallel()
+
+			exec, _ := setup(ctx, t)
+
+			_, err := exec.JobRetryMany(ctx, &riverdriver.JobRetryManyParams{
+				ID:     []int64{1234567890},
+				Schema: "custom_schema",
+			})
+			requireMissingRelation(t, err, "custom_schema.river_job")
+		})
+	})
+
 	t.Run("JobSchedule", func(t *testing.T) {
 		t.Parallel()
 
@@ -1727,6 +3096,139 @@ func Exercise[TTx any](ctx context.Context, t *testing.T,
 		return batchParams
 	}
 
+	t.Run("JobSequencePromote", func(t *testing.T) {
+		t.Parallel()
+
+		t.Run("PromotesSequenceHeads", func(t *testing.T) {
+			t.Parallel()
+
+			exec, _ := setup(ctx, t)
+
+			now := time.Now().UTC()
+
+			sequenceMetadata := func(sequenceKey string) []byte {
+				return []byte(fmt.Sprintf(`{"river:sequence_key": %q}`, sequenceKey))
+			}
+
+			testfactory.Job(ctx, t, exec, &testfactory.JobOpts{Metadata: sequenceMetadata("completed"), State: ptrutil.Ptr(rivertype.JobStateCompleted)})
+			testfactory.Job(ctx, t, exec, &testfactory.JobOpts{Metadata: sequenceMetadata("discarded"), State: ptrutil.Ptr(rivertype.JobStateDiscarded)})
+			testfactory.Job(ctx, t, exec, &testfactory.JobOpts{Metadata: sequenceMetadata("discarded_continue"), State: ptrutil.Ptr(rivertype.JobStateDiscarded)})
+			testfactory.Job(ctx, t, exec, &testfactory.JobOpts{Metadata: sequenceMetadata("retryable"), State: ptrutil.Ptr(rivertype.JobStateRetryable)})
+
+			afterCompleted := testfactory.Job(ctx, t, exec, &testfactory.JobOpts{Metadata: sequenceMetadata("completed"), State: ptrutil.Ptr(rivertype.JobStatePending)})
+			afterDiscarded := testfactory.Job(ctx, t, exec, &testfactory.JobOpts{Metadata: sequenceMetadata("discarded"), State: ptrutil.Ptr(rivertype.JobStatePending)})
+			afterDiscardedContinue := testfactory.Job(ctx, t, exec, &testfactory.JobOpts{Metadata: []byte(`{"river:sequence_key": "discarded_continue", "river:sequence_continue_on_discarded": true}`), State: ptrutil.Ptr(rivertype.JobStatePending)})
+			afterRetryable := testfactory.Job(ctx, t, exec, &testfactory.JobOpts{Metadata: sequenceMetadata("retryable"), State: ptrutil.Ptr(rivertype.JobStatePending)})
+			newHead := testfactory.Job(ctx, t, exec, &testfactory.JobOpts{Metadata: sequenceMetadata("new"), State: ptrutil.Ptr(rivertype.JobStatePending)})
+			newSecond := testfactory.Job(ctx, t, exec, &testfactory.JobOpts{Metadata: sequenceMetadata("new"), State: ptrutil.Ptr(rivertype.JobStatePending)})
+			scheduledHead := testfactory.Job(ctx, t, exec, &testfactory.JobOpts{Metadata: sequenceMetadata("scheduled"), ScheduledAt: ptrutil.Ptr(now.Add(time.Hour)), State: ptrutil.Ptr(rivertype.JobStatePending)})
+
+			// Pending job that's not sequenced, so not examined at all.
+			testfactory.Job(ctx, t, exec, &testfactory.JobOpts{State: ptrutil.Ptr(rivertype.JobStatePending)})
+
+			jobs, err := exec.JobSequencePromote(ctx, &riverdriver.JobSequencePromoteParams{
+				Max: 100,
+				Now: now,
+			})
+			require.NoError(t, err)
+
+			jobStates := make(map[int64]rivertype.JobState, len(jobs))
+			for _, job := range jobs {
+				jobStates[job.ID] = job.State
+			}
+			require.Equal(t, map[int64]rivertype.JobState{
+				afterCompleted.ID:         rivertype.JobStateAvailable,
+				afterDiscarded.ID:         rivertype.JobStatePending,
+				afterDiscardedContinue.ID: rivertype.JobStateAvailable,
+				afterRetryable.ID:         rivertype.JobStatePending,
+				newHead.ID:                rivertype.JobStateAvailable,
+				scheduledHead.ID:          rivertype.JobStateScheduled,
+			}, jobStates)
+
+			// Not examined because it's behind another pending job in its
+			// sequence.
+			newSecond, err = exec.JobGetByID(ctx, &riverdriver.JobGetByIDParams{ID: newSecond.ID})
+			require.NoError(t, err)
+			require.Equal(t, rivertype.JobStatePending, newSecond.State)
+		})
+
+		t.Run("ExaminesOnlyFirstPendingJobOfSequence", func(t *testing.T) {
+			t.Parallel()
+
+			exec, _ := setup(ctx, t)
+
+			sequenceMetadata := []byte(`{"river:sequence_key": "seq"}`)
+
+			testfactory.Job(ctx, t, exec, &testfactory.JobOpts{Metadata: sequenceMetadata, State: ptrutil.Ptr(rivertype.JobStateRunning)})
+			firstPendingJob := testfactory.Job(ctx, t, exec, &testfactory.JobOpts{Metadata: sequenceMetadata, State: ptrutil.Ptr(rivertype.JobStatePending)})
+			for range 3 {
+				testfactory.Job(ctx, t, exec, &testfactory.JobOpts{Metadata: sequenceMetadata, State: ptrutil.Ptr(rivertype.JobStatePending)})
+			}
+
+			promotedJobs, err := exec.JobSequencePromote(ctx, &riverdriver.JobSequencePromoteParams{
+				Max: 100,
+				Now: time.Now().UTC(),
+			})
+			require.NoError(t, err)
+			require.Len(t, promotedJobs, 1)
+			require.Equal(t, firstPendingJob.ID, promotedJobs[0].ID)
+			require.Equal(t, rivertype.JobStatePending, promotedJobs[0].State)
+		})
+
+		t.Run("RespectsAfterSequenceKeyAndMax", func(t *testing.T) {
+			t.Parallel()
+
+			exec, _ := setup(ctx, t)
+
+			jobs := make([]*rivertype.JobRow, 3)
+			for i := range jobs {
+				jobs[i] = testfactory.Job(ctx, t, exec, &testfactory.JobOpts{
+					Metadata: []byte(fmt.Sprintf(`{"river:sequence_key": "seq_%d"}`, i)),
+					State:    ptrutil.Ptr(rivertype.JobStatePending),
+				})
+			}
+
+			promotedJobs, err := exec.JobSequencePromote(ctx, &riverdriver.JobSequencePromoteParams{
+				AfterSequenceKey: ptrutil.Ptr("seq_0"),
+				Max:              1,
+				Now:              time.Now().UTC(),
+			})
+			require.NoError(t, err)
+			require.Len(t, promotedJobs, 1)
+			require.Equal(t, jobs[1].ID, promotedJobs[0].ID)
+			require.Equal(t, rivertype.JobStateAvailable, promotedJobs[0].State)
+		})
+
+		t.Run("ConsidersOnlyMostRecentPreviousJob", func(t *testing.T) {
+			t.Parallel()
+
+			exec, _ := setup(ctx, t)
+
+			sequenceMetadata := []byte(`{"river:sequence_key": "seq"}`)
+
+			// Only the discarded job directly ahead of the pending one should
+			// count, even though an older job in the sequence completed.
+			testfactory.Job(ctx, t, exec, &testfactory.JobOpts{Metadata: sequenceMetadata, State: ptrutil.Ptr(rivertype.JobStateCompleted)})
+			testfactory.Job(ctx, t, exec, &testfactory.JobOpts{Metadata: sequenceMetadata, State: ptrutil.Ptr(rivertype.JobStateDiscarded)})
+			pendingJob := testfactory.Job(ctx, t, exec, &testfactory.JobOpts{Metadata: sequenceMetadata, State: ptrutil.Ptr(rivertype.JobStatePending)})
+
+			// Job in another sequence with a higher ID has no effect.
+			testfactory.Job(ctx, t, exec, &testfactory.JobOpts{Metadata: []byte(`{"river:sequence_key": "other"}`), State: ptrutil.Ptr(rivertype.JobStateRunning)})
+
+			promotedJobs, err := exec.JobSequencePromote(ctx, &riverdriver.JobSequencePromoteParams{
+				Max: 100,
+				Now: time.Now().UTC(),
+			})
+			require.NoError(t, err)
+
+			jobStates := make(map[int64]rivertype.JobState, len(promotedJobs))
+			for _, job := range promotedJobs {
+				jobStates[job.ID] = job.State
+			}
+			require.Equal(t, rivertype.JobStatePending, jobStates[pendingJob.ID])
+		})
+	})
+
 	t.Run("JobSetStateIfRunningMany_JobSetStateCompleted", func(t *testing.T) {
 		t.Parallel()
 
@@ -2095,6 +3597,145 @@ func Exercise[TTx any](ctx context.Context, t *testing.T,
 		require.WithinDuration(t, now, *cancelledJob.FinalizedAt, time.Microsecond)
 	})
 
+	t.Run("JobStats", func(t *testing.T) {
+		t.Parallel()
+
+		t.Run("ReturnsStats", func(t *testing.T) {
+			t.Parallel()
+
+			exec, _ := setup(ctx, t)
+
+			var (
+				now       = time.Now().UTC()
+				queue     = ptrutil.Ptr("queue1")
+				available = ptrutil.Ptr(rivertype.JobStateAvailable)
+				completed = ptrutil.Ptr(rivertype.JobStateCompleted)
+				discarded = ptrutil.Ptr(rivertype.JobStateDiscarded)
+				running   = ptrutil.Ptr(rivertype.JobStateRunning)
+			)
+
+			_ = testfactory.Job(ctx, t, exec, &testfactory.JobOpts{Kind: ptrutil.Ptr("kind1"), Queue: queue, ScheduledAt: ptrutil.Ptr(now.Add(-5 * time.Minute)), State: available})
+			_ = testfactory.Job(ctx, t, exec, &testfactory.JobOpts{Kind: ptrutil.Ptr("kind2"), Queue: queue, ScheduledAt: ptrutil.Ptr(now.Add(-10 * time.Minute)), State: available})
+			_ = testfactory.Job(ctx, t, exec, &testfactory.JobOpts{AttemptedBy: []string{"client1"}, Kind: ptrutil.Ptr("kind1"), Queue: queue, State: running})
+			_ = testfactory.Job(ctx, t, exec, &testfactory.JobOpts{AttemptedBy: []string{"client1", "client2"}, Kind: ptrutil.Ptr("kind1"), Queue: queue, State: running})
+			_ = testfactory.Job(ctx, t, exec, &testfactory.JobOpts{AttemptedBy: []string{"client2"}, Kind: ptrutil.Ptr("kind2"), Queue: queue, State: running})
+			_ = testfactory.Job(ctx, t, exec, &testfactory.JobOpts{FinalizedAt: ptrutil.Ptr(now.Add(-time.Minute)), Kind: ptrutil.Ptr("kind1"), Queue: queue, State: completed})
+
+			_ = testfactory.Job(ctx, t, exec, &testfactory.JobOpts{FinalizedAt: ptrutil.Ptr(now.Add(-time.Minute)), Kind: ptrutil.Ptr("kind2"), Queue: queue, State: discarded})
+
+			// Excluded because they were finalized before the window.
+			_ = testfactory.Job(ctx, t, exec, &testfactory.JobOpts{FinalizedAt: ptrutil.Ptr(now.Add(-time.Hour)), Kind: ptrutil.Ptr("kind1"), Queue: queue, State: completed})
+			_ = testfactory.Job(ctx, t, exec, &testfactory.JobOpts{FinalizedAt: ptrutil.Ptr(now.Add(-time.Hour)), Kind: ptrutil.Ptr("kind1"), Queue: queue, State: discarded})
+
+			// Excluded because it's in another queue.
+			_ = testfactory.Job(ctx, t, exec, &testfactory.JobOpts{Kind: ptrutil.Ptr("kind1"), Queue: ptrutil.Ptr("queue2"), ScheduledAt: ptrutil.Ptr(now.Add(-time.Hour)), State: available})
+
+			results, err := exec.JobStats(ctx, &riverdriver.JobStatsParams{
+				FinalizedSince: now.Add(-5 * time.Minute),
+				Queues:         []string{"queue1"},
+			})
+			require.NoError(t, err)
+			require.Len(t, results, 1)
+			stats := results[0]
+			require.Equal(t, "queue1", stats.Queue)
+			require.Equal(t, map[rivertype.JobState]int{
+				rivertype.JobStateAvailable: 2,
+				rivertype.JobStateRunning:   3,
+			}, stats.CountsByState)
+			require.Equal(t, map[rivertype.JobState]int{
+				rivertype.JobStateCompleted: 1,
+				rivertype.JobStateDiscarded: 1,
+			}, stats.FinalizedCountsByState)
+			require.NotNil(t, stats.OldestAvailableAt)
+			require.WithinDuration(t, now.Add(-10*time.Minute), *stats.OldestAvailableAt, time.Millisecond)
+			require.Equal(t, map[string]int{"client1": 1, "client2": 2}, stats.RunningByClient)
+
+			results, err = exec.JobStats(ctx, &riverdriver.JobStatsParams{
+				FinalizedSince: now.Add(-5 * time.Minute),
+				Kind:           "kind1",
+				Queues:         []string{"queue1"},
+			})
+			require.NoError(t, err)
+			require.Len(t, results, 1)
+			stats = results[0]
+			require.Equal(t, map[rivertype.JobState]int{
+				rivertype.JobStateAvailable: 1,
+				rivertype.JobStateRunning:   2,
+			}, stats.CountsByState)
+			require.Equal(t, map[rivertype.JobState]int{
+				rivertype.JobStateCompleted: 1,
+			}, stats.FinalizedCountsByState)
+			require.WithinDuration(t, now.Add(-5*time.Minute), *stats.OldestAvailableAt, time.Millisecond)
+			require.Equal(t, map[string]int{"client1": 1, "client2": 1}, stats.RunningByClient)
+		})
+
+		t.Run("MultipleQueues", func(t *testing.T) {
+			t.Parallel()
+
+			exec, _ := setup(ctx, t)
+
+			now := time.Now().UTC()
+
+			_ = testfactory.Job(ctx, t, exec, &testfactory.JobOpts{Queue: ptrutil.Ptr("queue1"), State: ptrutil.Ptr(rivertype.JobStateAvailable)})
+			_ = testfactory.Job(ctx, t, exec, &testfactory.JobOpts{AttemptedBy: []string{"client1"}, Queue: ptrutil.Ptr("queue2"), State: ptrutil.Ptr(rivertype.JobStateRunning)})
+			_ = testfactory.Job(ctx, t, exec, &testfactory.JobOpts{FinalizedAt: ptrutil.Ptr(now.Add(-time.Minute)), Queue: ptrutil.Ptr("queue2"), State: ptrutil.Ptr(rivertype.JobStateDiscarded)})
+
+			// Excluded because its queue isn't requested.
+			_ = testfactory.Job(ctx, t, exec, &testfactory.JobOpts{Queue: ptrutil.Ptr("queue4"), State: ptrutil.Ptr(rivertype.JobStateAvailable)})
+
+			results, err := exec.JobStats(ctx, &riverdriver.JobStatsParams{
+				FinalizedSince: now.Add(-5 * time.Minute),
+				Queues:         []string{"queue3", "queue2", "queue1"},
+			})
+			require.NoError(t, err)
+			require.Equal(t, []string{"queue1", "queue2", "queue3"}, sliceutil.Map(results, func(r *riverdriver.JobStatsResult) string { return r.Queue }))
+
+			require.Equal(t, map[rivertype.JobState]int{rivertype.JobStateAvailable: 1}, results[0].CountsByState)
+			require.Empty(t, results[0].FinalizedCountsByState)
+			require.Empty(t, results[0].RunningByClient)
+
+			require.Equal(t, map[rivertype.JobState]int{rivertype.JobStateRunning: 1}, results[1].CountsByState)
+			require.Equal(t, map[rivertype.JobState]int{rivertype.JobStateDiscarded: 1}, results[1].FinalizedCountsByState)
+			require.Nil(t, results[1].OldestAvailableAt)
+			require.Equal(t, map[string]int{"client1": 1}, results[1].RunningByClient)
+
+			require.Empty(t, results[2].CountsByState)
+			require.Empty(t, results[2].FinalizedCountsByState)
+		})
+
+		t.Run("EmptyQueue", func(t *testing.T) {
+			t.Parallel()
+
+			exec, _ := setup(ctx, t)
+
+			results, err := exec.JobStats(ctx, &riverdriver.JobStatsParams{
+				FinalizedSince: time.Now(),
+				Queues:         []string{"queue1"},
+			})
+			require.NoError(t, err)
+			require.Len(t, results, 1)
+			stats := results[0]
+			require.Equal(t, "queue1", stats.Queue)
+			require.Empty(t, stats.CountsByState)
+			require.Empty(t, stats.FinalizedCountsByState)
+			require.Nil(t, stats.OldestAvailableAt)
+			require.Empty(t, stats.RunningByClient)
+		})
+
+		t.Run("AlternateSchema", func(t *testing.T) {
+			t.Parallel()
+
+			exec, _ := setup(ctx, t)
+
+			_, err := exec.JobStats(ctx, &riverdriver.JobStatsParams{
+				FinalizedSince: time.Now(),
+				Queues:         []string{"queue1"},
+				Schema:         "custom_schema",
+			})
+			requireMissingRelation(t, err, "custom_schema.river_job")
+		})
+	})
+
 	t.Run("JobUpdate", func(t *testing.T) {
 		t.Parallel()
 
@@ -2128,6 +3769,278 @@ func Exercise[TTx any](ctx context.Context, t *testing.T,
 		require.Equal(t, rivertype.JobStateDiscarded, updatedJob.State)
 	})
 
+	t.Run("JobUpdateIfQueued", func(t *testing.T) {
+		t.Parallel()
+
+		t.Run("UpdatesAllFields", func(t *testing.T) {
+			t.Parallel()
+
+			exec, _ := setup(ctx, t)
+
+			job := testfactory.Job(ctx, t, exec, &testfactory.JobOpts{
+				Metadata: []byte(`{"foo":"bar"}`),
+				Tags:     []string{"tag1"},
+			})
+
+			scheduledAt := time.Now().Add(time.Hour).UTC()
+
+			updatedJob, err := exec.JobUpdateIfQueued(ctx, &riverdriver.JobUpdateIfQueuedParams{
+				ID:                  job.ID,
+				MaxAttemptsDoUpdate: true,
+				MaxAttempts:         50,
+				MetadataDoMerge:     true,
+				Metadata:            []byte(`{"baz":"qux"}`),
+				PriorityDoUpdate:    true,
+				Priority:            3,
+				QueueDoUpdate:       true,
+				Queue:               "other_queue",
+				ScheduledAtDoUpdate: true,
+				ScheduledAt:         scheduledAt,
+				TagsDoUpdate:        true,
+				Tags:                []string{"tag2", "tag3"},
+			})
+			require.NoError(t, err)
+			require.Equal(t, 50, updatedJob.MaxAttempts)
+			require.JSONEq(t, `{"foo":"bar","baz":"qux"}`, string(updatedJob.Metadata))
+			require.Equal(t, 3, updatedJob.Priority)
+			require.Equal(t, "other_queue", updatedJob.Queue)
+			requireEqualTime(t, scheduledAt, updatedJob.ScheduledAt)
+			require.Equal(t, rivertype.JobStateScheduled, updatedJob.State) // moved into the future
+			require.Equal(t, []string{"tag2", "tag3"}, updatedJob.Tags)
+		})
+
+		t.Run("NoFieldsLeavesJobUnchanged", func(t *testing.T) {
+			t.Parallel()
+
+			exec, _ := setup(ctx, t)
+
+			job := testfactory.Job(ctx, t, exec, &testfactory.JobOpts{
+				Metadata: []byte(`{"foo":"bar"}`),
+				Tags:     []string{"tag1"},
+			})
+
+			updatedJob, err := exec.JobUpdateIfQueued(ctx, &riverdriver.JobUpdateIfQueuedParams{
+				ID: job.ID,
+			})
+			require.NoError(t, err)
+			require.Equal(t, job.MaxAttempts, updatedJob.MaxAttempts)
+			require.JSONEq(t, `{"foo":"bar"}`, string(updatedJob.Metadata))
+			require.Equal(t, job.Priority, updatedJob.Priority)
+			require.Equal(t, job.Queue, updatedJob.Queue)
+			requireEqualTime(t, job.ScheduledAt, updatedJob.ScheduledAt)
+			require.Equal(t, job.State, updatedJob.State)
+			require.Equal(t, []string{"tag1"}, updatedJob.Tags)
+		})
+
+		t.Run("ReplacesMetadata", func(t *testing.T) {
+			t.Parallel()
+
+			exec, _ := setup(ctx, t)
+
+			job := testfactory.Job(ctx, t, exec, &testfactory.JobOpts{Metadata: []byte(`{"foo":"bar"}`)})
+
+			updatedJob, err := exec.JobUpdateIfQueued(ctx, &riverdriver.JobUpdateIfQueuedParams{
+				ID:               job.ID,
+				MetadataDoUpdate: true,
+				Metadata:         []byte(`{"baz":"qux"}`),
+			})
+			require.NoError(t, err)
+			require.JSONEq(t, `{"baz":"qux"}`, string(updatedJob.Metadata))
+		})
+
+		t.Run("ScheduledJobMovedIntoPastBecomesAvailable", func(t *testing.T) {
+			t.Parallel()
+
+			exec, _ := setup(ctx, t)
+
+			job := testfactory.Job(ctx, t, exec, &testfactory.JobOpts{
+				ScheduledAt: ptrutil.Ptr(time.Now().Add(time.Hour)),
+				State:       ptrutil.Ptr(rivertype.JobStateScheduled),
+			})
+
+			updatedJob, err := exec.JobUpdateIfQueued(ctx, &riverdriver.JobUpdateIfQueuedParams{
+				ID:                  job.ID,
+				ScheduledAtDoUpdate: true,
+				ScheduledAt:         time.Now().Add(-time.Minute),
+			})
+			require.NoError(t, err)
+			require.Equal(t, rivertype.JobStateAvailable, updatedJob.State)
+		})
+
+		for _, state := range []rivertype.JobState{
+			rivertype.JobStateCompleted,
+			rivertype.JobStateRunning,
+		} {
+			t.Run(fmt.Sprintf("DoesNotUpdateJobIn%sState", state), func(t *testing.T) {
+				t.Parallel()
+
+				exec, _ := setup(ctx, t)
+
+				var finalizedAt *time.Time
+				if state == rivertype.JobStateCompleted {
+					finalizedAt = ptrutil.Ptr(time.Now())
+				}
+
+				job := testfactory.Job(ctx, t, exec, &testfactory.JobOpts{FinalizedAt: finalizedAt, State: &state})
+
+				updatedJob, err := exec.JobUpdateIfQueued(ctx, &riverdriver.JobUpdateIfQueuedParams{
+					ID:               job.ID,
+					PriorityDoUpdate: true,
+					Priority:         4,
+				})
+				require.NoError(t, err)
+				require.Equal(t, state, updatedJob.State)
+				require.Equal(t, job.Priority, updatedJob.Priority)
+			})
+		}
+
+		t.Run("ReturnsErrNotFoundIfJobDoesNotExist", func(t *testing.T) {
+			t.Parallel()
+
+			exec, _ := setup(ctx, t)
+
+			_, err := exec.JobUpdateIfQueued(ctx, &riverdriver.JobUpdateIfQueuedParams{
+				ID: 1234567890,
+			})
+			require.ErrorIs(t, err, rivertype.ErrNotFound)
+		})
+
+		t.Run("AlternateSchema", func(t *testing.T) {
+			t.Parallel()
+
+			exec, _ := setup(ctx, t)
+
+			_, err := exec.JobUpdateIfQueued(ctx, &riverdriver.JobUpdateIfQueuedParams{
+				ID:     1234567890,
+				Schema: "custom_schema",
+			})
+			requireMissingRelation(t, err, "custom_schema.river_job")
+		})
+	})
+
+	t.Run("JobWorkflowPromote", func(t *testing.T) {
+		t.Parallel()
+
+		t.Run("PromotesCancelsAndSkipsTasks", func(t *testing.T) {
+			t.Parallel()
+
+			exec, _ := setup(ctx, t)
+
+			now := time.Now().UTC()
+
+			testfactory.Job(ctx, t, exec, &testfactory.JobOpts{Metadata: []byte(`{"river:workflow_id": "wf1", "river:workflow_task": "completed"}`), State: ptrutil.Ptr(rivertype.JobStateCompleted)})
+			testfactory.Job(ctx, t, exec, &testfactory.JobOpts{Metadata: []byte(`{"river:workflow_id": "wf1", "river:workflow_task": "cancelled"}`), State: ptrutil.Ptr(rivertype.JobStateCancelled)})
+			testfactory.Job(ctx, t, exec, &testfactory.JobOpts{Metadata: []byte(`{"river:workflow_id": "wf1", "river:workflow_task": "running"}`), State: ptrutil.Ptr(rivertype.JobStateRunning)})
+
+			availableTask := testfactory.Job(ctx, t, exec, &testfactory.JobOpts{
+				Metadata: []byte(`{"river:workflow_id": "wf1", "river:workflow_task": "a", "river:workflow_deps": ["completed"]}`),
+				State:    ptrutil.Ptr(rivertype.JobStatePending),
+			})
+			scheduledTask := testfactory.Job(ctx, t, exec, &testfactory.JobOpts{
+				Metadata:    []byte(`{"river:workflow_id": "wf1", "river:workflow_task": "b", "river:workflow_deps": ["completed"]}`),
+				ScheduledAt: ptrutil.Ptr(now.Add(time.Hour)),
+				State:       ptrutil.Ptr(rivertype.JobStatePending),
+			})
+			cancelledTask := testfactory.Job(ctx, t, exec, &testfactory.JobOpts{
+				Metadata: []byte(`{"river:workflow_id": "wf1", "river:workflow_task": "c", "river:workflow_deps": ["completed", "cancelled"]}`),
+				State:    ptrutil.Ptr(rivertype.JobStatePending),
+			})
+			discardedTask := testfactory.Job(ctx, t, exec, &testfactory.JobOpts{
+				Metadata: []byte(`{"river:workflow_id": "wf1", "river:workflow_task": "d", "river:workflow_deps": ["cancelled"], "river:workflow_dep_failed_action": "discard"}`),
+				State:    ptrutil.Ptr(rivertype.JobStatePending),
+			})
+			waitingTask := testfactory.Job(ctx, t, exec, &testfactory.JobOpts{
+				Metadata: []byte(`{"river:workflow_id": "wf1", "river:workflow_task": "e", "river:workflow_deps": ["completed", "running"]}`),
+				State:    ptrutil.Ptr(rivertype.JobStatePending),
+			})
+
+			// Not part of a workflow, so not examined at all.
+			testfactory.Job(ctx, t, exec, &testfactory.JobOpts{State: ptrutil.Ptr(rivertype.JobStatePending)})
+
+			tasks, err := exec.JobWorkflowPromote(ctx, &riverdriver.JobWorkflowPromoteParams{
+				Max: 100,
+				Now: now,
+			})
+			require.NoError(t, err)
+			require.Len(t, tasks, 5)
+
+			taskStates := make(map[int64]rivertype.JobState, len(tasks))
+			for _, task := range tasks {
+				taskStates[task.ID] = task.State
+			}
+			require.Equal(t, map[int64]rivertype.JobState{
+				availableTask.ID: rivertype.JobStateAvailable,
+				scheduledTask.ID: rivertype.JobStateScheduled,
+				cancelledTask.ID: rivertype.JobStateCancelled,
+				discardedTask.ID: rivertype.JobStateDiscarded,
+				waitingTask.ID:   rivertype.JobStatePending,
+			}, taskStates)
+
+			updatedCancelledTask, err := exec.JobGetByID(ctx, &riverdriver.JobGetByIDParams{ID: cancelledTask.ID})
+			require.NoError(t, err)
+			require.NotNil(t, updatedCancelledTask.FinalizedAt)
+			requireEqualTime(t, now, *updatedCancelledTask.FinalizedAt)
+		})
+
+		t.Run("PromotesTasksWithDepJobIDs", func(t *testing.T) {
+			t.Parallel()
+
+			exec, _ := setup(ctx, t)
+
+			depJob := testfactory.Job(ctx, t, exec, &testfactory.JobOpts{State: ptrutil.Ptr(rivertype.JobStateCompleted)})
+			task := testfactory.Job(ctx, t, exec, &testfactory.JobOpts{
+				Metadata: []byte(fmt.Sprintf(`{"river:workflow_id": "wf1", "river:workflow_task": "a", "river:workflow_dep_job_ids": [%d]}`, depJob.ID)),
+				State:    ptrutil.Ptr(rivertype.JobStatePending),
+			})
+
+			tasks, err := exec.JobWorkflowPromote(ctx, &riverdriver.JobWorkflowPromoteParams{
+				Max: 100,
+				Now: time.Now().UTC(),
+			})
+			require.NoError(t, err)
+			require.Len(t, tasks, 1)
+			require.Equal(t, task.ID, tasks[0].ID)
+			require.Equal(t, rivertype.JobStateAvailable, tasks[0].State)
+		})
+
+		t.Run("RespectsAfterIDAndMax", func(t *testing.T) {
+			t.Parallel()
+
+			exec, _ := setup(ctx, t)
+
+			tasks := make([]*rivertype.JobRow, 3)
+			for i := range tasks {
+				tasks[i] = testfactory.Job(ctx, t, exec, &testfactory.JobOpts{
+					Metadata: []byte(fmt.Sprintf(`{"river:workflow_id": "wf1", "river:workflow_task": "task_%d"}`, i)),
+					State:    ptrutil.Ptr(rivertype.JobStatePending),
+				})
+			}
+
+			promotedTasks, err := exec.JobWorkflowPromote(ctx, &riverdriver.JobWorkflowPromoteParams{
+				AfterID: tasks[0].ID,
+				Max:     1,
+				Now:     time.Now().UTC(),
+			})
+			require.NoError(t, err)
+			require.Len(t, promotedTasks, 1)
+			require.Equal(t, tasks[1].ID, promotedTasks[0].ID)
+			require.Equal(t, rivertype.JobStateAvailable, promotedTasks[0].State)
+		})
+
+		t.Run("AlternateSchema", func(t *testing.T) {
+			t.Parallel()
+
+			exec, _ := setup(ctx, t)
+
+			_, err := exec.JobWorkflowPromote(ctx, &riverdriver.JobWorkflowPromoteParams{
+				Max:    100,
+				Now:    time.Now().UTC(),
+				Schema: "custom_schema",
+			})
+			requireMissingRelation(t, err, "custom_schema.river_job")
+		})
+	})
+
 	const leaderTTL = 10 * time.Second
 
 	t.Run("LeaderDeleteExpired", func(t *testing.T) {
@@ -2523,6 +4436,127 @@ func Exercise[TTx any](ctx context.Context, t *testing.T,
 		require.Equal(t, 2, migrations[1].Version)
 	})
 
+	t.Run("RateLimitRefill", func(t *testing.T) {
+		t.Parallel()
+
+		t.Run("CreatesFullBucket", func(t *testing.T) {
+			t.Parallel()
+
+			exec, _ := setup(ctx, t)
+
+			tokens, err := exec.RateLimitRefill(ctx, &riverdriver.RateLimitRefillParams{
+				Burst:         10,
+				Key:           "queue:default",
+				RatePerSecond: 1,
+			})
+			require.NoError(t, err)
+			require.InDelta(t, 10.0, tokens, 0.001)
+		})
+
+		t.Run("RefillsUpToBurst", func(t *testing.T) {
+			t.Parallel()
+
+			exec, _ := setup(ctx, t)
+
+			_, err := exec.RateLimitRefill(ctx, &riverdriver.RateLimitRefillParams{
+				Burst:         10,
+				Key:           "queue:default",
+				RatePerSecond: 1,
+			})
+			require.NoError(t, err)
+
+			require.NoError(t, exec.RateLimitTake(ctx, &riverdriver.RateLimitTakeParams{
+				Key:    "queue:default",
+				Tokens: 8,
+			}))
+
+			// Within a test transaction now() doesn't advance, so no tokens
+			// have been refilled.
+			tokens, err := exec.RateLimitRefill(ctx, &riverdriver.RateLimitRefillParams{
+				Burst:         10,
+				Key:           "queue:default",
+				RatePerSecond: 1,
+			})
+			require.NoError(t, err)
+			require.InDelta(t, 2.0, tokens, 0.001)
+
+			// A lowered burst caps the bucket immediately.
+			tokens, err = exec.RateLimitRefill(ctx, &riverdriver.RateLimitRefillParams{
+				Burst:         1,
+				Key:           "queue:default",
+				RatePerSecond: 1,
+			})
+			require.NoError(t, err)
+			require.InDelta(t, 1.0, tokens, 0.001)
+		})
+
+		t.Run("AlternateSchema", func(t *testing.T) {
+			t.Parallel()
+
+			exec, _ := setup(ctx, t)
+
+			_, err := exec.RateLimitRefill(ctx, &riverdriver.RateLimitRefillParams{
+				Burst:         10,
+				Key:           "queue:default",
+				RatePerSecond: 1,
+				Schema:        "custom_schema",
+			})
+			requireMissingRelation(t, err, "custom_schema.river_rate_limit")
+		})
+	})
+
+	t.Run("RateLimitTake", func(t *testing.T) {
+		t.Parallel()
+
+		t.Run("TakesTokens", func(t *testing.T) {
+			t.Parallel()
+
+			exec, _ := setup(ctx, t)
+
+			_, err := exec.RateLimitRefill(ctx, &riverdriver.RateLimitRefillParams{
+				Burst:         10,
+				Key:           "kind:kind1",
+				RatePerSecond: 1,
+			})
+			require.NoError(t, err)
+
+			require.NoError(t, exec.RateLimitTake(ctx, &riverdriver.RateLimitTakeParams{
+				Key:    "kind:kind1",
+				Tokens: 3,
+			}))
+
+			// Other buckets are unaffected.
+			tokens, err := exec.RateLimitRefill(ctx, &riverdriver.RateLimitRefillParams{
+				Burst:         10,
+				Key:           "kind:kind2",
+				RatePerSecond: 1,
+			})
+			require.NoError(t, err)
+			require.InDelta(t, 10.0, tokens, 0.001)
+
+			tokens, err = exec.RateLimitRefill(ctx, &riverdriver.RateLimitRefillParams{
+				Burst:         10,
+				Key:           "kind:kind1",
+				RatePerSecond: 1,
+			})
+			require.NoError(t, err)
+			require.InDelta(t, 7.0, tokens, 0.001)
+		})
+
+		t.Run("AlternateSchema", func(t *testing.T) {
+			t.Parallel()
+
+			exec, _ := setup(ctx, t)
+
+			err := exec.RateLimitTake(ctx, &riverdriver.RateLimitTakeParams{
+				Key:    "kind:kind1",
+				Schema: "custom_schema",
+				Tokens: 3,
+			})
+			requireMissingRelation(t, err, "custom_schema.river_rate_limit")
+		})
+	})
+
 	t.Run("TableExists", func(t *testing.T) {
 		t.Parallel()
 
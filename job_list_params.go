@@ -10,6 +10,7 @@ import (
 
 	"github.com/riverqueue/river/internal/dblist"
 	"github.com/riverqueue/river/rivershared/util/ptrutil"
+	"github.com/riverqueue/river/rivershared/util/sliceutil"
 	"github.com/riverqueue/river/rivertype"
 )
 
@@ -65,10 +66,15 @@ func jobListCursorFromJobAndParams(job *rivertype.JobRow, listParams *JobListPar
 // UnmarshalText implements encoding.TextUnmarshaler to decode the cursor from
 // a previously marshaled string.
 func (c *JobListCursor) UnmarshalText(text []byte) error {
-	dst := make([]byte, base64.StdEncoding.DecodedLen(len(text)))
-	n, err := base64.StdEncoding.Decode(dst, text)
+	// Cursors are marshaled with URL encoding, but fall back to standard
+	// encoding, which was used to decode them previously.
+	dst := make([]byte, base64.URLEncoding.DecodedLen(len(text)))
+	n, err := base64.URLEncoding.Decode(dst, text)
 	if err != nil {
-		return err
+		n, err = base64.StdEncoding.Decode(dst, text)
+		if err != nil {
+			return err
+		}
 	}
 	dst = dst[:n]
 
@@ -166,14 +172,32 @@ const (
 //	params := NewJobListParams().OrderBy(JobListOrderByTime, SortOrderAsc).First(100)
 type JobListParams struct {
 	after            *JobListCursor
+	argsFragment     string
+	argsPaths        []jobListArgsPath
+	attemptMax       *int
+	attemptMin       *int
+	before           *JobListCursor
+	createdAt        dblist.TimeRange
+	finalizedAt      dblist.TimeRange
+	idMax            *int64
+	ids              []int64
 	kinds            []string
 	metadataFragment string
 	overrodeState    bool
 	paginationCount  int32
+	priorities       []int
 	queues           []string
+	scheduledAt      dblist.TimeRange
 	sortField        JobListOrderByField
 	sortOrder        SortOrder
 	states           []rivertype.JobState
+	tagsAll          []string
+	tagsAny          []string
+}
+
+type jobListArgsPath struct {
+	path  []string
+	value any
 }
 
 // NewJobListParams creates a new JobListParams to return available jobs sorted
@@ -199,14 +223,27 @@ func NewJobListParams() *JobListParams {
 func (p *JobListParams) copy() *JobListParams {
 	return &JobListParams{
 		after:            p.after,
+		argsFragment:     p.argsFragment,
+		argsPaths:        append([]jobListArgsPath(nil), p.argsPaths...),
+		attemptMax:       p.attemptMax,
+		attemptMin:       p.attemptMin,
+		before:           p.before,
+		createdAt:        p.createdAt,
+		finalizedAt:      p.finalizedAt,
+		idMax:            p.idMax,
+		ids:              append([]int64(nil), p.ids...),
 		kinds:            append([]string(nil), p.kinds...),
 		metadataFragment: p.metadataFragment,
 		overrodeState:    p.overrodeState,
 		paginationCount:  p.paginationCount,
+		priorities:       append([]int(nil), p.priorities...),
 		queues:           append([]string(nil), p.queues...),
+		scheduledAt:      p.scheduledAt,
 		sortField:        p.sortField,
 		sortOrder:        p.sortOrder,
 		states:           append([]rivertype.JobState(nil), p.states...),
+		tagsAll:          append([]string(nil), p.tagsAll...),
+		tagsAny:          append([]string(nil), p.tagsAny...),
 	}
 }
 
@@ -265,23 +302,41 @@ func (p *JobListParams) toDBParams() (*dblist.JobListParams, error) {
 	}
 
 	if p.after != nil {
+		operator := ">"
+		if sortOrder == dblist.SortOrderDesc {
+			operator = "<"
+		}
+
 		if p.after.time.IsZero() { // order by ID only
-			if sortOrder == dblist.SortOrderAsc {
-				conditions = append(conditions, "(id > @after_id)")
-			} else {
-				conditions = append(conditions, "(id < @after_id)")
-			}
+			conditions = append(conditions, "(id "+operator+" @after_id)")
 		} else {
-			if sortOrder == dblist.SortOrderAsc {
-				conditions = append(conditions, fmt.Sprintf(`("%s" > @cursor_time OR ("%s" = @cursor_time AND "id" > @after_id))`, timeField, timeField))
-			} else {
-				conditions = append(conditions, fmt.Sprintf(`("%s" < @cursor_time OR ("%s" = @cursor_time AND "id" < @after_id))`, timeField, timeField))
-			}
+			conditions = append(conditions, fmt.Sprintf(`("%s" %s @cursor_time OR ("%s" = @cursor_time AND "id" %s @after_id))`, timeField, operator, timeField, operator))
 			namedArgs["cursor_time"] = p.after.time
 		}
 		namedArgs["after_id"] = p.after.id
 	}
 
+	if p.before != nil {
+		operator := "<"
+		if sortOrder == dblist.SortOrderDesc {
+			operator = ">"
+		}
+
+		if p.before.time.IsZero() { // order by ID only
+			conditions = append(conditions, "(id "+operator+" @before_id)")
+		} else {
+			conditions = append(conditions, fmt.Sprintf(`("%s" %s @before_time OR ("%s" = @before_time AND "id" %s @before_id))`, timeField, operator, timeField, operator))
+			namedArgs["before_time"] = p.before.time
+		}
+		namedArgs["before_id"] = p.before.id
+
+		// Jobs immediately before the cursor are selected by reversing the
+		// sort, then put back in the requested order after they're fetched.
+		for i := range orderBy {
+			orderBy[i].Order = reverseSortOrder(orderBy[i].Order)
+		}
+	}
+
 	for i, condition := range conditions {
 		if i > 0 {
 			conditionsBuilder.WriteString("\n  AND ")
@@ -289,20 +344,56 @@ func (p *JobListParams) toDBParams() (*dblist.JobListParams, error) {
 		conditionsBuilder.WriteString(condition)
 	}
 
+	argsPaths := make([]dblist.JobListArgsPath, len(p.argsPaths))
+	for i, argsPath := range p.argsPaths {
+		value, err := json.Marshal(argsPath.value)
+		if err != nil {
+			return nil, fmt.Errorf("error marshaling args path value: %w", err)
+		}
+		argsPaths[i] = dblist.JobListArgsPath{Path: argsPath.path, Value: string(value)}
+	}
+
 	dbParams := &dblist.JobListParams{
-		Conditions: conditionsBuilder.String(),
-		Kinds:      p.kinds,
-		LimitCount: p.paginationCount,
-		NamedArgs:  namedArgs,
-		OrderBy:    orderBy,
-		Priorities: nil,
-		Queues:     p.queues,
-		States:     p.states,
+		ArgsFragment: p.argsFragment,
+		ArgsPaths:    argsPaths,
+		AttemptMax:   p.attemptMax,
+		AttemptMin:   p.attemptMin,
+		Conditions:   conditionsBuilder.String(),
+		CreatedAt:    p.createdAt,
+		FinalizedAt:  p.finalizedAt,
+		IDMax:        p.idMax,
+		IDs:          p.ids,
+		Kinds:        p.kinds,
+		LimitCount:   p.paginationCount,
+		NamedArgs:    namedArgs,
+		OrderBy:      orderBy,
+		Priorities:   sliceutil.Map(p.priorities, func(priority int) int16 { return int16(priority) }), //nolint:gosec
+		Queues:       p.queues,
+		ScheduledAt:  p.scheduledAt,
+		States:       p.states,
+		TagsAll:      p.tagsAll,
+		TagsAny:      p.tagsAny,
 	}
 
 	return dbParams, nil
 }
 
+// Like toDBParams, but for counting jobs, so pagination and ordering are reset
+// to make sure that every matching job is counted.
+func (p *JobListParams) toCountDBParams() (*dblist.JobListParams, error) {
+	if p == nil {
+		p = NewJobListParams()
+	}
+
+	paramsCopy := p.copy()
+	paramsCopy.after = nil
+	paramsCopy.before = nil
+	paramsCopy.sortField = JobListOrderByID
+	paramsCopy.sortOrder = SortOrderAsc
+
+	return paramsCopy.toDBParams()
+}
+
 // After returns an updated filter set that will only return jobs
 // after the given cursor.
 func (p *JobListParams) After(cursor *JobListCursor) *JobListParams {
@@ -315,6 +406,87 @@ func (p *JobListParams) After(cursor *JobListCursor) *JobListParams {
 	return paramsCopy
 }
 
+// Args returns an updated filter set that will only return jobs whose args
+// contain the given JSON fragment, using Postgres' `@>` containment operator.
+// For example, `{"customer_id": 123}` matches jobs whose args have a top level
+// `customer_id` of 123, regardless of any other args they have.
+func (p *JobListParams) Args(json string) *JobListParams {
+	paramsCopy := p.copy()
+	paramsCopy.argsFragment = json
+	return paramsCopy
+}
+
+// ArgsPath returns an updated filter set that will only return jobs whose args
+// have the given value at path. Path is a dot separated list of object keys or
+// array indexes like `customer.id` or `items.0.sku`, and value is compared to
+// what's found there after being marshaled to JSON.
+//
+// Unlike other filters, ArgsPath can be called multiple times to require
+// multiple paths to match.
+func (p *JobListParams) ArgsPath(path string, value any) *JobListParams {
+	paramsCopy := p.copy()
+	paramsCopy.argsPaths = append(paramsCopy.argsPaths, jobListArgsPath{path: strings.Split(path, "."), value: value})
+	return paramsCopy
+}
+
+// AttemptAtLeast returns an updated filter set that will only return jobs that
+// have been attempted at least the given number of times.
+func (p *JobListParams) AttemptAtLeast(attempt int) *JobListParams {
+	paramsCopy := p.copy()
+	paramsCopy.attemptMin = &attempt
+	return paramsCopy
+}
+
+// AttemptAtMost returns an updated filter set that will only return jobs that
+// have been attempted at most the given number of times.
+func (p *JobListParams) AttemptAtMost(attempt int) *JobListParams {
+	paramsCopy := p.copy()
+	paramsCopy.attemptMax = &attempt
+	return paramsCopy
+}
+
+// Before returns an updated filter set that will only return jobs before the
+// given cursor. Combined with JobListResult.FirstCursor, it's used to paginate
+// backwards, with each page containing the jobs immediately preceding the
+// cursor, still in the order requested by OrderBy.
+//
+//	listRes, err := client.JobList(ctx, params.Before(previousRes.FirstCursor))
+//
+// Before may be combined with After to select only the jobs between two
+// cursors.
+func (p *JobListParams) Before(cursor *JobListCursor) *JobListParams {
+	paramsCopy := p.copy()
+	if cursor.job == nil {
+		paramsCopy.before = cursor
+	} else {
+		paramsCopy.before = jobListCursorFromJobAndParams(cursor.job, paramsCopy)
+	}
+	return paramsCopy
+}
+
+// CreatedAtRange returns an updated filter set that will only return jobs
+// created at or after start, and before end. Either may be left as a zero time
+// to leave that side of the range unbounded.
+//
+// Panics if both start and end are given, but end is before start.
+func (p *JobListParams) CreatedAtRange(start, end time.Time) *JobListParams {
+	paramsCopy := p.copy()
+	paramsCopy.createdAt = jobListTimeRange(start, end)
+	return paramsCopy
+}
+
+// FinalizedAtRange returns an updated filter set that will only return jobs
+// finalized at or after start, and before end. Either may be left as a zero
+// time to leave that side of the range unbounded. Jobs that haven't been
+// finalized never match.
+//
+// Panics if both start and end are given, but end is before start.
+func (p *JobListParams) FinalizedAtRange(start, end time.Time) *JobListParams {
+	paramsCopy := p.copy()
+	paramsCopy.finalizedAt = jobListTimeRange(start, end)
+	return paramsCopy
+}
+
 // First returns an updated filter set that will only return the first
 // count jobs.
 //
@@ -331,6 +503,25 @@ func (p *JobListParams) First(count int) *JobListParams {
 	return paramsCopy
 }
 
+// IDAtMost returns an updated filter set that will only return jobs with an ID
+// less than or equal to the given one. Since IDs increase as jobs are inserted,
+// it's useful for pinning a set of jobs so that a bulk operation like
+// JobCancelMany doesn't also act on jobs inserted after the set was inspected.
+func (p *JobListParams) IDAtMost(id int64) *JobListParams {
+	paramsCopy := p.copy()
+	paramsCopy.idMax = &id
+	return paramsCopy
+}
+
+// IDs returns an updated filter set that will only return jobs with the given
+// IDs.
+func (p *JobListParams) IDs(ids ...int64) *JobListParams {
+	paramsCopy := p.copy()
+	paramsCopy.ids = make([]int64, len(ids))
+	copy(paramsCopy.ids, ids)
+	return paramsCopy
+}
+
 // Kinds returns an updated filter set that will only return jobs of the given
 // kinds.
 func (p *JobListParams) Kinds(kinds ...string) *JobListParams {
@@ -346,6 +537,15 @@ func (p *JobListParams) Metadata(json string) *JobListParams {
 	return paramsCopy
 }
 
+// Priorities returns an updated filter set that will only return jobs with the
+// given priorities.
+func (p *JobListParams) Priorities(priorities ...int) *JobListParams {
+	paramsCopy := p.copy()
+	paramsCopy.priorities = make([]int, len(priorities))
+	copy(paramsCopy.priorities, priorities)
+	return paramsCopy
+}
+
 // Queues returns an updated filter set that will only return jobs from the
 // given queues.
 func (p *JobListParams) Queues(queues ...string) *JobListParams {
@@ -382,6 +582,17 @@ func (p *JobListParams) OrderBy(field JobListOrderByField, direction SortOrder)
 	return paramsCopy
 }
 
+// ScheduledAtRange returns an updated filter set that will only return jobs
+// scheduled at or after start, and before end. Either may be left as a zero
+// time to leave that side of the range unbounded.
+//
+// Panics if both start and end are given, but end is before start.
+func (p *JobListParams) ScheduledAtRange(start, end time.Time) *JobListParams {
+	paramsCopy := p.copy()
+	paramsCopy.scheduledAt = jobListTimeRange(start, end)
+	return paramsCopy
+}
+
 // States returns an updated filter set that will only return jobs in the given
 // states.
 func (p *JobListParams) States(states ...rivertype.JobState) *JobListParams {
@@ -392,6 +603,38 @@ func (p *JobListParams) States(states ...rivertype.JobState) *JobListParams {
 	return paramsCopy
 }
 
+// TagsAll returns an updated filter set that will only return jobs that have
+// all of the given tags.
+func (p *JobListParams) TagsAll(tags ...string) *JobListParams {
+	paramsCopy := p.copy()
+	paramsCopy.tagsAll = make([]string, len(tags))
+	copy(paramsCopy.tagsAll, tags)
+	return paramsCopy
+}
+
+// TagsAny returns an updated filter set that will only return jobs that have
+// at least one of the given tags.
+func (p *JobListParams) TagsAny(tags ...string) *JobListParams {
+	paramsCopy := p.copy()
+	paramsCopy.tagsAny = make([]string, len(tags))
+	copy(paramsCopy.tagsAny, tags)
+	return paramsCopy
+}
+
+func jobListTimeRange(start, end time.Time) dblist.TimeRange {
+	if !start.IsZero() && !end.IsZero() && end.Before(start) {
+		panic("end must not be before start")
+	}
+	return dblist.TimeRange{End: end, Start: start}
+}
+
+func reverseSortOrder(order dblist.SortOrder) dblist.SortOrder {
+	if order == dblist.SortOrderDesc {
+		return dblist.SortOrderAsc
+	}
+	return dblist.SortOrderDesc
+}
+
 func jobListTimeFieldForState(state rivertype.JobState) string {
 	// Don't include a `default` so `exhaustive` lint can detect omissions.
 	switch state {
@@ -3,11 +3,13 @@ package river
 import (
 	"encoding/json"
 	"fmt"
+	"strings"
 	"testing"
 	"time"
 
 	"github.com/stretchr/testify/require"
 
+	"github.com/riverqueue/river/internal/dblist"
 	"github.com/riverqueue/river/rivershared/util/ptrutil"
 	"github.com/riverqueue/river/rivertype"
 )
@@ -171,6 +173,31 @@ func Test_JobListCursor_MarshalJSON(t *testing.T) {
 		require.Equal(t, cursor, unmarshaledParams)
 	})
 
+	t.Run("UnmarshalsURLAndStandardEncodings", func(t *testing.T) {
+		t.Parallel()
+
+		// A kind chosen so that its URL encoding contains characters that
+		// aren't valid in standard encoding.
+		cursor := &JobListCursor{
+			id:    4,
+			kind:  "kind???",
+			queue: "test_queue",
+		}
+
+		text, err := cursor.MarshalText()
+		require.NoError(t, err)
+		require.Contains(t, string(text), "_")
+
+		unmarshaledCursor := &JobListCursor{}
+		require.NoError(t, unmarshaledCursor.UnmarshalText(text))
+		require.Equal(t, cursor, unmarshaledCursor)
+
+		stdText := strings.NewReplacer("-", "+", "_", "/").Replace(string(text))
+		unmarshaledCursor = &JobListCursor{}
+		require.NoError(t, unmarshaledCursor.UnmarshalText([]byte(stdText)))
+		require.Equal(t, cursor, unmarshaledCursor)
+	})
+
 	t.Run("ErrorsOnJobOnlyCursor", func(t *testing.T) {
 		t.Parallel()
 
@@ -187,3 +214,45 @@ func Test_JobListCursor_MarshalJSON(t *testing.T) {
 		require.EqualError(t, err, "json: error calling MarshalText for type *river.JobListCursor: cursor initialized with only a job can't be marshaled; try a cursor from JobListResult instead")
 	})
 }
+
+func Test_JobListParams_toDBParams(t *testing.T) {
+	t.Parallel()
+
+	t.Run("After", func(t *testing.T) {
+		t.Parallel()
+
+		dbParams, err := NewJobListParams().After(&JobListCursor{id: 123}).toDBParams()
+		require.NoError(t, err)
+		require.Equal(t, "(id > @after_id)", dbParams.Conditions)
+		require.Equal(t, map[string]any{"after_id": int64(123)}, dbParams.NamedArgs)
+		require.Equal(t, []dblist.JobListOrderBy{{Expr: "id", Order: dblist.SortOrderAsc}}, dbParams.OrderBy)
+	})
+
+	t.Run("BeforeReversesOrder", func(t *testing.T) {
+		t.Parallel()
+
+		dbParams, err := NewJobListParams().Before(&JobListCursor{id: 123}).toDBParams()
+		require.NoError(t, err)
+		require.Equal(t, "(id < @before_id)", dbParams.Conditions)
+		require.Equal(t, map[string]any{"before_id": int64(123)}, dbParams.NamedArgs)
+		require.Equal(t, []dblist.JobListOrderBy{{Expr: "id", Order: dblist.SortOrderDesc}}, dbParams.OrderBy)
+	})
+
+	t.Run("BeforeWithTimeDescending", func(t *testing.T) {
+		t.Parallel()
+
+		cursorTime := time.Now()
+
+		dbParams, err := NewJobListParams().
+			OrderBy(JobListOrderByScheduledAt, SortOrderDesc).
+			Before(&JobListCursor{id: 123, time: cursorTime}).
+			toDBParams()
+		require.NoError(t, err)
+		require.Equal(t, `("scheduled_at" > @before_time OR ("scheduled_at" = @before_time AND "id" > @before_id))`, dbParams.Conditions)
+		require.Equal(t, map[string]any{"before_id": int64(123), "before_time": cursorTime}, dbParams.NamedArgs)
+		require.Equal(t, []dblist.JobListOrderBy{
+			{Expr: "scheduled_at", Order: dblist.SortOrderAsc},
+			{Expr: "id", Order: dblist.SortOrderAsc},
+		}, dbParams.OrderBy)
+	})
+}
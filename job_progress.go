@@ -0,0 +1,119 @@
+package river
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/riverqueue/river/internal/jobexecutor"
+	"github.com/riverqueue/river/rivertype"
+)
+
+const (
+	maxCheckpointSizeMB    = 1
+	maxCheckpointSizeBytes = maxCheckpointSizeMB * 1024 * 1024
+)
+
+// RecordProgress records the progress of a long-running job as a percentage
+// between 0 and 100, along with an optional free-form status describing what
+// the job is currently doing.
+//
+// Unlike RecordOutput, progress is flushed to the job's metadata periodically
+// while the job is still running (see Config.ProgressFlushInterval) in
+// addition to when it finishes, so it's visible from JobGet while the job
+// works. Each flush also emits an EventKindJobProgress event to subscriptions.
+// Progress is stored under the `"progress"` key
+// ([github.com/riverqueue/river/rivertype.MetadataKeyProgress]) and can be
+// read back with [github.com/riverqueue/river/rivertype.JobRow.Progress].
+//
+// This function must be called within a Worker's Work function. It returns an
+// error if called anywhere else. It's cheap to call frequently because only the
+// most recent progress is written on each flush.
+func RecordProgress(ctx context.Context, percent float64, status string) error {
+	progressUpdates, ok := jobexecutor.ProgressUpdatesFromWorkContext(ctx)
+	if !ok {
+		return errors.New("RecordProgress must be called within a Worker")
+	}
+
+	if percent < 0 || percent > 100 {
+		return fmt.Errorf("progress percent must be between 0 and 100, got %v", percent)
+	}
+
+	progressBytes, err := json.Marshal(&rivertype.JobProgress{
+		Percent:   percent,
+		Status:    status,
+		UpdatedAt: time.Now().UTC(),
+	})
+	if err != nil {
+		return err
+	}
+
+	progressUpdates.Set(rivertype.MetadataKeyProgress, progressBytes)
+	return nil
+}
+
+// Checkpoint records a checkpoint state for a long-running job. The state can
+// be any JSON-encodable value, and is typically used to store how far along
+// the job got so that if the current attempt fails, the next attempt can
+// resume where it left off instead of starting from scratch. Retrieve it at
+// the beginning of Work with CheckpointFromJob.
+//
+// Like RecordProgress, checkpoints are flushed to the job's metadata
+// periodically while the job is running in addition to when it finishes, so
+// a checkpoint survives even if the job's process crashes and it's later
+// rescued. Checkpoints are stored under the `"checkpoint"` key
+// ([github.com/riverqueue/river/rivertype.MetadataKeyCheckpoint]). Only the
+// latest checkpoint is kept, and it's limited to 1 MB in size.
+//
+// This function must be called within a Worker's Work function. It returns an
+// error if called anywhere else, or if state is not JSON-encodable.
+func Checkpoint(ctx context.Context, state any) error {
+	progressUpdates, ok := jobexecutor.ProgressUpdatesFromWorkContext(ctx)
+	if !ok {
+		return errors.New("Checkpoint must be called within a Worker")
+	}
+
+	stateBytes, err := json.Marshal(state)
+	if err != nil {
+		return err
+	}
+
+	if len(stateBytes) > maxCheckpointSizeBytes {
+		return fmt.Errorf("checkpoint is too large: %d bytes (max %d MB)", len(stateBytes), maxCheckpointSizeMB)
+	}
+
+	progressUpdates.Set(rivertype.MetadataKeyCheckpoint, stateBytes)
+	return nil
+}
+
+// CheckpointFromJob unmarshals the checkpoint most recently recorded by a
+// previous attempt of the job with Checkpoint, returning nil if there isn't
+// one:
+//
+//	func (w *ImportWorker) Work(ctx context.Context, job *river.Job[ImportArgs]) error {
+//		checkpoint, err := river.CheckpointFromJob[ImportCheckpoint](job.JobRow)
+//		if err != nil {
+//			return err
+//		}
+//
+//		var offset int
+//		if checkpoint != nil {
+//			offset = checkpoint.Offset
+//		}
+//		...
+//	}
+func CheckpointFromJob[T any](job *rivertype.JobRow) (*T, error) {
+	checkpointBytes := job.Checkpoint()
+	if checkpointBytes == nil {
+		return nil, nil //nolint:nilnil
+	}
+
+	var checkpoint T
+	if err := json.Unmarshal(checkpointBytes, &checkpoint); err != nil {
+		return nil, fmt.Errorf("error unmarshaling checkpoint: %w", err)
+	}
+
+	return &checkpoint, nil
+}
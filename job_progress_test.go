@@ -0,0 +1,220 @@
+package river
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/stretchr/testify/require"
+
+	"github.com/riverqueue/river/internal/jobexecutor"
+	"github.com/riverqueue/river/internal/riverinternaltest"
+	"github.com/riverqueue/river/rivershared/riversharedtest"
+	"github.com/riverqueue/river/rivertype"
+)
+
+func TestRecordProgress(t *testing.T) {
+	t.Parallel()
+
+	ctx := context.Background()
+
+	t.Run("SetsProgress", func(t *testing.T) {
+		t.Parallel()
+
+		progressUpdates := jobexecutor.NewProgressUpdates()
+		workCtx := context.WithValue(ctx, jobexecutor.ContextKeyProgressUpdates, progressUpdates)
+
+		require.NoError(t, RecordProgress(workCtx, 50, "halfway there"))
+
+		jobRow := &rivertype.JobRow{Metadata: mustMarshalJSON(t, progressUpdates.All())}
+		progress := jobRow.Progress()
+		require.NotNil(t, progress)
+		require.InDelta(t, 50, progress.Percent, 0.001)
+		require.Equal(t, "halfway there", progress.Status)
+		require.WithinDuration(t, time.Now(), progress.UpdatedAt, 5*time.Second)
+	})
+
+	t.Run("PercentOutOfRange", func(t *testing.T) {
+		t.Parallel()
+
+		workCtx := context.WithValue(ctx, jobexecutor.ContextKeyProgressUpdates, jobexecutor.NewProgressUpdates())
+
+		require.EqualError(t, RecordProgress(workCtx, -1, ""), "progress percent must be between 0 and 100, got -1")
+		require.EqualError(t, RecordProgress(workCtx, 101, ""), "progress percent must be between 0 and 100, got 101")
+	})
+
+	t.Run("NotInWorker", func(t *testing.T) {
+		t.Parallel()
+
+		require.EqualError(t, RecordProgress(ctx, 50, ""), "RecordProgress must be called within a Worker")
+	})
+}
+
+func TestCheckpoint(t *testing.T) {
+	t.Parallel()
+
+	ctx := context.Background()
+
+	type importCheckpoint struct {
+		Offset int `json:"offset"`
+	}
+
+	t.Run("RoundTripsThroughCheckpointFromJob", func(t *testing.T) {
+		t.Parallel()
+
+		progressUpdates := jobexecutor.NewProgressUpdates()
+		workCtx := context.WithValue(ctx, jobexecutor.ContextKeyProgressUpdates, progressUpdates)
+
+		require.NoError(t, Checkpoint(workCtx, &importCheckpoint{Offset: 123}))
+
+		jobRow := &rivertype.JobRow{Metadata: mustMarshalJSON(t, progressUpdates.All())}
+		checkpoint, err := CheckpointFromJob[importCheckpoint](jobRow)
+		require.NoError(t, err)
+		require.Equal(t, &importCheckpoint{Offset: 123}, checkpoint)
+	})
+
+	t.Run("CheckpointFromJobWithoutCheckpoint", func(t *testing.T) {
+		t.Parallel()
+
+		checkpoint, err := CheckpointFromJob[importCheckpoint](&rivertype.JobRow{Metadata: []byte(`{}`)})
+		require.NoError(t, err)
+		require.Nil(t, checkpoint)
+	})
+
+	t.Run("InvalidState", func(t *testing.T) {
+		t.Parallel()
+
+		workCtx := context.WithValue(ctx, jobexecutor.ContextKeyProgressUpdates, jobexecutor.NewProgressUpdates())
+
+		var invalidState chan int
+		require.Error(t, Checkpoint(workCtx, invalidState))
+	})
+
+	t.Run("NotInWorker", func(t *testing.T) {
+		t.Parallel()
+
+		require.EqualError(t, Checkpoint(ctx, 1), "Checkpoint must be called within a Worker")
+	})
+}
+
+func Test_JobProgress_Client(t *testing.T) {
+	t.Parallel()
+
+	ctx := context.Background()
+
+	type JobArgs struct {
+		JobArgsReflectKind[JobArgs]
+	}
+
+	type importCheckpoint struct {
+		Offset int `json:"offset"`
+	}
+
+	setup := func(t *testing.T) *Client[pgx.Tx] {
+		t.Helper()
+
+		dbPool := riverinternaltest.TestDB(ctx, t)
+		config := newTestConfig(t, nil)
+		config.ProgressFlushInterval = 50 * time.Millisecond
+		client := newTestClient(t, dbPool, config)
+		t.Cleanup(func() { require.NoError(t, client.Stop(ctx)) })
+		return client
+	}
+
+	t.Run("FlushedWhileRunning", func(t *testing.T) {
+		t.Parallel()
+
+		client := setup(t)
+
+		finishJob := make(chan struct{})
+		AddWorker(client.config.Workers, WorkFunc(func(ctx context.Context, job *Job[JobArgs]) error {
+			if err := RecordProgress(ctx, 25, "importing"); err != nil {
+				return err
+			}
+			if err := Checkpoint(ctx, &importCheckpoint{Offset: 25}); err != nil {
+				return err
+			}
+
+			riversharedtest.WaitOrTimeout(t, finishJob)
+			return nil
+		}))
+
+		subChan, cancel := client.Subscribe(EventKindJobCompleted, EventKindJobProgress)
+		t.Cleanup(cancel)
+		startClient(ctx, t, client)
+
+		insertRes, err := client.Insert(ctx, JobArgs{}, nil)
+		require.NoError(t, err)
+
+		event := riversharedtest.WaitOrTimeout(t, subChan)
+		require.Equal(t, EventKindJobProgress, event.Kind)
+		require.Equal(t, insertRes.Job.ID, event.Job.ID)
+		require.Equal(t, rivertype.JobStateRunning, event.Job.State)
+		require.InDelta(t, 25, event.Job.Progress().Percent, 0.001)
+
+		// Visible from JobGet while the job is still running.
+		jobRow, err := client.JobGet(ctx, insertRes.Job.ID)
+		require.NoError(t, err)
+		require.Equal(t, rivertype.JobStateRunning, jobRow.State)
+		require.Equal(t, "importing", jobRow.Progress().Status)
+		checkpoint, err := CheckpointFromJob[importCheckpoint](jobRow)
+		require.NoError(t, err)
+		require.Equal(t, &importCheckpoint{Offset: 25}, checkpoint)
+
+		close(finishJob)
+
+		event = riversharedtest.WaitOrTimeout(t, subChan)
+		require.Equal(t, EventKindJobCompleted, event.Kind)
+		require.Equal(t, "importing", event.Job.Progress().Status)
+	})
+
+	t.Run("CheckpointSurvivesRetry", func(t *testing.T) {
+		t.Parallel()
+
+		client := setup(t)
+
+		AddWorker(client.config.Workers, WorkFunc(func(ctx context.Context, job *Job[JobArgs]) error {
+			checkpoint, err := CheckpointFromJob[importCheckpoint](job.JobRow)
+			if err != nil {
+				return err
+			}
+
+			if checkpoint == nil {
+				if err := Checkpoint(ctx, &importCheckpoint{Offset: 50}); err != nil {
+					return err
+				}
+				return errors.New("failed halfway")
+			}
+
+			return RecordOutput(ctx, checkpoint.Offset)
+		}))
+
+		subChan := subscribe(t, client)
+		startClient(ctx, t, client)
+
+		insertRes, err := client.Insert(ctx, JobArgs{}, &InsertOpts{MaxAttempts: 2})
+		require.NoError(t, err)
+
+		event := riversharedtest.WaitOrTimeout(t, subChan)
+		require.Equal(t, EventKindJobFailed, event.Kind)
+
+		// Make the job immediately available for its second attempt.
+		_, err = client.JobRetry(ctx, insertRes.Job.ID)
+		require.NoError(t, err)
+
+		event = riversharedtest.WaitOrTimeout(t, subChan)
+		require.Equal(t, EventKindJobCompleted, event.Kind)
+		require.JSONEq(t, `50`, string(event.Job.Output()))
+	})
+}
+
+func mustMarshalJSON(t *testing.T, v any) []byte {
+	t.Helper()
+
+	data, err := json.Marshal(v)
+	require.NoError(t, err)
+	return data
+}
@@ -0,0 +1,129 @@
+package river
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"github.com/riverqueue/river/rivertype"
+)
+
+// When JobWait receives cluster events via listen/notify, it still checks the
+// job periodically in case an event was dropped, but much less often than it
+// would when polling.
+const jobWaitPollIntervalWithListener = 5 * time.Second
+
+// JobWait blocks until the job with the given ID is finalized, which is to
+// say it reaches one of the states completed, cancelled, or discarded, and
+// returns its up-to-date JobRow. Returns ErrNotFound if the job doesn't exist,
+// and the context's error if the context is cancelled or its deadline is
+// exceeded first. A deadline should generally be set on the context so that
+// callers don't wait indefinitely on a job that's never worked.
+//
+// When Config.ClusterEvents is enabled and the driver supports listen/notify,
+// JobWait is woken by notifications published by clients working jobs (which
+// must also have ClusterEvents enabled). Otherwise, it polls for the job every
+// FetchPollInterval.
+//
+// JobWait may be used from clients that only insert jobs, which makes it
+// useful for request/reply style APIs where a handler inserts a job and waits
+// on its result. See JobWaitOutput for a variant that decodes the job's output.
+func (c *Client[TTx]) JobWait(ctx context.Context, id int64) (*rivertype.JobRow, error) {
+	if !c.driver.HasPool() {
+		return nil, errNoDriverDBPool
+	}
+
+	var (
+		jobChan      <-chan *rivertype.JobRow
+		pollInterval = c.config.FetchPollInterval
+	)
+	if c.clusterEventStream != nil && c.clusterEventStream.listener != nil {
+		// Register before checking the job for the first time so that an
+		// event can't be missed between the check and the registration. The
+		// stream dispatches by job ID, so only events for this job are
+		// received.
+		waitChan, cancel, err := c.clusterEventStream.WaitJob(ctx, id)
+		if err != nil {
+			return nil, err
+		}
+		defer cancel()
+
+		jobChan = waitChan
+		pollInterval = max(pollInterval, jobWaitPollIntervalWithListener)
+	}
+
+	ticker := time.NewTicker(pollInterval)
+	defer ticker.Stop()
+
+	for {
+		job, err := c.JobGet(ctx, id)
+		if err != nil {
+			return nil, err
+		}
+		if jobStateIsFinalized(job.State) {
+			return job, nil
+		}
+
+	waitLoop:
+		for {
+			select {
+			case <-ctx.Done():
+				return nil, ctx.Err()
+
+			case job := <-jobChan:
+				// The job may have moved on to another state since the event
+				// was published, like if it was retried, so keep waiting
+				// unless it's still finalized.
+				if jobStateIsFinalized(job.State) {
+					return job, nil
+				}
+
+			case <-ticker.C:
+				break waitLoop
+			}
+		}
+	}
+}
+
+// JobWaitOutput waits for the job with the given ID to be finalized like
+// Client.JobWait, then decodes the output it recorded with RecordOutput into
+// a value of type T. Returns the finalized JobRow along with its output, which
+// is nil if the job didn't record any output (e.g. because it was cancelled or
+// discarded).
+//
+//	output, job, err := river.JobWaitOutput[ReportOutput](ctx, client, insertRes.Job.ID)
+//	if err != nil {
+//		return err
+//	}
+//	if job.State != rivertype.JobStateCompleted {
+//		return fmt.Errorf("report job finished in state %s", job.State)
+//	}
+func JobWaitOutput[T any, TTx any](ctx context.Context, client *Client[TTx], id int64) (*T, *rivertype.JobRow, error) {
+	job, err := client.JobWait(ctx, id)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	outputBytes := job.Output()
+	if outputBytes == nil {
+		return nil, job, nil
+	}
+
+	var output T
+	if err := json.Unmarshal(outputBytes, &output); err != nil {
+		return nil, job, fmt.Errorf("error unmarshaling output: %w", err)
+	}
+
+	return &output, job, nil
+}
+
+// Whether the given state is one that a job won't leave without intervention
+// like a manual retry.
+func jobStateIsFinalized(state rivertype.JobState) bool {
+	switch state { //nolint:exhaustive
+	case rivertype.JobStateCancelled, rivertype.JobStateCompleted, rivertype.JobStateDiscarded:
+		return true
+	}
+	return false
+}
@@ -0,0 +1,192 @@
+package river
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/stretchr/testify/require"
+
+	"github.com/riverqueue/river/internal/riverinternaltest"
+	"github.com/riverqueue/river/riverdriver/riverpgxv5"
+	"github.com/riverqueue/river/rivershared/riversharedtest"
+	"github.com/riverqueue/river/rivertype"
+)
+
+func Test_Client_JobWait(t *testing.T) {
+	t.Parallel()
+
+	ctx := context.Background()
+
+	type JobArgs struct {
+		JobArgsReflectKind[JobArgs]
+
+		Fail bool `json:"fail"`
+	}
+
+	type reportOutput struct {
+		Rows int `json:"rows"`
+	}
+
+	type testBundle struct {
+		insertClient *Client[pgx.Tx]
+	}
+
+	// Returns a started client that works jobs, along with an insert-only
+	// client sharing its database.
+	setup := func(t *testing.T, clusterEvents bool) (*Client[pgx.Tx], *testBundle) {
+		t.Helper()
+
+		dbPool := riverinternaltest.TestDB(ctx, t)
+
+		config := newTestConfig(t, nil)
+		config.ClusterEvents = clusterEvents
+		AddWorker(config.Workers, WorkFunc(func(ctx context.Context, job *Job[JobArgs]) error {
+			if job.Args.Fail {
+				return JobCancel(errors.New("failed"))
+			}
+			return RecordOutput(ctx, reportOutput{Rows: 123})
+		}))
+		client := newTestClient(t, dbPool, config)
+
+		insertClient := newTestClient(t, dbPool, &Config{
+			ClusterEvents:     clusterEvents,
+			FetchPollInterval: 50 * time.Millisecond,
+			Logger:            riversharedtest.Logger(t),
+			TestOnly:          true,
+		})
+
+		return client, &testBundle{insertClient: insertClient}
+	}
+
+	t.Run("WaitsForCompletedPolling", func(t *testing.T) {
+		t.Parallel()
+
+		client, bundle := setup(t, false)
+
+		insertRes, err := bundle.insertClient.Insert(ctx, JobArgs{}, nil)
+		require.NoError(t, err)
+
+		startClient(ctx, t, client)
+
+		waitCtx, cancel := context.WithTimeout(ctx, 5*time.Second)
+		defer cancel()
+
+		job, err := bundle.insertClient.JobWait(waitCtx, insertRes.Job.ID)
+		require.NoError(t, err)
+		require.Equal(t, insertRes.Job.ID, job.ID)
+		require.Equal(t, rivertype.JobStateCompleted, job.State)
+	})
+
+	t.Run("WaitsForCompletedWithClusterEvents", func(t *testing.T) {
+		t.Parallel()
+
+		client, bundle := setup(t, true)
+
+		insertRes, err := bundle.insertClient.Insert(ctx, JobArgs{}, nil)
+		require.NoError(t, err)
+
+		startClient(ctx, t, client)
+
+		// Shorter than the fallback poll interval used with a listener, so
+		// this only succeeds if woken by a notification.
+		waitCtx, cancel := context.WithTimeout(ctx, jobWaitPollIntervalWithListener-time.Second)
+		defer cancel()
+
+		job, err := bundle.insertClient.JobWait(waitCtx, insertRes.Job.ID)
+		require.NoError(t, err)
+		require.Equal(t, rivertype.JobStateCompleted, job.State)
+	})
+
+	t.Run("AlreadyFinalized", func(t *testing.T) {
+		t.Parallel()
+
+		client, bundle := setup(t, false)
+
+		insertRes, err := bundle.insertClient.Insert(ctx, JobArgs{}, nil)
+		require.NoError(t, err)
+
+		_, err = client.JobCancel(ctx, insertRes.Job.ID)
+		require.NoError(t, err)
+
+		job, err := bundle.insertClient.JobWait(ctx, insertRes.Job.ID)
+		require.NoError(t, err)
+		require.Equal(t, rivertype.JobStateCancelled, job.State)
+	})
+
+	t.Run("ContextDeadline", func(t *testing.T) {
+		t.Parallel()
+
+		_, bundle := setup(t, false)
+
+		insertRes, err := bundle.insertClient.Insert(ctx, JobArgs{}, nil)
+		require.NoError(t, err)
+
+		waitCtx, cancel := context.WithTimeout(ctx, 100*time.Millisecond)
+		defer cancel()
+
+		_, err = bundle.insertClient.JobWait(waitCtx, insertRes.Job.ID)
+		require.ErrorIs(t, err, context.DeadlineExceeded)
+	})
+
+	t.Run("NotFound", func(t *testing.T) {
+		t.Parallel()
+
+		_, bundle := setup(t, false)
+
+		_, err := bundle.insertClient.JobWait(ctx, 0)
+		require.ErrorIs(t, err, ErrNotFound)
+	})
+
+	t.Run("NoDatabasePool", func(t *testing.T) {
+		t.Parallel()
+
+		client, err := NewClient(riverpgxv5.New(nil), &Config{
+			Logger: riversharedtest.Logger(t),
+		})
+		require.NoError(t, err)
+
+		_, err = client.JobWait(ctx, 1)
+		require.ErrorIs(t, err, errNoDriverDBPool)
+	})
+
+	t.Run("JobWaitOutput", func(t *testing.T) {
+		t.Parallel()
+
+		client, bundle := setup(t, false)
+
+		insertRes, err := bundle.insertClient.Insert(ctx, JobArgs{}, nil)
+		require.NoError(t, err)
+
+		startClient(ctx, t, client)
+
+		waitCtx, cancel := context.WithTimeout(ctx, 5*time.Second)
+		defer cancel()
+
+		output, job, err := JobWaitOutput[reportOutput](waitCtx, bundle.insertClient, insertRes.Job.ID)
+		require.NoError(t, err)
+		require.Equal(t, rivertype.JobStateCompleted, job.State)
+		require.Equal(t, &reportOutput{Rows: 123}, output)
+	})
+
+	t.Run("JobWaitOutputWithoutOutput", func(t *testing.T) {
+		t.Parallel()
+
+		client, bundle := setup(t, false)
+
+		insertRes, err := bundle.insertClient.Insert(ctx, JobArgs{Fail: true}, nil)
+		require.NoError(t, err)
+
+		startClient(ctx, t, client)
+
+		waitCtx, cancel := context.WithTimeout(ctx, 5*time.Second)
+		defer cancel()
+
+		output, job, err := JobWaitOutput[reportOutput](waitCtx, bundle.insertClient, insertRes.Job.ID)
+		require.NoError(t, err)
+		require.Equal(t, rivertype.JobStateCancelled, job.State)
+		require.Nil(t, output)
+	})
+}
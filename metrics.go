@@ -0,0 +1,179 @@
+package river
+
+import (
+	"context"
+	"errors"
+	"log/slog"
+	"time"
+
+	"github.com/riverqueue/river/internal/jobcompleter"
+	"github.com/riverqueue/river/riverdriver"
+	"github.com/riverqueue/river/rivermetrics"
+	"github.com/riverqueue/river/rivertype"
+)
+
+// The interval at which gauges of queue depth are updated.
+const queueMetricsInterval = 15 * time.Second
+
+// The states of jobs included in queue depth gauges. Finalized states are
+// left out because counting them may be expensive and they're not a
+// meaningful measure of a queue's depth.
+var queueMetricsStates = []rivertype.JobState{ //nolint:gochecknoglobals
+	rivertype.JobStateAvailable,
+	rivertype.JobStatePending,
+	rivertype.JobStateRetryable,
+	rivertype.JobStateRunning,
+	rivertype.JobStateScheduled,
+}
+
+// Maps the kinds of events distributed for jobs updated by the completer to
+// the counters incremented for them.
+var metricByEventKind = map[EventKind]string{ //nolint:gochecknoglobals
+	EventKindJobCancelled: rivermetrics.MetricJobsCancelled,
+	EventKindJobCompleted: rivermetrics.MetricJobsCompleted,
+	EventKindJobDiscarded: rivermetrics.MetricJobsDiscarded,
+	EventKindJobFailed:    rivermetrics.MetricJobsFailed,
+	EventKindJobSnoozed:   rivermetrics.MetricJobsSnoozed,
+}
+
+func jobMetricLabels(kind, queue string) rivermetrics.Labels {
+	return rivermetrics.Labels{rivermetrics.LabelKind: kind, rivermetrics.LabelQueue: queue}
+}
+
+// metricsMiddleware records metrics for inserted and started jobs, and tracks
+// the number of jobs being worked. It's installed automatically as the
+// outermost middleware when Config.Metrics is set.
+type metricsMiddleware struct {
+	MiddlewareDefaults
+
+	recorder rivermetrics.Recorder
+}
+
+func (m *metricsMiddleware) InsertMany(ctx context.Context, manyParams []*rivertype.JobInsertParams, doInner func(context.Context) ([]*rivertype.JobInsertResult, error)) ([]*rivertype.JobInsertResult, error) {
+	results, err := doInner(ctx)
+	if err != nil {
+		return results, err
+	}
+
+	for _, result := range results {
+		// Results may be nil for fast inserts, which don't return rows.
+		if result == nil || result.Job == nil || result.UniqueSkippedAsDuplicate {
+			continue
+		}
+
+		m.recorder.CounterAdd(rivermetrics.MetricJobsInserted, jobMetricLabels(result.Job.Kind, result.Job.Queue), 1)
+	}
+
+	return results, nil
+}
+
+func (m *metricsMiddleware) Work(ctx context.Context, job *rivertype.JobRow, doInner func(context.Context) error) error {
+	labels := jobMetricLabels(job.Kind, job.Queue)
+
+	m.recorder.CounterAdd(rivermetrics.MetricJobsStarted, labels, 1)
+
+	m.recorder.GaugeAdd(rivermetrics.MetricJobsRunning, labels, 1)
+	defer m.recorder.GaugeAdd(rivermetrics.MetricJobsRunning, labels, -1)
+
+	return doInner(ctx)
+}
+
+// Records counters and duration histograms for jobs that the completer has set
+// to a new state.
+func recordJobUpdateMetrics(recorder rivermetrics.Recorder, updates []jobcompleter.CompleterJobUpdated) {
+	for _, update := range updates {
+		labels := jobMetricLabels(update.Job.Kind, update.Job.Queue)
+
+		for _, eventKind := range jobEventKindsForState(update.Job.State) {
+			recorder.CounterAdd(metricByEventKind[eventKind], labels, 1)
+		}
+
+		if update.JobStats != nil {
+			recorder.HistogramObserve(rivermetrics.MetricJobCompleteDurationSeconds, labels, update.JobStats.CompleteDuration.Seconds())
+			recorder.HistogramObserve(rivermetrics.MetricJobQueueWaitDurationSeconds, labels, update.JobStats.QueueWaitDuration.Seconds())
+			recorder.HistogramObserve(rivermetrics.MetricJobRunDurationSeconds, labels, update.JobStats.RunDuration.Seconds())
+		}
+	}
+}
+
+// Periodically updates gauges of the number of unfinalized jobs in each queue
+// by state. Counts are cluster-wide, so this is only run by the elected leader
+// to avoid every client reporting the same depths. Gauges are reset to zero
+// when it stops, so a client that loses leadership doesn't keep reporting
+// stale depths alongside the new leader.
+func (c *Client[TTx]) queueMetricsLoop(ctx context.Context, shouldStart bool, started, stopped func()) error {
+	if !shouldStart {
+		return nil
+	}
+
+	go func() {
+		started()
+		defer stopped() // this defer should come first so it's last out
+
+		ticker := time.NewTicker(queueMetricsInterval)
+		defer ticker.Stop()
+
+		var previous map[queueMetricsKey]struct{}
+
+		for {
+			var err error
+			previous, err = c.recordQueueMetrics(ctx, previous)
+			if err != nil && !errors.Is(err, context.Canceled) {
+				c.baseService.Logger.ErrorContext(ctx, c.baseService.Name+": Error recording queue metrics", slog.String("err", err.Error()))
+			}
+
+			select {
+			case <-ctx.Done():
+				c.resetQueueMetrics(previous)
+				return
+
+			case <-ticker.C:
+			}
+		}
+	}()
+
+	return nil
+}
+
+type queueMetricsKey struct {
+	queue string
+	state rivertype.JobState
+}
+
+// Sets queue depth gauges, returning the set of queue and state combinations
+// that were set. Combinations set previously that no longer have any jobs are
+// reset to zero so that their gauges don't go stale.
+func (c *Client[TTx]) recordQueueMetrics(ctx context.Context, previous map[queueMetricsKey]struct{}) (map[queueMetricsKey]struct{}, error) {
+	results, err := c.driver.GetExecutor().JobCountByQueueAndState(ctx, &riverdriver.JobCountByQueueAndStateParams{
+		Schema: c.config.schema,
+		State:  queueMetricsStates,
+	})
+	if err != nil {
+		return previous, err
+	}
+
+	current := make(map[queueMetricsKey]struct{}, len(results))
+	for _, result := range results {
+		current[queueMetricsKey{queue: result.Queue, state: result.State}] = struct{}{}
+		c.config.Metrics.GaugeSet(rivermetrics.MetricQueueJobs, queueMetricLabels(result.Queue, result.State), float64(result.Count))
+	}
+
+	for key := range previous {
+		if _, ok := current[key]; !ok {
+			c.config.Metrics.GaugeSet(rivermetrics.MetricQueueJobs, queueMetricLabels(key.queue, key.state), 0)
+		}
+	}
+
+	return current, nil
+}
+
+// Resets queue depth gauges previously set by recordQueueMetrics to zero.
+func (c *Client[TTx]) resetQueueMetrics(previous map[queueMetricsKey]struct{}) {
+	for key := range previous {
+		c.config.Metrics.GaugeSet(rivermetrics.MetricQueueJobs, queueMetricLabels(key.queue, key.state), 0)
+	}
+}
+
+func queueMetricLabels(queue string, state rivertype.JobState) rivermetrics.Labels {
+	return rivermetrics.Labels{rivermetrics.LabelQueue: queue, rivermetrics.LabelState: string(state)}
+}
@@ -0,0 +1,274 @@
+package river
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/riverqueue/river/internal/jobcompleter"
+	"github.com/riverqueue/river/internal/jobstats"
+	"github.com/riverqueue/river/internal/riverinternaltest"
+	"github.com/riverqueue/river/rivermetrics"
+	"github.com/riverqueue/river/rivershared/riversharedtest"
+	"github.com/riverqueue/river/rivershared/testfactory"
+	"github.com/riverqueue/river/rivershared/util/ptrutil"
+	"github.com/riverqueue/river/rivertype"
+)
+
+type testMetricsKey struct {
+	labels string
+	name   string
+}
+
+// A recorder that keeps the latest value of every metric, with histograms
+// recorded as the sum of observations.
+type testMetricsRecorder struct {
+	mu     sync.Mutex
+	values map[testMetricsKey]float64
+}
+
+func newTestMetricsRecorder() *testMetricsRecorder {
+	return &testMetricsRecorder{values: make(map[testMetricsKey]float64)}
+}
+
+func (r *testMetricsRecorder) key(name string, labels rivermetrics.Labels) testMetricsKey {
+	return testMetricsKey{labels: labels[rivermetrics.LabelKind] + "/" + labels[rivermetrics.LabelQueue] + "/" + labels[rivermetrics.LabelState], name: name}
+}
+
+func (r *testMetricsRecorder) CounterAdd(name string, labels rivermetrics.Labels, value float64) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.values[r.key(name, labels)] += value
+}
+
+func (r *testMetricsRecorder) GaugeAdd(name string, labels rivermetrics.Labels, value float64) {
+	r.CounterAdd(name, labels, value)
+}
+
+func (r *testMetricsRecorder) GaugeSet(name string, labels rivermetrics.Labels, value float64) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.values[r.key(name, labels)] = value
+}
+
+func (r *testMetricsRecorder) HistogramObserve(name string, labels rivermetrics.Labels, value float64) {
+	r.CounterAdd(name, labels, value)
+}
+
+func (r *testMetricsRecorder) value(name, labels string) (float64, bool) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	value, ok := r.values[testMetricsKey{labels: labels, name: name}]
+	return value, ok
+}
+
+func TestMetricsMiddleware(t *testing.T) {
+	t.Parallel()
+
+	ctx := context.Background()
+
+	t.Run("InsertMany", func(t *testing.T) {
+		t.Parallel()
+
+		recorder := newTestMetricsRecorder()
+		middleware := &metricsMiddleware{recorder: recorder}
+
+		_, err := middleware.InsertMany(ctx, nil, func(ctx context.Context) ([]*rivertype.JobInsertResult, error) {
+			return []*rivertype.JobInsertResult{
+				{Job: &rivertype.JobRow{Kind: "kind1", Queue: "default"}},
+				{Job: &rivertype.JobRow{Kind: "kind1", Queue: "default"}},
+				{Job: &rivertype.JobRow{Kind: "kind1", Queue: "default"}, UniqueSkippedAsDuplicate: true},
+				nil, // fast inserts don't return results
+			}, nil
+		})
+		require.NoError(t, err)
+
+		value, _ := recorder.value(rivermetrics.MetricJobsInserted, "kind1/default/")
+		require.InDelta(t, 2, value, 0.001)
+	})
+
+	t.Run("InsertManyError", func(t *testing.T) {
+		t.Parallel()
+
+		recorder := newTestMetricsRecorder()
+		middleware := &metricsMiddleware{recorder: recorder}
+
+		insertErr := errors.New("insert error")
+		_, err := middleware.InsertMany(ctx, nil, func(ctx context.Context) ([]*rivertype.JobInsertResult, error) {
+			return nil, insertErr
+		})
+		require.ErrorIs(t, err, insertErr)
+
+		_, ok := recorder.value(rivermetrics.MetricJobsInserted, "kind1/default/")
+		require.False(t, ok)
+	})
+
+	t.Run("Work", func(t *testing.T) {
+		t.Parallel()
+
+		recorder := newTestMetricsRecorder()
+		middleware := &metricsMiddleware{recorder: recorder}
+
+		workErr := errors.New("work error")
+		err := middleware.Work(ctx, &rivertype.JobRow{Kind: "kind1", Queue: "default"}, func(ctx context.Context) error {
+			running, _ := recorder.value(rivermetrics.MetricJobsRunning, "kind1/default/")
+			require.InDelta(t, 1, running, 0.001)
+			return workErr
+		})
+		require.ErrorIs(t, err, workErr)
+
+		started, _ := recorder.value(rivermetrics.MetricJobsStarted, "kind1/default/")
+		require.InDelta(t, 1, started, 0.001)
+
+		running, _ := recorder.value(rivermetrics.MetricJobsRunning, "kind1/default/")
+		require.InDelta(t, 0, running, 0.001)
+	})
+}
+
+func TestRecordJobUpdateMetrics(t *testing.T) {
+	t.Parallel()
+
+	recorder := newTestMetricsRecorder()
+
+	makeUpdate := func(state rivertype.JobState) jobcompleter.CompleterJobUpdated {
+		return jobcompleter.CompleterJobUpdated{
+			Job: &rivertype.JobRow{Kind: "kind1", Queue: "default", State: state},
+			JobStats: &jobstats.JobStatistics{
+				CompleteDuration:  100 * time.Millisecond,
+				QueueWaitDuration: 2 * time.Second,
+				RunDuration:       time.Second,
+			},
+		}
+	}
+
+	recordJobUpdateMetrics(recorder, []jobcompleter.CompleterJobUpdated{
+		makeUpdate(rivertype.JobStateCompleted),
+		makeUpdate(rivertype.JobStateCompleted),
+		makeUpdate(rivertype.JobStateCancelled),
+		makeUpdate(rivertype.JobStateDiscarded),
+		makeUpdate(rivertype.JobStateRetryable),
+		makeUpdate(rivertype.JobStateScheduled),
+	})
+
+	for name, expected := range map[string]float64{
+		rivermetrics.MetricJobsCancelled: 1,
+		rivermetrics.MetricJobsCompleted: 2,
+		rivermetrics.MetricJobsDiscarded: 1,
+		rivermetrics.MetricJobsFailed:    2, // discarded jobs also count as failed
+		rivermetrics.MetricJobsSnoozed:   1,
+
+		rivermetrics.MetricJobCompleteDurationSeconds:  0.6,
+		rivermetrics.MetricJobQueueWaitDurationSeconds: 12,
+		rivermetrics.MetricJobRunDurationSeconds:       6,
+	} {
+		value, _ := recorder.value(name, "kind1/default/")
+		require.InDelta(t, expected, value, 0.001, "unexpected value for %s", name)
+	}
+}
+
+func Test_Client_Metrics(t *testing.T) {
+	t.Parallel()
+
+	ctx := context.Background()
+
+	t.Run("RecordsInsertedAndWorkedJobs", func(t *testing.T) {
+		t.Parallel()
+
+		recorder := newTestMetricsRecorder()
+
+		dbPool := riverinternaltest.TestDB(ctx, t)
+		config := newTestConfig(t, nil)
+		config.Metrics = recorder
+		client := newTestClient(t, dbPool, config)
+
+		subChan := subscribe(t, client)
+		startClient(ctx, t, client)
+
+		_, err := client.Insert(ctx, noOpArgs{}, nil)
+		require.NoError(t, err)
+
+		event := riversharedtest.WaitOrTimeout(t, subChan)
+		require.Equal(t, EventKindJobCompleted, event.Kind)
+
+		for _, name := range []string{rivermetrics.MetricJobsInserted, rivermetrics.MetricJobsStarted, rivermetrics.MetricJobsCompleted} {
+			value, _ := recorder.value(name, "noOp/default/")
+			require.InDelta(t, 1, value, 0.001, "unexpected value for %s", name)
+		}
+	})
+
+	t.Run("RecordQueueMetrics", func(t *testing.T) {
+		t.Parallel()
+
+		recorder := newTestMetricsRecorder()
+
+		dbPool := riverinternaltest.TestDB(ctx, t)
+		config := newTestConfig(t, nil)
+		config.Metrics = recorder
+		client := newTestClient(t, dbPool, config)
+
+		exec := client.driver.GetExecutor()
+
+		_ = testfactory.Job(ctx, t, exec, &testfactory.JobOpts{Queue: ptrutil.Ptr("queue1"), State: ptrutil.Ptr(rivertype.JobStateAvailable)})
+		_ = testfactory.Job(ctx, t, exec, &testfactory.JobOpts{Queue: ptrutil.Ptr("queue1"), State: ptrutil.Ptr(rivertype.JobStateAvailable)})
+		job := testfactory.Job(ctx, t, exec, &testfactory.JobOpts{Queue: ptrutil.Ptr("queue2"), State: ptrutil.Ptr(rivertype.JobStateRetryable)})
+
+		previous, err := client.recordQueueMetrics(ctx, nil)
+		require.NoError(t, err)
+		require.Len(t, previous, 2)
+
+		value, _ := recorder.value(rivermetrics.MetricQueueJobs, "/queue1/available")
+		require.InDelta(t, 2, value, 0.001)
+		value, _ = recorder.value(rivermetrics.MetricQueueJobs, "/queue2/retryable")
+		require.InDelta(t, 1, value, 0.001)
+
+		// Gauges for combinations that no longer have jobs are reset to zero.
+		_, err = client.JobDelete(ctx, job.ID)
+		require.NoError(t, err)
+
+		_, err = client.recordQueueMetrics(ctx, previous)
+		require.NoError(t, err)
+
+		value, ok := recorder.value(rivermetrics.MetricQueueJobs, "/queue2/retryable")
+		require.True(t, ok)
+		require.InDelta(t, 0, value, 0.001)
+	})
+
+	t.Run("QueueMetricsRecordedByLeader", func(t *testing.T) {
+		t.Parallel()
+
+		recorder := newTestMetricsRecorder()
+
+		dbPool := riverinternaltest.TestDB(ctx, t)
+		config := newTestConfig(t, nil)
+		config.Metrics = recorder
+		client := newTestClient(t, dbPool, config)
+		client.testSignals.Init()
+
+		// Only started on election rather than with the client's services.
+		require.NotNil(t, client.queueMetrics)
+		require.NotContains(t, client.services, client.queueMetrics)
+
+		// In a queue the client isn't working so the job stays available.
+		_ = testfactory.Job(ctx, t, client.driver.GetExecutor(), &testfactory.JobOpts{Queue: ptrutil.Ptr("queue1"), State: ptrutil.Ptr(rivertype.JobStateAvailable)})
+
+		startClient(ctx, t, client)
+		client.testSignals.electedLeader.WaitOrTimeout()
+
+		require.Eventually(t, func() bool {
+			value, _ := recorder.value(rivermetrics.MetricQueueJobs, "/queue1/available")
+			return value == 1
+		}, 5*time.Second, 10*time.Millisecond)
+
+		// Gauges are reset when the client stops recording them, like when it
+		// loses leadership.
+		client.queueMetrics.Stop()
+
+		value, ok := recorder.value(rivermetrics.MetricQueueJobs, "/queue1/available")
+		require.True(t, ok)
+		require.InDelta(t, 0, value, 0.001)
+	})
+}
@@ -5,8 +5,10 @@ import (
 	"context"
 	"encoding/json"
 	"errors"
+	"fmt"
 	"log/slog"
 	"math"
+	"slices"
 	"strings"
 	"sync"
 	"sync/atomic"
@@ -25,6 +27,9 @@ import (
 	"github.com/riverqueue/river/rivershared/riverpilot"
 	"github.com/riverqueue/river/rivershared/startstop"
 	"github.com/riverqueue/river/rivershared/testsignal"
+	"github.com/riverqueue/river/rivershared/util/hashutil"
+	"github.com/riverqueue/river/rivershared/util/maputil"
+	"github.com/riverqueue/river/rivershared/util/ptrutil"
 	"github.com/riverqueue/river/rivershared/util/randutil"
 	"github.com/riverqueue/river/rivershared/util/serviceutil"
 	"github.com/riverqueue/river/rivershared/util/timeutil"
@@ -37,12 +42,22 @@ const (
 	queueReportIntervalDefault    = 10 * time.Minute
 )
 
+// Bounds on how many of a queue's most urgent available jobs are considered
+// by a fetch with a partition limit, being the larger of a fixed minimum and a
+// multiple of the number of jobs to fetch. Jobs in partitions already at their
+// limit don't count towards it.
+const (
+	partitionCandidateMaxMin  = 1_000
+	partitionCandidatesPerJob = 10
+)
+
 // Test-only properties.
 type producerTestSignals struct {
 	DeletedExpiredQueueRecords testsignal.TestSignal[struct{}] // notifies when the producer deletes expired queue records
 	MetadataChanged            testsignal.TestSignal[struct{}] // notifies when the producer detects a metadata change
 	Paused                     testsignal.TestSignal[struct{}] // notifies when the producer is paused
 	PolledQueueConfig          testsignal.TestSignal[struct{}] // notifies when the producer polls for queue settings
+	RenewedJobLeases           testsignal.TestSignal[struct{}] // notifies when the producer renews leases on running jobs
 	ReportedProducerStatus     testsignal.TestSignal[struct{}] // notifies when the producer reports its own status
 	ReportedQueueStatus        testsignal.TestSignal[struct{}] // notifies when the producer reports queue status
 	Resumed                    testsignal.TestSignal[struct{}] // notifies when the producer is resumed
@@ -54,6 +69,7 @@ func (ts *producerTestSignals) Init() {
 	ts.MetadataChanged.Init()
 	ts.Paused.Init()
 	ts.PolledQueueConfig.Init()
+	ts.RenewedJobLeases.Init()
 	ts.ReportedQueueStatus.Init()
 	ts.ReportedProducerStatus.Init()
 	ts.Resumed.Init()
@@ -61,10 +77,23 @@ func (ts *producerTestSignals) Init() {
 }
 
 type producerConfig struct {
-	ClientID     string
-	Completer    jobcompleter.JobCompleter
+	// AdvisoryLockPrefix is used to build the advisory lock key that serializes
+	// fetches when the queue has a global concurrency limit.
+	AdvisoryLockPrefix int32
+
+	ClientID  string
+	Completer jobcompleter.JobCompleter
+
+	// Concurrency is the queue's configured concurrency limits, which may be
+	// overridden through queue metadata. May be nil.
+	Concurrency *ConcurrencyConfig
+
 	ErrorHandler ErrorHandler
 
+	// FairFetch shares fetches between the partition keys of the queue's jobs
+	// in round robin fashion instead of fetching strictly in order.
+	FairFetch bool
+
 	// FetchCooldown is the minimum amount of time to wait between fetches of new
 	// jobs. Jobs will only be fetched *at most* this often, but if no new jobs
 	// are coming in via LISTEN/NOTIFY then fetches may be delayed as long as
@@ -76,8 +105,18 @@ type producerConfig struct {
 	// LISTEN/NOTIFY, but this provides a fallback.
 	FetchPollInterval time.Duration
 
-	HookLookupByJob        *hooklookup.JobHookLookup
-	HookLookupGlobal       hooklookup.HookLookupInterface
+	HookLookupByJob  *hooklookup.JobHookLookup
+	HookLookupGlobal hooklookup.HookLookupInterface
+
+	// JobEventCallback gets called when a job is started or when a running
+	// job flushes progress updates so that an event can be emitted to
+	// subscriptions. May be nil.
+	JobEventCallback func(event *Event)
+
+	// JobLeaseDuration is the duration of the leases held on running jobs,
+	// which are renewed every third of the duration. Zero disables leases.
+	JobLeaseDuration time.Duration
+
 	JobTimeout             time.Duration
 	MaxWorkers             int
 	MiddlewareLookupGlobal middlewarelookup.MiddlewareLookupInterface
@@ -89,6 +128,10 @@ type producerConfig struct {
 	// of the producer status.
 	ProducerReportInterval time.Duration
 
+	// ProgressFlushInterval is the interval at which running jobs flush
+	// progress updates recorded with RecordProgress or Checkpoint.
+	ProgressFlushInterval time.Duration
+
 	Queue string
 	// QueueEventCallback gets called when a queue's config changes (such as
 	// pausing or resuming) events can be emitted to subscriptions.
@@ -100,7 +143,16 @@ type producerConfig struct {
 	QueuePollInterval time.Duration
 	// QueueReportInterval is the amount of time between periodic reports
 	// of the queue status.
-	QueueReportInterval          time.Duration
+	QueueReportInterval time.Duration
+
+	// RateLimit is a rate limit on how many of the queue's jobs may be started.
+	// May be nil.
+	RateLimit *rateLimit
+
+	// RateLimitsByKind are rate limits on how many jobs of particular kinds may
+	// be started. Shared between all of a client's producers.
+	RateLimitsByKind map[string]*rateLimit
+
 	RetryPolicy                  ClientRetryPolicy
 	SchedulerInterval            time.Duration
 	Schema                       string
@@ -121,6 +173,9 @@ func (c *producerConfig) mustValidate() *producerConfig {
 	if c.FetchPollInterval <= 0 {
 		panic("producerConfig.FetchPollInterval must be greater than zero")
 	}
+	if c.JobLeaseDuration < 0 {
+		panic("producerConfig.JobLeaseDuration must be greater or equal to zero")
+	}
 	if c.JobTimeout < -1 {
 		panic("producerConfig.JobTimeout must be greater or equal to zero")
 	}
@@ -175,7 +230,14 @@ type producer struct {
 	// Jobs which are currently being worked. Only used by main goroutine.
 	activeJobs map[int64]*jobexecutor.JobExecutor
 
-	completer    jobcompleter.JobCompleter
+	completer jobcompleter.JobCompleter
+
+	// Concurrency limits in effect for the queue, which are the configured
+	// limits unless they've been overridden in queue metadata. Written and read
+	// by the main goroutine, and read by the dispatcher while the main
+	// goroutine waits on it.
+	concurrency *ConcurrencyConfig
+
 	config       *producerConfig
 	id           atomic.Int64 // atomic because it's written at startup and read during shutdown
 	exec         riverdriver.Executor
@@ -188,17 +250,33 @@ type producer struct {
 	// main goroutine.
 	cancelCh chan int64
 
+	// Partition key after which the next fair fetch starts so that keys are
+	// fetched from in round robin order across fetches. Nil to start from the
+	// first key. Only used by the dispatcher, which never runs concurrently
+	// with itself.
+	fairFetchCursor *string
+
 	// Set to true when the producer thinks it should trigger another fetch as
 	// soon as slots are available. This is written and read by the main
 	// goroutine.
 	fetchWhenSlotsAreAvailable bool
 
+	// Advisory lock key used to serialize fetches across clients when the
+	// queue has a global concurrency limit.
+	globalLimitLockKey int64
+
 	// Receives completed jobs from workers. Written by completed workers, only
 	// read from main goroutine.
 	jobResultCh chan *rivertype.JobRow
 
 	jobTimeout time.Duration
 
+	// IDs of jobs being worked whose leases are renewed. Unlike activeJobs,
+	// it's read by the lease renewal goroutine, so it's protected by a mutex.
+	// Only maintained if leases are enabled.
+	leasedJobIDs   map[int64]struct{}
+	leasedJobIDsMu sync.Mutex
+
 	// An atomic count of the number of jobs actively being worked on. This is
 	// written to by the main goroutine, but read by the dispatcher.
 	numJobsActive atomic.Int32
@@ -225,19 +303,25 @@ func newProducer(archetype *baseservice.Archetype, exec riverdriver.Executor, pi
 		errorHandler = &errorHandlerAdapter{config.ErrorHandler}
 	}
 
+	globalLimitLockHash := hashutil.NewAdvisoryLockHash(config.AdvisoryLockPrefix)
+	globalLimitLockHash.Write([]byte("global_limit_fetch=" + config.Queue))
+
 	return baseservice.Init(archetype, &producer{
-		activeJobs:     make(map[int64]*jobexecutor.JobExecutor),
-		cancelCh:       make(chan int64, 1000),
-		completer:      config.Completer,
-		config:         config.mustValidate(),
-		exec:           exec,
-		errorHandler:   errorHandler,
-		jobResultCh:    make(chan *rivertype.JobRow, config.MaxWorkers),
-		jobTimeout:     config.JobTimeout,
-		pilot:          pilot,
-		queueControlCh: make(chan *controlEventPayload, 100),
-		retryPolicy:    config.RetryPolicy,
-		workers:        config.Workers,
+		activeJobs:         make(map[int64]*jobexecutor.JobExecutor),
+		cancelCh:           make(chan int64, 1000),
+		completer:          config.Completer,
+		concurrency:        config.Concurrency,
+		config:             config.mustValidate(),
+		exec:               exec,
+		errorHandler:       errorHandler,
+		globalLimitLockKey: globalLimitLockHash.Key(),
+		jobResultCh:        make(chan *rivertype.JobRow, config.MaxWorkers),
+		jobTimeout:         config.JobTimeout,
+		leasedJobIDs:       make(map[int64]struct{}),
+		pilot:              pilot,
+		queueControlCh:     make(chan *controlEventPayload, 100),
+		retryPolicy:        config.RetryPolicy,
+		workers:            config.Workers,
 	})
 }
 
@@ -300,6 +384,7 @@ func (p *producer) StartWorkContext(fetchCtx, workCtx context.Context) error {
 		initialMetadata = fetchedQueue.Metadata
 	}
 	p.paused = initiallyPaused
+	p.updateConcurrencyFromQueueMetadata(fetchCtx, initialMetadata)
 
 	id := p.id.Load()
 	id, p.state, err = p.pilot.ProducerInit(fetchCtx, p.exec, &riverpilot.ProducerInitParams{
@@ -388,6 +473,13 @@ func (p *producer) StartWorkContext(fetchCtx, workCtx context.Context) error {
 		go p.reportQueueStatusLoop(subroutineCtx, &subroutineWg)
 		go p.reportProducerStatusLoop(subroutineCtx, &subroutineWg)
 
+		// Subroutines are only stopped once all jobs have finished executing,
+		// so leases continue to be renewed while jobs finish during shutdown.
+		if p.config.JobLeaseDuration > 0 {
+			subroutineWg.Add(1)
+			go p.renewJobLeasesLoop(subroutineCtx, &subroutineWg)
+		}
+
 		p.fetchAndRunLoop(fetchCtx, workCtx, fetchLimiter)
 		p.Logger.Debug(p.Name+": Entering shutdown loop", slog.String("queue", p.config.Queue), slog.Int64("id", p.id.Load()))
 		p.executorShutdownLoop()
@@ -502,6 +594,7 @@ func (p *producer) fetchAndRunLoop(fetchCtx, workCtx context.Context, fetchLimit
 				p.Logger.DebugContext(workCtx, p.Name+": Unhandled queue control action", "action", msg.Action)
 			case controlActionMetadataChanged:
 				p.Logger.DebugContext(workCtx, p.Name+": Queue metadata changed", slog.String("queue", p.config.Queue), slog.String("queue_in_message", msg.Queue))
+				p.updateConcurrencyFromQueueMetadata(workCtx, msg.Metadata)
 				p.testSignals.MetadataChanged.Signal(struct{}{})
 				if err := p.pilot.QueueMetadataChanged(workCtx, p.exec, p.state, msg.Metadata); err != nil {
 					p.Logger.ErrorContext(workCtx, p.Name+": Error updating queue metadata with pilot", slog.String("queue", p.config.Queue), slog.String("err", err.Error()))
@@ -654,10 +747,23 @@ func (p *producer) finalizeShutdown(ctx context.Context) {
 func (p *producer) addActiveJob(id int64, executor *jobexecutor.JobExecutor) {
 	p.numJobsActive.Add(1)
 	p.activeJobs[id] = executor
+
+	if p.config.JobLeaseDuration > 0 {
+		p.leasedJobIDsMu.Lock()
+		p.leasedJobIDs[id] = struct{}{}
+		p.leasedJobIDsMu.Unlock()
+	}
 }
 
 func (p *producer) removeActiveJob(job *rivertype.JobRow) {
 	delete(p.activeJobs, job.ID)
+
+	if p.config.JobLeaseDuration > 0 {
+		p.leasedJobIDsMu.Lock()
+		delete(p.leasedJobIDs, job.ID)
+		p.leasedJobIDsMu.Unlock()
+	}
+
 	p.numJobsActive.Add(-1)
 	p.numJobsRan.Add(1)
 	p.state.JobFinish(job)
@@ -681,13 +787,40 @@ func (p *producer) dispatchWork(workCtx context.Context, count int, fetchResultC
 	// back to the queue.
 	ctx := context.WithoutCancel(workCtx)
 
-	jobs, err := p.pilot.JobGetAvailable(ctx, p.exec, p.state, &riverdriver.JobGetAvailableParams{
-		ClientID:   p.config.ClientID,
-		Max:        count,
-		Queue:      p.config.Queue,
-		ProducerID: p.id.Load(),
-		Schema:     p.config.Schema,
-	})
+	params := &riverdriver.JobGetAvailableParams{
+		ClientID:           p.config.ClientID,
+		GlobalLimitLockKey: p.globalLimitLockKey,
+		Max:                count,
+		Queue:              p.config.Queue,
+		ProducerID:         p.id.Load(),
+		Schema:             p.config.Schema,
+	}
+	// Leases are taken as part of the fetch so that fetched jobs are recovered
+	// quickly if this client crashes, and are renewed afterwards by
+	// renewJobLeasesLoop.
+	if p.config.JobLeaseDuration > 0 {
+		params.LeaseExpiresAt = ptrutil.Ptr(p.Time.NowUTC().Add(p.config.JobLeaseDuration))
+	}
+	if p.concurrency != nil {
+		params.GlobalLimit = p.concurrency.GlobalLimit
+		params.PartitionByArgs = p.concurrency.Partition.ByArgs
+		params.PartitionByKind = p.concurrency.Partition.ByKind
+		params.PartitionLimit = p.concurrency.Partition.Limit
+
+		if params.PartitionLimit > 0 {
+			params.PartitionCandidateMax = max(partitionCandidateMaxMin, count*partitionCandidatesPerJob)
+		}
+	}
+
+	var (
+		jobs []*rivertype.JobRow
+		err  error
+	)
+	if p.config.FairFetch {
+		jobs, err = p.fetchFair(ctx, params)
+	} else {
+		jobs, err = p.fetch(ctx, params)
+	}
 	if err != nil {
 		p.Logger.Error(p.Name+": Error fetching jobs", slog.String("err", err.Error()), slog.String("queue", p.config.Queue))
 		fetchResultCh <- producerFetchResult{err: err}
@@ -697,6 +830,282 @@ func (p *producer) dispatchWork(workCtx context.Context, count int, fetchResultC
 	fetchResultCh <- producerFetchResult{jobs: jobs}
 }
 
+// fetch fetches up to params.Max available jobs, going through rate limiting if
+// any rate limits are configured.
+func (p *producer) fetch(ctx context.Context, params *riverdriver.JobGetAvailableParams) ([]*rivertype.JobRow, error) {
+	if p.config.RateLimit == nil && len(p.config.RateLimitsByKind) < 1 {
+		return p.pilot.JobGetAvailable(ctx, p.exec, p.state, params)
+	}
+	return p.fetchRateLimited(ctx, params)
+}
+
+// fetchFair fetches up to params.Max jobs fairly across the partition keys of
+// the queue's jobs. Each fetch caps keys at an even share of its capacity, so
+// keys with fewer jobs than their share leave some of it unused. The unused
+// capacity is fetched again, which moves on to keys that still have jobs or
+// to the next priority, until params.Max jobs are fetched or a fetch comes
+// back empty.
+func (p *producer) fetchFair(ctx context.Context, params *riverdriver.JobGetAvailableParams) ([]*rivertype.JobRow, error) {
+	var jobs []*rivertype.JobRow
+
+	for len(jobs) < params.Max {
+		fetchParams := *params
+		fetchParams.Max = params.Max - len(jobs)
+
+		fetchedJobs, err := func() ([]*rivertype.JobRow, error) {
+			if err := p.setFairKeys(ctx, &fetchParams); err != nil {
+				return nil, fmt.Errorf("error fetching partition keys: %w", err)
+			}
+			return p.fetch(ctx, &fetchParams)
+		}()
+		if err != nil {
+			// Jobs from previous fetches are already running, so return them to
+			// be worked instead of stranding them until they're rescued.
+			if len(jobs) > 0 {
+				p.Logger.Error(p.Name+": Error topping up fair fetch", slog.String("err", err.Error()), slog.String("queue", p.config.Queue))
+				break
+			}
+			return nil, err
+		}
+
+		jobs = append(jobs, fetchedJobs...)
+
+		// Without partition keys the fetch wasn't capped per key, so there's
+		// no unused capacity left to top up.
+		if len(fetchedJobs) < 1 || len(fetchParams.FairKeys) < 1 {
+			break
+		}
+	}
+
+	return jobs, nil
+}
+
+// setFairKeys configures params to fetch fairly from the partition keys of the
+// queue's most urgent jobs, continuing on from where the previous fetch left
+// off. Up to params.Max keys are used so that each gets at least one job, with
+// any others left for subsequent fetches. If there are no keys, params are left
+// as they are, which falls back to a normal fetch.
+func (p *producer) setFairKeys(ctx context.Context, params *riverdriver.JobGetAvailableParams) error {
+	result, err := p.exec.JobGetAvailableFairKeys(ctx, &riverdriver.JobGetAvailableFairKeysParams{
+		After:  p.fairFetchCursor,
+		Max:    params.Max,
+		Now:    params.Now,
+		Queue:  p.config.Queue,
+		Schema: p.config.Schema,
+	})
+	if err != nil {
+		return err
+	}
+
+	// Wrap around to the first keys if the last one was reached. Keys found
+	// before wrapping may be found again if there are fewer than params.Max.
+	if len(result.FairKeys) < params.Max && p.fairFetchCursor != nil {
+		wrappedResult, err := p.exec.JobGetAvailableFairKeys(ctx, &riverdriver.JobGetAvailableFairKeysParams{
+			Max:    params.Max - len(result.FairKeys),
+			Now:    params.Now,
+			Queue:  p.config.Queue,
+			Schema: p.config.Schema,
+		})
+		if err != nil {
+			return err
+		}
+
+		switch {
+		case len(result.FairKeys) < 1:
+			result = wrappedResult
+		case wrappedResult.Priority == result.Priority:
+			seen := make(map[string]struct{}, len(result.FairKeys))
+			for _, key := range result.FairKeys {
+				seen[key] = struct{}{}
+			}
+			for _, key := range wrappedResult.FairKeys {
+				if _, ok := seen[key]; !ok {
+					result.FairKeys = append(result.FairKeys, key)
+				}
+			}
+		}
+	}
+
+	if len(result.FairKeys) < 1 {
+		p.fairFetchCursor = nil
+		return nil
+	}
+
+	params.FairKeys = result.FairKeys
+	params.FairMaxPerKey = (params.Max + len(result.FairKeys) - 1) / len(result.FairKeys)
+	params.FairPriority = result.Priority
+	p.fairFetchCursor = &result.FairKeys[len(result.FairKeys)-1]
+
+	return nil
+}
+
+// fetchRateLimited fetches jobs while enforcing configured rate limits. Tokens
+// are reserved from the queue's bucket and the buckets of limited kinds with
+// available jobs before fetching so that the fetch can be capped accordingly,
+// then any that went unused because fewer jobs were available are given back.
+// Global limits are enforced in a transaction which keeps their buckets locked
+// for the duration of the fetch.
+func (p *producer) fetchRateLimited(ctx context.Context, params *riverdriver.JobGetAvailableParams) ([]*rivertype.JobRow, error) {
+	type reservation struct {
+		limit    *rateLimit
+		reserved int
+		used     int
+	}
+
+	// Sort kinds so that buckets of global limits are always locked in the
+	// same order, avoiding deadlocks between concurrent fetches.
+	kinds := maputil.Keys(p.config.RateLimitsByKind)
+	slices.Sort(kinds)
+
+	hasGlobal := p.config.RateLimit != nil && p.config.RateLimit.local == nil
+	for _, kind := range kinds {
+		hasGlobal = hasGlobal || p.config.RateLimitsByKind[kind].local == nil
+	}
+
+	var (
+		exec = p.exec
+		tx   riverdriver.ExecutorTx
+	)
+	if hasGlobal {
+		var err error
+		if tx, err = p.exec.Begin(ctx); err != nil {
+			return nil, err
+		}
+		defer tx.Rollback(ctx)
+		exec = tx
+	}
+
+	var reservations []*reservation
+
+	// Local reservations are given back in full in case of error, and
+	// otherwise only the unused portion is.
+	var fetchSucceeded bool
+	defer func() {
+		for _, res := range reservations {
+			if res.limit.local == nil {
+				continue
+			}
+			if fetchSucceeded {
+				res.limit.local.Return(res.reserved - res.used)
+			} else {
+				res.limit.local.Return(res.reserved)
+			}
+		}
+	}()
+
+	reserve := func(limit *rateLimit, maxTokens int) (*reservation, error) {
+		res := &reservation{limit: limit}
+		if limit.local != nil {
+			res.reserved = limit.local.Reserve(maxTokens)
+		} else {
+			tokens, err := exec.RateLimitRefill(ctx, &riverdriver.RateLimitRefillParams{
+				Burst:         limit.config.Limit,
+				Key:           limit.key,
+				RatePerSecond: limit.ratePerSecond(),
+				Schema:        p.config.Schema,
+			})
+			if err != nil {
+				return nil, err
+			}
+			res.reserved = min(maxTokens, int(tokens))
+		}
+		reservations = append(reservations, res)
+		return res, nil
+	}
+
+	var queueReservation *reservation
+	if p.config.RateLimit != nil {
+		var err error
+		if queueReservation, err = reserve(p.config.RateLimit, params.Max); err != nil {
+			return nil, err
+		}
+		params.Max = queueReservation.reserved
+	}
+
+	if params.Max <= 0 {
+		fetchSucceeded = true
+		return nil, nil
+	}
+
+	// Tokens are only reserved for kinds that have jobs available so that a
+	// fetch doesn't hold tokens (or lock global buckets) for kinds it can't
+	// fetch, which would starve other queues working those kinds. Kinds
+	// without available jobs are capped at zero in case some become available
+	// before the fetch, leaving them for the next one.
+	availableKinds, err := exec.JobGetAvailableKinds(ctx, &riverdriver.JobGetAvailableKindsParams{
+		Kinds:  kinds,
+		Now:    params.Now,
+		Queue:  p.config.Queue,
+		Schema: p.config.Schema,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	kindReservations := make(map[string]*reservation, len(availableKinds))
+	params.MaxByKind = make(map[string]int, len(kinds))
+	for _, kind := range kinds {
+		params.MaxByKind[kind] = 0
+	}
+	for _, kind := range availableKinds {
+		res, err := reserve(p.config.RateLimitsByKind[kind], params.Max)
+		if err != nil {
+			return nil, err
+		}
+		kindReservations[kind] = res
+		params.MaxByKind[kind] = res.reserved
+	}
+
+	jobs, err := p.pilot.JobGetAvailable(ctx, exec, p.state, params)
+	if err != nil {
+		return nil, err
+	}
+
+	if queueReservation != nil {
+		queueReservation.used = len(jobs)
+	}
+	for _, job := range jobs {
+		if res, ok := kindReservations[job.Kind]; ok {
+			res.used++
+		}
+	}
+
+	if tx != nil {
+		for _, res := range reservations {
+			if res.limit.local != nil || res.used < 1 {
+				continue
+			}
+			if err := tx.RateLimitTake(ctx, &riverdriver.RateLimitTakeParams{
+				Key:    res.limit.key,
+				Schema: p.config.Schema,
+				Tokens: res.used,
+			}); err != nil {
+				return nil, err
+			}
+		}
+
+		if err := tx.Commit(ctx); err != nil {
+			return nil, err
+		}
+	}
+
+	fetchSucceeded = true
+	return jobs, nil
+}
+
+// updateConcurrencyFromQueueMetadata sets the concurrency limits in effect for
+// the queue from an override in queue metadata, falling back to the configured
+// limits if there isn't one. Metadata that can't be parsed is logged and
+// leaves the limits in effect unchanged.
+func (p *producer) updateConcurrencyFromQueueMetadata(ctx context.Context, metadata []byte) {
+	concurrency, err := concurrencyFromQueueMetadata(metadata, p.config.Concurrency)
+	if err != nil {
+		p.Logger.ErrorContext(ctx, p.Name+": Error reading concurrency limits from queue metadata", slog.String("queue", p.config.Queue), slog.String("err", err.Error()))
+		return
+	}
+	p.concurrency = concurrency
+}
+
 // Periodically logs an informational log line giving some insight into the
 // current state of the producer.
 func (p *producer) heartbeatLogLoop(ctx context.Context, wg *sync.WaitGroup) {
@@ -751,12 +1160,19 @@ func (p *producer) startNewExecutors(workCtx context.Context, jobs []*rivertype.
 			MiddlewareLookupGlobal:   p.config.MiddlewareLookupGlobal,
 			InformProducerDoneFunc:   p.handleWorkerDone,
 			JobRow:                   job,
+			ProgressExec:             p.exec,
+			ProgressFlushInterval:    p.config.ProgressFlushInterval,
+			ProgressFlushedFunc:      p.handleProgressFlushed,
 			SchedulerInterval:        p.config.SchedulerInterval,
 			Schema:                   p.config.Schema,
 			WorkUnit:                 workUnit,
 		})
 		p.addActiveJob(job.ID, executor)
 
+		if p.config.JobEventCallback != nil {
+			p.config.JobEventCallback(&Event{Kind: EventKindJobStarted, Job: job})
+		}
+
 		go executor.Execute(jobCtx)
 	}
 
@@ -769,6 +1185,12 @@ func (p *producer) maxJobsToFetch() int {
 	return p.config.MaxWorkers - int(p.numJobsActive.Load())
 }
 
+func (p *producer) handleProgressFlushed(job *rivertype.JobRow) {
+	if p.config.JobEventCallback != nil {
+		p.config.JobEventCallback(&Event{Kind: EventKindJobProgress, Job: job})
+	}
+}
+
 func (p *producer) handleWorkerDone(job *rivertype.JobRow) {
 	p.jobResultCh <- job
 }
@@ -890,6 +1312,52 @@ func (p *producer) reportProducerStatusOnce(ctx context.Context) {
 	p.testSignals.ReportedProducerStatus.Signal(struct{}{})
 }
 
+// Periodically renews leases on the jobs being worked so that the rescuer
+// doesn't consider them stuck. Only run if leases are enabled.
+func (p *producer) renewJobLeasesLoop(ctx context.Context, wg *sync.WaitGroup) {
+	defer wg.Done()
+
+	ticker := time.NewTicker(p.config.JobLeaseDuration / 3)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			p.leasedJobIDsMu.Lock()
+			jobIDs := maputil.Keys(p.leasedJobIDs)
+			p.leasedJobIDsMu.Unlock()
+
+			if len(jobIDs) > 0 {
+				p.renewJobLeases(ctx, jobIDs)
+			}
+		}
+	}
+}
+
+func (p *producer) renewJobLeases(ctx context.Context, jobIDs []int64) {
+	ctx, cancel := context.WithTimeout(ctx, 10*time.Second)
+	defer cancel()
+
+	p.Logger.DebugContext(ctx, p.Name+": Renewing job leases", slog.Int("num_jobs", len(jobIDs)), slog.String("queue", p.config.Queue))
+	_, err := p.exec.JobLeaseRenewMany(ctx, &riverdriver.JobLeaseRenewManyParams{
+		ExpiresAt: p.Time.NowUTC().Add(p.config.JobLeaseDuration),
+		ID:        jobIDs,
+		Schema:    p.config.Schema,
+	})
+	if err != nil && errors.Is(context.Cause(ctx), startstop.ErrStop) {
+		return
+	}
+	if err != nil {
+		p.Logger.ErrorContext(ctx, p.Name+": Error renewing job leases",
+			slog.String("queue", p.config.Queue),
+			slog.String("err", err.Error()),
+		)
+		return
+	}
+	p.testSignals.RenewedJobLeases.Signal(struct{}{})
+}
+
 func (p *producer) reportQueueStatusLoop(ctx context.Context, wg *sync.WaitGroup) {
 	defer wg.Done()
 
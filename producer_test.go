@@ -5,6 +5,7 @@ import (
 	"encoding/json"
 	"fmt"
 	"slices"
+	"sync"
 	"testing"
 	"time"
 
@@ -508,6 +509,370 @@ func testProducer(t *testing.T, makeProducer func(ctx context.Context, t *testin
 		require.Zero(t, producer.maxJobsToFetch()) // zero because all slots are occupied
 	})
 
+	t.Run("ConcurrencyGlobalLimit", func(t *testing.T) {
+		t.Parallel()
+
+		const (
+			globalLimit    = 5
+			numJobs        = 10
+			numJobsRunning = 2 // simulates jobs running in other clients
+		)
+
+		producer, bundle := setup(t)
+		producer.config.Concurrency = &ConcurrencyConfig{GlobalLimit: globalLimit}
+
+		type JobArgs struct {
+			JobArgsReflectKind[JobArgs]
+		}
+
+		unpauseWorkers := make(chan struct{})
+		defer close(unpauseWorkers)
+
+		AddWorker(bundle.workers, WorkFunc(func(ctx context.Context, job *Job[JobArgs]) error {
+			<-unpauseWorkers
+			return ctx.Err()
+		}))
+
+		for range numJobsRunning {
+			_ = testfactory.Job(ctx, t, bundle.exec, &testfactory.JobOpts{
+				Queue: &bundle.queue,
+				State: ptrutil.Ptr(rivertype.JobStateRunning),
+			})
+		}
+
+		for range numJobs {
+			mustInsert(ctx, t, producer, bundle, &JobArgs{})
+		}
+
+		startProducer(t, ctx, ctx, producer)
+
+		producer.testSignals.StartedExecutors.WaitOrTimeout()
+
+		// Only enough jobs to reach the global limit were started, even though
+		// the producer has plenty of free worker slots.
+		require.Equal(t, globalLimit-numJobsRunning, int(producer.numJobsActive.Load()))
+	})
+
+	t.Run("ConcurrencyPartitionLimit", func(t *testing.T) {
+		t.Parallel()
+
+		const (
+			numCustomers       = 3
+			numJobsPerCustomer = 5
+			partitionLimit     = 2
+		)
+
+		producer, bundle := setup(t)
+		producer.config.Concurrency = &ConcurrencyConfig{Partition: PartitionConfig{ByArgs: true, Limit: partitionLimit}}
+
+		type JobArgs struct {
+			JobArgsReflectKind[JobArgs]
+			CustomerID int `json:"customer_id" river:"partition"`
+		}
+
+		unpauseWorkers := make(chan struct{})
+		defer close(unpauseWorkers)
+
+		var (
+			jobsStartedByCustomer   = make(map[int]int)
+			jobsStartedByCustomerMu sync.Mutex
+		)
+		AddWorker(bundle.workers, WorkFunc(func(ctx context.Context, job *Job[JobArgs]) error {
+			jobsStartedByCustomerMu.Lock()
+			jobsStartedByCustomer[job.Args.CustomerID]++
+			jobsStartedByCustomerMu.Unlock()
+
+			<-unpauseWorkers
+			return ctx.Err()
+		}))
+
+		// The producer has plenty of free worker slots, so without the partition
+		// limit every one of these jobs would be started at once.
+		for customerID := range numCustomers {
+			for range numJobsPerCustomer {
+				mustInsert(ctx, t, producer, bundle, &JobArgs{CustomerID: customerID})
+			}
+		}
+
+		startProducer(t, ctx, ctx, producer)
+
+		producer.testSignals.StartedExecutors.WaitOrTimeout()
+
+		require.Equal(t, numCustomers*partitionLimit, int(producer.numJobsActive.Load()))
+
+		// Every customer gets a share of the workers.
+		require.Eventually(t, func() bool {
+			jobsStartedByCustomerMu.Lock()
+			defer jobsStartedByCustomerMu.Unlock()
+			return len(jobsStartedByCustomer) == numCustomers
+		}, 5*time.Second, 10*time.Millisecond)
+
+		jobsStartedByCustomerMu.Lock()
+		defer jobsStartedByCustomerMu.Unlock()
+		for customerID := range numCustomers {
+			require.Equal(t, partitionLimit, jobsStartedByCustomer[customerID])
+		}
+	})
+
+	t.Run("ConcurrencyOverriddenByQueueMetadata", func(t *testing.T) {
+		t.Parallel()
+
+		producer, bundle := setup(t)
+		producer.config.Concurrency = &ConcurrencyConfig{GlobalLimit: 5}
+		producer.config.QueuePollInterval = 50 * time.Millisecond
+
+		startProducer(t, ctx, ctx, producer)
+
+		metadata := []byte(`{"river:concurrency":{"global_limit":2}}`)
+		_, err := bundle.exec.QueueUpdate(ctx, &riverdriver.QueueUpdateParams{
+			Metadata:         metadata,
+			MetadataDoUpdate: true,
+			Name:             producer.config.Queue,
+			Schema:           producer.config.Schema,
+		})
+		require.NoError(t, err)
+
+		if producer.config.Notifier != nil {
+			emitQueueNotification(t, ctx, bundle.exec, producer.config.Queue, "metadata_changed", metadata)
+		}
+
+		producer.testSignals.MetadataChanged.WaitOrTimeout()
+		require.Equal(t, &ConcurrencyConfig{GlobalLimit: 2}, producer.concurrency)
+	})
+
+	testRateLimit := func(t *testing.T, global bool) {
+		t.Helper()
+		t.Parallel()
+
+		const (
+			limit   = 3
+			numJobs = 10
+		)
+
+		producer, bundle := setup(t)
+		producer.config.RateLimit = newRateLimit(bundle.archetype, rateLimitKeyQueue(bundle.queue), &RateLimitConfig{
+			Global: global,
+			Limit:  limit,
+			Period: time.Hour,
+		})
+
+		AddWorker(bundle.workers, &noOpWorker{})
+
+		for range numJobs {
+			mustInsert(ctx, t, producer, bundle, &noOpArgs{})
+		}
+
+		startProducer(t, ctx, ctx, producer)
+
+		for range limit {
+			update := riversharedtest.WaitOrTimeout(t, bundle.jobUpdates)
+			require.Equal(t, rivertype.JobStateCompleted, update.Job.State)
+		}
+
+		// The bucket won't refill for another hour, so no more jobs are
+		// started even though plenty are available.
+		select {
+		case update := <-bundle.jobUpdates:
+			t.Fatalf("Unexpected job update: job=%+v stats=%+v", update.Job, update.JobStats)
+		case <-time.After(500 * time.Millisecond):
+		}
+	}
+
+	t.Run("FairFetch", func(t *testing.T) {
+		t.Parallel()
+
+		const (
+			maxWorkers   = 3
+			numCustomers = 3
+		)
+
+		producer, bundle := setup(t)
+		producer.config.FairFetch = true
+		producer.config.MaxWorkers = maxWorkers
+
+		type JobArgs struct {
+			JobArgsReflectKind[JobArgs]
+			CustomerID int `json:"customer_id" river:"partition"`
+		}
+
+		unpauseWorkers := make(chan struct{})
+		defer close(unpauseWorkers)
+
+		var (
+			jobsStartedByCustomer   = make(map[int]int)
+			jobsStartedByCustomerMu sync.Mutex
+		)
+		AddWorker(bundle.workers, WorkFunc(func(ctx context.Context, job *Job[JobArgs]) error {
+			jobsStartedByCustomerMu.Lock()
+			jobsStartedByCustomer[job.Args.CustomerID]++
+			jobsStartedByCustomerMu.Unlock()
+
+			<-unpauseWorkers
+			return ctx.Err()
+		}))
+
+		// The first customer inserts a large backlog ahead of everyone else,
+		// which would take up every worker slot without fair fetching.
+		for range 10 {
+			mustInsert(ctx, t, producer, bundle, &JobArgs{CustomerID: 0})
+		}
+		for customerID := 1; customerID < numCustomers; customerID++ {
+			mustInsert(ctx, t, producer, bundle, &JobArgs{CustomerID: customerID})
+		}
+
+		startProducer(t, ctx, ctx, producer)
+
+		producer.testSignals.StartedExecutors.WaitOrTimeout()
+
+		require.Eventually(t, func() bool {
+			jobsStartedByCustomerMu.Lock()
+			defer jobsStartedByCustomerMu.Unlock()
+			return len(jobsStartedByCustomer) == numCustomers
+		}, 5*time.Second, 10*time.Millisecond)
+
+		jobsStartedByCustomerMu.Lock()
+		defer jobsStartedByCustomerMu.Unlock()
+		require.Equal(t, map[int]int{0: 1, 1: 1, 2: 1}, jobsStartedByCustomer)
+	})
+
+	t.Run("FairFetchTopsUpUnusedCapacity", func(t *testing.T) {
+		t.Parallel()
+
+		const maxWorkers = 4
+
+		producer, bundle := setup(t)
+		producer.config.FairFetch = true
+		producer.config.MaxWorkers = maxWorkers
+
+		type JobArgs struct {
+			JobArgsReflectKind[JobArgs]
+			CustomerID int `json:"customer_id" river:"partition"`
+		}
+
+		unpauseWorkers := make(chan struct{})
+		defer close(unpauseWorkers)
+
+		AddWorker(bundle.workers, WorkFunc(func(ctx context.Context, job *Job[JobArgs]) error {
+			<-unpauseWorkers
+			return ctx.Err()
+		}))
+
+		// The second customer only has a single job, so it can't use its
+		// share of the fetch. The first customer's jobs should take up the
+		// rest of it.
+		for range 10 {
+			mustInsert(ctx, t, producer, bundle, &JobArgs{CustomerID: 0})
+		}
+		mustInsert(ctx, t, producer, bundle, &JobArgs{CustomerID: 1})
+
+		startProducer(t, ctx, ctx, producer)
+
+		producer.testSignals.StartedExecutors.WaitOrTimeout()
+		require.Equal(t, maxWorkers, int(producer.numJobsActive.Load()))
+	})
+
+	t.Run("JobLeases", func(t *testing.T) {
+		t.Parallel()
+
+		producer, bundle := setup(t)
+		producer.config.JobLeaseDuration = 300 * time.Millisecond
+
+		type JobArgs struct {
+			JobArgsReflectKind[JobArgs]
+		}
+
+		jobStarted := make(chan *rivertype.JobRow)
+		unpauseWorker := make(chan struct{})
+		AddWorker(bundle.workers, WorkFunc(func(ctx context.Context, job *Job[JobArgs]) error {
+			jobStarted <- job.JobRow
+			<-unpauseWorker
+			return nil
+		}))
+
+		mustInsert(ctx, t, producer, bundle, &JobArgs{})
+
+		startProducer(t, ctx, ctx, producer)
+
+		type leaseMetadata struct {
+			Lease struct {
+				Attempt   int       `json:"attempt"`
+				ExpiresAt time.Time `json:"expires_at"`
+			} `json:"river:lease"`
+		}
+
+		// The lease is taken by the fetch itself, so it's already present on
+		// the job as it's handed to the worker.
+		startedJob := riversharedtest.WaitOrTimeout(t, jobStarted)
+
+		var metadata leaseMetadata
+		require.NoError(t, json.Unmarshal(startedJob.Metadata, &metadata))
+		require.Equal(t, 1, metadata.Lease.Attempt)
+		require.WithinDuration(t, time.Now().Add(producer.config.JobLeaseDuration), metadata.Lease.ExpiresAt, 5*time.Second)
+
+		// Then renewed on the heartbeat.
+		producer.testSignals.RenewedJobLeases.WaitOrTimeout()
+
+		job, err := bundle.exec.JobGetByID(ctx, &riverdriver.JobGetByIDParams{ID: startedJob.ID, Schema: producer.config.Schema})
+		require.NoError(t, err)
+
+		metadata = leaseMetadata{}
+		require.NoError(t, json.Unmarshal(job.Metadata, &metadata))
+		require.Equal(t, 1, metadata.Lease.Attempt)
+		require.WithinDuration(t, time.Now().Add(producer.config.JobLeaseDuration), metadata.Lease.ExpiresAt, 5*time.Second)
+
+		close(unpauseWorker)
+
+		update := riversharedtest.WaitOrTimeout(t, bundle.jobUpdates)
+		require.Equal(t, rivertype.JobStateCompleted, update.Job.State)
+	})
+
+	t.Run("RateLimit", func(t *testing.T) { testRateLimit(t, false) })
+	t.Run("RateLimitGlobal", func(t *testing.T) { testRateLimit(t, true) })
+
+	t.Run("RateLimitByKind", func(t *testing.T) {
+		t.Parallel()
+
+		const numJobs = 5
+
+		producer, bundle := setup(t)
+
+		type LimitedArgs struct {
+			JobArgsReflectKind[LimitedArgs]
+		}
+		type UnlimitedArgs struct {
+			JobArgsReflectKind[UnlimitedArgs]
+		}
+
+		AddWorker(bundle.workers, WorkFunc(func(ctx context.Context, job *Job[LimitedArgs]) error { return nil }))
+		AddWorker(bundle.workers, WorkFunc(func(ctx context.Context, job *Job[UnlimitedArgs]) error { return nil }))
+
+		limitedKind := (LimitedArgs{}).Kind()
+		producer.config.RateLimitsByKind = map[string]*rateLimit{
+			limitedKind: newRateLimit(bundle.archetype, rateLimitKeyKind(limitedKind), &RateLimitConfig{Limit: 1, Period: time.Hour}),
+		}
+
+		for range numJobs {
+			mustInsert(ctx, t, producer, bundle, &LimitedArgs{})
+			mustInsert(ctx, t, producer, bundle, &UnlimitedArgs{})
+		}
+
+		startProducer(t, ctx, ctx, producer)
+
+		jobCountsByKind := make(map[string]int)
+		for range numJobs + 1 {
+			update := riversharedtest.WaitOrTimeout(t, bundle.jobUpdates)
+			jobCountsByKind[update.Job.Kind]++
+		}
+
+		select {
+		case update := <-bundle.jobUpdates:
+			t.Fatalf("Unexpected job update: job=%+v stats=%+v", update.Job, update.JobStats)
+		case <-time.After(500 * time.Millisecond):
+		}
+
+		require.Equal(t, map[string]int{limitedKind: 1, (UnlimitedArgs{}).Kind(): numJobs}, jobCountsByKind)
+	})
+
 	t.Run("StartStopStress", func(t *testing.T) {
 		t.Parallel()
 
@@ -0,0 +1,96 @@
+package river
+
+import (
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/riverqueue/river/internal/ratelimit"
+	"github.com/riverqueue/river/rivershared/baseservice"
+)
+
+// RateLimitConfig configures a token bucket rate limit on how many jobs may be
+// started. A bucket holds up to Limit tokens and refills at a steady rate of
+// Limit tokens per Period. Each job fetched for work takes a token, and once
+// the bucket is empty no more jobs are fetched until it refills. Because the
+// bucket starts full, up to Limit jobs may be started in a burst after a
+// period of inactivity.
+//
+// Rate limits are enforced when jobs are fetched rather than when they're
+// worked, so jobs held back by a limit stay available in the database. They're
+// picked up by a subsequent fetch once tokens have refilled, which may take up
+// to Config.FetchPollInterval.
+type RateLimitConfig struct {
+	// Global shares the limit between every client sharing the same database
+	// rather than enforcing it separately in each client. A global limit's
+	// token bucket is stored in the database and locked while fetching, which
+	// serializes fetches that share the limit.
+	//
+	// All clients sharing a global limit should be configured with the same
+	// Limit and Period.
+	Global bool
+
+	// Limit is the maximum number of jobs that may be started per Period, and
+	// the maximum number that may be started in a single burst.
+	//
+	// Must be greater than zero.
+	Limit int
+
+	// Period is the period over which Limit applies.
+	//
+	// Must be greater than zero.
+	Period time.Duration
+}
+
+func (c *RateLimitConfig) validate() error {
+	if c.Limit < 1 {
+		return errors.New("rate limit Limit must be greater than zero")
+	}
+	if c.Period <= 0 {
+		return errors.New("rate limit Period must be greater than zero")
+	}
+
+	return nil
+}
+
+// rateLimit is a rate limit being enforced by a client. Local limits are
+// tracked with an in-memory token bucket, while global limits are tracked with
+// a token bucket in the database under key.
+type rateLimit struct {
+	config *RateLimitConfig
+	key    string
+	local  *ratelimit.TokenBucket // nil for global limits
+}
+
+func newRateLimit(archetype *baseservice.Archetype, key string, config *RateLimitConfig) *rateLimit {
+	limit := &rateLimit{
+		config: config,
+		key:    key,
+	}
+
+	if !config.Global {
+		limit.local = ratelimit.NewTokenBucket(archetype, config.Limit, config.Period)
+	}
+
+	return limit
+}
+
+func (l *rateLimit) ratePerSecond() float64 {
+	return float64(l.config.Limit) / l.config.Period.Seconds()
+}
+
+func rateLimitKeyKind(kind string) string   { return "kind:" + kind }
+func rateLimitKeyQueue(queue string) string { return "queue:" + queue }
+
+func validateRateLimitsByKind(rateLimitsByKind map[string]*RateLimitConfig) error {
+	for kind, config := range rateLimitsByKind {
+		if config == nil {
+			return fmt.Errorf("rate limit for kind %q must not be nil", kind)
+		}
+		if err := config.validate(); err != nil {
+			return fmt.Errorf("invalid rate limit for kind %q: %w", kind, err)
+		}
+	}
+
+	return nil
+}
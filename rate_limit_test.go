@@ -0,0 +1,51 @@
+package river
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/riverqueue/river/rivershared/riversharedtest"
+)
+
+func TestNewRateLimit(t *testing.T) {
+	t.Parallel()
+
+	archetype := riversharedtest.BaseServiceArchetype(t)
+
+	t.Run("Local", func(t *testing.T) {
+		t.Parallel()
+
+		limit := newRateLimit(archetype, rateLimitKeyQueue("default"), &RateLimitConfig{Limit: 10, Period: time.Minute})
+		require.Equal(t, "queue:default", limit.key)
+		require.NotNil(t, limit.local)
+		require.InDelta(t, 10.0/60, limit.ratePerSecond(), 0.0001)
+	})
+
+	t.Run("Global", func(t *testing.T) {
+		t.Parallel()
+
+		limit := newRateLimit(archetype, rateLimitKeyKind("my_kind"), &RateLimitConfig{Global: true, Limit: 10, Period: time.Second})
+		require.Equal(t, "kind:my_kind", limit.key)
+		require.Nil(t, limit.local)
+		require.InDelta(t, 10.0, limit.ratePerSecond(), 0.0001)
+	})
+}
+
+func TestValidateRateLimitsByKind(t *testing.T) {
+	t.Parallel()
+
+	require.NoError(t, validateRateLimitsByKind(nil))
+	require.NoError(t, validateRateLimitsByKind(map[string]*RateLimitConfig{"my_kind": {Limit: 1, Period: time.Second}}))
+
+	require.EqualError(t,
+		validateRateLimitsByKind(map[string]*RateLimitConfig{"my_kind": nil}),
+		`rate limit for kind "my_kind" must not be nil`)
+	require.EqualError(t,
+		validateRateLimitsByKind(map[string]*RateLimitConfig{"my_kind": {Period: time.Second}}),
+		`invalid rate limit for kind "my_kind": rate limit Limit must be greater than zero`)
+	require.EqualError(t,
+		validateRateLimitsByKind(map[string]*RateLimitConfig{"my_kind": {Limit: 1}}),
+		`invalid rate limit for kind "my_kind": rate limit Period must be greater than zero`)
+}
@@ -105,24 +105,138 @@ type Executor interface {
 	// Exec executes raw SQL. Used for migrations.
 	Exec(ctx context.Context, sql string) (struct{}, error)
 
+	// JobBatchCountByState counts the jobs in the batch with the given ID,
+	// grouped by state. States without any jobs are omitted.
+	JobBatchCountByState(ctx context.Context, params *JobBatchCountByStateParams) (map[rivertype.JobState]int, error)
+
+	// JobBatchOnFinishPromote examines pending batch on finish jobs, moving
+	// those whose batch has no remaining unfinalized jobs to available (or
+	// scheduled). Every examined job is returned, including those left
+	// pending, so that callers can page through pending jobs using the largest
+	// returned ID.
+	JobBatchOnFinishPromote(ctx context.Context, params *JobBatchOnFinishPromoteParams) ([]*rivertype.JobRow, error)
+
 	JobCancel(ctx context.Context, params *JobCancelParams) (*rivertype.JobRow, error)
+	JobCancelMany(ctx context.Context, params *JobCancelManyParams) ([]*rivertype.JobRow, error)
+
+	// JobCount counts jobs matching the given where clause, which is built the
+	// same way as the one given to JobList.
+	JobCount(ctx context.Context, params *JobCountParams) (int, error)
+
+	// JobCountByQueueAndState counts jobs in the given states, grouped by queue
+	// and state. Combinations without any jobs are omitted.
+	JobCountByQueueAndState(ctx context.Context, params *JobCountByQueueAndStateParams) ([]*JobCountByQueueAndStateResult, error)
+
 	JobCountByState(ctx context.Context, params *JobCountByStateParams) (int, error)
+
+	// JobCountGrouped counts jobs matching the given where clause, grouped by
+	// the text values of the given expression and ordered by value. The
+	// expression may be a set returning function like `unnest(tags)`, in which
+	// case each job is counted once for every value it produces.
+	JobCountGrouped(ctx context.Context, params *JobCountGroupedParams) ([]*JobCountGroupedResult, error)
+
+	// JobDeadLetterDeleteByID deletes a dead letter by ID, returning it.
+	// Returns rivertype.ErrNotFound if there's no dead letter with the ID.
+	JobDeadLetterDeleteByID(ctx context.Context, params *JobDeadLetterDeleteByIDParams) (*rivertype.DeadLetter, error)
+
+	// JobDeadLetterDeleteMany deletes all dead letters matching every one of
+	// the given filters, returning the number deleted. Empty filters match
+	// all dead letters.
+	JobDeadLetterDeleteMany(ctx context.Context, params *JobDeadLetterDeleteManyParams) (int, error)
+
+	JobDeadLetterGetByID(ctx context.Context, params *JobDeadLetterGetByIDParams) (*rivertype.DeadLetter, error)
+	JobDeadLetterList(ctx context.Context, params *JobDeadLetterListParams) ([]*rivertype.DeadLetter, error)
+
+	// JobDeadLetterMoveBefore moves discarded jobs finalized before the given
+	// horizon out of the jobs table and into the dead letter table, returning
+	// the number moved. Jobs are only deleted once archived, so a job whose ID
+	// is already in the dead letter table is left in place.
+	JobDeadLetterMoveBefore(ctx context.Context, params *JobDeadLetterMoveBeforeParams) (int, error)
+
 	JobDelete(ctx context.Context, params *JobDeleteParams) (*rivertype.JobRow, error)
 	JobDeleteBefore(ctx context.Context, params *JobDeleteBeforeParams) (int, error)
+	JobDeleteMany(ctx context.Context, params *JobDeleteManyParams) ([]*rivertype.JobRow, error)
 	JobGetAvailable(ctx context.Context, params *JobGetAvailableParams) ([]*rivertype.JobRow, error)
+
+	// JobGetAvailableFairKeys gets the distinct partition keys of available
+	// jobs in a queue at the queue's most urgent priority level, ordered and
+	// starting after the given key, for use with JobGetAvailableParams.FairKeys.
+	JobGetAvailableFairKeys(ctx context.Context, params *JobGetAvailableFairKeysParams) (*JobGetAvailableFairKeysResult, error)
+
+	// JobGetAvailableKinds gets which of the given kinds have jobs available to
+	// work in a queue, ordered by kind.
+	JobGetAvailableKinds(ctx context.Context, params *JobGetAvailableKindsParams) ([]string, error)
+
 	JobGetByID(ctx context.Context, params *JobGetByIDParams) (*rivertype.JobRow, error)
 	JobGetByIDMany(ctx context.Context, params *JobGetByIDManyParams) ([]*rivertype.JobRow, error)
 	JobGetByKindMany(ctx context.Context, params *JobGetByKindManyParams) ([]*rivertype.JobRow, error)
+
+	// JobGetFinalizedAfter gets jobs that were finalized (cancelled,
+	// completed, or discarded) after the given finalized at timestamp and ID
+	// cursor, ordered by finalized at and ID.
+	JobGetFinalizedAfter(ctx context.Context, params *JobGetFinalizedAfterParams) ([]*rivertype.JobRow, error)
+
 	JobGetStuck(ctx context.Context, params *JobGetStuckParams) ([]*rivertype.JobRow, error)
 	JobInsertFastMany(ctx context.Context, params *JobInsertFastManyParams) ([]*JobInsertFastResult, error)
 	JobInsertFastManyNoReturning(ctx context.Context, params *JobInsertFastManyParams) (int, error)
 	JobInsertFull(ctx context.Context, params *JobInsertFullParams) (*rivertype.JobRow, error)
+
+	// JobLeaseRenewMany sets a lease expiring at the given time on each of the
+	// given jobs that's still running, returning the number of leases renewed.
+	// Leases are stored in job metadata along with the job's current attempt
+	// and are taken into account by JobGetStuck.
+	JobLeaseRenewMany(ctx context.Context, params *JobLeaseRenewManyParams) (int, error)
+
 	JobList(ctx context.Context, params *JobListParams) ([]*rivertype.JobRow, error)
+
+	// JobMetadataMergeIfRunning merges the given metadata updates into the
+	// metadata of a job, but only if it's still running. Returns
+	// rivertype.ErrNotFound if the job doesn't exist or isn't running.
+	JobMetadataMergeIfRunning(ctx context.Context, params *JobMetadataMergeIfRunningParams) (*rivertype.JobRow, error)
+
 	JobRescueMany(ctx context.Context, params *JobRescueManyParams) (*struct{}, error)
 	JobRetry(ctx context.Context, params *JobRetryParams) (*rivertype.JobRow, error)
+	JobRetryMany(ctx context.Context, params *JobRetryManyParams) ([]*rivertype.JobRow, error)
 	JobSchedule(ctx context.Context, params *JobScheduleParams) ([]*JobScheduleResult, error)
+
+	// JobSequencePromote examines the first pending job of up to Max
+	// sequences whose keys sort after AfterSequenceKey, moving each to
+	// available (or scheduled) if it's at the head of its sequence. A job is at
+	// the head of its sequence when the job before it in the sequence has
+	// completed, or was cancelled or discarded and the job is configured to
+	// continue past discarded jobs. Pending jobs behind the first in their
+	// sequence are never examined because they can't be promoted until it has
+	// been. Every examined job is returned ordered by sequence key, including
+	// those left pending, so that callers can page through sequences using the
+	// key of the last returned job.
+	JobSequencePromote(ctx context.Context, params *JobSequencePromoteParams) ([]*rivertype.JobRow, error)
+
 	JobSetStateIfRunningMany(ctx context.Context, params *JobSetStateIfRunningManyParams) ([]*rivertype.JobRow, error)
+
+	// JobStats returns statistics about jobs in each of the given queues,
+	// optionally limited to a single kind, in a single statement so that
+	// they're consistent with each other. A result is returned for every
+	// queue, ordered by name, including queues without any jobs. Only
+	// unfinalized jobs and jobs finalized since FinalizedSince are counted so
+	// that the cost of the query doesn't grow with the number of jobs
+	// retained.
+	JobStats(ctx context.Context, params *JobStatsParams) ([]*JobStatsResult, error)
+
 	JobUpdate(ctx context.Context, params *JobUpdateParams) (*rivertype.JobRow, error)
+
+	// JobUpdateIfQueued updates a job that's still waiting in the queue
+	// (available, pending, retryable, or scheduled). The job is returned
+	// regardless of whether it was updated, so callers should check its state
+	// to determine whether the update took effect.
+	JobUpdateIfQueued(ctx context.Context, params *JobUpdateIfQueuedParams) (*rivertype.JobRow, error)
+
+	// JobWorkflowPromote examines pending workflow tasks, moving those whose
+	// dependencies have all completed to available (or scheduled), and those
+	// with a cancelled or discarded dependency to cancelled (or discarded).
+	// Every examined task is returned, including those left pending, so that
+	// callers can page through pending tasks using the largest returned ID.
+	JobWorkflowPromote(ctx context.Context, params *JobWorkflowPromoteParams) ([]*rivertype.JobRow, error)
+
 	LeaderAttemptElect(ctx context.Context, params *LeaderElectParams) (bool, error)
 	LeaderAttemptReelect(ctx context.Context, params *LeaderElectParams) (bool, error)
 	LeaderDeleteExpired(ctx context.Context, params *LeaderDeleteExpiredParams) (int, error)
@@ -166,6 +280,16 @@ type Executor interface {
 	QueueResume(ctx context.Context, params *QueueResumeParams) error
 	QueueUpdate(ctx context.Context, params *QueueUpdateParams) (*rivertype.Queue, error)
 
+	// RateLimitRefill refills the token bucket with the given key according to
+	// the time elapsed since it was last refilled, creating it full if it
+	// doesn't exist, and returns the number of tokens available. The bucket is
+	// locked until the end of the current transaction, so it should be called
+	// in a transaction along with RateLimitTake.
+	RateLimitRefill(ctx context.Context, params *RateLimitRefillParams) (float64, error)
+
+	// RateLimitTake removes tokens from the token bucket with the given key.
+	RateLimitTake(ctx context.Context, params *RateLimitTakeParams) error
+
 	// TableExists checks whether a table exists for the schema in the current
 	// search schema.
 	TableExists(ctx context.Context, params *TableExistsParams) (bool, error)
@@ -214,6 +338,18 @@ type ColumnExistsParams struct {
 	Table  string
 }
 
+type JobBatchCountByStateParams struct {
+	BatchID string
+	Schema  string
+}
+
+type JobBatchOnFinishPromoteParams struct {
+	AfterID int64
+	Max     int
+	Now     time.Time
+	Schema  string
+}
+
 type JobCancelParams struct {
 	ID                int64
 	CancelAttemptedAt time.Time
@@ -221,16 +357,85 @@ type JobCancelParams struct {
 	Schema            string
 }
 
+type JobCancelManyParams struct {
+	ID                []int64
+	CancelAttemptedAt time.Time
+	ControlTopic      string
+	Schema            string
+}
+
+type JobCountParams struct {
+	NamedArgs   map[string]any
+	Schema      string
+	WhereClause string
+}
+
+type JobCountByQueueAndStateParams struct {
+	Schema string
+	State  []rivertype.JobState
+}
+
+type JobCountByQueueAndStateResult struct {
+	Count int
+	Queue string
+	State rivertype.JobState
+}
+
 type JobCountByStateParams struct {
 	Schema string
 	State  rivertype.JobState
 }
 
+type JobCountGroupedParams struct {
+	GroupByExpr string
+	NamedArgs   map[string]any
+	Schema      string
+	WhereClause string
+}
+
+type JobCountGroupedResult struct {
+	Count int
+	Value string
+}
+
 type JobDeleteParams struct {
 	ID     int64
 	Schema string
 }
 
+type JobDeadLetterDeleteByIDParams struct {
+	ID     int64
+	Schema string
+}
+
+type JobDeadLetterDeleteManyParams struct {
+	DeadLetteredBefore *time.Time
+	ID                 []int64
+	Kind               []string
+	Queue              []string
+	Schema             string
+}
+
+type JobDeadLetterGetByIDParams struct {
+	ID     int64
+	Schema string
+}
+
+type JobDeadLetterListParams struct {
+	AfterID int64
+	Kind    []string
+	Max     int
+	Queue   []string
+	Schema  string
+}
+
+type JobDeadLetterMoveBeforeParams struct {
+	DiscardedFinalizedAtHorizon time.Time
+	Max                         int
+	Now                         *time.Time
+	Schema                      string
+}
+
 type JobDeleteBeforeParams struct {
 	CancelledFinalizedAtHorizon time.Time
 	CompletedFinalizedAtHorizon time.Time
@@ -239,15 +444,105 @@ type JobDeleteBeforeParams struct {
 	Schema                      string
 }
 
+type JobDeleteManyParams struct {
+	ID     []int64
+	Schema string
+}
+
 type JobGetAvailableParams struct {
-	ClientID   string
-	Max        int
-	Now        *time.Time
+	ClientID string
+
+	// GlobalLimit is the maximum number of jobs in the queue that may be
+	// running at once, including those running in other clients. Fetches for
+	// queues with a global limit must be serialized (see GlobalLimitLockKey)
+	// because running jobs are counted as part of the fetch. Zero means no
+	// limit.
+	GlobalLimit int
+
+	// FairKeys are partition keys as returned by JobGetAvailableFairKeys. When
+	// set, jobs are fetched only from these keys, at most FairMaxPerKey from
+	// each, and only at priority FairPriority. Jobs are interleaved across keys
+	// so that when more are eligible than can be fetched, each key gets a
+	// roughly equal share.
+	FairKeys []string
+
+	// FairMaxPerKey is the maximum number of jobs fetched from each of
+	// FairKeys.
+	FairMaxPerKey int
+
+	// FairPriority is the priority of jobs fetched when FairKeys is set.
+	FairPriority int
+
+	// GlobalLimitLockKey is an advisory lock key used to serialize fetches when
+	// GlobalLimit or PartitionLimit is set.
+	GlobalLimitLockKey int64
+
+	// LeaseExpiresAt is the expiry of a lease taken on each fetched job for
+	// its new attempt, stored in its metadata as part of the fetch. If nil,
+	// no lease is taken.
+	LeaseExpiresAt *time.Time
+
+	Max int
+
+	// MaxByKind limits the number of jobs of particular kinds that may be
+	// fetched, which may be less than Max. Kinds not present aren't limited
+	// beyond Max. A kind mapped to zero won't be fetched at all.
+	MaxByKind map[string]int
+
+	Now *time.Time
+
+	// PartitionCandidateMax is the maximum number of the queue's most urgent
+	// available jobs considered when PartitionLimit is set, bounding the cost
+	// of ranking jobs by partition in a deep queue. Jobs in partitions already
+	// at their limit are excluded before it's applied so that they can't
+	// crowd out other partitions. Zero means no maximum. Not used when
+	// FairKeys is set, in which case only jobs from FairKeys are considered.
+	PartitionCandidateMax int
+
+	// PartitionByArgs partitions jobs for PartitionLimit by the partition key
+	// stored in their metadata.
+	PartitionByArgs bool
+
+	// PartitionByKind partitions jobs for PartitionLimit by kind.
+	PartitionByKind bool
+
+	// PartitionLimit is the maximum number of jobs in any one partition of the
+	// queue that may be running at once, including those running in other
+	// clients. Like GlobalLimit, fetches must be serialized when it's set. Zero
+	// means no limit.
+	PartitionLimit int
+
 	ProducerID int64
 	Queue      string
 	Schema     string
 }
 
+type JobGetAvailableFairKeysParams struct {
+	// After is a partition key after which to start returning keys. If nil,
+	// keys are returned from the beginning.
+	After  *string
+	Max    int
+	Now    *time.Time
+	Queue  string
+	Schema string
+}
+
+type JobGetAvailableFairKeysResult struct {
+	// FairKeys are the returned partition keys, in order.
+	FairKeys []string
+
+	// Priority is the most urgent priority of available jobs in the queue,
+	// which is the priority all returned keys have jobs at.
+	Priority int
+}
+
+type JobGetAvailableKindsParams struct {
+	Kinds  []string
+	Now    *time.Time
+	Queue  string
+	Schema string
+}
+
 type JobGetByIDParams struct {
 	ID     int64
 	Schema string
@@ -263,8 +558,16 @@ type JobGetByKindManyParams struct {
 	Schema string
 }
 
+type JobGetFinalizedAfterParams struct {
+	FinalizedAt time.Time
+	ID          int64
+	Max         int
+	Schema      string
+}
+
 type JobGetStuckParams struct {
 	Max          int
+	Now          time.Time
 	Schema       string
 	StuckHorizon time.Time
 }
@@ -319,6 +622,12 @@ type JobInsertFullParams struct {
 	UniqueStates byte
 }
 
+type JobLeaseRenewManyParams struct {
+	ExpiresAt time.Time
+	ID        []int64
+	Schema    string
+}
+
 type JobListParams struct {
 	Max           int32
 	NamedArgs     map[string]any
@@ -327,6 +636,12 @@ type JobListParams struct {
 	WhereClause   string
 }
 
+type JobMetadataMergeIfRunningParams struct {
+	ID              int64
+	MetadataUpdates []byte
+	Schema          string
+}
+
 type JobRescueManyParams struct {
 	ID          []int64
 	Error       [][]byte
@@ -341,6 +656,11 @@ type JobRetryParams struct {
 	Schema string
 }
 
+type JobRetryManyParams struct {
+	ID     []int64
+	Schema string
+}
+
 type JobScheduleParams struct {
 	Max    int
 	Now    time.Time
@@ -352,6 +672,13 @@ type JobScheduleResult struct {
 	ConflictDiscarded bool
 }
 
+type JobSequencePromoteParams struct {
+	AfterSequenceKey *string
+	Max              int
+	Now              time.Time
+	Schema           string
+}
+
 // JobSetStateIfRunningParams are parameters to update the state of a currently
 // running job. Use one of the constructors below to ensure a correct
 // combination of parameters.
@@ -457,6 +784,35 @@ type JobSetStateIfRunningManyParams struct {
 	State           []rivertype.JobState
 }
 
+type JobStatsParams struct {
+	FinalizedSince time.Time
+	Kind           string // optional; all kinds if empty
+	Queues         []string
+	Schema         string
+}
+
+type JobStatsResult struct {
+	// CountsByState contains the number of jobs in each unfinalized state.
+	// States without any jobs are omitted.
+	CountsByState map[rivertype.JobState]int
+
+	// FinalizedCountsByState contains the number of jobs finalized since
+	// FinalizedSince in each finalized state. States without any jobs are
+	// omitted.
+	FinalizedCountsByState map[rivertype.JobState]int
+
+	// OldestAvailableAt is the earliest scheduled time of any available job,
+	// or nil if there are no available jobs.
+	OldestAvailableAt *time.Time
+
+	// Queue is the name of the queue that statistics are for.
+	Queue string
+
+	// RunningByClient contains the number of running jobs by the ID of the
+	// client working them.
+	RunningByClient map[string]int
+}
+
 type JobUpdateParams struct {
 	ID                  int64
 	AttemptDoUpdate     bool
@@ -478,6 +834,32 @@ type JobUpdateParams struct {
 	UniqueKey []byte
 }
 
+type JobUpdateIfQueuedParams struct {
+	ID                  int64
+	MaxAttemptsDoUpdate bool
+	MaxAttempts         int
+	MetadataDoMerge     bool
+	MetadataDoUpdate    bool
+	Metadata            []byte
+	Now                 *time.Time
+	PriorityDoUpdate    bool
+	Priority            int
+	QueueDoUpdate       bool
+	Queue               string
+	ScheduledAtDoUpdate bool
+	ScheduledAt         time.Time
+	Schema              string
+	TagsDoUpdate        bool
+	Tags                []string
+}
+
+type JobWorkflowPromoteParams struct {
+	AfterID int64
+	Max     int
+	Now     time.Time
+	Schema  string
+}
+
 // Leader represents a River leader.
 //
 // API is not stable. DO NOT USE.
@@ -622,6 +1004,19 @@ type QueueUpdateParams struct {
 	Schema           string
 }
 
+type RateLimitRefillParams struct {
+	Burst         int
+	Key           string
+	RatePerSecond float64
+	Schema        string
+}
+
+type RateLimitTakeParams struct {
+	Key    string
+	Schema string
+	Tokens int
+}
+
 type TableExistsParams struct {
 	Schema string
 	Table  string
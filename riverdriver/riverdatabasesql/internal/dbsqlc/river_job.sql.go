@@ -14,6 +14,131 @@ import (
 	"github.com/riverqueue/river/riverdriver/riverdatabasesql/internal/pgtypealias"
 )
 
+const jobBatchCountByState = `-- name: JobBatchCountByState :many
+SELECT state, count(*)
+FROM /* TEMPLATE: schema */river_job
+WHERE metadata @> jsonb_build_object('river:batch_id', $1::text)
+GROUP BY state
+`
+
+type JobBatchCountByStateRow struct {
+	State RiverJobState
+	Count int64
+}
+
+func (q *Queries) JobBatchCountByState(ctx context.Context, db DBTX, batchID string) ([]*JobBatchCountByStateRow, error) {
+	rows, err := db.QueryContext(ctx, jobBatchCountByState, batchID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []*JobBatchCountByStateRow
+	for rows.Next() {
+		var i JobBatchCountByStateRow
+		if err := rows.Scan(
+			&i.State,
+			&i.Count,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, &i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const jobBatchOnFinishPromote = `-- name: JobBatchOnFinishPromote :many
+WITH pending_on_finish AS (
+    SELECT id, metadata->>'river:batch_on_finish' AS batch_id
+    FROM /* TEMPLATE: schema */river_job
+    WHERE state = 'pending'
+        AND metadata ? 'river:batch_on_finish'
+        AND id > $1::bigint
+    ORDER BY id
+    LIMIT $2::integer
+    FOR UPDATE SKIP LOCKED
+),
+finished_batches AS (
+    SELECT pending_on_finish.id
+    FROM pending_on_finish
+    WHERE NOT EXISTS (
+        SELECT 1
+        FROM /* TEMPLATE: schema */river_job AS batch_job
+        WHERE batch_job.metadata @> jsonb_build_object('river:batch_id', pending_on_finish.batch_id)
+            AND batch_job.state NOT IN ('cancelled', 'completed', 'discarded')
+    )
+),
+updated_jobs AS (
+    UPDATE /* TEMPLATE: schema */river_job
+    SET
+        state = CASE WHEN river_job.scheduled_at > $3::timestamptz THEN 'scheduled'::river_job_state
+                     ELSE 'available'::river_job_state END
+    FROM finished_batches
+    WHERE river_job.id = finished_batches.id
+    RETURNING river_job.id, river_job.args, river_job.attempt, river_job.attempted_at, river_job.attempted_by, river_job.created_at, river_job.errors, river_job.finalized_at, river_job.kind, river_job.max_attempts, river_job.metadata, river_job.priority, river_job.queue, river_job.state, river_job.scheduled_at, river_job.tags, river_job.unique_key, river_job.unique_states
+)
+SELECT id, args, attempt, attempted_at, attempted_by, created_at, errors, finalized_at, kind, max_attempts, metadata, priority, queue, state, scheduled_at, tags, unique_key, unique_states
+FROM /* TEMPLATE: schema */river_job
+WHERE id IN (SELECT id FROM pending_on_finish)
+    AND id NOT IN (SELECT id FROM updated_jobs)
+UNION
+SELECT id, args, attempt, attempted_at, attempted_by, created_at, errors, finalized_at, kind, max_attempts, metadata, priority, queue, state, scheduled_at, tags, unique_key, unique_states
+FROM updated_jobs
+`
+
+type JobBatchOnFinishPromoteParams struct {
+	AfterID int64
+	Max     int32
+	Now     time.Time
+}
+
+func (q *Queries) JobBatchOnFinishPromote(ctx context.Context, db DBTX, arg *JobBatchOnFinishPromoteParams) ([]*RiverJob, error) {
+	rows, err := db.QueryContext(ctx, jobBatchOnFinishPromote, arg.AfterID, arg.Max, arg.Now)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []*RiverJob
+	for rows.Next() {
+		var i RiverJob
+		if err := rows.Scan(
+			&i.ID,
+			&i.Args,
+			&i.Attempt,
+			&i.AttemptedAt,
+			pq.Array(&i.AttemptedBy),
+			&i.CreatedAt,
+			pq.Array(&i.Errors),
+			&i.FinalizedAt,
+			&i.Kind,
+			&i.MaxAttempts,
+			&i.Metadata,
+			&i.Priority,
+			&i.Queue,
+			&i.State,
+			&i.ScheduledAt,
+			pq.Array(&i.Tags),
+			&i.UniqueKey,
+			&i.UniqueStates,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, &i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
 const jobCancel = `-- name: JobCancel :one
 WITH locked_job AS (
     SELECT
@@ -96,6 +221,108 @@ func (q *Queries) JobCancel(ctx context.Context, db DBTX, arg *JobCancelParams)
 	return &i, err
 }
 
+const jobCancelMany = `-- name: JobCancelMany :many
+WITH locked_jobs AS (
+    SELECT
+        id, queue, state, finalized_at
+    FROM /* TEMPLATE: schema */river_job
+    WHERE id = any($1::bigint[])
+        AND state NOT IN ('cancelled', 'completed', 'discarded')
+        AND finalized_at IS NULL
+    ORDER BY id
+    FOR UPDATE
+),
+notification AS (
+    SELECT
+        id,
+        pg_notify(
+            concat(coalesce($2::text, current_schema()), '.', $3::text),
+            json_build_object('action', 'cancel', 'job_id', id, 'queue', queue)::text
+        )
+    FROM
+        locked_jobs
+)
+UPDATE /* TEMPLATE: schema */river_job
+SET
+    -- If the job is actively running, we want to let its current client and
+    -- producer handle the cancellation. Otherwise, immediately cancel it.
+    state = CASE WHEN state = 'running' THEN state ELSE 'cancelled' END,
+    finalized_at = CASE WHEN state = 'running' THEN finalized_at ELSE now() END,
+    -- Mark the job as cancelled by query so that the rescuer knows not to
+    -- rescue it, even if it gets stuck in the running state:
+    metadata = jsonb_set(metadata, '{cancel_attempted_at}'::text[], $4::jsonb, true)
+FROM notification
+WHERE river_job.id = notification.id
+RETURNING river_job.id, river_job.args, river_job.attempt, river_job.attempted_at, river_job.attempted_by, river_job.created_at, river_job.errors, river_job.finalized_at, river_job.kind, river_job.max_attempts, river_job.metadata, river_job.priority, river_job.queue, river_job.state, river_job.scheduled_at, river_job.tags, river_job.unique_key, river_job.unique_states
+`
+
+type JobCancelManyParams struct {
+	ID                []int64
+	Schema            sql.NullString
+	ControlTopic      string
+	CancelAttemptedAt string
+}
+
+func (q *Queries) JobCancelMany(ctx context.Context, db DBTX, arg *JobCancelManyParams) ([]*RiverJob, error) {
+	rows, err := db.QueryContext(ctx, jobCancelMany,
+		pq.Array(arg.ID),
+		arg.Schema,
+		arg.ControlTopic,
+		arg.CancelAttemptedAt,
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []*RiverJob
+	for rows.Next() {
+		var i RiverJob
+		if err := rows.Scan(
+			&i.ID,
+			&i.Args,
+			&i.Attempt,
+			&i.AttemptedAt,
+			pq.Array(&i.AttemptedBy),
+			&i.CreatedAt,
+			pq.Array(&i.Errors),
+			&i.FinalizedAt,
+			&i.Kind,
+			&i.MaxAttempts,
+			&i.Metadata,
+			&i.Priority,
+			&i.Queue,
+			&i.State,
+			&i.ScheduledAt,
+			pq.Array(&i.Tags),
+			&i.UniqueKey,
+			&i.UniqueStates,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, &i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const jobCount = `-- name: JobCount :one
+SELECT count(*)
+FROM /* TEMPLATE: schema */river_job
+WHERE /* TEMPLATE_BEGIN: where_clause */ true /* TEMPLATE_END */
+`
+
+func (q *Queries) JobCount(ctx context.Context, db DBTX) (int64, error) {
+	row := db.QueryRowContext(ctx, jobCount)
+	var count int64
+	err := row.Scan(&count)
+	return count, err
+}
+
 const jobCountByState = `-- name: JobCountByState :one
 SELECT count(*)
 FROM /* TEMPLATE: schema */river_job
@@ -109,6 +336,89 @@ func (q *Queries) JobCountByState(ctx context.Context, db DBTX, state RiverJobSt
 	return count, err
 }
 
+const jobCountByQueueAndState = `-- name: JobCountByQueueAndState :many
+SELECT queue, state, count(*)
+FROM /* TEMPLATE: schema */river_job
+WHERE state = any($1::text[]::river_job_state[])
+GROUP BY queue, state
+ORDER BY queue, state
+`
+
+type JobCountByQueueAndStateRow struct {
+	Queue string
+	State RiverJobState
+	Count int64
+}
+
+func (q *Queries) JobCountByQueueAndState(ctx context.Context, db DBTX, state []string) ([]*JobCountByQueueAndStateRow, error) {
+	rows, err := db.QueryContext(ctx, jobCountByQueueAndState, pq.Array(state))
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []*JobCountByQueueAndStateRow
+	for rows.Next() {
+		var i JobCountByQueueAndStateRow
+		if err := rows.Scan(
+			&i.Queue,
+			&i.State,
+			&i.Count,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, &i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const jobCountGrouped = `-- name: JobCountGrouped :many
+SELECT group_value::text, count(*)
+FROM (
+    SELECT /* TEMPLATE_BEGIN: group_by_expr */ kind /* TEMPLATE_END */ AS group_value
+    FROM /* TEMPLATE: schema */river_job
+    WHERE /* TEMPLATE_BEGIN: where_clause */ true /* TEMPLATE_END */
+) AS filtered_job
+GROUP BY group_value
+ORDER BY group_value
+`
+
+type JobCountGroupedRow struct {
+	GroupValue string
+	Count      int64
+}
+
+func (q *Queries) JobCountGrouped(ctx context.Context, db DBTX) ([]*JobCountGroupedRow, error) {
+	rows, err := db.QueryContext(ctx, jobCountGrouped)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []*JobCountGroupedRow
+	for rows.Next() {
+		var i JobCountGroupedRow
+		if err := rows.Scan(
+			&i.GroupValue,
+			&i.Count,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, &i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
 const jobDelete = `-- name: JobDelete :one
 WITH job_to_delete AS (
     SELECT id
@@ -198,6 +508,62 @@ func (q *Queries) JobDeleteBefore(ctx context.Context, db DBTX, arg *JobDeleteBe
 	return count, err
 }
 
+const jobDeleteMany = `-- name: JobDeleteMany :many
+DELETE FROM /* TEMPLATE: schema */river_job
+WHERE id IN (
+    SELECT id
+    FROM /* TEMPLATE: schema */river_job
+    WHERE id = any($1::bigint[])
+        -- Do not touch running jobs:
+        AND state != 'running'
+    ORDER BY id
+    FOR UPDATE
+)
+RETURNING id, args, attempt, attempted_at, attempted_by, created_at, errors, finalized_at, kind, max_attempts, metadata, priority, queue, state, scheduled_at, tags, unique_key, unique_states
+`
+
+func (q *Queries) JobDeleteMany(ctx context.Context, db DBTX, id []int64) ([]*RiverJob, error) {
+	rows, err := db.QueryContext(ctx, jobDeleteMany, pq.Array(id))
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []*RiverJob
+	for rows.Next() {
+		var i RiverJob
+		if err := rows.Scan(
+			&i.ID,
+			&i.Args,
+			&i.Attempt,
+			&i.AttemptedAt,
+			pq.Array(&i.AttemptedBy),
+			&i.CreatedAt,
+			pq.Array(&i.Errors),
+			&i.FinalizedAt,
+			&i.Kind,
+			&i.MaxAttempts,
+			&i.Metadata,
+			&i.Priority,
+			&i.Queue,
+			&i.State,
+			&i.ScheduledAt,
+			pq.Array(&i.Tags),
+			&i.UniqueKey,
+			&i.UniqueStates,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, &i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
 const jobGetAvailable = `-- name: JobGetAvailable :many
 WITH locked_jobs AS (
     SELECT
@@ -208,11 +574,112 @@ WITH locked_jobs AS (
         state = 'available'
         AND queue = $2::text
         AND scheduled_at <= coalesce($3::timestamptz, now())
+        AND (
+            cardinality($4::text[]) = 0
+            OR NOT kind = any($4::text[])
+            OR id IN (
+                SELECT limited_kind_job.id
+                FROM unnest($4::text[], $5::integer[]) AS limited_kind(kind, max)
+                CROSS JOIN LATERAL (
+                    SELECT id
+                    FROM /* TEMPLATE: schema */river_job
+                    WHERE state = 'available'
+                        AND queue = $2::text
+                        AND kind = limited_kind.kind
+                        AND scheduled_at <= coalesce($3::timestamptz, now())
+                    ORDER BY
+                        priority ASC,
+                        scheduled_at ASC,
+                        id ASC
+                    LIMIT limited_kind.max
+                ) AS limited_kind_job
+            )
+        )
+        AND (
+            $6::integer <= 0
+            OR id IN (
+                SELECT partition_job.id
+                FROM (
+                    SELECT
+                        available_job.id,
+                        row_number() OVER (
+                            PARTITION BY available_job.partition_kind, available_job.partition_args
+                            ORDER BY
+                                available_job.priority ASC,
+                                available_job.scheduled_at ASC,
+                                available_job.id ASC
+                        ) AS partition_rank,
+                        available_job.partition_kind,
+                        available_job.partition_args
+                    FROM (
+                        SELECT
+                            id,
+                            priority,
+                            scheduled_at,
+                            CASE WHEN $7::boolean THEN kind ELSE '' END AS partition_kind,
+                            CASE WHEN $8::boolean THEN coalesce(metadata -> 'river:partition', '{}'::jsonb) ELSE '{}'::jsonb END AS partition_args
+                        FROM /* TEMPLATE: schema */river_job
+                        WHERE state = 'available'
+                            AND queue = $2::text
+                            AND scheduled_at <= coalesce($3::timestamptz, now())
+                            -- Jobs in partitions already at their limit can't
+                            -- be fetched, so they're excluded before the
+                            -- candidate limit below. Otherwise one partition's
+                            -- backlog could fill every candidate and starve the
+                            -- rest of the queue.
+                            AND (
+                                CASE WHEN $7::boolean THEN kind ELSE '' END,
+                                CASE WHEN $8::boolean THEN coalesce(metadata -> 'river:partition', '{}'::jsonb) ELSE '{}'::jsonb END
+                            ) NOT IN (
+                                SELECT
+                                    CASE WHEN $7::boolean THEN kind ELSE '' END,
+                                    CASE WHEN $8::boolean THEN coalesce(metadata -> 'river:partition', '{}'::jsonb) ELSE '{}'::jsonb END
+                                FROM /* TEMPLATE: schema */river_job
+                                WHERE state = 'running'
+                                    AND queue = $2::text
+                                GROUP BY 1, 2
+                                HAVING count(*) >= $6::integer
+                            )
+                        -- Only the queue's most urgent remaining jobs are
+                        -- ranked so that a deep queue doesn't have every
+                        -- available job read and sorted on each fetch.
+                        ORDER BY
+                            priority ASC,
+                            scheduled_at ASC,
+                            id ASC
+                        LIMIT CASE WHEN $12::integer > 0 THEN $12::integer END
+                    ) AS available_job
+                ) AS partition_job
+                LEFT JOIN (
+                    SELECT
+                        CASE WHEN $7::boolean THEN kind ELSE '' END AS partition_kind,
+                        CASE WHEN $8::boolean THEN coalesce(metadata -> 'river:partition', '{}'::jsonb) ELSE '{}'::jsonb END AS partition_args,
+                        count(*) AS running_count
+                    FROM /* TEMPLATE: schema */river_job
+                    WHERE state = 'running'
+                        AND queue = $2::text
+                    GROUP BY 1, 2
+                ) AS partition_running ON partition_running.partition_kind = partition_job.partition_kind
+                    AND partition_running.partition_args = partition_job.partition_args
+                WHERE partition_job.partition_rank <= $6::integer - coalesce(partition_running.running_count, 0)
+            )
+        )
     ORDER BY
         priority ASC,
         scheduled_at ASC,
         id ASC
-    LIMIT $4::integer
+    LIMIT CASE
+        WHEN $9::integer > 0 THEN least(
+            $10::integer,
+            greatest(0, $9::integer - (
+                SELECT count(*)
+                FROM /* TEMPLATE: schema */river_job
+                WHERE state = 'running'
+                    AND queue = $2::text
+            ))
+        )
+        ELSE $10::integer
+    END
     FOR UPDATE
     SKIP LOCKED
 )
@@ -222,7 +689,15 @@ SET
     state = 'running',
     attempt = river_job.attempt + 1,
     attempted_at = now(),
-    attempted_by = array_append(river_job.attempted_by, $1::text)
+    attempted_by = array_append(river_job.attempted_by, $1::text),
+    -- Takes a lease for the new attempt in the same statement when leases are
+    -- enabled so that a job is never running without one.
+    metadata = CASE
+        WHEN $11::timestamptz IS NULL THEN river_job.metadata
+        ELSE river_job.metadata || jsonb_build_object(
+            'river:lease', jsonb_build_object('attempt', river_job.attempt + 1, 'expires_at', $11::timestamptz)
+        )
+    END
 FROM
     locked_jobs
 WHERE
@@ -232,10 +707,18 @@ RETURNING
 `
 
 type JobGetAvailableParams struct {
-	AttemptedBy string
-	Queue       string
-	Now         *time.Time
-	Max         int32
+	AttemptedBy           string
+	Queue                 string
+	Now                   *time.Time
+	LimitedKind           []string
+	LimitedKindMax        []int32
+	PartitionLimit        int32
+	PartitionByKind       bool
+	PartitionByArgs       bool
+	GlobalLimit           int32
+	Max                   int32
+	LeaseExpiresAt        *time.Time
+	PartitionCandidateMax int32
 }
 
 func (q *Queries) JobGetAvailable(ctx context.Context, db DBTX, arg *JobGetAvailableParams) ([]*RiverJob, error) {
@@ -243,7 +726,15 @@ func (q *Queries) JobGetAvailable(ctx context.Context, db DBTX, arg *JobGetAvail
 		arg.AttemptedBy,
 		arg.Queue,
 		arg.Now,
+		pq.Array(arg.LimitedKind),
+		pq.Array(arg.LimitedKindMax),
+		arg.PartitionLimit,
+		arg.PartitionByKind,
+		arg.PartitionByArgs,
+		arg.GlobalLimit,
 		arg.Max,
+		arg.LeaseExpiresAt,
+		arg.PartitionCandidateMax,
 	)
 	if err != nil {
 		return nil, err
@@ -285,6 +776,358 @@ func (q *Queries) JobGetAvailable(ctx context.Context, db DBTX, arg *JobGetAvail
 	return items, nil
 }
 
+const jobGetAvailableFair = `-- name: JobGetAvailableFair :many
+WITH fair_job AS (
+    SELECT
+        fair_candidate.id,
+        fair_candidate.fair_rank
+    FROM unnest($2::text[]) AS fair(key)
+    CROSS JOIN LATERAL (
+        SELECT
+            key_job.id,
+            row_number() OVER (ORDER BY key_job.scheduled_at ASC, key_job.id ASC) AS fair_rank
+        FROM (
+            SELECT id, scheduled_at
+            FROM /* TEMPLATE: schema */river_job
+            WHERE state = 'available'
+                AND queue = $3::text
+                AND priority = $4::smallint
+                AND coalesce(metadata -> 'river:partition', '{}'::jsonb) = fair.key::jsonb
+                AND scheduled_at <= coalesce($5::timestamptz, now())
+            ORDER BY
+                scheduled_at ASC,
+                id ASC
+            LIMIT $6::integer
+        ) AS key_job
+    ) AS fair_candidate
+),
+locked_jobs AS (
+    SELECT
+        river_job.id
+    FROM
+        /* TEMPLATE: schema */river_job
+        INNER JOIN fair_job ON fair_job.id = river_job.id
+    WHERE
+        river_job.state = 'available'
+        AND (
+            cardinality($7::text[]) = 0
+            OR NOT river_job.kind = any($7::text[])
+            OR river_job.id IN (
+                SELECT limited_kind_job.id
+                FROM unnest($7::text[], $8::integer[]) AS limited_kind(kind, max)
+                CROSS JOIN LATERAL (
+                    SELECT id
+                    FROM /* TEMPLATE: schema */river_job
+                    WHERE state = 'available'
+                        AND queue = $3::text
+                        AND kind = limited_kind.kind
+                        AND scheduled_at <= coalesce($5::timestamptz, now())
+                    ORDER BY
+                        priority ASC,
+                        scheduled_at ASC,
+                        id ASC
+                    LIMIT limited_kind.max
+                ) AS limited_kind_job
+            )
+        )
+        AND (
+            $9::integer <= 0
+            OR river_job.id IN (
+                SELECT partition_job.id
+                FROM (
+                    SELECT
+                        available_job.id,
+                        row_number() OVER (
+                            PARTITION BY available_job.partition_kind, available_job.partition_args
+                            ORDER BY
+                                available_job.priority ASC,
+                                available_job.scheduled_at ASC,
+                                available_job.id ASC
+                        ) AS partition_rank,
+                        available_job.partition_kind,
+                        available_job.partition_args
+                    FROM (
+                        SELECT
+                            id,
+                            priority,
+                            scheduled_at,
+                            CASE WHEN $10::boolean THEN kind ELSE '' END AS partition_kind,
+                            CASE WHEN $11::boolean THEN coalesce(metadata -> 'river:partition', '{}'::jsonb) ELSE '{}'::jsonb END AS partition_args
+                        FROM /* TEMPLATE: schema */river_job
+                        -- Only jobs this fetch could take are ranked, which
+                        -- keeps the ranking to at most FairMaxPerKey jobs for
+                        -- each fair key however deep the queue is.
+                        WHERE id IN (SELECT fair_job.id FROM fair_job)
+                    ) AS available_job
+                ) AS partition_job
+                LEFT JOIN (
+                    SELECT
+                        CASE WHEN $10::boolean THEN kind ELSE '' END AS partition_kind,
+                        CASE WHEN $11::boolean THEN coalesce(metadata -> 'river:partition', '{}'::jsonb) ELSE '{}'::jsonb END AS partition_args,
+                        count(*) AS running_count
+                    FROM /* TEMPLATE: schema */river_job
+                    WHERE state = 'running'
+                        AND queue = $3::text
+                    GROUP BY 1, 2
+                ) AS partition_running ON partition_running.partition_kind = partition_job.partition_kind
+                    AND partition_running.partition_args = partition_job.partition_args
+                WHERE partition_job.partition_rank <= $9::integer - coalesce(partition_running.running_count, 0)
+            )
+        )
+    ORDER BY
+        fair_job.fair_rank ASC,
+        river_job.scheduled_at ASC,
+        river_job.id ASC
+    LIMIT CASE
+        WHEN $12::integer > 0 THEN least(
+            $13::integer,
+            greatest(0, $12::integer - (
+                SELECT count(*)
+                FROM /* TEMPLATE: schema */river_job
+                WHERE state = 'running'
+                    AND queue = $3::text
+            ))
+        )
+        ELSE $13::integer
+    END
+    FOR UPDATE OF river_job
+    SKIP LOCKED
+)
+UPDATE
+    /* TEMPLATE: schema */river_job
+SET
+    state = 'running',
+    attempt = river_job.attempt + 1,
+    attempted_at = now(),
+    attempted_by = array_append(river_job.attempted_by, $1::text),
+    -- Takes a lease for the new attempt in the same statement when leases are
+    -- enabled so that a job is never running without one.
+    metadata = CASE
+        WHEN $14::timestamptz IS NULL THEN river_job.metadata
+        ELSE river_job.metadata || jsonb_build_object(
+            'river:lease', jsonb_build_object('attempt', river_job.attempt + 1, 'expires_at', $14::timestamptz)
+        )
+    END
+FROM
+    locked_jobs
+WHERE
+    river_job.id = locked_jobs.id
+RETURNING
+    river_job.id, river_job.args, river_job.attempt, river_job.attempted_at, river_job.attempted_by, river_job.created_at, river_job.errors, river_job.finalized_at, river_job.kind, river_job.max_attempts, river_job.metadata, river_job.priority, river_job.queue, river_job.state, river_job.scheduled_at, river_job.tags, river_job.unique_key, river_job.unique_states
+`
+
+type JobGetAvailableFairParams struct {
+	AttemptedBy     string
+	FairKey         []string
+	Queue           string
+	FairPriority    int16
+	Now             *time.Time
+	FairMaxPerKey   int32
+	LimitedKind     []string
+	LimitedKindMax  []int32
+	PartitionLimit  int32
+	PartitionByKind bool
+	PartitionByArgs bool
+	GlobalLimit     int32
+	Max             int32
+	LeaseExpiresAt  *time.Time
+}
+
+func (q *Queries) JobGetAvailableFair(ctx context.Context, db DBTX, arg *JobGetAvailableFairParams) ([]*RiverJob, error) {
+	rows, err := db.QueryContext(ctx, jobGetAvailableFair,
+		arg.AttemptedBy,
+		pq.Array(arg.FairKey),
+		arg.Queue,
+		arg.FairPriority,
+		arg.Now,
+		arg.FairMaxPerKey,
+		pq.Array(arg.LimitedKind),
+		pq.Array(arg.LimitedKindMax),
+		arg.PartitionLimit,
+		arg.PartitionByKind,
+		arg.PartitionByArgs,
+		arg.GlobalLimit,
+		arg.Max,
+		arg.LeaseExpiresAt,
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []*RiverJob
+	for rows.Next() {
+		var i RiverJob
+		if err := rows.Scan(
+			&i.ID,
+			&i.Args,
+			&i.Attempt,
+			&i.AttemptedAt,
+			pq.Array(&i.AttemptedBy),
+			&i.CreatedAt,
+			pq.Array(&i.Errors),
+			&i.FinalizedAt,
+			&i.Kind,
+			&i.MaxAttempts,
+			&i.Metadata,
+			&i.Priority,
+			&i.Queue,
+			&i.State,
+			&i.ScheduledAt,
+			pq.Array(&i.Tags),
+			&i.UniqueKey,
+			&i.UniqueStates,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, &i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const jobGetAvailableFairKeys = `-- name: JobGetAvailableFairKeys :many
+WITH RECURSIVE top_priority AS (
+    SELECT priority
+    FROM /* TEMPLATE: schema */river_job
+    WHERE state = 'available'
+        AND queue = $1::text
+        AND scheduled_at <= coalesce($2::timestamptz, now())
+    ORDER BY priority ASC
+    LIMIT 1
+),
+fair_key AS (
+    (
+        SELECT
+            coalesce(metadata -> 'river:partition', '{}'::jsonb) AS key,
+            1 AS depth
+        FROM /* TEMPLATE: schema */river_job
+        WHERE state = 'available'
+            AND queue = $1::text
+            AND priority = (SELECT priority FROM top_priority)
+            AND scheduled_at <= coalesce($2::timestamptz, now())
+            AND (
+                $3::text IS NULL
+                OR coalesce(metadata -> 'river:partition', '{}'::jsonb) > $3::text::jsonb
+            )
+        ORDER BY 1
+        LIMIT 1
+    )
+    UNION ALL
+    SELECT
+        next_key.key,
+        fair_key.depth + 1
+    FROM fair_key
+    CROSS JOIN LATERAL (
+        SELECT coalesce(metadata -> 'river:partition', '{}'::jsonb) AS key
+        FROM /* TEMPLATE: schema */river_job
+        WHERE state = 'available'
+            AND queue = $1::text
+            AND priority = (SELECT priority FROM top_priority)
+            AND scheduled_at <= coalesce($2::timestamptz, now())
+            AND coalesce(metadata -> 'river:partition', '{}'::jsonb) > fair_key.key
+        ORDER BY 1
+        LIMIT 1
+    ) AS next_key
+    WHERE fair_key.depth < $4::integer
+)
+SELECT
+    (SELECT priority FROM top_priority)::smallint AS priority,
+    fair_key.key::text AS fair_key
+FROM fair_key
+ORDER BY fair_key.depth
+`
+
+type JobGetAvailableFairKeysParams struct {
+	Queue string
+	Now   *time.Time
+	After sql.NullString
+	Max   int32
+}
+
+type JobGetAvailableFairKeysRow struct {
+	Priority int16
+	FairKey  string
+}
+
+func (q *Queries) JobGetAvailableFairKeys(ctx context.Context, db DBTX, arg *JobGetAvailableFairKeysParams) ([]*JobGetAvailableFairKeysRow, error) {
+	rows, err := db.QueryContext(ctx, jobGetAvailableFairKeys,
+		arg.Queue,
+		arg.Now,
+		arg.After,
+		arg.Max,
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []*JobGetAvailableFairKeysRow
+	for rows.Next() {
+		var i JobGetAvailableFairKeysRow
+		if err := rows.Scan(
+			&i.Priority,
+			&i.FairKey,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, &i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const jobGetAvailableKinds = `-- name: JobGetAvailableKinds :many
+SELECT limited_kind.kind
+FROM unnest($1::text[]) AS limited_kind(kind)
+WHERE EXISTS (
+    SELECT 1
+    FROM /* TEMPLATE: schema */river_job
+    WHERE state = 'available'
+        AND queue = $2::text
+        AND kind = limited_kind.kind
+        AND scheduled_at <= coalesce($3::timestamptz, now())
+)
+ORDER BY limited_kind.kind
+`
+
+type JobGetAvailableKindsParams struct {
+	Kind  []string
+	Queue string
+	Now   *time.Time
+}
+
+func (q *Queries) JobGetAvailableKinds(ctx context.Context, db DBTX, arg *JobGetAvailableKindsParams) ([]string, error) {
+	rows, err := db.QueryContext(ctx, jobGetAvailableKinds, pq.Array(arg.Kind), arg.Queue, arg.Now)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []string
+	for rows.Next() {
+		var kind string
+		if err := rows.Scan(&kind); err != nil {
+			return nil, err
+		}
+		items = append(items, kind)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
 const jobGetByID = `-- name: JobGetByID :one
 SELECT id, args, attempt, attempted_at, attempted_by, created_at, errors, finalized_at, kind, max_attempts, metadata, priority, queue, state, scheduled_at, tags, unique_key, unique_states
 FROM /* TEMPLATE: schema */river_job
@@ -476,22 +1319,95 @@ func (q *Queries) JobGetByKindMany(ctx context.Context, db DBTX, kind []string)
 	return items, nil
 }
 
+const jobGetFinalizedAfter = `-- name: JobGetFinalizedAfter :many
+SELECT id, args, attempt, attempted_at, attempted_by, created_at, errors, finalized_at, kind, max_attempts, metadata, priority, queue, state, scheduled_at, tags, unique_key, unique_states
+FROM /* TEMPLATE: schema */river_job
+WHERE state IN ('cancelled', 'completed', 'discarded')
+    AND finalized_at IS NOT NULL
+    AND (finalized_at, id) > ($1::timestamptz, $2::bigint)
+ORDER BY finalized_at, id
+LIMIT $3::int
+`
+
+type JobGetFinalizedAfterParams struct {
+	FinalizedAt time.Time
+	ID          int64
+	Max         int32
+}
+
+func (q *Queries) JobGetFinalizedAfter(ctx context.Context, db DBTX, arg *JobGetFinalizedAfterParams) ([]*RiverJob, error) {
+	rows, err := db.QueryContext(ctx, jobGetFinalizedAfter, arg.FinalizedAt, arg.ID, arg.Max)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []*RiverJob
+	for rows.Next() {
+		var i RiverJob
+		if err := rows.Scan(
+			&i.ID,
+			&i.Args,
+			&i.Attempt,
+			&i.AttemptedAt,
+			pq.Array(&i.AttemptedBy),
+			&i.CreatedAt,
+			pq.Array(&i.Errors),
+			&i.FinalizedAt,
+			&i.Kind,
+			&i.MaxAttempts,
+			&i.Metadata,
+			&i.Priority,
+			&i.Queue,
+			&i.State,
+			&i.ScheduledAt,
+			pq.Array(&i.Tags),
+			&i.UniqueKey,
+			&i.UniqueStates,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, &i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
 const jobGetStuck = `-- name: JobGetStuck :many
 SELECT id, args, attempt, attempted_at, attempted_by, created_at, errors, finalized_at, kind, max_attempts, metadata, priority, queue, state, scheduled_at, tags, unique_key, unique_states
 FROM /* TEMPLATE: schema */river_job
 WHERE state = 'running'
-    AND attempted_at < $1::timestamptz
+    AND (
+        -- Jobs holding a lease for their current attempt are stuck once the
+        -- lease expires.
+        (
+            (metadata -> 'river:lease' ->> 'attempt')::smallint = attempt
+            AND (metadata -> 'river:lease' ->> 'expires_at')::timestamptz < $1::timestamptz
+        )
+        -- Otherwise, they're stuck after running longer than the horizon. This
+        -- is kept as a plain predicate on attempted_at rather than folded into
+        -- a CASE so that it remains usable by an index.
+        OR (
+            attempted_at < $2::timestamptz
+            AND (metadata -> 'river:lease' ->> 'attempt')::smallint IS DISTINCT FROM attempt
+        )
+    )
 ORDER BY id
-LIMIT $2
+LIMIT $3
 `
 
 type JobGetStuckParams struct {
+	Now          time.Time
 	StuckHorizon time.Time
 	Max          int32
 }
 
 func (q *Queries) JobGetStuck(ctx context.Context, db DBTX, arg *JobGetStuckParams) ([]*RiverJob, error) {
-	rows, err := db.QueryContext(ctx, jobGetStuck, arg.StuckHorizon, arg.Max)
+	rows, err := db.QueryContext(ctx, jobGetStuck, arg.Now, arg.StuckHorizon, arg.Max)
 	if err != nil {
 		return nil, err
 	}
@@ -832,6 +1748,28 @@ func (q *Queries) JobInsertFull(ctx context.Context, db DBTX, arg *JobInsertFull
 	return &i, err
 }
 
+const jobLeaseRenewMany = `-- name: JobLeaseRenewMany :execrows
+UPDATE /* TEMPLATE: schema */river_job
+SET metadata = metadata || jsonb_build_object(
+    'river:lease', jsonb_build_object('attempt', attempt, 'expires_at', $1::timestamptz)
+)
+WHERE id = any($2::bigint[])
+    AND state = 'running'
+`
+
+type JobLeaseRenewManyParams struct {
+	ExpiresAt time.Time
+	ID        []int64
+}
+
+func (q *Queries) JobLeaseRenewMany(ctx context.Context, db DBTX, arg *JobLeaseRenewManyParams) (int64, error) {
+	result, err := db.ExecContext(ctx, jobLeaseRenewMany, arg.ExpiresAt, pq.Array(arg.ID))
+	if err != nil {
+		return 0, err
+	}
+	return result.RowsAffected()
+}
+
 const jobList = `-- name: JobList :many
 SELECT id, args, attempt, attempted_at, attempted_by, created_at, errors, finalized_at, kind, max_attempts, metadata, priority, queue, state, scheduled_at, tags, unique_key, unique_states
 FROM /* TEMPLATE: schema */river_job
@@ -882,6 +1820,46 @@ func (q *Queries) JobList(ctx context.Context, db DBTX, max int32) ([]*RiverJob,
 	return items, nil
 }
 
+const jobMetadataMergeIfRunning = `-- name: JobMetadataMergeIfRunning :one
+UPDATE /* TEMPLATE: schema */river_job
+SET metadata = metadata || $1::jsonb
+WHERE id = $2
+    AND state = 'running'
+RETURNING id, args, attempt, attempted_at, attempted_by, created_at, errors, finalized_at, kind, max_attempts, metadata, priority, queue, state, scheduled_at, tags, unique_key, unique_states
+`
+
+type JobMetadataMergeIfRunningParams struct {
+	MetadataUpdates string
+	ID              int64
+}
+
+// Merges metadata into a job, but only while it's still running.
+func (q *Queries) JobMetadataMergeIfRunning(ctx context.Context, db DBTX, arg *JobMetadataMergeIfRunningParams) (*RiverJob, error) {
+	row := db.QueryRowContext(ctx, jobMetadataMergeIfRunning, arg.MetadataUpdates, arg.ID)
+	var i RiverJob
+	err := row.Scan(
+		&i.ID,
+		&i.Args,
+		&i.Attempt,
+		&i.AttemptedAt,
+		pq.Array(&i.AttemptedBy),
+		&i.CreatedAt,
+		pq.Array(&i.Errors),
+		&i.FinalizedAt,
+		&i.Kind,
+		&i.MaxAttempts,
+		&i.Metadata,
+		&i.Priority,
+		&i.Queue,
+		&i.State,
+		&i.ScheduledAt,
+		pq.Array(&i.Tags),
+		&i.UniqueKey,
+		&i.UniqueStates,
+	)
+	return &i, err
+}
+
 const jobRescueMany = `-- name: JobRescueMany :exec
 UPDATE /* TEMPLATE: schema */river_job
 SET
@@ -977,6 +1955,71 @@ func (q *Queries) JobRetry(ctx context.Context, db DBTX, id int64) (*RiverJob, e
 	return &i, err
 }
 
+const jobRetryMany = `-- name: JobRetryMany :many
+WITH jobs_to_update AS (
+    SELECT id
+    FROM /* TEMPLATE: schema */river_job
+    WHERE id = any($1::bigint[])
+        -- Do not touch running jobs:
+        AND state != 'running'
+        -- If the job is already available with a prior scheduled_at, leave it alone.
+        AND NOT (state = 'available' AND scheduled_at < now())
+    ORDER BY id
+    FOR UPDATE
+)
+UPDATE /* TEMPLATE: schema */river_job
+SET
+    state = 'available',
+    scheduled_at = now(),
+    max_attempts = CASE WHEN attempt = max_attempts THEN max_attempts + 1 ELSE max_attempts END,
+    finalized_at = NULL
+FROM jobs_to_update
+WHERE river_job.id = jobs_to_update.id
+RETURNING river_job.id, river_job.args, river_job.attempt, river_job.attempted_at, river_job.attempted_by, river_job.created_at, river_job.errors, river_job.finalized_at, river_job.kind, river_job.max_attempts, river_job.metadata, river_job.priority, river_job.queue, river_job.state, river_job.scheduled_at, river_job.tags, river_job.unique_key, river_job.unique_states
+`
+
+func (q *Queries) JobRetryMany(ctx context.Context, db DBTX, id []int64) ([]*RiverJob, error) {
+	rows, err := db.QueryContext(ctx, jobRetryMany, pq.Array(id))
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []*RiverJob
+	for rows.Next() {
+		var i RiverJob
+		if err := rows.Scan(
+			&i.ID,
+			&i.Args,
+			&i.Attempt,
+			&i.AttemptedAt,
+			pq.Array(&i.AttemptedBy),
+			&i.CreatedAt,
+			pq.Array(&i.Errors),
+			&i.FinalizedAt,
+			&i.Kind,
+			&i.MaxAttempts,
+			&i.Metadata,
+			&i.Priority,
+			&i.Queue,
+			&i.State,
+			&i.ScheduledAt,
+			pq.Array(&i.Tags),
+			&i.UniqueKey,
+			&i.UniqueStates,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, &i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
 const jobSchedule = `-- name: JobSchedule :many
 WITH jobs_to_schedule AS (
     SELECT
@@ -1112,6 +2155,147 @@ func (q *Queries) JobSchedule(ctx context.Context, db DBTX, arg *JobSchedulePara
 	return items, nil
 }
 
+const jobSequencePromote = `-- name: JobSequencePromote :many
+WITH RECURSIVE pending_sequence_head AS (
+    -- Skip scan over river_job_sequence_pending_index that finds only the
+    -- first pending job of each sequence, since no other pending job can be
+    -- promoted until it has been. Sequences are visited in key order, starting
+    -- after the given key.
+    (
+        SELECT
+            id,
+            metadata ->> 'river:sequence_key' AS sequence_key,
+            1 AS depth
+        FROM /* TEMPLATE: schema */river_job
+        WHERE state = 'pending'
+            AND metadata ? 'river:sequence_key'
+            AND (
+                $1::text IS NULL
+                OR metadata ->> 'river:sequence_key' > $1::text
+            )
+        ORDER BY metadata ->> 'river:sequence_key', id
+        LIMIT 1
+    )
+    UNION ALL
+    SELECT
+        next_head.id,
+        next_head.sequence_key,
+        pending_sequence_head.depth + 1
+    FROM pending_sequence_head
+    CROSS JOIN LATERAL (
+        SELECT
+            id,
+            metadata ->> 'river:sequence_key' AS sequence_key
+        FROM /* TEMPLATE: schema */river_job
+        WHERE state = 'pending'
+            AND metadata ? 'river:sequence_key'
+            AND metadata ->> 'river:sequence_key' > pending_sequence_head.sequence_key
+        ORDER BY metadata ->> 'river:sequence_key', id
+        LIMIT 1
+    ) AS next_head
+    WHERE pending_sequence_head.depth < $2::integer
+),
+pending_sequence_jobs AS (
+    SELECT
+        river_job.id,
+        pending_sequence_head.sequence_key,
+        coalesce((river_job.metadata->>'river:sequence_continue_on_discarded')::boolean, false) AS continue_on_discarded
+    FROM /* TEMPLATE: schema */river_job
+    INNER JOIN pending_sequence_head ON pending_sequence_head.id = river_job.id
+    WHERE river_job.state = 'pending'
+    FOR UPDATE OF river_job SKIP LOCKED
+),
+sequence_heads AS (
+    SELECT pending_sequence_jobs.id
+    FROM pending_sequence_jobs
+    LEFT JOIN LATERAL (
+        SELECT previous_job.state
+        FROM /* TEMPLATE: schema */river_job AS previous_job
+        -- Matches river_job_sequence_key_index so the most recent previous
+        -- job in the sequence is found with a backward index scan.
+        WHERE previous_job.metadata ? 'river:sequence_key'
+            AND previous_job.metadata ->> 'river:sequence_key' = pending_sequence_jobs.sequence_key
+            AND previous_job.id < pending_sequence_jobs.id
+        ORDER BY previous_job.id DESC
+        LIMIT 1
+    ) AS previous_job ON true
+    WHERE previous_job.state IS NULL
+        OR previous_job.state = 'completed'
+        OR (
+            previous_job.state IN ('cancelled', 'discarded')
+            AND pending_sequence_jobs.continue_on_discarded
+        )
+),
+updated_jobs AS (
+    UPDATE /* TEMPLATE: schema */river_job
+    SET
+        state = CASE WHEN river_job.scheduled_at > $3::timestamptz THEN 'scheduled'::river_job_state
+                     ELSE 'available'::river_job_state END
+    FROM sequence_heads
+    WHERE river_job.id = sequence_heads.id
+    RETURNING river_job.id, river_job.args, river_job.attempt, river_job.attempted_at, river_job.attempted_by, river_job.created_at, river_job.errors, river_job.finalized_at, river_job.kind, river_job.max_attempts, river_job.metadata, river_job.priority, river_job.queue, river_job.state, river_job.scheduled_at, river_job.tags, river_job.unique_key, river_job.unique_states
+)
+SELECT id, args, attempt, attempted_at, attempted_by, created_at, errors, finalized_at, kind, max_attempts, metadata, priority, queue, state, scheduled_at, tags, unique_key, unique_states
+FROM (
+    SELECT id, args, attempt, attempted_at, attempted_by, created_at, errors, finalized_at, kind, max_attempts, metadata, priority, queue, state, scheduled_at, tags, unique_key, unique_states
+    FROM /* TEMPLATE: schema */river_job
+    WHERE id IN (SELECT id FROM pending_sequence_jobs)
+        AND id NOT IN (SELECT id FROM updated_jobs)
+    UNION
+    SELECT id, args, attempt, attempted_at, attempted_by, created_at, errors, finalized_at, kind, max_attempts, metadata, priority, queue, state, scheduled_at, tags, unique_key, unique_states
+    FROM updated_jobs
+) AS sequence_job
+ORDER BY sequence_job.metadata ->> 'river:sequence_key'
+`
+
+type JobSequencePromoteParams struct {
+	AfterSequenceKey sql.NullString
+	Max              int32
+	Now              time.Time
+}
+
+func (q *Queries) JobSequencePromote(ctx context.Context, db DBTX, arg *JobSequencePromoteParams) ([]*RiverJob, error) {
+	rows, err := db.QueryContext(ctx, jobSequencePromote, arg.AfterSequenceKey, arg.Max, arg.Now)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []*RiverJob
+	for rows.Next() {
+		var i RiverJob
+		if err := rows.Scan(
+			&i.ID,
+			&i.Args,
+			&i.Attempt,
+			&i.AttemptedAt,
+			pq.Array(&i.AttemptedBy),
+			&i.CreatedAt,
+			pq.Array(&i.Errors),
+			&i.FinalizedAt,
+			&i.Kind,
+			&i.MaxAttempts,
+			&i.Metadata,
+			&i.Priority,
+			&i.Queue,
+			&i.State,
+			&i.ScheduledAt,
+			pq.Array(&i.Tags),
+			&i.UniqueKey,
+			&i.UniqueStates,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, &i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
 const jobSetStateIfRunningMany = `-- name: JobSetStateIfRunningMany :many
 WITH job_input AS (
     SELECT
@@ -1261,6 +2445,130 @@ func (q *Queries) JobSetStateIfRunningMany(ctx context.Context, db DBTX, arg *Jo
 	return items, nil
 }
 
+const jobStats = `-- name: JobStats :many
+WITH queue_name AS (
+    SELECT DISTINCT unnest($1::text[]) AS queue
+),
+unfinalized_stats AS (
+    SELECT
+        queue,
+        count(*) FILTER (WHERE state = 'available') AS available_count,
+        count(*) FILTER (WHERE state = 'pending') AS pending_count,
+        count(*) FILTER (WHERE state = 'retryable') AS retryable_count,
+        count(*) FILTER (WHERE state = 'running') AS running_count,
+        count(*) FILTER (WHERE state = 'scheduled') AS scheduled_count,
+        min(scheduled_at) FILTER (WHERE state = 'available') AS oldest_available_at
+    FROM /* TEMPLATE: schema */river_job
+    WHERE state IN ('available', 'pending', 'retryable', 'running', 'scheduled')
+        AND queue = any($1::text[])
+        AND ($2::text = '' OR kind = $2::text)
+    GROUP BY queue
+),
+finalized_stats AS (
+    SELECT
+        queue,
+        count(*) FILTER (WHERE state = 'cancelled') AS cancelled_count,
+        count(*) FILTER (WHERE state = 'completed') AS completed_count,
+        count(*) FILTER (WHERE state = 'discarded') AS discarded_count
+    FROM /* TEMPLATE: schema */river_job
+    WHERE state IN ('cancelled', 'completed', 'discarded')
+        AND queue = any($1::text[])
+        AND ($2::text = '' OR kind = $2::text)
+        AND finalized_at >= $3::timestamptz
+    GROUP BY queue
+),
+running_by_client AS (
+    SELECT queue, jsonb_object_agg(client_id, client_count) AS running_by_client
+    FROM (
+        SELECT queue, coalesce(attempted_by[array_upper(attempted_by, 1)], '') AS client_id, count(*) AS client_count
+        FROM /* TEMPLATE: schema */river_job
+        WHERE state = 'running'
+            AND queue = any($1::text[])
+            AND ($2::text = '' OR kind = $2::text)
+        GROUP BY queue, client_id
+    ) AS running_counts
+    GROUP BY queue
+)
+SELECT
+    queue_name.queue::text AS queue,
+    coalesce(unfinalized_stats.available_count, 0)::bigint AS available_count,
+    coalesce(unfinalized_stats.pending_count, 0)::bigint AS pending_count,
+    coalesce(unfinalized_stats.retryable_count, 0)::bigint AS retryable_count,
+    coalesce(unfinalized_stats.running_count, 0)::bigint AS running_count,
+    coalesce(unfinalized_stats.scheduled_count, 0)::bigint AS scheduled_count,
+    unfinalized_stats.oldest_available_at::timestamptz AS oldest_available_at,
+    coalesce(finalized_stats.cancelled_count, 0)::bigint AS cancelled_count,
+    coalesce(finalized_stats.completed_count, 0)::bigint AS completed_count,
+    coalesce(finalized_stats.discarded_count, 0)::bigint AS discarded_count,
+    coalesce(running_by_client.running_by_client, '{}')::jsonb AS running_by_client
+FROM queue_name
+    LEFT JOIN unfinalized_stats ON unfinalized_stats.queue = queue_name.queue
+    LEFT JOIN finalized_stats ON finalized_stats.queue = queue_name.queue
+    LEFT JOIN running_by_client ON running_by_client.queue = queue_name.queue
+ORDER BY queue_name.queue
+`
+
+type JobStatsParams struct {
+	Queue          []string
+	Kind           string
+	FinalizedSince time.Time
+}
+
+type JobStatsRow struct {
+	Queue             string
+	AvailableCount    int64
+	PendingCount      int64
+	RetryableCount    int64
+	RunningCount      int64
+	ScheduledCount    int64
+	OldestAvailableAt *time.Time
+	CancelledCount    int64
+	CompletedCount    int64
+	DiscardedCount    int64
+	RunningByClient   string
+}
+
+// Statistics for queues, optionally limited to a kind, computed in a single
+// statement so that they're consistent with each other. A row is returned for
+// every given queue, including those without any jobs. Unfinalized jobs are
+// counted in full, but finalized jobs only if they were finalized since
+// @finalized_since so that the cost of the query doesn't grow with the number
+// of jobs retained.
+func (q *Queries) JobStats(ctx context.Context, db DBTX, arg *JobStatsParams) ([]*JobStatsRow, error) {
+	rows, err := db.QueryContext(ctx, jobStats, pq.Array(arg.Queue), arg.Kind, arg.FinalizedSince)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []*JobStatsRow
+	for rows.Next() {
+		var i JobStatsRow
+		if err := rows.Scan(
+			&i.Queue,
+			&i.AvailableCount,
+			&i.PendingCount,
+			&i.RetryableCount,
+			&i.RunningCount,
+			&i.ScheduledCount,
+			&i.OldestAvailableAt,
+			&i.CancelledCount,
+			&i.CompletedCount,
+			&i.DiscardedCount,
+			&i.RunningByClient,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, &i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
 const jobUpdate = `-- name: JobUpdate :one
 UPDATE /* TEMPLATE: schema */river_job
 SET
@@ -1331,3 +2639,211 @@ func (q *Queries) JobUpdate(ctx context.Context, db DBTX, arg *JobUpdateParams)
 	)
 	return &i, err
 }
+
+const jobUpdateIfQueued = `-- name: JobUpdateIfQueued :one
+WITH job_to_update AS (
+    SELECT id
+    FROM /* TEMPLATE: schema */river_job
+    WHERE river_job.id = $1
+    FOR UPDATE
+),
+updated_job AS (
+    UPDATE /* TEMPLATE: schema */river_job
+    SET
+        max_attempts = CASE WHEN $2::boolean THEN $3::smallint ELSE max_attempts END,
+        metadata = CASE WHEN $4::boolean THEN metadata || $5::jsonb
+                        WHEN $6::boolean THEN $5::jsonb
+                        ELSE metadata END,
+        priority = CASE WHEN $7::boolean THEN $8::smallint ELSE priority END,
+        queue = CASE WHEN $9::boolean THEN $10::text ELSE queue END,
+        scheduled_at = CASE WHEN $11::boolean THEN $12::timestamptz ELSE scheduled_at END,
+        -- Moving an available job into the future makes it scheduled, and
+        -- moving a scheduled job into the past makes it available, the same as
+        -- if it'd been inserted with the new scheduled_at.
+        state = CASE WHEN $11::boolean AND state = 'available' AND $12::timestamptz > coalesce($13::timestamptz, now()) THEN 'scheduled'
+                     WHEN $11::boolean AND state = 'scheduled' AND $12::timestamptz <= coalesce($13::timestamptz, now()) THEN 'available'
+                     ELSE state END,
+        tags = CASE WHEN $14::boolean THEN $15::varchar(255)[] ELSE tags END
+    FROM job_to_update
+    WHERE river_job.id = job_to_update.id
+        -- Only jobs still waiting in the queue can be updated:
+        AND river_job.state IN ('available', 'pending', 'retryable', 'scheduled')
+    RETURNING river_job.id, river_job.args, river_job.attempt, river_job.attempted_at, river_job.attempted_by, river_job.created_at, river_job.errors, river_job.finalized_at, river_job.kind, river_job.max_attempts, river_job.metadata, river_job.priority, river_job.queue, river_job.state, river_job.scheduled_at, river_job.tags, river_job.unique_key, river_job.unique_states
+)
+SELECT id, args, attempt, attempted_at, attempted_by, created_at, errors, finalized_at, kind, max_attempts, metadata, priority, queue, state, scheduled_at, tags, unique_key, unique_states
+FROM /* TEMPLATE: schema */river_job
+WHERE id = $1::bigint
+    AND id NOT IN (SELECT id FROM updated_job)
+UNION
+SELECT id, args, attempt, attempted_at, attempted_by, created_at, errors, finalized_at, kind, max_attempts, metadata, priority, queue, state, scheduled_at, tags, unique_key, unique_states
+FROM updated_job
+`
+
+type JobUpdateIfQueuedParams struct {
+	ID                  int64
+	MaxAttemptsDoUpdate bool
+	MaxAttempts         int16
+	MetadataDoMerge     bool
+	Metadata            string
+	MetadataDoUpdate    bool
+	PriorityDoUpdate    bool
+	Priority            int16
+	QueueDoUpdate       bool
+	Queue               string
+	ScheduledAtDoUpdate bool
+	ScheduledAt         time.Time
+	Now                 *time.Time
+	TagsDoUpdate        bool
+	Tags                []string
+}
+
+func (q *Queries) JobUpdateIfQueued(ctx context.Context, db DBTX, arg *JobUpdateIfQueuedParams) (*RiverJob, error) {
+	row := db.QueryRowContext(ctx, jobUpdateIfQueued,
+		arg.ID,
+		arg.MaxAttemptsDoUpdate,
+		arg.MaxAttempts,
+		arg.MetadataDoMerge,
+		arg.Metadata,
+		arg.MetadataDoUpdate,
+		arg.PriorityDoUpdate,
+		arg.Priority,
+		arg.QueueDoUpdate,
+		arg.Queue,
+		arg.ScheduledAtDoUpdate,
+		arg.ScheduledAt,
+		arg.Now,
+		arg.TagsDoUpdate,
+		pq.Array(arg.Tags),
+	)
+	var i RiverJob
+	err := row.Scan(
+		&i.ID,
+		&i.Args,
+		&i.Attempt,
+		&i.AttemptedAt,
+		pq.Array(&i.AttemptedBy),
+		&i.CreatedAt,
+		pq.Array(&i.Errors),
+		&i.FinalizedAt,
+		&i.Kind,
+		&i.MaxAttempts,
+		&i.Metadata,
+		&i.Priority,
+		&i.Queue,
+		&i.State,
+		&i.ScheduledAt,
+		pq.Array(&i.Tags),
+		&i.UniqueKey,
+		&i.UniqueStates,
+	)
+	return &i, err
+}
+
+const jobWorkflowPromote = `-- name: JobWorkflowPromote :many
+WITH pending_tasks AS (
+    SELECT id, metadata
+    FROM /* TEMPLATE: schema */river_job
+    WHERE state = 'pending'
+        AND metadata ? 'river:workflow_id'
+        AND id > $1::bigint
+    ORDER BY id
+    LIMIT $2::integer
+    FOR UPDATE SKIP LOCKED
+),
+dep_states AS (
+    -- Dependencies on other tasks in the same workflow, referenced by name.
+    SELECT pending_tasks.id AS task_id, dep_job.state AS dep_state
+    FROM pending_tasks
+    CROSS JOIN LATERAL jsonb_array_elements_text(coalesce(pending_tasks.metadata->'river:workflow_deps', '[]'::jsonb)) AS dep_name
+    LEFT JOIN /* TEMPLATE: schema */river_job AS dep_job
+        ON dep_job.metadata @> jsonb_build_object('river:workflow_id', pending_tasks.metadata->'river:workflow_id', 'river:workflow_task', dep_name)
+    UNION ALL
+    -- Dependencies on any other job, referenced by ID.
+    SELECT pending_tasks.id AS task_id, dep_job.state AS dep_state
+    FROM pending_tasks
+    CROSS JOIN LATERAL jsonb_array_elements_text(coalesce(pending_tasks.metadata->'river:workflow_dep_job_ids', '[]'::jsonb)) AS dep_job_id
+    LEFT JOIN /* TEMPLATE: schema */river_job AS dep_job
+        ON dep_job.id = dep_job_id::bigint
+),
+task_outcomes AS (
+    -- A dependency that no longer exists (e.g. removed by the job cleaner
+    -- after completing) has a NULL state and counts as neither failed nor
+    -- unfinished. Dependencies are checked to exist when tasks are inserted,
+    -- so one that's missing must have been removed since.
+    SELECT
+        pending_tasks.id,
+        count(*) FILTER (WHERE dep_states.dep_state IN ('cancelled', 'discarded')) AS num_failed,
+        count(*) FILTER (WHERE dep_states.dep_state NOT IN ('cancelled', 'completed', 'discarded')) AS num_unfinished
+    FROM pending_tasks
+    LEFT JOIN dep_states ON dep_states.task_id = pending_tasks.id
+    GROUP BY pending_tasks.id
+),
+updated_tasks AS (
+    UPDATE /* TEMPLATE: schema */river_job
+    SET
+        state = CASE WHEN task_outcomes.num_failed > 0 AND river_job.metadata->>'river:workflow_dep_failed_action' = 'discard' THEN 'discarded'::river_job_state
+                     WHEN task_outcomes.num_failed > 0 THEN 'cancelled'::river_job_state
+                     WHEN river_job.scheduled_at > $3::timestamptz THEN 'scheduled'::river_job_state
+                     ELSE 'available'::river_job_state END,
+        finalized_at = CASE WHEN task_outcomes.num_failed > 0 THEN $3::timestamptz ELSE river_job.finalized_at END
+    FROM task_outcomes
+    WHERE river_job.id = task_outcomes.id
+        AND (task_outcomes.num_failed > 0 OR task_outcomes.num_unfinished = 0)
+    RETURNING river_job.id, river_job.args, river_job.attempt, river_job.attempted_at, river_job.attempted_by, river_job.created_at, river_job.errors, river_job.finalized_at, river_job.kind, river_job.max_attempts, river_job.metadata, river_job.priority, river_job.queue, river_job.state, river_job.scheduled_at, river_job.tags, river_job.unique_key, river_job.unique_states
+)
+SELECT id, args, attempt, attempted_at, attempted_by, created_at, errors, finalized_at, kind, max_attempts, metadata, priority, queue, state, scheduled_at, tags, unique_key, unique_states
+FROM /* TEMPLATE: schema */river_job
+WHERE id IN (SELECT id FROM pending_tasks)
+    AND id NOT IN (SELECT id FROM updated_tasks)
+UNION
+SELECT id, args, attempt, attempted_at, attempted_by, created_at, errors, finalized_at, kind, max_attempts, metadata, priority, queue, state, scheduled_at, tags, unique_key, unique_states
+FROM updated_tasks
+`
+
+type JobWorkflowPromoteParams struct {
+	AfterID int64
+	Max     int32
+	Now     time.Time
+}
+
+func (q *Queries) JobWorkflowPromote(ctx context.Context, db DBTX, arg *JobWorkflowPromoteParams) ([]*RiverJob, error) {
+	rows, err := db.QueryContext(ctx, jobWorkflowPromote, arg.AfterID, arg.Max, arg.Now)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []*RiverJob
+	for rows.Next() {
+		var i RiverJob
+		if err := rows.Scan(
+			&i.ID,
+			&i.Args,
+			&i.Attempt,
+			&i.AttemptedAt,
+			pq.Array(&i.AttemptedBy),
+			&i.CreatedAt,
+			pq.Array(&i.Errors),
+			&i.FinalizedAt,
+			&i.Kind,
+			&i.MaxAttempts,
+			&i.Metadata,
+			&i.Priority,
+			&i.Queue,
+			&i.State,
+			&i.ScheduledAt,
+			pq.Array(&i.Tags),
+			&i.UniqueKey,
+			&i.UniqueStates,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, &i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
@@ -0,0 +1,5 @@
+--
+-- Drop `river_job_dead_letter`.
+--
+
+DROP TABLE /* TEMPLATE: schema */river_job_dead_letter;
@@ -0,0 +1,6 @@
+--
+-- Drop `river_job_sequence_pending_index` and `river_job_sequence_key_index`.
+--
+
+DROP INDEX IF EXISTS /* TEMPLATE: schema */river_job_sequence_pending_index;
+DROP INDEX IF EXISTS /* TEMPLATE: schema */river_job_sequence_key_index;
@@ -14,6 +14,7 @@ import (
 	"fmt"
 	"io/fs"
 	"math"
+	"slices"
 	"strconv"
 	"strings"
 	"time"
@@ -25,6 +26,7 @@ import (
 	"github.com/riverqueue/river/riverdriver/riverdatabasesql/internal/dbsqlc"
 	"github.com/riverqueue/river/riverdriver/riverdatabasesql/internal/pgtypealias"
 	"github.com/riverqueue/river/rivershared/sqlctemplate"
+	"github.com/riverqueue/river/rivershared/util/maputil"
 	"github.com/riverqueue/river/rivershared/util/sliceutil"
 	"github.com/riverqueue/river/rivershared/util/valutil"
 	"github.com/riverqueue/river/rivertype"
@@ -116,6 +118,31 @@ func (e *Executor) Exec(ctx context.Context, sql string) (struct{}, error) {
 	return struct{}{}, interpretError(err)
 }
 
+func (e *Executor) JobBatchCountByState(ctx context.Context, params *riverdriver.JobBatchCountByStateParams) (map[rivertype.JobState]int, error) {
+	rows, err := dbsqlc.New().JobBatchCountByState(schemaTemplateParam(ctx, params.Schema), e.dbtx, params.BatchID)
+	if err != nil {
+		return nil, interpretError(err)
+	}
+
+	countsByState := make(map[rivertype.JobState]int, len(rows))
+	for _, row := range rows {
+		countsByState[rivertype.JobState(row.State)] = int(row.Count)
+	}
+	return countsByState, nil
+}
+
+func (e *Executor) JobBatchOnFinishPromote(ctx context.Context, params *riverdriver.JobBatchOnFinishPromoteParams) ([]*rivertype.JobRow, error) {
+	jobs, err := dbsqlc.New().JobBatchOnFinishPromote(schemaTemplateParam(ctx, params.Schema), e.dbtx, &dbsqlc.JobBatchOnFinishPromoteParams{
+		AfterID: params.AfterID,
+		Max:     int32(min(params.Max, math.MaxInt32)), //nolint:gosec
+		Now:     params.Now,
+	})
+	if err != nil {
+		return nil, interpretError(err)
+	}
+	return mapSliceError(jobs, jobRowFromInternal)
+}
+
 func (e *Executor) JobCancel(ctx context.Context, params *riverdriver.JobCancelParams) (*rivertype.JobRow, error) {
 	cancelledAt, err := params.CancelAttemptedAt.MarshalJSON()
 	if err != nil {
@@ -134,6 +161,56 @@ func (e *Executor) JobCancel(ctx context.Context, params *riverdriver.JobCancelP
 	return jobRowFromInternal(job)
 }
 
+func (e *Executor) JobCancelMany(ctx context.Context, params *riverdriver.JobCancelManyParams) ([]*rivertype.JobRow, error) {
+	cancelledAt, err := params.CancelAttemptedAt.MarshalJSON()
+	if err != nil {
+		return nil, err
+	}
+
+	jobs, err := dbsqlc.New().JobCancelMany(schemaTemplateParam(ctx, params.Schema), e.dbtx, &dbsqlc.JobCancelManyParams{
+		ID:                params.ID,
+		CancelAttemptedAt: string(cancelledAt),
+		ControlTopic:      params.ControlTopic,
+		Schema:            sql.NullString{String: params.Schema, Valid: params.Schema != ""},
+	})
+	if err != nil {
+		return nil, interpretError(err)
+	}
+	return mapSliceError(jobs, jobRowFromInternal)
+}
+
+func (e *Executor) JobCount(ctx context.Context, params *riverdriver.JobCountParams) (int, error) {
+	whereClause, err := replaceNamed(params.WhereClause, params.NamedArgs)
+	if err != nil {
+		return 0, err
+	}
+
+	ctx = sqlctemplate.WithReplacements(ctx, map[string]sqlctemplate.Replacement{
+		"where_clause": {Value: whereClause},
+	}, nil) // named params not passed because they've already been replaced above
+
+	numJobs, err := dbsqlc.New().JobCount(schemaTemplateParam(ctx, params.Schema), e.dbtx)
+	if err != nil {
+		return 0, interpretError(err)
+	}
+	return int(numJobs), nil
+}
+
+func (e *Executor) JobCountByQueueAndState(ctx context.Context, params *riverdriver.JobCountByQueueAndStateParams) ([]*riverdriver.JobCountByQueueAndStateResult, error) {
+	rows, err := dbsqlc.New().JobCountByQueueAndState(schemaTemplateParam(ctx, params.Schema), e.dbtx, sliceutil.Map(params.State, func(s rivertype.JobState) string { return string(s) }))
+	if err != nil {
+		return nil, interpretError(err)
+	}
+
+	return sliceutil.Map(rows, func(row *dbsqlc.JobCountByQueueAndStateRow) *riverdriver.JobCountByQueueAndStateResult {
+		return &riverdriver.JobCountByQueueAndStateResult{
+			Count: int(row.Count),
+			Queue: row.Queue,
+			State: rivertype.JobState(row.State),
+		}
+	}), nil
+}
+
 func (e *Executor) JobCountByState(ctx context.Context, params *riverdriver.JobCountByStateParams) (int, error) {
 	numJobs, err := dbsqlc.New().JobCountByState(schemaTemplateParam(ctx, params.Schema), e.dbtx, dbsqlc.RiverJobState(params.State))
 	if err != nil {
@@ -142,6 +219,30 @@ func (e *Executor) JobCountByState(ctx context.Context, params *riverdriver.JobC
 	return int(numJobs), nil
 }
 
+func (e *Executor) JobCountGrouped(ctx context.Context, params *riverdriver.JobCountGroupedParams) ([]*riverdriver.JobCountGroupedResult, error) {
+	whereClause, err := replaceNamed(params.WhereClause, params.NamedArgs)
+	if err != nil {
+		return nil, err
+	}
+
+	ctx = sqlctemplate.WithReplacements(ctx, map[string]sqlctemplate.Replacement{
+		"group_by_expr": {Value: params.GroupByExpr},
+		"where_clause":  {Value: whereClause},
+	}, nil) // named params not passed because they've already been replaced above
+
+	rows, err := dbsqlc.New().JobCountGrouped(schemaTemplateParam(ctx, params.Schema), e.dbtx)
+	if err != nil {
+		return nil, interpretError(err)
+	}
+
+	return sliceutil.Map(rows, func(row *dbsqlc.JobCountGroupedRow) *riverdriver.JobCountGroupedResult {
+		return &riverdriver.JobCountGroupedResult{
+			Count: int(row.Count),
+			Value: row.GroupValue,
+		}
+	}), nil
+}
+
 func (e *Executor) JobDelete(ctx context.Context, params *riverdriver.JobDeleteParams) (*rivertype.JobRow, error) {
 	job, err := dbsqlc.New().JobDelete(schemaTemplateParam(ctx, params.Schema), e.dbtx, params.ID)
 	if err != nil {
@@ -153,6 +254,54 @@ func (e *Executor) JobDelete(ctx context.Context, params *riverdriver.JobDeleteP
 	return jobRowFromInternal(job)
 }
 
+func (e *Executor) JobDeadLetterDeleteByID(ctx context.Context, params *riverdriver.JobDeadLetterDeleteByIDParams) (*rivertype.DeadLetter, error) {
+	deadLetter, err := dbsqlc.New().JobDeadLetterDeleteByID(schemaTemplateParam(ctx, params.Schema), e.dbtx, params.ID)
+	if err != nil {
+		return nil, interpretError(err)
+	}
+	return deadLetterFromInternal(deadLetter)
+}
+
+func (e *Executor) JobDeadLetterDeleteMany(ctx context.Context, params *riverdriver.JobDeadLetterDeleteManyParams) (int, error) {
+	numDeleted, err := dbsqlc.New().JobDeadLetterDeleteMany(schemaTemplateParam(ctx, params.Schema), e.dbtx, &dbsqlc.JobDeadLetterDeleteManyParams{
+		DeadLetteredBefore: params.DeadLetteredBefore,
+		ID:                 params.ID,
+		Kind:               params.Kind,
+		Queue:              params.Queue,
+	})
+	return int(numDeleted), interpretError(err)
+}
+
+func (e *Executor) JobDeadLetterGetByID(ctx context.Context, params *riverdriver.JobDeadLetterGetByIDParams) (*rivertype.DeadLetter, error) {
+	deadLetter, err := dbsqlc.New().JobDeadLetterGetByID(schemaTemplateParam(ctx, params.Schema), e.dbtx, params.ID)
+	if err != nil {
+		return nil, interpretError(err)
+	}
+	return deadLetterFromInternal(deadLetter)
+}
+
+func (e *Executor) JobDeadLetterList(ctx context.Context, params *riverdriver.JobDeadLetterListParams) ([]*rivertype.DeadLetter, error) {
+	deadLetters, err := dbsqlc.New().JobDeadLetterList(schemaTemplateParam(ctx, params.Schema), e.dbtx, &dbsqlc.JobDeadLetterListParams{
+		AfterID: params.AfterID,
+		Kind:    params.Kind,
+		Max:     int32(min(params.Max, math.MaxInt32)), //nolint:gosec
+		Queue:   params.Queue,
+	})
+	if err != nil {
+		return nil, interpretError(err)
+	}
+	return mapSliceError(deadLetters, deadLetterFromInternal)
+}
+
+func (e *Executor) JobDeadLetterMoveBefore(ctx context.Context, params *riverdriver.JobDeadLetterMoveBeforeParams) (int, error) {
+	numMoved, err := dbsqlc.New().JobDeadLetterMoveBefore(schemaTemplateParam(ctx, params.Schema), e.dbtx, &dbsqlc.JobDeadLetterMoveBeforeParams{
+		DiscardedFinalizedAtHorizon: params.DiscardedFinalizedAtHorizon,
+		Max:                         int32(min(params.Max, math.MaxInt32)), //nolint:gosec
+		Now:                         params.Now,
+	})
+	return int(numMoved), interpretError(err)
+}
+
 func (e *Executor) JobDeleteBefore(ctx context.Context, params *riverdriver.JobDeleteBeforeParams) (int, error) {
 	numDeleted, err := dbsqlc.New().JobDeleteBefore(schemaTemplateParam(ctx, params.Schema), e.dbtx, &dbsqlc.JobDeleteBeforeParams{
 		CancelledFinalizedAtHorizon: params.CancelledFinalizedAtHorizon,
@@ -163,12 +312,59 @@ func (e *Executor) JobDeleteBefore(ctx context.Context, params *riverdriver.JobD
 	return int(numDeleted), interpretError(err)
 }
 
+func (e *Executor) JobDeleteMany(ctx context.Context, params *riverdriver.JobDeleteManyParams) ([]*rivertype.JobRow, error) {
+	jobs, err := dbsqlc.New().JobDeleteMany(schemaTemplateParam(ctx, params.Schema), e.dbtx, params.ID)
+	if err != nil {
+		return nil, interpretError(err)
+	}
+	return mapSliceError(jobs, jobRowFromInternal)
+}
+
 func (e *Executor) JobGetAvailable(ctx context.Context, params *riverdriver.JobGetAvailableParams) ([]*rivertype.JobRow, error) {
+	limitedKinds := maputil.Keys(params.MaxByKind)
+	slices.Sort(limitedKinds)
+
+	limitedKindMax := make([]int32, len(limitedKinds))
+	for i, kind := range limitedKinds {
+		limitedKindMax[i] = int32(min(params.MaxByKind[kind], math.MaxInt32)) //nolint:gosec
+	}
+
+	if len(params.FairKeys) > 0 {
+		jobs, err := dbsqlc.New().JobGetAvailableFair(schemaTemplateParam(ctx, params.Schema), e.dbtx, &dbsqlc.JobGetAvailableFairParams{
+			AttemptedBy:     params.ClientID,
+			FairKey:         params.FairKeys,
+			FairMaxPerKey:   int32(min(params.FairMaxPerKey, math.MaxInt32)), //nolint:gosec
+			FairPriority:    int16(min(params.FairPriority, math.MaxInt16)),  //nolint:gosec
+			GlobalLimit:     int32(min(params.GlobalLimit, math.MaxInt32)),   //nolint:gosec
+			LeaseExpiresAt:  params.LeaseExpiresAt,
+			LimitedKind:     limitedKinds,
+			LimitedKindMax:  limitedKindMax,
+			Max:             int32(min(params.Max, math.MaxInt32)), //nolint:gosec
+			Now:             params.Now,
+			PartitionByArgs: params.PartitionByArgs,
+			PartitionByKind: params.PartitionByKind,
+			PartitionLimit:  int32(min(params.PartitionLimit, math.MaxInt32)), //nolint:gosec
+			Queue:           params.Queue,
+		})
+		if err != nil {
+			return nil, interpretError(err)
+		}
+		return mapSliceError(jobs, jobRowFromInternal)
+	}
+
 	jobs, err := dbsqlc.New().JobGetAvailable(schemaTemplateParam(ctx, params.Schema), e.dbtx, &dbsqlc.JobGetAvailableParams{
-		AttemptedBy: params.ClientID,
-		Max:         int32(min(params.Max, math.MaxInt32)), //nolint:gosec
-		Now:         params.Now,
-		Queue:       params.Queue,
+		AttemptedBy:           params.ClientID,
+		GlobalLimit:           int32(min(params.GlobalLimit, math.MaxInt32)), //nolint:gosec
+		LeaseExpiresAt:        params.LeaseExpiresAt,
+		LimitedKind:           limitedKinds,
+		LimitedKindMax:        limitedKindMax,
+		Max:                   int32(min(params.Max, math.MaxInt32)), //nolint:gosec
+		Now:                   params.Now,
+		PartitionByArgs:       params.PartitionByArgs,
+		PartitionByKind:       params.PartitionByKind,
+		PartitionCandidateMax: int32(min(params.PartitionCandidateMax, math.MaxInt32)), //nolint:gosec
+		PartitionLimit:        int32(min(params.PartitionLimit, math.MaxInt32)),        //nolint:gosec
+		Queue:                 params.Queue,
 	})
 	if err != nil {
 		return nil, interpretError(err)
@@ -176,6 +372,44 @@ func (e *Executor) JobGetAvailable(ctx context.Context, params *riverdriver.JobG
 	return mapSliceError(jobs, jobRowFromInternal)
 }
 
+func (e *Executor) JobGetAvailableFairKeys(ctx context.Context, params *riverdriver.JobGetAvailableFairKeysParams) (*riverdriver.JobGetAvailableFairKeysResult, error) {
+	var after sql.NullString
+	if params.After != nil {
+		after = sql.NullString{String: *params.After, Valid: true}
+	}
+
+	rows, err := dbsqlc.New().JobGetAvailableFairKeys(schemaTemplateParam(ctx, params.Schema), e.dbtx, &dbsqlc.JobGetAvailableFairKeysParams{
+		After: after,
+		Max:   int32(min(params.Max, math.MaxInt32)), //nolint:gosec
+		Now:   params.Now,
+		Queue: params.Queue,
+	})
+	if err != nil {
+		return nil, interpretError(err)
+	}
+
+	result := &riverdriver.JobGetAvailableFairKeysResult{
+		FairKeys: make([]string, len(rows)),
+	}
+	for i, row := range rows {
+		result.FairKeys[i] = row.FairKey
+		result.Priority = int(row.Priority)
+	}
+	return result, nil
+}
+
+func (e *Executor) JobGetAvailableKinds(ctx context.Context, params *riverdriver.JobGetAvailableKindsParams) ([]string, error) {
+	kinds, err := dbsqlc.New().JobGetAvailableKinds(schemaTemplateParam(ctx, params.Schema), e.dbtx, &dbsqlc.JobGetAvailableKindsParams{
+		Kind:  params.Kinds,
+		Now:   params.Now,
+		Queue: params.Queue,
+	})
+	if err != nil {
+		return nil, interpretError(err)
+	}
+	return kinds, nil
+}
+
 func (e *Executor) JobGetByID(ctx context.Context, params *riverdriver.JobGetByIDParams) (*rivertype.JobRow, error) {
 	job, err := dbsqlc.New().JobGetByID(schemaTemplateParam(ctx, params.Schema), e.dbtx, params.ID)
 	if err != nil {
@@ -200,8 +434,16 @@ func (e *Executor) JobGetByKindMany(ctx context.Context, params *riverdriver.Job
 	return mapSliceError(jobs, jobRowFromInternal)
 }
 
+func (e *Executor) JobGetFinalizedAfter(ctx context.Context, params *riverdriver.JobGetFinalizedAfterParams) ([]*rivertype.JobRow, error) {
+	jobs, err := dbsqlc.New().JobGetFinalizedAfter(schemaTemplateParam(ctx, params.Schema), e.dbtx, &dbsqlc.JobGetFinalizedAfterParams{FinalizedAt: params.FinalizedAt, ID: params.ID, Max: int32(min(params.Max, math.MaxInt32))}) //nolint:gosec
+	if err != nil {
+		return nil, interpretError(err)
+	}
+	return mapSliceError(jobs, jobRowFromInternal)
+}
+
 func (e *Executor) JobGetStuck(ctx context.Context, params *riverdriver.JobGetStuckParams) ([]*rivertype.JobRow, error) {
-	jobs, err := dbsqlc.New().JobGetStuck(schemaTemplateParam(ctx, params.Schema), e.dbtx, &dbsqlc.JobGetStuckParams{Max: int32(min(params.Max, math.MaxInt32)), StuckHorizon: params.StuckHorizon}) //nolint:gosec
+	jobs, err := dbsqlc.New().JobGetStuck(schemaTemplateParam(ctx, params.Schema), e.dbtx, &dbsqlc.JobGetStuckParams{Max: int32(min(params.Max, math.MaxInt32)), Now: params.Now, StuckHorizon: params.StuckHorizon}) //nolint:gosec
 	if err != nil {
 		return nil, interpretError(err)
 	}
@@ -358,6 +600,14 @@ func (e *Executor) JobInsertFull(ctx context.Context, params *riverdriver.JobIns
 	return jobRowFromInternal(job)
 }
 
+func (e *Executor) JobLeaseRenewMany(ctx context.Context, params *riverdriver.JobLeaseRenewManyParams) (int, error) {
+	numRenewed, err := dbsqlc.New().JobLeaseRenewMany(schemaTemplateParam(ctx, params.Schema), e.dbtx, &dbsqlc.JobLeaseRenewManyParams{
+		ExpiresAt: params.ExpiresAt,
+		ID:        params.ID,
+	})
+	return int(numRenewed), interpretError(err)
+}
+
 func (e *Executor) JobList(ctx context.Context, params *riverdriver.JobListParams) ([]*rivertype.JobRow, error) {
 	whereClause, err := replaceNamed(params.WhereClause, params.NamedArgs)
 	if err != nil {
@@ -425,6 +675,9 @@ func replaceNamed(query string, namedArgs map[string]any) (string, error) {
 		case bool, float32, float64, int, int16, int32, int64, string, uint, uint16, uint32, uint64:
 			escapedValue = escapeSinglePostgresValue(value)
 
+		case time.Time:
+			escapedValue = escapeSinglePostgresValue(typedValue.Format(time.RFC3339Nano))
+
 			// This is pretty awkward, but typedValue reverts back to `any` if
 			// any of these conditions are combined together, and that prevents
 			// us from ranging over the slice. Technically only `[]string` is
@@ -468,6 +721,17 @@ func replaceNamed(query string, namedArgs map[string]any) (string, error) {
 	return query, nil
 }
 
+func (e *Executor) JobMetadataMergeIfRunning(ctx context.Context, params *riverdriver.JobMetadataMergeIfRunningParams) (*rivertype.JobRow, error) {
+	job, err := dbsqlc.New().JobMetadataMergeIfRunning(schemaTemplateParam(ctx, params.Schema), e.dbtx, &dbsqlc.JobMetadataMergeIfRunningParams{
+		ID:              params.ID,
+		MetadataUpdates: string(params.MetadataUpdates),
+	})
+	if err != nil {
+		return nil, interpretError(err)
+	}
+	return jobRowFromInternal(job)
+}
+
 func (e *Executor) JobRescueMany(ctx context.Context, params *riverdriver.JobRescueManyParams) (*struct{}, error) {
 	err := dbsqlc.New().JobRescueMany(schemaTemplateParam(ctx, params.Schema), e.dbtx, &dbsqlc.JobRescueManyParams{
 		ID:          params.ID,
@@ -490,6 +754,14 @@ func (e *Executor) JobRetry(ctx context.Context, params *riverdriver.JobRetryPar
 	return jobRowFromInternal(job)
 }
 
+func (e *Executor) JobRetryMany(ctx context.Context, params *riverdriver.JobRetryManyParams) ([]*rivertype.JobRow, error) {
+	jobs, err := dbsqlc.New().JobRetryMany(schemaTemplateParam(ctx, params.Schema), e.dbtx, params.ID)
+	if err != nil {
+		return nil, interpretError(err)
+	}
+	return mapSliceError(jobs, jobRowFromInternal)
+}
+
 func (e *Executor) JobSchedule(ctx context.Context, params *riverdriver.JobScheduleParams) ([]*riverdriver.JobScheduleResult, error) {
 	scheduleResults, err := dbsqlc.New().JobSchedule(schemaTemplateParam(ctx, params.Schema), e.dbtx, &dbsqlc.JobScheduleParams{
 		Max: int64(params.Max),
@@ -507,6 +779,23 @@ func (e *Executor) JobSchedule(ctx context.Context, params *riverdriver.JobSched
 	})
 }
 
+func (e *Executor) JobSequencePromote(ctx context.Context, params *riverdriver.JobSequencePromoteParams) ([]*rivertype.JobRow, error) {
+	var afterSequenceKey sql.NullString
+	if params.AfterSequenceKey != nil {
+		afterSequenceKey = sql.NullString{String: *params.AfterSequenceKey, Valid: true}
+	}
+
+	jobs, err := dbsqlc.New().JobSequencePromote(schemaTemplateParam(ctx, params.Schema), e.dbtx, &dbsqlc.JobSequencePromoteParams{
+		AfterSequenceKey: afterSequenceKey,
+		Max:              int32(min(params.Max, math.MaxInt32)), //nolint:gosec
+		Now:              params.Now,
+	})
+	if err != nil {
+		return nil, interpretError(err)
+	}
+	return mapSliceError(jobs, jobRowFromInternal)
+}
+
 func (e *Executor) JobSetStateIfRunningMany(ctx context.Context, params *riverdriver.JobSetStateIfRunningManyParams) ([]*rivertype.JobRow, error) {
 	setStateParams := &dbsqlc.JobSetStateIfRunningManyParams{
 		IDs:                 params.ID,
@@ -560,6 +849,55 @@ func (e *Executor) JobSetStateIfRunningMany(ctx context.Context, params *riverdr
 	return mapSliceError(jobs, jobRowFromInternal)
 }
 
+func (e *Executor) JobStats(ctx context.Context, params *riverdriver.JobStatsParams) ([]*riverdriver.JobStatsResult, error) {
+	rows, err := dbsqlc.New().JobStats(schemaTemplateParam(ctx, params.Schema), e.dbtx, &dbsqlc.JobStatsParams{
+		FinalizedSince: params.FinalizedSince,
+		Kind:           params.Kind,
+		Queue:          params.Queues,
+	})
+	if err != nil {
+		return nil, interpretError(err)
+	}
+
+	// States without any jobs are omitted.
+	setCount := func(counts map[rivertype.JobState]int, state rivertype.JobState, count int64) {
+		if count > 0 {
+			counts[state] = int(count)
+		}
+	}
+
+	results := make([]*riverdriver.JobStatsResult, len(rows))
+	for i, stats := range rows {
+		result := &riverdriver.JobStatsResult{
+			CountsByState:          make(map[rivertype.JobState]int),
+			FinalizedCountsByState: make(map[rivertype.JobState]int),
+			Queue:                  stats.Queue,
+		}
+
+		setCount(result.CountsByState, rivertype.JobStateAvailable, stats.AvailableCount)
+		setCount(result.CountsByState, rivertype.JobStatePending, stats.PendingCount)
+		setCount(result.CountsByState, rivertype.JobStateRetryable, stats.RetryableCount)
+		setCount(result.CountsByState, rivertype.JobStateRunning, stats.RunningCount)
+		setCount(result.CountsByState, rivertype.JobStateScheduled, stats.ScheduledCount)
+		setCount(result.FinalizedCountsByState, rivertype.JobStateCancelled, stats.CancelledCount)
+		setCount(result.FinalizedCountsByState, rivertype.JobStateCompleted, stats.CompletedCount)
+		setCount(result.FinalizedCountsByState, rivertype.JobStateDiscarded, stats.DiscardedCount)
+
+		if err := json.Unmarshal([]byte(stats.RunningByClient), &result.RunningByClient); err != nil {
+			return nil, err
+		}
+
+		if stats.OldestAvailableAt != nil {
+			oldestAvailableAt := stats.OldestAvailableAt.UTC()
+			result.OldestAvailableAt = &oldestAvailableAt
+		}
+
+		results[i] = result
+	}
+
+	return results, nil
+}
+
 func (e *Executor) JobUpdate(ctx context.Context, params *riverdriver.JobUpdateParams) (*rivertype.JobRow, error) {
 	job, err := dbsqlc.New().JobUpdate(schemaTemplateParam(ctx, params.Schema), e.dbtx, &dbsqlc.JobUpdateParams{
 		ID:                  params.ID,
@@ -583,6 +921,42 @@ func (e *Executor) JobUpdate(ctx context.Context, params *riverdriver.JobUpdateP
 	return jobRowFromInternal(job)
 }
 
+func (e *Executor) JobUpdateIfQueued(ctx context.Context, params *riverdriver.JobUpdateIfQueuedParams) (*rivertype.JobRow, error) {
+	job, err := dbsqlc.New().JobUpdateIfQueued(schemaTemplateParam(ctx, params.Schema), e.dbtx, &dbsqlc.JobUpdateIfQueuedParams{
+		ID:                  params.ID,
+		MaxAttemptsDoUpdate: params.MaxAttemptsDoUpdate,
+		MaxAttempts:         int16(min(params.MaxAttempts, math.MaxInt16)), //nolint:gosec
+		MetadataDoMerge:     params.MetadataDoMerge,
+		MetadataDoUpdate:    params.MetadataDoUpdate,
+		Metadata:            string(params.Metadata),
+		Now:                 params.Now,
+		PriorityDoUpdate:    params.PriorityDoUpdate,
+		Priority:            int16(min(params.Priority, math.MaxInt16)), //nolint:gosec
+		QueueDoUpdate:       params.QueueDoUpdate,
+		Queue:               params.Queue,
+		ScheduledAtDoUpdate: params.ScheduledAtDoUpdate,
+		ScheduledAt:         params.ScheduledAt,
+		TagsDoUpdate:        params.TagsDoUpdate,
+		Tags:                params.Tags,
+	})
+	if err != nil {
+		return nil, interpretError(err)
+	}
+	return jobRowFromInternal(job)
+}
+
+func (e *Executor) JobWorkflowPromote(ctx context.Context, params *riverdriver.JobWorkflowPromoteParams) ([]*rivertype.JobRow, error) {
+	jobs, err := dbsqlc.New().JobWorkflowPromote(schemaTemplateParam(ctx, params.Schema), e.dbtx, &dbsqlc.JobWorkflowPromoteParams{
+		AfterID: params.AfterID,
+		Max:     int32(min(params.Max, math.MaxInt32)), //nolint:gosec
+		Now:     params.Now,
+	})
+	if err != nil {
+		return nil, interpretError(err)
+	}
+	return mapSliceError(jobs, jobRowFromInternal)
+}
+
 func (e *Executor) LeaderAttemptElect(ctx context.Context, params *riverdriver.LeaderElectParams) (bool, error) {
 	numElectionsWon, err := dbsqlc.New().LeaderAttemptElect(schemaTemplateParam(ctx, params.Schema), e.dbtx, &dbsqlc.LeaderAttemptElectParams{
 		LeaderID: params.LeaderID,
@@ -824,6 +1198,26 @@ func (e *Executor) QueueUpdate(ctx context.Context, params *riverdriver.QueueUpd
 	return queueFromInternal(queue), nil
 }
 
+func (e *Executor) RateLimitRefill(ctx context.Context, params *riverdriver.RateLimitRefillParams) (float64, error) {
+	tokens, err := dbsqlc.New().RateLimitRefill(schemaTemplateParam(ctx, params.Schema), e.dbtx, &dbsqlc.RateLimitRefillParams{
+		Burst:         float64(params.Burst),
+		Key:           params.Key,
+		RatePerSecond: params.RatePerSecond,
+	})
+	if err != nil {
+		return 0, interpretError(err)
+	}
+	return tokens, nil
+}
+
+func (e *Executor) RateLimitTake(ctx context.Context, params *riverdriver.RateLimitTakeParams) error {
+	err := dbsqlc.New().RateLimitTake(schemaTemplateParam(ctx, params.Schema), e.dbtx, &dbsqlc.RateLimitTakeParams{
+		Key:    params.Key,
+		Tokens: float64(params.Tokens),
+	})
+	return interpretError(err)
+}
+
 func (e *Executor) TableExists(ctx context.Context, params *riverdriver.TableExistsParams) (bool, error) {
 	// Different from other operations because the schemaAndTable name is a parameter.
 	schemaAndTable := params.Table
@@ -964,6 +1358,46 @@ func (w templateReplaceWrapper) QueryRowContext(ctx context.Context, sql string,
 	return w.dbtx.QueryRowContext(ctx, sql, args...)
 }
 
+func deadLetterFromInternal(internal *dbsqlc.RiverJobDeadLetter) (*rivertype.DeadLetter, error) {
+	var attemptedAt *time.Time
+	if internal.AttemptedAt != nil {
+		t := internal.AttemptedAt.UTC()
+		attemptedAt = &t
+	}
+
+	errors := make([]rivertype.AttemptError, len(internal.Errors))
+	for i, rawError := range internal.Errors {
+		if err := json.Unmarshal([]byte(rawError), &errors[i]); err != nil {
+			return nil, err
+		}
+	}
+
+	var finalizedAt *time.Time
+	if internal.FinalizedAt != nil {
+		t := internal.FinalizedAt.UTC()
+		finalizedAt = &t
+	}
+
+	return &rivertype.DeadLetter{
+		ID:             internal.ID,
+		Attempt:        max(int(internal.Attempt), 0),
+		AttemptedAt:    attemptedAt,
+		AttemptedBy:    internal.AttemptedBy,
+		CreatedAt:      internal.CreatedAt.UTC(),
+		DeadLetteredAt: internal.DeadLetteredAt.UTC(),
+		EncodedArgs:    []byte(internal.Args),
+		Errors:         errors,
+		FinalizedAt:    finalizedAt,
+		Kind:           internal.Kind,
+		MaxAttempts:    max(int(internal.MaxAttempts), 0),
+		Metadata:       []byte(internal.Metadata),
+		Priority:       max(int(internal.Priority), 0),
+		Queue:          internal.Queue,
+		ScheduledAt:    internal.ScheduledAt.UTC(),
+		Tags:           internal.Tags,
+	}, nil
+}
+
 func jobRowFromInternal(internal *dbsqlc.RiverJob) (*rivertype.JobRow, error) {
 	var attemptedAt *time.Time
 	if internal.AttemptedAt != nil {
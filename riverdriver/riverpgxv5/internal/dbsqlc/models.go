@@ -100,6 +100,25 @@ type RiverJob struct {
 	UniqueStates pgtype.Bits
 }
 
+type RiverJobDeadLetter struct {
+	ID             int64
+	Args           []byte
+	Attempt        int16
+	AttemptedAt    *time.Time
+	AttemptedBy    []string
+	CreatedAt      time.Time
+	DeadLetteredAt time.Time
+	Errors         [][]byte
+	FinalizedAt    *time.Time
+	Kind           string
+	MaxAttempts    int16
+	Metadata       []byte
+	Priority       int16
+	Queue          string
+	ScheduledAt    time.Time
+	Tags           []string
+}
+
 type RiverLeader struct {
 	ElectedAt time.Time
 	ExpiresAt time.Time
@@ -120,3 +139,9 @@ type RiverQueue struct {
 	PausedAt  *time.Time
 	UpdatedAt time.Time
 }
+
+type RiverRateLimit struct {
+	Key       string
+	Tokens    float64
+	UpdatedAt time.Time
+}
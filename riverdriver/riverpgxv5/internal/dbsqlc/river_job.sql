@@ -37,6 +37,50 @@ CREATE TABLE river_job(
     CONSTRAINT kind_length CHECK (char_length(kind) > 0 AND char_length(kind) < 128)
 );
 
+-- name: JobBatchCountByState :many
+SELECT state, count(*)
+FROM /* TEMPLATE: schema */river_job
+WHERE metadata @> jsonb_build_object('river:batch_id', @batch_id::text)
+GROUP BY state;
+
+-- name: JobBatchOnFinishPromote :many
+WITH pending_on_finish AS (
+    SELECT id, metadata->>'river:batch_on_finish' AS batch_id
+    FROM /* TEMPLATE: schema */river_job
+    WHERE state = 'pending'
+        AND metadata ? 'river:batch_on_finish'
+        AND id > @after_id::bigint
+    ORDER BY id
+    LIMIT @max::integer
+    FOR UPDATE SKIP LOCKED
+),
+finished_batches AS (
+    SELECT pending_on_finish.id
+    FROM pending_on_finish
+    WHERE NOT EXISTS (
+        SELECT 1
+        FROM /* TEMPLATE: schema */river_job AS batch_job
+        WHERE batch_job.metadata @> jsonb_build_object('river:batch_id', pending_on_finish.batch_id)
+            AND batch_job.state NOT IN ('cancelled', 'completed', 'discarded')
+    )
+),
+updated_jobs AS (
+    UPDATE /* TEMPLATE: schema */river_job
+    SET
+        state = CASE WHEN river_job.scheduled_at > @now::timestamptz THEN 'scheduled'::river_job_state
+                     ELSE 'available'::river_job_state END
+    FROM finished_batches
+    WHERE river_job.id = finished_batches.id
+    RETURNING river_job.*
+)
+SELECT *
+FROM /* TEMPLATE: schema */river_job
+WHERE id IN (SELECT id FROM pending_on_finish)
+    AND id NOT IN (SELECT id FROM updated_jobs)
+UNION
+SELECT *
+FROM updated_jobs;
+
 -- name: JobCancel :one
 WITH locked_job AS (
     SELECT
@@ -80,11 +124,69 @@ UNION
 SELECT *
 FROM updated_job;
 
+-- name: JobCancelMany :many
+WITH locked_jobs AS (
+    SELECT
+        id, queue, state, finalized_at
+    FROM /* TEMPLATE: schema */river_job
+    WHERE id = any(@id::bigint[])
+        AND state NOT IN ('cancelled', 'completed', 'discarded')
+        AND finalized_at IS NULL
+    ORDER BY id
+    FOR UPDATE
+),
+notification AS (
+    SELECT
+        id,
+        pg_notify(
+            concat(coalesce(sqlc.narg('schema')::text, current_schema()), '.', @control_topic::text),
+            json_build_object('action', 'cancel', 'job_id', id, 'queue', queue)::text
+        )
+    FROM
+        locked_jobs
+)
+UPDATE /* TEMPLATE: schema */river_job
+SET
+    -- If the job is actively running, we want to let its current client and
+    -- producer handle the cancellation. Otherwise, immediately cancel it.
+    state = CASE WHEN state = 'running' THEN state ELSE 'cancelled' END,
+    finalized_at = CASE WHEN state = 'running' THEN finalized_at ELSE now() END,
+    -- Mark the job as cancelled by query so that the rescuer knows not to
+    -- rescue it, even if it gets stuck in the running state:
+    metadata = jsonb_set(metadata, '{cancel_attempted_at}'::text[], @cancel_attempted_at::jsonb, true)
+FROM notification
+WHERE river_job.id = notification.id
+RETURNING river_job.*;
+
+-- name: JobCount :one
+SELECT count(*)
+FROM /* TEMPLATE: schema */river_job
+WHERE /* TEMPLATE_BEGIN: where_clause */ true /* TEMPLATE_END */;
+
 -- name: JobCountByState :one
 SELECT count(*)
 FROM /* TEMPLATE: schema */river_job
 WHERE state = @state;
 
+-- name: JobCountByQueueAndState :many
+SELECT queue, state, count(*)
+FROM /* TEMPLATE: schema */river_job
+WHERE state = any(@state::text[]::river_job_state[])
+GROUP BY queue, state
+ORDER BY queue, state;
+
+-- Counts jobs grouped by the values of an expression, which may be a set
+-- returning function like `unnest(tags)` to count each element of an array.
+-- name: JobCountGrouped :many
+SELECT group_value::text, count(*)
+FROM (
+    SELECT /* TEMPLATE_BEGIN: group_by_expr */ kind /* TEMPLATE_END */ AS group_value
+    FROM /* TEMPLATE: schema */river_job
+    WHERE /* TEMPLATE_BEGIN: where_clause */ true /* TEMPLATE_END */
+) AS filtered_job
+GROUP BY group_value
+ORDER BY group_value;
+
 -- name: JobDelete :one
 WITH job_to_delete AS (
     SELECT id
@@ -127,6 +229,19 @@ WITH deleted_jobs AS (
 SELECT count(*)
 FROM deleted_jobs;
 
+-- name: JobDeleteMany :many
+DELETE FROM /* TEMPLATE: schema */river_job
+WHERE id IN (
+    SELECT id
+    FROM /* TEMPLATE: schema */river_job
+    WHERE id = any(@id::bigint[])
+        -- Do not touch running jobs:
+        AND state != 'running'
+    ORDER BY id
+    FOR UPDATE
+)
+RETURNING *;
+
 -- name: JobGetAvailable :many
 WITH locked_jobs AS (
     SELECT
@@ -137,11 +252,112 @@ WITH locked_jobs AS (
         state = 'available'
         AND queue = @queue::text
         AND scheduled_at <= coalesce(sqlc.narg('now')::timestamptz, now())
+        AND (
+            cardinality(@limited_kind::text[]) = 0
+            OR NOT kind = any(@limited_kind::text[])
+            OR id IN (
+                SELECT limited_kind_job.id
+                FROM unnest(@limited_kind::text[], @limited_kind_max::integer[]) AS limited_kind(kind, max)
+                CROSS JOIN LATERAL (
+                    SELECT id
+                    FROM /* TEMPLATE: schema */river_job
+                    WHERE state = 'available'
+                        AND queue = @queue::text
+                        AND kind = limited_kind.kind
+                        AND scheduled_at <= coalesce(sqlc.narg('now')::timestamptz, now())
+                    ORDER BY
+                        priority ASC,
+                        scheduled_at ASC,
+                        id ASC
+                    LIMIT limited_kind.max
+                ) AS limited_kind_job
+            )
+        )
+        AND (
+            @partition_limit::integer <= 0
+            OR id IN (
+                SELECT partition_job.id
+                FROM (
+                    SELECT
+                        available_job.id,
+                        row_number() OVER (
+                            PARTITION BY available_job.partition_kind, available_job.partition_args
+                            ORDER BY
+                                available_job.priority ASC,
+                                available_job.scheduled_at ASC,
+                                available_job.id ASC
+                        ) AS partition_rank,
+                        available_job.partition_kind,
+                        available_job.partition_args
+                    FROM (
+                        SELECT
+                            id,
+                            priority,
+                            scheduled_at,
+                            CASE WHEN @partition_by_kind::boolean THEN kind ELSE '' END AS partition_kind,
+                            CASE WHEN @partition_by_args::boolean THEN coalesce(metadata -> 'river:partition', '{}'::jsonb) ELSE '{}'::jsonb END AS partition_args
+                        FROM /* TEMPLATE: schema */river_job
+                        WHERE state = 'available'
+                            AND queue = @queue::text
+                            AND scheduled_at <= coalesce(sqlc.narg('now')::timestamptz, now())
+                            -- Jobs in partitions already at their limit can't
+                            -- be fetched, so they're excluded before the
+                            -- candidate limit below. Otherwise one partition's
+                            -- backlog could fill every candidate and starve the
+                            -- rest of the queue.
+                            AND (
+                                CASE WHEN @partition_by_kind::boolean THEN kind ELSE '' END,
+                                CASE WHEN @partition_by_args::boolean THEN coalesce(metadata -> 'river:partition', '{}'::jsonb) ELSE '{}'::jsonb END
+                            ) NOT IN (
+                                SELECT
+                                    CASE WHEN @partition_by_kind::boolean THEN kind ELSE '' END,
+                                    CASE WHEN @partition_by_args::boolean THEN coalesce(metadata -> 'river:partition', '{}'::jsonb) ELSE '{}'::jsonb END
+                                FROM /* TEMPLATE: schema */river_job
+                                WHERE state = 'running'
+                                    AND queue = @queue::text
+                                GROUP BY 1, 2
+                                HAVING count(*) >= @partition_limit::integer
+                            )
+                        -- Only the queue's most urgent remaining jobs are
+                        -- ranked so that a deep queue doesn't have every
+                        -- available job read and sorted on each fetch.
+                        ORDER BY
+                            priority ASC,
+                            scheduled_at ASC,
+                            id ASC
+                        LIMIT CASE WHEN @partition_candidate_max::integer > 0 THEN @partition_candidate_max::integer END
+                    ) AS available_job
+                ) AS partition_job
+                LEFT JOIN (
+                    SELECT
+                        CASE WHEN @partition_by_kind::boolean THEN kind ELSE '' END AS partition_kind,
+                        CASE WHEN @partition_by_args::boolean THEN coalesce(metadata -> 'river:partition', '{}'::jsonb) ELSE '{}'::jsonb END AS partition_args,
+                        count(*) AS running_count
+                    FROM /* TEMPLATE: schema */river_job
+                    WHERE state = 'running'
+                        AND queue = @queue::text
+                    GROUP BY 1, 2
+                ) AS partition_running ON partition_running.partition_kind = partition_job.partition_kind
+                    AND partition_running.partition_args = partition_job.partition_args
+                WHERE partition_job.partition_rank <= @partition_limit::integer - coalesce(partition_running.running_count, 0)
+            )
+        )
     ORDER BY
         priority ASC,
         scheduled_at ASC,
         id ASC
-    LIMIT @max::integer
+    LIMIT CASE
+        WHEN @global_limit::integer > 0 THEN least(
+            @max::integer,
+            greatest(0, @global_limit::integer - (
+                SELECT count(*)
+                FROM /* TEMPLATE: schema */river_job
+                WHERE state = 'running'
+                    AND queue = @queue::text
+            ))
+        )
+        ELSE @max::integer
+    END
     FOR UPDATE
     SKIP LOCKED
 )
@@ -151,7 +367,15 @@ SET
     state = 'running',
     attempt = river_job.attempt + 1,
     attempted_at = now(),
-    attempted_by = array_append(river_job.attempted_by, @attempted_by::text)
+    attempted_by = array_append(river_job.attempted_by, @attempted_by::text),
+    -- Takes a lease for the new attempt in the same statement when leases are
+    -- enabled so that a job is never running without one.
+    metadata = CASE
+        WHEN sqlc.narg('lease_expires_at')::timestamptz IS NULL THEN river_job.metadata
+        ELSE river_job.metadata || jsonb_build_object(
+            'river:lease', jsonb_build_object('attempt', river_job.attempt + 1, 'expires_at', sqlc.narg('lease_expires_at')::timestamptz)
+        )
+    END
 FROM
     locked_jobs
 WHERE
@@ -159,6 +383,209 @@ WHERE
 RETURNING
     river_job.*;
 
+-- name: JobGetAvailableFair :many
+WITH fair_job AS (
+    SELECT
+        fair_candidate.id,
+        fair_candidate.fair_rank
+    FROM unnest(@fair_key::text[]) AS fair(key)
+    CROSS JOIN LATERAL (
+        SELECT
+            key_job.id,
+            row_number() OVER (ORDER BY key_job.scheduled_at ASC, key_job.id ASC) AS fair_rank
+        FROM (
+            SELECT id, scheduled_at
+            FROM /* TEMPLATE: schema */river_job
+            WHERE state = 'available'
+                AND queue = @queue::text
+                AND priority = @fair_priority::smallint
+                AND coalesce(metadata -> 'river:partition', '{}'::jsonb) = fair.key::jsonb
+                AND scheduled_at <= coalesce(sqlc.narg('now')::timestamptz, now())
+            ORDER BY
+                scheduled_at ASC,
+                id ASC
+            LIMIT @fair_max_per_key::integer
+        ) AS key_job
+    ) AS fair_candidate
+),
+locked_jobs AS (
+    SELECT
+        river_job.id
+    FROM
+        /* TEMPLATE: schema */river_job
+        INNER JOIN fair_job ON fair_job.id = river_job.id
+    WHERE
+        river_job.state = 'available'
+        AND (
+            cardinality(@limited_kind::text[]) = 0
+            OR NOT river_job.kind = any(@limited_kind::text[])
+            OR river_job.id IN (
+                SELECT limited_kind_job.id
+                FROM unnest(@limited_kind::text[], @limited_kind_max::integer[]) AS limited_kind(kind, max)
+                CROSS JOIN LATERAL (
+                    SELECT id
+                    FROM /* TEMPLATE: schema */river_job
+                    WHERE state = 'available'
+                        AND queue = @queue::text
+                        AND kind = limited_kind.kind
+                        AND scheduled_at <= coalesce(sqlc.narg('now')::timestamptz, now())
+                    ORDER BY
+                        priority ASC,
+                        scheduled_at ASC,
+                        id ASC
+                    LIMIT limited_kind.max
+                ) AS limited_kind_job
+            )
+        )
+        AND (
+            @partition_limit::integer <= 0
+            OR river_job.id IN (
+                SELECT partition_job.id
+                FROM (
+                    SELECT
+                        available_job.id,
+                        row_number() OVER (
+                            PARTITION BY available_job.partition_kind, available_job.partition_args
+                            ORDER BY
+                                available_job.priority ASC,
+                                available_job.scheduled_at ASC,
+                                available_job.id ASC
+                        ) AS partition_rank,
+                        available_job.partition_kind,
+                        available_job.partition_args
+                    FROM (
+                        SELECT
+                            id,
+                            priority,
+                            scheduled_at,
+                            CASE WHEN @partition_by_kind::boolean THEN kind ELSE '' END AS partition_kind,
+                            CASE WHEN @partition_by_args::boolean THEN coalesce(metadata -> 'river:partition', '{}'::jsonb) ELSE '{}'::jsonb END AS partition_args
+                        FROM /* TEMPLATE: schema */river_job
+                        -- Only jobs this fetch could take are ranked, which
+                        -- keeps the ranking to at most FairMaxPerKey jobs for
+                        -- each fair key however deep the queue is.
+                        WHERE id IN (SELECT fair_job.id FROM fair_job)
+                    ) AS available_job
+                ) AS partition_job
+                LEFT JOIN (
+                    SELECT
+                        CASE WHEN @partition_by_kind::boolean THEN kind ELSE '' END AS partition_kind,
+                        CASE WHEN @partition_by_args::boolean THEN coalesce(metadata -> 'river:partition', '{}'::jsonb) ELSE '{}'::jsonb END AS partition_args,
+                        count(*) AS running_count
+                    FROM /* TEMPLATE: schema */river_job
+                    WHERE state = 'running'
+                        AND queue = @queue::text
+                    GROUP BY 1, 2
+                ) AS partition_running ON partition_running.partition_kind = partition_job.partition_kind
+                    AND partition_running.partition_args = partition_job.partition_args
+                WHERE partition_job.partition_rank <= @partition_limit::integer - coalesce(partition_running.running_count, 0)
+            )
+        )
+    ORDER BY
+        fair_job.fair_rank ASC,
+        river_job.scheduled_at ASC,
+        river_job.id ASC
+    LIMIT CASE
+        WHEN @global_limit::integer > 0 THEN least(
+            @max::integer,
+            greatest(0, @global_limit::integer - (
+                SELECT count(*)
+                FROM /* TEMPLATE: schema */river_job
+                WHERE state = 'running'
+                    AND queue = @queue::text
+            ))
+        )
+        ELSE @max::integer
+    END
+    FOR UPDATE OF river_job
+    SKIP LOCKED
+)
+UPDATE
+    /* TEMPLATE: schema */river_job
+SET
+    state = 'running',
+    attempt = river_job.attempt + 1,
+    attempted_at = now(),
+    attempted_by = array_append(river_job.attempted_by, @attempted_by::text),
+    -- Takes a lease for the new attempt in the same statement when leases are
+    -- enabled so that a job is never running without one.
+    metadata = CASE
+        WHEN sqlc.narg('lease_expires_at')::timestamptz IS NULL THEN river_job.metadata
+        ELSE river_job.metadata || jsonb_build_object(
+            'river:lease', jsonb_build_object('attempt', river_job.attempt + 1, 'expires_at', sqlc.narg('lease_expires_at')::timestamptz)
+        )
+    END
+FROM
+    locked_jobs
+WHERE
+    river_job.id = locked_jobs.id
+RETURNING
+    river_job.*;
+
+-- name: JobGetAvailableFairKeys :many
+WITH RECURSIVE top_priority AS (
+    SELECT priority
+    FROM /* TEMPLATE: schema */river_job
+    WHERE state = 'available'
+        AND queue = @queue::text
+        AND scheduled_at <= coalesce(sqlc.narg('now')::timestamptz, now())
+    ORDER BY priority ASC
+    LIMIT 1
+),
+fair_key AS (
+    (
+        SELECT
+            coalesce(metadata -> 'river:partition', '{}'::jsonb) AS key,
+            1 AS depth
+        FROM /* TEMPLATE: schema */river_job
+        WHERE state = 'available'
+            AND queue = @queue::text
+            AND priority = (SELECT priority FROM top_priority)
+            AND scheduled_at <= coalesce(sqlc.narg('now')::timestamptz, now())
+            AND (
+                sqlc.narg('after')::text IS NULL
+                OR coalesce(metadata -> 'river:partition', '{}'::jsonb) > sqlc.narg('after')::text::jsonb
+            )
+        ORDER BY 1
+        LIMIT 1
+    )
+    UNION ALL
+    SELECT
+        next_key.key,
+        fair_key.depth + 1
+    FROM fair_key
+    CROSS JOIN LATERAL (
+        SELECT coalesce(metadata -> 'river:partition', '{}'::jsonb) AS key
+        FROM /* TEMPLATE: schema */river_job
+        WHERE state = 'available'
+            AND queue = @queue::text
+            AND priority = (SELECT priority FROM top_priority)
+            AND scheduled_at <= coalesce(sqlc.narg('now')::timestamptz, now())
+            AND coalesce(metadata -> 'river:partition', '{}'::jsonb) > fair_key.key
+        ORDER BY 1
+        LIMIT 1
+    ) AS next_key
+    WHERE fair_key.depth < @max::integer
+)
+SELECT
+    (SELECT priority FROM top_priority)::smallint AS priority,
+    fair_key.key::text AS fair_key
+FROM fair_key
+ORDER BY fair_key.depth;
+
+-- name: JobGetAvailableKinds :many
+SELECT limited_kind.kind
+FROM unnest(@kind::text[]) AS limited_kind(kind)
+WHERE EXISTS (
+    SELECT 1
+    FROM /* TEMPLATE: schema */river_job
+    WHERE state = 'available'
+        AND queue = @queue::text
+        AND kind = limited_kind.kind
+        AND scheduled_at <= coalesce(sqlc.narg('now')::timestamptz, now())
+)
+ORDER BY limited_kind.kind;
+
 -- name: JobGetByKindAndUniqueProperties :one
 SELECT *
 FROM /* TEMPLATE: schema */river_job
@@ -186,11 +613,34 @@ FROM /* TEMPLATE: schema */river_job
 WHERE id = any(@id::bigint[])
 ORDER BY id;
 
+-- name: JobGetFinalizedAfter :many
+SELECT *
+FROM /* TEMPLATE: schema */river_job
+WHERE state IN ('cancelled', 'completed', 'discarded')
+    AND finalized_at IS NOT NULL
+    AND (finalized_at, id) > (@finalized_at::timestamptz, @id::bigint)
+ORDER BY finalized_at, id
+LIMIT @max::int;
+
 -- name: JobGetStuck :many
 SELECT *
 FROM /* TEMPLATE: schema */river_job
 WHERE state = 'running'
-    AND attempted_at < @stuck_horizon::timestamptz
+    AND (
+        -- Jobs holding a lease for their current attempt are stuck once the
+        -- lease expires.
+        (
+            (metadata -> 'river:lease' ->> 'attempt')::smallint = attempt
+            AND (metadata -> 'river:lease' ->> 'expires_at')::timestamptz < @now::timestamptz
+        )
+        -- Otherwise, they're stuck after running longer than the horizon. This
+        -- is kept as a plain predicate on attempted_at rather than folded into
+        -- a CASE so that it remains usable by an index.
+        OR (
+            attempted_at < @stuck_horizon::timestamptz
+            AND (metadata -> 'river:lease' ->> 'attempt')::smallint IS DISTINCT FROM attempt
+        )
+    )
 ORDER BY id
 LIMIT @max;
 
@@ -315,6 +765,14 @@ INSERT INTO /* TEMPLATE: schema */river_job(
     @unique_states
 ) RETURNING *;
 
+-- name: JobLeaseRenewMany :execrows
+UPDATE /* TEMPLATE: schema */river_job
+SET metadata = metadata || jsonb_build_object(
+    'river:lease', jsonb_build_object('attempt', attempt, 'expires_at', @expires_at::timestamptz)
+)
+WHERE id = any(@id::bigint[])
+    AND state = 'running';
+
 -- name: JobList :many
 SELECT *
 FROM /* TEMPLATE: schema */river_job
@@ -322,6 +780,14 @@ WHERE /* TEMPLATE_BEGIN: where_clause */ 1 /* TEMPLATE_END */
 ORDER BY /* TEMPLATE_BEGIN: order_by_clause */ id /* TEMPLATE_END */
 LIMIT @max::int;
 
+-- Merges metadata into a job, but only while it's still running.
+-- name: JobMetadataMergeIfRunning :one
+UPDATE /* TEMPLATE: schema */river_job
+SET metadata = metadata || @metadata_updates::jsonb
+WHERE id = @id
+    AND state = 'running'
+RETURNING *;
+
 -- Run by the rescuer to queue for retry or discard depending on job state.
 -- name: JobRescueMany :exec
 UPDATE /* TEMPLATE: schema */river_job
@@ -370,6 +836,28 @@ UNION
 SELECT *
 FROM updated_job;
 
+-- name: JobRetryMany :many
+WITH jobs_to_update AS (
+    SELECT id
+    FROM /* TEMPLATE: schema */river_job
+    WHERE id = any(@id::bigint[])
+        -- Do not touch running jobs:
+        AND state != 'running'
+        -- If the job is already available with a prior scheduled_at, leave it alone.
+        AND NOT (state = 'available' AND scheduled_at < now())
+    ORDER BY id
+    FOR UPDATE
+)
+UPDATE /* TEMPLATE: schema */river_job
+SET
+    state = 'available',
+    scheduled_at = now(),
+    max_attempts = CASE WHEN attempt = max_attempts THEN max_attempts + 1 ELSE max_attempts END,
+    finalized_at = NULL
+FROM jobs_to_update
+WHERE river_job.id = jobs_to_update.id
+RETURNING river_job.*;
+
 -- name: JobSchedule :many
 WITH jobs_to_schedule AS (
     SELECT
@@ -451,6 +939,98 @@ SELECT
 FROM /* TEMPLATE: schema */river_job
 JOIN updated_jobs ON river_job.id = updated_jobs.id;
 
+-- name: JobSequencePromote :many
+WITH RECURSIVE pending_sequence_head AS (
+    -- Skip scan over river_job_sequence_pending_index that finds only the
+    -- first pending job of each sequence, since no other pending job can be
+    -- promoted until it has been. Sequences are visited in key order, starting
+    -- after the given key.
+    (
+        SELECT
+            id,
+            metadata ->> 'river:sequence_key' AS sequence_key,
+            1 AS depth
+        FROM /* TEMPLATE: schema */river_job
+        WHERE state = 'pending'
+            AND metadata ? 'river:sequence_key'
+            AND (
+                sqlc.narg('after_sequence_key')::text IS NULL
+                OR metadata ->> 'river:sequence_key' > sqlc.narg('after_sequence_key')::text
+            )
+        ORDER BY metadata ->> 'river:sequence_key', id
+        LIMIT 1
+    )
+    UNION ALL
+    SELECT
+        next_head.id,
+        next_head.sequence_key,
+        pending_sequence_head.depth + 1
+    FROM pending_sequence_head
+    CROSS JOIN LATERAL (
+        SELECT
+            id,
+            metadata ->> 'river:sequence_key' AS sequence_key
+        FROM /* TEMPLATE: schema */river_job
+        WHERE state = 'pending'
+            AND metadata ? 'river:sequence_key'
+            AND metadata ->> 'river:sequence_key' > pending_sequence_head.sequence_key
+        ORDER BY metadata ->> 'river:sequence_key', id
+        LIMIT 1
+    ) AS next_head
+    WHERE pending_sequence_head.depth < @max::integer
+),
+pending_sequence_jobs AS (
+    SELECT
+        river_job.id,
+        pending_sequence_head.sequence_key,
+        coalesce((river_job.metadata->>'river:sequence_continue_on_discarded')::boolean, false) AS continue_on_discarded
+    FROM /* TEMPLATE: schema */river_job
+    INNER JOIN pending_sequence_head ON pending_sequence_head.id = river_job.id
+    WHERE river_job.state = 'pending'
+    FOR UPDATE OF river_job SKIP LOCKED
+),
+sequence_heads AS (
+    SELECT pending_sequence_jobs.id
+    FROM pending_sequence_jobs
+    LEFT JOIN LATERAL (
+        SELECT previous_job.state
+        FROM /* TEMPLATE: schema */river_job AS previous_job
+        -- Matches river_job_sequence_key_index so the most recent previous
+        -- job in the sequence is found with a backward index scan.
+        WHERE previous_job.metadata ? 'river:sequence_key'
+            AND previous_job.metadata ->> 'river:sequence_key' = pending_sequence_jobs.sequence_key
+            AND previous_job.id < pending_sequence_jobs.id
+        ORDER BY previous_job.id DESC
+        LIMIT 1
+    ) AS previous_job ON true
+    WHERE previous_job.state IS NULL
+        OR previous_job.state = 'completed'
+        OR (
+            previous_job.state IN ('cancelled', 'discarded')
+            AND pending_sequence_jobs.continue_on_discarded
+        )
+),
+updated_jobs AS (
+    UPDATE /* TEMPLATE: schema */river_job
+    SET
+        state = CASE WHEN river_job.scheduled_at > @now::timestamptz THEN 'scheduled'::river_job_state
+                     ELSE 'available'::river_job_state END
+    FROM sequence_heads
+    WHERE river_job.id = sequence_heads.id
+    RETURNING river_job.*
+)
+SELECT *
+FROM (
+    SELECT *
+    FROM /* TEMPLATE: schema */river_job
+    WHERE id IN (SELECT id FROM pending_sequence_jobs)
+        AND id NOT IN (SELECT id FROM updated_jobs)
+    UNION
+    SELECT *
+    FROM updated_jobs
+) AS sequence_job
+ORDER BY sequence_job.metadata ->> 'river:sequence_key';
+
 -- name: JobSetStateIfRunningMany :many
 WITH job_input AS (
     SELECT
@@ -529,6 +1109,74 @@ WHERE id IN (SELECT id FROM job_input)
 UNION SELECT * FROM updated_metadata_only
 UNION SELECT * FROM updated_running;
 
+-- Statistics for queues, optionally limited to a kind, computed in a single
+-- statement so that they're consistent with each other. A row is returned for
+-- every given queue, including those without any jobs. Unfinalized jobs are
+-- counted in full, but finalized jobs only if they were finalized since
+-- @finalized_since so that the cost of the query doesn't grow with the number
+-- of jobs retained.
+-- name: JobStats :many
+WITH queue_name AS (
+    SELECT DISTINCT unnest(@queue::text[]) AS queue
+),
+unfinalized_stats AS (
+    SELECT
+        queue,
+        count(*) FILTER (WHERE state = 'available') AS available_count,
+        count(*) FILTER (WHERE state = 'pending') AS pending_count,
+        count(*) FILTER (WHERE state = 'retryable') AS retryable_count,
+        count(*) FILTER (WHERE state = 'running') AS running_count,
+        count(*) FILTER (WHERE state = 'scheduled') AS scheduled_count,
+        min(scheduled_at) FILTER (WHERE state = 'available') AS oldest_available_at
+    FROM /* TEMPLATE: schema */river_job
+    WHERE state IN ('available', 'pending', 'retryable', 'running', 'scheduled')
+        AND queue = any(@queue::text[])
+        AND (@kind::text = '' OR kind = @kind::text)
+    GROUP BY queue
+),
+finalized_stats AS (
+    SELECT
+        queue,
+        count(*) FILTER (WHERE state = 'cancelled') AS cancelled_count,
+        count(*) FILTER (WHERE state = 'completed') AS completed_count,
+        count(*) FILTER (WHERE state = 'discarded') AS discarded_count
+    FROM /* TEMPLATE: schema */river_job
+    WHERE state IN ('cancelled', 'completed', 'discarded')
+        AND queue = any(@queue::text[])
+        AND (@kind::text = '' OR kind = @kind::text)
+        AND finalized_at >= @finalized_since::timestamptz
+    GROUP BY queue
+),
+running_by_client AS (
+    SELECT queue, jsonb_object_agg(client_id, client_count) AS running_by_client
+    FROM (
+        SELECT queue, coalesce(attempted_by[array_upper(attempted_by, 1)], '') AS client_id, count(*) AS client_count
+        FROM /* TEMPLATE: schema */river_job
+        WHERE state = 'running'
+            AND queue = any(@queue::text[])
+            AND (@kind::text = '' OR kind = @kind::text)
+        GROUP BY queue, client_id
+    ) AS running_counts
+    GROUP BY queue
+)
+SELECT
+    queue_name.queue::text AS queue,
+    coalesce(unfinalized_stats.available_count, 0)::bigint AS available_count,
+    coalesce(unfinalized_stats.pending_count, 0)::bigint AS pending_count,
+    coalesce(unfinalized_stats.retryable_count, 0)::bigint AS retryable_count,
+    coalesce(unfinalized_stats.running_count, 0)::bigint AS running_count,
+    coalesce(unfinalized_stats.scheduled_count, 0)::bigint AS scheduled_count,
+    unfinalized_stats.oldest_available_at::timestamptz AS oldest_available_at,
+    coalesce(finalized_stats.cancelled_count, 0)::bigint AS cancelled_count,
+    coalesce(finalized_stats.completed_count, 0)::bigint AS completed_count,
+    coalesce(finalized_stats.discarded_count, 0)::bigint AS discarded_count,
+    coalesce(running_by_client.running_by_client, '{}')::jsonb AS running_by_client
+FROM queue_name
+    LEFT JOIN unfinalized_stats ON unfinalized_stats.queue = queue_name.queue
+    LEFT JOIN finalized_stats ON finalized_stats.queue = queue_name.queue
+    LEFT JOIN running_by_client ON running_by_client.queue = queue_name.queue
+ORDER BY queue_name.queue;
+
 -- A generalized update for any property on a job. This brings in a large number
 -- of parameters and therefore may be more suitable for testing than production.
 -- name: JobUpdate :one
@@ -542,3 +1190,101 @@ SET
     state = CASE WHEN @state_do_update::boolean THEN @state ELSE state END
 WHERE id = @id
 RETURNING *;
+
+-- name: JobUpdateIfQueued :one
+WITH job_to_update AS (
+    SELECT id
+    FROM /* TEMPLATE: schema */river_job
+    WHERE river_job.id = @id
+    FOR UPDATE
+),
+updated_job AS (
+    UPDATE /* TEMPLATE: schema */river_job
+    SET
+        max_attempts = CASE WHEN @max_attempts_do_update::boolean THEN @max_attempts::smallint ELSE max_attempts END,
+        metadata = CASE WHEN @metadata_do_merge::boolean THEN metadata || @metadata::jsonb
+                        WHEN @metadata_do_update::boolean THEN @metadata::jsonb
+                        ELSE metadata END,
+        priority = CASE WHEN @priority_do_update::boolean THEN @priority::smallint ELSE priority END,
+        queue = CASE WHEN @queue_do_update::boolean THEN @queue::text ELSE queue END,
+        scheduled_at = CASE WHEN @scheduled_at_do_update::boolean THEN @scheduled_at::timestamptz ELSE scheduled_at END,
+        -- Moving an available job into the future makes it scheduled, and
+        -- moving a scheduled job into the past makes it available, the same as
+        -- if it'd been inserted with the new scheduled_at.
+        state = CASE WHEN @scheduled_at_do_update::boolean AND state = 'available' AND @scheduled_at::timestamptz > coalesce(sqlc.narg('now')::timestamptz, now()) THEN 'scheduled'
+                     WHEN @scheduled_at_do_update::boolean AND state = 'scheduled' AND @scheduled_at::timestamptz <= coalesce(sqlc.narg('now')::timestamptz, now()) THEN 'available'
+                     ELSE state END,
+        tags = CASE WHEN @tags_do_update::boolean THEN @tags::varchar(255)[] ELSE tags END
+    FROM job_to_update
+    WHERE river_job.id = job_to_update.id
+        -- Only jobs still waiting in the queue can be updated:
+        AND river_job.state IN ('available', 'pending', 'retryable', 'scheduled')
+    RETURNING river_job.*
+)
+SELECT *
+FROM /* TEMPLATE: schema */river_job
+WHERE id = @id::bigint
+    AND id NOT IN (SELECT id FROM updated_job)
+UNION
+SELECT *
+FROM updated_job;
+
+-- name: JobWorkflowPromote :many
+WITH pending_tasks AS (
+    SELECT id, metadata
+    FROM /* TEMPLATE: schema */river_job
+    WHERE state = 'pending'
+        AND metadata ? 'river:workflow_id'
+        AND id > @after_id::bigint
+    ORDER BY id
+    LIMIT @max::integer
+    FOR UPDATE SKIP LOCKED
+),
+dep_states AS (
+    -- Dependencies on other tasks in the same workflow, referenced by name.
+    SELECT pending_tasks.id AS task_id, dep_job.state AS dep_state
+    FROM pending_tasks
+    CROSS JOIN LATERAL jsonb_array_elements_text(coalesce(pending_tasks.metadata->'river:workflow_deps', '[]'::jsonb)) AS dep_name
+    LEFT JOIN /* TEMPLATE: schema */river_job AS dep_job
+        ON dep_job.metadata @> jsonb_build_object('river:workflow_id', pending_tasks.metadata->'river:workflow_id', 'river:workflow_task', dep_name)
+    UNION ALL
+    -- Dependencies on any other job, referenced by ID.
+    SELECT pending_tasks.id AS task_id, dep_job.state AS dep_state
+    FROM pending_tasks
+    CROSS JOIN LATERAL jsonb_array_elements_text(coalesce(pending_tasks.metadata->'river:workflow_dep_job_ids', '[]'::jsonb)) AS dep_job_id
+    LEFT JOIN /* TEMPLATE: schema */river_job AS dep_job
+        ON dep_job.id = dep_job_id::bigint
+),
+task_outcomes AS (
+    -- A dependency that no longer exists (e.g. removed by the job cleaner
+    -- after completing) has a NULL state and counts as neither failed nor
+    -- unfinished. Dependencies are checked to exist when tasks are inserted,
+    -- so one that's missing must have been removed since.
+    SELECT
+        pending_tasks.id,
+        count(*) FILTER (WHERE dep_states.dep_state IN ('cancelled', 'discarded')) AS num_failed,
+        count(*) FILTER (WHERE dep_states.dep_state NOT IN ('cancelled', 'completed', 'discarded')) AS num_unfinished
+    FROM pending_tasks
+    LEFT JOIN dep_states ON dep_states.task_id = pending_tasks.id
+    GROUP BY pending_tasks.id
+),
+updated_tasks AS (
+    UPDATE /* TEMPLATE: schema */river_job
+    SET
+        state = CASE WHEN task_outcomes.num_failed > 0 AND river_job.metadata->>'river:workflow_dep_failed_action' = 'discard' THEN 'discarded'::river_job_state
+                     WHEN task_outcomes.num_failed > 0 THEN 'cancelled'::river_job_state
+                     WHEN river_job.scheduled_at > @now::timestamptz THEN 'scheduled'::river_job_state
+                     ELSE 'available'::river_job_state END,
+        finalized_at = CASE WHEN task_outcomes.num_failed > 0 THEN @now::timestamptz ELSE river_job.finalized_at END
+    FROM task_outcomes
+    WHERE river_job.id = task_outcomes.id
+        AND (task_outcomes.num_failed > 0 OR task_outcomes.num_unfinished = 0)
+    RETURNING river_job.*
+)
+SELECT *
+FROM /* TEMPLATE: schema */river_job
+WHERE id IN (SELECT id FROM pending_tasks)
+    AND id NOT IN (SELECT id FROM updated_tasks)
+UNION
+SELECT *
+FROM updated_tasks;
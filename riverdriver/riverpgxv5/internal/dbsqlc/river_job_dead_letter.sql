@@ -0,0 +1,131 @@
+CREATE TABLE river_job_dead_letter(
+    id bigint PRIMARY KEY,
+    args jsonb NOT NULL DEFAULT '{}',
+    attempt smallint NOT NULL,
+    attempted_at timestamptz,
+    attempted_by text[],
+    created_at timestamptz NOT NULL,
+    dead_lettered_at timestamptz NOT NULL DEFAULT now(),
+    errors jsonb[],
+    finalized_at timestamptz,
+    kind text NOT NULL,
+    max_attempts smallint NOT NULL,
+    metadata jsonb NOT NULL DEFAULT '{}',
+    priority smallint NOT NULL,
+    queue text NOT NULL,
+    scheduled_at timestamptz NOT NULL,
+    tags varchar(255)[] NOT NULL DEFAULT '{}'
+);
+
+-- name: JobDeadLetterDeleteByID :one
+DELETE FROM /* TEMPLATE: schema */river_job_dead_letter
+WHERE id = @id
+RETURNING *;
+
+-- name: JobDeadLetterDeleteMany :execrows
+DELETE FROM /* TEMPLATE: schema */river_job_dead_letter
+WHERE
+    (cardinality(@id::bigint[]) = 0 OR id = any(@id::bigint[]))
+    AND (sqlc.narg('dead_lettered_before')::timestamptz IS NULL OR dead_lettered_at < sqlc.narg('dead_lettered_before')::timestamptz)
+    AND (cardinality(@kind::text[]) = 0 OR kind = any(@kind::text[]))
+    AND (cardinality(@queue::text[]) = 0 OR queue = any(@queue::text[]));
+
+-- name: JobDeadLetterGetByID :one
+SELECT *
+FROM /* TEMPLATE: schema */river_job_dead_letter
+WHERE id = @id
+LIMIT 1;
+
+-- name: JobDeadLetterList :many
+SELECT *
+FROM /* TEMPLATE: schema */river_job_dead_letter
+WHERE
+    id > @after_id::bigint
+    AND (cardinality(@kind::text[]) = 0 OR kind = any(@kind::text[]))
+    AND (cardinality(@queue::text[]) = 0 OR queue = any(@queue::text[]))
+ORDER BY id
+LIMIT @max::integer;
+
+-- name: JobDeadLetterMoveBefore :one
+WITH jobs_to_move AS (
+    SELECT
+        id,
+        args,
+        attempt,
+        attempted_at,
+        attempted_by,
+        created_at,
+        errors,
+        finalized_at,
+        kind,
+        max_attempts,
+        metadata,
+        priority,
+        queue,
+        scheduled_at,
+        tags
+    FROM /* TEMPLATE: schema */river_job
+    WHERE state = 'discarded'
+        AND finalized_at < @discarded_finalized_at_horizon::timestamptz
+        -- A job whose ID is already dead lettered can't be archived, so it's
+        -- left in place instead of being deleted without a copy.
+        AND NOT EXISTS (
+            SELECT 1
+            FROM /* TEMPLATE: schema */river_job_dead_letter
+            WHERE river_job_dead_letter.id = river_job.id
+        )
+    ORDER BY id
+    LIMIT @max::integer
+    FOR UPDATE SKIP LOCKED
+),
+inserted_dead_letters AS (
+    INSERT INTO /* TEMPLATE: schema */river_job_dead_letter (
+        id,
+        args,
+        attempt,
+        attempted_at,
+        attempted_by,
+        created_at,
+        dead_lettered_at,
+        errors,
+        finalized_at,
+        kind,
+        max_attempts,
+        metadata,
+        priority,
+        queue,
+        scheduled_at,
+        tags
+    )
+    SELECT
+        id,
+        args,
+        attempt,
+        attempted_at,
+        attempted_by,
+        created_at,
+        coalesce(sqlc.narg('now')::timestamptz, now()),
+        errors,
+        finalized_at,
+        kind,
+        max_attempts,
+        metadata,
+        priority,
+        queue,
+        scheduled_at,
+        tags
+    FROM jobs_to_move
+    ON CONFLICT (id) DO NOTHING
+    RETURNING id
+),
+-- Only jobs that were successfully archived are deleted. Any that conflicted
+-- with a dead letter inserted concurrently stay in place.
+deleted_jobs AS (
+    DELETE FROM /* TEMPLATE: schema */river_job
+    WHERE id IN (
+        SELECT id
+        FROM inserted_dead_letters
+    )
+)
+SELECT count(*)
+FROM inserted_dead_letters;
@@ -0,0 +1,29 @@
+CREATE UNLOGGED TABLE river_rate_limit(
+    key text PRIMARY KEY NOT NULL,
+    tokens double precision NOT NULL,
+    updated_at timestamptz NOT NULL DEFAULT now()
+);
+
+-- name: RateLimitRefill :one
+INSERT INTO /* TEMPLATE: schema */river_rate_limit (
+    key,
+    tokens,
+    updated_at
+) VALUES (
+    @key::text,
+    @burst::double precision,
+    now()
+)
+ON CONFLICT (key) DO UPDATE
+SET
+    tokens = least(
+        @burst::double precision,
+        river_rate_limit.tokens + greatest(0, extract(epoch FROM now() - river_rate_limit.updated_at)::double precision) * @rate_per_second::double precision
+    ),
+    updated_at = greatest(river_rate_limit.updated_at, now())
+RETURNING tokens;
+
+-- name: RateLimitTake :exec
+UPDATE /* TEMPLATE: schema */river_rate_limit
+SET tokens = tokens - @tokens::double precision
+WHERE key = @key::text;
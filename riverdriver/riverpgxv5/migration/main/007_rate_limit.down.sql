@@ -0,0 +1,5 @@
+--
+-- Drop `river_rate_limit`.
+--
+
+DROP TABLE /* TEMPLATE: schema */river_rate_limit;
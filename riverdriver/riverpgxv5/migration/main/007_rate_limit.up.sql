@@ -0,0 +1,11 @@
+--
+-- Add `river_rate_limit`, which holds the state of token buckets used by rate
+-- limits shared across all clients.
+--
+
+CREATE UNLOGGED TABLE /* TEMPLATE: schema */river_rate_limit(
+    key text PRIMARY KEY NOT NULL,
+    tokens double precision NOT NULL,
+    updated_at timestamptz NOT NULL DEFAULT now(),
+    CONSTRAINT key_length CHECK (char_length(key) > 0 AND char_length(key) < 512)
+);
@@ -0,0 +1,5 @@
+--
+-- Drop `river_job_fair_fetching_index`.
+--
+
+DROP INDEX IF EXISTS /* TEMPLATE: schema */river_job_fair_fetching_index;
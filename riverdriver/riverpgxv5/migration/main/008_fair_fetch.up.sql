@@ -0,0 +1,8 @@
+--
+-- Add an index used by queues configured with fair fetching. It orders
+-- available jobs by the partition key stored in metadata so that distinct
+-- keys can be enumerated with a skip scan and each key's jobs can be selected
+-- without scanning the rest of the queue.
+--
+
+CREATE INDEX IF NOT EXISTS river_job_fair_fetching_index ON /* TEMPLATE: schema */river_job USING btree(queue, priority, (coalesce(metadata -> 'river:partition', '{}'::jsonb)), scheduled_at, id) WHERE state = 'available';
@@ -0,0 +1,25 @@
+--
+-- Add `river_job_dead_letter`, an optional archive that discarded jobs are
+-- moved to by the job cleaner instead of being deleted.
+--
+
+CREATE TABLE /* TEMPLATE: schema */river_job_dead_letter(
+    id bigint PRIMARY KEY,
+    args jsonb NOT NULL DEFAULT '{}',
+    attempt smallint NOT NULL,
+    attempted_at timestamptz,
+    attempted_by text[],
+    created_at timestamptz NOT NULL,
+    dead_lettered_at timestamptz NOT NULL DEFAULT now(),
+    errors jsonb[],
+    finalized_at timestamptz,
+    kind text NOT NULL,
+    max_attempts smallint NOT NULL,
+    metadata jsonb NOT NULL DEFAULT '{}',
+    priority smallint NOT NULL,
+    queue text NOT NULL,
+    scheduled_at timestamptz NOT NULL,
+    tags varchar(255)[] NOT NULL DEFAULT '{}'
+);
+
+CREATE INDEX river_job_dead_letter_dead_lettered_at_index ON /* TEMPLATE: schema */river_job_dead_letter USING btree(dead_lettered_at);
@@ -0,0 +1,15 @@
+--
+-- Add an index on the sequence key of sequenced jobs so that the sequence
+-- promoter can look up the job preceding each pending job in its sequence
+-- with an index scan rather than scanning every job containing the key.
+--
+
+CREATE INDEX IF NOT EXISTS river_job_sequence_key_index ON /* TEMPLATE: schema */river_job USING btree((metadata ->> 'river:sequence_key'), id) WHERE metadata ? 'river:sequence_key';
+
+--
+-- Add a narrower index covering only pending sequenced jobs, which the
+-- sequence promoter skip scans to find the first pending job of each sequence
+-- without visiting the rest of the jobs queued up behind it.
+--
+
+CREATE INDEX IF NOT EXISTS river_job_sequence_pending_index ON /* TEMPLATE: schema */river_job USING btree((metadata ->> 'river:sequence_key'), id) WHERE state = 'pending' AND metadata ? 'river:sequence_key';
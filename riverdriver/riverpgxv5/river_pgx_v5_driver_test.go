@@ -6,6 +6,7 @@ import (
 	"fmt"
 	"net"
 	"os"
+	"strings"
 	"testing"
 	"time"
 
@@ -16,6 +17,7 @@ import (
 
 	"github.com/riverqueue/river/riverdriver"
 	"github.com/riverqueue/river/rivershared/sqlctemplate"
+	"github.com/riverqueue/river/rivershared/util/ptrutil"
 	"github.com/riverqueue/river/rivertype"
 )
 
@@ -188,3 +190,124 @@ func TestSchemaTemplateParam(t *testing.T) {
 		require.Equal(t, "SELECT 1 FROM custom_schema.river_job", updatedSQL)
 	})
 }
+
+// queryCapturer is a pgx query tracer that records the most recent query so
+// that its plan can be checked with EXPLAIN.
+type queryCapturer struct {
+	args []any
+	sql  string
+}
+
+func (c *queryCapturer) TraceQueryStart(ctx context.Context, conn *pgx.Conn, data pgx.TraceQueryStartData) context.Context {
+	c.args = data.Args
+	c.sql = data.SQL
+	return ctx
+}
+
+func (c *queryCapturer) TraceQueryEnd(ctx context.Context, conn *pgx.Conn, data pgx.TraceQueryEndData) {
+}
+
+// Checks that queries used by fair fetching are planned against
+// river_job_fair_fetching_index (added by migration 008) in a queue deep
+// enough that a sequential scan would be chosen without it.
+func TestFairFetchQueryPlans(t *testing.T) {
+	t.Parallel()
+
+	ctx := context.Background()
+
+	const queue = "fair_fetch_plan"
+
+	type testBundle struct {
+		capturer *queryCapturer
+		exec     riverdriver.ExecutorTx
+		tx       pgx.Tx
+	}
+
+	setup := func(t *testing.T) *testBundle {
+		t.Helper()
+
+		capturer := &queryCapturer{}
+
+		config := testPoolConfig()
+		config.ConnConfig.Tracer = capturer
+		config.MaxConns = 1
+
+		tx, err := testPool(ctx, t, config).Begin(ctx)
+		require.NoError(t, err)
+		t.Cleanup(func() { _ = tx.Rollback(ctx) })
+
+		// Many jobs over a moderate number of partition keys, analyzed so
+		// that the planner knows the queue is deep. Both are rolled back
+		// with the transaction.
+		_, err = tx.Exec(ctx, `
+			INSERT INTO river_job (args, kind, max_attempts, metadata, queue)
+			SELECT
+				'{}',
+				'fair_kind',
+				25,
+				jsonb_build_object('river:partition', jsonb_build_object('customer_id', n % 100)),
+				$1
+			FROM generate_series(1, 50000) AS n`, queue)
+		require.NoError(t, err)
+
+		_, err = tx.Exec(ctx, "ANALYZE river_job")
+		require.NoError(t, err)
+
+		return &testBundle{
+			capturer: capturer,
+			exec:     New(nil).UnwrapExecutor(tx),
+			tx:       tx,
+		}
+	}
+
+	explain := func(t *testing.T, bundle *testBundle) string {
+		t.Helper()
+
+		rows, err := bundle.tx.Query(ctx, "EXPLAIN "+bundle.capturer.sql, bundle.capturer.args...)
+		require.NoError(t, err)
+
+		lines, err := pgx.CollectRows(rows, pgx.RowTo[string])
+		require.NoError(t, err)
+
+		return strings.Join(lines, "\n")
+	}
+
+	t.Run("JobGetAvailableFairKeys", func(t *testing.T) {
+		t.Parallel()
+
+		bundle := setup(t)
+
+		result, err := bundle.exec.JobGetAvailableFairKeys(ctx, &riverdriver.JobGetAvailableFairKeysParams{
+			After: ptrutil.Ptr(`{"customer_id": 50}`),
+			Max:   10,
+			Queue: queue,
+		})
+		require.NoError(t, err)
+		require.Len(t, result.FairKeys, 10)
+
+		plan := explain(t, bundle)
+		require.Contains(t, plan, "river_job_fair_fetching_index", "plan:\n%s", plan)
+		require.NotContains(t, plan, "Seq Scan on river_job", "plan:\n%s", plan)
+	})
+
+	t.Run("JobGetAvailableFair", func(t *testing.T) {
+		t.Parallel()
+
+		bundle := setup(t)
+
+		jobs, err := bundle.exec.JobGetAvailable(ctx, &riverdriver.JobGetAvailableParams{
+			ClientID:      "client-id",
+			FairKeys:      []string{`{"customer_id": 1}`, `{"customer_id": 2}`},
+			FairMaxPerKey: 5,
+			FairPriority:  1,
+			Max:           10,
+			Queue:         queue,
+		})
+		require.NoError(t, err)
+		require.Len(t, jobs, 10)
+
+		plan := explain(t, bundle)
+		require.Contains(t, plan, "river_job_fair_fetching_index", "plan:\n%s", plan)
+		require.NotContains(t, plan, "Seq Scan on river_job", "plan:\n%s", plan)
+	})
+}
@@ -0,0 +1,224 @@
+package rivermetrics
+
+import (
+	"bytes"
+	"maps"
+	"math"
+	"net/http"
+	"slices"
+	"strconv"
+	"strings"
+	"sync"
+)
+
+// DefaultBuckets are the default histogram buckets of PrometheusRecorder in
+// seconds, ranging from 5 milliseconds to 5 minutes.
+var DefaultBuckets = []float64{.005, .01, .025, .05, .1, .25, .5, 1, 2.5, 5, 10, 30, 60, 300} //nolint:gochecknoglobals
+
+type metricType string
+
+const (
+	metricTypeCounter   metricType = "counter"
+	metricTypeGauge     metricType = "gauge"
+	metricTypeHistogram metricType = "histogram"
+)
+
+// PrometheusRecorder is a Recorder that keeps metrics in memory and serves
+// them over HTTP in the Prometheus text exposition format. It has no
+// dependencies on Prometheus' client libraries.
+//
+//	metrics := rivermetrics.NewPrometheusRecorder(nil)
+//
+//	riverClient, err := river.NewClient(riverpgxv5.New(dbPool), &river.Config{
+//		Metrics: metrics,
+//		...
+//	})
+//
+//	http.Handle("/metrics", metrics)
+type PrometheusRecorder struct {
+	config *PrometheusRecorderConfig
+
+	mu       sync.Mutex
+	families map[string]*metricFamily
+}
+
+// PrometheusRecorderConfig is configuration for PrometheusRecorder.
+type PrometheusRecorderConfig struct {
+	// Buckets are the upper bounds of histogram buckets in seconds, in
+	// increasing order.
+	//
+	// Defaults to DefaultBuckets.
+	Buckets []float64
+}
+
+// NewPrometheusRecorder initializes a new PrometheusRecorder with the given
+// configuration.
+func NewPrometheusRecorder(config *PrometheusRecorderConfig) *PrometheusRecorder {
+	if config == nil {
+		config = &PrometheusRecorderConfig{}
+	}
+
+	buckets := config.Buckets
+	if len(buckets) < 1 {
+		buckets = DefaultBuckets
+	}
+
+	return &PrometheusRecorder{
+		config:   &PrometheusRecorderConfig{Buckets: slices.Sorted(slices.Values(buckets))},
+		families: make(map[string]*metricFamily),
+	}
+}
+
+type metricFamily struct {
+	series map[string]*metricSeries // keyed by encoded labels
+	typ    metricType
+}
+
+type metricSeries struct {
+	bucketCounts []uint64 // histograms only; not cumulative
+	count        uint64   // histograms only
+	labels       string   // encoded labels, like `kind="a",queue="b"`
+	value        float64  // counter and gauge value, or histogram sum
+}
+
+func (r *PrometheusRecorder) CounterAdd(name string, labels Labels, value float64) {
+	r.withSeries(name, metricTypeCounter, labels, func(s *metricSeries) { s.value += value })
+}
+
+func (r *PrometheusRecorder) GaugeAdd(name string, labels Labels, value float64) {
+	r.withSeries(name, metricTypeGauge, labels, func(s *metricSeries) { s.value += value })
+}
+
+func (r *PrometheusRecorder) GaugeSet(name string, labels Labels, value float64) {
+	r.withSeries(name, metricTypeGauge, labels, func(s *metricSeries) { s.value = value })
+}
+
+func (r *PrometheusRecorder) HistogramObserve(name string, labels Labels, value float64) {
+	r.withSeries(name, metricTypeHistogram, labels, func(s *metricSeries) {
+		if s.bucketCounts == nil {
+			s.bucketCounts = make([]uint64, len(r.config.Buckets))
+		}
+
+		if i, _ := slices.BinarySearch(r.config.Buckets, value); i < len(r.config.Buckets) {
+			s.bucketCounts[i]++
+		}
+		s.count++
+		s.value += value
+	})
+}
+
+// Invokes the given function on the series with the given name and labels
+// under lock, creating it if necessary. Observations are ignored if the metric
+// was previously recorded as a different type.
+func (r *PrometheusRecorder) withSeries(name string, typ metricType, labels Labels, seriesFunc func(s *metricSeries)) {
+	encodedLabels := encodeLabels(labels)
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	family, ok := r.families[name]
+	if !ok {
+		family = &metricFamily{series: make(map[string]*metricSeries), typ: typ}
+		r.families[name] = family
+	}
+	if family.typ != typ {
+		return
+	}
+
+	series, ok := family.series[encodedLabels]
+	if !ok {
+		series = &metricSeries{labels: encodedLabels}
+		family.series[encodedLabels] = series
+	}
+
+	seriesFunc(series)
+}
+
+// ServeHTTP serves all recorded metrics in the Prometheus text exposition
+// format.
+func (r *PrometheusRecorder) ServeHTTP(w http.ResponseWriter, _ *http.Request) {
+	w.Header().Set("Content-Type", "text/plain; version=0.0.4; charset=utf-8")
+	_, _ = w.Write(r.expose())
+}
+
+func (r *PrometheusRecorder) expose() []byte {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	var buf bytes.Buffer
+
+	for _, name := range slices.Sorted(maps.Keys(r.families)) {
+		family := r.families[name]
+
+		if help, ok := metricHelp[name]; ok {
+			buf.WriteString("# HELP " + name + " " + help + "\n")
+		}
+		buf.WriteString("# TYPE " + name + " " + string(family.typ) + "\n")
+
+		for _, key := range slices.Sorted(maps.Keys(family.series)) {
+			series := family.series[key]
+
+			if family.typ != metricTypeHistogram {
+				writeSample(&buf, name, series.labels, series.value)
+				continue
+			}
+
+			var cumulative uint64
+			for i, upperBound := range r.config.Buckets {
+				cumulative += series.bucketCounts[i]
+				writeSample(&buf, name+"_bucket", joinLabels(series.labels, `le="`+formatFloat(upperBound)+`"`), float64(cumulative))
+			}
+			writeSample(&buf, name+"_bucket", joinLabels(series.labels, `le="+Inf"`), float64(series.count))
+			writeSample(&buf, name+"_sum", series.labels, series.value)
+			writeSample(&buf, name+"_count", series.labels, float64(series.count))
+		}
+	}
+
+	return buf.Bytes()
+}
+
+// Encodes labels as they appear in exposition, sorted by name so that the
+// same labels always encode identically.
+func encodeLabels(labels Labels) string {
+	var sb strings.Builder
+	for i, name := range slices.Sorted(maps.Keys(labels)) {
+		if i > 0 {
+			sb.WriteByte(',')
+		}
+		sb.WriteString(name + `="` + escapeLabelValue(labels[name]) + `"`)
+	}
+	return sb.String()
+}
+
+var labelValueReplacer = strings.NewReplacer(`\`, `\\`, `"`, `\"`, "\n", `\n`) //nolint:gochecknoglobals
+
+func escapeLabelValue(value string) string {
+	return labelValueReplacer.Replace(value)
+}
+
+func formatFloat(value float64) string {
+	switch {
+	case math.IsInf(value, 1):
+		return "+Inf"
+	case math.IsInf(value, -1):
+		return "-Inf"
+	case math.IsNaN(value):
+		return "NaN"
+	}
+	return strconv.FormatFloat(value, 'g', -1, 64)
+}
+
+func joinLabels(labels, extra string) string {
+	if labels == "" {
+		return extra
+	}
+	return labels + "," + extra
+}
+
+func writeSample(buf *bytes.Buffer, name, labels string, value float64) {
+	buf.WriteString(name)
+	if labels != "" {
+		buf.WriteString("{" + labels + "}")
+	}
+	buf.WriteString(" " + formatFloat(value) + "\n")
+}
@@ -0,0 +1,124 @@
+package rivermetrics
+
+import (
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+var (
+	_ http.Handler = &PrometheusRecorder{}
+	_ Recorder     = &PrometheusRecorder{}
+)
+
+func TestPrometheusRecorder(t *testing.T) {
+	t.Parallel()
+
+	labels := Labels{LabelKind: "kind1", LabelQueue: "default"}
+
+	t.Run("Counter", func(t *testing.T) {
+		t.Parallel()
+
+		recorder := NewPrometheusRecorder(nil)
+		recorder.CounterAdd(MetricJobsCompleted, labels, 1)
+		recorder.CounterAdd(MetricJobsCompleted, labels, 2)
+		recorder.CounterAdd(MetricJobsCompleted, Labels{LabelKind: "kind2", LabelQueue: "default"}, 1)
+
+		require.Equal(t, `# HELP river_jobs_completed_total Number of jobs completed.
+# TYPE river_jobs_completed_total counter
+river_jobs_completed_total{kind="kind1",queue="default"} 3
+river_jobs_completed_total{kind="kind2",queue="default"} 1
+`, string(recorder.expose()))
+	})
+
+	t.Run("Gauge", func(t *testing.T) {
+		t.Parallel()
+
+		recorder := NewPrometheusRecorder(nil)
+		recorder.GaugeAdd(MetricJobsRunning, labels, 1)
+		recorder.GaugeAdd(MetricJobsRunning, labels, 1)
+		recorder.GaugeAdd(MetricJobsRunning, labels, -1)
+		recorder.GaugeSet(MetricQueueJobs, Labels{LabelQueue: "default", LabelState: "available"}, 12)
+
+		require.Equal(t, `# HELP river_jobs_running Number of jobs being worked by the client.
+# TYPE river_jobs_running gauge
+river_jobs_running{kind="kind1",queue="default"} 1
+# HELP river_queue_jobs Number of unfinalized jobs in the database.
+# TYPE river_queue_jobs gauge
+river_queue_jobs{queue="default",state="available"} 12
+`, string(recorder.expose()))
+	})
+
+	t.Run("Histogram", func(t *testing.T) {
+		t.Parallel()
+
+		recorder := NewPrometheusRecorder(&PrometheusRecorderConfig{Buckets: []float64{1, 0.1}})
+		recorder.HistogramObserve(MetricJobRunDurationSeconds, labels, 0.05)
+		recorder.HistogramObserve(MetricJobRunDurationSeconds, labels, 0.1)
+		recorder.HistogramObserve(MetricJobRunDurationSeconds, labels, 0.5)
+		recorder.HistogramObserve(MetricJobRunDurationSeconds, labels, 2)
+
+		require.Equal(t, `# HELP river_job_run_duration_seconds Time jobs took to be worked.
+# TYPE river_job_run_duration_seconds histogram
+river_job_run_duration_seconds_bucket{kind="kind1",queue="default",le="0.1"} 2
+river_job_run_duration_seconds_bucket{kind="kind1",queue="default",le="1"} 3
+river_job_run_duration_seconds_bucket{kind="kind1",queue="default",le="+Inf"} 4
+river_job_run_duration_seconds_sum{kind="kind1",queue="default"} 2.65
+river_job_run_duration_seconds_count{kind="kind1",queue="default"} 4
+`, string(recorder.expose()))
+	})
+
+	t.Run("EscapesLabelValues", func(t *testing.T) {
+		t.Parallel()
+
+		recorder := NewPrometheusRecorder(nil)
+		recorder.CounterAdd("custom_total", Labels{"name": "a\"b\\c\nd"}, 1)
+
+		require.Equal(t, `# TYPE custom_total counter
+custom_total{name="a\"b\\c\nd"} 1
+`, string(recorder.expose()))
+	})
+
+	t.Run("NoLabels", func(t *testing.T) {
+		t.Parallel()
+
+		recorder := NewPrometheusRecorder(nil)
+		recorder.GaugeSet("custom", nil, 1.5)
+
+		require.Equal(t, `# TYPE custom gauge
+custom 1.5
+`, string(recorder.expose()))
+	})
+
+	t.Run("IgnoresMismatchedType", func(t *testing.T) {
+		t.Parallel()
+
+		recorder := NewPrometheusRecorder(nil)
+		recorder.CounterAdd("custom", nil, 1)
+		recorder.GaugeSet("custom", nil, 5)
+
+		require.Equal(t, `# TYPE custom counter
+custom 1
+`, string(recorder.expose()))
+	})
+
+	t.Run("ServeHTTP", func(t *testing.T) {
+		t.Parallel()
+
+		recorder := NewPrometheusRecorder(nil)
+		recorder.CounterAdd(MetricJobsInserted, labels, 1)
+
+		resp := httptest.NewRecorder()
+		recorder.ServeHTTP(resp, httptest.NewRequest(http.MethodGet, "/metrics", nil))
+
+		require.Equal(t, http.StatusOK, resp.Code)
+		require.Equal(t, "text/plain; version=0.0.4; charset=utf-8", resp.Header().Get("Content-Type"))
+
+		body, err := io.ReadAll(resp.Body)
+		require.NoError(t, err)
+		require.Contains(t, string(body), `river_jobs_inserted_total{kind="kind1",queue="default"} 1`)
+	})
+}
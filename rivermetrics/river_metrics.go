@@ -0,0 +1,107 @@
+// Package rivermetrics contains the metrics recorded by River clients
+// configured with Config.Metrics, along with Recorder, a small interface that
+// can be implemented to send them to any metrics backend, and
+// PrometheusRecorder, an implementation that exposes them for scraping in the
+// Prometheus text exposition format.
+package rivermetrics
+
+// Names of metrics recorded by River clients.
+const (
+	// MetricJobsInserted counts jobs inserted, excluding any skipped as
+	// duplicates of existing unique jobs. Labeled by kind and queue.
+	MetricJobsInserted = "river_jobs_inserted_total"
+
+	// MetricJobsStarted counts job attempts started. Labeled by kind and
+	// queue.
+	MetricJobsStarted = "river_jobs_started_total"
+
+	// MetricJobsCancelled counts jobs cancelled while being worked. Labeled by
+	// kind and queue.
+	MetricJobsCancelled = "river_jobs_cancelled_total"
+
+	// MetricJobsCompleted counts jobs completed. Labeled by kind and queue.
+	MetricJobsCompleted = "river_jobs_completed_total"
+
+	// MetricJobsDiscarded counts jobs discarded after exhausting their
+	// attempts. Labeled by kind and queue.
+	MetricJobsDiscarded = "river_jobs_discarded_total"
+
+	// MetricJobsFailed counts job attempts that failed, including ones whose
+	// jobs were discarded as a result. Labeled by kind and queue.
+	MetricJobsFailed = "river_jobs_failed_total"
+
+	// MetricJobsSnoozed counts jobs snoozed. Labeled by kind and queue.
+	MetricJobsSnoozed = "river_jobs_snoozed_total"
+
+	// MetricJobCompleteDurationSeconds is a histogram of the time taken to
+	// persist a job's state after it was worked. Labeled by kind and queue.
+	MetricJobCompleteDurationSeconds = "river_job_complete_duration_seconds"
+
+	// MetricJobQueueWaitDurationSeconds is a histogram of the time jobs
+	// waited to be worked after becoming available. Labeled by kind and
+	// queue.
+	MetricJobQueueWaitDurationSeconds = "river_job_queue_wait_duration_seconds"
+
+	// MetricJobRunDurationSeconds is a histogram of the time jobs took to be
+	// worked. Labeled by kind and queue.
+	MetricJobRunDurationSeconds = "river_job_run_duration_seconds"
+
+	// MetricJobsRunning is a gauge of the number of jobs being worked by the
+	// client. Labeled by kind and queue.
+	MetricJobsRunning = "river_jobs_running"
+
+	// MetricQueueJobs is a gauge of the number of unfinalized jobs in the
+	// database, as observed periodically. Labeled by queue and state. It's
+	// only reported by the elected leader because counts are cluster-wide, so
+	// it shouldn't be summed across clients.
+	MetricQueueJobs = "river_queue_jobs"
+)
+
+// Names of labels applied to metrics.
+const (
+	LabelKind  = "kind"
+	LabelQueue = "queue"
+	LabelState = "state"
+)
+
+// Descriptions of River's metrics, used for help text.
+var metricHelp = map[string]string{ //nolint:gochecknoglobals
+	MetricJobsInserted:                "Number of jobs inserted.",
+	MetricJobsStarted:                 "Number of job attempts started.",
+	MetricJobsCancelled:               "Number of jobs cancelled while being worked.",
+	MetricJobsCompleted:               "Number of jobs completed.",
+	MetricJobsDiscarded:               "Number of jobs discarded.",
+	MetricJobsFailed:                  "Number of job attempts failed.",
+	MetricJobsSnoozed:                 "Number of jobs snoozed.",
+	MetricJobCompleteDurationSeconds:  "Time taken to persist a job's state after it was worked.",
+	MetricJobQueueWaitDurationSeconds: "Time jobs waited to be worked after becoming available.",
+	MetricJobRunDurationSeconds:       "Time jobs took to be worked.",
+	MetricJobsRunning:                 "Number of jobs being worked by the client.",
+	MetricQueueJobs:                   "Number of unfinalized jobs in the database.",
+}
+
+// Labels are the labels of a metric, mapping label names to values.
+type Labels map[string]string
+
+// Recorder receives metrics from a River client. It's implemented by
+// PrometheusRecorder, and may be implemented to send metrics to another
+// backend.
+//
+// Implementations must be safe for concurrent use.
+type Recorder interface {
+	// CounterAdd adds the given value to the counter with the given name and
+	// labels.
+	CounterAdd(name string, labels Labels, value float64)
+
+	// GaugeAdd adds the given value, which may be negative, to the gauge with
+	// the given name and labels.
+	GaugeAdd(name string, labels Labels, value float64)
+
+	// GaugeSet sets the gauge with the given name and labels to the given
+	// value.
+	GaugeSet(name string, labels Labels, value float64)
+
+	// HistogramObserve records an observation of the given value in the
+	// histogram with the given name and labels.
+	HistogramObserve(name string, labels Labels, value float64)
+}
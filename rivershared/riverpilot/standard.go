@@ -17,7 +17,36 @@ func (p *StandardPilot) JobGetAvailable(ctx context.Context, exec riverdriver.Ex
 	if params.Max <= 0 {
 		return nil, nil
 	}
-	return exec.JobGetAvailable(ctx, params)
+	if params.GlobalLimit <= 0 && params.PartitionLimit <= 0 {
+		return exec.JobGetAvailable(ctx, params)
+	}
+
+	// Running jobs are counted as part of the fetch to enforce global and
+	// partition limits, so fetches from all clients working the queue are serialized
+	// with an advisory lock. Otherwise, concurrent fetches could each see the
+	// same spare capacity and collectively exceed the limit. The lock is taken
+	// in a separate statement so that the fetch's snapshot includes jobs
+	// started by whichever fetch held it previously.
+	tx, err := exec.Begin(ctx)
+	if err != nil {
+		return nil, err
+	}
+	defer tx.Rollback(ctx)
+
+	if _, err := tx.PGAdvisoryXactLock(ctx, params.GlobalLimitLockKey); err != nil {
+		return nil, err
+	}
+
+	jobs, err := tx.JobGetAvailable(ctx, params)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		return nil, err
+	}
+
+	return jobs, nil
 }
 
 func (p *StandardPilot) JobInsertMany(
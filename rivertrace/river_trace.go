@@ -0,0 +1,272 @@
+// Package rivertrace provides middleware that propagates W3C trace context
+// (traceparent and tracestate) through job metadata so that traces continue
+// from where a job is inserted to where it's worked, and that creates spans
+// around job insertion and work.
+//
+// The middleware is agnostic to any particular tracing library. It's used
+// through the small Tracer interface, which an adapter for a library like
+// OpenTelemetry can implement in a few lines.
+package rivertrace
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"regexp"
+	"strings"
+
+	"github.com/tidwall/sjson"
+
+	"github.com/riverqueue/river/rivershared/baseservice"
+	"github.com/riverqueue/river/rivertype"
+)
+
+const (
+	headerTraceParent = "traceparent"
+	headerTraceState  = "tracestate"
+	metadataKey       = "river:trace"
+
+	spanNameInsert = "river.insert_many"
+	spanNameWork   = "river.work"
+)
+
+// Attribute names set on spans.
+const (
+	AttributeJobAttempt  = "river.job.attempt"
+	AttributeJobCount    = "river.job.count"
+	AttributeJobID       = "river.job.id"
+	AttributeJobKind     = "river.job.kind"
+	AttributeJobPriority = "river.job.priority"
+	AttributeJobQueue    = "river.job.queue"
+)
+
+// Carrier carries trace context headers as W3C names (traceparent and
+// tracestate) to their values. Its underlying type is the same as
+// OpenTelemetry's propagation.MapCarrier, so it can be converted to one
+// directly.
+type Carrier map[string]string
+
+// Attribute is a key/value pair describing a span. Values are one of int,
+// int64, or string.
+type Attribute struct {
+	Key   string
+	Value any
+}
+
+// SpanKind describes the relationship of a span to the job.
+type SpanKind int
+
+const (
+	// SpanKindProducer is the kind of spans around job insertion.
+	SpanKindProducer SpanKind = iota + 1
+
+	// SpanKindConsumer is the kind of spans around working a job.
+	SpanKindConsumer
+)
+
+// Span is a span started by a Tracer.
+type Span interface {
+	// End ends the span. err is the error that the operation around which the
+	// span was started returned, or nil if it succeeded.
+	End(err error)
+}
+
+// Tracer is the interface through which the middleware interacts with a
+// tracing library.
+//
+// An OpenTelemetry adapter might look like:
+//
+//	type otelTracer struct {
+//		propagator propagation.TextMapPropagator
+//		tracer     trace.Tracer
+//	}
+//
+//	func (t *otelTracer) Extract(ctx context.Context, carrier rivertrace.Carrier) context.Context {
+//		return t.propagator.Extract(ctx, propagation.MapCarrier(carrier))
+//	}
+//
+//	func (t *otelTracer) Inject(ctx context.Context, carrier rivertrace.Carrier) {
+//		t.propagator.Inject(ctx, propagation.MapCarrier(carrier))
+//	}
+//
+//	func (t *otelTracer) StartSpan(ctx context.Context, name string, kind rivertrace.SpanKind, attrs []rivertrace.Attribute) (context.Context, rivertrace.Span) {
+//		...
+//	}
+type Tracer interface {
+	// Extract returns a copy of ctx containing the trace context from the
+	// given carrier.
+	Extract(ctx context.Context, carrier Carrier) context.Context
+
+	// Inject sets the trace context in ctx into the given carrier as W3C trace
+	// context headers. It should leave the carrier empty if ctx carries no
+	// trace context.
+	Inject(ctx context.Context, carrier Carrier)
+
+	// StartSpan starts a span of the given name and kind as a child of any
+	// span in ctx, returning a copy of ctx containing the new span.
+	StartSpan(ctx context.Context, name string, kind SpanKind, attrs []Attribute) (context.Context, Span)
+}
+
+// Middleware captures the trace context at insert time into job metadata and
+// restores it into the context of the job's Work function, creating spans
+// around both insertion and work. It implements both
+// rivertype.JobInsertMiddleware and rivertype.WorkerMiddleware, and should be
+// installed on the client so that it runs for both:
+//
+//	riverClient, err := river.NewClient(riverpgxv5.New(dbPool), &river.Config{
+//		Middleware: []rivertype.Middleware{
+//			rivertrace.NewMiddleware(tracer, nil),
+//		},
+//	})
+type Middleware struct {
+	baseservice.BaseService
+	rivertype.Middleware
+	config *MiddlewareConfig
+	tracer Tracer
+}
+
+// MiddlewareConfig is configuration for Middleware.
+type MiddlewareConfig struct {
+	// DisableSpans disables the creation of spans around insertion and work
+	// so that only trace context is propagated. Useful in case spans are
+	// already created elsewhere.
+	DisableSpans bool
+}
+
+// NewMiddleware initializes a new Middleware with the given tracer and
+// configuration.
+func NewMiddleware(tracer Tracer, config *MiddlewareConfig) *Middleware {
+	if config == nil {
+		config = &MiddlewareConfig{}
+	}
+
+	return &Middleware{
+		config: config,
+		tracer: tracer,
+	}
+}
+
+type metadataWithTrace struct {
+	RiverTrace Carrier `json:"river:trace"`
+}
+
+func (m *Middleware) InsertMany(ctx context.Context, manyParams []*rivertype.JobInsertParams, doInner func(context.Context) ([]*rivertype.JobInsertResult, error)) ([]*rivertype.JobInsertResult, error) {
+	var span Span
+	if !m.config.DisableSpans {
+		attrs := []Attribute{{Key: AttributeJobCount, Value: len(manyParams)}}
+
+		// Kind and queue are only meaningful if they're the same for the
+		// whole batch, which is the case for most inserts.
+		if len(manyParams) > 0 {
+			if kind, ok := uniformValue(manyParams, func(p *rivertype.JobInsertParams) string { return p.Kind }); ok {
+				attrs = append(attrs, Attribute{Key: AttributeJobKind, Value: kind})
+			}
+			if queue, ok := uniformValue(manyParams, func(p *rivertype.JobInsertParams) string { return p.Queue }); ok {
+				attrs = append(attrs, Attribute{Key: AttributeJobQueue, Value: queue})
+			}
+		}
+
+		ctx, span = m.tracer.StartSpan(ctx, spanNameInsert, SpanKindProducer, attrs)
+	}
+
+	carrier := make(Carrier)
+	m.tracer.Inject(ctx, carrier)
+
+	if validTraceParent(carrier[headerTraceParent]) {
+		trace := Carrier{headerTraceParent: carrier[headerTraceParent]}
+		if traceState := carrier[headerTraceState]; traceState != "" {
+			trace[headerTraceState] = traceState
+		}
+
+		for _, params := range manyParams {
+			metadata := params.Metadata
+			if len(metadata) < 1 {
+				metadata = []byte("{}")
+			}
+
+			var err error
+			params.Metadata, err = sjson.SetBytes(metadata, metadataKey, trace)
+			if err != nil {
+				err = fmt.Errorf("error setting trace context in metadata: %w", err)
+				if span != nil {
+					span.End(err)
+				}
+				return nil, err
+			}
+		}
+	}
+
+	results, err := doInner(ctx)
+	if span != nil {
+		span.End(err)
+	}
+	return results, err
+}
+
+func (m *Middleware) Work(ctx context.Context, job *rivertype.JobRow, doInner func(context.Context) error) (err error) {
+	var metadata metadataWithTrace
+	if len(job.Metadata) > 0 {
+		if err := json.Unmarshal(job.Metadata, &metadata); err != nil {
+			return err
+		}
+	}
+
+	if validTraceParent(metadata.RiverTrace[headerTraceParent]) {
+		ctx = m.tracer.Extract(ctx, metadata.RiverTrace)
+	}
+
+	if m.config.DisableSpans {
+		return doInner(ctx)
+	}
+
+	ctx, span := m.tracer.StartSpan(ctx, spanNameWork, SpanKindConsumer, []Attribute{
+		{Key: AttributeJobAttempt, Value: job.Attempt},
+		{Key: AttributeJobID, Value: job.ID},
+		{Key: AttributeJobKind, Value: job.Kind},
+		{Key: AttributeJobPriority, Value: job.Priority},
+		{Key: AttributeJobQueue, Value: job.Queue},
+	})
+
+	// End the span even if the job panics, then continue panicking so the
+	// executor can handle the panic as usual.
+	defer func() {
+		if recovery := recover(); recovery != nil {
+			span.End(fmt.Errorf("job panicked: %v", recovery))
+			panic(recovery)
+		}
+		span.End(err)
+	}()
+
+	return doInner(ctx)
+}
+
+// Returns the value of the given field if it's the same for all params.
+func uniformValue(manyParams []*rivertype.JobInsertParams, field func(*rivertype.JobInsertParams) string) (string, bool) {
+	value := field(manyParams[0])
+	for _, params := range manyParams[1:] {
+		if field(params) != value {
+			return "", false
+		}
+	}
+	return value, true
+}
+
+// Matches a W3C traceparent like 00-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-01.
+var traceParentRE = regexp.MustCompile(`^([0-9a-f]{2})-([0-9a-f]{32})-([0-9a-f]{16})-([0-9a-f]{2})$`)
+
+// Validates a W3C traceparent header, rejecting the invalid version ff and all
+// zero trace or span IDs as the specification requires.
+func validTraceParent(traceParent string) bool {
+	matches := traceParentRE.FindStringSubmatch(traceParent)
+	if matches == nil {
+		return false
+	}
+
+	if matches[1] == "ff" {
+		return false
+	}
+
+	allZeroes := func(s string) bool { return strings.Trim(s, "0") == "" }
+
+	return !allZeroes(matches[2]) && !allZeroes(matches[3])
+}
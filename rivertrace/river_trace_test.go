@@ -0,0 +1,275 @@
+package rivertrace
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/riverqueue/river/rivertype"
+)
+
+var (
+	_ rivertype.JobInsertMiddleware = &Middleware{}
+	_ rivertype.WorkerMiddleware    = &Middleware{}
+)
+
+const (
+	testTraceParent = "00-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-01"
+	testTraceState  = "vendor=value"
+)
+
+type testSpan struct {
+	attrs []Attribute
+	ended bool
+	err   error
+	kind  SpanKind
+	name  string
+}
+
+func (s *testSpan) End(err error) {
+	s.ended = true
+	s.err = err
+}
+
+type contextKeyTrace struct{}
+
+// A tracer that stores a carrier in context as its "trace context".
+type testTracer struct {
+	spans []*testSpan
+}
+
+func (t *testTracer) Extract(ctx context.Context, carrier Carrier) context.Context {
+	return context.WithValue(ctx, contextKeyTrace{}, carrier)
+}
+
+func (t *testTracer) Inject(ctx context.Context, carrier Carrier) {
+	if trace, ok := ctx.Value(contextKeyTrace{}).(Carrier); ok {
+		for key, val := range trace {
+			carrier[key] = val
+		}
+	}
+}
+
+func (t *testTracer) StartSpan(ctx context.Context, name string, kind SpanKind, attrs []Attribute) (context.Context, Span) {
+	span := &testSpan{attrs: attrs, kind: kind, name: name}
+	t.spans = append(t.spans, span)
+	return ctx, span
+}
+
+func TestMiddleware(t *testing.T) {
+	t.Parallel()
+
+	ctx := context.Background()
+
+	type testBundle struct {
+		tracer *testTracer
+	}
+
+	setup := func(t *testing.T, config *MiddlewareConfig) (*Middleware, *testBundle) {
+		t.Helper()
+
+		tracer := &testTracer{}
+		return NewMiddleware(tracer, config), &testBundle{tracer: tracer}
+	}
+
+	traceCtx := context.WithValue(ctx, contextKeyTrace{}, Carrier{
+		headerTraceParent: testTraceParent,
+		headerTraceState:  testTraceState,
+	})
+
+	t.Run("InsertManyStoresTraceContext", func(t *testing.T) {
+		t.Parallel()
+
+		middleware, bundle := setup(t, nil)
+
+		manyParams := []*rivertype.JobInsertParams{
+			{Kind: "kind1", Metadata: []byte(`{"foo":"bar"}`), Queue: "default"},
+			{Kind: "kind1", Queue: "default"},
+		}
+
+		_, err := middleware.InsertMany(traceCtx, manyParams, func(ctx context.Context) ([]*rivertype.JobInsertResult, error) {
+			return nil, nil
+		})
+		require.NoError(t, err)
+
+		require.JSONEq(t, `{"foo":"bar","river:trace":{"traceparent":"`+testTraceParent+`","tracestate":"`+testTraceState+`"}}`, string(manyParams[0].Metadata))
+		require.JSONEq(t, `{"river:trace":{"traceparent":"`+testTraceParent+`","tracestate":"`+testTraceState+`"}}`, string(manyParams[1].Metadata))
+
+		require.Len(t, bundle.tracer.spans, 1)
+		span := bundle.tracer.spans[0]
+		require.Equal(t, spanNameInsert, span.name)
+		require.Equal(t, SpanKindProducer, span.kind)
+		require.Equal(t, []Attribute{
+			{Key: AttributeJobCount, Value: 2},
+			{Key: AttributeJobKind, Value: "kind1"},
+			{Key: AttributeJobQueue, Value: "default"},
+		}, span.attrs)
+		require.True(t, span.ended)
+		require.NoError(t, span.err)
+	})
+
+	t.Run("InsertManyMixedKindsOmitsAttribute", func(t *testing.T) {
+		t.Parallel()
+
+		middleware, bundle := setup(t, nil)
+
+		_, err := middleware.InsertMany(ctx, []*rivertype.JobInsertParams{
+			{Kind: "kind1", Queue: "default"},
+			{Kind: "kind2", Queue: "default"},
+		}, func(ctx context.Context) ([]*rivertype.JobInsertResult, error) {
+			return nil, nil
+		})
+		require.NoError(t, err)
+
+		require.Equal(t, []Attribute{
+			{Key: AttributeJobCount, Value: 2},
+			{Key: AttributeJobQueue, Value: "default"},
+		}, bundle.tracer.spans[0].attrs)
+	})
+
+	t.Run("InsertManyWithoutTraceContext", func(t *testing.T) {
+		t.Parallel()
+
+		middleware, _ := setup(t, nil)
+
+		manyParams := []*rivertype.JobInsertParams{{Kind: "kind1", Metadata: []byte(`{"foo":"bar"}`)}}
+
+		_, err := middleware.InsertMany(ctx, manyParams, func(ctx context.Context) ([]*rivertype.JobInsertResult, error) {
+			return nil, nil
+		})
+		require.NoError(t, err)
+		require.JSONEq(t, `{"foo":"bar"}`, string(manyParams[0].Metadata))
+	})
+
+	t.Run("InsertManyError", func(t *testing.T) {
+		t.Parallel()
+
+		middleware, bundle := setup(t, nil)
+
+		insertErr := errors.New("insert error")
+		_, err := middleware.InsertMany(ctx, []*rivertype.JobInsertParams{{Kind: "kind1"}}, func(ctx context.Context) ([]*rivertype.JobInsertResult, error) {
+			return nil, insertErr
+		})
+		require.ErrorIs(t, err, insertErr)
+		require.ErrorIs(t, bundle.tracer.spans[0].err, insertErr)
+	})
+
+	t.Run("WorkRestoresTraceContext", func(t *testing.T) {
+		t.Parallel()
+
+		middleware, bundle := setup(t, nil)
+
+		job := &rivertype.JobRow{
+			Attempt:  1,
+			ID:       123,
+			Kind:     "kind1",
+			Metadata: []byte(`{"river:trace":{"traceparent":"` + testTraceParent + `","tracestate":"` + testTraceState + `"}}`),
+			Priority: 1,
+			Queue:    "default",
+		}
+
+		var workCtx context.Context
+		require.NoError(t, middleware.Work(ctx, job, func(ctx context.Context) error {
+			workCtx = ctx
+			return nil
+		}))
+
+		require.Equal(t, Carrier{headerTraceParent: testTraceParent, headerTraceState: testTraceState}, workCtx.Value(contextKeyTrace{}))
+
+		require.Len(t, bundle.tracer.spans, 1)
+		span := bundle.tracer.spans[0]
+		require.Equal(t, spanNameWork, span.name)
+		require.Equal(t, SpanKindConsumer, span.kind)
+		require.Equal(t, []Attribute{
+			{Key: AttributeJobAttempt, Value: 1},
+			{Key: AttributeJobID, Value: int64(123)},
+			{Key: AttributeJobKind, Value: "kind1"},
+			{Key: AttributeJobPriority, Value: 1},
+			{Key: AttributeJobQueue, Value: "default"},
+		}, span.attrs)
+		require.True(t, span.ended)
+		require.NoError(t, span.err)
+	})
+
+	t.Run("WorkIgnoresInvalidTraceParent", func(t *testing.T) {
+		t.Parallel()
+
+		middleware, _ := setup(t, nil)
+
+		job := &rivertype.JobRow{Metadata: []byte(`{"river:trace":{"traceparent":"not-a-traceparent"}}`)}
+
+		require.NoError(t, middleware.Work(ctx, job, func(ctx context.Context) error {
+			require.Nil(t, ctx.Value(contextKeyTrace{}))
+			return nil
+		}))
+	})
+
+	t.Run("WorkError", func(t *testing.T) {
+		t.Parallel()
+
+		middleware, bundle := setup(t, nil)
+
+		workErr := errors.New("work error")
+		err := middleware.Work(ctx, &rivertype.JobRow{Metadata: []byte(`{}`)}, func(ctx context.Context) error {
+			return workErr
+		})
+		require.ErrorIs(t, err, workErr)
+		require.ErrorIs(t, bundle.tracer.spans[0].err, workErr)
+	})
+
+	t.Run("WorkPanic", func(t *testing.T) {
+		t.Parallel()
+
+		middleware, bundle := setup(t, nil)
+
+		require.PanicsWithValue(t, "panic from worker", func() {
+			_ = middleware.Work(ctx, &rivertype.JobRow{Metadata: []byte(`{}`)}, func(ctx context.Context) error {
+				panic("panic from worker")
+			})
+		})
+
+		span := bundle.tracer.spans[0]
+		require.True(t, span.ended)
+		require.EqualError(t, span.err, "job panicked: panic from worker")
+	})
+
+	t.Run("DisableSpans", func(t *testing.T) {
+		t.Parallel()
+
+		middleware, bundle := setup(t, &MiddlewareConfig{DisableSpans: true})
+
+		manyParams := []*rivertype.JobInsertParams{{Kind: "kind1"}}
+		_, err := middleware.InsertMany(traceCtx, manyParams, func(ctx context.Context) ([]*rivertype.JobInsertResult, error) {
+			return nil, nil
+		})
+		require.NoError(t, err)
+
+		var metadata metadataWithTrace
+		require.NoError(t, json.Unmarshal(manyParams[0].Metadata, &metadata))
+		require.Equal(t, testTraceParent, metadata.RiverTrace[headerTraceParent])
+
+		require.NoError(t, middleware.Work(ctx, &rivertype.JobRow{Metadata: manyParams[0].Metadata}, func(ctx context.Context) error {
+			require.NotNil(t, ctx.Value(contextKeyTrace{}))
+			return nil
+		}))
+
+		require.Empty(t, bundle.tracer.spans)
+	})
+}
+
+func TestValidTraceParent(t *testing.T) {
+	t.Parallel()
+
+	require.True(t, validTraceParent(testTraceParent))
+	require.True(t, validTraceParent("01-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-00"))
+
+	require.False(t, validTraceParent(""))
+	require.False(t, validTraceParent("00-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7"))
+	require.False(t, validTraceParent("00-4BF92F3577B34DA6A3CE929D0E0E4736-00f067aa0ba902b7-01"))
+	require.False(t, validTraceParent("ff-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-01"))
+	require.False(t, validTraceParent("00-00000000000000000000000000000000-00f067aa0ba902b7-01"))
+	require.False(t, validTraceParent("00-4bf92f3577b34da6a3ce929d0e0e4736-0000000000000000-01"))
+}
@@ -7,11 +7,22 @@ import (
 	"context"
 	"encoding/json"
 	"errors"
+	"fmt"
 	"time"
 )
 
-// MetadataKeyOutput is the metadata key used to store recorded job output.
-const MetadataKeyOutput = "output"
+const (
+	// MetadataKeyCheckpoint is the metadata key used to store a job's
+	// checkpoint, a state that persists across attempts.
+	MetadataKeyCheckpoint = "checkpoint"
+
+	// MetadataKeyOutput is the metadata key used to store recorded job output.
+	MetadataKeyOutput = "output"
+
+	// MetadataKeyProgress is the metadata key used to store a job's most
+	// recently recorded progress.
+	MetadataKeyProgress = "progress"
+)
 
 // ErrNotFound is returned when a query by ID does not match any existing
 // rows. For example, attempting to cancel a job that doesn't exist will
@@ -22,6 +33,32 @@ var ErrNotFound = errors.New("not found")
 // running.
 var ErrJobRunning = errors.New("running jobs cannot be deleted")
 
+// JobNotUpdatableError is returned when a job is attempted to be updated while
+// it's running or after it's been finalized. Only jobs still waiting in the
+// queue (available, pending, retryable, or scheduled) can be updated.
+type JobNotUpdatableError struct {
+	// ID is the ID of the job that couldn't be updated.
+	ID int64
+
+	// State is the state of the job at the time of the attempted update.
+	State JobState
+}
+
+// Error returns the error string.
+func (e *JobNotUpdatableError) Error() string {
+	return fmt.Sprintf("job %d in state %q cannot be updated", e.ID, e.State)
+}
+
+// Is implements the interface used by errors.Is to determine if errors are
+// equivalent. It returns true for any other JobNotUpdatableError without
+// regard to ID or State so it is possible to detect this type of error with:
+//
+//	errors.Is(err, &JobNotUpdatableError{})
+func (e *JobNotUpdatableError) Is(target error) bool {
+	_, ok := target.(*JobNotUpdatableError)
+	return ok
+}
+
 // JobArgs is an interface that should be implemented by the arguments to a job.
 // This definition duplicates the JobArgs interface in the river package so that
 // it can be used in other packages without creating a circular dependency.
@@ -156,6 +193,49 @@ func (j *JobRow) Output() []byte {
 	return metadata.Output
 }
 
+// Checkpoint returns the job's most recently recorded checkpoint, if any. The
+// return value is a raw JSON payload of the checkpoint state, or nil if no
+// checkpoint was recorded.
+func (j *JobRow) Checkpoint() []byte {
+	type metadataWithCheckpoint struct {
+		Checkpoint json.RawMessage `json:"checkpoint"`
+	}
+
+	var metadata metadataWithCheckpoint
+	if err := json.Unmarshal(j.Metadata, &metadata); err != nil {
+		return nil
+	}
+
+	return metadata.Checkpoint
+}
+
+// Progress returns the job's most recently recorded progress, or nil if no
+// progress was recorded.
+func (j *JobRow) Progress() *JobProgress {
+	type metadataWithProgress struct {
+		Progress *JobProgress `json:"progress"`
+	}
+
+	var metadata metadataWithProgress
+	if err := json.Unmarshal(j.Metadata, &metadata); err != nil {
+		return nil
+	}
+
+	return metadata.Progress
+}
+
+// JobProgress is progress reported by a running job.
+type JobProgress struct {
+	// Percent is how far along the job is, between 0 and 100.
+	Percent float64 `json:"percent"`
+
+	// Status is a free-form description of what the job is currently doing.
+	Status string `json:"status,omitempty"`
+
+	// UpdatedAt is when the progress was recorded.
+	UpdatedAt time.Time `json:"updated_at"`
+}
+
 // JobState is the state of a job. Jobs start their lifecycle as either
 // JobStateAvailable or JobStateScheduled, and if all goes well, transition to
 // JobStateCompleted after they're worked.
@@ -254,6 +334,61 @@ type AttemptError struct {
 	Trace string `json:"trace"`
 }
 
+// DeadLetter is a discarded job that was moved out of the jobs table into the
+// dead letter table so that it can be retained long after discarded jobs would
+// otherwise have been deleted. It contains the properties of the job at the
+// time it was moved, including its full history of errors.
+type DeadLetter struct {
+	// ID is the ID of the job that was moved to the dead letter table.
+	ID int64
+
+	// Attempt is the number of attempts the job made before it was discarded.
+	Attempt int
+
+	// AttemptedAt is the time that the job was last worked.
+	AttemptedAt *time.Time
+
+	// AttemptedBy is the set of client IDs that worked the job.
+	AttemptedBy []string
+
+	// CreatedAt is when the job record was originally created.
+	CreatedAt time.Time
+
+	// DeadLetteredAt is when the job was moved to the dead letter table.
+	DeadLetteredAt time.Time
+
+	// EncodedArgs is the job's JobArgs encoded as JSON.
+	EncodedArgs []byte
+
+	// Errors is the set of errors that occurred when the job was worked, one
+	// for each attempt. Ordered from earliest error to the latest error.
+	Errors []AttemptError
+
+	// FinalizedAt is the time at which the job was discarded.
+	FinalizedAt *time.Time
+
+	// Kind is the job's kind.
+	Kind string
+
+	// MaxAttempts is the maximum number of attempts that the job was allowed.
+	MaxAttempts int
+
+	// Metadata is the job's metadata at the time it was discarded.
+	Metadata []byte
+
+	// Priority is the job's priority.
+	Priority int
+
+	// Queue is the name of the queue the job was in.
+	Queue string
+
+	// ScheduledAt is when the job was last scheduled to become available.
+	ScheduledAt time.Time
+
+	// Tags are the job's tags.
+	Tags []string
+}
+
 type JobInsertParams struct {
 	Args         JobArgs
 	CreatedAt    *time.Time
@@ -299,7 +434,11 @@ type JobInsertParams struct {
 //
 // List of hook interfaces that may be implemented:
 // - HookInsertBegin
+// - HookInsertEnd
+// - HookJobRescued
+// - HookJobStateChange
 // - HookWorkBegin
+// - HookWorkEnd
 //
 // More operation-specific interfaces may be added in future versions.
 type Hook interface {
@@ -317,6 +456,50 @@ type HookInsertBegin interface {
 	InsertBegin(ctx context.Context, params *JobInsertParams) error
 }
 
+// HookInsertEnd is an interface to a hook that runs after a job has been
+// inserted, but while still inside the insert's transaction. It's invoked with
+// the job's insert parameters and the insert's result, which includes the
+// inserted job and whether it was skipped as a duplicate of an existing unique
+// job. Returning an error aborts the insert.
+//
+// HookInsertEnd isn't invoked for jobs inserted with InsertManyFast, which
+// doesn't return inserted jobs.
+type HookInsertEnd interface {
+	Hook
+
+	InsertEnd(ctx context.Context, params *JobInsertParams, result *JobInsertResult) error
+}
+
+// HookJobRescued is an interface to a hook that runs after the rescuer has
+// rescued a job that was stuck in the running state, like when the client
+// working it crashed. It's invoked with the job in its updated state, which is
+// retryable if the job will be retried, discarded if it's exhausted its
+// attempts, or cancelled if it was cancelled while running.
+//
+// The rescuer only runs on the leader, so this hook is only invoked on the
+// client that's currently elected leader.
+type HookJobRescued interface {
+	Hook
+
+	JobRescued(ctx context.Context, job *JobRow)
+}
+
+// HookJobStateChange is an interface to a hook that runs after the completer
+// has persisted a new state for a job that was worked: completed, retryable
+// (or available if it'll be retried imminently), discarded, cancelled, or
+// scheduled (or available) if it was snoozed. It's invoked with the job in its
+// updated state.
+//
+// Unlike HookWorkEnd, it runs only once the state change has been committed,
+// so it'll see an accurate result even if the job's outcome was changed while
+// it was running. Hooks should be fast because they're invoked serially
+// alongside the distribution of events to subscriptions.
+type HookJobStateChange interface {
+	Hook
+
+	JobStateChange(ctx context.Context, job *JobRow)
+}
+
 // HookWorkBegin is an interface to a hook that runs after a job has been locked
 // for work and before it's worked.
 type HookWorkBegin interface {
@@ -325,6 +508,33 @@ type HookWorkBegin interface {
 	WorkBegin(ctx context.Context, job *JobRow) error
 }
 
+// HookWorkEnd is an interface to a hook that runs after a job has been worked,
+// once its outcome is known but before the outcome is persisted by the
+// completer. It's invoked with the job as it was before being worked and a
+// result containing any error or panic value from the worker along with the
+// state that the job is being transitioned to.
+type HookWorkEnd interface {
+	Hook
+
+	WorkEnd(ctx context.Context, job *JobRow, result *JobWorkResult)
+}
+
+// JobWorkResult is the result of working a job, passed to HookWorkEnd.
+type JobWorkResult struct {
+	// Err is the error returned by the worker, or nil if it succeeded or
+	// panicked.
+	Err error
+
+	// PanicVal is the value that the worker panicked with, or nil if it
+	// didn't panic.
+	PanicVal any
+
+	// State is the state that the job is being transitioned to as a result of
+	// being worked. Jobs that were snoozed or that errored and will be
+	// retried imminently are transitioned straight to available.
+	State JobState
+}
+
 // Middleware is an arbitrary interface for a struct which will execute some
 // arbitrary code at a predefined step in the job lifecycle.
 //
@@ -6,12 +6,79 @@ import (
 	"go/token"
 	"os"
 	"testing"
+	"time"
 
 	"github.com/stretchr/testify/require"
 
 	"github.com/riverqueue/river/rivertype"
 )
 
+func TestJobRow_Checkpoint(t *testing.T) {
+	t.Parallel()
+
+	t.Run("Checkpoint", func(t *testing.T) {
+		t.Parallel()
+
+		jobRow := &rivertype.JobRow{
+			Metadata: []byte(`{"checkpoint": {"offset": 123}}`),
+		}
+		require.JSONEq(t, `{"offset": 123}`, string(jobRow.Checkpoint()))
+	})
+
+	t.Run("NoCheckpoint", func(t *testing.T) {
+		t.Parallel()
+
+		jobRow := &rivertype.JobRow{
+			Metadata: []byte(`{}`),
+		}
+		require.Nil(t, jobRow.Checkpoint())
+	})
+
+	t.Run("InvalidMetadata", func(t *testing.T) {
+		t.Parallel()
+
+		jobRow := &rivertype.JobRow{
+			Metadata: []byte(`not-json`),
+		}
+		require.Nil(t, jobRow.Checkpoint())
+	})
+}
+
+func TestJobRow_Progress(t *testing.T) {
+	t.Parallel()
+
+	t.Run("Progress", func(t *testing.T) {
+		t.Parallel()
+
+		jobRow := &rivertype.JobRow{
+			Metadata: []byte(`{"progress": {"percent": 42.5, "status": "importing rows", "updated_at": "2025-01-01T00:00:00Z"}}`),
+		}
+		require.Equal(t, &rivertype.JobProgress{
+			Percent:   42.5,
+			Status:    "importing rows",
+			UpdatedAt: time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC),
+		}, jobRow.Progress())
+	})
+
+	t.Run("NoProgress", func(t *testing.T) {
+		t.Parallel()
+
+		jobRow := &rivertype.JobRow{
+			Metadata: []byte(`{}`),
+		}
+		require.Nil(t, jobRow.Progress())
+	})
+
+	t.Run("InvalidMetadata", func(t *testing.T) {
+		t.Parallel()
+
+		jobRow := &rivertype.JobRow{
+			Metadata: []byte(`not-json`),
+		}
+		require.Nil(t, jobRow.Progress())
+	})
+}
+
 func TestJobRow_Output(t *testing.T) {
 	t.Parallel()
 
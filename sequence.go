@@ -0,0 +1,43 @@
+package river
+
+import (
+	"encoding/json"
+	"fmt"
+
+	"github.com/riverqueue/river/internal/dbunique"
+	"github.com/riverqueue/river/rivertype"
+)
+
+const (
+	metadataKeySequenceContinueOnDiscarded = "river:sequence_continue_on_discarded"
+	metadataKeySequenceKey                 = "river:sequence_key"
+)
+
+// metadataWithSequence returns the metadata of the given insert params with
+// the job's sequence key (and whether its sequence continues past discarded
+// jobs) merged in.
+func metadataWithSequence(sequenceOpts *SequenceOpts, params *rivertype.JobInsertParams) ([]byte, error) {
+	sequenceKey, err := dbunique.SequenceKey(&dbunique.SequenceOpts{
+		ByArgs:      sequenceOpts.ByArgs,
+		ByQueue:     sequenceOpts.ByQueue,
+		ExcludeKind: sequenceOpts.ExcludeKind,
+	}, params)
+	if err != nil {
+		return nil, err
+	}
+
+	metadataMap := make(map[string]json.RawMessage)
+	if len(params.Metadata) > 0 {
+		if err := json.Unmarshal(params.Metadata, &metadataMap); err != nil {
+			return nil, fmt.Errorf("error unmarshaling metadata for sequenced job: %w", err)
+		}
+	}
+	metadataMap[metadataKeySequenceKey] = json.RawMessage(`"` + sequenceKey + `"`) // hex encoded, so needs no escaping
+	if sequenceOpts.ContinueOnDiscarded {
+		metadataMap[metadataKeySequenceContinueOnDiscarded] = json.RawMessage("true")
+	} else {
+		delete(metadataMap, metadataKeySequenceContinueOnDiscarded)
+	}
+
+	return json.Marshal(metadataMap)
+}
@@ -8,6 +8,7 @@ import (
 
 	"github.com/riverqueue/river/internal/jobcompleter"
 	"github.com/riverqueue/river/internal/jobstats"
+	"github.com/riverqueue/river/rivermetrics"
 	"github.com/riverqueue/river/rivershared/baseservice"
 	"github.com/riverqueue/river/rivershared/startstop"
 	"github.com/riverqueue/river/rivershared/util/sliceutil"
@@ -20,6 +21,20 @@ type subscriptionManager struct {
 
 	subscribeCh <-chan []jobcompleter.CompleterJobUpdated
 
+	// jobStateChangeFunc is invoked for every job update received from the
+	// completer, regardless of whether there are any subscriptions. It's used
+	// to invoke job state change hooks.
+	jobStateChangeFunc func(ctx context.Context, job *rivertype.JobRow)
+
+	// clusterPublishFunc is invoked with every batch of job updates received
+	// from the completer when cluster events are enabled. It publishes the
+	// updates so they can be received by clients elsewhere in the cluster.
+	clusterPublishFunc func(ctx context.Context, jobs []*rivertype.JobRow)
+
+	// metrics records metrics for every job update received from the
+	// completer. Nil unless Config.Metrics is set.
+	metrics rivermetrics.Recorder
+
 	statsMu        sync.Mutex // protects stats fields
 	statsAggregate jobstats.JobStatistics
 	statsNumJobs   int
@@ -79,13 +94,13 @@ func (sm *subscriptionManager) Start(ctx context.Context) error {
 				// one has to be careful in tests.
 				sm.Logger.DebugContext(ctx, sm.Name+": Stopping; distributing subscriptions until channel is closed")
 				for updates := range sm.subscribeCh {
-					sm.distributeJobUpdates(updates)
+					sm.distributeJobUpdates(context.WithoutCancel(ctx), updates)
 				}
 
 				return
 
 			case updates := <-sm.subscribeCh:
-				sm.distributeJobUpdates(updates)
+				sm.distributeJobUpdates(ctx, updates)
 			}
 		}
 	}()
@@ -118,7 +133,21 @@ func (sm *subscriptionManager) safeDurationAverage(d time.Duration, n int) time.
 // Receives updates from the completer and prompts the client to update
 // statistics and distribute jobs into any listening subscriber channels.
 // (Subscriber channels are non-blocking so this should be quite fast.)
-func (sm *subscriptionManager) distributeJobUpdates(updates []jobcompleter.CompleterJobUpdated) {
+func (sm *subscriptionManager) distributeJobUpdates(ctx context.Context, updates []jobcompleter.CompleterJobUpdated) {
+	if sm.jobStateChangeFunc != nil {
+		for _, update := range updates {
+			sm.jobStateChangeFunc(ctx, update.Job)
+		}
+	}
+
+	if sm.metrics != nil {
+		recordJobUpdateMetrics(sm.metrics, updates)
+	}
+
+	if sm.clusterPublishFunc != nil {
+		sm.clusterPublishFunc(ctx, sliceutil.Map(updates, func(u jobcompleter.CompleterJobUpdated) *rivertype.JobRow { return u.Job }))
+	}
+
 	func() {
 		sm.statsMu.Lock()
 		defer sm.statsMu.Unlock()
@@ -152,23 +181,48 @@ func (sm *subscriptionManager) distributeJobUpdates(updates []jobcompleter.Compl
 //
 // MUST be called with sm.mu already held.
 func (sm *subscriptionManager) distributeJobEvent(job *rivertype.JobRow, stats *JobStatistics) {
-	var event *Event
-	switch job.State {
+	for _, kind := range jobEventKindsForState(job.State) {
+		sm.sendEvent(&Event{Kind: kind, Job: job, JobStats: stats})
+	}
+}
+
+// Returns the kinds of events to distribute for a job that the completer set
+// to the given state. Discarded jobs are distributed as both failed and
+// discarded so that subscribers predating the discarded event kind continue to
+// receive them.
+func jobEventKindsForState(state rivertype.JobState) []EventKind {
+	switch state {
 	case rivertype.JobStateCancelled:
-		event = &Event{Kind: EventKindJobCancelled, Job: job, JobStats: stats}
+		return []EventKind{EventKindJobCancelled}
 	case rivertype.JobStateCompleted:
-		event = &Event{Kind: EventKindJobCompleted, Job: job, JobStats: stats}
+		return []EventKind{EventKindJobCompleted}
+	case rivertype.JobStateDiscarded:
+		return []EventKind{EventKindJobFailed, EventKindJobDiscarded}
 	case rivertype.JobStateScheduled:
-		event = &Event{Kind: EventKindJobSnoozed, Job: job, JobStats: stats}
-	case rivertype.JobStateAvailable, rivertype.JobStateDiscarded, rivertype.JobStateRetryable, rivertype.JobStateRunning:
-		event = &Event{Kind: EventKindJobFailed, Job: job, JobStats: stats}
+		return []EventKind{EventKindJobSnoozed}
+	case rivertype.JobStateAvailable, rivertype.JobStateRetryable, rivertype.JobStateRunning:
+		return []EventKind{EventKindJobFailed}
 	case rivertype.JobStatePending:
 		panic("completion subscriber unexpectedly received job in pending state, river bug")
-	default:
-		// linter exhaustive rule prevents this from being reached
-		panic("unreachable state to distribute, river bug")
 	}
 
+	// linter exhaustive rule prevents this from being reached
+	panic("unreachable state to distribute, river bug")
+}
+
+// Distribute an event that didn't originate from the completer, like a queue
+// being paused or a running job flushing its progress.
+func (sm *subscriptionManager) distributeEvent(event *Event) {
+	sm.mu.Lock()
+	defer sm.mu.Unlock()
+
+	sm.sendEvent(event)
+}
+
+// Send an event to any subscriptions listening for its kind.
+//
+// MUST be called with sm.mu already held.
+func (sm *subscriptionManager) sendEvent(event *Event) {
 	// All subscription channels are non-blocking so this is always fast and
 	// there's no risk of falling behind what producers are sending.
 	for _, sub := range sm.subscriptions {
@@ -187,22 +241,6 @@ func (sm *subscriptionManager) distributeJobEvent(job *rivertype.JobRow, stats *
 	}
 }
 
-func (sm *subscriptionManager) distributeQueueEvent(event *Event) {
-	sm.mu.Lock()
-	defer sm.mu.Unlock()
-
-	// All subscription channels are non-blocking so this is always fast and
-	// there's no risk of falling behind what producers are sending.
-	for _, sub := range sm.subscriptions {
-		if sub.ListensFor(event.Kind) {
-			select {
-			case sub.Chan <- event:
-			default:
-			}
-		}
-	}
-}
-
 // Special internal variant that lets us inject an overridden size.
 func (sm *subscriptionManager) SubscribeConfig(config *SubscribeConfig) (<-chan *Event, func()) {
 	if config.ChanSize < 0 {
@@ -104,6 +104,28 @@ func Test_SubscriptionManager(t *testing.T) {
 		}
 	})
 
+	t.Run("DistributesDiscardedAsFailedAndDiscarded", func(t *testing.T) {
+		t.Parallel()
+
+		manager, bundle := setup(t)
+		t.Cleanup(func() { close(bundle.subscribeCh) })
+
+		sub, cancelSub := manager.SubscribeConfig(&SubscribeConfig{ChanSize: 10, Kinds: []EventKind{EventKindJobDiscarded, EventKindJobFailed}})
+		t.Cleanup(cancelSub)
+
+		job := testfactory.Job(ctx, t, bundle.exec, &testfactory.JobOpts{State: ptrutil.Ptr(rivertype.JobStateDiscarded), FinalizedAt: ptrutil.Ptr(time.Now())})
+
+		bundle.subscribeCh <- []jobcompleter.CompleterJobUpdated{
+			{Job: job, JobStats: &jobstats.JobStatistics{}},
+		}
+
+		received := riversharedtest.WaitOrTimeoutN(t, sub, 2)
+		require.Equal(t, EventKindJobFailed, received[0].Kind)
+		require.Equal(t, job.ID, received[0].Job.ID)
+		require.Equal(t, EventKindJobDiscarded, received[1].Kind)
+		require.Equal(t, job.ID, received[1].Job.ID)
+	})
+
 	t.Run("StartStopRepeatedly", func(t *testing.T) {
 		// This service does not use the typical `startstoptest.Stress()` test
 		// because there are some additional steps required after a `Stop` for the
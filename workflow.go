@@ -0,0 +1,381 @@
+package river
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"slices"
+	"strings"
+
+	"github.com/tidwall/gjson"
+
+	"github.com/riverqueue/river/riverdriver"
+	"github.com/riverqueue/river/rivershared/util/randutil"
+	"github.com/riverqueue/river/rivertype"
+)
+
+// Metadata keys used to track workflow tasks. Workflows are modeled entirely
+// in job metadata, so these keys are reserved for River's use.
+const (
+	metadataKeyWorkflowDepFailedAction = "river:workflow_dep_failed_action"
+	metadataKeyWorkflowDepJobIDs       = "river:workflow_dep_job_ids"
+	metadataKeyWorkflowDeps            = "river:workflow_deps"
+	metadataKeyWorkflowID              = "river:workflow_id"
+	metadataKeyWorkflowName            = "river:workflow_name"
+	metadataKeyWorkflowTask            = "river:workflow_task"
+)
+
+// WorkflowDepFailedAction is the action taken on a workflow task in case one
+// of its dependencies is cancelled or discarded, and therefore will never
+// complete.
+type WorkflowDepFailedAction string
+
+const (
+	// WorkflowDepFailedActionCancel cancels a task when one of its dependencies
+	// is cancelled or discarded. This is the default.
+	WorkflowDepFailedActionCancel WorkflowDepFailedAction = "cancel"
+
+	// WorkflowDepFailedActionDiscard discards a task when one of its
+	// dependencies is cancelled or discarded.
+	WorkflowDepFailedActionDiscard WorkflowDepFailedAction = "discard"
+)
+
+// Workflow is a set of jobs (called tasks) with dependencies between them,
+// forming a directed acyclic graph. Tasks without dependencies are inserted as
+// available and start running right away, while tasks with dependencies are
+// inserted in the pending state. A maintenance service run by the elected
+// leader promotes pending tasks to available once all their dependencies have
+// completed, or cancels them if any dependency is cancelled or discarded.
+//
+// A workflow is built up with Add, then converted to insert parameters with
+// Prepare, which can be passed to InsertMany or InsertManyTx:
+//
+//	workflow := river.NewWorkflow(&river.WorkflowOpts{Name: "nightly_report"})
+//	workflow.Add("fetch", FetchArgs{}, nil, nil)
+//	workflow.Add("transform", TransformArgs{}, nil, &river.WorkflowTaskOpts{Deps: []string{"fetch"}})
+//	workflow.Add("report", ReportArgs{}, nil, &river.WorkflowTaskOpts{Deps: []string{"transform"}})
+//
+//	params, err := workflow.Prepare()
+//	if err != nil {
+//		// handle error
+//	}
+//
+//	if _, err := client.InsertMany(ctx, params); err != nil {
+//		// handle error
+//	}
+//
+// All tasks in a workflow should be inserted together, ideally in a single
+// transaction. Dependencies on jobs by ID are checked to exist when tasks are
+// inserted. A dependency that can't be found when checking a pending task is
+// then treated as satisfied so that workflows continue to make progress even
+// after completed tasks have been removed by the job cleaner.
+type Workflow struct {
+	id    string
+	name  string
+	tasks []*workflowTask
+}
+
+// WorkflowOpts are options for a new workflow.
+type WorkflowOpts struct {
+	// ID is a unique identifier for the workflow. It's stored in the metadata
+	// of each of the workflow's tasks and used to resolve dependencies by
+	// name, so it should be unique across all workflows that may be in the
+	// database at the same time.
+	//
+	// Defaults to a randomly generated ID.
+	ID string
+
+	// Name is an optional human-friendly name for the workflow, stored in the
+	// metadata of each of its tasks for informational purposes.
+	Name string
+}
+
+// WorkflowTaskOpts are options for a task added to a workflow.
+type WorkflowTaskOpts struct {
+	// DepFailedAction is the action to take if one of the task's dependencies
+	// is cancelled or discarded.
+	//
+	// Defaults to WorkflowDepFailedActionCancel.
+	DepFailedAction WorkflowDepFailedAction
+
+	// DepJobIDs are the IDs of already inserted jobs that this task depends on.
+	// The jobs don't need to be part of a workflow, but must exist when the
+	// task is inserted or the insert returns an error.
+	DepJobIDs []int64
+
+	// Deps are the names of other tasks in the same workflow that this task
+	// depends on. Each must be added to the workflow before Prepare is called.
+	Deps []string
+}
+
+type workflowTask struct {
+	args       JobArgs
+	insertOpts *InsertOpts
+	name       string
+	opts       *WorkflowTaskOpts
+}
+
+// NewWorkflow initializes a new workflow. Add tasks with Add, then call Prepare
+// to get insert parameters.
+func NewWorkflow(opts *WorkflowOpts) *Workflow {
+	if opts == nil {
+		opts = &WorkflowOpts{}
+	}
+
+	id := opts.ID
+	if id == "" {
+		id = "wf_" + randutil.Hex(12)
+	}
+
+	return &Workflow{
+		id:   id,
+		name: opts.Name,
+	}
+}
+
+// ID returns the workflow's unique identifier, which can be used to list its
+// tasks with Client.WorkflowTaskList.
+func (w *Workflow) ID() string { return w.id }
+
+// Add adds a task to the workflow. The task name must be unique within the
+// workflow, and is what other tasks use to reference it as a dependency.
+// Insert options and task options are optional and may be nil.
+//
+// Dependencies are validated by Prepare rather than Add so that tasks may be
+// added in any order.
+func (w *Workflow) Add(taskName string, args JobArgs, insertOpts *InsertOpts, opts *WorkflowTaskOpts) *Workflow {
+	if opts == nil {
+		opts = &WorkflowTaskOpts{}
+	}
+
+	w.tasks = append(w.tasks, &workflowTask{
+		args:       args,
+		insertOpts: insertOpts,
+		name:       taskName,
+		opts:       opts,
+	})
+	return w
+}
+
+// Prepare validates the workflow and returns insert parameters for each of its
+// tasks, in the order they were added. The result should be passed to
+// InsertMany or InsertManyTx.
+//
+// An error is returned if a task name is empty or duplicated, a dependency
+// refers to a task that doesn't exist, or dependencies form a cycle.
+func (w *Workflow) Prepare() ([]InsertManyParams, error) {
+	if len(w.tasks) < 1 {
+		return nil, errors.New("workflow must have at least one task")
+	}
+
+	tasksByName := make(map[string]*workflowTask, len(w.tasks))
+	for _, task := range w.tasks {
+		if task.name == "" {
+			return nil, errors.New("workflow task name must not be empty")
+		}
+		if task.args == nil {
+			return nil, fmt.Errorf("workflow task %q must have args", task.name)
+		}
+		if _, ok := tasksByName[task.name]; ok {
+			return nil, fmt.Errorf("duplicate workflow task name %q", task.name)
+		}
+		tasksByName[task.name] = task
+	}
+
+	for _, task := range w.tasks {
+		for _, dep := range task.opts.Deps {
+			if _, ok := tasksByName[dep]; !ok {
+				return nil, fmt.Errorf("workflow task %q depends on unknown task %q", task.name, dep)
+			}
+		}
+
+		switch task.opts.DepFailedAction {
+		case "", WorkflowDepFailedActionCancel, WorkflowDepFailedActionDiscard:
+		default:
+			return nil, fmt.Errorf("workflow task %q has invalid dep failed action %q", task.name, task.opts.DepFailedAction)
+		}
+	}
+
+	if err := workflowCheckCycles(w.tasks, tasksByName); err != nil {
+		return nil, err
+	}
+
+	params := make([]InsertManyParams, len(w.tasks))
+	for i, task := range w.tasks {
+		var insertOpts InsertOpts
+		if task.insertOpts != nil {
+			insertOpts = *task.insertOpts
+		}
+
+		metadata, err := w.taskMetadata(task, insertOpts.Metadata)
+		if err != nil {
+			return nil, err
+		}
+		insertOpts.Metadata = metadata
+
+		if len(task.opts.Deps) > 0 || len(task.opts.DepJobIDs) > 0 {
+			insertOpts.Pending = true
+		}
+
+		params[i] = InsertManyParams{Args: task.args, InsertOpts: &insertOpts}
+	}
+
+	return params, nil
+}
+
+// taskMetadata merges workflow tracking keys into the given metadata, which
+// may be empty.
+func (w *Workflow) taskMetadata(task *workflowTask, metadata []byte) ([]byte, error) {
+	metadataMap := make(map[string]any)
+	if len(metadata) > 0 {
+		if err := json.Unmarshal(metadata, &metadataMap); err != nil {
+			return nil, fmt.Errorf("error unmarshaling metadata for workflow task %q: %w", task.name, err)
+		}
+	}
+
+	metadataMap[metadataKeyWorkflowID] = w.id
+	metadataMap[metadataKeyWorkflowTask] = task.name
+
+	if w.name != "" {
+		metadataMap[metadataKeyWorkflowName] = w.name
+	}
+	if len(task.opts.Deps) > 0 {
+		metadataMap[metadataKeyWorkflowDeps] = task.opts.Deps
+	}
+	if len(task.opts.DepJobIDs) > 0 {
+		metadataMap[metadataKeyWorkflowDepJobIDs] = task.opts.DepJobIDs
+	}
+	if task.opts.DepFailedAction != "" {
+		metadataMap[metadataKeyWorkflowDepFailedAction] = string(task.opts.DepFailedAction)
+	}
+
+	return json.Marshal(metadataMap)
+}
+
+// workflowValidateDepJobIDs returns an error if any of the given jobs to be
+// inserted depends on a job ID that doesn't exist. Checking at insert means a
+// dependency found to be missing by the workflow promoter can only have been
+// removed afterwards, so it's safe to treat as satisfied rather than hold up
+// the task forever on a mistyped ID.
+func workflowValidateDepJobIDs(ctx context.Context, exec riverdriver.Executor, schema string, insertParams []*rivertype.JobInsertParams) error {
+	var depJobIDs []int64
+	for _, params := range insertParams {
+		for _, depJobID := range gjson.GetBytes(params.Metadata, metadataKeyWorkflowDepJobIDs).Array() {
+			depJobIDs = append(depJobIDs, depJobID.Int())
+		}
+	}
+	if len(depJobIDs) < 1 {
+		return nil
+	}
+
+	slices.Sort(depJobIDs)
+	depJobIDs = slices.Compact(depJobIDs)
+
+	depJobs, err := exec.JobGetByIDMany(ctx, &riverdriver.JobGetByIDManyParams{
+		ID:     depJobIDs,
+		Schema: schema,
+	})
+	if err != nil {
+		return err
+	}
+
+	existingIDs := make(map[int64]struct{}, len(depJobs))
+	for _, depJob := range depJobs {
+		existingIDs[depJob.ID] = struct{}{}
+	}
+	for _, depJobID := range depJobIDs {
+		if _, ok := existingIDs[depJobID]; !ok {
+			return fmt.Errorf("workflow task depends on job %d, which doesn't exist", depJobID)
+		}
+	}
+
+	return nil
+}
+
+// workflowCheckCycles returns an error if dependencies between the given tasks
+// form a cycle. Dependencies must have already been validated to exist.
+func workflowCheckCycles(tasks []*workflowTask, tasksByName map[string]*workflowTask) error {
+	const (
+		unvisited = iota
+		visiting
+		visited
+	)
+
+	var (
+		path   []string
+		states = make(map[string]int, len(tasks))
+		visit  func(task *workflowTask) error
+	)
+
+	visit = func(task *workflowTask) error {
+		switch states[task.name] {
+		case visited:
+			return nil
+		case visiting:
+			cycleStart := slices.Index(path, task.name)
+			return fmt.Errorf("workflow has a dependency cycle: %s", strings.Join(append(path[cycleStart:], task.name), " -> "))
+		}
+
+		states[task.name] = visiting
+		path = append(path, task.name)
+
+		for _, dep := range task.opts.Deps {
+			if err := visit(tasksByName[dep]); err != nil {
+				return err
+			}
+		}
+
+		states[task.name] = visited
+		path = path[:len(path)-1]
+		return nil
+	}
+
+	for _, task := range tasks {
+		if states[task.name] == unvisited {
+			if err := visit(task); err != nil {
+				return err
+			}
+		}
+	}
+
+	return nil
+}
+
+// WorkflowTask is a task in a workflow along with its current job row,
+// returned by Client.WorkflowTaskList.
+type WorkflowTask struct {
+	// DepJobIDs are the IDs of jobs outside the workflow that the task depends
+	// on.
+	DepJobIDs []int64
+
+	// Deps are the names of other tasks in the workflow that the task depends
+	// on.
+	Deps []string
+
+	// Job is the task's job row, which includes its current state.
+	Job *rivertype.JobRow
+
+	// Name is the task's name within the workflow.
+	Name string
+}
+
+// workflowTaskFromJobRow extracts workflow information from a job row's
+// metadata.
+func workflowTaskFromJobRow(job *rivertype.JobRow) (*WorkflowTask, error) {
+	var metadata struct {
+		DepJobIDs []int64  `json:"river:workflow_dep_job_ids"`
+		Deps      []string `json:"river:workflow_deps"`
+		Task      string   `json:"river:workflow_task"`
+	}
+	if err := json.Unmarshal(job.Metadata, &metadata); err != nil {
+		return nil, fmt.Errorf("error unmarshaling workflow metadata for job %d: %w", job.ID, err)
+	}
+
+	return &WorkflowTask{
+		DepJobIDs: metadata.DepJobIDs,
+		Deps:      metadata.Deps,
+		Job:       job,
+		Name:      metadata.Task,
+	}, nil
+}
@@ -0,0 +1,117 @@
+package river
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestWorkflow(t *testing.T) {
+	t.Parallel()
+
+	t.Run("GeneratesID", func(t *testing.T) {
+		t.Parallel()
+
+		workflow1 := NewWorkflow(nil)
+		workflow2 := NewWorkflow(&WorkflowOpts{})
+		require.NotEmpty(t, workflow1.ID())
+		require.NotEmpty(t, workflow2.ID())
+		require.NotEqual(t, workflow1.ID(), workflow2.ID())
+
+		require.Equal(t, "my_workflow", NewWorkflow(&WorkflowOpts{ID: "my_workflow"}).ID())
+	})
+
+	t.Run("PreparesInsertParams", func(t *testing.T) {
+		t.Parallel()
+
+		workflow := NewWorkflow(&WorkflowOpts{ID: "wf_123", Name: "my_workflow"})
+		workflow.Add("a", noOpArgs{}, &InsertOpts{Metadata: []byte(`{"foo":"bar"}`), Queue: "other_queue"}, nil)
+		workflow.Add("b", noOpArgs{}, nil, &WorkflowTaskOpts{Deps: []string{"a"}})
+		workflow.Add("c", noOpArgs{}, nil, &WorkflowTaskOpts{
+			DepFailedAction: WorkflowDepFailedActionDiscard,
+			DepJobIDs:       []int64{123},
+			Deps:            []string{"a", "b"},
+		})
+
+		params, err := workflow.Prepare()
+		require.NoError(t, err)
+		require.Len(t, params, 3)
+
+		require.False(t, params[0].InsertOpts.Pending)
+		require.Equal(t, "other_queue", params[0].InsertOpts.Queue)
+		require.JSONEq(t, `{"foo":"bar","river:workflow_id":"wf_123","river:workflow_name":"my_workflow","river:workflow_task":"a"}`, string(params[0].InsertOpts.Metadata))
+
+		require.True(t, params[1].InsertOpts.Pending)
+		require.JSONEq(t, `{"river:workflow_id":"wf_123","river:workflow_name":"my_workflow","river:workflow_task":"b","river:workflow_deps":["a"]}`, string(params[1].InsertOpts.Metadata))
+
+		require.True(t, params[2].InsertOpts.Pending)
+		require.JSONEq(t, `{"river:workflow_id":"wf_123","river:workflow_name":"my_workflow","river:workflow_task":"c","river:workflow_deps":["a","b"],"river:workflow_dep_job_ids":[123],"river:workflow_dep_failed_action":"discard"}`, string(params[2].InsertOpts.Metadata))
+	})
+
+	t.Run("DoesNotModifyInsertOpts", func(t *testing.T) {
+		t.Parallel()
+
+		insertOpts := &InsertOpts{Metadata: []byte(`{"foo":"bar"}`)}
+
+		workflow := NewWorkflow(nil)
+		workflow.Add("a", noOpArgs{}, insertOpts, &WorkflowTaskOpts{DepJobIDs: []int64{123}})
+
+		_, err := workflow.Prepare()
+		require.NoError(t, err)
+		require.Equal(t, &InsertOpts{Metadata: []byte(`{"foo":"bar"}`)}, insertOpts)
+	})
+
+	t.Run("ErrorOnNoTasks", func(t *testing.T) {
+		t.Parallel()
+
+		_, err := NewWorkflow(nil).Prepare()
+		require.EqualError(t, err, "workflow must have at least one task")
+	})
+
+	t.Run("ErrorOnEmptyTaskName", func(t *testing.T) {
+		t.Parallel()
+
+		_, err := NewWorkflow(nil).Add("", noOpArgs{}, nil, nil).Prepare()
+		require.EqualError(t, err, "workflow task name must not be empty")
+	})
+
+	t.Run("ErrorOnDuplicateTaskName", func(t *testing.T) {
+		t.Parallel()
+
+		_, err := NewWorkflow(nil).
+			Add("a", noOpArgs{}, nil, nil).
+			Add("a", noOpArgs{}, nil, nil).
+			Prepare()
+		require.EqualError(t, err, `duplicate workflow task name "a"`)
+	})
+
+	t.Run("ErrorOnUnknownDep", func(t *testing.T) {
+		t.Parallel()
+
+		_, err := NewWorkflow(nil).
+			Add("a", noOpArgs{}, nil, &WorkflowTaskOpts{Deps: []string{"b"}}).
+			Prepare()
+		require.EqualError(t, err, `workflow task "a" depends on unknown task "b"`)
+	})
+
+	t.Run("ErrorOnInvalidDepFailedAction", func(t *testing.T) {
+		t.Parallel()
+
+		_, err := NewWorkflow(nil).
+			Add("a", noOpArgs{}, nil, &WorkflowTaskOpts{DepFailedAction: "explode"}).
+			Prepare()
+		require.EqualError(t, err, `workflow task "a" has invalid dep failed action "explode"`)
+	})
+
+	t.Run("ErrorOnCycle", func(t *testing.T) {
+		t.Parallel()
+
+		_, err := NewWorkflow(nil).
+			Add("a", noOpArgs{}, nil, nil).
+			Add("b", noOpArgs{}, nil, &WorkflowTaskOpts{Deps: []string{"a", "d"}}).
+			Add("c", noOpArgs{}, nil, &WorkflowTaskOpts{Deps: []string{"b"}}).
+			Add("d", noOpArgs{}, nil, &WorkflowTaskOpts{Deps: []string{"c"}}).
+			Prepare()
+		require.EqualError(t, err, "workflow has a dependency cycle: b -> d -> c -> b")
+	})
+}